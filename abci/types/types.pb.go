@@ -1840,6 +1840,10 @@ type ResponseCheckTx struct {
 	// mempool_error is set by Tendermint.
 	// ABCI applications creating a ResponseCheckTX should not set mempool_error.
 	MempoolError string `protobuf:"bytes,11,opt,name=mempool_error,json=mempoolError,proto3" json:"mempool_error,omitempty"`
+	// lane is the mempool lane the transaction is assigned to. Transactions
+	// without a lane, or with a lane the node has not configured, are
+	// assigned to the default lane.
+	Lane string `protobuf:"bytes,12,opt,name=lane,proto3" json:"lane,omitempty"`
 }
 
 func (m *ResponseCheckTx) Reset()         { *m = ResponseCheckTx{} }
@@ -1952,6 +1956,13 @@ func (m *ResponseCheckTx) GetMempoolError() string {
 	return ""
 }
 
+func (m *ResponseCheckTx) GetLane() string {
+	if m != nil {
+		return m.Lane
+	}
+	return ""
+}
+
 type ResponseDeliverTx struct {
 	Code      uint32  `protobuf:"varint,1,opt,name=code,proto3" json:"code,omitempty"`
 	Data      []byte  `protobuf:"bytes,2,opt,name=data,proto3" json:"data,omitempty"`
@@ -2964,172 +2975,172 @@ func init() {
 func init() { proto.RegisterFile("tendermint/abci/types.proto", fileDescriptor_252557cfdd89a31a) }
 
 var fileDescriptor_252557cfdd89a31a = []byte{
-	// 2627 bytes of a gzipped FileDescriptorProto
-	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0xe4, 0x5a, 0xcd, 0x73, 0xdb, 0xc6,
-	0x15, 0xe7, 0x37, 0x89, 0x47, 0x91, 0xa2, 0xd6, 0x8a, 0x43, 0x33, 0xb6, 0xe4, 0xc0, 0xe3, 0x34,
-	0x76, 0x12, 0xa9, 0x91, 0xc7, 0xae, 0x33, 0xe9, 0x47, 0x44, 0x9a, 0x2e, 0x15, 0xab, 0x92, 0xba,
-	0xa2, 0x9d, 0x49, 0xdb, 0x18, 0x01, 0x89, 0x15, 0x89, 0x98, 0x04, 0x10, 0x60, 0x29, 0x4b, 0x39,
-	0x76, 0xda, 0x8b, 0xa7, 0x07, 0x1f, 0x7b, 0xc9, 0x4c, 0xff, 0x83, 0x5e, 0x7b, 0xea, 0xa9, 0x87,
-	0x1c, 0xda, 0x99, 0x1c, 0x7b, 0xe8, 0xa4, 0x1d, 0xfb, 0xd6, 0x7f, 0xa0, 0xa7, 0xce, 0x74, 0xf6,
-	0x03, 0x20, 0x40, 0x12, 0x22, 0xd5, 0xf4, 0xd6, 0xdb, 0xee, 0xc3, 0x7b, 0x8f, 0xbb, 0x6f, 0xf7,
-	0xfd, 0xf6, 0xb7, 0x6f, 0x09, 0xaf, 0x51, 0x62, 0x19, 0xc4, 0x1d, 0x9a, 0x16, 0xdd, 0xd4, 0x3b,
-	0x5d, 0x73, 0x93, 0x9e, 0x3a, 0xc4, 0xdb, 0x70, 0x5c, 0x9b, 0xda, 0x68, 0x79, 0xfc, 0x71, 0x83,
-	0x7d, 0xac, 0x5d, 0x09, 0x69, 0x77, 0xdd, 0x53, 0x87, 0xda, 0x9b, 0x8e, 0x6b, 0xdb, 0x47, 0x42,
-	0xbf, 0x76, 0x39, 0xf4, 0x99, 0xfb, 0x09, 0x7b, 0x8b, 0x7c, 0x95, 0xc6, 0x4f, 0xc8, 0xa9, 0xff,
-	0xf5, 0xca, 0x94, 0xad, 0xa3, 0xbb, 0xfa, 0xd0, 0xff, 0xbc, 0xde, 0xb3, 0xed, 0xde, 0x80, 0x6c,
-	0xf2, 0x5e, 0x67, 0x74, 0xb4, 0x49, 0xcd, 0x21, 0xf1, 0xa8, 0x3e, 0x74, 0xa4, 0xc2, 0x6a, 0xcf,
-	0xee, 0xd9, 0xbc, 0xb9, 0xc9, 0x5a, 0x42, 0xaa, 0xfe, 0x25, 0x0f, 0x79, 0x4c, 0x3e, 0x1f, 0x11,
-	0x8f, 0xa2, 0x2d, 0xc8, 0x90, 0x6e, 0xdf, 0xae, 0x26, 0xaf, 0x26, 0xdf, 0x2c, 0x6e, 0x5d, 0xde,
-	0x98, 0x98, 0xdc, 0x86, 0xd4, 0x6b, 0x76, 0xfb, 0x76, 0x2b, 0x81, 0xb9, 0x2e, 0xba, 0x0d, 0xd9,
-	0xa3, 0xc1, 0xc8, 0xeb, 0x57, 0x53, 0xdc, 0xe8, 0x4a, 0x9c, 0xd1, 0x7d, 0xa6, 0xd4, 0x4a, 0x60,
-	0xa1, 0xcd, 0x7e, 0xca, 0xb4, 0x8e, 0xec, 0x6a, 0xfa, 0xec, 0x9f, 0xda, 0xb1, 0x8e, 0xf8, 0x4f,
-	0x31, 0x5d, 0x54, 0x07, 0x30, 0x2d, 0x93, 0x6a, 0xdd, 0xbe, 0x6e, 0x5a, 0xd5, 0x0c, 0xb7, 0x7c,
-	0x3d, 0xde, 0xd2, 0xa4, 0x0d, 0xa6, 0xd8, 0x4a, 0x60, 0xc5, 0xf4, 0x3b, 0x6c, 0xb8, 0x9f, 0x8f,
-	0x88, 0x7b, 0x5a, 0xcd, 0x9e, 0x3d, 0xdc, 0x9f, 0x32, 0x25, 0x36, 0x5c, 0xae, 0x8d, 0x9a, 0x50,
-	0xec, 0x90, 0x9e, 0x69, 0x69, 0x9d, 0x81, 0xdd, 0x7d, 0x52, 0xcd, 0x71, 0x63, 0x35, 0xce, 0xb8,
-	0xce, 0x54, 0xeb, 0x4c, 0xb3, 0x95, 0xc0, 0xd0, 0x09, 0x7a, 0xe8, 0xfb, 0x50, 0xe8, 0xf6, 0x49,
-	0xf7, 0x89, 0x46, 0x4f, 0xaa, 0x79, 0xee, 0x63, 0x3d, 0xce, 0x47, 0x83, 0xe9, 0xb5, 0x4f, 0x5a,
-	0x09, 0x9c, 0xef, 0x8a, 0x26, 0x9b, 0xbf, 0x41, 0x06, 0xe6, 0x31, 0x71, 0x99, 0x7d, 0xe1, 0xec,
-	0xf9, 0xdf, 0x13, 0x9a, 0xdc, 0x83, 0x62, 0xf8, 0x1d, 0xf4, 0x23, 0x50, 0x88, 0x65, 0xc8, 0x69,
-	0x28, 0xdc, 0xc5, 0xd5, 0xd8, 0x75, 0xb6, 0x0c, 0x7f, 0x12, 0x05, 0x22, 0xdb, 0xe8, 0x2e, 0xe4,
-	0xba, 0xf6, 0x70, 0x68, 0xd2, 0x2a, 0x70, 0xeb, 0xb5, 0xd8, 0x09, 0x70, 0xad, 0x56, 0x02, 0x4b,
-	0x7d, 0xb4, 0x07, 0xe5, 0x81, 0xe9, 0x51, 0xcd, 0xb3, 0x74, 0xc7, 0xeb, 0xdb, 0xd4, 0xab, 0x16,
-	0xb9, 0x87, 0xeb, 0x71, 0x1e, 0x76, 0x4d, 0x8f, 0x1e, 0xfa, 0xca, 0xad, 0x04, 0x2e, 0x0d, 0xc2,
-	0x02, 0xe6, 0xcf, 0x3e, 0x3a, 0x22, 0x6e, 0xe0, 0xb0, 0xba, 0x74, 0xb6, 0xbf, 0x7d, 0xa6, 0xed,
-	0xdb, 0x33, 0x7f, 0x76, 0x58, 0x80, 0x7e, 0x0e, 0x17, 0x06, 0xb6, 0x6e, 0x04, 0xee, 0xb4, 0x6e,
-	0x7f, 0x64, 0x3d, 0xa9, 0x96, 0xb8, 0xd3, 0x1b, 0xb1, 0x83, 0xb4, 0x75, 0xc3, 0x77, 0xd1, 0x60,
-	0x06, 0xad, 0x04, 0x5e, 0x19, 0x4c, 0x0a, 0xd1, 0x63, 0x58, 0xd5, 0x1d, 0x67, 0x70, 0x3a, 0xe9,
-	0xbd, 0xcc, 0xbd, 0xdf, 0x8c, 0xf3, 0xbe, 0xcd, 0x6c, 0x26, 0xdd, 0x23, 0x7d, 0x4a, 0x5a, 0xcf,
-	0x43, 0xf6, 0x58, 0x1f, 0x8c, 0x88, 0xfa, 0x1d, 0x28, 0x86, 0xd2, 0x14, 0x55, 0x21, 0x3f, 0x24,
-	0x9e, 0xa7, 0xf7, 0x08, 0xcf, 0x6a, 0x05, 0xfb, 0x5d, 0xb5, 0x0c, 0x4b, 0xe1, 0xd4, 0x54, 0x9f,
-	0x27, 0x03, 0x4b, 0x96, 0x75, 0xcc, 0xf2, 0x98, 0xb8, 0x9e, 0x69, 0x5b, 0xbe, 0xa5, 0xec, 0xa2,
-	0x6b, 0x50, 0xe2, 0xfb, 0x47, 0xf3, 0xbf, 0xb3, 0xd4, 0xcf, 0xe0, 0x25, 0x2e, 0x7c, 0x24, 0x95,
-	0xd6, 0xa1, 0xe8, 0x6c, 0x39, 0x81, 0x4a, 0x9a, 0xab, 0x80, 0xb3, 0xe5, 0xf8, 0x0a, 0xaf, 0xc3,
-	0x12, 0x9b, 0x69, 0xa0, 0x91, 0xe1, 0x3f, 0x52, 0x64, 0x32, 0xa9, 0xa2, 0xfe, 0x39, 0x05, 0x95,
-	0xc9, 0x74, 0x46, 0x77, 0x21, 0xc3, 0x90, 0x4d, 0x82, 0x54, 0x6d, 0x43, 0xc0, 0xde, 0x86, 0x0f,
-	0x7b, 0x1b, 0x6d, 0x1f, 0xf6, 0xea, 0x85, 0xaf, 0xbe, 0x59, 0x4f, 0x3c, 0xff, 0xfb, 0x7a, 0x12,
-	0x73, 0x0b, 0x74, 0x89, 0x65, 0x9f, 0x6e, 0x5a, 0x9a, 0x69, 0xf0, 0x21, 0x2b, 0x2c, 0xb5, 0x74,
-	0xd3, 0xda, 0x31, 0xd0, 0x2e, 0x54, 0xba, 0xb6, 0xe5, 0x11, 0xcb, 0x1b, 0x79, 0x9a, 0x80, 0x55,
-	0x09, 0x4d, 0x91, 0x04, 0x13, 0x60, 0xdd, 0xf0, 0x35, 0x0f, 0xb8, 0x22, 0x5e, 0xee, 0x46, 0x05,
-	0xe8, 0x3e, 0xc0, 0xb1, 0x3e, 0x30, 0x0d, 0x9d, 0xda, 0xae, 0x57, 0xcd, 0x5c, 0x4d, 0xcf, 0xcc,
-	0xb2, 0x47, 0xbe, 0xca, 0x43, 0xc7, 0xd0, 0x29, 0xa9, 0x67, 0xd8, 0x70, 0x71, 0xc8, 0x12, 0xbd,
-	0x01, 0xcb, 0xba, 0xe3, 0x68, 0x1e, 0xd5, 0x29, 0xd1, 0x3a, 0xa7, 0x94, 0x78, 0x1c, 0xb6, 0x96,
-	0x70, 0x49, 0x77, 0x9c, 0x43, 0x26, 0xad, 0x33, 0x21, 0xba, 0x0e, 0x65, 0x86, 0x70, 0xa6, 0x3e,
-	0xd0, 0xfa, 0xc4, 0xec, 0xf5, 0x29, 0x07, 0xa8, 0x34, 0x2e, 0x49, 0x69, 0x8b, 0x0b, 0x55, 0x23,
-	0x58, 0x71, 0x8e, 0x6e, 0x08, 0x41, 0xc6, 0xd0, 0xa9, 0xce, 0x23, 0xb9, 0x84, 0x79, 0x9b, 0xc9,
-	0x1c, 0x9d, 0xf6, 0x65, 0x7c, 0x78, 0x1b, 0x5d, 0x84, 0x9c, 0x74, 0x9b, 0xe6, 0x6e, 0x65, 0x0f,
-	0xad, 0x42, 0xd6, 0x71, 0xed, 0x63, 0xc2, 0x97, 0xae, 0x80, 0x45, 0x47, 0xfd, 0x55, 0x0a, 0x56,
-	0xa6, 0x70, 0x90, 0xf9, 0xed, 0xeb, 0x5e, 0xdf, 0xff, 0x2d, 0xd6, 0x46, 0x77, 0x98, 0x5f, 0xdd,
-	0x20, 0xae, 0x3c, 0x3b, 0xaa, 0xd3, 0xa1, 0x6e, 0xf1, 0xef, 0x32, 0x34, 0x52, 0x1b, 0xed, 0x43,
-	0x65, 0xa0, 0x7b, 0x54, 0x13, 0xb8, 0xa2, 0x85, 0xce, 0x91, 0x69, 0x34, 0xdd, 0xd5, 0x7d, 0x24,
-	0x62, 0x9b, 0x5a, 0x3a, 0x2a, 0x0f, 0x22, 0x52, 0x84, 0x61, 0xb5, 0x73, 0xfa, 0x85, 0x6e, 0x51,
-	0xd3, 0x22, 0xda, 0xd4, 0xca, 0x5d, 0x9a, 0x72, 0xda, 0x3c, 0x36, 0x0d, 0x62, 0x75, 0xfd, 0x25,
-	0xbb, 0x10, 0x18, 0x07, 0x4b, 0xea, 0xa9, 0x18, 0xca, 0x51, 0x24, 0x47, 0x65, 0x48, 0xd1, 0x13,
-	0x19, 0x80, 0x14, 0x3d, 0x41, 0xdf, 0x85, 0x0c, 0x9b, 0x24, 0x9f, 0x7c, 0x79, 0xc6, 0x11, 0x28,
-	0xed, 0xda, 0xa7, 0x0e, 0xc1, 0x5c, 0x53, 0x55, 0x83, 0x74, 0x08, 0xd0, 0x7d, 0xd2, 0xab, 0x7a,
-	0x03, 0x96, 0x27, 0xe0, 0x3b, 0xb4, 0x7e, 0xc9, 0xf0, 0xfa, 0xa9, 0xcb, 0x50, 0x8a, 0x60, 0xb5,
-	0x7a, 0x11, 0x56, 0x67, 0x41, 0xaf, 0xda, 0x0f, 0xe4, 0x11, 0x08, 0x45, 0xb7, 0xa1, 0x10, 0x60,
-	0xaf, 0x48, 0xc7, 0xe9, 0x58, 0xf9, 0xca, 0x38, 0x50, 0x65, 0x79, 0xc8, 0xb6, 0x35, 0xdf, 0x0f,
-	0x29, 0x3e, 0xf0, 0xbc, 0xee, 0x38, 0x2d, 0xdd, 0xeb, 0xab, 0x9f, 0x42, 0x35, 0x0e, 0x57, 0x27,
-	0xa6, 0x91, 0x09, 0xb6, 0xe1, 0x45, 0xc8, 0x1d, 0xd9, 0xee, 0x50, 0xa7, 0xdc, 0x59, 0x09, 0xcb,
-	0x1e, 0xdb, 0x9e, 0x02, 0x63, 0xd3, 0x5c, 0x2c, 0x3a, 0xaa, 0x06, 0x97, 0x62, 0xb1, 0x95, 0x99,
-	0x98, 0x96, 0x41, 0x44, 0x3c, 0x4b, 0x58, 0x74, 0xc6, 0x8e, 0xc4, 0x60, 0x45, 0x87, 0xfd, 0xac,
-	0xc7, 0xe7, 0xca, 0xfd, 0x2b, 0x58, 0xf6, 0xd4, 0xdf, 0x15, 0xa0, 0x80, 0x89, 0xe7, 0x30, 0x4c,
-	0x40, 0x75, 0x50, 0xc8, 0x49, 0x97, 0x38, 0xd4, 0x87, 0xd1, 0xd9, 0xac, 0x41, 0x68, 0x37, 0x7d,
-	0x4d, 0x76, 0x64, 0x07, 0x66, 0xe8, 0x96, 0x64, 0x65, 0xf1, 0x04, 0x4b, 0x9a, 0x87, 0x69, 0xd9,
-	0x1d, 0x9f, 0x96, 0xa5, 0x63, 0x4f, 0x69, 0x61, 0x35, 0xc1, 0xcb, 0x6e, 0x49, 0x5e, 0x96, 0x99,
-	0xf3, 0x63, 0x11, 0x62, 0xd6, 0x88, 0x10, 0xb3, 0xec, 0x9c, 0x69, 0xc6, 0x30, 0xb3, 0x3b, 0x3e,
-	0x33, 0xcb, 0xcd, 0x19, 0xf1, 0x04, 0x35, 0xbb, 0x1f, 0xa5, 0x66, 0x82, 0x56, 0x5d, 0x8b, 0xb5,
-	0x8e, 0xe5, 0x66, 0x3f, 0x08, 0x71, 0xb3, 0x42, 0x2c, 0x31, 0x12, 0x4e, 0x66, 0x90, 0xb3, 0x46,
-	0x84, 0x9c, 0x29, 0x73, 0x62, 0x10, 0xc3, 0xce, 0x3e, 0x08, 0xb3, 0x33, 0x88, 0x25, 0x78, 0x72,
-	0xbd, 0x67, 0xd1, 0xb3, 0xf7, 0x02, 0x7a, 0x56, 0x8c, 0xe5, 0x97, 0x72, 0x0e, 0x93, 0xfc, 0x6c,
-	0x7f, 0x8a, 0x9f, 0x09, 0x3e, 0xf5, 0x46, 0xac, 0x8b, 0x39, 0x04, 0x6d, 0x7f, 0x8a, 0xa0, 0x95,
-	0xe6, 0x38, 0x9c, 0xc3, 0xd0, 0x7e, 0x31, 0x9b, 0xa1, 0xc5, 0x73, 0x28, 0x39, 0xcc, 0xc5, 0x28,
-	0x9a, 0x16, 0x43, 0xd1, 0x96, 0xb9, 0xfb, 0xb7, 0x62, 0xdd, 0x9f, 0x9f, 0xa3, 0xdd, 0x60, 0x27,
-	0xe4, 0x44, 0xce, 0x33, 0x94, 0x21, 0xae, 0x6b, 0xbb, 0x92, 0x6d, 0x89, 0x8e, 0xfa, 0x26, 0x3b,
-	0xb3, 0xc7, 0xf9, 0x7d, 0x06, 0x9f, 0xe3, 0x68, 0x1e, 0xca, 0x69, 0xf5, 0x0f, 0xc9, 0xb1, 0x2d,
-	0x3f, 0xe6, 0xc2, 0xe7, 0xbd, 0x22, 0xcf, 0xfb, 0x10, 0xcb, 0x4b, 0x45, 0x59, 0xde, 0x3a, 0x14,
-	0x19, 0x4a, 0x4f, 0x10, 0x38, 0xdd, 0x09, 0x08, 0xdc, 0x4d, 0x58, 0xe1, 0xc7, 0xb0, 0xe0, 0x82,
-	0x12, 0x9a, 0x33, 0xfc, 0x84, 0x59, 0x66, 0x1f, 0xc4, 0xe6, 0x14, 0x18, 0xfd, 0x0e, 0x5c, 0x08,
-	0xe9, 0x06, 0xe8, 0x2f, 0xd8, 0x4c, 0x25, 0xd0, 0xde, 0x96, 0xc7, 0xc0, 0x9f, 0x92, 0xe3, 0x08,
-	0x8d, 0x99, 0xdf, 0x2c, 0x92, 0x96, 0xfc, 0x1f, 0x91, 0xb4, 0xd4, 0x7f, 0x4d, 0xd2, 0xc2, 0xa7,
-	0x59, 0x3a, 0x7a, 0x9a, 0xfd, 0x2b, 0x39, 0x5e, 0x93, 0x80, 0x72, 0x75, 0x6d, 0x83, 0xc8, 0xf3,
-	0x85, 0xb7, 0x51, 0x05, 0xd2, 0x03, 0xbb, 0x27, 0x4f, 0x11, 0xd6, 0x64, 0x5a, 0x01, 0x08, 0x2b,
-	0x12, 0x63, 0x83, 0xa3, 0x29, 0xcb, 0x23, 0x2c, 0x8f, 0xa6, 0x0a, 0xa4, 0x9f, 0x10, 0x01, 0x99,
-	0x4b, 0x98, 0x35, 0x99, 0x1e, 0xdf, 0x64, 0x1c, 0x08, 0x97, 0xb0, 0xe8, 0xa0, 0xbb, 0xa0, 0xf0,
-	0x32, 0x84, 0x66, 0x3b, 0x9e, 0x44, 0xb7, 0xd7, 0xc2, 0x73, 0x15, 0xd5, 0x86, 0x8d, 0x03, 0xa6,
-	0xb3, 0xef, 0x78, 0xb8, 0xe0, 0xc8, 0x56, 0xe8, 0xd4, 0x55, 0x22, 0xe4, 0xef, 0x32, 0x28, 0x6c,
-	0xf4, 0x9e, 0xa3, 0x77, 0x09, 0x87, 0x2a, 0x05, 0x8f, 0x05, 0xea, 0x63, 0x40, 0xd3, 0x80, 0x8b,
-	0x5a, 0x90, 0x23, 0xc7, 0xc4, 0xa2, 0x6c, 0xd9, 0x58, 0xb8, 0x2f, 0xce, 0x60, 0x56, 0xc4, 0xa2,
-	0xf5, 0x2a, 0x0b, 0xf2, 0x3f, 0xbf, 0x59, 0xaf, 0x08, 0xed, 0xb7, 0xed, 0xa1, 0x49, 0xc9, 0xd0,
-	0xa1, 0xa7, 0x58, 0xda, 0xab, 0x7f, 0x4b, 0x31, 0x9a, 0x13, 0x01, 0xe3, 0x99, 0xb1, 0xf5, 0xb7,
-	0x7c, 0x2a, 0x44, 0x71, 0x17, 0x8b, 0xf7, 0x1a, 0x40, 0x4f, 0xf7, 0xb4, 0xa7, 0xba, 0x45, 0x89,
-	0x21, 0x83, 0x1e, 0x92, 0xa0, 0x1a, 0x14, 0x58, 0x6f, 0xe4, 0x11, 0x43, 0xb2, 0xed, 0xa0, 0x1f,
-	0x9a, 0x67, 0xfe, 0xdb, 0xcd, 0x33, 0x1a, 0xe5, 0xc2, 0x44, 0x94, 0x43, 0x14, 0x44, 0x09, 0x53,
-	0x10, 0x36, 0x36, 0xc7, 0x35, 0x6d, 0xd7, 0xa4, 0xa7, 0x7c, 0x69, 0xd2, 0x38, 0xe8, 0xb3, 0xcb,
-	0xdb, 0x90, 0x0c, 0x1d, 0xdb, 0x1e, 0x68, 0x02, 0x6e, 0x8a, 0xdc, 0x74, 0x49, 0x0a, 0x9b, 0x1c,
-	0x75, 0x7e, 0x9d, 0x1a, 0xe7, 0xdf, 0x98, 0x6a, 0xfe, 0xdf, 0x05, 0x58, 0xfd, 0x0d, 0xbf, 0x80,
-	0x46, 0x8f, 0x5b, 0x74, 0x08, 0x2b, 0x41, 0xfa, 0x6b, 0x23, 0x0e, 0x0b, 0xfe, 0x86, 0x5e, 0x14,
-	0x3f, 0x2a, 0xc7, 0x51, 0xb1, 0x87, 0x3e, 0x86, 0x57, 0x27, 0xb0, 0x2d, 0x70, 0x9d, 0x5a, 0x14,
-	0xe2, 0x5e, 0x89, 0x42, 0x9c, 0xef, 0x7a, 0x1c, 0xac, 0xf4, 0xb7, 0xcc, 0xba, 0x1d, 0x76, 0xa7,
-	0x09, 0xb3, 0x87, 0x99, 0xcb, 0x7f, 0x0d, 0x4a, 0x2e, 0xa1, 0xec, 0x9e, 0x1d, 0xb9, 0x35, 0x2e,
-	0x09, 0xa1, 0xbc, 0x8b, 0x1e, 0xc0, 0x2b, 0x33, 0x59, 0x04, 0xfa, 0x1e, 0x28, 0x63, 0x02, 0x92,
-	0x8c, 0xb9, 0x80, 0x05, 0x97, 0x8a, 0xb1, 0xae, 0xfa, 0xc7, 0xe4, 0xd8, 0x65, 0xf4, 0x9a, 0xd2,
-	0x84, 0x9c, 0x4b, 0xbc, 0xd1, 0x40, 0x5c, 0x1c, 0xca, 0x5b, 0xef, 0x2c, 0xc6, 0x3f, 0x98, 0x74,
-	0x34, 0xa0, 0x58, 0x1a, 0xab, 0x8f, 0x21, 0x27, 0x24, 0xa8, 0x08, 0xf9, 0x87, 0x7b, 0x0f, 0xf6,
-	0xf6, 0x3f, 0xda, 0xab, 0x24, 0x10, 0x40, 0x6e, 0xbb, 0xd1, 0x68, 0x1e, 0xb4, 0x2b, 0x49, 0xa4,
-	0x40, 0x76, 0xbb, 0xbe, 0x8f, 0xdb, 0x95, 0x14, 0x13, 0xe3, 0xe6, 0x87, 0xcd, 0x46, 0xbb, 0x92,
-	0x46, 0x2b, 0x50, 0x12, 0x6d, 0xed, 0xfe, 0x3e, 0xfe, 0xc9, 0x76, 0xbb, 0x92, 0x09, 0x89, 0x0e,
-	0x9b, 0x7b, 0xf7, 0x9a, 0xb8, 0x92, 0x55, 0xdf, 0x65, 0x37, 0x93, 0x18, 0xc6, 0x32, 0xbe, 0x83,
-	0x24, 0x43, 0x77, 0x10, 0xf5, 0xb7, 0x29, 0xa8, 0xc5, 0xd3, 0x10, 0xf4, 0xe1, 0xc4, 0xc4, 0xb7,
-	0xce, 0xc1, 0x61, 0x26, 0x66, 0x8f, 0xae, 0x43, 0xd9, 0x25, 0x47, 0x84, 0x76, 0xfb, 0x82, 0x16,
-	0x89, 0x23, 0xb3, 0x84, 0x4b, 0x52, 0xca, 0x8d, 0x3c, 0xa1, 0xf6, 0x19, 0xe9, 0x52, 0x4d, 0x60,
-	0x91, 0xd8, 0x74, 0x0a, 0x53, 0x63, 0xd2, 0x43, 0x21, 0x54, 0x3f, 0x3d, 0x57, 0x2c, 0x15, 0xc8,
-	0xe2, 0x66, 0x1b, 0x7f, 0x5c, 0x49, 0x23, 0x04, 0x65, 0xde, 0xd4, 0x0e, 0xf7, 0xb6, 0x0f, 0x0e,
-	0x5b, 0xfb, 0x2c, 0x96, 0x17, 0x60, 0xd9, 0x8f, 0xa5, 0x2f, 0xcc, 0xaa, 0x9f, 0x40, 0x39, 0x7a,
-	0xf7, 0x67, 0x21, 0x74, 0xed, 0x91, 0x65, 0xf0, 0x60, 0x64, 0xb1, 0xe8, 0xa0, 0xdb, 0x90, 0x3d,
-	0xb6, 0x45, 0x9a, 0xcd, 0xde, 0x6b, 0x8f, 0x6c, 0x4a, 0x42, 0xb5, 0x03, 0xa1, 0xad, 0x7e, 0x01,
-	0x59, 0x9e, 0x35, 0x2c, 0x03, 0xf8, 0x2d, 0x5e, 0x92, 0x2a, 0xd6, 0x46, 0x9f, 0x00, 0xe8, 0x94,
-	0xba, 0x66, 0x67, 0x34, 0x76, 0xbc, 0x3e, 0x3b, 0xeb, 0xb6, 0x7d, 0xbd, 0xfa, 0x65, 0x99, 0x7e,
-	0xab, 0x63, 0xd3, 0x50, 0x0a, 0x86, 0x1c, 0xaa, 0x7b, 0x50, 0x8e, 0xda, 0xfa, 0x34, 0x40, 0x8c,
-	0x21, 0x4a, 0x03, 0x04, 0xab, 0x93, 0x34, 0x20, 0x20, 0x11, 0x69, 0x51, 0xb1, 0xe1, 0x1d, 0xf5,
-	0x59, 0x12, 0x0a, 0xed, 0x13, 0xb9, 0x1e, 0x31, 0xc5, 0x82, 0xb1, 0x69, 0x2a, 0x7c, 0x35, 0x16,
-	0xd5, 0x87, 0x74, 0x50, 0xd3, 0xf8, 0x20, 0xd8, 0x71, 0x99, 0x45, 0x6f, 0x40, 0x7e, 0x71, 0x47,
-	0x66, 0xd9, 0xfb, 0xa0, 0x04, 0x98, 0xc9, 0xd8, 0xa9, 0x6e, 0x18, 0x2e, 0xf1, 0x3c, 0xb9, 0xef,
-	0xfd, 0x2e, 0xaf, 0x3d, 0xd9, 0x4f, 0xe5, 0xe5, 0x3b, 0x8d, 0x45, 0x47, 0x35, 0x60, 0x79, 0x02,
-	0x70, 0xd1, 0xfb, 0x90, 0x77, 0x46, 0x1d, 0xcd, 0x0f, 0xcf, 0xc4, 0x5b, 0x83, 0xcf, 0x7b, 0x46,
-	0x9d, 0x81, 0xd9, 0x7d, 0x40, 0x4e, 0xfd, 0xc1, 0x38, 0xa3, 0xce, 0x03, 0x11, 0x45, 0xf1, 0x2b,
-	0xa9, 0xf0, 0xaf, 0x1c, 0x43, 0xc1, 0xdf, 0x14, 0xe8, 0x87, 0xa0, 0x04, 0x58, 0x1e, 0x94, 0x24,
-	0x63, 0x0f, 0x01, 0xe9, 0x7e, 0x6c, 0xc2, 0x48, 0xb4, 0x67, 0xf6, 0x2c, 0x62, 0x68, 0x63, 0x7e,
-	0xcc, 0x7f, 0xad, 0x80, 0x97, 0xc5, 0x87, 0x5d, 0x9f, 0x1c, 0xab, 0xff, 0x4e, 0x42, 0xc1, 0x2f,
-	0x3d, 0xa1, 0x77, 0x43, 0xfb, 0xae, 0x3c, 0xe3, 0xa2, 0xee, 0x2b, 0x8e, 0xcb, 0x47, 0xd1, 0xb1,
-	0xa6, 0xce, 0x3f, 0xd6, 0xb8, 0x3a, 0xa0, 0x5f, 0x91, 0xcd, 0x9c, 0xbb, 0x22, 0xfb, 0x36, 0x20,
-	0x6a, 0x53, 0x7d, 0xa0, 0x1d, 0xdb, 0xd4, 0xb4, 0x7a, 0x9a, 0x08, 0xb6, 0xe0, 0x02, 0x15, 0xfe,
-	0xe5, 0x11, 0xff, 0x70, 0xc0, 0xe3, 0xfe, 0xcb, 0x24, 0x14, 0x02, 0x50, 0x3f, 0x6f, 0x35, 0xe8,
-	0x22, 0xe4, 0x24, 0x6e, 0x89, 0x72, 0x90, 0xec, 0x05, 0x85, 0xc9, 0x4c, 0xa8, 0x30, 0x59, 0x83,
-	0xc2, 0x90, 0x50, 0x9d, 0x9f, 0x6c, 0xe2, 0x8a, 0x12, 0xf4, 0x6f, 0xbe, 0x07, 0xc5, 0x50, 0x61,
-	0x8e, 0x65, 0xde, 0x5e, 0xf3, 0xa3, 0x4a, 0xa2, 0x96, 0x7f, 0xf6, 0xe5, 0xd5, 0xf4, 0x1e, 0x79,
-	0xca, 0xf6, 0x2c, 0x6e, 0x36, 0x5a, 0xcd, 0xc6, 0x83, 0x4a, 0xb2, 0x56, 0x7c, 0xf6, 0xe5, 0xd5,
-	0x3c, 0x26, 0xbc, 0x48, 0x70, 0xb3, 0x05, 0x4b, 0xe1, 0x55, 0x89, 0x42, 0x1f, 0x82, 0xf2, 0xbd,
-	0x87, 0x07, 0xbb, 0x3b, 0x8d, 0xed, 0x76, 0x53, 0x7b, 0xb4, 0xdf, 0x6e, 0x56, 0x92, 0xe8, 0x55,
-	0xb8, 0xb0, 0xbb, 0xf3, 0xe3, 0x56, 0x5b, 0x6b, 0xec, 0xee, 0x34, 0xf7, 0xda, 0xda, 0x76, 0xbb,
-	0xbd, 0xdd, 0x78, 0x50, 0x49, 0x6d, 0xfd, 0x5e, 0x81, 0xe5, 0xed, 0x7a, 0x63, 0x87, 0xc1, 0xb6,
-	0xd9, 0xd5, 0xf9, 0xfd, 0xb1, 0x01, 0x19, 0x7e, 0x43, 0x3c, 0xf3, 0xd9, 0xae, 0x76, 0x76, 0xf9,
-	0x08, 0xdd, 0x87, 0x2c, 0xbf, 0x3c, 0xa2, 0xb3, 0xdf, 0xf1, 0x6a, 0x73, 0xea, 0x49, 0x6c, 0x30,
-	0x3c, 0x3d, 0xce, 0x7c, 0xd8, 0xab, 0x9d, 0x5d, 0x5e, 0x42, 0x18, 0x94, 0x31, 0xf9, 0x9c, 0xff,
-	0xd0, 0x55, 0x5b, 0x00, 0x6c, 0xd0, 0x2e, 0xe4, 0xfd, 0xfb, 0xc2, 0xbc, 0xa7, 0xb7, 0xda, 0xdc,
-	0xfa, 0x0f, 0x0b, 0x97, 0xb8, 0xd7, 0x9d, 0xfd, 0x8e, 0x58, 0x9b, 0x53, 0xcc, 0x42, 0x3b, 0x90,
-	0x93, 0x84, 0x6a, 0xce, 0x73, 0x5a, 0x6d, 0x5e, 0x3d, 0x87, 0x05, 0x6d, 0x7c, 0x63, 0x9e, 0xff,
-	0x3a, 0x5a, 0x5b, 0xa0, 0x4e, 0x87, 0x1e, 0x02, 0x84, 0x6e, 0x71, 0x0b, 0x3c, 0x7b, 0xd6, 0x16,
-	0xa9, 0xbf, 0xa1, 0x7d, 0x28, 0x04, 0xa4, 0x7a, 0xee, 0x23, 0x64, 0x6d, 0x7e, 0x21, 0x0c, 0x3d,
-	0x86, 0x52, 0x94, 0x4c, 0x2e, 0xf6, 0xb4, 0x58, 0x5b, 0xb0, 0xc2, 0xc5, 0xfc, 0x47, 0x99, 0xe5,
-	0x62, 0x4f, 0x8d, 0xb5, 0x05, 0x0b, 0x5e, 0xe8, 0x33, 0x58, 0x99, 0x66, 0x7e, 0x8b, 0xbf, 0x3c,
-	0xd6, 0xce, 0x51, 0x02, 0x43, 0x43, 0x40, 0x33, 0x18, 0xe3, 0x39, 0x1e, 0x22, 0x6b, 0xe7, 0xa9,
-	0x88, 0xd5, 0x9b, 0x5f, 0xbd, 0x58, 0x4b, 0x7e, 0xfd, 0x62, 0x2d, 0xf9, 0x8f, 0x17, 0x6b, 0xc9,
-	0xe7, 0x2f, 0xd7, 0x12, 0x5f, 0xbf, 0x5c, 0x4b, 0xfc, 0xf5, 0xe5, 0x5a, 0xe2, 0x67, 0x6f, 0xf5,
-	0x4c, 0xda, 0x1f, 0x75, 0x36, 0xba, 0xf6, 0x70, 0x33, 0xfc, 0x0f, 0x87, 0x59, 0xff, 0xba, 0xe8,
-	0xe4, 0xf8, 0xa1, 0x72, 0xeb, 0x3f, 0x01, 0x00, 0x00, 0xff, 0xff, 0x92, 0xa5, 0x39, 0xcc, 0x95,
-	0x21, 0x00, 0x00,
+	// 2629 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0xe4, 0x5a, 0xbd, 0x73, 0x1b, 0xc7,
+	0x15, 0xc7, 0x37, 0x70, 0x0f, 0x9f, 0x5c, 0xd1, 0x32, 0x04, 0xcb, 0xa4, 0x7c, 0x1e, 0x3b, 0xb6,
+	0x6c, 0x93, 0x31, 0x3d, 0x76, 0xec, 0x71, 0x3e, 0x4c, 0x40, 0x50, 0x40, 0x8b, 0x21, 0x99, 0x25,
+	0x24, 0x8f, 0x93, 0x58, 0xe7, 0x03, 0x6e, 0x09, 0x9c, 0x05, 0xdc, 0x9d, 0xef, 0x0e, 0x14, 0xe9,
+	0x32, 0x93, 0x34, 0x9a, 0x14, 0x2a, 0xd3, 0x78, 0x26, 0xff, 0x41, 0xda, 0x54, 0xa9, 0x52, 0xb8,
+	0x48, 0x66, 0x5c, 0xa6, 0x72, 0x32, 0x52, 0x97, 0x2a, 0x5d, 0xaa, 0xcc, 0x64, 0xf6, 0xeb, 0x70,
+	0x07, 0xe0, 0x08, 0x30, 0x4e, 0x97, 0x6e, 0xdf, 0xc3, 0x7b, 0xef, 0x76, 0xdf, 0xee, 0xfe, 0xf6,
+	0xb7, 0x6f, 0x01, 0xcf, 0xf9, 0xc4, 0x32, 0x88, 0x3b, 0x36, 0x2d, 0x7f, 0x5b, 0xef, 0xf5, 0xcd,
+	0x6d, 0xff, 0xdc, 0x21, 0xde, 0x96, 0xe3, 0xda, 0xbe, 0x8d, 0xaa, 0xd3, 0x1f, 0xb7, 0xe8, 0x8f,
+	0x8d, 0xe7, 0x43, 0xd6, 0x7d, 0xf7, 0xdc, 0xf1, 0xed, 0x6d, 0xc7, 0xb5, 0xed, 0x13, 0x6e, 0xdf,
+	0xb8, 0x1e, 0xfa, 0x99, 0xc5, 0x09, 0x47, 0x6b, 0x5c, 0x9f, 0x77, 0x7e, 0x40, 0xce, 0xe5, 0xaf,
+	0xcf, 0xcf, 0xf9, 0x3a, 0xba, 0xab, 0x8f, 0xe5, 0xcf, 0x9b, 0x03, 0xdb, 0x1e, 0x8c, 0xc8, 0x36,
+	0x93, 0x7a, 0x93, 0x93, 0x6d, 0xdf, 0x1c, 0x13, 0xcf, 0xd7, 0xc7, 0x8e, 0x30, 0x58, 0x1f, 0xd8,
+	0x03, 0x9b, 0x35, 0xb7, 0x69, 0x8b, 0x6b, 0xd5, 0xbf, 0xe4, 0x21, 0x8f, 0xc9, 0xe7, 0x13, 0xe2,
+	0xf9, 0x68, 0x07, 0x32, 0xa4, 0x3f, 0xb4, 0xeb, 0xc9, 0x1b, 0xc9, 0x57, 0x8a, 0x3b, 0xd7, 0xb7,
+	0x66, 0x06, 0xb7, 0x25, 0xec, 0xda, 0xfd, 0xa1, 0xdd, 0x49, 0x60, 0x66, 0x8b, 0xde, 0x86, 0xec,
+	0xc9, 0x68, 0xe2, 0x0d, 0xeb, 0x29, 0xe6, 0xf4, 0x7c, 0x9c, 0xd3, 0x6d, 0x6a, 0xd4, 0x49, 0x60,
+	0x6e, 0x4d, 0x3f, 0x65, 0x5a, 0x27, 0x76, 0x3d, 0x7d, 0xf1, 0xa7, 0xf6, 0xac, 0x13, 0xf6, 0x29,
+	0x6a, 0x8b, 0x9a, 0x00, 0xa6, 0x65, 0xfa, 0x5a, 0x7f, 0xa8, 0x9b, 0x56, 0x3d, 0xc3, 0x3c, 0x5f,
+	0x88, 0xf7, 0x34, 0xfd, 0x16, 0x35, 0xec, 0x24, 0xb0, 0x62, 0x4a, 0x81, 0x76, 0xf7, 0xf3, 0x09,
+	0x71, 0xcf, 0xeb, 0xd9, 0x8b, 0xbb, 0xfb, 0x53, 0x6a, 0x44, 0xbb, 0xcb, 0xac, 0x51, 0x1b, 0x8a,
+	0x3d, 0x32, 0x30, 0x2d, 0xad, 0x37, 0xb2, 0xfb, 0x0f, 0xea, 0x39, 0xe6, 0xac, 0xc6, 0x39, 0x37,
+	0xa9, 0x69, 0x93, 0x5a, 0x76, 0x12, 0x18, 0x7a, 0x81, 0x84, 0xbe, 0x0f, 0x85, 0xfe, 0x90, 0xf4,
+	0x1f, 0x68, 0xfe, 0x59, 0x3d, 0xcf, 0x62, 0x6c, 0xc6, 0xc5, 0x68, 0x51, 0xbb, 0xee, 0x59, 0x27,
+	0x81, 0xf3, 0x7d, 0xde, 0xa4, 0xe3, 0x37, 0xc8, 0xc8, 0x3c, 0x25, 0x2e, 0xf5, 0x2f, 0x5c, 0x3c,
+	0xfe, 0x5b, 0xdc, 0x92, 0x45, 0x50, 0x0c, 0x29, 0xa0, 0x1f, 0x81, 0x42, 0x2c, 0x43, 0x0c, 0x43,
+	0x61, 0x21, 0x6e, 0xc4, 0xce, 0xb3, 0x65, 0xc8, 0x41, 0x14, 0x88, 0x68, 0xa3, 0x77, 0x21, 0xd7,
+	0xb7, 0xc7, 0x63, 0xd3, 0xaf, 0x03, 0xf3, 0xde, 0x88, 0x1d, 0x00, 0xb3, 0xea, 0x24, 0xb0, 0xb0,
+	0x47, 0x07, 0x50, 0x19, 0x99, 0x9e, 0xaf, 0x79, 0x96, 0xee, 0x78, 0x43, 0xdb, 0xf7, 0xea, 0x45,
+	0x16, 0xe1, 0xa5, 0xb8, 0x08, 0xfb, 0xa6, 0xe7, 0x1f, 0x4b, 0xe3, 0x4e, 0x02, 0x97, 0x47, 0x61,
+	0x05, 0x8d, 0x67, 0x9f, 0x9c, 0x10, 0x37, 0x08, 0x58, 0x2f, 0x5d, 0x1c, 0xef, 0x90, 0x5a, 0x4b,
+	0x7f, 0x1a, 0xcf, 0x0e, 0x2b, 0xd0, 0xcf, 0xe1, 0xca, 0xc8, 0xd6, 0x8d, 0x20, 0x9c, 0xd6, 0x1f,
+	0x4e, 0xac, 0x07, 0xf5, 0x32, 0x0b, 0xfa, 0x6a, 0x6c, 0x27, 0x6d, 0xdd, 0x90, 0x21, 0x5a, 0xd4,
+	0xa1, 0x93, 0xc0, 0x6b, 0xa3, 0x59, 0x25, 0xba, 0x0f, 0xeb, 0xba, 0xe3, 0x8c, 0xce, 0x67, 0xa3,
+	0x57, 0x58, 0xf4, 0x9b, 0x71, 0xd1, 0x77, 0xa9, 0xcf, 0x6c, 0x78, 0xa4, 0xcf, 0x69, 0x9b, 0x79,
+	0xc8, 0x9e, 0xea, 0xa3, 0x09, 0x51, 0xbf, 0x03, 0xc5, 0xd0, 0x36, 0x45, 0x75, 0xc8, 0x8f, 0x89,
+	0xe7, 0xe9, 0x03, 0xc2, 0x76, 0xb5, 0x82, 0xa5, 0xa8, 0x56, 0xa0, 0x14, 0xde, 0x9a, 0xea, 0xe3,
+	0x24, 0x14, 0x43, 0xbb, 0x8e, 0x7a, 0x9e, 0x12, 0xd7, 0x33, 0x6d, 0x4b, 0x7a, 0x0a, 0x11, 0xbd,
+	0x08, 0x65, 0xb6, 0x7e, 0x34, 0xf9, 0x3b, 0xdd, 0xfa, 0x19, 0x5c, 0x62, 0xca, 0x7b, 0xc2, 0x68,
+	0x13, 0x8a, 0xce, 0x8e, 0x13, 0x98, 0xa4, 0x99, 0x09, 0x38, 0x3b, 0x8e, 0x34, 0x78, 0x01, 0x4a,
+	0x74, 0xa4, 0x81, 0x45, 0x86, 0x7d, 0xa4, 0x48, 0x75, 0xc2, 0x44, 0xfd, 0x73, 0x0a, 0x6a, 0xb3,
+	0xdb, 0x19, 0xbd, 0x0b, 0x19, 0x8a, 0x6c, 0x02, 0xa4, 0x1a, 0x5b, 0x1c, 0xf6, 0xb6, 0x24, 0xec,
+	0x6d, 0x75, 0x25, 0xec, 0x35, 0x0b, 0x5f, 0x7d, 0xb3, 0x99, 0x78, 0xfc, 0xb7, 0xcd, 0x24, 0x66,
+	0x1e, 0xe8, 0x1a, 0xdd, 0x7d, 0xba, 0x69, 0x69, 0xa6, 0xc1, 0xba, 0xac, 0xd0, 0xad, 0xa5, 0x9b,
+	0xd6, 0x9e, 0x81, 0xf6, 0xa1, 0xd6, 0xb7, 0x2d, 0x8f, 0x58, 0xde, 0xc4, 0xd3, 0x38, 0xac, 0xd6,
+	0xd3, 0xf3, 0x1b, 0x8c, 0x83, 0x75, 0x4b, 0x5a, 0x1e, 0x31, 0x43, 0x5c, 0xed, 0x47, 0x15, 0xe8,
+	0x36, 0xc0, 0xa9, 0x3e, 0x32, 0x0d, 0xdd, 0xb7, 0x5d, 0xaf, 0x9e, 0xb9, 0x91, 0x5e, 0xb8, 0xcb,
+	0xee, 0x49, 0x93, 0xbb, 0x8e, 0xa1, 0xfb, 0xa4, 0x99, 0xa1, 0xdd, 0xc5, 0x21, 0x4f, 0xf4, 0x32,
+	0x54, 0x75, 0xc7, 0xd1, 0x3c, 0x5f, 0xf7, 0x89, 0xd6, 0x3b, 0xf7, 0x89, 0xc7, 0x60, 0xab, 0x84,
+	0xcb, 0xba, 0xe3, 0x1c, 0x53, 0x6d, 0x93, 0x2a, 0xd1, 0x4b, 0x50, 0xa1, 0x08, 0x67, 0xea, 0x23,
+	0x6d, 0x48, 0xcc, 0xc1, 0xd0, 0x67, 0x00, 0x95, 0xc6, 0x65, 0xa1, 0xed, 0x30, 0xa5, 0x6a, 0x40,
+	0x29, 0x8c, 0x6e, 0x08, 0x41, 0xc6, 0xd0, 0x7d, 0x9d, 0x65, 0xb2, 0x84, 0x59, 0x9b, 0xea, 0x1c,
+	0xdd, 0x1f, 0x8a, 0xfc, 0xb0, 0x36, 0xba, 0x0a, 0x39, 0x11, 0x36, 0xcd, 0xc2, 0x0a, 0x09, 0xad,
+	0x43, 0xd6, 0x71, 0xed, 0x53, 0xc2, 0xa6, 0xae, 0x80, 0xb9, 0xa0, 0xfe, 0x2a, 0x05, 0x6b, 0x73,
+	0x38, 0x48, 0xe3, 0x0e, 0x75, 0x6f, 0x28, 0xbf, 0x45, 0xdb, 0xe8, 0x1d, 0x1a, 0x57, 0x37, 0x88,
+	0x2b, 0xce, 0x8e, 0xfa, 0x7c, 0xaa, 0x3b, 0xec, 0x77, 0x91, 0x1a, 0x61, 0x8d, 0x0e, 0xa1, 0x36,
+	0xd2, 0x3d, 0x5f, 0xe3, 0xb8, 0xa2, 0x85, 0xce, 0x91, 0x79, 0x34, 0xdd, 0xd7, 0x25, 0x12, 0xd1,
+	0x45, 0x2d, 0x02, 0x55, 0x46, 0x11, 0x2d, 0xc2, 0xb0, 0xde, 0x3b, 0xff, 0x42, 0xb7, 0x7c, 0xd3,
+	0x22, 0xda, 0xdc, 0xcc, 0x5d, 0x9b, 0x0b, 0xda, 0x3e, 0x35, 0x0d, 0x62, 0xf5, 0xe5, 0x94, 0x5d,
+	0x09, 0x9c, 0x83, 0x29, 0xf5, 0x54, 0x0c, 0x95, 0x28, 0x92, 0xa3, 0x0a, 0xa4, 0xfc, 0x33, 0x91,
+	0x80, 0x94, 0x7f, 0x86, 0xbe, 0x0b, 0x19, 0x3a, 0x48, 0x36, 0xf8, 0xca, 0x82, 0x23, 0x50, 0xf8,
+	0x75, 0xcf, 0x1d, 0x82, 0x99, 0xa5, 0xaa, 0x42, 0x6d, 0x16, 0xdd, 0x67, 0xa3, 0xaa, 0xaf, 0x42,
+	0x75, 0x06, 0xbe, 0x43, 0xf3, 0x97, 0x0c, 0xcf, 0x9f, 0x5a, 0x85, 0x72, 0x04, 0xab, 0xd5, 0xab,
+	0xb0, 0xbe, 0x08, 0x7a, 0xd5, 0x21, 0xac, 0x2f, 0x82, 0x50, 0xf4, 0x36, 0x14, 0x02, 0xec, 0xe5,
+	0xdb, 0x71, 0x3e, 0x57, 0xd2, 0x18, 0x07, 0xa6, 0x74, 0x1f, 0xd2, 0x65, 0xcd, 0xd6, 0x43, 0x8a,
+	0x75, 0x3c, 0xaf, 0x3b, 0x4e, 0x47, 0xf7, 0x86, 0xea, 0xa7, 0x50, 0x8f, 0xc3, 0xd5, 0x99, 0x61,
+	0x64, 0x82, 0x65, 0x78, 0x15, 0x72, 0x27, 0xb6, 0x3b, 0xd6, 0x7d, 0x16, 0xac, 0x8c, 0x85, 0x44,
+	0x97, 0x27, 0xc7, 0xd8, 0x34, 0x53, 0x73, 0x41, 0xd5, 0xe0, 0x5a, 0x2c, 0xb6, 0x52, 0x17, 0xd3,
+	0x32, 0x08, 0xcf, 0x67, 0x19, 0x73, 0x61, 0x1a, 0x88, 0x77, 0x96, 0x0b, 0xf4, 0xb3, 0x1e, 0x1b,
+	0x2b, 0x8b, 0xaf, 0x60, 0x21, 0xa9, 0xbf, 0x2b, 0x40, 0x01, 0x13, 0xcf, 0xa1, 0x98, 0x80, 0x9a,
+	0xa0, 0x90, 0xb3, 0x3e, 0x71, 0x7c, 0x09, 0xa3, 0x8b, 0x59, 0x03, 0xb7, 0x6e, 0x4b, 0x4b, 0x7a,
+	0x64, 0x07, 0x6e, 0xe8, 0x2d, 0xc1, 0xca, 0xe2, 0x09, 0x96, 0x70, 0x0f, 0xd3, 0xb2, 0x77, 0x24,
+	0x2d, 0x4b, 0xc7, 0x9e, 0xd2, 0xdc, 0x6b, 0x86, 0x97, 0xbd, 0x25, 0x78, 0x59, 0x66, 0xc9, 0xc7,
+	0x22, 0xc4, 0xac, 0x15, 0x21, 0x66, 0xd9, 0x25, 0xc3, 0x8c, 0x61, 0x66, 0xef, 0x48, 0x66, 0x96,
+	0x5b, 0xd2, 0xe3, 0x19, 0x6a, 0x76, 0x3b, 0x4a, 0xcd, 0x38, 0xad, 0x7a, 0x31, 0xd6, 0x3b, 0x96,
+	0x9b, 0xfd, 0x20, 0xc4, 0xcd, 0x0a, 0xb1, 0xc4, 0x88, 0x07, 0x59, 0x40, 0xce, 0x5a, 0x11, 0x72,
+	0xa6, 0x2c, 0xc9, 0x41, 0x0c, 0x3b, 0xfb, 0x20, 0xcc, 0xce, 0x20, 0x96, 0xe0, 0x89, 0xf9, 0x5e,
+	0x44, 0xcf, 0xde, 0x0b, 0xe8, 0x59, 0x31, 0x96, 0x5f, 0x8a, 0x31, 0xcc, 0xf2, 0xb3, 0xc3, 0x39,
+	0x7e, 0xc6, 0xf9, 0xd4, 0xcb, 0xb1, 0x21, 0x96, 0x10, 0xb4, 0xc3, 0x39, 0x82, 0x56, 0x5e, 0x12,
+	0x70, 0x09, 0x43, 0xfb, 0xc5, 0x62, 0x86, 0x16, 0xcf, 0xa1, 0x44, 0x37, 0x57, 0xa3, 0x68, 0x5a,
+	0x0c, 0x45, 0xab, 0xb2, 0xf0, 0xaf, 0xc5, 0x86, 0xbf, 0x3c, 0x47, 0x7b, 0x15, 0xd6, 0xa4, 0x73,
+	0xb0, 0xe7, 0x29, 0xca, 0x10, 0xd7, 0xb5, 0x5d, 0xc1, 0xb6, 0xb8, 0xa0, 0xbe, 0x02, 0xa5, 0xc0,
+	0xf4, 0x62, 0x3e, 0xc7, 0xd0, 0x3c, 0xb4, 0xa7, 0xd5, 0x3f, 0x24, 0xa1, 0x14, 0xde, 0xae, 0x91,
+	0xf3, 0x5e, 0x11, 0xe7, 0x7d, 0x88, 0xe5, 0xa5, 0xa2, 0x2c, 0x6f, 0x13, 0x8a, 0x14, 0xa5, 0x67,
+	0x08, 0x9c, 0xee, 0x04, 0x04, 0xee, 0x26, 0xac, 0xb1, 0x63, 0x98, 0x73, 0x41, 0x01, 0xcd, 0x19,
+	0x76, 0xc2, 0x54, 0xe9, 0x0f, 0x7c, 0x71, 0x32, 0x35, 0x7a, 0x03, 0xae, 0x84, 0x6c, 0x03, 0xf4,
+	0xe7, 0x6c, 0xa6, 0x16, 0x58, 0xef, 0x8a, 0x63, 0xe0, 0x4f, 0x49, 0x58, 0x9b, 0x83, 0x8b, 0x85,
+	0x24, 0x2d, 0xf9, 0x3f, 0x22, 0x69, 0xa9, 0xff, 0x9a, 0xa4, 0x85, 0x4f, 0xb3, 0x74, 0xf4, 0x34,
+	0xfb, 0x57, 0x12, 0xca, 0x11, 0xd4, 0xa2, 0x53, 0xd0, 0xb7, 0x0d, 0x22, 0xce, 0x17, 0xd6, 0x46,
+	0x35, 0x48, 0x8f, 0xec, 0x81, 0x38, 0x45, 0x68, 0x93, 0x5a, 0x05, 0x20, 0xac, 0x08, 0x8c, 0x0d,
+	0x8e, 0xa6, 0x2c, 0xcb, 0x30, 0x17, 0xa8, 0xef, 0x03, 0xc2, 0x21, 0xb3, 0x84, 0x69, 0x13, 0xad,
+	0x8b, 0x45, 0xc6, 0x80, 0xb0, 0x84, 0xb9, 0x80, 0xde, 0x05, 0x85, 0x95, 0x21, 0x34, 0xdb, 0xf1,
+	0x04, 0xba, 0x3d, 0x17, 0x1e, 0x2b, 0xaf, 0x36, 0x6c, 0x1d, 0x51, 0x9b, 0x43, 0xc7, 0xc3, 0x05,
+	0x47, 0xb4, 0x42, 0xa7, 0xae, 0x12, 0x21, 0x7f, 0xd7, 0x41, 0xa1, 0xbd, 0xf7, 0x1c, 0xbd, 0x4f,
+	0x18, 0x54, 0x29, 0x78, 0xaa, 0x50, 0xef, 0x03, 0x9a, 0x07, 0x5c, 0xd4, 0x81, 0x1c, 0x39, 0x25,
+	0x96, 0x4f, 0xa7, 0x8d, 0xa6, 0xfb, 0xea, 0x02, 0x66, 0x45, 0x2c, 0xbf, 0x59, 0xa7, 0x49, 0xfe,
+	0xc7, 0x37, 0x9b, 0x35, 0x6e, 0xfd, 0xba, 0x3d, 0x36, 0x7d, 0x32, 0x76, 0xfc, 0x73, 0x2c, 0xfc,
+	0xd5, 0x7f, 0xa6, 0xa0, 0x2a, 0x3f, 0x20, 0xf9, 0xd5, 0xa2, 0xdc, 0xca, 0x25, 0x9f, 0x0a, 0x51,
+	0xdc, 0xd5, 0xf2, 0xbd, 0x01, 0x30, 0xd0, 0x3d, 0xed, 0xa1, 0x6e, 0xf9, 0xc4, 0x10, 0x49, 0x0f,
+	0x69, 0x50, 0x03, 0x0a, 0x54, 0x9a, 0x78, 0xc4, 0x10, 0x6c, 0x3b, 0x90, 0x43, 0xe3, 0xcc, 0x7f,
+	0xbb, 0x71, 0x46, 0xb3, 0x5c, 0x98, 0xc9, 0x72, 0x88, 0x82, 0x28, 0x61, 0x0a, 0x42, 0xfb, 0xe6,
+	0xb8, 0xa6, 0xed, 0x9a, 0xfe, 0x39, 0x9b, 0x9a, 0x34, 0x0e, 0x64, 0x7a, 0x79, 0x1b, 0x93, 0xb1,
+	0x63, 0xdb, 0x23, 0x8d, 0xc3, 0x4d, 0x91, 0xb9, 0x96, 0x84, 0xb2, 0x4d, 0x75, 0x34, 0x21, 0x23,
+	0xdd, 0x22, 0xec, 0x00, 0x50, 0x30, 0x6b, 0xab, 0xbf, 0x4e, 0xc1, 0xda, 0xdc, 0xf1, 0xf5, 0xff,
+	0x97, 0x74, 0xf5, 0x37, 0xec, 0x52, 0x1a, 0x3d, 0x82, 0xd1, 0x31, 0xac, 0x05, 0x90, 0xa0, 0x4d,
+	0x18, 0x54, 0xc8, 0x45, 0xbe, 0x2a, 0xa6, 0xd4, 0x4e, 0xa3, 0x6a, 0x0f, 0x7d, 0x0c, 0xcf, 0xce,
+	0xe0, 0x5d, 0x10, 0x3a, 0xb5, 0x2a, 0xec, 0x3d, 0x13, 0x85, 0x3d, 0x19, 0x7a, 0x9a, 0xac, 0xf4,
+	0xb7, 0xdc, 0x89, 0x7b, 0x50, 0x91, 0xd9, 0xe0, 0x8c, 0x62, 0xe1, 0xf4, 0xbf, 0x08, 0x65, 0x97,
+	0xf8, 0xf4, 0xee, 0x1d, 0xb9, 0x49, 0x96, 0xb8, 0x52, 0xdc, 0x4f, 0x8f, 0xe0, 0x99, 0x85, 0xcc,
+	0x02, 0x7d, 0x0f, 0x94, 0x29, 0x29, 0x49, 0xc6, 0x5c, 0xca, 0xa4, 0x39, 0x9e, 0xda, 0xaa, 0x7f,
+	0x4c, 0xc2, 0x33, 0x0b, 0xb9, 0x05, 0x6a, 0x43, 0xce, 0x25, 0xde, 0x64, 0xc4, 0x2f, 0x13, 0x95,
+	0x9d, 0x37, 0x56, 0xe3, 0x24, 0x54, 0x3b, 0x19, 0xf9, 0x58, 0x38, 0xab, 0xf7, 0x21, 0xc7, 0x35,
+	0xa8, 0x08, 0xf9, 0xbb, 0x07, 0x77, 0x0e, 0x0e, 0x3f, 0x3a, 0xa8, 0x25, 0x10, 0x40, 0x6e, 0xb7,
+	0xd5, 0x6a, 0x1f, 0x75, 0x6b, 0x49, 0xa4, 0x40, 0x76, 0xb7, 0x79, 0x88, 0xbb, 0xb5, 0x14, 0x55,
+	0xe3, 0xf6, 0x87, 0xed, 0x56, 0xb7, 0x96, 0x46, 0x6b, 0x50, 0xe6, 0x6d, 0xed, 0xf6, 0x21, 0xfe,
+	0xc9, 0x6e, 0xb7, 0x96, 0x09, 0xa9, 0x8e, 0xdb, 0x07, 0xb7, 0xda, 0xb8, 0x96, 0x55, 0xdf, 0x84,
+	0x6b, 0xb2, 0x1f, 0xf3, 0x17, 0xa2, 0xe0, 0x5e, 0x92, 0x0c, 0xdd, 0x4b, 0xd4, 0xdf, 0xa6, 0xa0,
+	0x11, 0x4f, 0x4d, 0xd0, 0x87, 0x33, 0x03, 0xdf, 0xb9, 0x04, 0xaf, 0x99, 0x19, 0x3d, 0xad, 0x3b,
+	0xb8, 0xe4, 0x84, 0xf8, 0xfd, 0x21, 0xa7, 0x4a, 0xfc, 0x18, 0x2d, 0xe3, 0xb2, 0xd0, 0x32, 0x27,
+	0x8f, 0x9b, 0x7d, 0x46, 0xfa, 0xbe, 0xc6, 0xf1, 0x89, 0x2f, 0x3a, 0x05, 0x97, 0xb9, 0xf6, 0x98,
+	0x2b, 0xd5, 0x4f, 0x2f, 0x95, 0x4b, 0x05, 0xb2, 0xb8, 0xdd, 0xc5, 0x1f, 0xd7, 0xd2, 0x08, 0x41,
+	0x85, 0x35, 0xb5, 0xe3, 0x83, 0xdd, 0xa3, 0xe3, 0xce, 0x21, 0xcd, 0xe5, 0x15, 0xa8, 0xca, 0x5c,
+	0x4a, 0x65, 0x56, 0xfd, 0x04, 0x2a, 0xd1, 0x7a, 0x00, 0x4d, 0xa1, 0x6b, 0x4f, 0x2c, 0x83, 0x25,
+	0x23, 0x8b, 0xb9, 0x40, 0x8b, 0xc4, 0xa7, 0x36, 0xdf, 0x66, 0x8b, 0xd7, 0xda, 0x3d, 0xdb, 0x27,
+	0xa1, 0x7a, 0x02, 0xb7, 0x56, 0xbf, 0x80, 0x2c, 0xdb, 0x35, 0x74, 0x07, 0xb0, 0x9b, 0xbd, 0x20,
+	0x5a, 0xb4, 0x8d, 0x3e, 0x01, 0xd0, 0x7d, 0xdf, 0x35, 0x7b, 0x93, 0x69, 0xe0, 0xcd, 0xc5, 0xbb,
+	0x6e, 0x57, 0xda, 0x35, 0xaf, 0x8b, 0xed, 0xb7, 0x3e, 0x75, 0x0d, 0x6d, 0xc1, 0x50, 0x40, 0xf5,
+	0x00, 0x2a, 0x51, 0x5f, 0x49, 0x0d, 0x78, 0x1f, 0xa2, 0xd4, 0x80, 0x33, 0x3d, 0x2e, 0x4c, 0x89,
+	0x45, 0x9a, 0x57, 0x71, 0x98, 0xa0, 0x3e, 0x4a, 0x42, 0xa1, 0x7b, 0x26, 0xe6, 0x23, 0xa6, 0x80,
+	0x30, 0x75, 0x4d, 0x85, 0xaf, 0xcb, 0xbc, 0x22, 0x91, 0x0e, 0xea, 0x1c, 0x1f, 0x04, 0x2b, 0x2e,
+	0xb3, 0xea, 0xad, 0x48, 0x16, 0x7c, 0xc4, 0x2e, 0x7b, 0x1f, 0x94, 0x00, 0x33, 0x29, 0x63, 0xd5,
+	0x0d, 0xc3, 0x25, 0x9e, 0x27, 0xd6, 0xbd, 0x14, 0x69, 0x77, 0x1c, 0xfb, 0xa1, 0xb8, 0x90, 0xa7,
+	0x31, 0x17, 0x54, 0x03, 0xaa, 0x33, 0x80, 0x8b, 0xde, 0x87, 0xbc, 0x33, 0xe9, 0x69, 0x32, 0x3d,
+	0x33, 0xef, 0x0f, 0x92, 0x0b, 0x4d, 0x7a, 0x23, 0xb3, 0x7f, 0x87, 0x9c, 0xcb, 0xce, 0x38, 0x93,
+	0xde, 0x1d, 0x9e, 0x45, 0xfe, 0x95, 0x54, 0xf8, 0x2b, 0xa7, 0x50, 0x90, 0x8b, 0x02, 0xfd, 0x10,
+	0x94, 0x00, 0xcb, 0x83, 0x32, 0x65, 0xec, 0x21, 0x20, 0xc2, 0x4f, 0x5d, 0x28, 0xb1, 0xf6, 0xcc,
+	0x81, 0x45, 0x0c, 0x6d, 0xca, 0x99, 0xd9, 0xd7, 0x0a, 0xb8, 0xca, 0x7f, 0xd8, 0x97, 0x84, 0x59,
+	0xfd, 0x77, 0x12, 0x0a, 0xb2, 0x1c, 0x85, 0xde, 0x0c, 0xad, 0xbb, 0xca, 0x82, 0xcb, 0xbb, 0x34,
+	0x9c, 0x96, 0x94, 0xa2, 0x7d, 0x4d, 0x5d, 0xbe, 0xaf, 0x71, 0xb5, 0x41, 0x59, 0xa5, 0xcd, 0x5c,
+	0xba, 0x4a, 0xfb, 0x3a, 0x20, 0xdf, 0xf6, 0xf5, 0x91, 0x76, 0x6a, 0xfb, 0xa6, 0x35, 0xd0, 0x78,
+	0xb2, 0x39, 0x17, 0xa8, 0xb1, 0x5f, 0xee, 0xb1, 0x1f, 0x8e, 0x58, 0xde, 0x7f, 0x99, 0x84, 0x42,
+	0x00, 0xea, 0x97, 0xad, 0x10, 0x5d, 0x85, 0x9c, 0xc0, 0x2d, 0x5e, 0x22, 0x12, 0x52, 0x50, 0xac,
+	0xcc, 0x84, 0x8a, 0x95, 0x0d, 0x28, 0x8c, 0x89, 0xaf, 0xb3, 0x93, 0x8d, 0x5f, 0x5b, 0x02, 0xf9,
+	0xe6, 0x7b, 0x50, 0x0c, 0x15, 0xeb, 0xe8, 0xce, 0x3b, 0x68, 0x7f, 0x54, 0x4b, 0x34, 0xf2, 0x8f,
+	0xbe, 0xbc, 0x91, 0x3e, 0x20, 0x0f, 0xe9, 0x9a, 0xc5, 0xed, 0x56, 0xa7, 0xdd, 0xba, 0x53, 0x4b,
+	0x36, 0x8a, 0x8f, 0xbe, 0xbc, 0x91, 0xc7, 0x84, 0x15, 0x0e, 0x6e, 0x76, 0xa0, 0x14, 0x9e, 0x95,
+	0x28, 0xf4, 0x21, 0xa8, 0xdc, 0xba, 0x7b, 0xb4, 0xbf, 0xd7, 0xda, 0xed, 0xb6, 0xb5, 0x7b, 0x87,
+	0xdd, 0x76, 0x2d, 0x89, 0x9e, 0x85, 0x2b, 0xfb, 0x7b, 0x3f, 0xee, 0x74, 0xb5, 0xd6, 0xfe, 0x5e,
+	0xfb, 0xa0, 0xab, 0xed, 0x76, 0xbb, 0xbb, 0xad, 0x3b, 0xb5, 0xd4, 0xce, 0xef, 0x15, 0xa8, 0xee,
+	0x36, 0x5b, 0x7b, 0x14, 0xb6, 0xcd, 0xbe, 0xce, 0xee, 0x94, 0x2d, 0xc8, 0xb0, 0x5b, 0xe3, 0x85,
+	0x4f, 0x79, 0x8d, 0x8b, 0x4b, 0x4a, 0xe8, 0x36, 0x64, 0xd9, 0x85, 0x12, 0x5d, 0xfc, 0xb6, 0xd7,
+	0x58, 0x52, 0x63, 0xa2, 0x9d, 0x61, 0xdb, 0xe3, 0xc2, 0xc7, 0xbe, 0xc6, 0xc5, 0x25, 0x27, 0x84,
+	0x41, 0x99, 0x92, 0xcf, 0xe5, 0x8f, 0x5f, 0x8d, 0x15, 0xc0, 0x06, 0xed, 0x43, 0x5e, 0xde, 0x21,
+	0x96, 0x3d, 0xc7, 0x35, 0x96, 0xd6, 0x84, 0x68, 0xba, 0xf8, 0x5d, 0xef, 0xe2, 0xb7, 0xc5, 0xc6,
+	0x92, 0x02, 0x17, 0xda, 0x83, 0x9c, 0x20, 0x54, 0x4b, 0x9e, 0xd8, 0x1a, 0xcb, 0x6a, 0x3c, 0x34,
+	0x69, 0xd3, 0x5b, 0xf4, 0xf2, 0x17, 0xd3, 0xc6, 0x0a, 0xb5, 0x3b, 0x74, 0x17, 0x20, 0x74, 0xb3,
+	0x5b, 0xe1, 0x29, 0xb4, 0xb1, 0x4a, 0x4d, 0x0e, 0x1d, 0x42, 0x21, 0x20, 0xd5, 0x4b, 0x1f, 0x26,
+	0x1b, 0xcb, 0x8b, 0x63, 0xe8, 0x3e, 0x94, 0xa3, 0x64, 0x72, 0xb5, 0xe7, 0xc6, 0xc6, 0x8a, 0x55,
+	0x2f, 0x1a, 0x3f, 0xca, 0x2c, 0x57, 0x7b, 0x7e, 0x6c, 0xac, 0x58, 0x04, 0x43, 0x9f, 0xc1, 0xda,
+	0x3c, 0xf3, 0x5b, 0xfd, 0x35, 0xb2, 0x71, 0x89, 0xb2, 0x18, 0x1a, 0x03, 0x5a, 0xc0, 0x18, 0x2f,
+	0xf1, 0x38, 0xd9, 0xb8, 0x4c, 0x95, 0xac, 0xd9, 0xfe, 0xea, 0xc9, 0x46, 0xf2, 0xeb, 0x27, 0x1b,
+	0xc9, 0xbf, 0x3f, 0xd9, 0x48, 0x3e, 0x7e, 0xba, 0x91, 0xf8, 0xfa, 0xe9, 0x46, 0xe2, 0xaf, 0x4f,
+	0x37, 0x12, 0x3f, 0x7b, 0x6d, 0x60, 0xfa, 0xc3, 0x49, 0x6f, 0xab, 0x6f, 0x8f, 0xb7, 0xc3, 0xff,
+	0x7a, 0x58, 0xf4, 0x4f, 0x8c, 0x5e, 0x8e, 0x1d, 0x2a, 0x6f, 0xfd, 0x67, 0x00, 0x76, 0x19, 0xf8,
+	0x29, 0xa9, 0x21, 0x00, 0x00,
 }
 
 // Reference imports to suppress errors if they are not otherwise used.
@@ -5241,6 +5252,13 @@ func (m *ResponseCheckTx) MarshalToSizedBuffer(dAtA []byte) (int, error) {
 	_ = i
 	var l int
 	_ = l
+	if len(m.Lane) > 0 {
+		i -= len(m.Lane)
+		copy(dAtA[i:], m.Lane)
+		i = encodeVarintTypes(dAtA, i, uint64(len(m.Lane)))
+		i--
+		dAtA[i] = 0x62
+	}
 	if len(m.MempoolError) > 0 {
 		i -= len(m.MempoolError)
 		copy(dAtA[i:], m.MempoolError)
@@ -6865,6 +6883,10 @@ func (m *ResponseCheckTx) Size() (n int) {
 	if l > 0 {
 		n += 1 + l + sovTypes(uint64(l))
 	}
+	l = len(m.Lane)
+	if l > 0 {
+		n += 1 + l + sovTypes(uint64(l))
+	}
 	return n
 }
 
@@ -11162,6 +11184,38 @@ func (m *ResponseCheckTx) Unmarshal(dAtA []byte) error {
 			}
 			m.MempoolError = string(dAtA[iNdEx:postIndex])
 			iNdEx = postIndex
+		case 12:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Lane", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowTypes
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthTypes
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex < 0 {
+				return ErrInvalidLengthTypes
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Lane = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
 		default:
 			iNdEx = preIndex
 			skippy, err := skipTypes(dAtA[iNdEx:])
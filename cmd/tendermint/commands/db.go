@@ -0,0 +1,497 @@
+package commands
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/spf13/cobra"
+	"github.com/syndtr/goleveldb/leveldb"
+	"github.com/syndtr/goleveldb/leveldb/util"
+	dbm "github.com/tendermint/tm-db"
+
+	tmcfg "github.com/tendermint/tendermint/config"
+	"github.com/tendermint/tendermint/internal/state"
+	"github.com/tendermint/tendermint/internal/state/indexer"
+	tmstate "github.com/tendermint/tendermint/proto/tendermint/state"
+	"github.com/tendermint/tendermint/types"
+)
+
+// nodeStores are the databases of a node handled by the db commands.
+var nodeStores = []string{"blockstore", "state", "tx_index", "evidence", "peerstore"}
+
+// maxReportedErrors caps the number of errors listed for each check.
+const maxReportedErrors = 100
+
+// DBCmd groups the commands that inspect and maintain the databases of a node.
+var DBCmd = &cobra.Command{
+	Use:   "db",
+	Short: "Inspect, compact and check the node databases",
+	Long: `
+Report the size of the node databases, compact them, or check that the block,
+state and index stores are consistent with each other. The results are written
+to the standard output as JSON. The node must be stopped while running these
+commands.
+`,
+}
+
+var dbStatsCmd = &cobra.Command{
+	Use:   "stats [store...]",
+	Short: "Report the size and number of keys of the node databases",
+	Long: `
+Report the size on disk, the number of keys and the total size of the keys and
+values of each database (blockstore, state, tx_index, evidence and peerstore by
+default). Databases which do not exist are reported as such and not created.
+`,
+	Example: `
+	tendermint db stats
+	tendermint db stats blockstore state
+	`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		stats, err := dbStats(cmd.Context(), config, storeNames(args))
+		if err != nil {
+			return fmt.Errorf("failed to collect database stats: %w", err)
+		}
+		return writeJSON(stats)
+	},
+}
+
+var dbCompactCmd = &cobra.Command{
+	Use:   "compact [store...]",
+	Short: "Compact the node databases",
+	Long: `
+Force a full compaction of each database, for instance to reclaim disk space
+after pruning a large number of blocks. Only the goleveldb backend supports
+compaction.
+`,
+	Example: `
+	tendermint db compact
+	tendermint db compact blockstore
+	`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		stats, err := compactDBs(cmd.Context(), config, storeNames(args))
+		if err != nil {
+			return fmt.Errorf("failed to compact databases: %w", err)
+		}
+		return writeJSON(stats)
+	},
+}
+
+var dbCheckCmd = &cobra.Command{
+	Use:   "check",
+	Short: "Check the consistency of the block, state and index stores",
+	Long: `
+Check, for every height stored in the block store:
+ - that each block header hashes to its block ID and links to the previous one,
+   and that the stored commit of the previous block matches it
+ - that the state store has the validators and consensus parameters
+ - that the stored ABCI responses match the LastResultsHash of the next block
+ - that the block is covered by the enabled event sinks which support it
+
+The command exits with an error if any check fails.
+`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		report, err := checkDBs(cmd.Context(), config)
+		if err != nil {
+			return fmt.Errorf("failed to check databases: %w", err)
+		}
+		if err := writeJSON(report); err != nil {
+			return err
+		}
+		if !report.OK {
+			return errors.New("inconsistencies found")
+		}
+		return nil
+	},
+}
+
+func init() {
+	DBCmd.AddCommand(dbStatsCmd)
+	DBCmd.AddCommand(dbCompactCmd)
+	DBCmd.AddCommand(dbCheckCmd)
+}
+
+func storeNames(args []string) []string {
+	if len(args) == 0 {
+		return nodeStores
+	}
+	return args
+}
+
+func writeJSON(v interface{}) error {
+	enc := json.NewEncoder(os.Stdout)
+	enc.SetIndent("", "  ")
+	return enc.Encode(v)
+}
+
+// storeStats describes the size of a database.
+type storeStats struct {
+	Name   string `json:"name"`
+	Path   string `json:"path"`
+	Exists bool   `json:"exists"`
+	// Size of the database files on disk.
+	DiskBytes int64 `json:"disk_bytes"`
+	// Size of the block segment files of the flatfile block store backend.
+	SegmentBytes int64 `json:"segment_bytes,omitempty"`
+	Keys         int64 `json:"keys"`
+	KeyBytes     int64 `json:"key_bytes"`
+	ValueBytes   int64 `json:"value_bytes"`
+}
+
+func storePath(config *tmcfg.Config, name string) string {
+	return filepath.Join(config.DBDir(), name+".db")
+}
+
+// openStore opens an existing database. It returns nil if the database does
+// not exist.
+func openStore(config *tmcfg.Config, name string) (dbm.DB, error) {
+	if _, err := os.Stat(storePath(config, name)); errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+	return tmcfg.DefaultDBProvider(&tmcfg.DBContext{ID: name, Config: config})
+}
+
+func dbStats(ctx context.Context, config *tmcfg.Config, names []string) ([]storeStats, error) {
+	stats := make([]storeStats, 0, len(names))
+	for _, name := range names {
+		s := storeStats{Name: name, Path: storePath(config, name)}
+
+		db, err := openStore(config, name)
+		if err != nil {
+			return nil, fmt.Errorf("opening %s: %w", name, err)
+		}
+		if db != nil {
+			s.Exists = true
+			err = countKeys(ctx, db, &s)
+			if cerr := db.Close(); err == nil {
+				err = cerr
+			}
+			if err != nil {
+				return nil, fmt.Errorf("reading %s: %w", name, err)
+			}
+			if s.DiskBytes, err = dirSize(s.Path); err != nil {
+				return nil, err
+			}
+		}
+		if name == "blockstore" {
+			if s.SegmentBytes, err = dirSize(config.BlockSegmentsDir()); err != nil {
+				return nil, err
+			}
+		}
+		stats = append(stats, s)
+	}
+	return stats, nil
+}
+
+func countKeys(ctx context.Context, db dbm.DB, s *storeStats) error {
+	iter, err := db.Iterator(nil, nil)
+	if err != nil {
+		return err
+	}
+	defer iter.Close()
+
+	for ; iter.Valid(); iter.Next() {
+		if s.Keys%10000 == 0 {
+			if err := ctx.Err(); err != nil {
+				return err
+			}
+		}
+		s.Keys++
+		s.KeyBytes += int64(len(iter.Key()))
+		s.ValueBytes += int64(len(iter.Value()))
+	}
+	return iter.Error()
+}
+
+// dirSize returns the total size of the files under path, or 0 if it does
+// not exist.
+func dirSize(path string) (int64, error) {
+	var size int64
+	err := filepath.Walk(path, func(_ string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if !info.IsDir() {
+			size += info.Size()
+		}
+		return nil
+	})
+	if errors.Is(err, os.ErrNotExist) {
+		return 0, nil
+	}
+	return size, err
+}
+
+// compactStats describes the size of a database before and after compaction.
+type compactStats struct {
+	Name        string `json:"name"`
+	Exists      bool   `json:"exists"`
+	BytesBefore int64  `json:"bytes_before"`
+	BytesAfter  int64  `json:"bytes_after"`
+}
+
+func compactDBs(ctx context.Context, config *tmcfg.Config, names []string) ([]compactStats, error) {
+	stats := make([]compactStats, 0, len(names))
+	for _, name := range names {
+		if err := ctx.Err(); err != nil {
+			return stats, err
+		}
+		s := compactStats{Name: name}
+
+		db, err := openStore(config, name)
+		if err != nil {
+			return stats, fmt.Errorf("opening %s: %w", name, err)
+		}
+		if db == nil {
+			stats = append(stats, s)
+			continue
+		}
+		s.Exists = true
+		if s.BytesBefore, err = dirSize(storePath(config, name)); err != nil {
+			db.Close()
+			return stats, err
+		}
+
+		logger.Info("compacting database", "db", name)
+		err = compactDB(db)
+		if cerr := db.Close(); err == nil {
+			err = cerr
+		}
+		if err != nil {
+			return stats, fmt.Errorf("compacting %s: %w", name, err)
+		}
+		if s.BytesAfter, err = dirSize(storePath(config, name)); err != nil {
+			return stats, err
+		}
+		stats = append(stats, s)
+	}
+	return stats, nil
+}
+
+func compactDB(db dbm.DB) error {
+	ldb, ok := db.(interface{ DB() *leveldb.DB })
+	if !ok {
+		return fmt.Errorf("compaction is not supported by the %T backend", db)
+	}
+	return ldb.DB().CompactRange(util.Range{})
+}
+
+// checkResult is the outcome of one of the consistency checks.
+type checkResult struct {
+	Name string `json:"name"`
+	OK   bool   `json:"ok"`
+	// Number of heights checked and skipped (e.g. because the data is
+	// legitimately absent after state sync).
+	Checked int64 `json:"checked"`
+	Skipped int64 `json:"skipped,omitempty"`
+	// Total number of failures, of which at most maxReportedErrors are listed.
+	Failures   int64    `json:"failures"`
+	Errors     []string `json:"errors,omitempty"`
+	SkipReason string   `json:"skip_reason,omitempty"`
+}
+
+func (r *checkResult) fail(format string, args ...interface{}) {
+	r.Failures++
+	if len(r.Errors) < maxReportedErrors {
+		r.Errors = append(r.Errors, fmt.Sprintf(format, args...))
+	}
+}
+
+// checkReport is the output of the check command.
+type checkReport struct {
+	OK     bool           `json:"ok"`
+	Base   int64          `json:"base"`
+	Height int64          `json:"height"`
+	Checks []*checkResult `json:"checks"`
+}
+
+func checkDBs(ctx context.Context, config *tmcfg.Config) (*checkReport, error) {
+	blockStore, stateStore, err := loadStateAndBlockStore(config)
+	if err != nil {
+		return nil, err
+	}
+
+	state, err := stateStore.Load()
+	if err != nil {
+		return nil, err
+	}
+
+	sinks, err := loadEventSinks(config, state.ChainID)
+	if err != nil {
+		logger.Info("not checking the event sinks", "reason", err)
+		sinks = nil
+	}
+	defer func() {
+		for _, sink := range sinks {
+			if err := sink.Stop(); err != nil {
+				logger.Error("failed to stop event sink", "sink", sink.Type(), "err", err)
+			}
+		}
+	}()
+
+	return checkStores(ctx, blockStore, stateStore, sinks)
+}
+
+// checkStores checks the consistency of the block store, the state store and
+// the event sinks for every height in the block store.
+func checkStores(
+	ctx context.Context,
+	blockStore state.BlockStore,
+	stateStore state.Store,
+	sinks []indexer.EventSink,
+) (*checkReport, error) {
+	report := &checkReport{
+		OK:     true,
+		Base:   blockStore.Base(),
+		Height: blockStore.Height(),
+	}
+	var (
+		chain     = &checkResult{Name: "block_chain"}
+		vals      = &checkResult{Name: "state_validators_params"}
+		responses = &checkResult{Name: "abci_responses"}
+		indexed   = &checkResult{Name: "indexer_coverage"}
+	)
+	report.Checks = []*checkResult{chain, vals, responses, indexed}
+
+	checkSinks := make([]indexer.EventSink, 0, len(sinks))
+	for _, sink := range sinks {
+		if _, err := sink.HasBlock(report.Base); err != nil {
+			logger.Info("event sink does not support coverage checks", "sink", sink.Type(), "err", err)
+			continue
+		}
+		checkSinks = append(checkSinks, sink)
+	}
+	if len(checkSinks) == 0 {
+		indexed.SkipReason = "no enabled event sink supports coverage checks"
+	}
+
+	var prev *types.BlockMeta
+	for height := report.Base; report.Base > 0 && height <= report.Height; height++ {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+
+		var cur *types.BlockMeta
+		err := recoverCorruption(func() { cur = blockStore.LoadBlockMeta(height) })
+		switch {
+		case err != nil:
+			chain.fail("height %d: %v", height, err)
+		case cur == nil:
+			chain.fail("height %d: missing block meta", height)
+		}
+		chain.Checked++
+		if cur != nil {
+			checkBlockLink(chain, blockStore, prev, cur)
+		}
+
+		vals.Checked++
+		if _, err := stateStore.LoadValidators(height); err != nil {
+			vals.fail("height %d: validators: %v", height, err)
+		}
+		if _, err := stateStore.LoadConsensusParams(height); err != nil {
+			vals.fail("height %d: consensus params: %v", height, err)
+		}
+
+		// the results of the previous block are committed to by this one
+		if prev != nil && cur != nil {
+			checkABCIResponses(responses, stateStore, prev.Header.Height, cur.Header.LastResultsHash)
+		}
+
+		if len(checkSinks) > 0 {
+			indexed.Checked++
+			for _, sink := range checkSinks {
+				ok, err := sink.HasBlock(height)
+				if err != nil {
+					indexed.fail("height %d: %s sink: %v", height, sink.Type(), err)
+				} else if !ok {
+					indexed.fail("height %d: not indexed by the %s sink", height, sink.Type())
+				}
+			}
+		}
+
+		prev = cur
+	}
+
+	for _, check := range report.Checks {
+		check.OK = check.Failures == 0
+		report.OK = report.OK && check.OK
+	}
+	return report, nil
+}
+
+// checkBlockLink checks that the header of a block hashes to its block ID and
+// links to the previous block and its commit.
+func checkBlockLink(result *checkResult, blockStore state.BlockStore, prev, cur *types.BlockMeta) {
+	height := cur.Header.Height
+	if hash := cur.Header.Hash(); !bytes.Equal(hash, cur.BlockID.Hash) {
+		result.fail("height %d: header hash %X does not match block ID %X", height, hash, cur.BlockID.Hash)
+	}
+	if prev == nil {
+		return
+	}
+	if !cur.Header.LastBlockID.Equals(prev.BlockID) {
+		result.fail("height %d: last block ID %v does not match block %d (%v)",
+			height, cur.Header.LastBlockID, prev.Header.Height, prev.BlockID)
+	}
+
+	var commit *types.Commit
+	err := recoverCorruption(func() { commit = blockStore.LoadBlockCommit(prev.Header.Height) })
+	switch {
+	case err != nil:
+		result.fail("height %d: commit: %v", prev.Header.Height, err)
+	case commit == nil:
+		result.fail("height %d: missing commit", prev.Header.Height)
+	case !commit.BlockID.Equals(prev.BlockID):
+		result.fail("height %d: commit for block %v does not match block ID %v",
+			prev.Header.Height, commit.BlockID, prev.BlockID)
+	case cur.BlockSize >= 0 && !bytes.Equal(commit.Hash(), cur.Header.LastCommitHash):
+		// headers saved without their block (e.g. backfilled by state sync)
+		// are stored with the commit of their own height instead
+		result.fail("height %d: commit hash %X does not match LastCommitHash %X of the next block",
+			prev.Header.Height, commit.Hash(), cur.Header.LastCommitHash)
+	}
+}
+
+// checkABCIResponses checks that the ABCI responses stored for a height match
+// the results hash committed to by the next block.
+func checkABCIResponses(result *checkResult, stateStore state.Store, height int64, resultsHash []byte) {
+	var (
+		abciResponses *tmstate.ABCIResponses
+		err           error
+	)
+	if perr := recoverCorruption(func() { abciResponses, err = stateStore.LoadABCIResponses(height) }); perr != nil {
+		err = perr
+	}
+	if errors.As(err, &state.ErrNoABCIResponsesForHeight{}) {
+		// responses are not available for blocks synced with state sync
+		result.Skipped++
+		return
+	}
+	result.Checked++
+	if err != nil {
+		result.fail("height %d: %v", height, err)
+		return
+	}
+	if hash := state.ABCIResponsesResultsHash(abciResponses); !bytes.Equal(hash, resultsHash) {
+		result.fail("height %d: results hash %X does not match LastResultsHash %X of the next block",
+			height, hash, resultsHash)
+	}
+}
+
+// recoverCorruption calls load, turning the panics of the stores on corrupted
+// records into errors.
+func recoverCorruption(load func()) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("corrupted record: %v", r)
+		}
+	}()
+	load()
+	return nil
+}
@@ -0,0 +1,148 @@
+package commands
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	dbm "github.com/tendermint/tm-db"
+
+	abcitypes "github.com/tendermint/tendermint/abci/types"
+	sm "github.com/tendermint/tendermint/internal/state"
+	"github.com/tendermint/tendermint/internal/state/indexer"
+	"github.com/tendermint/tendermint/internal/state/indexer/sink/kv"
+	"github.com/tendermint/tendermint/internal/store"
+	"github.com/tendermint/tendermint/internal/test/factory"
+	tmtime "github.com/tendermint/tendermint/libs/time"
+	prototmstate "github.com/tendermint/tendermint/proto/tendermint/state"
+	"github.com/tendermint/tendermint/types"
+)
+
+// makeCheckedStores saves a chain of blocks along with their state and ABCI
+// responses, and indexes all of them but the last one.
+func makeCheckedStores(t *testing.T, numBlocks int64) (*store.BlockStore, sm.Store, indexer.EventSink) {
+	t.Helper()
+
+	blockStore := store.NewBlockStore(dbm.NewMemDB())
+	stateStore := sm.NewStore(dbm.NewMemDB())
+	sink := kv.NewEventSink(dbm.NewMemDB())
+
+	valSet, _ := factory.RandValidatorSet(1, 10)
+	state := sm.State{
+		ChainID:                          "db_check_test",
+		InitialHeight:                    1,
+		Validators:                       valSet,
+		NextValidators:                   valSet,
+		LastValidators:                   valSet,
+		LastHeightValidatorsChanged:      1,
+		ConsensusParams:                  *types.DefaultConsensusParams(),
+		LastHeightConsensusParamsChanged: 1,
+	}
+
+	var (
+		lastBlockID types.BlockID
+		lastCommit  = new(types.Commit)
+		lastResults []byte
+	)
+	for height := int64(1); height <= numBlocks; height++ {
+		state.LastBlockHeight = height - 1
+		require.NoError(t, stateStore.Save(state))
+
+		block := types.MakeBlock(height, factory.MakeTenTxs(height), lastCommit, nil)
+		block.ChainID = state.ChainID
+		block.LastBlockID = lastBlockID
+		block.LastResultsHash = lastResults
+		block.ProposerAddress = valSet.Proposer.Address
+		parts := block.MakePartSet(types.BlockPartSizeBytes)
+		blockID := types.BlockID{Hash: block.Hash(), PartSetHeader: parts.Header()}
+		commit := types.NewCommit(height, 0, blockID, []types.CommitSig{{
+			BlockIDFlag:      types.BlockIDFlagCommit,
+			ValidatorAddress: valSet.Proposer.Address,
+			Timestamp:        tmtime.Now(),
+			Signature:        []byte("signature"),
+		}})
+		blockStore.SaveBlock(block, parts, commit)
+
+		responses := &prototmstate.ABCIResponses{
+			BeginBlock: &abcitypes.ResponseBeginBlock{},
+			DeliverTxs: []*abcitypes.ResponseDeliverTx{{Code: uint32(height)}},
+			EndBlock:   &abcitypes.ResponseEndBlock{},
+		}
+		require.NoError(t, stateStore.SaveABCIResponses(height, responses))
+
+		if height < numBlocks {
+			require.NoError(t, sink.IndexBlockEvents(types.EventDataNewBlockHeader{Header: block.Header}))
+		}
+
+		lastBlockID, lastCommit = blockID, commit
+		lastResults = sm.ABCIResponsesResultsHash(responses)
+	}
+
+	return blockStore, stateStore, sink
+}
+
+func checkFailures(report *checkReport) map[string]int64 {
+	failures := make(map[string]int64)
+	for _, check := range report.Checks {
+		if check.Failures > 0 {
+			failures[check.Name] = check.Failures
+		}
+	}
+	return failures
+}
+
+func TestCheckStores(t *testing.T) {
+	ctx := context.Background()
+	blockStore, stateStore, sink := makeCheckedStores(t, 5)
+
+	report, err := checkStores(ctx, blockStore, stateStore, nil)
+	require.NoError(t, err)
+	require.True(t, report.OK, "%+v", checkFailures(report))
+	require.EqualValues(t, 1, report.Base)
+	require.EqualValues(t, 5, report.Height)
+	require.Equal(t, "no enabled event sink supports coverage checks", report.Checks[3].SkipReason)
+
+	// the last block is not indexed
+	report, err = checkStores(ctx, blockStore, stateStore, []indexer.EventSink{sink})
+	require.NoError(t, err)
+	require.False(t, report.OK)
+	require.Equal(t, map[string]int64{"indexer_coverage": 1}, checkFailures(report))
+
+	// ABCI responses which do not match the results hash of the next block
+	require.NoError(t, stateStore.SaveABCIResponses(2, &prototmstate.ABCIResponses{
+		BeginBlock: &abcitypes.ResponseBeginBlock{},
+		DeliverTxs: []*abcitypes.ResponseDeliverTx{{Code: 100}},
+		EndBlock:   &abcitypes.ResponseEndBlock{},
+	}))
+	report, err = checkStores(ctx, blockStore, stateStore, nil)
+	require.NoError(t, err)
+	require.Equal(t, map[string]int64{"abci_responses": 1}, checkFailures(report))
+	require.Contains(t, report.Checks[2].Errors[0], "height 2")
+}
+
+func TestCheckStoresBrokenChain(t *testing.T) {
+	blockStore, stateStore, _ := makeCheckedStores(t, 5)
+
+	// replace block 3 by a block which does not link to block 2
+	other, _, _ := makeCheckedStores(t, 5)
+	mixed := store.NewBlockStore(dbm.NewMemDB())
+	for height := int64(1); height <= 5; height++ {
+		source := blockStore
+		if height >= 3 {
+			source = other
+		}
+		block := source.LoadBlock(height)
+		seenCommit := source.LoadBlockCommit(height)
+		if seenCommit == nil {
+			seenCommit = source.LoadSeenCommit()
+		}
+		mixed.SaveBlock(block, block.MakePartSet(types.BlockPartSizeBytes), seenCommit)
+	}
+
+	report, err := checkStores(context.Background(), mixed, stateStore, nil)
+	require.NoError(t, err)
+	require.False(t, report.OK)
+	failures := checkFailures(report)
+	require.Contains(t, failures, "block_chain")
+	require.Contains(t, report.Checks[0].Errors[0], "height 3: last block ID")
+}
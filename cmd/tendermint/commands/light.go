@@ -13,6 +13,7 @@ import (
 	"github.com/spf13/cobra"
 	dbm "github.com/tendermint/tm-db"
 
+	cfg "github.com/tendermint/tendermint/config"
 	"github.com/tendermint/tendermint/libs/log"
 	tmmath "github.com/tendermint/tendermint/libs/math"
 	tmos "github.com/tendermint/tendermint/libs/os"
@@ -38,6 +39,12 @@ need a primary RPC address, a trusted hash and height and witness RPC addresses
 (if not using sequential verification). To restart the node, thereafter
 only the chainID is required.
 
+With --p2p, light blocks are fetched from peers on the p2p network instead,
+which are found through the persistent and bootstrap peers and PEX as
+configured in the home directory. The first peer serves as primary and the
+others as witnesses. The primary RPC address is then only used to fetch the
+data that is verified against the light blocks.
+
 When /abci_query is called, the Merkle key path format is:
 
 	/{store name}/{key}
@@ -64,6 +71,8 @@ var (
 	trustedHeight  int64
 	trustedHash    []byte
 	trustLevelStr  string
+	upgradesFile   string
+	useP2P         bool
 
 	logLevel  string
 	logFormat string
@@ -72,6 +81,11 @@ var (
 	witnessesKey = []byte("witnesses")
 )
 
+// LightNodeProvider takes a config, a logger and a chain ID and returns the
+// light node that the light command fetches light blocks from in p2p mode.
+// It is set by the binary, see node.NewLightNode.
+var LightNodeProvider func(*cfg.Config, log.Logger, string) (light.Network, error)
+
 func init() {
 	LightCmd.Flags().StringVar(&listenAddr, "laddr", "tcp://localhost:8888",
 		"serve the proxy on the given address")
@@ -98,6 +112,14 @@ func init() {
 	LightCmd.Flags().BoolVar(&sequential, "sequential", false,
 		"sequential verification. Verify all headers sequentially as opposed to using skipping verification",
 	)
+	LightCmd.Flags().StringVar(&upgradesFile, "upgrades", "",
+		"path to a JSON file with trusted hard-fork upgrades to follow the chain across",
+	)
+	LightCmd.Flags().BoolVar(&useP2P, "p2p", false,
+		"fetch light blocks from peers on the p2p network instead of the primary and witness RPC addresses. "+
+			"Peers are discovered through the [p2p] section of the config in the home directory. "+
+			"The primary address is still used to serve the data of the proxied rpc calls",
+	)
 }
 
 func runProxy(cmd *cobra.Command, args []string) error {
@@ -151,23 +173,79 @@ func runProxy(cmd *cobra.Command, args []string) error {
 		options = append(options, light.SkippingVerification(trustLevel))
 	}
 
+	if upgradesFile != "" {
+		upgrades, err := light.LoadUpgradesFile(upgradesFile)
+		if err != nil {
+			return err
+		}
+		options = append(options, light.Upgrades(upgrades...))
+	}
+
+	trustOptions := light.TrustOptions{
+		Period: trustingPeriod,
+		Height: trustedHeight,
+		Hash:   trustedHash,
+	}
+
 	// Initiate the light client. If the trusted store already has blocks in it, this
 	// will be used else we use the trusted options.
-	c, err := light.NewHTTPClient(
-		context.Background(),
-		chainID,
-		light.TrustOptions{
-			Period: trustingPeriod,
-			Height: trustedHeight,
-			Hash:   trustedHash,
-		},
-		primaryAddr,
-		witnessesAddrs,
-		dbs.New(db),
-		options...,
+	var (
+		c         *light.Client
+		lightNode light.Network
 	)
-	if err != nil {
-		return err
+	if useP2P {
+		if LightNodeProvider == nil {
+			return errors.New("p2p mode is not supported by this binary")
+		}
+		lightNode, err = LightNodeProvider(config, logger, chainID)
+		if err != nil {
+			return fmt.Errorf("failed to create light node: %w", err)
+		}
+		if err := lightNode.Start(); err != nil {
+			return fmt.Errorf("failed to start light node: %w", err)
+		}
+		defer func() {
+			if !lightNode.IsRunning() {
+				return
+			}
+			if err := lightNode.Stop(); err != nil {
+				logger.Error("failed to stop light node", "err", err)
+			}
+		}()
+
+		// a primary and at least one witness are needed
+		logger.Info("Waiting for peers...")
+		providers, err := lightNode.Providers(context.Background(), 2)
+		if err != nil {
+			return err
+		}
+
+		c, err = light.NewClient(
+			context.Background(),
+			chainID,
+			trustOptions,
+			providers[0],
+			providers[1:],
+			dbs.New(db),
+			options...,
+		)
+		if err != nil {
+			return err
+		}
+		lightNode.SetLightClient(c)
+	} else {
+		c, err = light.NewHTTPClient(
+			context.Background(),
+			chainID,
+			trustOptions,
+			primaryAddr,
+			witnessesAddrs,
+			dbs.New(db),
+			options...,
+		)
+		if err != nil {
+			return err
+		}
 	}
 
 	cfg := rpcserver.DefaultConfig()
@@ -189,6 +267,11 @@ func runProxy(cmd *cobra.Command, args []string) error {
 	// Stop upon receiving SIGTERM or CTRL-C.
 	tmos.TrapSignal(logger, func() {
 		p.Listener.Close()
+		if lightNode != nil {
+			if err := lightNode.Stop(); err != nil {
+				logger.Error("failed to stop light node", "err", err)
+			}
+		}
 	})
 
 	logger.Info("Starting proxy...", "laddr", listenAddr)
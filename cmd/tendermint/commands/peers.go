@@ -0,0 +1,169 @@
+package commands
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	tmcfg "github.com/tendermint/tendermint/config"
+	"github.com/tendermint/tendermint/internal/p2p"
+)
+
+// PeersCmd groups the commands that move the contents of the peer store in and
+// out of a node.
+var PeersCmd = &cobra.Command{
+	Use:   "peers",
+	Short: "Export and import the peer store",
+	Long: `
+Export the peers known to this node, along with the dial history of their
+addresses and any bans, to a JSON file, or merge such a file into the peer
+store of this or another node. The node must be stopped while running these
+commands. Peers can be managed at runtime with the unsafe dial_peers,
+remove_peer, ban_peer, unban_peer and list_peers RPC routes.
+`,
+}
+
+var peersExportCmd = &cobra.Command{
+	Use:   "export [file]",
+	Short: "Export the peer store to a JSON file",
+	Long: `
+Export the peer store to a JSON file, or to the standard output if no file is
+given.
+`,
+	Example: `
+	tendermint peers export peers.json
+	`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		var w io.Writer = os.Stdout
+		if len(args) > 0 {
+			f, err := os.Create(args[0])
+			if err != nil {
+				return err
+			}
+			defer f.Close()
+			w = f
+		}
+
+		book, err := exportPeers(config)
+		if err != nil {
+			return fmt.Errorf("failed to export peers: %w", err)
+		}
+
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(book)
+	},
+}
+
+var peersImportCmd = &cobra.Command{
+	Use:   "import [file]",
+	Short: "Merge a JSON file exported by another node into the peer store",
+	Long: `
+Merge the peers and bans of a JSON file exported with "tendermint peers export"
+into the peer store. Known addresses keep their own dial history, new ones are
+added along with the exported history. Expired bans are skipped.
+`,
+	Example: `
+	tendermint peers import peers.json
+	`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		bz, err := os.ReadFile(args[0])
+		if err != nil {
+			return err
+		}
+		var book addressBook
+		if err := json.Unmarshal(bz, &book); err != nil {
+			return fmt.Errorf("invalid peers file: %w", err)
+		}
+
+		added, bans, err := importPeers(config, book)
+		if err != nil {
+			return fmt.Errorf("failed to import peers: %w", err)
+		}
+
+		fmt.Printf("Imported %d new peer addresses and %d bans\n", added, bans)
+		return nil
+	},
+}
+
+func init() {
+	PeersCmd.AddCommand(peersExportCmd)
+	PeersCmd.AddCommand(peersImportCmd)
+}
+
+// addressBook is the file format of exported peer stores.
+type addressBook struct {
+	Peers []p2p.PeerRecord `json:"peers"`
+	Bans  []p2p.PeerBan    `json:"bans"`
+}
+
+func exportPeers(config *tmcfg.Config) (addressBook, error) {
+	peerManager, closer, err := loadPeerManager(config)
+	if err != nil {
+		return addressBook{}, err
+	}
+	defer closer()
+
+	return addressBook{
+		Peers: peerManager.Records(),
+		Bans:  peerManager.Bans(),
+	}, nil
+}
+
+func importPeers(config *tmcfg.Config, book addressBook) (int, int, error) {
+	peerManager, closer, err := loadPeerManager(config)
+	if err != nil {
+		return 0, 0, err
+	}
+	defer closer()
+
+	added, err := peerManager.Import(book.Peers)
+	if err != nil {
+		return added, 0, err
+	}
+
+	bans := 0
+	for _, ban := range book.Bans {
+		if !ban.Expires.IsZero() && !ban.Expires.After(time.Now()) {
+			continue
+		}
+		if _, err := peerManager.Ban(ban.Peer, ban.Expires); err != nil {
+			return added, bans, fmt.Errorf("invalid ban of %q: %w", ban.Peer, err)
+		}
+		bans++
+	}
+	return added, bans, nil
+}
+
+// loadPeerManager opens the peer store of the node. The returned function
+// closes it.
+func loadPeerManager(config *tmcfg.Config) (*p2p.PeerManager, func(), error) {
+	nodeID, err := config.LoadNodeKeyID()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	peerDB, err := tmcfg.DefaultDBProvider(&tmcfg.DBContext{ID: "peerstore", Config: config})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	peerManager, err := p2p.NewPeerManager(nodeID, peerDB, p2p.PeerManagerOptions{})
+	if err != nil {
+		peerDB.Close()
+		return nil, nil, err
+	}
+
+	return peerManager, func() {
+		peerManager.Close()
+		if err := peerDB.Close(); err != nil {
+			logger.Error("failed to close peer store", "err", err)
+		}
+	}, nil
+}
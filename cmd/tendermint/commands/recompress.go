@@ -0,0 +1,106 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/spf13/cobra"
+	dbm "github.com/tendermint/tm-db"
+
+	cfg "github.com/tendermint/tendermint/config"
+	"github.com/tendermint/tendermint/internal/libs/compress"
+	"github.com/tendermint/tendermint/internal/state"
+	"github.com/tendermint/tendermint/internal/store"
+)
+
+var recompressBatchSize int
+
+// RecompressCmd rewrites the stored block parts, commits and ABCI responses
+// with the configured db-compression codec.
+var RecompressCmd = &cobra.Command{
+	Use:   "recompress",
+	Short: "convert stored blocks and ABCI responses to the configured db-compression",
+	Long: `
+recompress is an offline tool which rewrites the block parts, commits and ABCI
+responses in the block and state stores with the codec configured by
+db-compression (or the --db-compression flag). Nodes read every record using the
+codec it was written with, so changing db-compression only affects new data;
+this command converts the existing data as well.
+
+The stores are converted in batches and records already using the target codec
+are skipped, so the command can be interrupted and restarted at any time. The
+node must be stopped while it runs. Block parts kept in segment files by the
+flatfile block-store-backend are not rewritten.
+`,
+	Example: `
+	tendermint recompress --db-compression snappy
+	tendermint recompress --db-compression none --batch-size 100
+	`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx, cancel := context.WithCancel(cmd.Context())
+		defer cancel()
+
+		c := make(chan os.Signal, 1)
+		signal.Notify(c, syscall.SIGTERM, syscall.SIGINT)
+		go func() {
+			<-c
+			cancel()
+		}()
+
+		return recompress(ctx, config, recompressBatchSize)
+	},
+}
+
+func init() {
+	addDBFlags(RecompressCmd)
+	RecompressCmd.Flags().IntVar(&recompressBatchSize, "batch-size", 1000,
+		"number of records to rewrite per database write")
+}
+
+func recompress(ctx context.Context, config *cfg.Config, batchSize int) error {
+	codec, err := compress.ParseCodec(config.DBCompression)
+	if err != nil {
+		return err
+	}
+
+	stores := []struct {
+		id  string
+		run func(ctx context.Context, db dbm.DB) (compress.Stats, error)
+	}{
+		{"blockstore", func(ctx context.Context, db dbm.DB) (compress.Stats, error) {
+			return store.Recompress(ctx, db, codec, batchSize)
+		}},
+		{"state", func(ctx context.Context, db dbm.DB) (compress.Stats, error) {
+			return state.RecompressABCIResponses(ctx, db, codec, batchSize)
+		}},
+	}
+
+	for _, s := range stores {
+		db, err := cfg.DefaultDBProvider(&cfg.DBContext{ID: s.id, Config: config})
+		if err != nil {
+			return fmt.Errorf("constructing database handle: %w", err)
+		}
+
+		logger.Info("recompressing database", "db", s.id, "codec", codec)
+		stats, err := s.run(ctx, db)
+		if cerr := db.Close(); err == nil {
+			err = cerr
+		}
+		if err != nil {
+			return fmt.Errorf("recompressing %s: %w", s.id, err)
+		}
+
+		logger.Info("recompressed database",
+			"db", s.id,
+			"records", stats.Records,
+			"rewritten", stats.Rewritten,
+			"bytes_before", stats.BytesBefore,
+			"bytes_after", stats.BytesAfter,
+		)
+	}
+
+	return nil
+}
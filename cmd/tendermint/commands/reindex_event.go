@@ -13,6 +13,7 @@ import (
 	"github.com/tendermint/tendermint/internal/libs/progressbar"
 	"github.com/tendermint/tendermint/internal/state"
 	"github.com/tendermint/tendermint/internal/state/indexer"
+	"github.com/tendermint/tendermint/internal/state/indexer/sink"
 	"github.com/tendermint/tendermint/internal/state/indexer/sink/kv"
 	"github.com/tendermint/tendermint/internal/state/indexer/sink/psql"
 	"github.com/tendermint/tendermint/internal/store"
@@ -54,12 +55,25 @@ either or both arguments.
 			return
 		}
 
-		es, err := loadEventSinks(config)
+		state, err := ss.Load()
 		if err != nil {
 			fmt.Println(reindexFailed, err)
 			return
 		}
 
+		es, err := loadEventSinks(config, state.ChainID)
+		if err != nil {
+			fmt.Println(reindexFailed, err)
+			return
+		}
+		defer func() {
+			for _, s := range es {
+				if err := s.Stop(); err != nil {
+					fmt.Println("failed to stop event sink:", err)
+				}
+			}
+		}()
+
 		if err = eventReIndex(cmd, es, bs, ss); err != nil {
 			fmt.Println(reindexFailed, err)
 			return
@@ -79,7 +93,9 @@ func init() {
 	ReIndexEventCmd.Flags().Int64Var(&endHeight, "end-height", 0, "the block height would like to finish for re-index")
 }
 
-func loadEventSinks(cfg *tmcfg.Config) ([]indexer.EventSink, error) {
+// loadEventSinks opens the event sinks configured in cfg, which record the
+// events of the given chain.
+func loadEventSinks(cfg *tmcfg.Config, chainID string) ([]indexer.EventSink, error) {
 	// Check duplicated sinks.
 	sinks := map[string]bool{}
 	for _, s := range cfg.TxIndex.Indexer {
@@ -112,6 +128,20 @@ func loadEventSinks(cfg *tmcfg.Config) ([]indexer.EventSink, error) {
 				return nil, err
 			}
 			eventSinks = append(eventSinks, es)
+		case string(indexer.WEBHOOK):
+			// The events are queued in the outbox, and the ones not delivered
+			// before the command exits are delivered once the node starts.
+			es, err := sink.NewWebhookEventSink(cfg, tmcfg.DefaultDBProvider, chainID, logger)
+			if err != nil {
+				return nil, err
+			}
+			eventSinks = append(eventSinks, es)
+		case string(indexer.SQLITE):
+			es, err := sink.NewSQLiteEventSink(cfg, chainID)
+			if err != nil {
+				return nil, err
+			}
+			eventSinks = append(eventSinks, es)
 		default:
 			return nil, errors.New("unsupported event sink type")
 		}
@@ -126,7 +156,7 @@ func loadEventSinks(cfg *tmcfg.Config) ([]indexer.EventSink, error) {
 		return nil, fmt.Errorf("no event sink has been enabled")
 	}
 
-	return eventSinks, nil
+	return sink.WithEventFilters(cfg, eventSinks), nil
 }
 
 func loadStateAndBlockStore(cfg *tmcfg.Config) (*store.BlockStore, state.Store, error) {
@@ -137,7 +167,10 @@ func loadStateAndBlockStore(cfg *tmcfg.Config) (*store.BlockStore, state.Store,
 	if err != nil {
 		return nil, nil, err
 	}
-	blockStore := store.NewBlockStore(blockStoreDB)
+	blockStore, err := store.OpenBlockStore(cfg.BaseConfig, blockStoreDB)
+	if err != nil {
+		return nil, nil, err
+	}
 
 	// Get StateStore
 	stateDB, err := dbm.NewDB("state", dbType, cfg.DBDir())
@@ -90,14 +90,17 @@ func TestLoadEventSink(t *testing.T) {
 		{[]string{"PSQL"}, "", true},         // true because empty connect url
 		{[]string{"PSQL"}, "wrongUrl", true}, // true because wrong connect url
 		// skip to test PSQL connect with correct url
+		{[]string{"WEBHOOK"}, "", true}, // true because no webhook urls
+		{[]string{"SQLITE"}, "", false},
 		{[]string{"UnsupportedSinkType"}, "wrongUrl", true},
 	}
 
 	for _, tc := range testCases {
 		cfg := tmcfg.TestConfig()
+		cfg.RootDir = t.TempDir()
 		cfg.TxIndex.Indexer = tc.sinks
 		cfg.TxIndex.PsqlConn = tc.connURL
-		_, err := loadEventSinks(cfg)
+		_, err := loadEventSinks(cfg, "test-chain")
 		if tc.loadErr {
 			require.Error(t, err)
 		} else {
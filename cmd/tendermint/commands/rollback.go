@@ -1,17 +1,37 @@
 package commands
 
 import (
+	"errors"
 	"fmt"
 
 	"github.com/spf13/cobra"
 
 	cfg "github.com/tendermint/tendermint/config"
 	"github.com/tendermint/tendermint/internal/state"
+	"github.com/tendermint/tendermint/privval"
 )
 
+var (
+	rollbackHeight        int64
+	rollbackDryRun        bool
+	rollbackForce         bool
+	rollbackPrivValidator bool
+)
+
+func init() {
+	RollbackStateCmd.Flags().Int64Var(&rollbackHeight, "height", 0,
+		"roll back to this height, removing the blocks above it")
+	RollbackStateCmd.Flags().BoolVar(&rollbackDryRun, "dry-run", false,
+		"check the rollback to --height without modifying anything")
+	RollbackStateCmd.Flags().BoolVar(&rollbackForce, "force", false,
+		"allow rolling back to --height further than the evidence max age")
+	RollbackStateCmd.Flags().BoolVar(&rollbackPrivValidator, "adjust-priv-validator", false,
+		"(unsafe) allow the file private validator to sign the heights removed by the rollback to --height again")
+}
+
 var RollbackStateCmd = &cobra.Command{
 	Use:   "rollback",
-	Short: "rollback tendermint state by one height",
+	Short: "rollback tendermint state by one height, or to a given height",
 	Long: `
 A state rollback is performed to recover from an incorrect application state transition,
 when Tendermint has persisted an incorrect app hash and is thus unable to make
@@ -19,14 +39,43 @@ progress. Rollback overwrites a state at height n with the state at height n - 1
 The application should also roll back to height n - 1. No blocks are removed, so upon
 restarting Tendermint the transactions in block n will be re-executed against the
 application.
+
+With --height, the state is instead rebuilt for the given height and all blocks
+above it are removed, so that they are fetched from peers or agreed upon again.
+The application should also roll back to that height. The target height must not
+be below the pruned base of the block store, nor, unless --force is given, further
+back than the evidence max age. The rollback is recorded in the state store; with
+--adjust-priv-validator, the last sign state of the file private validator is
+adjusted accordingly. Only do this if the whole network rolled back, since signing
+the removed heights again may otherwise produce conflicting votes.
 `,
 	RunE: func(cmd *cobra.Command, args []string) error {
-		height, hash, err := RollbackState(config)
+		if rollbackHeight == 0 {
+			if rollbackDryRun || rollbackForce || rollbackPrivValidator {
+				return errors.New("--dry-run, --force and --adjust-priv-validator require --height")
+			}
+			height, hash, err := RollbackState(config)
+			if err != nil {
+				return fmt.Errorf("failed to rollback state: %w", err)
+			}
+
+			fmt.Printf("Rolled back state to height %d and hash %v", height, hash)
+			return nil
+		}
+
+		opts := state.RollbackOptions{DryRun: rollbackDryRun, Force: rollbackForce}
+		result, err := RollbackStateTo(config, rollbackHeight, opts, rollbackPrivValidator)
 		if err != nil {
 			return fmt.Errorf("failed to rollback state: %w", err)
 		}
 
-		fmt.Printf("Rolled back state to height %d and hash %v", height, hash)
+		if opts.DryRun {
+			fmt.Printf("Would roll back state from height %d to height %d and hash %v, removing %d blocks\n",
+				result.FromHeight, result.Height, result.AppHash, result.BlocksRemoved)
+			return nil
+		}
+		fmt.Printf("Rolled back state from height %d to height %d and hash %v, removed %d blocks\n",
+			result.FromHeight, result.Height, result.AppHash, result.BlocksRemoved)
 		return nil
 	},
 }
@@ -44,3 +93,43 @@ func RollbackState(config *cfg.Config) (int64, []byte, error) {
 	// rollback the last state
 	return state.Rollback(blockStore, stateStore)
 }
+
+// RollbackStateTo overwrites the state with the state at the given height and
+// removes the blocks above it. If adjustPrivValidator is set, the last sign
+// state of the file private validator is then adjusted to the recorded
+// rollback. Note state here refers to tendermint state not application state.
+func RollbackStateTo(
+	config *cfg.Config,
+	height int64,
+	opts state.RollbackOptions,
+	adjustPrivValidator bool,
+) (state.RollbackResult, error) {
+	if adjustPrivValidator && config.PrivValidator.ListenAddr != "" {
+		return state.RollbackResult{}, errors.New("only a file private validator can be adjusted")
+	}
+
+	blockStore, stateStore, err := loadStateAndBlockStore(config)
+	if err != nil {
+		return state.RollbackResult{}, err
+	}
+	defer blockStore.Close()
+
+	result, err := state.RollbackTo(blockStore, stateStore, height, opts)
+	if err != nil || opts.DryRun || !adjustPrivValidator {
+		return result, err
+	}
+
+	info, err := stateStore.LoadRollback()
+	if err != nil {
+		return result, err
+	}
+	pv, err := privval.LoadFilePV(config.PrivValidator.KeyFile(), config.PrivValidator.StateFile())
+	if err != nil {
+		return result, err
+	}
+	if pv.Rollback(info.Height) {
+		logger.Info("Adjusted private validator state", "height", info.Height,
+			"stateFile", config.PrivValidator.StateFile())
+	}
+	return result, nil
+}
@@ -89,6 +89,10 @@ func addDBFlags(cmd *cobra.Command) {
 		"db-dir",
 		config.DBPath,
 		"database directory")
+	cmd.Flags().String(
+		"db-compression",
+		config.DBCompression,
+		"compression of block parts, commits and ABCI responses: none | snappy")
 }
 
 // NewRunNodeCmd returns the command that allows the CLI to start a node.
@@ -0,0 +1,183 @@
+package commands
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+
+	tmcfg "github.com/tendermint/tendermint/config"
+	"github.com/tendermint/tendermint/internal/proxy"
+	"github.com/tendermint/tendermint/internal/statesync"
+	"github.com/tendermint/tendermint/types"
+)
+
+var (
+	snapshotHeight      uint64
+	snapshotTrustedHash []byte
+)
+
+// SnapshotCmd groups the commands that move application snapshots in and out
+// of a node without going through state sync over the p2p network.
+var SnapshotCmd = &cobra.Command{
+	Use:   "snapshot",
+	Short: "Export and restore application snapshots",
+	Long: `
+Export an application snapshot of this node to an archive file, or restore a
+fresh node from such an archive without any peers.
+
+An archive contains the snapshot chunks as well as the light blocks and the
+Tendermint state at the snapshot height, so that a restored node can continue
+with block sync from there. The node must be stopped while running these
+commands.
+`,
+}
+
+var snapshotExportCmd = &cobra.Command{
+	Use:   "export [file]",
+	Short: "Export a snapshot of the application to an archive file",
+	Long: `
+Export a snapshot of the application to an archive file. The snapshot must
+have been taken by the application and the node must have the blocks at the
+snapshot height and the two following heights. By default, the most recent
+snapshot that satisfies this is exported.
+`,
+	Example: `
+	tendermint snapshot export snapshot.bin
+	tendermint snapshot export snapshot.bin --height 1000
+	`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		height, err := exportSnapshot(cmd, config, args[0])
+		if err != nil {
+			return fmt.Errorf("failed to export snapshot: %w", err)
+		}
+
+		fmt.Printf("Exported snapshot at height %d to %s\n", height, args[0])
+		return nil
+	},
+}
+
+var snapshotRestoreCmd = &cobra.Command{
+	Use:   "restore [file]",
+	Short: "Restore the application and an empty node from a snapshot archive",
+	Long: `
+Restore the application from a snapshot archive and bootstrap the node's state
+and block stores, which must be empty, at the snapshot height. The light blocks
+in the archive must belong to the chain of the node's genesis file and be
+signed and linked, starting at the block with the hash passed with
+--trusted-hash. That hash of the block at the snapshot height must be obtained
+from a trusted source, since it is the root of trust of the restore. The
+restored application's app hash is verified against the light blocks.
+`,
+	Example: `
+	tendermint snapshot restore snapshot.bin --trusted-hash 28B97BE9F6DE51AC69F70E0B7BFD7E5C9CD1A595B7DC31AFF27C50D4948020CD
+	`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		height, err := restoreSnapshot(cmd, config, args[0])
+		if err != nil {
+			return fmt.Errorf("failed to restore snapshot: %w", err)
+		}
+
+		fmt.Printf("Restored snapshot at height %d\n", height)
+		return nil
+	},
+}
+
+func init() {
+	snapshotExportCmd.Flags().Uint64Var(&snapshotHeight, "height", 0,
+		"height of the snapshot to export (0 for the most recent one)")
+	snapshotRestoreCmd.Flags().BytesHexVar(&snapshotTrustedHash, "trusted-hash", nil,
+		"trusted hash of the block at the snapshot height (required)")
+
+	SnapshotCmd.AddCommand(snapshotExportCmd)
+	SnapshotCmd.AddCommand(snapshotRestoreCmd)
+}
+
+func exportSnapshot(cmd *cobra.Command, config *tmcfg.Config, path string) (uint64, error) {
+	blockStore, stateStore, err := loadStateAndBlockStore(config)
+	if err != nil {
+		return 0, err
+	}
+
+	proxyApp, err := startProxyApp(config)
+	if err != nil {
+		return 0, err
+	}
+	defer func() {
+		if err := proxyApp.Stop(); err != nil {
+			logger.Error("failed to stop proxy app", "err", err)
+		}
+	}()
+
+	f, err := os.Create(path)
+	if err != nil {
+		return 0, err
+	}
+	defer f.Close()
+
+	w := bufio.NewWriter(f)
+	height, err := statesync.ExportSnapshot(cmd.Context(), proxyApp.Snapshot(), stateStore, blockStore,
+		snapshotHeight, w)
+	if err == nil {
+		err = w.Flush()
+	}
+	if err == nil {
+		err = f.Sync()
+	}
+	if err != nil {
+		// don't leave an incomplete archive behind
+		if rerr := os.Remove(path); rerr != nil {
+			logger.Error("failed to remove incomplete archive", "path", path, "err", rerr)
+		}
+		return 0, err
+	}
+	return height, nil
+}
+
+func restoreSnapshot(cmd *cobra.Command, config *tmcfg.Config, path string) (int64, error) {
+	blockStore, stateStore, err := loadStateAndBlockStore(config)
+	if err != nil {
+		return 0, err
+	}
+
+	genDoc, err := types.GenesisDocFromFile(config.GenesisFile())
+	if err != nil {
+		return 0, err
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		return 0, err
+	}
+	defer f.Close()
+
+	proxyApp, err := startProxyApp(config)
+	if err != nil {
+		return 0, err
+	}
+	defer func() {
+		if err := proxyApp.Stop(); err != nil {
+			logger.Error("failed to stop proxy app", "err", err)
+		}
+	}()
+
+	state, err := statesync.RestoreSnapshot(cmd.Context(), logger, proxyApp.Snapshot(), proxyApp.Query(),
+		stateStore, blockStore, genDoc, bufio.NewReader(f), snapshotTrustedHash)
+	if err != nil {
+		return 0, err
+	}
+	return state.LastBlockHeight, nil
+}
+
+// startProxyApp connects to the application configured for the node.
+func startProxyApp(config *tmcfg.Config) (proxy.AppConns, error) {
+	clientCreator, _ := proxy.DefaultClientCreator(logger, config.ProxyApp, config.ABCI, config.DBDir())
+	proxyApp := proxy.NewAppConns(clientCreator, logger.With("module", "proxy"), proxy.NopMetrics())
+	if err := proxyApp.Start(); err != nil {
+		return nil, fmt.Errorf("error starting proxy app connections: %w", err)
+	}
+	return proxyApp, nil
+}
@@ -30,6 +30,10 @@ func main() {
 		cmd.VersionCmd,
 		cmd.InspectCmd,
 		cmd.RollbackStateCmd,
+		cmd.RecompressCmd,
+		cmd.DBCmd,
+		cmd.SnapshotCmd,
+		cmd.PeersCmd,
 		cmd.MakeKeyMigrateCommand(),
 		debug.DebugCmd,
 		cli.NewCompletionCmd(rootCmd, true),
@@ -45,6 +49,9 @@ func main() {
 	// node.NewDefault function
 	nodeFunc := node.NewDefault
 
+	// Provide the light node used by `tendermint light --p2p`
+	cmd.LightNodeProvider = node.NewLightNode
+
 	// Create & start node
 	rootCmd.AddCommand(cmd.NewRunNodeCmd(nodeFunc))
 
@@ -5,13 +5,17 @@ import (
 	"errors"
 	"fmt"
 	"net/http"
+	"net/url"
 	"os"
 	"path/filepath"
+	"strconv"
+	"strings"
 	"time"
 
 	tmjson "github.com/tendermint/tendermint/libs/json"
 	"github.com/tendermint/tendermint/libs/log"
 	tmos "github.com/tendermint/tendermint/libs/os"
+	tmstrings "github.com/tendermint/tendermint/libs/strings"
 	"github.com/tendermint/tendermint/types"
 )
 
@@ -27,6 +31,9 @@ const (
 	ModeFull      = "full"
 	ModeValidator = "validator"
 	ModeSeed      = "seed"
+
+	BlockStoreBackendKV       = "kv"
+	BlockStoreBackendFlatFile = "flatfile"
 )
 
 // NOTE: Most of the structs & relevant comments + the
@@ -66,6 +73,7 @@ type Config struct {
 	RPC             *RPCConfig             `mapstructure:"rpc"`
 	P2P             *P2PConfig             `mapstructure:"p2p"`
 	Mempool         *MempoolConfig         `mapstructure:"mempool"`
+	BlockSync       *BlockSyncConfig       `mapstructure:"blocksync"`
 	StateSync       *StateSyncConfig       `mapstructure:"statesync"`
 	Consensus       *ConsensusConfig       `mapstructure:"consensus"`
 	TxIndex         *TxIndexConfig         `mapstructure:"tx-index"`
@@ -80,6 +88,7 @@ func DefaultConfig() *Config {
 		RPC:             DefaultRPCConfig(),
 		P2P:             DefaultP2PConfig(),
 		Mempool:         DefaultMempoolConfig(),
+		BlockSync:       DefaultBlockSyncConfig(),
 		StateSync:       DefaultStateSyncConfig(),
 		Consensus:       DefaultConsensusConfig(),
 		TxIndex:         DefaultTxIndexConfig(),
@@ -102,6 +111,7 @@ func TestConfig() *Config {
 		RPC:             TestRPCConfig(),
 		P2P:             TestP2PConfig(),
 		Mempool:         TestMempoolConfig(),
+		BlockSync:       TestBlockSyncConfig(),
 		StateSync:       TestStateSyncConfig(),
 		Consensus:       TestConsensusConfig(),
 		TxIndex:         TestTxIndexConfig(),
@@ -133,12 +143,18 @@ func (cfg *Config) ValidateBasic() error {
 	if err := cfg.Mempool.ValidateBasic(); err != nil {
 		return fmt.Errorf("error in [mempool] section: %w", err)
 	}
+	if err := cfg.BlockSync.ValidateBasic(); err != nil {
+		return fmt.Errorf("error in [blocksync] section: %w", err)
+	}
 	if err := cfg.StateSync.ValidateBasic(); err != nil {
 		return fmt.Errorf("error in [statesync] section: %w", err)
 	}
 	if err := cfg.Consensus.ValidateBasic(); err != nil {
 		return fmt.Errorf("error in [consensus] section: %w", err)
 	}
+	if err := cfg.TxIndex.ValidateBasic(); err != nil {
+		return fmt.Errorf("error in [tx-index] section: %w", err)
+	}
 	if err := cfg.Instrumentation.ValidateBasic(); err != nil {
 		return fmt.Errorf("error in [instrumentation] section: %w", err)
 	}
@@ -200,6 +216,20 @@ type BaseConfig struct { //nolint: maligned
 	// Database directory
 	DBPath string `mapstructure:"db-dir"`
 
+	// Compression applied to block parts, commits and ABCI responses
+	// written to the block and state stores: none | snappy
+	// The codec is recorded with every record, so it can be changed at any
+	// time; existing data can be converted with "tendermint recompress".
+	DBCompression string `mapstructure:"db-compression"`
+
+	// Where the block store keeps block parts: kv | flatfile
+	// * kv - in the blockstore database, with everything else
+	// * flatfile - in append-only segment files next to the blockstore
+	//   database, which only keeps their locations. Pruning deletes whole
+	//   segments. Block parts written by the kv backend remain readable, but
+	//   switching back from flatfile to kv is not supported.
+	BlockStoreBackend string `mapstructure:"block-store-backend"`
+
 	// Output level for logging
 	LogLevel string `mapstructure:"log-level"`
 
@@ -225,17 +255,20 @@ type BaseConfig struct { //nolint: maligned
 // DefaultBaseConfig returns a default base configuration for a Tendermint node
 func DefaultBaseConfig() BaseConfig {
 	return BaseConfig{
-		Genesis:     defaultGenesisJSONPath,
-		NodeKey:     defaultNodeKeyPath,
-		Mode:        defaultMode,
-		Moniker:     defaultMoniker,
-		ProxyApp:    "tcp://127.0.0.1:26658",
-		ABCI:        "socket",
-		LogLevel:    DefaultLogLevel,
-		LogFormat:   log.LogFormatPlain,
-		FilterPeers: false,
-		DBBackend:   "goleveldb",
-		DBPath:      "data",
+		Genesis:       defaultGenesisJSONPath,
+		NodeKey:       defaultNodeKeyPath,
+		Mode:          defaultMode,
+		Moniker:       defaultMoniker,
+		ProxyApp:      "tcp://127.0.0.1:26658",
+		ABCI:          "socket",
+		LogLevel:      DefaultLogLevel,
+		LogFormat:     log.LogFormatPlain,
+		FilterPeers:   false,
+		DBBackend:     "goleveldb",
+		DBPath:        "data",
+		DBCompression: "none",
+
+		BlockStoreBackend: BlockStoreBackendKV,
 	}
 }
 
@@ -303,6 +336,12 @@ func (cfg BaseConfig) DBDir() string {
 	return rootify(cfg.DBPath, cfg.RootDir)
 }
 
+// BlockSegmentsDir returns the full path to the directory of the block
+// segment files used by the flatfile block store backend.
+func (cfg BaseConfig) BlockSegmentsDir() string {
+	return filepath.Join(cfg.DBDir(), "blockstore.segments")
+}
+
 // ValidateBasic performs basic validation (checking param bounds, etc.) and
 // returns an error if any check fails.
 func (cfg BaseConfig) ValidateBasic() error {
@@ -321,6 +360,18 @@ func (cfg BaseConfig) ValidateBasic() error {
 		return fmt.Errorf("unknown mode: %v", cfg.Mode)
 	}
 
+	switch cfg.DBCompression {
+	case "", "none", "snappy":
+	default:
+		return fmt.Errorf("unknown db-compression: %q (must be 'none' or 'snappy')", cfg.DBCompression)
+	}
+
+	switch cfg.BlockStoreBackend {
+	case "", BlockStoreBackendKV, BlockStoreBackendFlatFile:
+	default:
+		return fmt.Errorf("unknown block-store-backend: %q (must be 'kv' or 'flatfile')", cfg.BlockStoreBackend)
+	}
+
 	return nil
 }
 
@@ -578,9 +629,14 @@ type P2PConfig struct { //nolint: maligned
 
 	// Comma separated list of peers to be added to the peer store
 	// on startup. Either BootstrapPeers or PersistentPeers are
-	// needed for peer discovery
+	// needed for peer discovery. DNS seeds (dnsseed://<domain>) add
+	// the peers listed in the TXT and SRV records of the domain.
 	BootstrapPeers string `mapstructure:"bootstrap-peers"`
 
+	// How often DNS seeds in BootstrapPeers are resolved again. 0 resolves
+	// them only on startup.
+	DNSSeedInterval time.Duration `mapstructure:"dns-seed-interval"`
+
 	// Comma separated list of nodes to keep persistent connections to
 	PersistentPeers string `mapstructure:"persistent-peers"`
 
@@ -595,9 +651,34 @@ type P2PConfig struct { //nolint: maligned
 	// attempts per IP address.
 	MaxIncomingConnectionAttempts uint `mapstructure:"max-incoming-connection-attempts"`
 
+	// Maximum number of connected peers, and of peers in the peer store, in
+	// the same IPv4 /16 or IPv6 /32 subnet. Peers on private and loopback
+	// addresses and persistent peers are exempt. 0 means no limit.
+	MaxConnectionsPerSubnet uint16 `mapstructure:"max-connections-per-subnet"`
+	MaxPeersPerSubnet       uint16 `mapstructure:"max-peers-per-subnet"`
+
+	// Maximum number of connected peers, and of peers in the peer store, in
+	// the same autonomous system, as given by ASNMap. 0 means no limit.
+	MaxConnectionsPerASN uint16 `mapstructure:"max-connections-per-asn"`
+	MaxPeersPerASN       uint16 `mapstructure:"max-peers-per-asn"`
+
+	// Path to a file mapping IP ranges to autonomous system numbers, with one
+	// "<CIDR> <ASN>" pair per line (e.g. "1.1.1.0/24 AS13335").
+	ASNMap string `mapstructure:"asn-map-file"`
+
 	// Set true to enable the peer-exchange reactor
 	PexReactor bool `mapstructure:"pex"`
 
+	// Set true for seed nodes to crawl the network: every peer in the peer
+	// store is dialed every CrawlInterval, and its NodeInfo, uptime and dial
+	// success rate are recorded and served over RPC. Reachable and compatible
+	// peers running the newest software versions are favoured in PEX
+	// responses.
+	Crawl bool `mapstructure:"crawl"`
+
+	// How often each peer is dialed when crawling.
+	CrawlInterval time.Duration `mapstructure:"crawl-interval"`
+
 	// Comma separated list of peer IDs to keep private (will not be gossiped to
 	// other peers)
 	PrivatePeerIDs string `mapstructure:"private-peer-ids"`
@@ -617,6 +698,25 @@ type P2PConfig struct { //nolint: maligned
 	// Rate at which packets can be received, in bytes/second
 	RecvRate int64 `mapstructure:"recv-rate"`
 
+	// Rate at which the router sends messages to each peer, in bytes/second,
+	// regardless of the transport. 0 means unlimited. Consensus channels are
+	// guaranteed a minimum share of it.
+	PeerSendRate int64 `mapstructure:"peer-send-rate"`
+
+	// Rate at which the router receives messages from each peer, in
+	// bytes/second, regardless of the transport. 0 means unlimited.
+	PeerRecvRate int64 `mapstructure:"peer-recv-rate"`
+
+	// Comma separated list of send and receive rates of individual channels
+	// with each peer, in bytes/second, as "<channel id>:<send rate>:<recv rate>"
+	// (e.g. "0x38:1024000:1024000"). A rate of 0 means unlimited.
+	ChannelRateLimits string `mapstructure:"channel-rate-limits"`
+
+	// Protocol securing peer connections, either "secret-connection" or
+	// "noise". With "noise", nodes negotiate the protocol before a Noise_XX
+	// handshake, and can't connect to nodes using "secret-connection".
+	SecureChannel string `mapstructure:"secure-channel"`
+
 	// Peer connection configuration.
 	HandshakeTimeout time.Duration `mapstructure:"handshake-timeout"`
 	DialTimeout      time.Duration `mapstructure:"dial-timeout"`
@@ -639,6 +739,7 @@ func DefaultP2PConfig() *P2PConfig {
 		UPNP:                          false,
 		MaxConnections:                64,
 		MaxIncomingConnectionAttempts: 100,
+		DNSSeedInterval:               time.Hour,
 		FlushThrottleTimeout:          100 * time.Millisecond,
 		// The MTU (Maximum Transmission Unit) for Ethernet is 1500 bytes.
 		// The IP header and the TCP header take up 20 bytes each at least (unless
@@ -649,7 +750,9 @@ func DefaultP2PConfig() *P2PConfig {
 		SendRate:                5120000, // 5 mB/s
 		RecvRate:                5120000, // 5 mB/s
 		PexReactor:              true,
+		CrawlInterval:           30 * time.Minute,
 		AllowDuplicateIP:        false,
+		SecureChannel:           "secret-connection",
 		HandshakeTimeout:        20 * time.Second,
 		DialTimeout:             3 * time.Second,
 		TestDialFail:            false,
@@ -657,6 +760,14 @@ func DefaultP2PConfig() *P2PConfig {
 	}
 }
 
+// ASNMapFile returns the full path to the ASN map file.
+func (cfg *P2PConfig) ASNMapFile() string {
+	if cfg.ASNMap == "" {
+		return ""
+	}
+	return rootify(cfg.ASNMap, cfg.RootDir)
+}
+
 // ValidateBasic performs basic validation (checking param bounds, etc.) and
 // returns an error if any check fails.
 func (cfg *P2PConfig) ValidateBasic() error {
@@ -672,9 +783,63 @@ func (cfg *P2PConfig) ValidateBasic() error {
 	if cfg.RecvRate < 0 {
 		return errors.New("recv-rate can't be negative")
 	}
+	if cfg.DNSSeedInterval < 0 {
+		return errors.New("dns-seed-interval can't be negative")
+	}
+	if cfg.CrawlInterval < 0 {
+		return errors.New("crawl-interval can't be negative")
+	}
+	if cfg.PeerSendRate < 0 {
+		return errors.New("peer-send-rate can't be negative")
+	}
+	if cfg.PeerRecvRate < 0 {
+		return errors.New("peer-recv-rate can't be negative")
+	}
+	if _, err := cfg.ParseChannelRateLimits(); err != nil {
+		return fmt.Errorf("invalid channel-rate-limits: %w", err)
+	}
+	switch cfg.SecureChannel {
+	case "secret-connection", "noise":
+	default:
+		return fmt.Errorf("unsupported secure-channel %q", cfg.SecureChannel)
+	}
 	return nil
 }
 
+// ChannelRateLimit is the bandwidth limit of a p2p channel with each peer, in
+// bytes/second. A rate of 0 means unlimited.
+type ChannelRateLimit struct {
+	SendRate int64
+	RecvRate int64
+}
+
+// ParseChannelRateLimits parses ChannelRateLimits, keyed by channel ID.
+func (cfg *P2PConfig) ParseChannelRateLimits() (map[uint16]ChannelRateLimit, error) {
+	limits := map[uint16]ChannelRateLimit{}
+	for _, entry := range tmstrings.SplitAndTrimEmpty(cfg.ChannelRateLimits, ",", " ") {
+		parts := strings.Split(entry, ":")
+		if len(parts) != 3 {
+			return nil, fmt.Errorf("%q is not of the form <channel id>:<send rate>:<recv rate>", entry)
+		}
+		chID, err := strconv.ParseUint(parts[0], 0, 16)
+		if err != nil {
+			return nil, fmt.Errorf("invalid channel id %q: %w", parts[0], err)
+		}
+		if _, ok := limits[uint16(chID)]; ok {
+			return nil, fmt.Errorf("duplicate channel id %q", parts[0])
+		}
+		var limit ChannelRateLimit
+		if limit.SendRate, err = strconv.ParseInt(parts[1], 10, 64); err != nil || limit.SendRate < 0 {
+			return nil, fmt.Errorf("invalid send rate %q of channel %q", parts[1], parts[0])
+		}
+		if limit.RecvRate, err = strconv.ParseInt(parts[2], 10, 64); err != nil || limit.RecvRate < 0 {
+			return nil, fmt.Errorf("invalid receive rate %q of channel %q", parts[2], parts[0])
+		}
+		limits[uint16(chID)] = limit
+	}
+	return limits, nil
+}
+
 // TestP2PConfig returns a configuration for testing the peer-to-peer layer
 func TestP2PConfig() *P2PConfig {
 	cfg := DefaultP2PConfig()
@@ -719,6 +884,50 @@ type MempoolConfig struct {
 	// XXX: Unused due to https://github.com/tendermint/tendermint/issues/5796
 	MaxBatchBytes int `mapstructure:"max-batch-bytes"`
 
+	// Transactions are announced to peers by their keys, and peers request
+	// the ones they don't have. AnnounceInterval is the maximum time to wait
+	// for more transactions to batch into an announcement. 0 announces the
+	// transactions available right away.
+	AnnounceInterval time.Duration `mapstructure:"announce-interval"`
+
+	// TxRequestTimeout is how long to wait for a peer to send a requested
+	// transaction before requesting it from another peer that announced it.
+	TxRequestTimeout time.Duration `mapstructure:"tx-request-timeout"`
+
+	// Comma separated list of lanes the application can assign transactions
+	// to in ResponseCheckTx, as
+	// "<name>:<max txs>:<max bytes>:<block share>:<gossip priority>"
+	// (e.g. "oracle:1000:1048576:10:1"). A lane holds at most max txs
+	// transactions of at most max bytes in total, within the mempool-wide
+	// limits (0 means no lane limit), and is guaranteed block share percent
+	// of the bytes of each block proposed by this node. Lanes with a higher
+	// gossip priority are announced to peers first, and lanes with a positive
+	// gossip priority without waiting for AnnounceInterval. Transactions
+	// without a configured lane go to the "default" lane, with priority 0.
+	// When the mempool is full, transactions only evict lower priority
+	// transactions of lanes with a lower or equal gossip priority.
+	Lanes string `mapstructure:"lanes"`
+
+	// PeerTxRate is the maximum number of transactions per second checked
+	// from each peer, with bursts of up to a second worth of transactions.
+	// Transactions above the limit are dropped. 0 means unlimited.
+	PeerTxRate int `mapstructure:"peer-tx-rate"`
+
+	// PeerTxBytesRate is the maximum number of transaction bytes per second
+	// checked from each peer. 0 means unlimited.
+	PeerTxBytesRate int64 `mapstructure:"peer-tx-bytes-rate"`
+
+	// BadTxsPerPenalty is the number of invalid, duplicate or rate limited
+	// transactions received from a peer after which its score is reduced.
+	// Duplicates only count if they were neither requested from the peer nor
+	// pushed by a peer without have/want gossip. 0 disables score reductions.
+	BadTxsPerPenalty int `mapstructure:"bad-txs-per-penalty"`
+
+	// MaxExcessBadTxs is the number of invalid, duplicate or rate limited
+	// transactions a peer may send in excess of its accepted transactions
+	// before it is disconnected. 0 disables disconnections.
+	MaxExcessBadTxs int `mapstructure:"max-excess-bad-txs"`
+
 	// TTLDuration, if non-zero, defines the maximum amount of time a transaction
 	// can exist for in the mempool.
 	//
@@ -749,6 +958,11 @@ func DefaultMempoolConfig() *MempoolConfig {
 		MaxTxBytes:   1024 * 1024, // 1MB
 		TTLDuration:  0 * time.Second,
 		TTLNumBlocks: 0,
+
+		AnnounceInterval: 100 * time.Millisecond,
+		TxRequestTimeout: 5 * time.Second,
+		BadTxsPerPenalty: 100,
+		MaxExcessBadTxs:  1000,
 	}
 }
 
@@ -780,10 +994,140 @@ func (cfg *MempoolConfig) ValidateBasic() error {
 	if cfg.TTLNumBlocks < 0 {
 		return errors.New("ttl-num-blocks can't be negative")
 	}
+	if cfg.AnnounceInterval < 0 {
+		return errors.New("announce-interval can't be negative")
+	}
+	if cfg.TxRequestTimeout <= 0 {
+		return errors.New("tx-request-timeout must be positive")
+	}
+	if _, err := cfg.ParseLanes(); err != nil {
+		return fmt.Errorf("invalid lanes: %w", err)
+	}
+	if cfg.PeerTxRate < 0 {
+		return errors.New("peer-tx-rate can't be negative")
+	}
+	if cfg.PeerTxBytesRate < 0 {
+		return errors.New("peer-tx-bytes-rate can't be negative")
+	}
+	if cfg.BadTxsPerPenalty < 0 {
+		return errors.New("bad-txs-per-penalty can't be negative")
+	}
+	if cfg.MaxExcessBadTxs < 0 {
+		return errors.New("max-excess-bad-txs can't be negative")
+	}
 
 	return nil
 }
 
+// DefaultMempoolLane is the lane of transactions that the application doesn't
+// assign to a configured lane.
+const DefaultMempoolLane = "default"
+
+// MempoolLane is the configuration of a mempool lane.
+type MempoolLane struct {
+	Name           string
+	MaxTxs         int
+	MaxTxsBytes    int64
+	BlockShare     int // percent of block bytes
+	GossipPriority int
+}
+
+// ParseLanes parses Lanes, in the configured order.
+func (cfg *MempoolConfig) ParseLanes() ([]MempoolLane, error) {
+	var (
+		lanes      []MempoolLane
+		names      = map[string]bool{}
+		blockShare int
+	)
+	for _, entry := range tmstrings.SplitAndTrimEmpty(cfg.Lanes, ",", " ") {
+		parts := strings.Split(entry, ":")
+		if len(parts) != 5 {
+			return nil, fmt.Errorf(
+				"%q is not of the form <name>:<max txs>:<max bytes>:<block share>:<gossip priority>", entry)
+		}
+		lane := MempoolLane{Name: parts[0]}
+		switch {
+		case lane.Name == "":
+			return nil, fmt.Errorf("empty lane name in %q", entry)
+		case lane.Name == DefaultMempoolLane:
+			return nil, fmt.Errorf("lane name %q is reserved", lane.Name)
+		case names[lane.Name]:
+			return nil, fmt.Errorf("duplicate lane %q", lane.Name)
+		}
+		names[lane.Name] = true
+
+		var err error
+		if lane.MaxTxs, err = strconv.Atoi(parts[1]); err != nil || lane.MaxTxs < 0 {
+			return nil, fmt.Errorf("invalid max txs %q of lane %q", parts[1], lane.Name)
+		}
+		if lane.MaxTxsBytes, err = strconv.ParseInt(parts[2], 10, 64); err != nil || lane.MaxTxsBytes < 0 {
+			return nil, fmt.Errorf("invalid max bytes %q of lane %q", parts[2], lane.Name)
+		}
+		if lane.BlockShare, err = strconv.Atoi(parts[3]); err != nil || lane.BlockShare < 0 || lane.BlockShare > 100 {
+			return nil, fmt.Errorf("invalid block share %q of lane %q", parts[3], lane.Name)
+		}
+		if lane.GossipPriority, err = strconv.Atoi(parts[4]); err != nil {
+			return nil, fmt.Errorf("invalid gossip priority %q of lane %q", parts[4], lane.Name)
+		}
+		blockShare += lane.BlockShare
+		lanes = append(lanes, lane)
+	}
+	if blockShare > 100 {
+		return nil, fmt.Errorf("block shares of lanes add up to %d%%", blockShare)
+	}
+	return lanes, nil
+}
+
+//-----------------------------------------------------------------------------
+// BlockSyncConfig
+
+// BlockSyncConfig defines the configuration for the Tendermint block sync service
+type BlockSyncConfig struct {
+	// If true, block sync first verifies the headers of the blocks to sync with
+	// light client skipping verification, starting from the node's trusted
+	// state, and fetches the blocks themselves from peers out of order and in
+	// parallel, checking each against its verified header. Headers are
+	// fetched from peers over the state sync light block channel.
+	HeaderFirst bool `mapstructure:"header-first"`
+
+	// Time period during which the headers verified in header-first block
+	// sync can be trusted. If the node's trusted state is older than this,
+	// blocks are synced without verifying headers first.
+	TrustPeriod time.Duration `mapstructure:"trust-period"`
+
+	// How far into the future the time of a header verified in header-first
+	// block sync can be.
+	MaxClockDrift time.Duration `mapstructure:"max-clock-drift"`
+}
+
+// DefaultBlockSyncConfig returns a default configuration for the block sync service
+func DefaultBlockSyncConfig() *BlockSyncConfig {
+	return &BlockSyncConfig{
+		HeaderFirst:   false,
+		TrustPeriod:   168 * time.Hour,
+		MaxClockDrift: 10 * time.Second,
+	}
+}
+
+// TestBlockSyncConfig returns a default configuration for the block sync service
+func TestBlockSyncConfig() *BlockSyncConfig {
+	return DefaultBlockSyncConfig()
+}
+
+// ValidateBasic performs basic validation.
+func (cfg *BlockSyncConfig) ValidateBasic() error {
+	if !cfg.HeaderFirst {
+		return nil
+	}
+	if cfg.TrustPeriod <= 0 {
+		return errors.New("trust-period must be positive")
+	}
+	if cfg.MaxClockDrift < 0 {
+		return errors.New("max-clock-drift can't be negative")
+	}
+	return nil
+}
+
 //-----------------------------------------------------------------------------
 // StateSyncConfig
 
@@ -821,6 +1165,9 @@ type StateSyncConfig struct {
 	// Temporary directory for state sync snapshot chunks, defaults to os.TempDir().
 	// The synchronizer will create a new, randomly named directory within this directory
 	// and remove it when the sync is complete.
+	//
+	// Deprecated: nodes keep snapshot chunks in the statesync directory of the
+	// data directory instead, so that an interrupted sync can be resumed.
 	TempDir string `mapstructure:"temp-dir"`
 
 	// The timeout duration before re-requesting a chunk, possibly from a different
@@ -1090,18 +1437,104 @@ type TxIndexConfig struct {
 	//   2) "kv" (default) - the simplest possible indexer,
 	//      backed by key-value storage (defaults to levelDB; see DBBackend).
 	//   3) "psql" - the indexer services backed by PostgreSQL.
+	//   4) "webhook" - posts block and tx events to the WebhookURLs.
+	//   5) "sqlite" - the indexer services backed by an embedded SQLite database.
 	Indexer []string `mapstructure:"indexer"`
 
 	// The PostgreSQL connection configuration, the connection format:
 	// postgresql://<user>:<password>@<host>:<port>/<db>?<opts>
 	PsqlConn string `mapstructure:"psql-conn"`
+
+	// The URLs the webhook sink posts events to.
+	WebhookURLs []string `mapstructure:"webhook-urls"`
+
+	// The secret the webhook sink signs its requests with, using HMAC-SHA256.
+	WebhookSecret string `mapstructure:"webhook-secret"`
+
+	// Maximum number of events posted in a single webhook request.
+	WebhookBatchSize int `mapstructure:"webhook-batch-size"`
+
+	// Timeout of a webhook request.
+	WebhookTimeout time.Duration `mapstructure:"webhook-timeout"`
+
+	// IndexAllow and IndexDeny select the event attributes the sinks index,
+	// among those the application marks for indexing. The rules are glob
+	// patterns ("*" matches any sequence of characters, "?" a single one)
+	// matched against the composite key "type.key" of the attributes, such
+	// as "transfer.*" or "*.sender". If IndexAllow is not empty, only the
+	// attributes matching one of its rules are indexed, and the attributes
+	// matching one of the IndexDeny rules are never indexed. A rule prefixed
+	// with the name of a sink and a colon, such as "kv:message.*", only
+	// applies to that sink.
+	IndexAllow []string `mapstructure:"index-allow"`
+	IndexDeny  []string `mapstructure:"index-deny"`
 }
 
 // DefaultTxIndexConfig returns a default configuration for the transaction indexer.
 func DefaultTxIndexConfig() *TxIndexConfig {
 	return &TxIndexConfig{
-		Indexer: []string{"kv"},
+		Indexer:          []string{"kv"},
+		WebhookBatchSize: 100,
+		WebhookTimeout:   10 * time.Second,
+	}
+}
+
+// ValidateBasic performs basic validation (checking param bounds, etc.) and
+// returns an error if any check fails.
+func (cfg *TxIndexConfig) ValidateBasic() error {
+	for _, rule := range append(append([]string{}, cfg.IndexAllow...), cfg.IndexDeny...) {
+		if err := validateIndexRule(rule); err != nil {
+			return err
+		}
+	}
+
+	webhook := false
+	for _, indexer := range cfg.Indexer {
+		if strings.ToLower(indexer) == "webhook" {
+			webhook = true
+		}
+	}
+	if !webhook {
+		return nil
+	}
+
+	if len(cfg.WebhookURLs) == 0 {
+		return errors.New("webhook-urls can't be empty when the webhook indexer is enabled")
 	}
+	for _, rawURL := range cfg.WebhookURLs {
+		u, err := url.Parse(rawURL)
+		if err != nil {
+			return fmt.Errorf("invalid webhook URL %q: %w", rawURL, err)
+		}
+		if (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return fmt.Errorf("invalid webhook URL %q: must be an absolute http or https URL", rawURL)
+		}
+	}
+	if cfg.WebhookSecret == "" {
+		return errors.New("webhook-secret can't be empty when the webhook indexer is enabled")
+	}
+	if cfg.WebhookBatchSize <= 0 {
+		return errors.New("webhook-batch-size must be positive")
+	}
+	if cfg.WebhookTimeout <= 0 {
+		return errors.New("webhook-timeout must be positive")
+	}
+	return nil
+}
+
+// validateIndexRule checks an IndexAllow or IndexDeny rule.
+func validateIndexRule(rule string) error {
+	pattern := rule
+	if i := strings.Index(rule, ":"); i >= 0 {
+		switch rule[:i] {
+		case "kv", "psql", "webhook", "sqlite":
+			pattern = rule[i+1:]
+		}
+	}
+	if pattern == "" {
+		return fmt.Errorf("invalid index rule %q: the pattern can't be empty", rule)
+	}
+	return nil
 }
 
 // TestTxIndexConfig returns a default configuration for the transaction indexer.
@@ -59,6 +59,16 @@ func TestBaseConfigValidateBasic(t *testing.T) {
 	// tamper with log format
 	cfg.LogFormat = "invalid"
 	assert.Error(t, cfg.ValidateBasic())
+
+	// tamper with db compression
+	cfg = TestBaseConfig()
+	cfg.DBCompression = "zstd"
+	assert.Error(t, cfg.ValidateBasic())
+
+	// tamper with block store backend
+	cfg = TestBaseConfig()
+	cfg.BlockStoreBackend = "sqlite"
+	assert.Error(t, cfg.ValidateBasic())
 }
 
 func TestRPCConfigValidateBasic(t *testing.T) {
@@ -90,6 +100,11 @@ func TestMempoolConfigValidateBasic(t *testing.T) {
 		"MaxTxsBytes",
 		"CacheSize",
 		"MaxTxBytes",
+		"AnnounceInterval",
+		"PeerTxRate",
+		"PeerTxBytesRate",
+		"BadTxsPerPenalty",
+		"MaxExcessBadTxs",
 	}
 
 	for _, fieldName := range fieldsToTest {
@@ -97,6 +112,42 @@ func TestMempoolConfigValidateBasic(t *testing.T) {
 		assert.Error(t, cfg.ValidateBasic())
 		reflect.ValueOf(cfg).Elem().FieldByName(fieldName).SetInt(0)
 	}
+
+	cfg.TxRequestTimeout = 0
+	assert.Error(t, cfg.ValidateBasic())
+	cfg.TxRequestTimeout = time.Second
+
+	cfg.Lanes = "oracle:1000:1048576:10:1, bulk:0:0:0:-1"
+	assert.NoError(t, cfg.ValidateBasic())
+	lanes, err := cfg.ParseLanes()
+	assert.NoError(t, err)
+	assert.Equal(t, []MempoolLane{
+		{Name: "oracle", MaxTxs: 1000, MaxTxsBytes: 1048576, BlockShare: 10, GossipPriority: 1},
+		{Name: "bulk", GossipPriority: -1},
+	}, lanes)
+
+	for _, invalid := range []string{
+		"oracle:1:1:1", "default:1:1:1:1", ":1:1:1:1", "oracle:-1:1:1:1", "oracle:1:1:101:1",
+		"oracle:1:1:1:x", "oracle:1:1:1:1,oracle:1:1:1:1", "a:0:0:60:0,b:0:0:50:0",
+	} {
+		cfg.Lanes = invalid
+		assert.Error(t, cfg.ValidateBasic(), invalid)
+	}
+}
+
+func TestBlockSyncConfigValidateBasic(t *testing.T) {
+	cfg := TestBlockSyncConfig()
+	require.NoError(t, cfg.ValidateBasic())
+
+	cfg.HeaderFirst = true
+	require.NoError(t, cfg.ValidateBasic())
+
+	cfg.TrustPeriod = 0
+	assert.Error(t, cfg.ValidateBasic())
+
+	cfg.TrustPeriod = time.Hour
+	cfg.MaxClockDrift = -time.Second
+	assert.Error(t, cfg.ValidateBasic())
 }
 
 func TestStateSyncConfigValidateBasic(t *testing.T) {
@@ -146,6 +197,39 @@ func TestConsensusConfig_ValidateBasic(t *testing.T) {
 	}
 }
 
+func TestTxIndexConfigValidateBasic(t *testing.T) {
+	cfg := TestTxIndexConfig()
+	assert.NoError(t, cfg.ValidateBasic())
+
+	// the webhook settings are only checked when the webhook indexer is enabled
+	cfg.WebhookURLs = []string{"not a url"}
+	assert.NoError(t, cfg.ValidateBasic())
+
+	cfg.Indexer = []string{"kv", "webhook"}
+	assert.Error(t, cfg.ValidateBasic())
+	cfg.WebhookURLs = []string{"http://localhost:8080/events", "ftp://localhost/events"}
+	assert.Error(t, cfg.ValidateBasic())
+	cfg.WebhookURLs = []string{"http://localhost:8080/events"}
+	assert.Error(t, cfg.ValidateBasic())
+	cfg.WebhookSecret = "secret"
+	assert.NoError(t, cfg.ValidateBasic())
+
+	cfg.WebhookBatchSize = 0
+	assert.Error(t, cfg.ValidateBasic())
+	cfg.WebhookBatchSize = 100
+	cfg.WebhookTimeout = 0
+	assert.Error(t, cfg.ValidateBasic())
+	cfg.WebhookTimeout = time.Second
+
+	cfg.IndexAllow = []string{"transfer.*", "kv:*.sender"}
+	cfg.IndexDeny = []string{"psql:message.action", "ibc:packet.data"}
+	assert.NoError(t, cfg.ValidateBasic())
+	cfg.IndexDeny = []string{"sqlite:"}
+	assert.Error(t, cfg.ValidateBasic())
+	cfg.IndexDeny = []string{""}
+	assert.Error(t, cfg.ValidateBasic())
+}
+
 func TestInstrumentationConfigValidateBasic(t *testing.T) {
 	cfg := TestInstrumentationConfig()
 	assert.NoError(t, cfg.ValidateBasic())
@@ -164,6 +248,9 @@ func TestP2PConfigValidateBasic(t *testing.T) {
 		"MaxPacketMsgPayloadSize",
 		"SendRate",
 		"RecvRate",
+		"PeerSendRate",
+		"PeerRecvRate",
+		"CrawlInterval",
 	}
 
 	for _, fieldName := range fieldsToTest {
@@ -171,4 +258,24 @@ func TestP2PConfigValidateBasic(t *testing.T) {
 		assert.Error(t, cfg.ValidateBasic())
 		reflect.ValueOf(cfg).Elem().FieldByName(fieldName).SetInt(0)
 	}
+
+	cfg.ChannelRateLimits = "0x30:1024:2048, 56:0:512"
+	assert.NoError(t, cfg.ValidateBasic())
+	limits, err := cfg.ParseChannelRateLimits()
+	assert.NoError(t, err)
+	assert.Equal(t, map[uint16]ChannelRateLimit{
+		0x30: {SendRate: 1024, RecvRate: 2048},
+		0x38: {SendRate: 0, RecvRate: 512},
+	}, limits)
+
+	for _, invalid := range []string{"0x30:1024", "0x30:1024:-1", "foo:1:1", "0x30:1:1,48:2:2"} {
+		cfg.ChannelRateLimits = invalid
+		assert.Error(t, cfg.ValidateBasic(), invalid)
+	}
+	cfg.ChannelRateLimits = ""
+
+	cfg.SecureChannel = "noise"
+	assert.NoError(t, cfg.ValidateBasic())
+	cfg.SecureChannel = "tls"
+	assert.Error(t, cfg.ValidateBasic())
 }
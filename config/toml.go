@@ -126,6 +126,20 @@ db-backend = "{{ .BaseConfig.DBBackend }}"
 # Database directory
 db-dir = "{{ js .BaseConfig.DBPath }}"
 
+# Compression applied to block parts, commits and ABCI responses written to
+# the block and state stores: none | snappy
+# The codec is recorded with every record, so it can be changed at any time;
+# existing data can be converted with "tendermint recompress".
+db-compression = "{{ .BaseConfig.DBCompression }}"
+
+# Where the block store keeps block parts: kv | flatfile
+# * kv - in the blockstore database, with everything else
+# * flatfile - in append-only segment files next to the blockstore database
+#   (blockstore.segments), which only keeps their locations. Pruning deletes
+#   whole segments. Block parts written by the kv backend remain readable,
+#   but switching back from flatfile to kv is not supported.
+block-store-backend = "{{ .BaseConfig.BlockStoreBackend }}"
+
 # Output level for logging, including package level options
 log-level = "{{ .BaseConfig.LogLevel }}"
 
@@ -276,9 +290,15 @@ seeds = "{{ .P2P.Seeds }}"
 
 # Comma separated list of peers to be added to the peer store
 # on startup. Either BootstrapPeers or PersistentPeers are
-# needed for peer discovery
+# needed for peer discovery.
+# DNS seeds (dnsseed://<domain>) add the peers listed in the DNS records
+# of the domain: TXT records containing peer addresses (id@host:port), and
+# SRV records whose target's first label is the peer ID.
 bootstrap-peers = "{{ .P2P.BootstrapPeers }}"
 
+# How often DNS seeds are resolved again. 0 resolves them only on startup.
+dns-seed-interval = "{{ .P2P.DNSSeedInterval }}"
+
 # Comma separated list of nodes to keep persistent connections to
 persistent-peers = "{{ .P2P.PersistentPeers }}"
 
@@ -291,9 +311,35 @@ max-connections = {{ .P2P.MaxConnections }}
 # Rate limits the number of incoming connection attempts per IP address.
 max-incoming-connection-attempts = {{ .P2P.MaxIncomingConnectionAttempts }}
 
+# Maximum number of connections, and of peers in the peer store, in the same
+# IPv4 /16 or IPv6 /32 subnet. Peers on private and loopback addresses and
+# persistent peers are exempt. 0 means no limit. For public nodes, 16 and 64
+# are recommended.
+max-connections-per-subnet = {{ .P2P.MaxConnectionsPerSubnet }}
+max-peers-per-subnet = {{ .P2P.MaxPeersPerSubnet }}
+
+# Maximum number of connections, and of peers in the peer store, in the same
+# autonomous system, as given by asn-map-file. 0 means no limit.
+max-connections-per-asn = {{ .P2P.MaxConnectionsPerASN }}
+max-peers-per-asn = {{ .P2P.MaxPeersPerASN }}
+
+# Path to a file mapping IP ranges to autonomous system numbers, with one
+# "<CIDR> <ASN>" pair per line (e.g. "1.1.1.0/24 AS13335"), for the ASN limits.
+asn-map-file = "{{ js .P2P.ASNMap }}"
+
 # Set true to enable the peer-exchange reactor
 pex = {{ .P2P.PexReactor }}
 
+# Set true for seed nodes to crawl the network: every known peer is dialed
+# every crawl-interval, and its NodeInfo, uptime and dial success rate are
+# recorded and served by the crawled_peers RPC endpoint. Seed nodes start
+# their RPC server only when crawling. Reachable and compatible peers
+# running the newest software versions are favoured in PEX responses.
+crawl = {{ .P2P.Crawl }}
+
+# How often each peer is dialed when crawling.
+crawl-interval = "{{ .P2P.CrawlInterval }}"
+
 # Comma separated list of peer IDs to keep private (will not be gossiped to other peers)
 # Warning: IPs will be exposed at /net_info, for more information https://github.com/tendermint/tendermint/issues/3055
 private-peer-ids = "{{ .P2P.PrivatePeerIDs }}"
@@ -301,6 +347,12 @@ private-peer-ids = "{{ .P2P.PrivatePeerIDs }}"
 # Toggle to disable guard against peers connecting from the same ip.
 allow-duplicate-ip = {{ .P2P.AllowDuplicateIP }}
 
+# Protocol securing peer connections: "secret-connection" or "noise".
+# With "noise", nodes negotiate the protocol before a Noise_XX handshake.
+# Nodes using "noise" can't connect to nodes using "secret-connection", so
+# the nodes of a network should switch together.
+secure-channel = "{{ .P2P.SecureChannel }}"
+
 # Peer connection configuration.
 handshake-timeout = "{{ .P2P.HandshakeTimeout }}"
 dial-timeout = "{{ .P2P.DialTimeout }}"
@@ -321,6 +373,22 @@ send-rate = {{ .P2P.SendRate }}
 # TODO: Remove once MConnConnection is removed.
 recv-rate = {{ .P2P.RecvRate }}
 
+# Rate at which the router sends messages to each peer, in bytes/second,
+# regardless of the transport. 0 means unlimited. Consensus channels are
+# guaranteed a minimum share of it, which other channels can use while
+# consensus doesn't.
+peer-send-rate = {{ .P2P.PeerSendRate }}
+
+# Rate at which the router receives messages from each peer, in bytes/second,
+# regardless of the transport. 0 means unlimited.
+peer-recv-rate = {{ .P2P.PeerRecvRate }}
+
+# Comma separated list of send and receive rates of individual channels with
+# each peer, in bytes/second, as "<channel id>:<send rate>:<recv rate>".
+# A rate of 0 means unlimited.
+# example: "0x30:1024000:1024000,0x38:512000:0"
+channel-rate-limits = "{{ .P2P.ChannelRateLimits }}"
+
 
 #######################################################
 ###          Mempool Configuration Option          ###
@@ -355,6 +423,48 @@ max-tx-bytes = {{ .Mempool.MaxTxBytes }}
 # XXX: Unused due to https://github.com/tendermint/tendermint/issues/5796
 max-batch-bytes = {{ .Mempool.MaxBatchBytes }}
 
+# Transactions are announced to peers by their keys, and peers request the
+# ones they don't have. announce-interval is the maximum time to wait for more
+# transactions to batch into an announcement. 0 announces the transactions
+# available right away.
+announce-interval = "{{ .Mempool.AnnounceInterval }}"
+
+# How long to wait for a peer to send a requested transaction before
+# requesting it from another peer that announced it.
+tx-request-timeout = "{{ .Mempool.TxRequestTimeout }}"
+
+# Comma separated list of lanes the application can assign transactions to in
+# ResponseCheckTx, as "<name>:<max txs>:<max bytes>:<block share>:<gossip priority>"
+# (e.g. "oracle:1000:1048576:10:1"). A lane holds at most max txs transactions
+# of at most max bytes in total, within the mempool-wide limits (0 means no lane
+# limit), and is guaranteed block share percent of the bytes of each block
+# proposed by this node. Lanes with a higher gossip priority are announced to
+# peers first, and lanes with a positive gossip priority without waiting for
+# announce-interval. Transactions without a configured lane go to the "default"
+# lane, with priority 0. When the mempool is full, transactions only evict
+# lower priority transactions of lanes with a lower or equal gossip priority.
+lanes = "{{ .Mempool.Lanes }}"
+
+# Maximum number of transactions per second checked from each peer, with
+# bursts of up to a second worth of transactions. Transactions above the limit
+# are dropped. 0 means unlimited.
+peer-tx-rate = {{ .Mempool.PeerTxRate }}
+
+# Maximum number of transaction bytes per second checked from each peer.
+# 0 means unlimited.
+peer-tx-bytes-rate = {{ .Mempool.PeerTxBytesRate }}
+
+# Number of invalid, duplicate or rate limited transactions received from a
+# peer after which its score is reduced. Duplicates only count if they were
+# neither requested from the peer nor pushed by a peer without have/want
+# gossip. 0 disables score reductions.
+bad-txs-per-penalty = {{ .Mempool.BadTxsPerPenalty }}
+
+# Number of invalid, duplicate or rate limited transactions a peer may send in
+# excess of its accepted transactions before it is disconnected. 0 disables
+# disconnections.
+max-excess-bad-txs = {{ .Mempool.MaxExcessBadTxs }}
+
 # ttl-duration, if non-zero, defines the maximum amount of time a transaction
 # can exist for in the mempool.
 #
@@ -371,6 +481,25 @@ ttl-duration = "{{ .Mempool.TTLDuration }}"
 # it's insertion time into the mempool is beyond ttl-duration.
 ttl-num-blocks = {{ .Mempool.TTLNumBlocks }}
 
+#######################################################
+###         Block Sync Configuration Options        ###
+#######################################################
+[blocksync]
+
+# If true, block sync first verifies the headers of the blocks to sync with light client
+# skipping verification, starting from the node's trusted state, and fetches the blocks
+# themselves from peers out of order and in parallel, checking each against its verified
+# header. Headers are fetched from peers over the state sync light block channel.
+header-first = {{ .BlockSync.HeaderFirst }}
+
+# Time period during which the headers verified in header-first block sync can be trusted.
+# If the node's trusted state is older than this, blocks are synced without verifying
+# headers first. Should usually be about 2/3 of the unbonding time.
+trust-period = "{{ .BlockSync.TrustPeriod }}"
+
+# How far into the future the time of a header verified in header-first block sync can be.
+max-clock-drift = "{{ .BlockSync.MaxClockDrift }}"
+
 #######################################################
 ###         State Sync Configuration Options        ###
 #######################################################
@@ -406,6 +535,8 @@ discovery-time = "{{ .StateSync.DiscoveryTime }}"
 # Temporary directory for state sync snapshot chunks, defaults to os.TempDir().
 # The synchronizer will create a new, randomly named directory within this directory
 # and remove it when the sync is complete.
+# Deprecated: nodes keep snapshot chunks in the statesync directory of the data
+# directory instead, so that an interrupted sync can be resumed.
 temp-dir = "{{ .StateSync.TempDir }}"
 
 # The timeout duration before re-requesting a chunk, possibly from a different
@@ -471,13 +602,43 @@ peer-query-maj23-sleep-duration = "{{ .Consensus.PeerQueryMaj23SleepDuration }}"
 #   1) "null"
 #   2) "kv" (default) - the simplest possible indexer, backed by key-value storage (defaults to levelDB; see DBBackend).
 #   3) "psql" - the indexer services backed by PostgreSQL.
-# When "kv" or "psql" is chosen "tx.height" and "tx.hash" will always be indexed.
+#   4) "webhook" - posts batches of block and tx events as signed JSON to webhook-urls.
+#   5) "sqlite" - the indexer services backed by an embedded SQLite database
+#      (<db-dir>/tx_index.sqlite), with the schema of "psql" and support for searching.
+# When "kv", "psql" or "sqlite" is chosen "tx.height" and "tx.hash" will always be indexed.
 indexer = [{{ range $i, $e := .TxIndex.Indexer }}{{if $i}}, {{end}}{{ printf "%q" $e}}{{end}}]
 
 # The PostgreSQL connection configuration, the connection format:
 #   postgresql://<user>:<password>@<host>:<port>/<db>?<opts>
 psql-conn = "{{ .TxIndex.PsqlConn }}"
 
+# The URLs the webhook indexer posts events to. Events are kept in an outbox
+# database until every URL has accepted them, so receivers that are down
+# receive them once they are back.
+webhook-urls = [{{ range $i, $e := .TxIndex.WebhookURLs }}{{if $i}}, {{end}}{{ printf "%q" $e}}{{end}}]
+
+# The secret used to sign webhook requests. The hex-encoded HMAC-SHA256 of the
+# request body is sent in the X-Tendermint-Signature header.
+webhook-secret = "{{ .TxIndex.WebhookSecret }}"
+
+# Maximum number of events posted in a single webhook request.
+webhook-batch-size = {{ .TxIndex.WebhookBatchSize }}
+
+# Timeout of a webhook request.
+webhook-timeout = "{{ .TxIndex.WebhookTimeout }}"
+
+# Rules selecting the event attributes the indexer indexes, among those the
+# application marks for indexing. Rules are glob patterns ("*" matches any
+# sequence of characters, "?" a single one) matched against the composite key
+# "type.key" of the attributes, e.g. "transfer.*" or "*.sender". If index-allow
+# is not empty, only the attributes matching one of its rules are indexed, and
+# the attributes matching one of the index-deny rules are never indexed.
+# A rule prefixed with the name of an indexer and a colon, e.g. "kv:message.*",
+# only applies to that indexer. Searches on attributes which are not indexed
+# report an error.
+index-allow = [{{ range $i, $e := .TxIndex.IndexAllow }}{{if $i}}, {{end}}{{ printf "%q" $e}}{{end}}]
+index-deny = [{{ range $i, $e := .TxIndex.IndexDeny }}{{if $i}}, {{end}}{{ printf "%q" $e}}{{end}}]
+
 #######################################################
 ###       Instrumentation Configuration Options     ###
 #######################################################
@@ -0,0 +1,372 @@
+package blocksync
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	tmsync "github.com/tendermint/tendermint/internal/libs/sync"
+	sm "github.com/tendermint/tendermint/internal/state"
+	tmmath "github.com/tendermint/tendermint/libs/math"
+	"github.com/tendermint/tendermint/light"
+	"github.com/tendermint/tendermint/types"
+)
+
+const (
+	// headerWindow is how far ahead of the pool height headers are verified.
+	// The blocks below a pivot are verified by following their hash links
+	// back from it, so the pool must be able to request all of them at once.
+	headerWindow = maxTotalRequesters - 1
+
+	// headerPivotInterval is the minimum number of heights between two
+	// pivots, unless the later one is the last block that can be synced.
+	headerPivotInterval = maxBlockRange
+
+	// headerRetries is the number of times fetching a light block is
+	// attempted before giving up on the current verification.
+	headerRetries = 10
+
+	headerRetryInterval = 100 * time.Millisecond
+)
+
+// LightBlockProvider fetches light blocks from peers. In header-first block
+// sync it provides the pivot headers of the blocks to sync, which are verified
+// before the blocks themselves are requested.
+type LightBlockProvider interface {
+	// LightBlock returns the light block at the given height from one of the
+	// peers. The light block is not verified.
+	LightBlock(ctx context.Context, height int64) (*types.LightBlock, error)
+}
+
+// errNoHeader is returned when a block is checked against a header that
+// hasn't been verified yet.
+var errNoHeader = errors.New("header not verified yet")
+
+// headerVerifier verifies the headers of the blocks to sync ahead of the
+// blocks themselves. Starting from the trusted state, it verifies pivot
+// headers with the light client's skipping verification, i.e. a pivot is
+// trusted if enough of the trusted validators signed it, bisecting towards the
+// trusted height if not. The blocks up to a pivot are then requested, and
+// verified as they are received by following their hash links back from the
+// pivot, see Link.
+//
+// Like the light client, this relies on at least a third of the voting power
+// of the trusted validators being correct for the heights that are skipped,
+// and so only within the trusting period, rather than checking the signatures
+// of every commit. Blocks are still fully validated when they are applied.
+type headerVerifier struct {
+	chainID        string
+	provider       LightBlockProvider
+	trustLevel     tmmath.Fraction
+	trustingPeriod time.Duration
+	maxClockDrift  time.Duration
+
+	mtx tmsync.RWMutex
+	// the highest verified header and the validators that sign pivots
+	// skipping from it, or nil and the genesis validators before the first
+	// block of the chain
+	trusted     *types.SignedHeader
+	trustedVals *types.ValidatorSet
+	genesisTime time.Time
+	// height of the highest verified header
+	height int64
+	// height and hash of the last applied block
+	base     int64
+	baseHash []byte
+	// the verified hashes of the blocks above the last applied height, of the
+	// pivots and of the blocks linked to them, the heights of the received
+	// blocks that have been linked, and the verified commits of the pivots
+	hashes  map[int64][]byte
+	linked  map[int64]bool
+	commits map[int64]*types.Commit
+}
+
+// newHeaderVerifier returns a header verifier that trusts the given state.
+func newHeaderVerifier(
+	state sm.State,
+	provider LightBlockProvider,
+	trustingPeriod time.Duration,
+	maxClockDrift time.Duration,
+) *headerVerifier {
+	hv := &headerVerifier{
+		chainID:        state.ChainID,
+		provider:       provider,
+		trustLevel:     light.DefaultTrustLevel,
+		trustingPeriod: trustingPeriod,
+		maxClockDrift:  maxClockDrift,
+	}
+	hv.reset(state)
+	return hv
+}
+
+// reset discards the verified headers and trusts the given state instead.
+func (hv *headerVerifier) reset(state sm.State) {
+	hv.mtx.Lock()
+	defer hv.mtx.Unlock()
+
+	hv.base = state.LastBlockHeight
+	hv.trusted = nil
+	if hv.base == 0 {
+		hv.base = state.InitialHeight - 1
+		hv.genesisTime = state.LastBlockTime
+	} else {
+		// only the fields used by skipping verification are known
+		hv.trusted = &types.SignedHeader{
+			Header: &types.Header{
+				ChainID:            state.ChainID,
+				Height:             state.LastBlockHeight,
+				Time:               state.LastBlockTime,
+				NextValidatorsHash: state.Validators.Hash(),
+			},
+		}
+	}
+	hv.baseHash = state.LastBlockID.Hash
+	hv.height = hv.base
+	hv.trustedVals = state.Validators
+	hv.hashes = make(map[int64][]byte)
+	hv.linked = make(map[int64]bool)
+	hv.commits = make(map[int64]*types.Commit)
+}
+
+// Height returns the height of the highest verified header.
+func (hv *headerVerifier) Height() int64 {
+	hv.mtx.RLock()
+	defer hv.mtx.RUnlock()
+	return hv.height
+}
+
+// Expired returns true if the trusted header, or the genesis before the first
+// block, is older than the trusting period. Headers can't be verified from it
+// then.
+func (hv *headerVerifier) Expired(now time.Time) bool {
+	hv.mtx.RLock()
+	defer hv.mtx.RUnlock()
+
+	if hv.trusted == nil {
+		return !hv.genesisTime.Add(hv.trustingPeriod).After(now)
+	}
+	return light.HeaderExpired(hv.trusted, hv.trustingPeriod, now)
+}
+
+// VerifyTo verifies a pivot header at the target height, which becomes the
+// height of the highest verified header. If the header at the target height
+// can't be trusted from the currently trusted validators, a header at a lower
+// height is verified first. A light.ErrOldHeaderExpired is returned if the
+// pivot is older than the trusting period.
+func (hv *headerVerifier) VerifyTo(ctx context.Context, target int64) error {
+	// light blocks that could not be trusted yet, to retry from later pivots
+	cache := make(map[int64]*types.LightBlock)
+
+	for {
+		hv.mtx.RLock()
+		height, trusted, trustedVals := hv.height, hv.trusted, hv.trustedVals
+		hv.mtx.RUnlock()
+
+		if target <= height {
+			return nil
+		}
+
+		pivot := target
+		if trusted == nil {
+			pivot = height + 1
+		}
+		for {
+			lb, ok := cache[pivot]
+			if !ok {
+				var err error
+				if lb, err = hv.fetch(ctx, pivot); err != nil {
+					return err
+				}
+				cache[pivot] = lb
+			}
+
+			err := lb.ValidateBasic(hv.chainID)
+			if err == nil && trusted == nil {
+				err = hv.verifyFirst(lb, trustedVals, time.Now())
+			} else if err == nil {
+				err = light.Verify(trusted, trustedVals, lb.SignedHeader, lb.ValidatorSet,
+					hv.trustingPeriod, time.Now(), hv.maxClockDrift, hv.trustLevel)
+			}
+			var errTrust light.ErrNewValSetCantBeTrusted
+			if errors.As(err, &errTrust) && pivot > height+1 {
+				// bisect towards the trusted height
+				pivot = height + (pivot-height)/2
+				continue
+			}
+			if err != nil {
+				return fmt.Errorf("failed to verify header at height %d: %w", pivot, err)
+			}
+
+			hv.trust(height, lb)
+			break
+		}
+	}
+}
+
+// verifyFirst verifies the first block of the chain, which has no trusted
+// header to skip from, with the genesis validators.
+func (hv *headerVerifier) verifyFirst(lb *types.LightBlock, vals *types.ValidatorSet, now time.Time) error {
+	if light.HeaderExpired(lb.SignedHeader, hv.trustingPeriod, now) {
+		return light.ErrOldHeaderExpired{At: lb.Time.Add(hv.trustingPeriod), Now: now}
+	}
+	if lb.Time.Before(hv.genesisTime) {
+		return light.ErrInvalidHeader{Reason: fmt.Errorf("header time %v is before genesis time %v",
+			lb.Time, hv.genesisTime)}
+	}
+	if !lb.Time.Before(now.Add(hv.maxClockDrift)) {
+		return light.ErrInvalidHeader{Reason: fmt.Errorf("header has a time from the future %v (now: %v)",
+			lb.Time, now)}
+	}
+	if !bytes.Equal(lb.ValidatorsHash, vals.Hash()) {
+		return light.ErrInvalidHeader{Reason: fmt.Errorf("expected genesis validators %X, got %X",
+			vals.Hash(), lb.ValidatorsHash)}
+	}
+	if err := vals.VerifyCommitLight(hv.chainID, lb.Commit.BlockID, lb.Height, lb.Commit); err != nil {
+		return light.ErrInvalidHeader{Reason: err}
+	}
+	return nil
+}
+
+// trust makes a verified pivot the highest verified header, unless the
+// verifier was reset since verification started at the given height.
+func (hv *headerVerifier) trust(height int64, pivot *types.LightBlock) {
+	hv.mtx.Lock()
+	defer hv.mtx.Unlock()
+
+	if hv.height != height {
+		return
+	}
+	hv.hashes[pivot.Height] = pivot.Hash()
+	hv.commits[pivot.Height] = pivot.Commit
+	hv.trusted = pivot.SignedHeader
+	hv.trustedVals = pivot.ValidatorSet
+	hv.height = pivot.Height
+}
+
+// fetch fetches the light block at the given height, retrying on errors.
+func (hv *headerVerifier) fetch(ctx context.Context, height int64) (*types.LightBlock, error) {
+	var err error
+	for i := 0; i < headerRetries; i++ {
+		var lb *types.LightBlock
+		lb, err = hv.provider.LightBlock(ctx, height)
+		if err == nil {
+			if lb.Height != height {
+				return nil, fmt.Errorf("expected light block at height %d, got %d", height, lb.Height)
+			}
+			return lb, nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(headerRetryInterval):
+		}
+	}
+	return nil, fmt.Errorf("failed to fetch light block at height %d: %w", height, err)
+}
+
+// Link verifies the received blocks from the highest verified header down. A
+// block that matches its verified hash also verifies the hash of the block
+// below it, so that the blocks below a pivot are verified one after the other
+// as they are received. getBlock returns the received block at a height, or
+// nil, and is called with the verifier locked. The heights of the blocks that
+// don't match are returned, for them to be requested again.
+func (hv *headerVerifier) Link(getBlock func(height int64) *types.Block) []int64 {
+	hv.mtx.Lock()
+	defer hv.mtx.Unlock()
+
+	var bad []int64
+	for height := hv.height; height > hv.base; height-- {
+		if _, ok := hv.hashes[height]; !ok || hv.linked[height] {
+			continue
+		}
+		block := getBlock(height)
+		if block == nil {
+			continue
+		}
+		if err := hv.verifyBlock(block); err != nil {
+			bad = append(bad, height)
+			continue
+		}
+
+		hv.linked[height] = true
+		if _, ok := hv.hashes[height-1]; !ok && height-1 > hv.base {
+			hv.hashes[height-1] = block.LastBlockID.Hash
+		}
+	}
+	return bad
+}
+
+// VerifyBlock checks a block against its verified hash, and the hash of the
+// block below it if that is verified too. It returns errNoHeader if the hash
+// at the height of the block hasn't been verified.
+func (hv *headerVerifier) VerifyBlock(block *types.Block) error {
+	hv.mtx.RLock()
+	defer hv.mtx.RUnlock()
+	return hv.verifyBlock(block)
+}
+
+// NOTE: requires the verifier's mtx lock
+func (hv *headerVerifier) verifyBlock(block *types.Block) error {
+	hash, ok := hv.hashes[block.Height]
+	if !ok {
+		return errNoHeader
+	}
+
+	if err := block.ValidateBasic(); err != nil {
+		return err
+	}
+	if !bytes.Equal(block.Hash(), hash) {
+		return fmt.Errorf("expected block hash %X, got %X", hash, block.Hash())
+	}
+
+	lastHash, ok := hv.hashes[block.Height-1]
+	if block.Height-1 == hv.base {
+		lastHash, ok = hv.baseHash, len(hv.baseHash) > 0
+	}
+	if ok && !bytes.Equal(block.LastBlockID.Hash, lastHash) {
+		return fmt.Errorf("expected last block hash %X, got %X", lastHash, block.LastBlockID.Hash)
+	}
+	return nil
+}
+
+// Commit returns the verified commit for the block at the given height. This
+// is the commit of a pivot, or else the last commit of the next block, which
+// must match its verified hash. It returns nil if neither is available.
+func (hv *headerVerifier) Commit(height int64, next *types.Block) *types.Commit {
+	hv.mtx.RLock()
+	commit, ok := hv.commits[height]
+	hv.mtx.RUnlock()
+	if ok {
+		return commit
+	}
+
+	if next == nil || next.Height != height+1 || hv.VerifyBlock(next) != nil {
+		return nil
+	}
+	return next.LastCommit
+}
+
+// Prune discards the verified hashes and commits up to the given height, the
+// height of the last applied block.
+func (hv *headerVerifier) Prune(height int64) {
+	hv.mtx.Lock()
+	defer hv.mtx.Unlock()
+
+	if height <= hv.base {
+		return
+	}
+	if hash, ok := hv.hashes[height]; ok {
+		hv.baseHash = hash
+	}
+	hv.base = height
+	for h := range hv.hashes {
+		if h <= height {
+			delete(hv.hashes, h)
+			delete(hv.linked, h)
+			delete(hv.commits, h)
+		}
+	}
+}
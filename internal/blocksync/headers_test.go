@@ -0,0 +1,242 @@
+package blocksync
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	sm "github.com/tendermint/tendermint/internal/state"
+	"github.com/tendermint/tendermint/internal/test/factory"
+	"github.com/tendermint/tendermint/light"
+	tmproto "github.com/tendermint/tendermint/proto/tendermint/types"
+	"github.com/tendermint/tendermint/types"
+)
+
+type mapLightBlocks map[int64]*types.LightBlock
+
+func (m mapLightBlocks) LightBlock(ctx context.Context, height int64) (*types.LightBlock, error) {
+	lb, ok := m[height]
+	if !ok {
+		return nil, errors.New("light block not found")
+	}
+	return lb, nil
+}
+
+// countingLightBlocks counts the light blocks fetched from it at each height.
+type countingLightBlocks struct {
+	mapLightBlocks
+	fetched map[int64]int
+}
+
+func (c *countingLightBlocks) LightBlock(ctx context.Context, height int64) (*types.LightBlock, error) {
+	c.fetched[height]++
+	return c.mapLightBlocks.LightBlock(ctx, height)
+}
+
+// receivedBlocks returns the blocks as getBlock for headerVerifier.Link.
+func receivedBlocks(blocks map[int64]*types.Block) func(int64) *types.Block {
+	return func(height int64) *types.Block { return blocks[height] }
+}
+
+// makeHeaderChain makes a chain of blocks and their light blocks up to the
+// given height. The validator set is replaced entirely at the given heights.
+func makeHeaderChain(t *testing.T, height int64, newVals map[int64]bool) (
+	map[int64]*types.Block, mapLightBlocks, *types.ValidatorSet,
+) {
+	var (
+		blocks      = make(map[int64]*types.Block)
+		lightBlocks = make(mapLightBlocks)
+		lastBlockID types.BlockID
+		lastCommit  = types.NewCommit(0, 0, types.BlockID{}, nil)
+		blockTime   = time.Now().Add(-time.Duration(height) * time.Minute)
+	)
+	vals, privVals := factory.RandValidatorSet(3, 10)
+	genesisVals := vals
+
+	for h := int64(1); h <= height; h++ {
+		nextVals, nextPrivVals := vals, privVals
+		if newVals[h+1] {
+			nextVals, nextPrivVals = factory.RandValidatorSet(3, 10)
+		}
+
+		block := types.MakeBlock(h, factory.MakeTenTxs(h), lastCommit, nil)
+		header, err := factory.MakeHeader(&types.Header{
+			Height:             h,
+			Time:               blockTime,
+			LastBlockID:        lastBlockID,
+			LastCommitHash:     lastCommit.Hash(),
+			DataHash:           block.Data.Hash(),
+			ValidatorsHash:     vals.Hash(),
+			NextValidatorsHash: nextVals.Hash(),
+			EvidenceHash:       block.Evidence.Hash(),
+			ProposerAddress:    vals.Proposer.Address,
+		})
+		require.NoError(t, err)
+		block.Header = *header
+
+		blockID := types.BlockID{
+			Hash:          block.Hash(),
+			PartSetHeader: block.MakePartSet(types.BlockPartSizeBytes).Header(),
+		}
+		voteSet := types.NewVoteSet(factory.DefaultTestChainID, h, 0, tmproto.PrecommitType, vals)
+		commit, err := factory.MakeCommit(blockID, h, 0, voteSet, privVals, blockTime)
+		require.NoError(t, err)
+
+		blocks[h] = block
+		lightBlocks[h] = &types.LightBlock{
+			SignedHeader: &types.SignedHeader{Header: &block.Header, Commit: commit},
+			ValidatorSet: vals,
+		}
+
+		lastBlockID, lastCommit = blockID, commit
+		vals, privVals = nextVals, nextPrivVals
+		blockTime = blockTime.Add(time.Minute)
+	}
+
+	return blocks, lightBlocks, genesisVals
+}
+
+func TestHeaderVerifier(t *testing.T) {
+	ctx := context.Background()
+	blocks, lightBlocks, vals := makeHeaderChain(t, 40, map[int64]bool{15: true, 16: true, 30: true})
+
+	state := sm.State{
+		ChainID:       factory.DefaultTestChainID,
+		InitialHeight: 1,
+		LastBlockTime: lightBlocks[1].Time,
+		Validators:    vals,
+	}
+	provider := &countingLightBlocks{mapLightBlocks: lightBlocks, fetched: make(map[int64]int)}
+	hv := newHeaderVerifier(state, provider, time.Hour, 10*time.Second)
+	require.EqualValues(t, 0, hv.Height())
+	require.False(t, hv.Expired(time.Now()))
+
+	// the validators have been replaced entirely three times, so the verifier
+	// has to bisect to get there, fetching each pivot once
+	require.NoError(t, hv.VerifyTo(ctx, 39))
+	require.EqualValues(t, 39, hv.Height())
+	for h, n := range provider.fetched {
+		require.Equal(t, 1, n, "height %d", h)
+	}
+
+	// the blocks below the pivots are verified by their hash links as they
+	// are received
+	var between int64
+	for h := int64(38); h > 0 && between == 0; h-- {
+		if _, ok := hv.commits[h]; !ok {
+			between = h
+		}
+	}
+	require.ErrorIs(t, hv.VerifyBlock(blocks[between]), errNoHeader)
+	require.Nil(t, hv.Commit(between, nil))
+
+	require.Empty(t, hv.Link(receivedBlocks(blocks)))
+	for h := int64(1); h <= 39; h++ {
+		require.NoError(t, hv.VerifyBlock(blocks[h]), "height %d", h)
+	}
+	require.ErrorIs(t, hv.VerifyBlock(blocks[40]), errNoHeader)
+
+	// the pivot has its own commit, others take it from the next block
+	require.Equal(t, lightBlocks[39].Commit, hv.Commit(39, nil))
+	require.Equal(t, blocks[between+1].LastCommit, hv.Commit(between, blocks[between+1]))
+
+	// blocks that don't match their hashes are rejected
+	tampered := types.MakeBlock(5, factory.MakeTenTxs(100), blocks[5].LastCommit, nil)
+	tampered.Header = blocks[5].Header
+	tampered.DataHash = nil
+	require.Error(t, hv.VerifyBlock(tampered))
+	require.Nil(t, hv.Commit(4, tampered))
+
+	hv.Prune(20)
+	require.ErrorIs(t, hv.VerifyBlock(blocks[20]), errNoHeader)
+	require.NoError(t, hv.VerifyBlock(blocks[21]))
+}
+
+func TestHeaderVerifier_BadBlock(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	blocks, lightBlocks, vals := makeHeaderChain(t, 20, nil)
+	forkedBlocks, forked, _ := makeHeaderChain(t, 20, nil)
+
+	state := sm.State{
+		ChainID:       factory.DefaultTestChainID,
+		InitialHeight: 1,
+		LastBlockTime: lightBlocks[1].Time,
+		Validators:    vals,
+	}
+
+	// a pivot signed by other validators is rejected
+	hv := newHeaderVerifier(state, forked, time.Hour, 10*time.Second)
+	require.Error(t, hv.VerifyTo(ctx, 19))
+	require.EqualValues(t, 0, hv.Height())
+
+	// without validator changes, only the first block and the target are
+	// fetched as light blocks
+	provider := &countingLightBlocks{mapLightBlocks: lightBlocks, fetched: make(map[int64]int)}
+	hv = newHeaderVerifier(state, provider, time.Hour, 10*time.Second)
+	require.NoError(t, hv.VerifyTo(ctx, 19))
+	require.EqualValues(t, 19, hv.Height())
+	require.Equal(t, map[int64]int{1: 1, 19: 1}, provider.fetched)
+
+	// a block below the pivot that doesn't link up is rejected, and the
+	// blocks below it can't be verified until it is received again
+	received := make(map[int64]*types.Block)
+	for h, block := range blocks {
+		received[h] = block
+	}
+	received[10] = forkedBlocks[10]
+	require.Equal(t, []int64{10}, hv.Link(receivedBlocks(received)))
+	require.NoError(t, hv.VerifyBlock(blocks[11]))
+	require.Error(t, hv.VerifyBlock(forkedBlocks[10]))
+	require.ErrorIs(t, hv.VerifyBlock(blocks[9]), errNoHeader)
+
+	received[10] = blocks[10]
+	require.Empty(t, hv.Link(receivedBlocks(received)))
+	require.NoError(t, hv.VerifyBlock(blocks[9]))
+}
+
+func TestHeaderVerifier_TrustPeriod(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	blocks, lightBlocks, vals := makeHeaderChain(t, 40, nil)
+
+	// the chain started 40 minutes ago
+	state := sm.State{
+		ChainID:       factory.DefaultTestChainID,
+		InitialHeight: 1,
+		LastBlockTime: lightBlocks[1].Time,
+		Validators:    vals,
+	}
+	hv := newHeaderVerifier(state, lightBlocks, 30*time.Minute, 10*time.Second)
+	require.True(t, hv.Expired(time.Now()))
+	var errExpired light.ErrOldHeaderExpired
+	require.True(t, errors.As(hv.VerifyTo(ctx, 39), &errExpired))
+
+	// a trusted state after the first block is trusted for the trust period
+	// after its own time
+	state = sm.State{
+		ChainID:         factory.DefaultTestChainID,
+		InitialHeight:   1,
+		LastBlockHeight: 20,
+		LastBlockID:     lightBlocks[21].LastBlockID,
+		LastBlockTime:   lightBlocks[20].Time,
+		Validators:      lightBlocks[21].ValidatorSet,
+	}
+	hv = newHeaderVerifier(state, lightBlocks, 30*time.Minute, 10*time.Second)
+	require.False(t, hv.Expired(time.Now()))
+	require.True(t, hv.Expired(time.Now().Add(15*time.Minute)))
+	require.NoError(t, hv.VerifyTo(ctx, 39))
+	require.Empty(t, hv.Link(receivedBlocks(blocks)))
+	require.NoError(t, hv.VerifyBlock(blocks[21]))
+	require.ErrorIs(t, hv.VerifyBlock(blocks[20]), errNoHeader)
+
+	// headers from the future are rejected
+	hv = newHeaderVerifier(state, lightBlocks, 30*time.Minute, -time.Hour)
+	require.Error(t, hv.VerifyTo(ctx, 39))
+	require.EqualValues(t, 20, hv.Height())
+}
@@ -43,6 +43,18 @@ const (
 
 	// Maximum difference between current and new block's height.
 	maxDiffBetweenCurrentAndReceivedBlockHeight = 100
+
+	// Maximum number of blocks requested from and served to a peer in a
+	// single range request.
+	maxBlockRange = 100
+
+	// Number of blocks requested in a range from a peer whose throughput is
+	// not known yet, and the minimum range size otherwise.
+	minBlockRange = 4
+
+	// Time that a peer should take to send a range of blocks, at its measured
+	// throughput. Ranges are sized accordingly.
+	targetRangeDuration = time.Second
 )
 
 var peerTimeout = 15 * time.Second // not const so we can override with tests
@@ -59,10 +71,12 @@ var peerTimeout = 15 * time.Second // not const so we can override with tests
 */
 
 // BlockRequest stores a block request identified by the block Height and the
-// PeerID responsible for delivering the block.
+// PeerID responsible for delivering the block. If EndHeight is set, the blocks
+// from Height to EndHeight are requested at once.
 type BlockRequest struct {
-	Height int64
-	PeerID types.NodeID
+	Height    int64
+	EndHeight int64
+	PeerID    types.NodeID
 }
 
 // BlockPool keeps track of the block sync peers, block requests and block responses.
@@ -77,6 +91,9 @@ type BlockPool struct {
 	// peers
 	peers         map[types.NodeID]*bpPeer
 	maxPeerHeight int64 // the biggest reported height
+	// the biggest height blocks are requested up to, which is the height of the
+	// last verified header in header-first block sync
+	maxRequestHeight int64
 
 	// atomic
 	numPending int32 // number of requests pending assignment or block response
@@ -87,6 +104,7 @@ type BlockPool struct {
 	startHeight               int64
 	lastHundredBlockTimeStamp time.Time
 	lastSyncRate              float64
+	avgBlockSize              float64 // moving average of received block sizes
 }
 
 // NewBlockPool returns a new BlockPool with the height equal to start. Block
@@ -109,6 +127,8 @@ func NewBlockPool(
 		requestsCh:   requestsCh,
 		errorsCh:     errorsCh,
 		lastSyncRate: 0,
+
+		maxRequestHeight: math.MaxInt64,
 	}
 	bp.BaseService = *service.NewBaseService(logger, "BlockPool", bp)
 	return bp
@@ -144,7 +164,11 @@ func (pool *BlockPool) makeRequestersRoutine() {
 			pool.removeTimedoutPeers()
 		default:
 			// request for more blocks.
-			pool.makeNextRequester()
+			if !pool.makeNextRequester() {
+				// sleep for a bit, until peers report new heights or have
+				// capacity again.
+				time.Sleep(requestIntervalMS * time.Millisecond)
+			}
 		}
 	}
 }
@@ -216,6 +240,18 @@ func (pool *BlockPool) PeekTwoBlocks() (first *types.Block, second *types.Block)
 	return
 }
 
+// PeekBlock returns the block at the given height, or nil if it hasn't been
+// received. The caller will verify the block.
+func (pool *BlockPool) PeekBlock(height int64) *types.Block {
+	pool.mtx.RLock()
+	defer pool.mtx.RUnlock()
+
+	if r := pool.requesters[height]; r != nil {
+		return r.getBlock()
+	}
+	return nil
+}
+
 // PopRequest pops the first block at pool.height.
 // It must have been validated by 'second'.Commit from PeekTwoBlocks().
 func (pool *BlockPool) PopRequest() {
@@ -285,6 +321,11 @@ func (pool *BlockPool) AddBlock(peerID types.NodeID, block *types.Block, blockSi
 
 	if requester.setBlock(block, peerID) {
 		atomic.AddInt32(&pool.numPending, -1)
+		if pool.avgBlockSize == 0 {
+			pool.avgBlockSize = float64(blockSize)
+		} else {
+			pool.avgBlockSize = 0.9*pool.avgBlockSize + 0.1*float64(blockSize)
+		}
 		peer := pool.peers[peerID]
 		if peer != nil {
 			peer.decrPending(blockSize)
@@ -296,6 +337,26 @@ func (pool *BlockPool) AddBlock(peerID types.NodeID, block *types.Block, blockSi
 	}
 }
 
+// NoBlock handles a peer declining to send the block at height. If the block
+// was requested from the peer as part of a range, it and the rest of the range
+// are requested again.
+func (pool *BlockPool) NoBlock(peerID types.NodeID, height int64) {
+	pool.mtx.Lock()
+	defer pool.mtx.Unlock()
+
+	peer := pool.peers[peerID]
+	for h := height; h < height+maxBlockRange; h++ {
+		requester := pool.requesters[h]
+		if requester == nil || !requester.cancelRange(peerID) {
+			continue
+		}
+		if peer != nil {
+			peer.cancelPending()
+		}
+		requester.redo(peerID)
+	}
+}
+
 // MaxPeerHeight returns the highest reported height.
 func (pool *BlockPool) MaxPeerHeight() int64 {
 	pool.mtx.RLock()
@@ -331,6 +392,27 @@ func (pool *BlockPool) SetPeerRange(peerID types.NodeID, base int64, height int6
 	}
 }
 
+// SetPeerMaxRange sets the maximum number of blocks that the peer serves per
+// range request. Blocks are requested one by one from peers with a maximum of
+// zero, which don't support range requests.
+func (pool *BlockPool) SetPeerMaxRange(peerID types.NodeID, maxRange int64) {
+	pool.mtx.Lock()
+	defer pool.mtx.Unlock()
+
+	if peer := pool.peers[peerID]; peer != nil {
+		peer.maxRange = maxRange
+	}
+}
+
+// SetMaxRequestHeight limits the requested blocks to the given height. In
+// header-first block sync, this is the height of the last verified header.
+func (pool *BlockPool) SetMaxRequestHeight(height int64) {
+	pool.mtx.Lock()
+	defer pool.mtx.Unlock()
+
+	pool.maxRequestHeight = height
+}
+
 // RemovePeer removes the peer with peerID from the pool. If there's no peer
 // with peerID, function is a no-op.
 func (pool *BlockPool) RemovePeer(peerID types.NodeID) {
@@ -397,18 +479,151 @@ func (pool *BlockPool) pickIncrAvailablePeer(height int64) *bpPeer {
 	return nil
 }
 
-func (pool *BlockPool) makeNextRequester() {
+// pickRangePeer picks the peer to request the range of blocks starting at
+// height from, among the peers that support range requests and are not busy.
+// Peers are ranked by their measured throughput per pending block, and the
+// range is sized so that the peer can send it within targetRangeDuration. It
+// returns the peer and the end height of the range, or nil if there is no such
+// peer. The caller must hold the mutex lock.
+func (pool *BlockPool) pickRangePeer(height int64) (*bpPeer, int64) {
+	var (
+		best      *bpPeer
+		bestScore float64
+	)
+	for _, peer := range pool.peers {
+		if peer.didTimeout || peer.maxRange == 0 {
+			continue
+		}
+		if height < peer.base || height > peer.height {
+			continue
+		}
+		// allow for a second range to be in flight while the first is served
+		if int64(peer.numPending) >= 2*pool.rangeSize(peer) {
+			continue
+		}
+		score := pool.peerThroughput(peer) / float64(peer.numPending+1)
+		if best == nil || score > bestScore {
+			best = peer
+			bestScore = score
+		}
+	}
+	if best == nil {
+		return nil, 0
+	}
+
+	endHeight := height + pool.rangeSize(best) - 1
+	if endHeight > best.height {
+		endHeight = best.height
+	}
+	if last := height + maxTotalRequesters - pool.requestersLen() - 1; endHeight > last {
+		endHeight = last
+	}
+	if endHeight > pool.maxRequestHeight {
+		endHeight = pool.maxRequestHeight
+	}
+	return best, endHeight
+}
+
+// peerThroughput returns the measured throughput of a peer in bytes per
+// second. Peers that haven't sent any blocks yet are assumed to be as fast as
+// the fastest peer, so that they are given a chance. The caller must hold the
+// mutex lock.
+func (pool *BlockPool) peerThroughput(peer *bpPeer) float64 {
+	if peer.throughput > 0 {
+		return peer.throughput
+	}
+
+	max := float64(minRecvRate)
+	for _, p := range pool.peers {
+		if p.throughput > max {
+			max = p.throughput
+		}
+	}
+	return max
+}
+
+// rangeSize returns the number of blocks to request from a peer in a single
+// range request. The caller must hold the mutex lock.
+func (pool *BlockPool) rangeSize(peer *bpPeer) int64 {
+	size := int64(minBlockRange)
+	if peer.throughput > 0 && pool.avgBlockSize > 0 {
+		size = int64(peer.throughput * targetRangeDuration.Seconds() / pool.avgBlockSize)
+	}
+
+	limit := peer.maxRange
+	if limit > maxBlockRange {
+		limit = maxBlockRange
+	}
+	if size < minBlockRange {
+		size = minBlockRange
+	}
+	if size > limit {
+		size = limit
+	}
+	return size
+}
+
+// makeNextRequester requests the next block(s), and returns false if there
+// was nothing to request.
+func (pool *BlockPool) makeNextRequester() bool {
 	pool.mtx.Lock()
-	defer pool.mtx.Unlock()
 
 	nextHeight := pool.height + pool.requestersLen()
-	if nextHeight > pool.maxPeerHeight {
-		return
+	if nextHeight > pool.maxPeerHeight || nextHeight > pool.maxRequestHeight {
+		pool.mtx.Unlock()
+		return false
+	}
+
+	// Prefer to request a range of blocks at once from a peer that supports it,
+	// otherwise the requester picks a peer to request its block from. While the
+	// range peers are busy, the block is left to them unless peers without range
+	// support have it.
+	peer, endHeight := pool.pickRangePeer(nextHeight)
+	if peer == nil {
+		if pool.onlyRangePeersHave(nextHeight) {
+			pool.mtx.Unlock()
+			return false
+		}
+		pool.startRequester(nextHeight, "")
+		pool.mtx.Unlock()
+		return true
+	}
+
+	for height := nextHeight; height <= endHeight; height++ {
+		peer.incrPending()
+		pool.startRequester(height, peer.id)
 	}
+	pool.mtx.Unlock()
 
-	request := newBPRequester(pool, nextHeight)
+	pool.sendRangeRequest(nextHeight, endHeight, peer.id)
+	return true
+}
 
-	pool.requesters[nextHeight] = request
+// onlyRangePeersHave returns true if only peers that support range requests
+// have the block at height. The caller must hold the mutex lock.
+func (pool *BlockPool) onlyRangePeersHave(height int64) bool {
+	found := false
+	for _, peer := range pool.peers {
+		if peer.didTimeout || height < peer.base || height > peer.height {
+			continue
+		}
+		if peer.maxRange == 0 {
+			return false
+		}
+		found = true
+	}
+	return found
+}
+
+// startRequester starts a requester for the block at height. If peerID is set,
+// the block was requested from that peer as part of a range. The caller must
+// hold the mutex lock.
+func (pool *BlockPool) startRequester(height int64, peerID types.NodeID) {
+	request := newBPRequester(pool, height)
+	request.peerID = peerID
+	request.inRange = peerID != ""
+
+	pool.requesters[height] = request
 	atomic.AddInt32(&pool.numPending, 1)
 
 	err := request.Start()
@@ -425,7 +640,14 @@ func (pool *BlockPool) sendRequest(height int64, peerID types.NodeID) {
 	if !pool.IsRunning() {
 		return
 	}
-	pool.requestsCh <- BlockRequest{height, peerID}
+	pool.requestsCh <- BlockRequest{Height: height, PeerID: peerID}
+}
+
+func (pool *BlockPool) sendRangeRequest(height, endHeight int64, peerID types.NodeID) {
+	if !pool.IsRunning() {
+		return
+	}
+	pool.requestsCh <- BlockRequest{Height: height, EndHeight: endHeight, PeerID: peerID}
 }
 
 func (pool *BlockPool) sendError(err error, peerID types.NodeID) {
@@ -436,6 +658,7 @@ func (pool *BlockPool) sendError(err error, peerID types.NodeID) {
 }
 
 // for debugging purposes
+//
 //nolint:unused
 func (pool *BlockPool) debug() string {
 	pool.mtx.Lock()
@@ -475,6 +698,9 @@ type bpPeer struct {
 	numPending  int32
 	height      int64
 	base        int64
+	maxRange    int64   // max blocks per range request, 0 if unsupported
+	throughput  float64 // moving average of bytes/second while requests are pending
+	lastRecv    time.Time
 	pool        *BlockPool
 	id          types.NodeID
 	recvMonitor *flowrate.Monitor
@@ -518,11 +744,24 @@ func (peer *bpPeer) incrPending() {
 	if peer.numPending == 0 {
 		peer.resetMonitor()
 		peer.resetTimeout()
+		peer.lastRecv = time.Now()
 	}
 	peer.numPending++
 }
 
 func (peer *bpPeer) decrPending(recvSize int) {
+	// the time since the previous block (or the first request) is the time it
+	// took the peer to send this one
+	if elapsed := time.Since(peer.lastRecv).Seconds(); elapsed > 0 {
+		rate := float64(recvSize) / elapsed
+		if peer.throughput == 0 {
+			peer.throughput = rate
+		} else {
+			peer.throughput = 0.8*peer.throughput + 0.2*rate
+		}
+	}
+	peer.lastRecv = time.Now()
+
 	peer.numPending--
 	if peer.numPending == 0 {
 		peer.timeout.Stop()
@@ -532,6 +771,15 @@ func (peer *bpPeer) decrPending(recvSize int) {
 	}
 }
 
+// cancelPending removes a pending request that the peer declined, without
+// counting it towards the peer's throughput.
+func (peer *bpPeer) cancelPending() {
+	peer.numPending--
+	if peer.numPending == 0 {
+		peer.timeout.Stop()
+	}
+}
+
 func (peer *bpPeer) onTimeout() {
 	peer.pool.mtx.Lock()
 	defer peer.pool.mtx.Unlock()
@@ -551,9 +799,10 @@ type bpRequester struct {
 	gotBlockCh chan struct{}
 	redoCh     chan types.NodeID // redo may send multitime, add peerId to identify repeat
 
-	mtx    tmsync.Mutex
-	peerID types.NodeID
-	block  *types.Block
+	mtx     tmsync.Mutex
+	peerID  types.NodeID
+	inRange bool // whether the block was requested from peerID in a range
+	block   *types.Block
 }
 
 func newBPRequester(pool *BlockPool, height int64) *bpRequester {
@@ -604,6 +853,19 @@ func (bpr *bpRequester) getPeerID() types.NodeID {
 	return bpr.peerID
 }
 
+// cancelRange returns true if the block is still expected from peerID as part
+// of a range, and stops expecting it.
+func (bpr *bpRequester) cancelRange(peerID types.NodeID) bool {
+	bpr.mtx.Lock()
+	defer bpr.mtx.Unlock()
+
+	if !bpr.inRange || bpr.peerID != peerID || bpr.block != nil {
+		return false
+	}
+	bpr.inRange = false
+	return true
+}
+
 // This is called from the requestRoutine, upon redo().
 func (bpr *bpRequester) reset() {
 	bpr.mtx.Lock()
@@ -614,6 +876,7 @@ func (bpr *bpRequester) reset() {
 	}
 
 	bpr.peerID = ""
+	bpr.inRange = false
 	bpr.block = nil
 }
 
@@ -632,26 +895,30 @@ func (bpr *bpRequester) redo(peerID types.NodeID) {
 func (bpr *bpRequester) requestRoutine() {
 OUTER_LOOP:
 	for {
-		// Pick a peer to send request to.
-		var peer *bpPeer
-	PICK_PEER_LOOP:
-		for {
-			if !bpr.IsRunning() || !bpr.pool.IsRunning() {
-				return
-			}
-			peer = bpr.pool.pickIncrAvailablePeer(bpr.height)
-			if peer == nil {
-				time.Sleep(requestIntervalMS * time.Millisecond)
-				continue PICK_PEER_LOOP
+		// Blocks requested as part of a range already have a peer assigned,
+		// until they need to be requested again.
+		if bpr.getPeerID() == "" {
+			// Pick a peer to send request to.
+			var peer *bpPeer
+		PICK_PEER_LOOP:
+			for {
+				if !bpr.IsRunning() || !bpr.pool.IsRunning() {
+					return
+				}
+				peer = bpr.pool.pickIncrAvailablePeer(bpr.height)
+				if peer == nil {
+					time.Sleep(requestIntervalMS * time.Millisecond)
+					continue PICK_PEER_LOOP
+				}
+				break PICK_PEER_LOOP
 			}
-			break PICK_PEER_LOOP
-		}
-		bpr.mtx.Lock()
-		bpr.peerID = peer.id
-		bpr.mtx.Unlock()
+			bpr.mtx.Lock()
+			bpr.peerID = peer.id
+			bpr.mtx.Unlock()
 
-		// Send request and wait.
-		bpr.pool.sendRequest(bpr.height, peer.id)
+			// Send request and wait.
+			bpr.pool.sendRequest(bpr.height, peer.id)
+		}
 	WAIT_LOOP:
 		for {
 			select {
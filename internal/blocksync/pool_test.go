@@ -39,10 +39,12 @@ func (p testPeer) runInputRoutine() {
 	}()
 }
 
-// Request desired, pretend like we got the block immediately.
+// Request desired, pretend like we got the block(s) immediately.
 func (p testPeer) simulateInput(input inputData) {
-	block := &types.Block{Header: types.Header{Height: input.request.Height}}
-	input.pool.AddBlock(input.request.PeerID, block, 123)
+	for height := input.request.Height; height == input.request.Height || height <= input.request.EndHeight; height++ {
+		block := &types.Block{Header: types.Header{Height: height}}
+		input.pool.AddBlock(input.request.PeerID, block, 123)
+	}
 	// TODO: uncommenting this creates a race which is detected by:
 	// https://github.com/golang/go/blob/2bd767b1022dd3254bcec469f0ee164024726486/src/testing/testing.go#L854-L856
 	// see: https://github.com/tendermint/tendermint/issues/3390#issue-418379890
@@ -136,6 +138,128 @@ func TestBlockPoolBasic(t *testing.T) {
 	}
 }
 
+func TestBlockPoolRangeRequests(t *testing.T) {
+	start := int64(42)
+	peers := makePeers(10, start+1, 1000)
+	errorsCh := make(chan peerError, 1000)
+	requestsCh := make(chan BlockRequest, 1000)
+	pool := NewBlockPool(log.TestingLogger(), start, requestsCh, errorsCh)
+
+	require.NoError(t, pool.Start())
+	t.Cleanup(func() {
+		if err := pool.Stop(); err != nil {
+			t.Error(err)
+		}
+	})
+
+	peers.start()
+	defer peers.stop()
+
+	// Introduce each peer, half of which support range requests.
+	rangePeers := make(map[types.NodeID]bool)
+	for peerID := range peers {
+		if len(rangePeers) < len(peers)/2 {
+			rangePeers[peerID] = true
+		}
+	}
+	go func() {
+		for _, peer := range peers {
+			pool.SetPeerRange(peer.id, peer.base, peer.height)
+			if rangePeers[peer.id] {
+				pool.SetPeerMaxRange(peer.id, 16)
+			}
+		}
+	}()
+
+	// Start a goroutine to pull blocks
+	go func() {
+		for {
+			if !pool.IsRunning() {
+				return
+			}
+			first, second := pool.PeekTwoBlocks()
+			if first != nil && second != nil {
+				pool.PopRequest()
+			} else {
+				time.Sleep(10 * time.Millisecond)
+			}
+		}
+	}()
+
+	// Pull from channels
+	numRanges := 0
+	for {
+		select {
+		case err := <-errorsCh:
+			t.Error(err)
+		case request := <-requestsCh:
+			if request.EndHeight > request.Height {
+				require.True(t, rangePeers[request.PeerID], "range requested from peer without support")
+				require.LessOrEqual(t, request.EndHeight-request.Height+1, int64(16))
+				numRanges++
+			}
+			if request.Height <= 300 && 300 <= request.EndHeight || request.Height == 300 {
+				require.NotZero(t, numRanges)
+				return // Done!
+			}
+
+			peers[request.PeerID].inputChan <- inputData{t, pool, request}
+		}
+	}
+}
+
+func TestBlockPoolNoBlockInRange(t *testing.T) {
+	start := int64(42)
+	peerID := types.NodeID("aa")
+	errorsCh := make(chan peerError, 1000)
+	requestsCh := make(chan BlockRequest, 1000)
+	pool := NewBlockPool(log.TestingLogger(), start, requestsCh, errorsCh)
+
+	require.NoError(t, pool.Start())
+	t.Cleanup(func() {
+		if err := pool.Stop(); err != nil {
+			t.Error(err)
+		}
+	})
+
+	pool.SetPeerRange(peerID, start, 1000)
+	pool.SetPeerMaxRange(peerID, 16)
+
+	request := <-requestsCh
+	require.Equal(t, start, request.Height)
+	require.Greater(t, request.EndHeight, start+2)
+
+	// the peer sends two blocks and declines the rest of the range, which is
+	// requested again
+	for height := start; height < start+2; height++ {
+		pool.AddBlock(peerID, &types.Block{Header: types.Header{Height: height}}, 123)
+	}
+	pool.NoBlock(peerID, start+2)
+
+	redone := make(map[int64]bool)
+	timeout := time.After(5 * time.Second)
+	for int64(len(redone)) < request.EndHeight-start-1 {
+		select {
+		case err := <-errorsCh:
+			t.Error(err)
+		case r := <-requestsCh:
+			if r.EndHeight == 0 && r.Height >= start+2 && r.Height <= request.EndHeight {
+				redone[r.Height] = true
+			}
+		case <-timeout:
+			require.FailNow(t, "declined blocks were not requested again", "redone", redone)
+		}
+	}
+
+	// a block that is not part of a range is not requested again
+	pool.NoBlock(peerID, start+2)
+	select {
+	case r := <-requestsCh:
+		require.NotEqual(t, start+2, r.Height)
+	case <-time.After(100 * time.Millisecond):
+	}
+}
+
 func TestBlockPoolTimeout(t *testing.T) {
 	start := int64(42)
 	peers := makePeers(10, start+1, 1000)
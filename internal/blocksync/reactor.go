@@ -1,17 +1,24 @@
 package blocksync
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"math"
 	"runtime/debug"
 	"sync"
 	"time"
 
+	"github.com/gogo/protobuf/proto"
+
+	"github.com/tendermint/tendermint/config"
 	"github.com/tendermint/tendermint/internal/consensus"
 	"github.com/tendermint/tendermint/internal/p2p"
 	sm "github.com/tendermint/tendermint/internal/state"
 	"github.com/tendermint/tendermint/internal/store"
 	"github.com/tendermint/tendermint/libs/log"
 	"github.com/tendermint/tendermint/libs/service"
+	"github.com/tendermint/tendermint/light"
 	tmsync "github.com/tendermint/tendermint/libs/sync"
 	bcproto "github.com/tendermint/tendermint/proto/tendermint/blocksync"
 	"github.com/tendermint/tendermint/types"
@@ -33,6 +40,17 @@ const (
 
 	// switch to consensus after this duration of inactivity
 	syncTimeout = 60 * time.Second
+
+	// check if more headers should be verified in header-first block sync
+	headerSyncIntervalMS = 100
+
+	// maximum number of block ranges served to a peer at once; further ranges
+	// are declined until one of them is done
+	maxPeerRanges = 2
+
+	// maximum number of bytes of blocks served in a single range; the rest of
+	// the range is declined, for the peer to request it again
+	maxRangeBytes = 16 * 1024 * 1024
 )
 
 func GetChannelDescriptor() *p2p.ChannelDescriptor {
@@ -86,9 +104,21 @@ type Reactor struct {
 	peerUpdates          *p2p.PeerUpdates
 	closeCh              chan struct{}
 
+	// rangesMtx guards ranges, the number of block ranges being served to
+	// each peer.
+	rangesMtx sync.Mutex
+	ranges    map[types.NodeID]int
+
 	requestsCh <-chan BlockRequest
 	errorsCh   <-chan peerError
 
+	// headers verifies the headers of the blocks to sync ahead of the blocks
+	// themselves in header-first block sync. It is nil otherwise.
+	headers *headerVerifier
+	// headerFirst is set while block sync is header-first. It is unset if
+	// the trusted state is older than the trusting period.
+	headerFirst *tmsync.AtomicBool
+
 	// poolWG is used to synchronize the graceful shutdown of the poolRoutine and
 	// requestRoutine spawned goroutines when stopping the reactor and before
 	// stopping the p2p Channel(s).
@@ -99,7 +129,11 @@ type Reactor struct {
 	syncStartTime time.Time
 }
 
-// NewReactor returns new reactor instance.
+// NewReactor returns new reactor instance. If lightBlocks is not nil, block
+// sync is header-first: the headers of the blocks to sync are fetched from it
+// and verified before the blocks are requested, and each block is checked
+// against its header. Otherwise, or if the trusted state is older than the
+// trust period of cfg, each block is verified by the commit in the next block.
 func NewReactor(
 	logger log.Logger,
 	state sm.State,
@@ -109,6 +143,8 @@ func NewReactor(
 	blockSyncCh *p2p.Channel,
 	peerUpdates *p2p.PeerUpdates,
 	blockSync bool,
+	cfg config.BlockSyncConfig,
+	lightBlocks LightBlockProvider,
 	metrics *consensus.Metrics,
 ) (*Reactor, error) {
 	if state.LastBlockHeight != store.Height() {
@@ -130,20 +166,48 @@ func NewReactor(
 		pool:                 NewBlockPool(logger, startHeight, requestsCh, errorsCh),
 		consReactor:          consReactor,
 		blockSync:            tmsync.NewBool(blockSync),
+		headerFirst:          tmsync.NewBool(false),
 		requestsCh:           requestsCh,
 		errorsCh:             errorsCh,
 		blockSyncCh:          blockSyncCh,
 		blockSyncOutBridgeCh: make(chan p2p.Envelope),
 		peerUpdates:          peerUpdates,
 		closeCh:              make(chan struct{}),
+		ranges:               make(map[types.NodeID]int),
 		metrics:              metrics,
 		syncStartTime:        time.Time{},
 	}
 
 	r.BaseService = *service.NewBaseService(logger, "BlockSync", r)
+
+	if lightBlocks != nil {
+		r.headers = newHeaderVerifier(state, lightBlocks, cfg.TrustPeriod, cfg.MaxClockDrift)
+		r.startHeaderFirst()
+	}
+
 	return r, nil
 }
 
+// startHeaderFirst makes block sync header-first, unless the trusted state of
+// the header verifier is older than the trusting period. Headers can't be
+// verified from it then, so blocks are verified by their commits instead.
+func (r *Reactor) startHeaderFirst() {
+	if r.headers.Expired(time.Now()) {
+		r.Logger.Info("trusted state is older than the trust period, syncing blocks without verifying headers first")
+		r.stopHeaderFirst()
+		return
+	}
+	r.headerFirst.Set()
+	r.pool.SetMaxRequestHeight(r.headers.Height())
+}
+
+// stopHeaderFirst falls back to verifying each block by the commit in the
+// next block.
+func (r *Reactor) stopHeaderFirst() {
+	r.headerFirst.UnSet()
+	r.pool.SetMaxRequestHeight(math.MaxInt64)
+}
+
 // OnStart starts separate go routines for each p2p Channel and listens for
 // envelopes on each. In addition, it also listens for peer updates and handles
 // messages on that p2p channel accordingly. The caller must be sure to execute
@@ -161,6 +225,11 @@ func (r *Reactor) OnStart() error {
 
 		r.poolWG.Add(1)
 		go r.poolRoutine(false)
+
+		if r.headerFirst.IsSet() {
+			r.poolWG.Add(1)
+			go r.headerRoutine()
+		}
 	}
 
 	go r.processBlockSyncCh()
@@ -218,6 +287,88 @@ func (r *Reactor) respondToPeer(msg *bcproto.BlockRequest, peerID types.NodeID)
 	}
 }
 
+// serveRange serves the requested range of blocks to the peer off the message
+// loop. Ranges beyond the maximum a peer may have in flight are declined with a
+// NoBlockResponse for their first block.
+func (r *Reactor) serveRange(msg *bcproto.BlockRangeRequest, peerID types.NodeID) error {
+	if size := msg.EndHeight - msg.StartHeight + 1; size > maxBlockRange {
+		return fmt.Errorf("requested range of %d blocks exceeds the maximum of %d", size, maxBlockRange)
+	}
+
+	r.rangesMtx.Lock()
+	if r.ranges[peerID] >= maxPeerRanges {
+		r.rangesMtx.Unlock()
+		r.Logger.Debug("declining block range, too many in flight", "peer", peerID, "height", msg.StartHeight)
+		r.blockSyncCh.Out <- p2p.Envelope{
+			To:      peerID,
+			Message: &bcproto.NoBlockResponse{Height: msg.StartHeight},
+		}
+		return nil
+	}
+	r.ranges[peerID]++
+	r.rangesMtx.Unlock()
+
+	go func() {
+		defer func() {
+			r.rangesMtx.Lock()
+			defer r.rangesMtx.Unlock()
+
+			r.ranges[peerID]--
+			if r.ranges[peerID] == 0 {
+				delete(r.ranges, peerID)
+			}
+		}()
+
+		r.respondToRange(msg, peerID)
+	}()
+
+	return nil
+}
+
+// respondToRange sends the requested range of blocks to the peer in order. If
+// we don't have one of the blocks, or the range exceeds maxRangeBytes, we
+// respond saying we don't have it and stop there.
+func (r *Reactor) respondToRange(msg *bcproto.BlockRangeRequest, peerID types.NodeID) {
+	var rangeBytes int
+	for height := msg.StartHeight; height <= msg.EndHeight; height++ {
+		var block *types.Block
+		if meta := r.store.LoadBlockMeta(height); meta != nil {
+			rangeBytes += meta.BlockSize
+			// the first block is always sent, so that large blocks can be synced
+			if height == msg.StartHeight || rangeBytes <= maxRangeBytes {
+				block = r.store.LoadBlock(height)
+			}
+		}
+
+		if block == nil {
+			r.Logger.Debug("not sending the rest of the block range", "peer", peerID, "height", height)
+			r.sendRangeResponse(peerID, &bcproto.NoBlockResponse{Height: height})
+			return
+		}
+
+		blockProto, err := block.ToProto()
+		if err != nil {
+			r.Logger.Error("failed to convert msg to protobuf", "err", err)
+			return
+		}
+
+		if !r.sendRangeResponse(peerID, &bcproto.BlockResponse{Block: blockProto}) {
+			return
+		}
+	}
+}
+
+// sendRangeResponse sends a message of a block range through the bridge
+// channel. It returns false if the reactor is stopping.
+func (r *Reactor) sendRangeResponse(peerID types.NodeID, msg proto.Message) bool {
+	select {
+	case r.blockSyncOutBridgeCh <- p2p.Envelope{To: peerID, Message: msg}:
+		return true
+	case <-r.closeCh:
+		return false
+	}
+}
+
 // handleBlockSyncMessage handles envelopes sent from peers on the
 // BlockSyncChannel. It returns an error only if the Envelope.Message is unknown
 // for this channel. This should never be called outside of handleMessage.
@@ -228,6 +379,9 @@ func (r *Reactor) handleBlockSyncMessage(envelope p2p.Envelope) error {
 	case *bcproto.BlockRequest:
 		r.respondToPeer(msg, envelope.From)
 
+	case *bcproto.BlockRangeRequest:
+		return r.serveRange(msg, envelope.From)
+
 	case *bcproto.BlockResponse:
 		block, err := types.BlockFromProto(msg.Block)
 		if err != nil {
@@ -235,22 +389,34 @@ func (r *Reactor) handleBlockSyncMessage(envelope p2p.Envelope) error {
 			return err
 		}
 
+		// in header-first block sync, a block that doesn't match its verified
+		// header is rejected as soon as it is received
+		if r.headerFirst.IsSet() {
+			if err := r.headers.VerifyBlock(block); err != nil && !errors.Is(err, errNoHeader) {
+				logger.Error("block does not match its verified header", "height", block.Height, "err", err)
+				return err
+			}
+		}
+
 		r.pool.AddBlock(envelope.From, block, block.Size())
 
 	case *bcproto.StatusRequest:
 		r.blockSyncCh.Out <- p2p.Envelope{
 			To: envelope.From,
 			Message: &bcproto.StatusResponse{
-				Height: r.store.Height(),
-				Base:   r.store.Base(),
+				Height:        r.store.Height(),
+				Base:          r.store.Base(),
+				MaxBlockRange: maxBlockRange,
 			},
 		}
 
 	case *bcproto.StatusResponse:
 		r.pool.SetPeerRange(envelope.From, msg.Base, msg.Height)
+		r.pool.SetPeerMaxRange(envelope.From, msg.MaxBlockRange)
 
 	case *bcproto.NoBlockResponse:
 		logger.Debug("peer does not have the requested block", "height", msg.Height)
+		r.pool.NoBlock(envelope.From, msg.Height)
 
 	default:
 		return fmt.Errorf("received unknown message: %T", msg)
@@ -332,8 +498,9 @@ func (r *Reactor) processPeerUpdate(peerUpdate p2p.PeerUpdate) {
 		r.blockSyncOutBridgeCh <- p2p.Envelope{
 			To: peerUpdate.NodeID,
 			Message: &bcproto.StatusResponse{
-				Base:   r.store.Base(),
-				Height: r.store.Height(),
+				Base:          r.store.Base(),
+				Height:        r.store.Height(),
+				MaxBlockRange: maxBlockRange,
 			},
 		}
 
@@ -367,6 +534,11 @@ func (r *Reactor) SwitchToBlockSync(state sm.State) error {
 	r.initialState = state
 	r.pool.height = state.LastBlockHeight + 1
 
+	if r.headers != nil {
+		r.headers.reset(state)
+		r.startHeaderFirst()
+	}
+
 	if err := r.pool.Start(); err != nil {
 		return err
 	}
@@ -379,9 +551,68 @@ func (r *Reactor) SwitchToBlockSync(state sm.State) error {
 	r.poolWG.Add(1)
 	go r.poolRoutine(true)
 
+	if r.headerFirst.IsSet() {
+		r.poolWG.Add(1)
+		go r.headerRoutine()
+	}
+
 	return nil
 }
 
+// headerRoutine verifies the headers of the blocks to sync ahead of the pool
+// in header-first block sync, and lets the pool request the blocks of the
+// verified headers.
+func (r *Reactor) headerRoutine() {
+	defer r.poolWG.Done()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go func() {
+		select {
+		case <-r.closeCh:
+		case <-r.pool.Quit():
+		}
+		cancel()
+	}()
+
+	ticker := time.NewTicker(headerSyncIntervalMS * time.Millisecond)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+
+		case <-ticker.C:
+			// NOTE: a peer at height H can only serve the light block at H-1,
+			// as the commit for H is in the block at H+1.
+			height, _, _ := r.pool.GetStatus()
+			last := r.pool.MaxPeerHeight() - 1
+			target := last
+			if target > height+headerWindow {
+				target = height + headerWindow
+			}
+			if target <= r.headers.Height() ||
+				(target < r.headers.Height()+headerPivotInterval && target < last) {
+				continue
+			}
+
+			err := r.headers.VerifyTo(ctx, target)
+			var errExpired light.ErrOldHeaderExpired
+			switch {
+			case errors.As(err, &errExpired):
+				r.Logger.Info("headers are older than the trust period, syncing blocks without verifying headers first",
+					"target", target, "err", err)
+				r.stopHeaderFirst()
+				return
+			case err != nil && ctx.Err() == nil:
+				r.Logger.Error("failed to verify headers", "target", target, "err", err)
+			}
+			r.pool.SetMaxRequestHeight(r.headers.Height())
+		}
+	}
+}
+
 func (r *Reactor) requestRoutine() {
 	statusUpdateTicker := time.NewTicker(statusUpdateIntervalSeconds * time.Second)
 	defer statusUpdateTicker.Stop()
@@ -397,6 +628,17 @@ func (r *Reactor) requestRoutine() {
 			return
 
 		case request := <-r.requestsCh:
+			if request.EndHeight > request.Height {
+				r.blockSyncOutBridgeCh <- p2p.Envelope{
+					To: request.PeerID,
+					Message: &bcproto.BlockRangeRequest{
+						StartHeight: request.Height,
+						EndHeight:   request.EndHeight,
+					},
+				}
+				continue
+			}
+
 			r.blockSyncOutBridgeCh <- p2p.Envelope{
 				To:      request.PeerID,
 				Message: &bcproto.BlockRequest{Height: request.Height},
@@ -509,10 +751,18 @@ FOR_LOOP:
 			//
 			// TODO: Uncouple from request routine.
 
+			// in header-first block sync, verify the received blocks by their
+			// hash links first
+			headerFirst := r.headerFirst.IsSet()
+			if headerFirst {
+				r.linkBlocks()
+			}
+
 			// see if there are any blocks to sync
 			first, second := r.pool.PeekTwoBlocks()
-			if first == nil || second == nil {
-				// we need both to sync the first block
+			commit := r.commitFor(headerFirst, first, second)
+			if commit == nil {
+				// we need the commit to sync the first block
 				continue FOR_LOOP
 			} else {
 				// try again quickly next loop
@@ -525,17 +775,28 @@ FOR_LOOP:
 				firstID            = types.BlockID{Hash: first.Hash(), PartSetHeader: firstPartSetHeader}
 			)
 
-			// Finally, verify the first block using the second's commit.
+			// Finally, verify the first block using the second's commit, or
+			// against its verified header in header-first block sync.
 			//
 			// NOTE: We can probably make this more efficient, but note that calling
 			// first.Hash() doesn't verify the tx contents, so MakePartSet() is
 			// currently necessary.
-			err := state.Validators.VerifyCommitLight(chainID, firstID, first.Height, second.LastCommit)
-			if err != nil {
+			var err error
+			if headerFirst {
+				err = r.headers.VerifyBlock(first)
+				if err == nil && !commit.BlockID.Equals(firstID) {
+					err = fmt.Errorf("commit is for block %v, not %v", commit.BlockID, firstID)
+				}
+				if err != nil {
+					err = fmt.Errorf("invalid block: %w", err)
+				}
+			} else if err = state.Validators.VerifyCommitLight(chainID, firstID, first.Height, commit); err != nil {
 				err = fmt.Errorf("invalid last commit: %w", err)
+			}
+			if err != nil {
 				r.Logger.Error(
 					err.Error(),
-					"last_commit", second.LastCommit,
+					"last_commit", commit,
 					"block_id", firstID,
 					"height", first.Height,
 				)
@@ -548,11 +809,15 @@ FOR_LOOP:
 					Err:    err,
 				}
 
-				peerID2 := r.pool.RedoRequest(second.Height)
-				if peerID2 != peerID {
-					r.blockSyncCh.Error <- p2p.PeerError{
-						NodeID: peerID2,
-						Err:    err,
+				// the first block alone is at fault in header-first block sync,
+				// as the commit has been verified
+				if !headerFirst {
+					peerID2 := r.pool.RedoRequest(second.Height)
+					if peerID2 != peerID {
+						r.blockSyncCh.Error <- p2p.PeerError{
+							NodeID: peerID2,
+							Err:    err,
+						}
 					}
 				}
 
@@ -561,7 +826,7 @@ FOR_LOOP:
 				r.pool.PopRequest()
 
 				// TODO: batch saves so we do not persist to disk every block
-				r.store.SaveBlock(first, firstParts, second.LastCommit)
+				r.store.SaveBlock(first, firstParts, commit)
 
 				var err error
 
@@ -573,6 +838,10 @@ FOR_LOOP:
 					panic(fmt.Sprintf("failed to process committed block (%d:%X): %v", first.Height, first.Hash(), err))
 				}
 
+				if headerFirst {
+					r.headers.Prune(first.Height)
+				}
+
 				r.metrics.RecordConsMetrics(first)
 
 				blocksSynced++
@@ -600,6 +869,38 @@ FOR_LOOP:
 	}
 }
 
+// commitFor returns the commit to sync the first block with, or nil if it
+// isn't available yet. This is the last commit of the second block, which in
+// header-first block sync must match its verified hash, unless the first
+// block's header was verified with its own commit.
+func (r *Reactor) commitFor(headerFirst bool, first, second *types.Block) *types.Commit {
+	if first == nil {
+		return nil
+	}
+	if headerFirst {
+		return r.headers.Commit(first.Height, second)
+	}
+	if second == nil {
+		return nil
+	}
+	return second.LastCommit
+}
+
+// linkBlocks verifies the received blocks by their hash links back from the
+// verified headers, and requests the blocks that don't match again.
+func (r *Reactor) linkBlocks() {
+	for _, height := range r.headers.Link(r.pool.PeekBlock) {
+		err := fmt.Errorf("block at height %d does not match its verified hash", height)
+		r.Logger.Error(err.Error())
+
+		peerID := r.pool.RedoRequest(height)
+		r.blockSyncCh.Error <- p2p.PeerError{
+			NodeID: peerID,
+			Err:    err,
+		}
+	}
+}
+
 func (r *Reactor) GetMaxPeerBlockHeight() int64 {
 	return r.pool.MaxPeerHeight()
 }
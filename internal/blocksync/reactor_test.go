@@ -1,10 +1,13 @@
 package blocksync
 
 import (
+	"context"
+	"errors"
 	"os"
 	"testing"
 	"time"
 
+	"github.com/gogo/protobuf/proto"
 	"github.com/stretchr/testify/require"
 	dbm "github.com/tendermint/tm-db"
 
@@ -37,7 +40,32 @@ type reactorTestSuite struct {
 	peerChans         map[types.NodeID]chan p2p.PeerUpdate
 	peerUpdates       map[types.NodeID]*p2p.PeerUpdates
 
-	blockSync bool
+	blockSync   bool
+	headerFirst bool
+}
+
+// storeLightBlocks serves the light blocks of a node from its stores, as the
+// state sync reactor of a peer would.
+type storeLightBlocks struct {
+	reactor *Reactor
+}
+
+func (p storeLightBlocks) LightBlock(ctx context.Context, height int64) (*types.LightBlock, error) {
+	meta := p.reactor.store.LoadBlockMeta(height)
+	commit := p.reactor.store.LoadBlockCommit(height)
+	if meta == nil || commit == nil {
+		return nil, errors.New("light block not found")
+	}
+
+	vals, err := p.reactor.blockExec.Store().LoadValidators(height)
+	if err != nil {
+		return nil, err
+	}
+
+	return &types.LightBlock{
+		SignedHeader: &types.SignedHeader{Header: &meta.Header, Commit: commit},
+		ValidatorSet: vals,
+	}, nil
 }
 
 func setup(
@@ -46,6 +74,7 @@ func setup(
 	privVal types.PrivValidator,
 	maxBlockHeights []int64,
 	chBuf uint,
+	headerFirst bool,
 ) *reactorTestSuite {
 	t.Helper()
 
@@ -63,6 +92,7 @@ func setup(
 		peerChans:         make(map[types.NodeID]chan p2p.PeerUpdate, numNodes),
 		peerUpdates:       make(map[types.NodeID]*p2p.PeerUpdates, numNodes),
 		blockSync:         true,
+		headerFirst:       headerFirst,
 	}
 
 	chDesc := &p2p.ChannelDescriptor{ID: BlockSyncChannel, MessageType: new(bcproto.Message)}
@@ -155,6 +185,12 @@ func (rts *reactorTestSuite) addNode(t *testing.T,
 		blockStore.SaveBlock(thisBlock, thisParts, lastCommit)
 	}
 
+	// nodes sync the headers from the first node in header-first block sync
+	var lightBlocks LightBlockProvider
+	if rts.headerFirst && len(rts.nodes) > 1 {
+		lightBlocks = storeLightBlocks{reactor: rts.reactors[rts.nodes[0]]}
+	}
+
 	rts.peerChans[nodeID] = make(chan p2p.PeerUpdate)
 	rts.peerUpdates[nodeID] = p2p.NewPeerUpdates(rts.peerChans[nodeID], 1)
 	rts.network.Nodes[nodeID].PeerManager.Register(rts.peerUpdates[nodeID])
@@ -167,6 +203,8 @@ func (rts *reactorTestSuite) addNode(t *testing.T,
 		rts.blockSyncChannels[nodeID],
 		rts.peerUpdates[nodeID],
 		rts.blockSync,
+		*config.TestBlockSyncConfig(),
+		lightBlocks,
 		consensus.NopMetrics())
 	require.NoError(t, err)
 
@@ -191,7 +229,7 @@ func TestReactor_AbruptDisconnect(t *testing.T) {
 	genDoc, privVals := factory.RandGenesisDoc(cfg, 1, false, 30)
 	maxBlockHeight := int64(64)
 
-	rts := setup(t, genDoc, privVals[0], []int64{maxBlockHeight, 0}, 0)
+	rts := setup(t, genDoc, privVals[0], []int64{maxBlockHeight, 0}, 0, false)
 
 	require.Equal(t, maxBlockHeight, rts.reactors[rts.nodes[0]].store.Height())
 
@@ -227,7 +265,7 @@ func TestReactor_SyncTime(t *testing.T) {
 	genDoc, privVals := factory.RandGenesisDoc(cfg, 1, false, 30)
 	maxBlockHeight := int64(101)
 
-	rts := setup(t, genDoc, privVals[0], []int64{maxBlockHeight, 0}, 0)
+	rts := setup(t, genDoc, privVals[0], []int64{maxBlockHeight, 0}, 0, false)
 	require.Equal(t, maxBlockHeight, rts.reactors[rts.nodes[0]].store.Height())
 	rts.start(t)
 
@@ -252,7 +290,7 @@ func TestReactor_NoBlockResponse(t *testing.T) {
 	genDoc, privVals := factory.RandGenesisDoc(cfg, 1, false, 30)
 	maxBlockHeight := int64(65)
 
-	rts := setup(t, genDoc, privVals[0], []int64{maxBlockHeight, 0}, 0)
+	rts := setup(t, genDoc, privVals[0], []int64{maxBlockHeight, 0}, 0, false)
 
 	require.Equal(t, maxBlockHeight, rts.reactors[rts.nodes[0]].store.Height())
 
@@ -287,6 +325,111 @@ func TestReactor_NoBlockResponse(t *testing.T) {
 	}
 }
 
+func TestReactor_ServeRange(t *testing.T) {
+	cfg, err := config.ResetTestRoot("block_sync_reactor_test")
+	require.NoError(t, err)
+	defer os.RemoveAll(cfg.RootDir)
+
+	genDoc, privVals := factory.RandGenesisDoc(cfg, 1, false, 30)
+	rts := setup(t, genDoc, privVals[0], []int64{10}, 0, false)
+	src := rts.reactors[rts.nodes[0]]
+
+	// serve from a reactor that is not started, so that its responses can be
+	// read directly
+	outCh := make(chan p2p.Envelope, 10)
+	blockSyncCh := p2p.NewChannel(BlockSyncChannel, new(bcproto.Message),
+		make(chan p2p.Envelope), outCh, make(chan p2p.PeerError))
+	r, err := NewReactor(rts.logger, src.initialState, src.blockExec, src.store, nil, blockSyncCh,
+		p2p.NewPeerUpdates(make(chan p2p.PeerUpdate), 1), false, *config.TestBlockSyncConfig(), nil,
+		consensus.NopMetrics())
+	require.NoError(t, err)
+	t.Cleanup(func() { close(r.closeCh) })
+
+	peerID := types.NodeID("aa")
+	request := func(start, end int64) error {
+		return r.handleBlockSyncMessage(p2p.Envelope{
+			From:    peerID,
+			Message: &bcproto.BlockRangeRequest{StartHeight: start, EndHeight: end},
+		})
+	}
+	receive := func() proto.Message {
+		select {
+		case envelope := <-r.blockSyncOutBridgeCh:
+			require.Equal(t, peerID, envelope.To)
+			return envelope.Message
+		case <-time.After(time.Second):
+			require.FailNow(t, "timed out waiting for a range response")
+			return nil
+		}
+	}
+
+	// ranges are served off the message loop, two at a time per peer
+	require.NoError(t, request(1, 3))
+	require.NoError(t, request(4, 6))
+	require.NoError(t, request(7, 9))
+	select {
+	case envelope := <-outCh:
+		require.Equal(t, &bcproto.NoBlockResponse{Height: 7}, envelope.Message)
+	case <-time.After(time.Second):
+		require.FailNow(t, "third range was not declined")
+	}
+
+	heights := make(map[int64]bool)
+	for i := 0; i < 6; i++ {
+		msg, ok := receive().(*bcproto.BlockResponse)
+		require.True(t, ok)
+		heights[msg.Block.Header.Height] = true
+	}
+	require.Len(t, heights, 6)
+
+	// once a range is done, the peer may request another one; the range stops
+	// at the first block we don't have
+	require.Eventually(t, func() bool {
+		r.rangesMtx.Lock()
+		defer r.rangesMtx.Unlock()
+		return r.ranges[peerID] == 0
+	}, time.Second, 10*time.Millisecond)
+	require.NoError(t, request(9, 12))
+	require.IsType(t, &bcproto.BlockResponse{}, receive())
+	require.IsType(t, &bcproto.BlockResponse{}, receive())
+	require.Equal(t, &bcproto.NoBlockResponse{Height: 11}, receive())
+
+	require.Error(t, request(1, maxBlockRange+1))
+}
+
+func TestReactor_HeaderFirstSync(t *testing.T) {
+	cfg, err := config.ResetTestRoot("block_sync_reactor_test")
+	require.NoError(t, err)
+	defer os.RemoveAll(cfg.RootDir)
+
+	genDoc, privVals := factory.RandGenesisDoc(cfg, 1, false, 30)
+	maxBlockHeight := int64(65)
+
+	rts := setup(t, genDoc, privVals[0], []int64{maxBlockHeight, 0}, 0, true)
+	require.Equal(t, maxBlockHeight, rts.reactors[rts.nodes[0]].store.Height())
+	rts.start(t)
+
+	secondary := rts.reactors[rts.nodes[1]]
+	require.Eventually(
+		t,
+		func() bool { return secondary.pool.MaxPeerHeight() > 0 && secondary.pool.IsCaughtUp() },
+		10*time.Second,
+		10*time.Millisecond,
+		"expected node to be fully synced",
+	)
+
+	// the headers were verified ahead of the blocks, up to the last block
+	// that can be synced
+	require.EqualValues(t, maxBlockHeight-1, secondary.headers.Height())
+	for height := int64(1); height < maxBlockHeight; height++ {
+		expected := rts.reactors[rts.nodes[0]].store.LoadBlockMeta(height)
+		actual := secondary.store.LoadBlockMeta(height)
+		require.NotNil(t, actual, "height %d", height)
+		require.Equal(t, expected.BlockID, actual.BlockID)
+	}
+	require.Equal(t, maxBlockHeight-1, secondary.store.LoadSeenCommit().Height)
+}
+
 func TestReactor_BadBlockStopsPeer(t *testing.T) {
 	// Ultimately, this should be refactored to be less integration test oriented
 	// and more unit test oriented by simply testing channel sends and receives.
@@ -300,7 +443,7 @@ func TestReactor_BadBlockStopsPeer(t *testing.T) {
 	maxBlockHeight := int64(48)
 	genDoc, privVals := factory.RandGenesisDoc(cfg, 1, false, 30)
 
-	rts := setup(t, genDoc, privVals[0], []int64{maxBlockHeight, 0, 0, 0, 0}, 1000)
+	rts := setup(t, genDoc, privVals[0], []int64{maxBlockHeight, 0, 0, 0, 0}, 1000, false)
 
 	require.Equal(t, maxBlockHeight, rts.reactors[rts.nodes[0]].store.Height())
 
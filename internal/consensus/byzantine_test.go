@@ -70,12 +70,13 @@ func TestByzantinePrevoteEquivocation(t *testing.T) {
 			proxyAppConnCon := abciclient.NewLocalClient(mtx, app)
 
 			// Make Mempool
-			mempool := mempool.NewTxMempool(
+			mempool, err := mempool.NewTxMempool(
 				log.TestingLogger().With("module", "mempool"),
 				thisConfig.Mempool,
 				proxyAppConnMem,
 				0,
 			)
+			require.NoError(t, err)
 			if thisConfig.Consensus.WaitForTxs() {
 				mempool.EnableTxsAvailable()
 			}
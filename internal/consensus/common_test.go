@@ -424,12 +424,15 @@ func newStateWithConfigAndBlockStore(
 
 	// Make Mempool
 
-	mempool := mempool.NewTxMempool(
+	mempool, err := mempool.NewTxMempool(
 		logger.With("module", "mempool"),
 		thisConfig.Mempool,
 		proxyAppConnMem,
 		0,
 	)
+	if err != nil {
+		panic(err)
+	}
 
 	if thisConfig.Consensus.WaitForTxs() {
 		mempool.EnableTxsAvailable()
@@ -449,7 +452,7 @@ func newStateWithConfigAndBlockStore(
 	cs.SetPrivValidator(pv)
 
 	eventBus := eventbus.NewDefault(logger.With("module", "events"))
-	err := eventBus.Start()
+	err = eventBus.Start()
 	if err != nil {
 		panic(err)
 	}
@@ -35,6 +35,8 @@ func GetChannelDescriptors() []*p2p.ChannelDescriptor {
 			SendQueueCapacity:   64,
 			RecvMessageCapacity: maxMsgSize,
 			RecvBufferCapacity:  128,
+			MinBandwidthShare:   0.1,
+			Lossless:            true,
 		},
 		{
 			// TODO: Consider a split between gossiping current block and catchup
@@ -46,6 +48,8 @@ func GetChannelDescriptors() []*p2p.ChannelDescriptor {
 			SendQueueCapacity:   64,
 			RecvBufferCapacity:  512,
 			RecvMessageCapacity: maxMsgSize,
+			MinBandwidthShare:   0.25,
+			Lossless:            true,
 		},
 		{
 			ID:                  VoteChannel,
@@ -54,6 +58,8 @@ func GetChannelDescriptors() []*p2p.ChannelDescriptor {
 			SendQueueCapacity:   64,
 			RecvBufferCapacity:  128,
 			RecvMessageCapacity: maxMsgSize,
+			MinBandwidthShare:   0.15,
+			Lossless:            true,
 		},
 		{
 			ID:                  VoteSetBitsChannel,
@@ -62,6 +68,7 @@ func GetChannelDescriptors() []*p2p.ChannelDescriptor {
 			SendQueueCapacity:   8,
 			RecvBufferCapacity:  128,
 			RecvMessageCapacity: maxMsgSize,
+			Lossless:            true,
 		},
 	}
 }
@@ -386,12 +386,13 @@ func TestReactorWithEvidence(t *testing.T) {
 		proxyAppConnMem := abciclient.NewLocalClient(mtx, app)
 		proxyAppConnCon := abciclient.NewLocalClient(mtx, app)
 
-		mempool := mempool.NewTxMempool(
+		mempool, err := mempool.NewTxMempool(
 			log.TestingLogger().With("module", "mempool"),
 			thisConfig.Mempool,
 			proxyAppConnMem,
 			0,
 		)
+		require.NoError(t, err)
 
 		if thisConfig.Consensus.WaitForTxs() {
 			mempool.EnableTxsAvailable()
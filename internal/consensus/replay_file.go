@@ -317,7 +317,10 @@ func newConsensusStateForReplay(
 	if err != nil {
 		return nil, err
 	}
-	blockStore := store.NewBlockStore(blockStoreDB)
+	blockStore, err := store.OpenBlockStore(cfg, blockStoreDB)
+	if err != nil {
+		return nil, err
+	}
 
 	// Get State
 	stateDB, err := dbm.NewDB("state", dbType, cfg.DBDir())
@@ -1233,6 +1233,13 @@ func (bs *mockBlockStore) PruneBlocks(height int64) (uint64, error) {
 	return pruned, nil
 }
 
+func (bs *mockBlockStore) DeleteBlocksAfter(height int64) (uint64, error) {
+	deleted := uint64(len(bs.chain)) - uint64(height)
+	bs.chain = bs.chain[:height]
+	bs.commits = bs.commits[:height]
+	return deleted, nil
+}
+
 //---------------------------------------
 // Test handshake/init chain
 
@@ -119,8 +119,15 @@ func (b *EventBus) PublishEventNewBlockHeader(data types.EventDataNewBlockHeader
 	return b.pubsub.PublishWithEvents(ctx, data, events)
 }
 
+// PublishEventNewEvidence publishes a new evidence event with the reserved
+// evidence events (see types.EvidenceEvents), so that subscribers can filter
+// evidence in the same way as with evidence_search.
 func (b *EventBus) PublishEventNewEvidence(evidence types.EventDataNewEvidence) error {
-	return b.Publish(types.EventNewEvidenceValue, evidence)
+	// no explicit deadline for publishing events
+	ctx := context.Background()
+	events := append(types.EvidenceEvents(evidence.Evidence), types.EventNewEvidence)
+
+	return b.pubsub.PublishWithEvents(ctx, evidence, events)
 }
 
 func (b *EventBus) PublishEventVote(data types.EventDataVote) error {
@@ -175,6 +182,39 @@ func (b *EventBus) PublishEventTx(data types.EventDataTx) error {
 	return b.pubsub.PublishWithEvents(ctx, data, events)
 }
 
+// PublishEventMempoolTx publishes a mempool tx event with the predefined keys
+// EventTypeKey, TxHashKey and MempoolTxStatusKey, so that subscribers can
+// follow a transaction by its hash.
+func (b *EventBus) PublishEventMempoolTx(data types.EventDataMempoolTx) error {
+	// no explicit deadline for publishing events
+	ctx := context.Background()
+	events := []abci.Event{types.EventMempoolTx}
+
+	tokens := strings.Split(types.TxHashKey, ".")
+	events = append(events, abci.Event{
+		Type: tokens[0],
+		Attributes: []abci.EventAttribute{
+			{
+				Key:   tokens[1],
+				Value: fmt.Sprintf("%X", data.Tx.Hash()),
+			},
+		},
+	})
+
+	tokens = strings.Split(types.MempoolTxStatusKey, ".")
+	events = append(events, abci.Event{
+		Type: tokens[0],
+		Attributes: []abci.EventAttribute{
+			{
+				Key:   tokens[1],
+				Value: data.Status,
+			},
+		},
+	})
+
+	return b.pubsub.PublishWithEvents(ctx, data, events)
+}
+
 func (b *EventBus) PublishEventNewRoundStep(data types.EventDataRoundState) error {
 	return b.Publish(types.EventNewRoundStepValue, data)
 }
@@ -236,6 +276,10 @@ func (NopEventBus) PublishEventTx(types.EventDataTx) error {
 	return nil
 }
 
+func (NopEventBus) PublishEventMempoolTx(types.EventDataMempoolTx) error {
+	return nil
+}
+
 func (NopEventBus) PublishEventValidatorSetUpdates(types.EventDataValidatorSetUpdates) error {
 	return nil
 }
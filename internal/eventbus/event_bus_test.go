@@ -343,6 +343,61 @@ func TestEventBusPublishEventNewEvidence(t *testing.T) {
 	}
 }
 
+func TestEventBusPublishEventMempoolTx(t *testing.T) {
+	eventBus := eventbus.NewDefault(log.TestingLogger())
+	err := eventBus.Start()
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		if err := eventBus.Stop(); err != nil {
+			t.Error(err)
+		}
+	})
+
+	tx := types.Tx("foo")
+
+	// PublishEventMempoolTx adds 3 composite keys, so the query below should work
+	ctx := context.Background()
+	query := fmt.Sprintf("tm.event='MempoolTx' AND tx.hash='%X' AND mempool_tx.status='expired'", tx.Hash())
+	txsSub, err := eventBus.SubscribeWithArgs(ctx, tmpubsub.SubscribeArgs{
+		ClientID: "test",
+		Query:    tmquery.MustParse(query),
+	})
+	require.NoError(t, err)
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		msg, err := txsSub.Next(ctx)
+		assert.NoError(t, err)
+
+		edt := msg.Data().(types.EventDataMempoolTx)
+		assert.EqualValues(t, tx, edt.Tx)
+		assert.Equal(t, types.MempoolTxExpired, edt.Status)
+		assert.Equal(t, "exceeded ttl-num-blocks", edt.Reason)
+	}()
+
+	// Events of other statuses don't match the query.
+	err = eventBus.PublishEventMempoolTx(types.EventDataMempoolTx{
+		Tx:     tx,
+		Status: types.MempoolTxAdded,
+		Height: 1,
+	})
+	assert.NoError(t, err)
+	err = eventBus.PublishEventMempoolTx(types.EventDataMempoolTx{
+		Tx:     tx,
+		Status: types.MempoolTxExpired,
+		Reason: "exceeded ttl-num-blocks",
+		Height: 5,
+	})
+	assert.NoError(t, err)
+
+	select {
+	case <-done:
+	case <-time.After(1 * time.Second):
+		t.Fatal("did not receive a mempool tx after 1 sec.")
+	}
+}
+
 func TestEventBusPublish(t *testing.T) {
 	eventBus := eventbus.NewDefault(log.TestingLogger())
 	err := eventBus.Start()
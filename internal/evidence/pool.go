@@ -22,8 +22,10 @@ import (
 
 const (
 	// prefixes are unique across all tm db's
-	prefixCommitted = int64(9)
-	prefixPending   = int64(10)
+	prefixCommitted         = int64(9)
+	prefixPending           = int64(10)
+	prefixHash              = int64(11)
+	prefixCommittedEvidence = int64(12)
 )
 
 // Pool maintains a pool of valid evidence to be broadcasted and committed
@@ -79,6 +81,11 @@ func NewPool(logger log.Logger, evidenceDB dbm.DB, stateDB sm.Store, blockStore
 
 	atomic.StoreUint32(&pool.evidenceSize, uint32(len(evList)))
 
+	// evidence stored before the hash index was introduced is indexed now
+	if err := pool.indexHashes(); err != nil {
+		return nil, err
+	}
+
 	for _, ev := range evList {
 		pool.evidenceList.PushBack(ev)
 	}
@@ -261,6 +268,197 @@ func (evpool *Pool) State() sm.State {
 	return evpool.state
 }
 
+// CommittedEvidence is evidence that has been committed in a block.
+type CommittedEvidence struct {
+	Evidence types.Evidence
+	// Height is the height of the block the evidence was committed in.
+	Height int64
+}
+
+// ListPendingEvidence returns all the pending evidence, from oldest to newest.
+func (evpool *Pool) ListPendingEvidence() ([]types.Evidence, error) {
+	evidence, _, err := evpool.listEvidence(prefixPending, -1)
+	return evidence, err
+}
+
+// ListCommittedEvidence returns the evidence committed in blocks, ordered by the
+// height of the misbehavior. If height is positive, only the evidence committed
+// in the block at that height is returned. Evidence committed in blocks that
+// have been pruned is left out.
+func (evpool *Pool) ListCommittedEvidence(height int64) ([]CommittedEvidence, error) {
+	if height > 0 {
+		block := evpool.blockStore.LoadBlock(height)
+		if block == nil {
+			return nil, nil
+		}
+
+		committed := make([]CommittedEvidence, 0, len(block.Evidence.Evidence))
+		for _, ev := range block.Evidence.Evidence {
+			committed = append(committed, CommittedEvidence{Evidence: ev, Height: height})
+		}
+		return committed, nil
+	}
+
+	iter, err := dbm.IteratePrefix(evpool.evidenceStore, prefixToBytes(prefixCommitted))
+	if err != nil {
+		return nil, fmt.Errorf("database error: %v", err)
+	}
+	defer iter.Close()
+
+	var (
+		committed []CommittedEvidence
+		blocks    = make(map[int64]*types.Block)
+	)
+	for ; iter.Valid(); iter.Next() {
+		ev, height, err := evpool.loadCommittedEvidence(iter.Key(), iter.Value(), blocks)
+		if err != nil {
+			return nil, err
+		}
+		if ev != nil {
+			committed = append(committed, CommittedEvidence{Evidence: ev, Height: height})
+		}
+	}
+
+	return committed, iter.Error()
+}
+
+// GetEvidence returns the pending or committed evidence with the given hash,
+// along with the height of the block it was committed in, which is 0 for
+// pending evidence. It returns nil if the evidence is unknown.
+func (evpool *Pool) GetEvidence(hash []byte) (types.Evidence, int64, error) {
+	heightBytes, err := evpool.evidenceStore.Get(keyHash(hash))
+	if err != nil {
+		return nil, 0, fmt.Errorf("database error: %v", err)
+	}
+	if heightBytes == nil {
+		return nil, 0, nil
+	}
+
+	var h gogotypes.Int64Value
+	if err := proto.Unmarshal(heightBytes, &h); err != nil {
+		return nil, 0, fmt.Errorf("failed to unmarshal evidence height: %w", err)
+	}
+
+	key := keyEvidence(prefixPending, h.Value, hash)
+	evBytes, err := evpool.evidenceStore.Get(key)
+	if err != nil {
+		return nil, 0, fmt.Errorf("database error: %v", err)
+	}
+	if evBytes != nil {
+		ev, err := bytesToEv(evBytes)
+		return ev, 0, err
+	}
+
+	key = keyEvidence(prefixCommitted, h.Value, hash)
+	value, err := evpool.evidenceStore.Get(key)
+	if err != nil {
+		return nil, 0, fmt.Errorf("database error: %v", err)
+	}
+	if value == nil {
+		return nil, 0, nil
+	}
+	return evpool.loadCommittedEvidence(key, value, nil)
+}
+
+// indexHashes indexes the hashes of the pending and committed evidence that
+// are missing from the hash index.
+func (evpool *Pool) indexHashes() error {
+	for _, prefix := range []int64{prefixPending, prefixCommitted} {
+		if err := evpool.indexPrefixHashes(prefix); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (evpool *Pool) indexPrefixHashes(prefix int64) error {
+	iter, err := dbm.IteratePrefix(evpool.evidenceStore, prefixToBytes(prefix))
+	if err != nil {
+		return fmt.Errorf("database error: %v", err)
+	}
+	defer iter.Close()
+
+	batch := evpool.evidenceStore.NewBatch()
+	defer batch.Close()
+
+	for ; iter.Valid(); iter.Next() {
+		height, hash, err := parseEvidenceKey(iter.Key())
+		if err != nil {
+			return err
+		}
+		ok, err := evpool.evidenceStore.Has(keyHash(hash))
+		if err != nil {
+			return fmt.Errorf("database error: %v", err)
+		}
+		if ok {
+			continue
+		}
+
+		heightBytes, err := proto.Marshal(&gogotypes.Int64Value{Value: height})
+		if err != nil {
+			return fmt.Errorf("failed to marshal evidence height: %w", err)
+		}
+		if err := batch.Set(keyHash(hash), heightBytes); err != nil {
+			return err
+		}
+	}
+	if err := iter.Error(); err != nil {
+		return err
+	}
+
+	return batch.WriteSync()
+}
+
+// loadCommittedEvidence loads committed evidence given its key and value in
+// the evidence store. Evidence committed before it was stored alongside its
+// key is loaded from the block it was committed in instead, and loaded blocks
+// are cached in blocks, if not nil. It returns nil if that block has been
+// pruned.
+func (evpool *Pool) loadCommittedEvidence(
+	key, value []byte,
+	blocks map[int64]*types.Block,
+) (types.Evidence, int64, error) {
+	evHeight, hash, err := parseEvidenceKey(key)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	var h gogotypes.Int64Value
+	if err := proto.Unmarshal(value, &h); err != nil {
+		return nil, 0, fmt.Errorf("failed to unmarshal committed evidence height: %w", err)
+	}
+
+	evBytes, err := evpool.evidenceStore.Get(keyEvidence(prefixCommittedEvidence, evHeight, hash))
+	if err != nil {
+		return nil, 0, fmt.Errorf("database error: %v", err)
+	}
+	if evBytes != nil {
+		ev, err := bytesToEv(evBytes)
+		if err != nil {
+			return nil, 0, err
+		}
+		return ev, h.Value, nil
+	}
+
+	block, ok := blocks[h.Value]
+	if !ok {
+		block = evpool.blockStore.LoadBlock(h.Value)
+		if blocks != nil {
+			blocks[h.Value] = block
+		}
+	}
+	if block == nil {
+		return nil, 0, nil
+	}
+
+	for _, ev := range block.Evidence.Evidence {
+		if bytes.Equal(ev.Hash(), hash) {
+			return ev, h.Value, nil
+		}
+	}
+	return nil, 0, fmt.Errorf("evidence %X not found in block at height %d", hash, h.Value)
+}
+
 // IsExpired checks whether evidence or a polc is expired by checking whether a height and time is older
 // than set by the evidence consensus parameters
 func (evpool *Pool) isExpired(height int64, time time.Time) bool {
@@ -304,10 +502,21 @@ func (evpool *Pool) addPendingEvidence(ev types.Evidence) error {
 		return fmt.Errorf("failed to marshal evidence: %w", err)
 	}
 
-	key := keyPending(ev)
-
-	err = evpool.evidenceStore.Set(key, evBytes)
+	heightBytes, err := proto.Marshal(&gogotypes.Int64Value{Value: ev.Height()})
 	if err != nil {
+		return fmt.Errorf("failed to marshal evidence height: %w", err)
+	}
+
+	batch := evpool.evidenceStore.NewBatch()
+	defer batch.Close()
+
+	if err := batch.Set(keyPending(ev), evBytes); err != nil {
+		return fmt.Errorf("failed to persist evidence: %w", err)
+	}
+	if err := batch.Set(keyHash(ev.Hash()), heightBytes); err != nil {
+		return fmt.Errorf("failed to index evidence: %w", err)
+	}
+	if err := batch.WriteSync(); err != nil {
 		return fmt.Errorf("failed to persist evidence: %w", err)
 	}
 
@@ -330,18 +539,22 @@ func (evpool *Pool) markEvidenceAsCommitted(evidence types.EvidenceList, height
 			blockEvidenceMap[evMapKey(ev)] = struct{}{}
 		}
 
-		// Add evidence to the committed list. As the evidence is stored in the block store
-		// we only need to record the height that it was saved at.
+		// Add evidence to the committed list, recording the height that it was
+		// saved at. The evidence itself is stored alongside, so that it can be
+		// queried without loading the block, and indexed by its hash.
 		key := keyCommitted(ev)
 
 		h := gogotypes.Int64Value{Value: height}
-		evBytes, err := proto.Marshal(&h)
+		heightBytes, err := proto.Marshal(&h)
 		if err != nil {
 			evpool.logger.Error("failed to marshal committed evidence", "key(height/hash)", key, "err", err)
 			continue
 		}
+		if err := evpool.evidenceStore.Set(key, heightBytes); err != nil {
+			evpool.logger.Error("failed to save committed evidence", "key(height/hash)", key, "err", err)
+		}
 
-		if err := evpool.evidenceStore.Set(key, evBytes); err != nil {
+		if err := evpool.saveCommittedEvidence(ev); err != nil {
 			evpool.logger.Error("failed to save committed evidence", "key(height/hash)", key, "err", err)
 		}
 
@@ -366,6 +579,33 @@ func (evpool *Pool) markEvidenceAsCommitted(evidence types.EvidenceList, height
 	atomic.AddUint32(&evpool.evidenceSize, ^uint32(len(blockEvidenceMap)-1))
 }
 
+// saveCommittedEvidence stores committed evidence and indexes its hash.
+func (evpool *Pool) saveCommittedEvidence(ev types.Evidence) error {
+	evpb, err := types.EvidenceToProto(ev)
+	if err != nil {
+		return fmt.Errorf("failed to convert to proto: %w", err)
+	}
+	evBytes, err := evpb.Marshal()
+	if err != nil {
+		return fmt.Errorf("failed to marshal evidence: %w", err)
+	}
+	heightBytes, err := proto.Marshal(&gogotypes.Int64Value{Value: ev.Height()})
+	if err != nil {
+		return fmt.Errorf("failed to marshal evidence height: %w", err)
+	}
+
+	batch := evpool.evidenceStore.NewBatch()
+	defer batch.Close()
+
+	if err := batch.Set(keyEvidence(prefixCommittedEvidence, ev.Height(), ev.Hash()), evBytes); err != nil {
+		return err
+	}
+	if err := batch.Set(keyHash(ev.Hash()), heightBytes); err != nil {
+		return err
+	}
+	return batch.Write()
+}
+
 // listEvidence retrieves lists evidence from oldest to newest within maxBytes.
 // If maxBytes is -1, there's no cap on the size of returned evidence.
 func (evpool *Pool) listEvidence(prefixKey int64, maxBytes int64) ([]types.Evidence, int64, error) {
@@ -478,6 +718,9 @@ func (evpool *Pool) batchExpiredPendingEvidence(batch dbm.Batch) (int64, time.Ti
 			evpool.logger.Error("failed to batch delete evidence", "err", err, "ev", ev)
 			continue
 		}
+		if err := batch.Delete(keyHash(ev.Hash())); err != nil {
+			evpool.logger.Error("failed to batch delete evidence hash", "err", err, "ev", ev)
+		}
 
 		// and add to the map to remove the evidence from the clist
 		blockEvidenceMap[evMapKey(ev)] = struct{}{}
@@ -615,17 +858,38 @@ func prefixToBytes(prefix int64) []byte {
 }
 
 func keyCommitted(evidence types.Evidence) []byte {
-	height := evidence.Height()
-	key, err := orderedcode.Append(nil, prefixCommitted, height, string(evidence.Hash()))
+	return keyEvidence(prefixCommitted, evidence.Height(), evidence.Hash())
+}
+
+func keyPending(evidence types.Evidence) []byte {
+	return keyEvidence(prefixPending, evidence.Height(), evidence.Hash())
+}
+
+func keyEvidence(prefix, height int64, hash []byte) []byte {
+	key, err := orderedcode.Append(nil, prefix, height, string(hash))
 	if err != nil {
 		panic(err)
 	}
 	return key
 }
 
-func keyPending(evidence types.Evidence) []byte {
-	height := evidence.Height()
-	key, err := orderedcode.Append(nil, prefixPending, height, string(evidence.Hash()))
+// parseEvidenceKey returns the evidence height and hash of a pending or
+// committed evidence key.
+func parseEvidenceKey(key []byte) (int64, []byte, error) {
+	var (
+		prefix, height int64
+		hash           string
+	)
+	if _, err := orderedcode.Parse(string(key), &prefix, &height, &hash); err != nil {
+		return 0, nil, fmt.Errorf("failed to parse evidence key: %w", err)
+	}
+	return height, []byte(hash), nil
+}
+
+// keyHash is the key of the hash index, which maps the hash of pending and
+// committed evidence to its height.
+func keyHash(hash []byte) []byte {
+	key, err := orderedcode.Append(nil, prefixHash, string(hash))
 	if err != nil {
 		panic(err)
 	}
@@ -233,6 +233,97 @@ func TestEvidencePoolUpdate(t *testing.T) {
 	}
 }
 
+func TestEvidencePoolQueries(t *testing.T) {
+	height := int64(21)
+	val := types.NewMockPV()
+	valAddress := val.PrivKey.PubKey().Address()
+	stateStore := initializeValidatorState(t, val, height)
+	state, err := stateStore.Load()
+	require.NoError(t, err)
+	blockStore := initializeBlockStore(dbm.NewMemDB(), state, valAddress)
+	evidenceDB := dbm.NewMemDB()
+
+	pool, err := evidence.NewPool(log.TestingLogger(), evidenceDB, stateStore, blockStore)
+	require.NoError(t, err)
+
+	pendingEv := types.NewMockDuplicateVoteEvidenceWithValidator(
+		height,
+		defaultEvidenceTime.Add(21*time.Minute),
+		val,
+		evidenceChainID,
+	)
+	committedEv := types.NewMockDuplicateVoteEvidenceWithValidator(
+		height-1,
+		defaultEvidenceTime.Add(20*time.Minute),
+		val,
+		evidenceChainID,
+	)
+	require.NoError(t, pool.AddEvidence(pendingEv))
+	require.NoError(t, pool.AddEvidence(committedEv))
+
+	pending, err := pool.ListPendingEvidence()
+	require.NoError(t, err)
+	require.Len(t, pending, 2)
+
+	// commit one of them in the next block
+	lastCommit := makeCommit(height, valAddress)
+	lastCommit.BlockID = blockStore.LoadBlockMeta(height).BlockID
+	block, _ := state.MakeBlock(height+1, []types.Tx{}, lastCommit,
+		[]types.Evidence{committedEv}, state.Validators.GetProposer().Address)
+	block.Header.Version = version.Consensus{Block: version.BlockProtocol, App: 1}
+	blockStore.SaveBlock(block, block.MakePartSet(types.BlockPartSizeBytes), makeCommit(height+1, valAddress))
+	state.LastBlockHeight = height + 1
+	state.LastBlockTime = defaultEvidenceTime.Add(22 * time.Minute)
+	pool.Update(state, block.Evidence.Evidence)
+
+	pending, err = pool.ListPendingEvidence()
+	require.NoError(t, err)
+	require.Equal(t, []types.Evidence{pendingEv}, pending)
+
+	committed, err := pool.ListCommittedEvidence(0)
+	require.NoError(t, err)
+	require.Len(t, committed, 1)
+	require.Equal(t, committedEv.Hash(), committed[0].Evidence.Hash())
+	require.Equal(t, height+1, committed[0].Height)
+
+	committed, err = pool.ListCommittedEvidence(height + 1)
+	require.NoError(t, err)
+	require.Len(t, committed, 1)
+	committed, err = pool.ListCommittedEvidence(height + 2)
+	require.NoError(t, err)
+	require.Empty(t, committed)
+
+	ev, evHeight, err := pool.GetEvidence(pendingEv.Hash())
+	require.NoError(t, err)
+	require.Equal(t, pendingEv, ev)
+	require.EqualValues(t, 0, evHeight)
+
+	ev, evHeight, err = pool.GetEvidence(committedEv.Hash())
+	require.NoError(t, err)
+	require.Equal(t, committedEv.Hash(), ev.Hash())
+	require.Equal(t, height+1, evHeight)
+
+	ev, _, err = pool.GetEvidence([]byte("unknown"))
+	require.NoError(t, err)
+	require.Nil(t, ev)
+
+	// committed evidence is stored in the evidence store, and queried without
+	// loading the block it was committed in
+	pool, err = evidence.NewPool(log.TestingLogger(), evidenceDB, stateStore, store.NewBlockStore(dbm.NewMemDB()))
+	require.NoError(t, err)
+
+	committed, err = pool.ListCommittedEvidence(0)
+	require.NoError(t, err)
+	require.Len(t, committed, 1)
+	require.Equal(t, committedEv.Hash(), committed[0].Evidence.Hash())
+	require.Equal(t, height+1, committed[0].Height)
+
+	ev, evHeight, err = pool.GetEvidence(committedEv.Hash())
+	require.NoError(t, err)
+	require.Equal(t, committedEv.Hash(), ev.Hash())
+	require.Equal(t, height+1, evHeight)
+}
+
 func TestVerifyPendingEvidencePasses(t *testing.T) {
 	var height int64 = 1
 
@@ -0,0 +1,102 @@
+// Package compress implements the transparent, per-record compression used
+// by the block and state stores.
+//
+// Records written with a codec other than None are prefixed with a two-byte
+// header: a zero marker byte followed by the codec identifier. A protobuf
+// encoded message can never begin with a zero byte (field number 0 is
+// invalid), so records without a header are read back as-is. This keeps
+// databases written before compression was enabled, or with a mix of codecs,
+// readable without a migration.
+package compress
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/golang/snappy"
+)
+
+// Codec identifies the compression algorithm used for a record.
+type Codec byte
+
+const (
+	// None stores records uncompressed and without a header.
+	None Codec = 0
+	// Snappy compresses records with github.com/golang/snappy.
+	Snappy Codec = 1
+)
+
+const (
+	// headerMarker is the first byte of every compressed record.
+	headerMarker = byte(0x00)
+	headerSize   = 2
+)
+
+// ErrUnknownCodec is returned when a record or a configuration value refers
+// to an unsupported codec.
+var ErrUnknownCodec = errors.New("unknown compression codec")
+
+// ParseCodec returns the codec with the given name. The empty string is
+// treated as "none".
+func ParseCodec(name string) (Codec, error) {
+	switch name {
+	case "", "none":
+		return None, nil
+	case "snappy":
+		return Snappy, nil
+	default:
+		return None, fmt.Errorf("%w %q (must be 'none' or 'snappy')", ErrUnknownCodec, name)
+	}
+}
+
+// String implements fmt.Stringer.
+func (c Codec) String() string {
+	switch c {
+	case None:
+		return "none"
+	case Snappy:
+		return "snappy"
+	default:
+		return fmt.Sprintf("unknown(%d)", byte(c))
+	}
+}
+
+// Encode compresses bz with the given codec and prepends the record header.
+// If compression does not make the record smaller, the raw bytes are
+// returned instead, since readers detect the codec per record.
+func Encode(c Codec, bz []byte) []byte {
+	switch c {
+	case Snappy:
+		out := make([]byte, headerSize+snappy.MaxEncodedLen(len(bz)))
+		out[0], out[1] = headerMarker, byte(Snappy)
+		out = out[:headerSize+len(snappy.Encode(out[headerSize:], bz))]
+		if len(out) < len(bz) {
+			return out
+		}
+	}
+	return bz
+}
+
+// Decode returns the uncompressed contents of a record written by Encode.
+func Decode(bz []byte) ([]byte, error) {
+	switch c := RecordCodec(bz); c {
+	case None:
+		return bz, nil
+	case Snappy:
+		out, err := snappy.Decode(nil, bz[headerSize:])
+		if err != nil {
+			return nil, fmt.Errorf("decoding snappy record: %w", err)
+		}
+		return out, nil
+	default:
+		return nil, fmt.Errorf("%w: %v", ErrUnknownCodec, c)
+	}
+}
+
+// RecordCodec returns the codec a record was written with.
+func RecordCodec(bz []byte) Codec {
+	if len(bz) < headerSize || bz[0] != headerMarker {
+		return None
+	}
+	return Codec(bz[1])
+}
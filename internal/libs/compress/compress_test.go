@@ -0,0 +1,107 @@
+package compress
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	dbm "github.com/tendermint/tm-db"
+)
+
+func TestParseCodec(t *testing.T) {
+	testCases := []struct {
+		name    string
+		codec   Codec
+		wantErr bool
+	}{
+		{"", None, false},
+		{"none", None, false},
+		{"snappy", Snappy, false},
+		{"zstd", None, true},
+	}
+	for _, tc := range testCases {
+		codec, err := ParseCodec(tc.name)
+		if tc.wantErr {
+			assert.ErrorIs(t, err, ErrUnknownCodec, tc.name)
+			continue
+		}
+		require.NoError(t, err, tc.name)
+		assert.Equal(t, tc.codec, codec, tc.name)
+	}
+}
+
+func TestEncodeDecode(t *testing.T) {
+	compressible := bytes.Repeat([]byte{0x0a, 0x01, 0x02}, 1000)
+	incompressible := []byte{0x0a, 0x03, 0x01, 0x02, 0x03}
+
+	testCases := []struct {
+		desc      string
+		codec     Codec
+		input     []byte
+		wantCodec Codec
+	}{
+		{"none", None, compressible, None},
+		{"snappy", Snappy, compressible, Snappy},
+		{"snappy falls back to raw", Snappy, incompressible, None},
+	}
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.desc, func(t *testing.T) {
+			bz := Encode(tc.codec, tc.input)
+			assert.Equal(t, tc.wantCodec, RecordCodec(bz))
+			if tc.wantCodec != None {
+				assert.Less(t, len(bz), len(tc.input))
+			}
+
+			out, err := Decode(bz)
+			require.NoError(t, err)
+			assert.Equal(t, tc.input, out)
+		})
+	}
+
+	_, err := Decode([]byte{headerMarker, 0xff, 0x01})
+	assert.ErrorIs(t, err, ErrUnknownCodec)
+}
+
+func TestRecompress(t *testing.T) {
+	ctx := context.Background()
+	db := dbm.NewMemDB()
+
+	value := bytes.Repeat([]byte{0x0a, 0x01, 0x02}, 100)
+	for i := byte(0); i < 10; i++ {
+		require.NoError(t, db.Set([]byte{0x01, i}, value))
+	}
+	// outside of the range and must not be touched
+	require.NoError(t, db.Set([]byte{0x02, 0x00}, value))
+
+	stats, err := Recompress(ctx, db, []byte{0x01}, []byte{0x02}, Snappy, 3)
+	require.NoError(t, err)
+	assert.EqualValues(t, 10, stats.Records)
+	assert.EqualValues(t, 10, stats.Rewritten)
+	assert.Less(t, stats.BytesAfter, stats.BytesBefore)
+
+	for i := byte(0); i < 10; i++ {
+		bz, err := db.Get([]byte{0x01, i})
+		require.NoError(t, err)
+		assert.Equal(t, Snappy, RecordCodec(bz))
+	}
+	bz, err := db.Get([]byte{0x02, 0x00})
+	require.NoError(t, err)
+	assert.Equal(t, value, bz)
+
+	// a second run has nothing left to do
+	stats, err = Recompress(ctx, db, []byte{0x01}, []byte{0x02}, Snappy, 3)
+	require.NoError(t, err)
+	assert.EqualValues(t, 10, stats.Records)
+	assert.Zero(t, stats.Rewritten)
+
+	// and converting back restores the raw records
+	stats, err = Recompress(ctx, db, []byte{0x01}, []byte{0x02}, None, 4)
+	require.NoError(t, err)
+	assert.EqualValues(t, 10, stats.Rewritten)
+	bz, err = db.Get([]byte{0x01, 0x05})
+	require.NoError(t, err)
+	assert.Equal(t, value, bz)
+}
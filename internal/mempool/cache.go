@@ -11,7 +11,8 @@ import (
 // Currently, a TxCache does not allow direct reading or getting of transaction
 // values. A TxCache is used primarily to push transactions and removing
 // transactions. Pushing via Push returns a boolean telling the caller if the
-// transaction already exists in the cache or not.
+// transaction already exists in the cache or not, and Has checks it by key
+// without adding it.
 type TxCache interface {
 	// Reset resets the cache to an empty state.
 	Reset()
@@ -22,6 +23,9 @@ type TxCache interface {
 
 	// Remove removes the given raw transaction from the cache.
 	Remove(tx types.Tx)
+
+	// Has returns true if a transaction with the given key is in the cache.
+	Has(key types.TxKey) bool
 }
 
 var _ TxCache = (*LRUTxCache)(nil)
@@ -97,11 +101,20 @@ func (c *LRUTxCache) Remove(tx types.Tx) {
 	}
 }
 
+func (c *LRUTxCache) Has(key types.TxKey) bool {
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+
+	_, ok := c.cacheMap[key]
+	return ok
+}
+
 // NopTxCache defines a no-op raw transaction cache.
 type NopTxCache struct{}
 
 var _ TxCache = (*NopTxCache)(nil)
 
-func (NopTxCache) Reset()             {}
-func (NopTxCache) Push(types.Tx) bool { return true }
-func (NopTxCache) Remove(types.Tx)    {}
+func (NopTxCache) Reset()               {}
+func (NopTxCache) Push(types.Tx) bool   { return true }
+func (NopTxCache) Remove(types.Tx)      {}
+func (NopTxCache) Has(types.TxKey) bool { return false }
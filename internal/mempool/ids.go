@@ -55,6 +55,19 @@ func (ids *IDs) GetForPeer(peerID types.NodeID) uint16 {
 	return ids.peerMap[peerID]
 }
 
+// GetPeer returns the peer an ID is reserved for.
+func (ids *IDs) GetPeer(id uint16) (types.NodeID, bool) {
+	ids.mtx.RLock()
+	defer ids.mtx.RUnlock()
+
+	for peerID, peerMempoolID := range ids.peerMap {
+		if peerMempoolID == id {
+			return peerID, true
+		}
+	}
+	return "", false
+}
+
 // nextPeerID returns the next unused peer ID to use. We assume that the mutex
 // is already held.
 func (ids *IDs) nextPeerID() uint16 {
@@ -0,0 +1,165 @@
+package mempool
+
+import (
+	"fmt"
+	"sort"
+	"sync/atomic"
+
+	"github.com/tendermint/tendermint/config"
+	"github.com/tendermint/tendermint/types"
+)
+
+// txLane is a mempool lane, holding the valid transactions the application
+// assigned to it in a priority index. Each lane has its own limits within the
+// mempool-wide ones, share of block space and gossip priority.
+type txLane struct {
+	config.MempoolLane
+
+	priorityIndex *TxPriorityQueue
+
+	// sizeBytes defines the total size of the lane (sum of all tx bytes)
+	sizeBytes int64
+}
+
+// newTxLanes returns the configured lanes and the default lane, in
+// descending gossip priority order. It errors if the lanes are invalid, see
+// MempoolConfig.ValidateBasic.
+func newTxLanes(cfg *config.MempoolConfig) ([]*txLane, error) {
+	laneCfgs, err := cfg.ParseLanes()
+	if err != nil {
+		return nil, fmt.Errorf("invalid mempool lanes: %w", err)
+	}
+	laneCfgs = append(laneCfgs, config.MempoolLane{Name: config.DefaultMempoolLane})
+
+	lanes := make([]*txLane, len(laneCfgs))
+	for i, laneCfg := range laneCfgs {
+		lanes[i] = &txLane{
+			MempoolLane:   laneCfg,
+			priorityIndex: NewTxPriorityQueue(),
+		}
+	}
+	sort.SliceStable(lanes, func(i, j int) bool {
+		return lanes[i].GossipPriority > lanes[j].GossipPriority
+	})
+	return lanes, nil
+}
+
+// NumTxs returns the number of transactions in the lane.
+func (l *txLane) NumTxs() int {
+	return l.priorityIndex.NumTxs()
+}
+
+// SizeBytes returns the total size of the transactions in the lane.
+func (l *txLane) SizeBytes() int64 {
+	return atomic.LoadInt64(&l.sizeBytes)
+}
+
+// fits returns true if a lane with the given number of transactions and
+// bytes is within its limits.
+func (l *txLane) fits(numTxs int, sizeBytes int64) bool {
+	return (l.MaxTxs == 0 || numTxs <= l.MaxTxs) &&
+		(l.MaxTxsBytes == 0 || sizeBytes <= l.MaxTxsBytes)
+}
+
+// canAddTx returns an error if the transaction doesn't fit in the lane.
+func (l *txLane) canAddTx(wtx *WrappedTx) error {
+	numTxs, sizeBytes := l.NumTxs(), l.SizeBytes()
+	if !l.fits(numTxs+1, sizeBytes+int64(wtx.Size())) {
+		return types.ErrMempoolLaneIsFull{
+			Lane:        l.Name,
+			NumTxs:      numTxs,
+			MaxTxs:      l.MaxTxs,
+			TxsBytes:    sizeBytes,
+			MaxTxsBytes: l.MaxTxsBytes,
+		}
+	}
+	return nil
+}
+
+// lane returns the lane with the given name, or the default lane if there is
+// no such lane.
+func (txmp *TxMempool) lane(name string) *txLane {
+	if lane, ok := txmp.lanes[name]; ok {
+		return lane
+	}
+	return txmp.lanes[config.DefaultMempoolLane]
+}
+
+// gossipPriority returns the gossip priority of the lane of a transaction.
+func (txmp *TxMempool) gossipPriority(wtx *WrappedTx) int {
+	return txmp.lane(wtx.lane).GossipPriority
+}
+
+// nextLane returns the lane holding the highest priority transaction, or nil
+// if the mempool is empty.
+func (txmp *TxMempool) nextLane() *txLane {
+	var (
+		next   *txLane
+		nextTx *WrappedTx
+	)
+	for _, lane := range txmp.laneOrder {
+		wtx := lane.priorityIndex.PeekTx()
+		if wtx != nil && (nextTx == nil || higherPriority(wtx, nextTx)) {
+			next, nextTx = lane, wtx
+		}
+	}
+	return next
+}
+
+// evictableTxs returns the transactions of lower priority than the given one
+// to evict to make room for wtx in the mempool and in the given lane, lowest
+// priority first, or nil if there is no such set of transactions. Only
+// transactions in lanes with a gossip priority lower than or equal to the
+// lane's are evicted, and if the lane is full, only its own transactions, so
+// that a lane can't crowd out the others.
+func (txmp *TxMempool) evictableTxs(wtx *WrappedTx, priority int64, lane *txLane) []*WrappedTx {
+	var txs []*WrappedTx
+	if lane.canAddTx(wtx) != nil {
+		txs = lane.priorityIndex.GetTxs()
+	} else {
+		for _, l := range txmp.laneOrder {
+			if l.GossipPriority <= lane.GossipPriority {
+				txs = append(txs, l.priorityIndex.GetTxs()...)
+			}
+		}
+	}
+
+	sort.Slice(txs, func(i, j int) bool {
+		return txs[i].priority < txs[j].priority
+	})
+
+	var (
+		toEvict   []*WrappedTx
+		txSize    = int64(wtx.Size())
+		numTxs    = txmp.Size()
+		sizeBytes = txmp.SizeBytes()
+		laneTxs   = lane.NumTxs()
+		laneBytes = lane.SizeBytes()
+	)
+
+	// Evict transactions in ascending priority order, as long as they are of
+	// less priority than the new transaction, until there is sufficient
+	// capacity for it.
+	for i := 0; i < len(txs) && txs[i].priority < priority; i++ {
+		toEvict = append(toEvict, txs[i])
+		numTxs--
+		sizeBytes -= int64(txs[i].Size())
+		if txs[i].lane == lane.Name {
+			laneTxs--
+			laneBytes -= int64(txs[i].Size())
+		}
+
+		if numTxs < txmp.config.Size && sizeBytes+txSize <= txmp.config.MaxTxsBytes &&
+			lane.fits(laneTxs+1, laneBytes+txSize) {
+			return toEvict
+		}
+	}
+
+	return nil
+}
+
+// updateLaneMetrics updates the size metrics of a lane.
+func (txmp *TxMempool) updateLaneMetrics(lane *txLane) {
+	txmp.metrics.LaneSize.With("lane", lane.Name).Set(float64(lane.NumTxs()))
+	txmp.metrics.LaneSizeBytes.With("lane", lane.Name).Set(float64(lane.SizeBytes()))
+}
@@ -6,6 +6,7 @@ import (
 	"errors"
 	"fmt"
 	"reflect"
+	"sort"
 	"sync/atomic"
 	"time"
 
@@ -25,14 +26,15 @@ var _ Mempool = (*TxMempool)(nil)
 type TxMempoolOption func(*TxMempool)
 
 // TxMempool defines a prioritized mempool data structure used by the v1 mempool
-// reactor. It keeps a thread-safe priority queue of transactions that is used
-// when a block proposer constructs a block and a thread-safe linked-list that
-// is used to gossip transactions to peers in a FIFO manner.
+// reactor. It keeps a thread-safe priority queue of transactions per lane that
+// is used when a block proposer constructs a block and a thread-safe
+// linked-list that is used to gossip transactions to peers in a FIFO manner.
 type TxMempool struct {
 	logger       log.Logger
 	metrics      *Metrics
 	config       *config.MempoolConfig
 	proxyAppConn proxy.AppConnMempool
+	eventBus     types.MempoolEventPublisher
 
 	// txsAvailable fires once for each height when the mempool is not empty
 	txsAvailable         chan struct{}
@@ -70,9 +72,12 @@ type TxMempool struct {
 	recheckCursor *clist.CElement // next expected response
 	recheckEnd    *clist.CElement // re-checking stops here
 
-	// priorityIndex defines the priority index of valid transactions via a
-	// thread-safe priority queue.
-	priorityIndex *TxPriorityQueue
+	// lanes defines the lanes the application can assign transactions to,
+	// each with a priority index of its valid transactions via a thread-safe
+	// priority queue. laneOrder lists the lanes in descending gossip priority
+	// order.
+	lanes     map[string]*txLane
+	laneOrder []*txLane
 
 	// heightIndex defines a height-based, in ascending order, transaction index.
 	// i.e. older transactions are first.
@@ -91,13 +96,19 @@ type TxMempool struct {
 	postCheck PostCheckFunc
 }
 
+// NewTxMempool returns a new mempool. It errors if the configured lanes are
+// invalid.
 func NewTxMempool(
 	logger log.Logger,
 	cfg *config.MempoolConfig,
 	proxyAppConn proxy.AppConnMempool,
 	height int64,
 	options ...TxMempoolOption,
-) *TxMempool {
+) (*TxMempool, error) {
+	laneOrder, err := newTxLanes(cfg)
+	if err != nil {
+		return nil, err
+	}
 
 	txmp := &TxMempool{
 		logger:        logger,
@@ -108,7 +119,8 @@ func NewTxMempool(
 		metrics:       NopMetrics(),
 		txStore:       NewTxStore(),
 		gossipIndex:   clist.New(),
-		priorityIndex: NewTxPriorityQueue(),
+		lanes:         make(map[string]*txLane),
+		laneOrder:     laneOrder,
 		heightIndex: NewWrappedTxList(func(wtx1, wtx2 *WrappedTx) bool {
 			return wtx1.height >= wtx2.height
 		}),
@@ -121,13 +133,17 @@ func NewTxMempool(
 		txmp.cache = NewLRUTxCache(cfg.CacheSize)
 	}
 
+	for _, lane := range txmp.laneOrder {
+		txmp.lanes[lane.Name] = lane
+	}
+
 	proxyAppConn.SetResponseCallback(txmp.defaultTxCallback)
 
 	for _, opt := range options {
 		opt(txmp)
 	}
 
-	return txmp
+	return txmp, nil
 }
 
 // WithPreCheck sets a filter for the mempool to reject a transaction if f(tx)
@@ -149,6 +165,12 @@ func WithMetrics(metrics *Metrics) TxMempoolOption {
 	return func(txmp *TxMempool) { txmp.metrics = metrics }
 }
 
+// WithEventBus sets the publisher of the events of transactions entering and
+// leaving the mempool.
+func WithEventBus(eventBus types.MempoolEventPublisher) TxMempoolOption {
+	return func(txmp *TxMempool) { txmp.eventBus = eventBus }
+}
+
 // Lock obtains a write-lock on the mempool. A caller must be sure to explicitly
 // release the lock when finished.
 func (txmp *TxMempool) Lock() {
@@ -301,13 +323,34 @@ func (txmp *TxMempool) CheckTx(
 	return nil
 }
 
+// pendingTx returns a transaction pending in the mempool by key, and the IDs
+// of the peers that sent it.
+func (txmp *TxMempool) pendingTx(txKey types.TxKey) (PendingTx, []uint16, bool) {
+	txmp.mtx.RLock()
+	defer txmp.mtx.RUnlock()
+
+	wtx := txmp.txStore.GetTxByHash(txKey)
+	if wtx == nil {
+		return PendingTx{}, nil, false
+	}
+
+	return PendingTx{
+		Tx:        wtx.tx,
+		Priority:  wtx.priority,
+		Sender:    wtx.sender,
+		Lane:      wtx.lane,
+		Height:    wtx.height,
+		Timestamp: wtx.timestamp,
+	}, txmp.txStore.TxPeers(txKey), true
+}
+
 func (txmp *TxMempool) RemoveTxByKey(txKey types.TxKey) error {
 	txmp.Lock()
 	defer txmp.Unlock()
 
 	// remove the committed transaction from the transaction store and indexes
 	if wtx := txmp.txStore.GetTxByHash(txKey); wtx != nil {
-		txmp.removeTx(wtx, false)
+		txmp.removeTx(wtx, false, types.MempoolTxRemoved, "removed by key")
 		return nil
 	}
 
@@ -328,7 +371,7 @@ func (txmp *TxMempool) Flush() {
 	txmp.timestampIndex.Reset()
 
 	for _, wtx := range txmp.txStore.GetAllTxs() {
-		txmp.removeTx(wtx, false)
+		txmp.removeTx(wtx, false, types.MempoolTxRemoved, "flushed")
 	}
 
 	atomic.SwapInt64(&txmp.sizeBytes, 0)
@@ -336,7 +379,9 @@ func (txmp *TxMempool) Flush() {
 }
 
 // ReapMaxBytesMaxGas returns a list of transactions within the provided size
-// and gas constraints. Transaction are retrieved in priority order.
+// and gas constraints. Each lane is first given its share of the block bytes,
+// then the remaining space is filled across lanes. Transaction are retrieved
+// and returned in priority order.
 //
 // NOTE:
 // - Transactions returned are not removed from the mempool transaction
@@ -350,39 +395,66 @@ func (txmp *TxMempool) ReapMaxBytesMaxGas(maxBytes, maxGas int64) types.Txs {
 		totalSize int64
 	)
 
-	// wTxs contains a list of *WrappedTx retrieved from the priority queue that
-	// need to be re-enqueued prior to returning.
-	wTxs := make([]*WrappedTx, 0, txmp.priorityIndex.NumTxs())
+	// wTxs contains a list of *WrappedTx retrieved from the priority queues
+	// that need to be re-enqueued prior to returning.
+	wTxs := make([]*WrappedTx, 0, txmp.Size())
 	defer func() {
 		for _, wtx := range wTxs {
-			txmp.priorityIndex.PushTx(wtx)
+			txmp.lane(wtx.lane).priorityIndex.PushTx(wtx)
 		}
 	}()
 
-	txs := make([]types.Tx, 0, txmp.priorityIndex.NumTxs())
-	for txmp.priorityIndex.NumTxs() > 0 {
-		wtx := txmp.priorityIndex.PopTx()
-		txs = append(txs, wtx.tx)
-		wTxs = append(wTxs, wtx)
+	// reap pops the next transaction of the lane if it fits within the
+	// provided number of bytes and maxGas, and returns its size, or -1.
+	reap := func(lane *txLane, maxBytes int64) int64 {
+		wtx := lane.priorityIndex.PeekTx()
 		size := types.ComputeProtoSizeForTxs([]types.Tx{wtx.tx})
-
-		// Ensure we have capacity for the transaction with respect to the
-		// transaction size.
-		if maxBytes > -1 && totalSize+size > maxBytes {
-			return txs[:len(txs)-1]
+		if maxBytes > -1 && size > maxBytes {
+			return -1
+		}
+		if maxGas > -1 && totalGas+wtx.gasWanted > maxGas {
+			return -1
 		}
 
+		wTxs = append(wTxs, lane.priorityIndex.PopTx())
 		totalSize += size
-
-		// ensure we have capacity for the transaction with respect to total gas
-		gas := totalGas + wtx.gasWanted
-		if maxGas > -1 && gas > maxGas {
-			return txs[:len(txs)-1]
+		totalGas += wtx.gasWanted
+		return size
+	}
+
+	// Fill the block space reserved for each lane with its transactions, so
+	// that they can't be crowded out by higher priority transactions of other
+	// lanes.
+	if maxBytes > -1 {
+		for _, lane := range txmp.laneOrder {
+			reserved := maxBytes * int64(lane.BlockShare) / 100
+			for lane.NumTxs() > 0 {
+				size := reap(lane, reserved)
+				if size < 0 {
+					break
+				}
+				reserved -= size
+			}
 		}
+	}
 
-		totalGas = gas
+	for lane := txmp.nextLane(); lane != nil; lane = txmp.nextLane() {
+		remaining := int64(-1)
+		if maxBytes > -1 {
+			remaining = maxBytes - totalSize
+		}
+		if reap(lane, remaining) < 0 {
+			break
+		}
 	}
 
+	sort.SliceStable(wTxs, func(i, j int) bool {
+		return higherPriority(wTxs[i], wTxs[j])
+	})
+	txs := make([]types.Tx, len(wTxs))
+	for i, wtx := range wTxs {
+		txs[i] = wtx.tx
+	}
 	return txs
 }
 
@@ -396,24 +468,24 @@ func (txmp *TxMempool) ReapMaxTxs(max int) types.Txs {
 	txmp.mtx.RLock()
 	defer txmp.mtx.RUnlock()
 
-	numTxs := txmp.priorityIndex.NumTxs()
+	numTxs := txmp.Size()
 	if max < 0 {
 		max = numTxs
 	}
 
 	cap := tmmath.MinInt(numTxs, max)
 
-	// wTxs contains a list of *WrappedTx retrieved from the priority queue that
-	// need to be re-enqueued prior to returning.
+	// wTxs contains a list of *WrappedTx retrieved from the priority queues
+	// that need to be re-enqueued prior to returning.
 	wTxs := make([]*WrappedTx, 0, cap)
 	txs := make([]types.Tx, 0, cap)
-	for txmp.priorityIndex.NumTxs() > 0 && len(txs) < max {
-		wtx := txmp.priorityIndex.PopTx()
+	for lane := txmp.nextLane(); lane != nil && len(txs) < max; lane = txmp.nextLane() {
+		wtx := lane.priorityIndex.PopTx()
 		txs = append(txs, wtx.tx)
 		wTxs = append(wTxs, wtx)
 	}
 	for _, wtx := range wTxs {
-		txmp.priorityIndex.PushTx(wtx)
+		txmp.lane(wtx.lane).priorityIndex.PushTx(wtx)
 	}
 	return txs
 }
@@ -456,7 +528,7 @@ func (txmp *TxMempool) Update(
 
 		// remove the committed transaction from the transaction store and indexes
 		if wtx := txmp.txStore.GetTxByHash(tx.Key()); wtx != nil {
-			txmp.removeTx(wtx, false)
+			txmp.removeTx(wtx, false, types.MempoolTxRemoved, "committed")
 		}
 	}
 
@@ -536,6 +608,8 @@ func (txmp *TxMempool) initTxCallback(wtx *WrappedTx, res *abci.Response, txInfo
 
 	sender := checkTxRes.CheckTx.Sender
 	priority := checkTxRes.CheckTx.Priority
+	lane := txmp.lane(checkTxRes.CheckTx.Lane)
+	wtx.lane = lane.Name
 
 	if len(sender) > 0 {
 		if wtx := txmp.txStore.GetTxBySender(sender); wtx != nil {
@@ -549,13 +623,8 @@ func (txmp *TxMempool) initTxCallback(wtx *WrappedTx, res *abci.Response, txInfo
 		}
 	}
 
-	if err := txmp.canAddTx(wtx); err != nil {
-		evictTxs := txmp.priorityIndex.GetEvictableTxs(
-			priority,
-			int64(wtx.Size()),
-			txmp.SizeBytes(),
-			txmp.config.MaxTxsBytes,
-		)
+	if err := txmp.canAddTx(wtx, lane); err != nil {
+		evictTxs := txmp.evictableTxs(wtx, priority, lane)
 		if len(evictTxs) == 0 {
 			// No room for the new incoming transaction so we just remove it from
 			// the cache.
@@ -563,9 +632,11 @@ func (txmp *TxMempool) initTxCallback(wtx *WrappedTx, res *abci.Response, txInfo
 			txmp.logger.Error(
 				"rejected incoming good transaction; mempool full",
 				"tx", fmt.Sprintf("%X", wtx.tx.Hash()),
+				"lane", lane.Name,
 				"err", err.Error(),
 			)
 			txmp.metrics.RejectedTxs.Add(1)
+			txmp.metrics.LaneRejectedTxs.With("lane", lane.Name).Add(1)
 			return
 		}
 
@@ -575,7 +646,8 @@ func (txmp *TxMempool) initTxCallback(wtx *WrappedTx, res *abci.Response, txInfo
 		// - The transaction, toEvict, can be removed while a concurrent
 		//   reCheckTx callback is being executed for the same transaction.
 		for _, toEvict := range evictTxs {
-			txmp.removeTx(toEvict, true)
+			txmp.removeTx(toEvict, true, types.MempoolTxEvicted, fmt.Sprintf(
+				"evicted by tx %X of priority %d", wtx.tx.Hash(), priority))
 			txmp.logger.Debug(
 				"evicted existing good transaction; mempool full",
 				"old_tx", fmt.Sprintf("%X", toEvict.tx.Hash()),
@@ -584,6 +656,7 @@ func (txmp *TxMempool) initTxCallback(wtx *WrappedTx, res *abci.Response, txInfo
 				"new_priority", wtx.priority,
 			)
 			txmp.metrics.EvictedTxs.Add(1)
+			txmp.metrics.LaneEvictedTxs.With("lane", toEvict.lane).Add(1)
 		}
 	}
 
@@ -601,10 +674,12 @@ func (txmp *TxMempool) initTxCallback(wtx *WrappedTx, res *abci.Response, txInfo
 	txmp.logger.Debug(
 		"inserted good transaction",
 		"priority", wtx.priority,
+		"lane", wtx.lane,
 		"tx", fmt.Sprintf("%X", wtx.tx.Hash()),
 		"height", txmp.height,
 		"num_txs", txmp.Size(),
 	)
+	txmp.publishTxEvent(wtx, types.MempoolTxAdded, "passed CheckTx")
 	txmp.notifyTxsAvailable()
 }
 
@@ -684,7 +759,11 @@ func (txmp *TxMempool) defaultTxCallback(req *abci.Request, res *abci.Response)
 				panic("corrupted reCheckTx cursor")
 			}
 
-			txmp.removeTx(wtx, !txmp.config.KeepInvalidTxsInCache)
+			reason := fmt.Sprintf("failed re-CheckTx with code %d", checkTxRes.CheckTx.Code)
+			if err != nil {
+				reason = fmt.Sprintf("failed post-check: %v", err)
+			}
+			txmp.removeTx(wtx, !txmp.config.KeepInvalidTxsInCache, types.MempoolTxRecheckedInvalid, reason)
 		}
 	}
 
@@ -745,9 +824,9 @@ func (txmp *TxMempool) updateReCheckTxs() {
 }
 
 // canAddTx returns an error if we cannot insert the provided *WrappedTx into
-// the mempool due to mempool configured constraints. If it returns nil,
-// the transaction can be inserted into the mempool.
-func (txmp *TxMempool) canAddTx(wtx *WrappedTx) error {
+// the mempool and the given lane due to mempool configured constraints. If it
+// returns nil, the transaction can be inserted into the mempool.
+func (txmp *TxMempool) canAddTx(wtx *WrappedTx, lane *txLane) error {
 	var (
 		numTxs    = txmp.Size()
 		sizeBytes = txmp.SizeBytes()
@@ -762,12 +841,14 @@ func (txmp *TxMempool) canAddTx(wtx *WrappedTx) error {
 		}
 	}
 
-	return nil
+	return lane.canAddTx(wtx)
 }
 
 func (txmp *TxMempool) insertTx(wtx *WrappedTx) {
+	lane := txmp.lane(wtx.lane)
+
 	txmp.txStore.SetTx(wtx)
-	txmp.priorityIndex.PushTx(wtx)
+	lane.priorityIndex.PushTx(wtx)
 	txmp.heightIndex.Insert(wtx)
 	txmp.timestampIndex.Insert(wtx)
 
@@ -778,15 +859,21 @@ func (txmp *TxMempool) insertTx(wtx *WrappedTx) {
 	wtx.gossipEl = gossipEl
 
 	atomic.AddInt64(&txmp.sizeBytes, int64(wtx.Size()))
+	atomic.AddInt64(&lane.sizeBytes, int64(wtx.Size()))
+	txmp.updateLaneMetrics(lane)
 }
 
-func (txmp *TxMempool) removeTx(wtx *WrappedTx, removeFromCache bool) {
+// removeTx removes a transaction from the mempool, publishing an event with
+// the given status and reason.
+func (txmp *TxMempool) removeTx(wtx *WrappedTx, removeFromCache bool, status, reason string) {
 	if txmp.txStore.IsTxRemoved(wtx.hash) {
 		return
 	}
 
+	lane := txmp.lane(wtx.lane)
+
 	txmp.txStore.RemoveTx(wtx)
-	txmp.priorityIndex.RemoveTx(wtx)
+	lane.priorityIndex.RemoveTx(wtx)
 	txmp.heightIndex.Remove(wtx)
 	txmp.timestampIndex.Remove(wtx)
 
@@ -796,10 +883,35 @@ func (txmp *TxMempool) removeTx(wtx *WrappedTx, removeFromCache bool) {
 	wtx.gossipEl.DetachPrev()
 
 	atomic.AddInt64(&txmp.sizeBytes, int64(-wtx.Size()))
+	atomic.AddInt64(&lane.sizeBytes, int64(-wtx.Size()))
+	txmp.updateLaneMetrics(lane)
 
 	if removeFromCache {
 		txmp.cache.Remove(wtx.tx)
 	}
+
+	txmp.publishTxEvent(wtx, status, reason)
+}
+
+// publishTxEvent publishes an event of a transaction entering or leaving the
+// mempool, if an event bus is set.
+func (txmp *TxMempool) publishTxEvent(wtx *WrappedTx, status, reason string) {
+	if txmp.eventBus == nil {
+		return
+	}
+
+	err := txmp.eventBus.PublishEventMempoolTx(types.EventDataMempoolTx{
+		Tx:       wtx.tx,
+		Status:   status,
+		Reason:   reason,
+		Priority: wtx.priority,
+		Sender:   wtx.sender,
+		Lane:     wtx.lane,
+		Height:   txmp.height,
+	})
+	if err != nil {
+		txmp.logger.Error("failed publishing mempool tx event", "tx", fmt.Sprintf("%X", wtx.tx.Hash()), "err", err)
+	}
 }
 
 // purgeExpiredTxs removes all transactions that have exceeded their respective
@@ -812,12 +924,14 @@ func (txmp *TxMempool) removeTx(wtx *WrappedTx, removeFromCache bool) {
 func (txmp *TxMempool) purgeExpiredTxs(blockHeight int64) {
 	now := time.Now()
 	expiredTxs := make(map[types.TxKey]*WrappedTx)
+	reasons := make(map[types.TxKey]string)
 
 	if txmp.config.TTLNumBlocks > 0 {
 		purgeIdx := -1
 		for i, wtx := range txmp.heightIndex.txs {
 			if (blockHeight - wtx.height) > txmp.config.TTLNumBlocks {
 				expiredTxs[wtx.tx.Key()] = wtx
+				reasons[wtx.tx.Key()] = "exceeded ttl-num-blocks"
 				purgeIdx = i
 			} else {
 				// since the index is sorted, we know no other txs can be be purged
@@ -835,6 +949,9 @@ func (txmp *TxMempool) purgeExpiredTxs(blockHeight int64) {
 		for i, wtx := range txmp.timestampIndex.txs {
 			if now.Sub(wtx.timestamp) > txmp.config.TTLDuration {
 				expiredTxs[wtx.tx.Key()] = wtx
+				if _, ok := reasons[wtx.tx.Key()]; !ok {
+					reasons[wtx.tx.Key()] = "exceeded ttl-duration"
+				}
 				purgeIdx = i
 			} else {
 				// since the index is sorted, we know no other txs can be be purged
@@ -847,8 +964,8 @@ func (txmp *TxMempool) purgeExpiredTxs(blockHeight int64) {
 		}
 	}
 
-	for _, wtx := range expiredTxs {
-		txmp.removeTx(wtx, false)
+	for key, wtx := range expiredTxs {
+		txmp.removeTx(wtx, false, types.MempoolTxExpired, reasons[key])
 	}
 }
 
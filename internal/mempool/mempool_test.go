@@ -26,7 +26,8 @@ import (
 )
 
 // application extends the KV store application by overriding CheckTx to provide
-// transaction priority based on the value in the key/value pair.
+// transaction priority based on the value in the key/value pair, and the lane
+// based on an optional suffix (sender=key=value=lane).
 type application struct {
 	*kvstore.Application
 }
@@ -40,10 +41,15 @@ func (app *application) CheckTx(req abci.RequestCheckTx) abci.ResponseCheckTx {
 	var (
 		priority int64
 		sender   string
+		lane     string
 	)
 
 	// infer the priority from the raw transaction value (sender=key=value)
 	parts := bytes.Split(req.Tx, []byte("="))
+	if len(parts) == 4 {
+		lane = string(parts[3])
+		parts = parts[:3]
+	}
 	if len(parts) == 3 {
 		v, err := strconv.ParseInt(string(parts[2]), 10, 64)
 		if err != nil {
@@ -67,6 +73,7 @@ func (app *application) CheckTx(req abci.RequestCheckTx) abci.ResponseCheckTx {
 	return abci.ResponseCheckTx{
 		Priority:  priority,
 		Sender:    sender,
+		Lane:      lane,
 		Code:      code.CodeTypeOK,
 		GasWanted: 1,
 	}
@@ -75,13 +82,21 @@ func (app *application) CheckTx(req abci.RequestCheckTx) abci.ResponseCheckTx {
 func setup(t testing.TB, cacheSize int, options ...TxMempoolOption) *TxMempool {
 	t.Helper()
 
+	return setupWithConfig(t, func(cfg *config.MempoolConfig) {
+		cfg.CacheSize = cacheSize
+	}, options...)
+}
+
+func setupWithConfig(t testing.TB, configure func(*config.MempoolConfig), options ...TxMempoolOption) *TxMempool {
+	t.Helper()
+
 	app := &application{kvstore.NewApplication()}
 	cc := abciclient.NewLocalCreator(app)
 	logger := log.TestingLogger()
 
 	cfg, err := config.ResetTestRoot(strings.ReplaceAll(t.Name(), "/", "|"))
 	require.NoError(t, err)
-	cfg.Mempool.CacheSize = cacheSize
+	configure(cfg.Mempool)
 	appConnMem, err := cc(logger)
 	require.NoError(t, err)
 	require.NoError(t, appConnMem.Start())
@@ -91,7 +106,9 @@ func setup(t testing.TB, cacheSize int, options ...TxMempoolOption) *TxMempool {
 		require.NoError(t, appConnMem.Stop())
 	})
 
-	return NewTxMempool(logger.With("test", t.Name()), cfg.Mempool, appConnMem, 0, options...)
+	txmp, err := NewTxMempool(logger.With("test", t.Name()), cfg.Mempool, appConnMem, 0, options...)
+	require.NoError(t, err)
+	return txmp
 }
 
 func checkTxs(t *testing.T, txmp *TxMempool, numTxs int, peerID uint16) []testTx {
@@ -280,6 +297,124 @@ func TestTxMempool_ReapMaxBytesMaxGas(t *testing.T) {
 	require.Len(t, reapedTxs, 25)
 }
 
+func TestTxMempool_Lanes_Limits(t *testing.T) {
+	txmp := setupWithConfig(t, func(cfg *config.MempoolConfig) {
+		cfg.Size = 4
+		cfg.Lanes = "oracle:2:0:0:1"
+	})
+
+	checkTx := func(tx string) {
+		require.NoError(t, txmp.CheckTx(context.Background(), types.Tx(tx), nil, TxInfo{}))
+	}
+	hasTx := func(tx string) bool {
+		return txmp.txStore.GetTxByHash(types.Tx(tx).Key()) != nil
+	}
+
+	checkTx("o1=key=10=oracle")
+	checkTx("o2=key=20=oracle")
+	checkTx("d1=key=5")
+	require.Equal(t, 2, txmp.lane("oracle").NumTxs())
+	require.Equal(t, 1, txmp.lane(config.DefaultMempoolLane).NumTxs())
+
+	// The oracle lane is full, so a higher priority oracle tx evicts the
+	// lowest priority oracle tx, rather than the lower priority default tx.
+	checkTx("o3=key=15=oracle")
+	require.False(t, hasTx("o1=key=10=oracle"))
+	require.True(t, hasTx("d1=key=5"))
+	require.Equal(t, 2, txmp.lane("oracle").NumTxs())
+
+	// A lower priority oracle tx is rejected.
+	checkTx("o4=key=1=oracle")
+	require.False(t, hasTx("o4=key=1=oracle"))
+
+	// Txs with an unknown lane go to the default lane, and the mempool-wide
+	// limits apply across lanes.
+	checkTx("d2=key=6=unknown")
+	require.Equal(t, 2, txmp.lane(config.DefaultMempoolLane).NumTxs())
+	checkTx("d3=key=30")
+	require.False(t, hasTx("d1=key=5"))
+	require.Equal(t, 4, txmp.Size())
+	require.Equal(t, int64(len("o2=key=20=oracle")+len("o3=key=15=oracle")), txmp.lane("oracle").SizeBytes())
+}
+
+func TestNewTxMempool_InvalidLanes(t *testing.T) {
+	cfg := config.TestMempoolConfig()
+	cfg.Lanes = "default:1:1:1:1"
+	_, err := NewTxMempool(log.TestingLogger(), cfg, nil, 0)
+	require.Error(t, err)
+}
+
+func TestTxMempool_Lanes_Eviction(t *testing.T) {
+	txmp := setupWithConfig(t, func(cfg *config.MempoolConfig) {
+		cfg.Size = 3
+		cfg.Lanes = "oracle:0:0:0:1"
+	})
+
+	checkTx := func(tx string) {
+		require.NoError(t, txmp.CheckTx(context.Background(), types.Tx(tx), nil, TxInfo{}))
+	}
+	hasTx := func(tx string) bool {
+		return txmp.txStore.GetTxByHash(types.Tx(tx).Key()) != nil
+	}
+
+	checkTx("o1=key=10=oracle")
+	checkTx("o2=key=20=oracle")
+	checkTx("d1=key=30")
+
+	// Default txs only evict txs in lanes with a lower or equal gossip
+	// priority, even if txs of higher lanes have a lower priority.
+	checkTx("d2=key=40")
+	require.False(t, hasTx("d1=key=30"))
+	require.True(t, hasTx("o1=key=10=oracle"))
+
+	checkTx("d3=key=25")
+	require.False(t, hasTx("d3=key=25"))
+	require.Equal(t, 3, txmp.Size())
+
+	// Oracle txs evict the lowest priority txs of any lane.
+	checkTx("o3=key=50=oracle")
+	require.False(t, hasTx("o1=key=10=oracle"))
+	require.True(t, hasTx("d2=key=40"))
+	require.Equal(t, 3, txmp.Size())
+}
+
+func TestTxMempool_Lanes_ReapBlockShare(t *testing.T) {
+	txmp := setupWithConfig(t, func(cfg *config.MempoolConfig) {
+		cfg.Lanes = "oracle:0:0:50:1"
+	})
+
+	for i := 0; i < 10; i++ {
+		require.NoError(t, txmp.CheckTx(context.Background(),
+			types.Tx(fmt.Sprintf("d%d=key=%d", i, 100+i)), nil, TxInfo{}))
+		require.NoError(t, txmp.CheckTx(context.Background(),
+			types.Tx(fmt.Sprintf("o%d=key=%d=oracle", i, i)), nil, TxInfo{}))
+	}
+
+	// Each default tx takes 12 bytes of the block and each oracle tx 17 bytes.
+	// Half of the block is reserved for oracle txs, and the rest goes to the
+	// highest priority txs, in priority order.
+	require.Equal(t, types.Txs{
+		types.Tx("d9=key=109"),
+		types.Tx("d8=key=108"),
+		types.Tx("d7=key=107"),
+		types.Tx("d6=key=106"),
+		types.Tx("d5=key=105"),
+		types.Tx("o9=key=9=oracle"),
+		types.Tx("o8=key=8=oracle"),
+	}, txmp.ReapMaxBytesMaxGas(100, -1))
+
+	// Unused reserved space goes to other lanes.
+	reapedTxs := txmp.ReapMaxBytesMaxGas(-1, 12)
+	require.Len(t, reapedTxs, 12)
+	require.Equal(t, types.Tx("o9=key=9=oracle"), reapedTxs[10])
+
+	require.Equal(t, types.Txs{
+		types.Tx("d9=key=109"),
+		types.Tx("d8=key=108"),
+	}, txmp.ReapMaxTxs(2))
+	require.Equal(t, 20, txmp.Size())
+}
+
 func TestTxMempool_ReapMaxTxs(t *testing.T) {
 	txmp := setup(t, 0)
 	tTxs := checkTxs(t, txmp, 100, 0)
@@ -536,3 +671,86 @@ func TestTxMempool_CheckTxPostCheckError(t *testing.T) {
 		})
 	}
 }
+
+// eventRecorder records the published mempool tx events.
+type eventRecorder struct {
+	mtx    sync.Mutex
+	events []types.EventDataMempoolTx
+}
+
+func (r *eventRecorder) PublishEventMempoolTx(data types.EventDataMempoolTx) error {
+	r.mtx.Lock()
+	defer r.mtx.Unlock()
+
+	r.events = append(r.events, data)
+	return nil
+}
+
+// take returns the recorded events as "<tx> <status>: <reason>", and clears
+// them.
+func (r *eventRecorder) take() []string {
+	r.mtx.Lock()
+	defer r.mtx.Unlock()
+
+	var events []string
+	for _, event := range r.events {
+		events = append(events, fmt.Sprintf("%s %s: %s", string(event.Tx), event.Status, event.Reason))
+	}
+	r.events = nil
+	return events
+}
+
+func TestTxMempool_Events(t *testing.T) {
+	recorder := &eventRecorder{}
+	txmp := setupWithConfig(t, func(cfg *config.MempoolConfig) {
+		cfg.Size = 2
+		cfg.TTLNumBlocks = 2
+	}, WithEventBus(recorder))
+
+	checkTx := func(tx string) {
+		require.NoError(t, txmp.CheckTx(context.Background(), types.Tx(tx), nil, TxInfo{}))
+	}
+	update := func(height int64, blockTxs types.Txs, postCheck PostCheckFunc) {
+		responses := make([]*abci.ResponseDeliverTx, len(blockTxs))
+		for i := range responses {
+			responses[i] = &abci.ResponseDeliverTx{Code: abci.CodeTypeOK}
+		}
+		txmp.Lock()
+		require.NoError(t, txmp.Update(height, blockTxs, responses, nil, postCheck))
+		txmp.Unlock()
+	}
+
+	// A higher priority tx evicts a lower priority one from the full mempool.
+	checkTx("a=1=1")
+	checkTx("b=2=2")
+	checkTx("c=3=3")
+	require.Equal(t, []string{
+		"a=1=1 added: passed CheckTx",
+		"b=2=2 added: passed CheckTx",
+		fmt.Sprintf("a=1=1 evicted: evicted by tx %X of priority 3", types.Tx("c=3=3").Hash()),
+		"c=3=3 added: passed CheckTx",
+	}, recorder.take())
+
+	require.NoError(t, txmp.RemoveTxByKey(types.Tx("b=2=2").Key()))
+	update(1, types.Txs{types.Tx("c=3=3")}, nil)
+	require.Equal(t, []string{
+		"b=2=2 removed: removed by key",
+		"c=3=3 removed: committed",
+	}, recorder.take())
+
+	// Txs failing re-CheckTx are removed.
+	checkTx("d=4=4")
+	checkTx("e=5=5")
+	recorder.take()
+	update(2, nil, func(tx types.Tx, _ *abci.ResponseCheckTx) error {
+		if string(tx) == "e=5=5" {
+			return errors.New("test error")
+		}
+		return nil
+	})
+	require.Equal(t, []string{"e=5=5 rechecked_invalid: failed post-check: test error"}, recorder.take())
+
+	update(4, nil, nil)
+	require.Equal(t, []string{"d=4=4 expired: exceeded ttl-num-blocks"}, recorder.take())
+	require.Zero(t, txmp.Size())
+}
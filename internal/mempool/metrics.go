@@ -39,6 +39,18 @@ type Metrics struct {
 
 	// Number of times transactions are rechecked in the mempool.
 	RecheckTimes metrics.Counter
+
+	// Number of transactions in each lane.
+	LaneSize metrics.Gauge
+
+	// Total size of the transactions in each lane, in bytes.
+	LaneSizeBytes metrics.Gauge
+
+	// Number of transactions rejected by each lane due to resource limits.
+	LaneRejectedTxs metrics.Counter
+
+	// Number of transactions evicted from each lane.
+	LaneEvictedTxs metrics.Counter
 }
 
 // PrometheusMetrics returns Metrics build using Prometheus client library.
@@ -92,6 +104,34 @@ func PrometheusMetrics(namespace string, labelsAndValues ...string) *Metrics {
 			Name:      "recheck_times",
 			Help:      "Number of times transactions are rechecked in the mempool.",
 		}, labels).With(labelsAndValues...),
+
+		LaneSize: prometheus.NewGaugeFrom(stdprometheus.GaugeOpts{
+			Namespace: namespace,
+			Subsystem: MetricsSubsystem,
+			Name:      "lane_size",
+			Help:      "Number of uncommitted transactions in each mempool lane.",
+		}, append(labels, "lane")).With(labelsAndValues...),
+
+		LaneSizeBytes: prometheus.NewGaugeFrom(stdprometheus.GaugeOpts{
+			Namespace: namespace,
+			Subsystem: MetricsSubsystem,
+			Name:      "lane_size_bytes",
+			Help:      "Total size of the transactions in each mempool lane, in bytes.",
+		}, append(labels, "lane")).With(labelsAndValues...),
+
+		LaneRejectedTxs: prometheus.NewCounterFrom(stdprometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: MetricsSubsystem,
+			Name:      "lane_rejected_txs",
+			Help:      "Number of transactions rejected by each mempool lane.",
+		}, append(labels, "lane")).With(labelsAndValues...),
+
+		LaneEvictedTxs: prometheus.NewCounterFrom(stdprometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: MetricsSubsystem,
+			Name:      "lane_evicted_txs",
+			Help:      "Number of transactions evicted from each mempool lane.",
+		}, append(labels, "lane")).With(labelsAndValues...),
 	}
 }
 
@@ -104,5 +144,10 @@ func NopMetrics() *Metrics {
 		RejectedTxs:  discard.NewCounter(),
 		EvictedTxs:   discard.NewCounter(),
 		RecheckTimes: discard.NewCounter(),
+
+		LaneSize:        discard.NewGauge(),
+		LaneSizeBytes:   discard.NewGauge(),
+		LaneRejectedTxs: discard.NewCounter(),
+		LaneEvictedTxs:  discard.NewCounter(),
 	}
 }
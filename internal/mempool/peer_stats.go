@@ -0,0 +1,199 @@
+package mempool
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/tendermint/tendermint/internal/p2p"
+	"github.com/tendermint/tendermint/types"
+)
+
+// PeerTxStats counts the transactions received from a peer.
+type PeerTxStats struct {
+	AcceptedTxs    uint64 // passed CheckTx
+	InvalidTxs     uint64 // failed CheckTx or the mempool's checks
+	DuplicateTxs   uint64 // already seen, and sent unsolicited
+	RateLimitedTxs uint64 // dropped for exceeding the peer rate limits
+}
+
+// BadTxs returns the number of invalid, duplicate and rate limited
+// transactions.
+func (s PeerTxStats) BadTxs() uint64 {
+	return s.InvalidTxs + s.DuplicateTxs + s.RateLimitedTxs
+}
+
+// txOutcome is the outcome of a transaction received from a peer.
+type txOutcome int
+
+const (
+	txAccepted txOutcome = iota
+	txInvalid
+	txDuplicate
+	txRateLimited
+)
+
+// peerTxTracker tracks the transactions received from a peer, and limits the
+// rate at which they are checked with token buckets.
+type peerTxTracker struct {
+	stats PeerTxStats
+
+	txTokens   float64
+	byteTokens float64
+	refilled   time.Time
+
+	penalties    int  // score reductions to report
+	disconnect   bool // disconnect to report
+	disconnected bool // disconnect reported
+}
+
+func newPeerTxTracker(txRate int, bytesRate int64, now time.Time) *peerTxTracker {
+	return &peerTxTracker{
+		txTokens:   float64(txRate),
+		byteTokens: float64(bytesRate),
+		refilled:   now,
+	}
+}
+
+// allow refills the token buckets, allowing bursts of up to one second worth
+// of transactions, and takes a transaction of the given size from them if
+// there are tokens left. The byte bucket may go into debt, so that
+// transactions larger than the byte rate can be received. A rate of 0 means
+// unlimited.
+func (t *peerTxTracker) allow(size int, txRate int, bytesRate int64, now time.Time) bool {
+	elapsed := now.Sub(t.refilled).Seconds()
+	t.refilled = now
+	if elapsed > 0 {
+		t.txTokens += elapsed * float64(txRate)
+		if t.txTokens > float64(txRate) {
+			t.txTokens = float64(txRate)
+		}
+		t.byteTokens += elapsed * float64(bytesRate)
+		if t.byteTokens > float64(bytesRate) {
+			t.byteTokens = float64(bytesRate)
+		}
+	}
+
+	if (txRate > 0 && t.txTokens < 1) || (bytesRate > 0 && t.byteTokens <= 0) {
+		return false
+	}
+	if txRate > 0 {
+		t.txTokens--
+	}
+	if bytesRate > 0 {
+		t.byteTokens -= float64(size)
+	}
+	return true
+}
+
+// allowTx checks whether a transaction of the given size from a peer is
+// within the peer rate limits. Peers are only tracked while they are up, see
+// processPeerUpdate, and transactions from other peers are allowed.
+func (r *Reactor) allowTx(peerID types.NodeID, size int) bool {
+	if r.cfg.PeerTxRate == 0 && r.cfg.PeerTxBytesRate == 0 {
+		return true
+	}
+
+	r.statsMtx.Lock()
+	defer r.statsMtx.Unlock()
+
+	t, ok := r.peerStats[peerID]
+	if !ok {
+		return true
+	}
+	return t.allow(size, r.cfg.PeerTxRate, r.cfg.PeerTxBytesRate, time.Now())
+}
+
+// recordTx records the outcome of a transaction received from a peer. Every
+// BadTxsPerPenalty bad transactions, a score reduction is queued, and once
+// the peer sent MaxExcessBadTxs more bad transactions than accepted ones, a
+// disconnect. They are reported by reportPeerTxs. Outcomes of peers that are
+// no longer up, e.g. from CheckTx callbacks completing after the peer
+// disconnected, are ignored.
+func (r *Reactor) recordTx(peerID types.NodeID, outcome txOutcome) {
+	r.statsMtx.Lock()
+	defer r.statsMtx.Unlock()
+
+	t, ok := r.peerStats[peerID]
+	if !ok {
+		return
+	}
+	switch outcome {
+	case txAccepted:
+		t.stats.AcceptedTxs++
+		return
+	case txInvalid:
+		t.stats.InvalidTxs++
+	case txDuplicate:
+		t.stats.DuplicateTxs++
+	case txRateLimited:
+		t.stats.RateLimitedTxs++
+	}
+
+	bad := t.stats.BadTxs()
+	if r.cfg.BadTxsPerPenalty > 0 && bad%uint64(r.cfg.BadTxsPerPenalty) == 0 {
+		t.penalties++
+	}
+	if r.cfg.MaxExcessBadTxs > 0 && !t.disconnected &&
+		bad >= t.stats.AcceptedTxs+uint64(r.cfg.MaxExcessBadTxs) {
+		t.disconnect = true
+	}
+}
+
+// reportPeerTxs reports the queued score reductions to the peer manager, and
+// the queued disconnects as peer errors. It must be called from
+// processMempoolCh, since the mempool channel is closed when it exits.
+func (r *Reactor) reportPeerTxs() {
+	type report struct {
+		peerID     types.NodeID
+		stats      PeerTxStats
+		penalties  int
+		disconnect bool
+	}
+
+	r.statsMtx.Lock()
+	var reports []report
+	for peerID, t := range r.peerStats {
+		if t.penalties == 0 && !t.disconnect {
+			continue
+		}
+		reports = append(reports, report{
+			peerID:     peerID,
+			stats:      t.stats,
+			penalties:  t.penalties,
+			disconnect: t.disconnect,
+		})
+		t.disconnected = t.disconnected || t.disconnect
+		t.penalties, t.disconnect = 0, false
+	}
+	r.statsMtx.Unlock()
+
+	for _, rep := range reports {
+		for i := 0; i < rep.penalties; i++ {
+			r.peerUpdates.SendUpdate(p2p.PeerUpdate{
+				NodeID: rep.peerID,
+				Status: p2p.PeerStatusBad,
+			})
+		}
+		if rep.disconnect {
+			r.Logger.Info("disconnecting peer sending bad txs", "peer", rep.peerID,
+				"bad_txs", rep.stats.BadTxs(), "accepted_txs", rep.stats.AcceptedTxs)
+			r.mempoolCh.Error <- p2p.PeerError{
+				NodeID: rep.peerID,
+				Err: fmt.Errorf("peer sent %d bad txs and only %d accepted txs",
+					rep.stats.BadTxs(), rep.stats.AcceptedTxs),
+			}
+		}
+	}
+}
+
+// PeerTxStats returns the transaction counts of a connected peer.
+func (r *Reactor) PeerTxStats(peerID types.NodeID) (PeerTxStats, bool) {
+	r.statsMtx.Lock()
+	defer r.statsMtx.Unlock()
+
+	t, ok := r.peerStats[peerID]
+	if !ok {
+		return PeerTxStats{}, false
+	}
+	return t.stats, true
+}
@@ -0,0 +1,37 @@
+package mempool
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestPeerTxTracker_Allow(t *testing.T) {
+	now := time.Now()
+
+	// Bursts of up to a second worth of txs are allowed.
+	tracker := newPeerTxTracker(2, 0, now)
+	require.True(t, tracker.allow(10, 2, 0, now))
+	require.True(t, tracker.allow(10, 2, 0, now))
+	require.False(t, tracker.allow(10, 2, 0, now))
+
+	now = now.Add(500 * time.Millisecond)
+	require.True(t, tracker.allow(10, 2, 0, now))
+	require.False(t, tracker.allow(10, 2, 0, now))
+
+	// The byte bucket may go into debt, but txs are dropped until it is
+	// refilled.
+	tracker = newPeerTxTracker(0, 100, now)
+	require.True(t, tracker.allow(150, 0, 100, now))
+	require.False(t, tracker.allow(1, 0, 100, now))
+
+	now = now.Add(time.Second)
+	require.True(t, tracker.allow(1, 0, 100, now))
+
+	// A rate of 0 is unlimited.
+	tracker = newPeerTxTracker(0, 0, now)
+	for i := 0; i < 100; i++ {
+		require.True(t, tracker.allow(1000, 0, 0, now))
+	}
+}
@@ -67,6 +67,17 @@ func (pq *TxPriorityQueue) GetEvictableTxs(priority, txSize, totalSize, cap int6
 	return nil
 }
 
+// GetTxs returns a copy of the transactions in the priority queue, in no
+// particular order. It is thread safe.
+func (pq *TxPriorityQueue) GetTxs() []*WrappedTx {
+	pq.mtx.RLock()
+	defer pq.mtx.RUnlock()
+
+	txs := make([]*WrappedTx, len(pq.txs))
+	copy(txs, pq.txs)
+	return txs
+}
+
 // NumTxs returns the number of transactions in the priority queue. It is
 // thread safe.
 func (pq *TxPriorityQueue) NumTxs() int {
@@ -94,6 +105,18 @@ func (pq *TxPriorityQueue) PushTx(tx *WrappedTx) {
 	heap.Push(pq, tx)
 }
 
+// PeekTx returns the top priority transaction without removing it from the
+// queue, or nil if the queue is empty. It is thread safe.
+func (pq *TxPriorityQueue) PeekTx() *WrappedTx {
+	pq.mtx.RLock()
+	defer pq.mtx.RUnlock()
+
+	if len(pq.txs) == 0 {
+		return nil
+	}
+	return pq.txs[0]
+}
+
 // PopTx removes the top priority transaction from the queue. It is thread safe.
 func (pq *TxPriorityQueue) PopTx() *WrappedTx {
 	pq.mtx.Lock()
@@ -140,15 +163,19 @@ func (pq *TxPriorityQueue) Len() int {
 // Less implements the Heap interface. It returns true if the transaction at
 // position i in the queue is of less priority than the transaction at position j.
 func (pq *TxPriorityQueue) Less(i, j int) bool {
+	// We want Pop to give us the highest, not lowest, priority.
+	return higherPriority(pq.txs[i], pq.txs[j])
+}
+
+// higherPriority returns true if wtx1 is of higher priority than wtx2.
+func higherPriority(wtx1, wtx2 *WrappedTx) bool {
 	// If there exists two transactions with the same priority, consider the one
 	// that we saw the earliest as the higher priority transaction.
-	if pq.txs[i].priority == pq.txs[j].priority {
-		return pq.txs[i].timestamp.Before(pq.txs[j].timestamp)
+	if wtx1.priority == wtx2.priority {
+		return wtx1.timestamp.Before(wtx2.timestamp)
 	}
 
-	// We want Pop to give us the highest, not lowest, priority so we use greater
-	// than here.
-	return pq.txs[i].priority > pq.txs[j].priority
+	return wtx1.priority > wtx2.priority
 }
 
 // Swap implements the Heap interface. It swaps two transactions in the queue.
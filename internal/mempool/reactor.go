@@ -5,9 +5,11 @@ import (
 	"errors"
 	"fmt"
 	"runtime/debug"
+	"sort"
 	"sync"
 	"time"
 
+	abci "github.com/tendermint/tendermint/abci/types"
 	"github.com/tendermint/tendermint/config"
 	"github.com/tendermint/tendermint/internal/libs/clist"
 	tmsync "github.com/tendermint/tendermint/internal/libs/sync"
@@ -23,22 +25,40 @@ var (
 	_ p2p.Wrapper     = (*protomem.Message)(nil)
 )
 
+// haveWantP2PProtocol is the first P2P protocol version supporting the
+// HaveTxs and WantTxs messages.
+const haveWantP2PProtocol uint64 = 9
+
 // PeerManager defines the interface contract required for getting necessary
 // peer information. This should eventually be replaced with a message-oriented
 // approach utilizing the p2p stack.
 type PeerManager interface {
 	GetHeight(types.NodeID) int64
+	GetProtocolVersion(types.NodeID) types.ProtocolVersion
 }
 
 // Reactor implements a service that contains mempool of txs that are broadcasted
 // amongst peers. It maintains a map from peer ID to counter, to prevent gossiping
 // txs to the peers you received it from.
+//
+// Txs are gossiped with a have/want protocol: the reactor announces the keys
+// of new txs to each peer in batched HaveTxs messages, and peers request the
+// txs they don't have with WantTxs, which are sent back in Txs messages. Each
+// announced tx is requested from one peer at a time, falling back to other
+// announcers if the request times out. Peers running a P2P protocol version
+// older than haveWantP2PProtocol don't understand these messages, and are sent
+// full txs instead.
+//
+// The txs received from each peer are rate limited and counted, and peers
+// sending too many invalid or duplicate txs are penalized through the peer
+// manager, see recordTx.
 type Reactor struct {
 	service.BaseService
 
-	cfg     *config.MempoolConfig
-	mempool *TxMempool
-	ids     *IDs
+	cfg      *config.MempoolConfig
+	mempool  *TxMempool
+	ids      *IDs
+	requests *TxRequests
 
 	// XXX: Currently, this is the only way to get information about a peer. Ideally,
 	// we rely on message-oriented communication to get necessary peer data.
@@ -59,6 +79,9 @@ type Reactor struct {
 
 	mtx          tmsync.Mutex
 	peerRoutines map[types.NodeID]*tmsync.Closer
+
+	statsMtx  tmsync.Mutex
+	peerStats map[types.NodeID]*peerTxTracker
 }
 
 // NewReactor returns a reference to a new reactor.
@@ -76,10 +99,12 @@ func NewReactor(
 		peerMgr:      peerMgr,
 		mempool:      txmp,
 		ids:          NewMempoolIDs(),
+		requests:     NewTxRequests(cfg.TxRequestTimeout),
 		mempoolCh:    mempoolCh,
 		peerUpdates:  peerUpdates,
 		closeCh:      make(chan struct{}),
 		peerRoutines: make(map[types.NodeID]*tmsync.Closer),
+		peerStats:    make(map[types.NodeID]*peerTxTracker),
 		observePanic: defaultObservePanic,
 	}
 
@@ -99,11 +124,26 @@ func GetChannelDescriptor(cfg *config.MempoolConfig) *p2p.ChannelDescriptor {
 		},
 	}
 
+	txKeys := make([][]byte, MaxTxKeysPerMessage)
+	for i := range txKeys {
+		txKeys[i] = make([]byte, len(types.TxKey{}))
+	}
+	announceMsg := protomem.Message{
+		Sum: &protomem.Message_HaveTxs{
+			HaveTxs: &protomem.HaveTxs{TxKeys: txKeys},
+		},
+	}
+
+	recvCapacity := batchMsg.Size()
+	if announceMsg.Size() > recvCapacity {
+		recvCapacity = announceMsg.Size()
+	}
+
 	return &p2p.ChannelDescriptor{
 		ID:                  MempoolChannel,
 		MessageType:         new(protomem.Message),
 		Priority:            5,
-		RecvMessageCapacity: batchMsg.Size(),
+		RecvMessageCapacity: recvCapacity,
 		RecvBufferCapacity:  128,
 	}
 }
@@ -146,10 +186,28 @@ func (r *Reactor) OnStop() {
 	<-r.peerUpdates.Done()
 }
 
+// PendingTx returns a transaction pending in the mempool by key, with the
+// connected peers that sent it.
+func (r *Reactor) PendingTx(txKey types.TxKey) (PendingTx, bool) {
+	pending, peerIDs, ok := r.mempool.pendingTx(txKey)
+	if !ok {
+		return PendingTx{}, false
+	}
+
+	for _, id := range peerIDs {
+		if peerID, ok := r.ids.GetPeer(id); ok {
+			pending.Peers = append(pending.Peers, peerID)
+		}
+	}
+	sort.Slice(pending.Peers, func(i, j int) bool { return pending.Peers[i] < pending.Peers[j] })
+
+	return pending, true
+}
+
 // handleMempoolMessage handles envelopes sent from peers on the MempoolChannel.
-// For every tx in the message, we execute CheckTx. It returns an error if an
-// empty set of txs are sent in an envelope or if we receive an unexpected
-// message type.
+// For every tx in the message within the peer rate limits, we execute CheckTx.
+// It returns an error if an empty set of txs are sent in an envelope or if we
+// receive an unexpected message type.
 func (r *Reactor) handleMempoolMessage(envelope p2p.Envelope) error {
 	logger := r.Logger.With("peer", envelope.From)
 
@@ -165,9 +223,95 @@ func (r *Reactor) handleMempoolMessage(envelope p2p.Envelope) error {
 			txInfo.SenderNodeID = envelope.From
 		}
 
+		// Peers without have/want push every tx, so duplicates from them are
+		// expected, as are duplicates we requested from several announcers.
+		haveWant := r.supportsHaveWant(envelope.From)
+
 		for _, tx := range protoTxs {
-			if err := r.mempool.CheckTx(context.Background(), types.Tx(tx), nil, txInfo); err != nil {
-				logger.Error("checktx failed for tx", "tx", fmt.Sprintf("%X", types.Tx(tx).Hash()), "err", err)
+			tx := types.Tx(tx)
+			key := tx.Key()
+			requested := r.requests.Received(key, envelope.From)
+			badDuplicate := haveWant && !requested
+
+			if !r.allowTx(envelope.From, len(tx)) {
+				r.recordTx(envelope.From, txRateLimited)
+				continue
+			}
+
+			// Txs we've already seen are still passed to CheckTx, to record
+			// the peer as a sender, but don't run CheckTx again.
+			var cb func(*abci.Response)
+			if r.mempool.cache.Has(key) {
+				if badDuplicate {
+					r.recordTx(envelope.From, txDuplicate)
+				}
+			} else {
+				cb = func(res *abci.Response) {
+					if checkTxRes := res.GetCheckTx(); checkTxRes != nil &&
+						checkTxRes.Code == abci.CodeTypeOK && checkTxRes.MempoolError == "" {
+						r.recordTx(envelope.From, txAccepted)
+					} else {
+						r.recordTx(envelope.From, txInvalid)
+					}
+				}
+			}
+
+			err := r.mempool.CheckTx(context.Background(), tx, cb, txInfo)
+			switch {
+			case err == nil:
+			case errors.Is(err, types.ErrTxInCache):
+				if cb != nil && badDuplicate {
+					r.recordTx(envelope.From, txDuplicate)
+				}
+			default:
+				r.recordTx(envelope.From, txInvalid)
+				logger.Error("checktx failed for tx", "tx", fmt.Sprintf("%X", tx.Hash()), "err", err)
+			}
+		}
+
+	case *protomem.HaveTxs:
+		txKeys, err := parseTxKeys(msg.GetTxKeys())
+		if err != nil {
+			return err
+		}
+
+		// Record the peer as a sender of the txs we already have, so that we
+		// don't announce them back, and request the ones we haven't seen.
+		peerMempoolID := r.ids.GetForPeer(envelope.From)
+		want := make([]types.TxKey, 0, len(txKeys))
+		for _, key := range txKeys {
+			if wtx, _ := r.mempool.txStore.GetOrSetPeerByTxHash(key, peerMempoolID); wtx != nil {
+				continue
+			}
+			if r.mempool.cache.Has(key) {
+				continue
+			}
+			if r.requests.Announced(key, envelope.From) {
+				want = append(want, key)
+			}
+		}
+		r.requestTxs(envelope.From, want)
+
+	case *protomem.WantTxs:
+		txKeys, err := parseTxKeys(msg.GetTxKeys())
+		if err != nil {
+			return err
+		}
+
+		// Txs may have been removed from the mempool since we announced them,
+		// in which case the peer will request them from someone else. Txs in
+		// lanes with a higher gossip priority are sent first.
+		wtxs := make([]*WrappedTx, 0, len(txKeys))
+		for _, key := range txKeys {
+			if wtx := r.mempool.txStore.GetTxByHash(key); wtx != nil {
+				wtxs = append(wtxs, wtx)
+			}
+		}
+		r.sortByGossipPriority(wtxs)
+		for _, wtx := range wtxs {
+			r.mempoolCh.Out <- p2p.Envelope{
+				To:      envelope.From,
+				Message: &protomem.Txs{Txs: [][]byte{wtx.tx}},
 			}
 		}
 
@@ -178,6 +322,53 @@ func (r *Reactor) handleMempoolMessage(envelope p2p.Envelope) error {
 	return nil
 }
 
+// parseTxKeys parses the tx keys of a HaveTxs or WantTxs message.
+func parseTxKeys(keys [][]byte) ([]types.TxKey, error) {
+	if len(keys) == 0 {
+		return nil, errors.New("empty tx keys received from peer")
+	}
+	if len(keys) > MaxTxKeysPerMessage {
+		return nil, fmt.Errorf("too many tx keys received from peer (%d > %d)", len(keys), MaxTxKeysPerMessage)
+	}
+
+	txKeys := make([]types.TxKey, len(keys))
+	for i, key := range keys {
+		if len(key) != len(txKeys[i]) {
+			return nil, fmt.Errorf("invalid tx key length %d", len(key))
+		}
+		copy(txKeys[i][:], key)
+	}
+	return txKeys, nil
+}
+
+// requestTxs sends WantTxs messages for the given tx keys to a peer.
+func (r *Reactor) requestTxs(peerID types.NodeID, keys []types.TxKey) {
+	for len(keys) > 0 {
+		n := len(keys)
+		if n > MaxTxKeysPerMessage {
+			n = MaxTxKeysPerMessage
+		}
+
+		want := make([][]byte, n)
+		for i := range want {
+			want[i] = keys[i][:]
+		}
+		r.mempoolCh.Out <- p2p.Envelope{
+			To:      peerID,
+			Message: &protomem.WantTxs{TxKeys: want},
+		}
+		keys = keys[n:]
+	}
+}
+
+// sortByGossipPriority sorts txs by the gossip priority of their lanes, in
+// descending order, keeping the order of txs in the same lane.
+func (r *Reactor) sortByGossipPriority(wtxs []*WrappedTx) {
+	sort.SliceStable(wtxs, func(i, j int) bool {
+		return r.mempool.gossipPriority(wtxs[i]) > r.mempool.gossipPriority(wtxs[j])
+	})
+}
+
 // handleMessage handles an Envelope sent from a peer on a specific p2p Channel.
 // It will handle errors and any possible panics gracefully. A caller can handle
 // any error returned by sending a PeerError on the respective channel.
@@ -208,12 +399,23 @@ func (r *Reactor) handleMessage(chID p2p.ChannelID, envelope p2p.Envelope) (err
 }
 
 // processMempoolCh implements a blocking event loop where we listen for p2p
-// Envelope messages from the mempoolCh.
+// Envelope messages from the mempoolCh. It also periodically requests txs whose
+// requests timed out from other peers that announced them, and reports peers
+// sending bad txs.
 func (r *Reactor) processMempoolCh() {
 	defer r.mempoolCh.Close()
 
+	retryTicker := time.NewTicker(r.cfg.TxRequestTimeout / 2)
+	defer retryTicker.Stop()
+
 	for {
 		select {
+		case <-retryTicker.C:
+			for peerID, keys := range r.requests.Expire(time.Now()) {
+				r.requestTxs(peerID, keys)
+			}
+			r.reportPeerTxs()
+
 		case envelope := <-r.mempoolCh.In:
 			if err := r.handleMessage(r.mempoolCh.ID, envelope); err != nil {
 				r.Logger.Error("failed to process message", "ch_id", r.mempoolCh.ID, "envelope", envelope, "err", err)
@@ -222,6 +424,7 @@ func (r *Reactor) processMempoolCh() {
 					Err:    err,
 				}
 			}
+			r.reportPeerTxs()
 
 		case <-r.closeCh:
 			r.Logger.Debug("stopped listening on mempool channel; closing...")
@@ -251,6 +454,12 @@ func (r *Reactor) processPeerUpdate(peerUpdate p2p.PeerUpdate) {
 			return
 		}
 
+		r.statsMtx.Lock()
+		if _, ok := r.peerStats[peerUpdate.NodeID]; !ok {
+			r.peerStats[peerUpdate.NodeID] = newPeerTxTracker(r.cfg.PeerTxRate, r.cfg.PeerTxBytesRate, time.Now())
+		}
+		r.statsMtx.Unlock()
+
 		if r.cfg.Broadcast {
 			// Check if we've already started a goroutine for this peer, if not we create
 			// a new done channel so we can explicitly close the goroutine if the peer
@@ -266,12 +475,17 @@ func (r *Reactor) processPeerUpdate(peerUpdate p2p.PeerUpdate) {
 				r.ids.ReserveForPeer(peerUpdate.NodeID)
 
 				// start a broadcast routine ensuring all txs are forwarded to the peer
-				go r.broadcastTxRoutine(peerUpdate.NodeID, closer)
+				go r.broadcastTxRoutine(peerUpdate.NodeID, r.supportsHaveWant(peerUpdate.NodeID), closer)
 			}
 		}
 
 	case p2p.PeerStatusDown:
 		r.ids.Reclaim(peerUpdate.NodeID)
+		r.requests.PeerDown(peerUpdate.NodeID)
+
+		r.statsMtx.Lock()
+		delete(r.peerStats, peerUpdate.NodeID)
+		r.statsMtx.Unlock()
 
 		// Check if we've started a tx broadcasting goroutine for this peer.
 		// If we have, we signal to terminate the goroutine via the channel's closure.
@@ -284,6 +498,16 @@ func (r *Reactor) processPeerUpdate(peerUpdate p2p.PeerUpdate) {
 	}
 }
 
+// supportsHaveWant returns true if a peer's P2P protocol version supports the
+// HaveTxs and WantTxs messages. Without a peer manager, all peers are assumed
+// to support them.
+func (r *Reactor) supportsHaveWant(peerID types.NodeID) bool {
+	if r.peerMgr == nil {
+		return true
+	}
+	return r.peerMgr.GetProtocolVersion(peerID).P2P >= haveWantP2PProtocol
+}
+
 // processPeerUpdates initiates a blocking process where we listen for and handle
 // PeerUpdate messages. When the reactor is stopped, we will catch the signal and
 // close the p2p PeerUpdatesCh gracefully.
@@ -302,10 +526,52 @@ func (r *Reactor) processPeerUpdates() {
 	}
 }
 
-func (r *Reactor) broadcastTxRoutine(peerID types.NodeID, closer *tmsync.Closer) {
+// broadcastTxRoutine announces the txs in the mempool to a peer, in the order
+// they were added, batching up to MaxTxKeysPerMessage tx keys per HaveTxs
+// message for at most AnnounceInterval. Within a batch, txs in lanes with a
+// higher gossip priority are announced first, and txs in lanes with a positive
+// gossip priority are announced right away. Txs received from the peer are not
+// announced to it.
+//
+// If the peer doesn't support have/want, i.e. haveWant is false, the txs are
+// sent to it in full one by one instead, as before P2P protocol version
+// haveWantP2PProtocol.
+func (r *Reactor) broadcastTxRoutine(peerID types.NodeID, haveWant bool, closer *tmsync.Closer) {
 	peerMempoolID := r.ids.GetForPeer(peerID)
 	var nextGossipTx *clist.CElement
 
+	var (
+		announce   []*WrappedTx
+		flushTimer = time.NewTimer(0)
+		flushCh    <-chan time.Time
+	)
+	<-flushTimer.C
+	defer flushTimer.Stop()
+
+	flush := func() {
+		if len(announce) > 0 {
+			r.sortByGossipPriority(announce)
+			keys := make([][]byte, len(announce))
+			for i, wtx := range announce {
+				key := wtx.hash
+				keys[i] = key[:]
+			}
+			r.mempoolCh.Out <- p2p.Envelope{
+				To:      peerID,
+				Message: &protomem.HaveTxs{TxKeys: keys},
+			}
+			r.Logger.Debug("announced txs to peer", "num_txs", len(announce), "peer", peerID)
+			announce = nil
+		}
+		if flushCh != nil && !flushTimer.Stop() {
+			select {
+			case <-flushTimer.C:
+			default:
+			}
+		}
+		flushCh = nil
+	}
+
 	// remove the peer ID from the map of routines and mark the waitgroup as done
 	defer func() {
 		r.mtx.Lock()
@@ -339,6 +605,10 @@ func (r *Reactor) broadcastTxRoutine(peerID types.NodeID, closer *tmsync.Closer)
 					continue
 				}
 
+			case <-flushCh:
+				flush()
+				continue
+
 			case <-closer.Done():
 				// The peer is marked for removal via a PeerUpdate as the doneCh was
 				// explicitly closed to signal we should exit.
@@ -357,16 +627,18 @@ func (r *Reactor) broadcastTxRoutine(peerID types.NodeID, closer *tmsync.Closer)
 			height := r.peerMgr.GetHeight(peerID)
 			if height > 0 && height < memTx.height-1 {
 				// allow for a lag of one block
+				flush()
 				time.Sleep(PeerCatchupSleepIntervalMS * time.Millisecond)
 				continue
 			}
 		}
 
-		// NOTE: Transaction batching was disabled due to:
-		// https://github.com/tendermint/tendermint/issues/5796
-		if ok := r.mempool.txStore.TxHasPeer(memTx.hash, peerMempoolID); !ok {
+		if ok := r.mempool.txStore.TxHasPeer(memTx.hash, peerMempoolID); !ok && !haveWant {
 			// Send the mempool tx to the corresponding peer. Note, the peer may be
 			// behind and thus would not be able to process the mempool tx correctly.
+			//
+			// NOTE: Transaction batching was disabled due to:
+			// https://github.com/tendermint/tendermint/issues/5796
 			r.mempoolCh.Out <- p2p.Envelope{
 				To: peerID,
 				Message: &protomem.Txs{
@@ -378,12 +650,36 @@ func (r *Reactor) broadcastTxRoutine(peerID types.NodeID, closer *tmsync.Closer)
 				"tx", fmt.Sprintf("%X", memTx.tx.Hash()),
 				"peer", peerID,
 			)
+		} else if !ok {
+			// Announce the mempool tx to the corresponding peer, which requests
+			// it if it doesn't have it yet. Note, the peer may be behind and
+			// thus would not be able to process the mempool tx correctly.
+			announce = append(announce, memTx)
+			if len(announce) >= MaxTxKeysPerMessage || r.mempool.gossipPriority(memTx) > 0 {
+				flush()
+			} else if flushCh == nil {
+				flushTimer.Reset(r.cfg.AnnounceInterval)
+				flushCh = flushTimer.C
+			}
 		}
 
 		select {
 		case <-nextGossipTx.NextWaitChan():
 			nextGossipTx = nextGossipTx.Next()
 
+		case <-flushCh:
+			// No new txs arrived within the announce interval, so we announce
+			// the ones we have. We keep waiting for the next tx after this.
+			flush()
+			select {
+			case <-nextGossipTx.NextWaitChan():
+				nextGossipTx = nextGossipTx.Next()
+			case <-closer.Done():
+				return
+			case <-r.closeCh:
+				return
+			}
+
 		case <-closer.Done():
 			// The peer is marked for removal via a PeerUpdate as the doneCh was
 			// explicitly closed to signal we should exit.
@@ -9,6 +9,8 @@ import (
 	"time"
 
 	"github.com/stretchr/testify/require"
+	dbm "github.com/tendermint/tm-db"
+
 	"github.com/tendermint/tendermint/abci/example/kvstore"
 	abci "github.com/tendermint/tendermint/abci/types"
 	"github.com/tendermint/tendermint/config"
@@ -171,7 +173,7 @@ func TestReactorBroadcastDoesNotPanic(t *testing.T) {
 
 	closer := tmsync.NewCloser()
 	primaryReactor.peerWG.Add(1)
-	go primaryReactor.broadcastTxRoutine(secondary, closer)
+	go primaryReactor.broadcastTxRoutine(secondary, true, closer)
 
 	wg := &sync.WaitGroup{}
 	for i := 0; i < 50; i++ {
@@ -210,6 +212,35 @@ func TestReactorBroadcastTxs(t *testing.T) {
 	rts.stop(t)
 }
 
+func TestReactor_PendingTx(t *testing.T) {
+	rts := setupReactors(t, 2, 0)
+
+	primary := rts.nodes[0]
+	secondary := rts.nodes[1]
+
+	txs := checkTxs(t, rts.reactors[primary].mempool, 1, UnknownPeerID)
+	key := txs[0].tx.Key()
+
+	rts.start(t)
+	rts.waitForTxns(t, convertTex(txs), secondary)
+
+	// Txs submitted locally have no peers.
+	pending, ok := rts.reactors[primary].PendingTx(key)
+	require.True(t, ok)
+	require.Equal(t, txs[0].tx, pending.Tx)
+	require.Equal(t, txs[0].priority, pending.Priority)
+	require.Empty(t, pending.Peers)
+
+	pending, ok = rts.reactors[secondary].PendingTx(key)
+	require.True(t, ok)
+	require.Equal(t, []types.NodeID{primary}, pending.Peers)
+
+	_, ok = rts.reactors[secondary].PendingTx(types.Tx("unknown").Key())
+	require.False(t, ok)
+
+	rts.stop(t)
+}
+
 // regression test for https://github.com/tendermint/tendermint/issues/5408
 func TestReactorConcurrency(t *testing.T) {
 	numTxs := 5
@@ -327,6 +358,271 @@ func TestReactor_MaxTxBytes(t *testing.T) {
 	rts.assertMempoolChannelsDrained(t)
 }
 
+func TestReactor_HaveWantTxs(t *testing.T) {
+	cfg := config.TestMempoolConfig()
+	cfg.Broadcast = false
+	cfg.TxRequestTimeout = 500 * time.Millisecond
+
+	inCh := make(chan p2p.Envelope)
+	outCh := make(chan p2p.Envelope, 16)
+	errCh := make(chan p2p.PeerError, 16)
+	mempoolCh := p2p.NewChannel(MempoolChannel, new(protomem.Message), inCh, outCh, errCh)
+	peerUpdates := p2p.NewPeerUpdates(make(chan p2p.PeerUpdate), 1)
+
+	txmp := setup(t, 100)
+	reactor := NewReactor(log.TestingLogger(), cfg, nil, txmp, mempoolCh, peerUpdates)
+	require.NoError(t, reactor.Start())
+	t.Cleanup(func() { require.NoError(t, reactor.Stop()) })
+
+	var (
+		a   = types.NodeID("aa")
+		b   = types.NodeID("bb")
+		tx  = types.Tx("sender=key=1")
+		key = tx.Key()
+	)
+	receive := func() p2p.Envelope {
+		select {
+		case envelope := <-outCh:
+			return envelope
+		case <-time.After(5 * time.Second):
+			require.FailNow(t, "timed out waiting for message")
+			return p2p.Envelope{}
+		}
+	}
+
+	// The tx is requested from the first peer announcing it.
+	inCh <- p2p.Envelope{From: a, Message: &protomem.HaveTxs{TxKeys: [][]byte{key[:]}}}
+	require.Equal(t, p2p.Envelope{To: a, Message: &protomem.WantTxs{TxKeys: [][]byte{key[:]}}}, receive())
+
+	// Other announcers are only requested from once the request times out.
+	inCh <- p2p.Envelope{From: b, Message: &protomem.HaveTxs{TxKeys: [][]byte{key[:]}}}
+	time.Sleep(100 * time.Millisecond)
+	require.Empty(t, outCh)
+	require.Equal(t, p2p.Envelope{To: b, Message: &protomem.WantTxs{TxKeys: [][]byte{key[:]}}}, receive())
+
+	inCh <- p2p.Envelope{From: b, Message: &protomem.Txs{Txs: [][]byte{tx}}}
+	require.Eventually(t, func() bool { return txmp.Size() == 1 }, 5*time.Second, 10*time.Millisecond)
+
+	// Known txs aren't requested again, and are sent to peers that want them.
+	inCh <- p2p.Envelope{From: a, Message: &protomem.HaveTxs{TxKeys: [][]byte{key[:]}}}
+	inCh <- p2p.Envelope{From: a, Message: &protomem.WantTxs{TxKeys: [][]byte{key[:]}}}
+	require.Equal(t, p2p.Envelope{To: a, Message: &protomem.Txs{Txs: [][]byte{tx}}}, receive())
+
+	// Invalid tx keys are peer errors.
+	inCh <- p2p.Envelope{From: a, Message: &protomem.HaveTxs{TxKeys: [][]byte{{1, 2, 3}}}}
+	select {
+	case peerErr := <-errCh:
+		require.Equal(t, a, peerErr.NodeID)
+	case <-time.After(5 * time.Second):
+		require.FailNow(t, "timed out waiting for peer error")
+	}
+}
+
+func TestReactor_LegacyPeers(t *testing.T) {
+	cfg := config.TestMempoolConfig()
+	cfg.AnnounceInterval = 10 * time.Millisecond
+
+	inCh := make(chan p2p.Envelope)
+	outCh := make(chan p2p.Envelope, 16)
+	errCh := make(chan p2p.PeerError, 16)
+	mempoolCh := p2p.NewChannel(MempoolChannel, new(protomem.Message), inCh, outCh, errCh)
+	updatesCh := make(chan p2p.PeerUpdate)
+	peerUpdates := p2p.NewPeerUpdates(updatesCh, 1)
+
+	var (
+		legacy  = types.NodeID(strings.Repeat("a", 40))
+		current = types.NodeID(strings.Repeat("b", 40))
+		tx      = types.Tx("sender=key=1")
+		key     = tx.Key()
+	)
+	peerManager, err := p2p.NewPeerManager(types.NodeID(strings.Repeat("f", 40)), dbm.NewMemDB(), p2p.PeerManagerOptions{})
+	require.NoError(t, err)
+	for peerID, p2pVersion := range map[types.NodeID]uint64{legacy: 8, current: haveWantP2PProtocol} {
+		require.NoError(t, peerManager.Accepted(peerID, nil))
+		peerManager.SetProtocolVersion(peerID, types.ProtocolVersion{P2P: p2pVersion})
+	}
+
+	txmp := setup(t, 100)
+	reactor := NewReactor(log.TestingLogger(), cfg, peerManager, txmp, mempoolCh, peerUpdates)
+	require.NoError(t, reactor.Start())
+	t.Cleanup(func() { require.NoError(t, reactor.Stop()) })
+
+	updatesCh <- p2p.PeerUpdate{NodeID: legacy, Status: p2p.PeerStatusUp}
+	updatesCh <- p2p.PeerUpdate{NodeID: current, Status: p2p.PeerStatusUp}
+	require.NoError(t, txmp.CheckTx(context.Background(), tx, nil, TxInfo{SenderID: UnknownPeerID}))
+
+	// Peers with an older P2P protocol version are sent the full tx, others
+	// have it announced.
+	received := map[types.NodeID]p2p.Envelope{}
+	for len(received) < 2 {
+		select {
+		case envelope := <-outCh:
+			received[envelope.To] = envelope
+		case <-time.After(5 * time.Second):
+			require.FailNow(t, "timed out waiting for message")
+		}
+	}
+	require.Equal(t, &protomem.Txs{Txs: [][]byte{tx}}, received[legacy].Message)
+	require.Equal(t, &protomem.HaveTxs{TxKeys: [][]byte{key[:]}}, received[current].Message)
+}
+
+func TestReactor_PeerTxStats(t *testing.T) {
+	cfg := config.TestMempoolConfig()
+	cfg.Broadcast = false
+	cfg.BadTxsPerPenalty = 2
+	cfg.MaxExcessBadTxs = 4
+
+	inCh := make(chan p2p.Envelope)
+	outCh := make(chan p2p.Envelope, 16)
+	errCh := make(chan p2p.PeerError, 16)
+	mempoolCh := p2p.NewChannel(MempoolChannel, new(protomem.Message), inCh, outCh, errCh)
+	peerUpdates := p2p.NewPeerUpdates(make(chan p2p.PeerUpdate), 1)
+
+	peer := types.NodeID(strings.Repeat("a", 40))
+	peerManager, err := p2p.NewPeerManager(types.NodeID(strings.Repeat("f", 40)), dbm.NewMemDB(), p2p.PeerManagerOptions{})
+	require.NoError(t, err)
+	added, err := peerManager.Add(p2p.NodeAddress{Protocol: "memory", NodeID: peer})
+	require.NoError(t, err)
+	require.True(t, added)
+	require.NoError(t, peerManager.Accepted(peer, nil))
+	peerManager.SetProtocolVersion(peer, types.ProtocolVersion{P2P: haveWantP2PProtocol})
+	peerManager.Register(peerUpdates)
+	for i := 0; i < 3; i++ {
+		peerUpdates.SendUpdate(p2p.PeerUpdate{NodeID: peer, Status: p2p.PeerStatusGood})
+	}
+	require.Eventually(t, func() bool {
+		return peerManager.Scores()[peer] == 3
+	}, 5*time.Second, 10*time.Millisecond)
+
+	txmp := setup(t, 100)
+	reactor := NewReactor(log.TestingLogger(), cfg, peerManager, txmp, mempoolCh, peerUpdates)
+	require.NoError(t, reactor.Start())
+	t.Cleanup(func() { require.NoError(t, reactor.Stop()) })
+
+	peerManager.Ready(peer)
+	require.Eventually(t, func() bool {
+		_, ok := reactor.PeerTxStats(peer)
+		return ok
+	}, 5*time.Second, 10*time.Millisecond)
+
+	send := func(txs ...string) {
+		msg := &protomem.Txs{}
+		for _, tx := range txs {
+			msg.Txs = append(msg.Txs, []byte(tx))
+		}
+		inCh <- p2p.Envelope{From: peer, Message: msg}
+	}
+
+	// Every 2 bad txs reduce the peer score.
+	send("sender=key=1")
+	send("bad1", "bad2")
+	require.Eventually(t, func() bool {
+		return peerManager.Scores()[peer] == 2
+	}, 5*time.Second, 10*time.Millisecond)
+
+	// The peer is disconnected once it sent 4 more bad txs than accepted ones.
+	send("sender=key=1")
+	require.Empty(t, errCh)
+	send("bad3", "bad4")
+	select {
+	case peerErr := <-errCh:
+		require.Equal(t, peer, peerErr.NodeID)
+	case <-time.After(5 * time.Second):
+		require.FailNow(t, "timed out waiting for peer error")
+	}
+	require.Eventually(t, func() bool {
+		return peerManager.Scores()[peer] == 1
+	}, 5*time.Second, 10*time.Millisecond)
+
+	stats, ok := reactor.PeerTxStats(peer)
+	require.True(t, ok)
+	require.Equal(t, PeerTxStats{AcceptedTxs: 1, InvalidTxs: 4, DuplicateTxs: 1}, stats)
+}
+
+func TestReactor_PeerTxStats_Duplicates(t *testing.T) {
+	cfg := config.TestMempoolConfig()
+	cfg.Broadcast = false
+	cfg.TxRequestTimeout = 200 * time.Millisecond
+
+	inCh := make(chan p2p.Envelope)
+	outCh := make(chan p2p.Envelope, 16)
+	errCh := make(chan p2p.PeerError, 16)
+	mempoolCh := p2p.NewChannel(MempoolChannel, new(protomem.Message), inCh, outCh, errCh)
+	updatesCh := make(chan p2p.PeerUpdate)
+	peerUpdates := p2p.NewPeerUpdates(updatesCh, 1)
+
+	var (
+		a      = types.NodeID(strings.Repeat("a", 40))
+		b      = types.NodeID(strings.Repeat("b", 40))
+		c      = types.NodeID(strings.Repeat("c", 40))
+		legacy = types.NodeID(strings.Repeat("d", 40))
+		tx     = types.Tx("sender=key=1")
+		key    = tx.Key()
+	)
+	peerManager, err := p2p.NewPeerManager(types.NodeID(strings.Repeat("f", 40)), dbm.NewMemDB(), p2p.PeerManagerOptions{})
+	require.NoError(t, err)
+	for peerID, p2pVersion := range map[types.NodeID]uint64{
+		a: haveWantP2PProtocol, b: haveWantP2PProtocol, c: haveWantP2PProtocol, legacy: 8,
+	} {
+		require.NoError(t, peerManager.Accepted(peerID, nil))
+		peerManager.SetProtocolVersion(peerID, types.ProtocolVersion{P2P: p2pVersion})
+	}
+
+	txmp := setup(t, 100)
+	reactor := NewReactor(log.TestingLogger(), cfg, peerManager, txmp, mempoolCh, peerUpdates)
+	require.NoError(t, reactor.Start())
+	t.Cleanup(func() { require.NoError(t, reactor.Stop()) })
+
+	for _, peerID := range []types.NodeID{a, b, c, legacy} {
+		updatesCh <- p2p.PeerUpdate{NodeID: peerID, Status: p2p.PeerStatusUp}
+	}
+	receive := func() p2p.Envelope {
+		select {
+		case envelope := <-outCh:
+			return envelope
+		case <-time.After(5 * time.Second):
+			require.FailNow(t, "timed out waiting for message")
+			return p2p.Envelope{}
+		}
+	}
+	stats := func(peerID types.NodeID) PeerTxStats {
+		stats, ok := reactor.PeerTxStats(peerID)
+		require.True(t, ok)
+		return stats
+	}
+
+	// The tx is requested from a, then from b once the request times out.
+	inCh <- p2p.Envelope{From: a, Message: &protomem.HaveTxs{TxKeys: [][]byte{key[:]}}}
+	require.Equal(t, a, receive().To)
+	inCh <- p2p.Envelope{From: b, Message: &protomem.HaveTxs{TxKeys: [][]byte{key[:]}}}
+	require.Equal(t, b, receive().To)
+
+	// Both respond, but a's late response isn't a bad duplicate, since we
+	// requested it. Neither are the txs pushed by peers without have/want,
+	// but unsolicited duplicates from other peers are.
+	inCh <- p2p.Envelope{From: b, Message: &protomem.Txs{Txs: [][]byte{tx}}}
+	inCh <- p2p.Envelope{From: a, Message: &protomem.Txs{Txs: [][]byte{tx}}}
+	inCh <- p2p.Envelope{From: legacy, Message: &protomem.Txs{Txs: [][]byte{tx}}}
+	inCh <- p2p.Envelope{From: c, Message: &protomem.Txs{Txs: [][]byte{tx}}}
+	require.Eventually(t, func() bool {
+		return stats(c).DuplicateTxs == 1
+	}, 5*time.Second, 10*time.Millisecond)
+	require.Equal(t, PeerTxStats{AcceptedTxs: 1}, stats(b))
+	require.Zero(t, stats(a).BadTxs())
+	require.Zero(t, stats(legacy).BadTxs())
+
+	// Outcomes recorded after a peer went down, e.g. by CheckTx callbacks,
+	// don't track the peer again.
+	updatesCh <- p2p.PeerUpdate{NodeID: a, Status: p2p.PeerStatusDown}
+	require.Eventually(t, func() bool {
+		_, ok := reactor.PeerTxStats(a)
+		return !ok
+	}, 5*time.Second, 10*time.Millisecond)
+	reactor.recordTx(a, txInvalid)
+	_, ok := reactor.PeerTxStats(a)
+	require.False(t, ok)
+}
+
 func TestDontExhaustMaxActiveIDs(t *testing.T) {
 	// we're creating a single node network, but not starting the
 	// network.
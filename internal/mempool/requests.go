@@ -0,0 +1,199 @@
+package mempool
+
+import (
+	"time"
+
+	tmsync "github.com/tendermint/tendermint/internal/libs/sync"
+	"github.com/tendermint/tendermint/types"
+)
+
+const (
+	// maxTxAnnouncers is the maximum number of peers that we remember as having
+	// announced a transaction, to request it from if a request times out.
+	maxTxAnnouncers = 8
+
+	// maxPeerAnnouncedTxs is the maximum number of outstanding transactions
+	// that we track for each peer, either requested from it or remembered as
+	// announced by it. Further announcements from the peer are ignored until
+	// some of them are received or expire.
+	maxPeerAnnouncedTxs = 10 * MaxTxKeysPerMessage
+)
+
+// txRequest is an in-flight request for an announced transaction.
+type txRequest struct {
+	peerID     types.NodeID   // peer the transaction was requested from
+	deadline   time.Time      // when to request it from another announcer
+	announcers []types.NodeID // other peers that announced it, in order
+	requested  []types.NodeID // all peers it was requested from, in order
+}
+
+// receivedTx is a requested transaction that was received, remembered for a
+// request timeout so that late responses to earlier requests are recognized.
+type receivedTx struct {
+	requested []types.NodeID // peers the transaction was requested from
+	expires   time.Time
+}
+
+// TxRequests tracks the transactions that peers have announced and we have
+// requested but not yet received. Each transaction is requested from a single
+// peer at a time, and if it doesn't arrive in time, from the next peer that
+// announced it. The number of transactions tracked for each peer is limited to
+// maxPeerAnnouncedTxs.
+type TxRequests struct {
+	mtx      tmsync.Mutex
+	timeout  time.Duration
+	requests map[types.TxKey]*txRequest
+	received map[types.TxKey]*receivedTx
+	peerTxs  map[types.NodeID]int // number of requests each peer is tracked in
+}
+
+// NewTxRequests returns a new TxRequests where requests time out after the
+// given duration.
+func NewTxRequests(timeout time.Duration) *TxRequests {
+	return &TxRequests{
+		timeout:  timeout,
+		requests: make(map[types.TxKey]*txRequest),
+		received: make(map[types.TxKey]*receivedTx),
+		peerTxs:  make(map[types.NodeID]int),
+	}
+}
+
+// Announced records that a peer announced a transaction, and returns true if
+// it should be requested from the peer. If the transaction has already been
+// requested from another peer, the peer is remembered as a fallback. The
+// announcement is ignored if the peer already has maxPeerAnnouncedTxs
+// outstanding transactions.
+func (r *TxRequests) Announced(key types.TxKey, peerID types.NodeID) bool {
+	r.mtx.Lock()
+	defer r.mtx.Unlock()
+
+	if r.peerTxs[peerID] >= maxPeerAnnouncedTxs {
+		return false
+	}
+
+	req, ok := r.requests[key]
+	if !ok {
+		r.requests[key] = &txRequest{
+			peerID:    peerID,
+			deadline:  time.Now().Add(r.timeout),
+			requested: []types.NodeID{peerID},
+		}
+		r.peerTxs[peerID]++
+		return true
+	}
+
+	if req.peerID == peerID || len(req.announcers) >= maxTxAnnouncers {
+		return false
+	}
+	for _, announcer := range req.announcers {
+		if announcer == peerID {
+			return false
+		}
+	}
+	req.announcers = append(req.announcers, peerID)
+	r.peerTxs[peerID]++
+	return false
+}
+
+// Received marks a transaction as received from a peer, and returns true if
+// it was requested from that peer, either by the in-flight request or by an
+// earlier one that timed out. Requests are remembered for a request timeout
+// after the transaction is first received, since several of the peers it was
+// requested from may send it.
+func (r *TxRequests) Received(key types.TxKey, peerID types.NodeID) bool {
+	r.mtx.Lock()
+	defer r.mtx.Unlock()
+
+	if req, ok := r.requests[key]; ok {
+		r.untrack(req.peerID)
+		for _, announcer := range req.announcers {
+			r.untrack(announcer)
+		}
+		delete(r.requests, key)
+		r.received[key] = &receivedTx{
+			requested: req.requested,
+			expires:   time.Now().Add(r.timeout),
+		}
+	}
+
+	if received, ok := r.received[key]; ok {
+		for _, requested := range received.requested {
+			if requested == peerID {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// Expire moves requests that have timed out to the next peer that announced
+// the transaction, and returns the transactions to request from each peer.
+// Requests with no more announcers are dropped, and so are received
+// transactions remembered for longer than the request timeout.
+func (r *TxRequests) Expire(now time.Time) map[types.NodeID][]types.TxKey {
+	r.mtx.Lock()
+	defer r.mtx.Unlock()
+
+	for key, received := range r.received {
+		if !now.Before(received.expires) {
+			delete(r.received, key)
+		}
+	}
+
+	retries := make(map[types.NodeID][]types.TxKey)
+	for key, req := range r.requests {
+		if now.Before(req.deadline) {
+			continue
+		}
+		r.untrack(req.peerID)
+		if len(req.announcers) == 0 {
+			delete(r.requests, key)
+			continue
+		}
+
+		req.peerID, req.announcers = req.announcers[0], req.announcers[1:]
+		req.requested = append(req.requested, req.peerID)
+		req.deadline = now.Add(r.timeout)
+		retries[req.peerID] = append(retries[req.peerID], key)
+	}
+
+	return retries
+}
+
+// PeerDown forgets a disconnected peer as an announcer, and expires the
+// requests sent to it, so that they're moved to other announcers on the next
+// Expire call.
+func (r *TxRequests) PeerDown(peerID types.NodeID) {
+	r.mtx.Lock()
+	defer r.mtx.Unlock()
+
+	for _, req := range r.requests {
+		if req.peerID == peerID {
+			req.deadline = time.Time{}
+		}
+		for i, announcer := range req.announcers {
+			if announcer == peerID {
+				req.announcers = append(req.announcers[:i], req.announcers[i+1:]...)
+				r.untrack(peerID)
+				break
+			}
+		}
+	}
+}
+
+// untrack decrements the number of requests a peer is tracked in.
+func (r *TxRequests) untrack(peerID types.NodeID) {
+	if r.peerTxs[peerID] <= 1 {
+		delete(r.peerTxs, peerID)
+		return
+	}
+	r.peerTxs[peerID]--
+}
+
+// Len returns the number of in-flight requests.
+func (r *TxRequests) Len() int {
+	r.mtx.Lock()
+	defer r.mtx.Unlock()
+
+	return len(r.requests)
+}
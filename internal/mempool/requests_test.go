@@ -0,0 +1,112 @@
+package mempool
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/tendermint/tendermint/types"
+)
+
+func TestTxRequests(t *testing.T) {
+	var (
+		a   = types.NodeID("aa")
+		b   = types.NodeID("bb")
+		c   = types.NodeID("cc")
+		key = types.Tx("tx").Key()
+	)
+
+	requests := NewTxRequests(time.Minute)
+
+	// The tx is only requested from the first announcer.
+	require.True(t, requests.Announced(key, a))
+	require.False(t, requests.Announced(key, b))
+	require.False(t, requests.Announced(key, a))
+	require.False(t, requests.Announced(key, c))
+	require.Equal(t, 1, requests.Len())
+
+	// Timed out requests move to the next announcer.
+	require.Empty(t, requests.Expire(time.Now()))
+	require.Equal(t, map[types.NodeID][]types.TxKey{b: {key}},
+		requests.Expire(time.Now().Add(time.Minute)))
+
+	// Requests to disconnected peers expire right away, and disconnected
+	// peers aren't requested from.
+	requests.PeerDown(b)
+	require.Equal(t, map[types.NodeID][]types.TxKey{c: {key}}, requests.Expire(time.Now()))
+	requests.PeerDown(c)
+	require.Empty(t, requests.Expire(time.Now()))
+	require.Zero(t, requests.Len())
+
+	// Received txs are no longer tracked.
+	require.True(t, requests.Announced(key, a))
+	require.False(t, requests.Announced(key, b))
+	require.True(t, requests.Received(key, a))
+	require.Zero(t, requests.Len())
+	require.True(t, requests.Announced(key, b))
+}
+
+func TestTxRequests_Received(t *testing.T) {
+	var (
+		a   = types.NodeID("aa")
+		b   = types.NodeID("bb")
+		c   = types.NodeID("cc")
+		key = types.Tx("tx").Key()
+	)
+
+	requests := NewTxRequests(time.Minute)
+	require.False(t, requests.Received(key, a))
+
+	// Txs are requested from the next announcer when the request times out,
+	// and the responses of both peers are recognized as requested, but not
+	// those of other peers.
+	require.True(t, requests.Announced(key, a))
+	require.False(t, requests.Announced(key, b))
+	require.Equal(t, map[types.NodeID][]types.TxKey{b: {key}},
+		requests.Expire(time.Now().Add(time.Minute)))
+
+	require.True(t, requests.Received(key, b))
+	require.True(t, requests.Received(key, a))
+	require.False(t, requests.Received(key, c))
+	require.Zero(t, requests.Len())
+
+	// The requests are forgotten after the request timeout.
+	require.Empty(t, requests.Expire(time.Now().Add(time.Minute)))
+	require.False(t, requests.Received(key, a))
+}
+
+func TestTxRequests_PeerLimit(t *testing.T) {
+	var (
+		a = types.NodeID("aa")
+		b = types.NodeID("bb")
+	)
+	keys := make([]types.TxKey, maxPeerAnnouncedTxs+1)
+	for i := range keys {
+		keys[i] = types.Tx(fmt.Sprintf("tx%d", i)).Key()
+	}
+
+	requests := NewTxRequests(time.Minute)
+
+	// Announcements beyond the peer's limit are ignored, both as the
+	// requested peer and as a fallback announcer.
+	for _, key := range keys[:maxPeerAnnouncedTxs] {
+		require.True(t, requests.Announced(key, a))
+	}
+	require.False(t, requests.Announced(keys[maxPeerAnnouncedTxs], a))
+	require.Equal(t, maxPeerAnnouncedTxs, requests.Len())
+
+	require.True(t, requests.Announced(keys[maxPeerAnnouncedTxs], b))
+	require.False(t, requests.Announced(keys[maxPeerAnnouncedTxs], a))
+	require.True(t, requests.Received(keys[maxPeerAnnouncedTxs], b))
+
+	// Received and expired txs make room for new announcements.
+	require.True(t, requests.Received(keys[0], a))
+	require.True(t, requests.Announced(keys[maxPeerAnnouncedTxs], a))
+	require.False(t, requests.Announced(keys[0], a))
+
+	require.Empty(t, requests.Expire(time.Now().Add(time.Minute)))
+	require.Zero(t, requests.Len())
+	require.True(t, requests.Announced(keys[0], a))
+}
@@ -21,6 +21,17 @@ type TxInfo struct {
 	SenderNodeID types.NodeID
 }
 
+// PendingTx describes a transaction pending in the mempool.
+type PendingTx struct {
+	Tx        types.Tx
+	Priority  int64
+	Sender    string
+	Lane      string
+	Height    int64          // height at which the transaction was first checked
+	Timestamp time.Time      // time at which the transaction was first received
+	Peers     []types.NodeID // connected peers that sent the transaction
+}
+
 // WrappedTx defines a wrapper around a raw transaction with additional metadata
 // that is used for indexing.
 type WrappedTx struct {
@@ -44,6 +55,10 @@ type WrappedTx struct {
 	// the ResponseCheckTx response.
 	sender string
 
+	// lane defines the mempool lane the application assigned the transaction to
+	// in the ResponseCheckTx response, or the default lane.
+	lane string
+
 	// timestamp is the time at which the node first received the transaction from
 	// a peer. It is used as a second dimension is prioritizing transactions when
 	// two transactions have the same priority.
@@ -185,6 +200,24 @@ func (txs *TxStore) TxHasPeer(hash types.TxKey, peerID uint16) bool {
 	return ok
 }
 
+// TxPeers returns the IDs of the peers that sent a transaction by hash, or nil
+// if the transaction does not exist.
+func (txs *TxStore) TxPeers(hash types.TxKey) []uint16 {
+	txs.mtx.RLock()
+	defer txs.mtx.RUnlock()
+
+	wtx := txs.hashTxs[hash]
+	if wtx == nil {
+		return nil
+	}
+
+	peerIDs := make([]uint16, 0, len(wtx.peers))
+	for peerID := range wtx.peers {
+		peerIDs = append(peerIDs, peerID)
+	}
+	return peerIDs
+}
+
 // GetOrSetPeerByTxHash looks up a WrappedTx by transaction hash and adds the
 // given peerID to the WrappedTx's set of peers that sent us this transaction.
 // We return true if we've already recorded the given peer for this transaction
@@ -16,6 +16,10 @@ const (
 	// PeerCatchupSleepIntervalMS defines how much time to sleep if a peer is behind
 	PeerCatchupSleepIntervalMS = 100
 
+	// MaxTxKeysPerMessage is the maximum number of transaction keys in a
+	// HaveTxs or WantTxs message.
+	MaxTxKeysPerMessage = 512
+
 	// UnknownPeerID is the peer ID to use when running CheckTx when there is
 	// no peer (e.g. RPC)
 	UnknownPeerID uint16 = 0
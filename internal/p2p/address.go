@@ -101,6 +101,9 @@ func (a NodeAddress) Resolve(ctx context.Context) ([]Endpoint, error) {
 	if a.Protocol == "" {
 		return nil, errors.New("address has no protocol")
 	}
+	if a.Protocol == DNSSeedProtocol {
+		return nil, errors.New("DNS seeds must be resolved with ResolveDNSSeed")
+	}
 
 	// If there is no hostname, this is an opaque URL in the form
 	// "scheme:opaque", and the opaque part is assumed to be node ID used as
@@ -164,6 +167,17 @@ func (a NodeAddress) Validate() error {
 	if a.Protocol == "" {
 		return errors.New("no protocol")
 	}
+	if a.Protocol == DNSSeedProtocol {
+		switch {
+		case a.Hostname == "":
+			return errors.New("DNS seed has no hostname")
+		case a.NodeID != "":
+			return errors.New("DNS seed can't have a peer ID")
+		case a.Port > 0 || a.Path != "":
+			return errors.New("DNS seed can't have a port or path")
+		}
+		return nil
+	}
 	if a.NodeID == "" {
 		return errors.New("no peer ID")
 	} else if err := a.NodeID.Validate(); err != nil {
@@ -173,6 +173,11 @@ func TestParseNodeAddress(t *testing.T) {
 			p2p.NodeAddress{Protocol: "mconn", NodeID: id, Hostname: "fd80:b10c::2", Port: 26657},
 			true,
 		},
+		{
+			"dnsseed://Seeds.Example.org",
+			p2p.NodeAddress{Protocol: "dnsseed", Hostname: "seeds.example.org"},
+			true,
+		},
 
 		// Invalid addresses.
 		{"", p2p.NodeAddress{}, false},
@@ -186,6 +191,10 @@ func TestParseNodeAddress(t *testing.T) {
 		{"mconn://foo@127.0.0.1", p2p.NodeAddress{}, false},
 		{"mconn://" + user + "@127.0.0.1:65536", p2p.NodeAddress{}, false},
 		{"mconn://" + user + "@:80", p2p.NodeAddress{}, false},
+		{"dnsseed://" + user + "@seeds.example.org", p2p.NodeAddress{}, false},
+		{"dnsseed://seeds.example.org:53", p2p.NodeAddress{}, false},
+		{"dnsseed://seeds.example.org/path", p2p.NodeAddress{}, false},
+		{"dnsseed:" + user, p2p.NodeAddress{}, false},
 	}
 	for _, tc := range testcases {
 		tc := tc
@@ -0,0 +1,160 @@
+package p2p
+
+import (
+	"math"
+	"time"
+)
+
+// maxBandwidthWait is the longest a bandwidthLimiter asks to wait before
+// trying again.
+const maxBandwidthWait = 100 * time.Millisecond
+
+// tokenBucket is a token bucket filled at rate bytes per second, holding up to
+// size bytes. The bucket may go into debt so that messages larger than the
+// bucket can pass, the debt is then paid off before anything else can use the
+// bucket.
+type tokenBucket struct {
+	rate   float64
+	size   float64
+	tokens float64
+	last   time.Time
+}
+
+func newTokenBucket(rate, size float64, now time.Time) *tokenBucket {
+	return &tokenBucket{rate: rate, size: size, tokens: size, last: now}
+}
+
+// refill adds the tokens accumulated since the last refill, and returns the
+// tokens that did not fit in the bucket.
+func (b *tokenBucket) refill(now time.Time) float64 {
+	if now.After(b.last) {
+		b.tokens += b.rate * now.Sub(b.last).Seconds()
+		b.last = now
+	}
+	if b.tokens > b.size {
+		overflow := b.tokens - b.size
+		b.tokens = b.size
+		return overflow
+	}
+	return 0
+}
+
+// wait returns how long it takes for the bucket to get out of debt on its own.
+func (b *tokenBucket) wait() time.Duration {
+	if b.tokens > 0 {
+		return 0
+	}
+	if b.rate <= 0 {
+		return time.Duration(math.MaxInt64)
+	}
+	return time.Duration((-b.tokens/b.rate)*float64(time.Second)) + time.Millisecond
+}
+
+// bandwidthLimiter limits the bandwidth of a peer connection in one direction.
+// It is not goroutine-safe.
+//
+// The peer limit is split between a bucket reserved for each channel with a
+// minimum bandwidth share and a bucket shared by all channels. Channels with a
+// reserved bucket draw from it before the shared bucket, and tokens overflowing
+// from a full reserved bucket go to the shared bucket, so reserved bandwidth
+// that is not used is available to the other channels. Channels may also have
+// their own limit, which applies in addition to the peer limit.
+type bandwidthLimiter struct {
+	shared   *tokenBucket               // nil if the peer is unlimited
+	reserved map[ChannelID]*tokenBucket // reserved peer bandwidth per channel
+	channels map[ChannelID]*tokenBucket // channel limits
+}
+
+// newBandwidthLimiter creates a new bandwidth limiter for a peer limit of rate
+// bytes per second, the given minimum channel shares and the channel limits.
+// Rates of 0 are unlimited. It returns nil if nothing is limited.
+func newBandwidthLimiter(
+	rate int64,
+	shares map[ChannelID]float64,
+	channelRates map[ChannelID]int64,
+	now time.Time,
+) *bandwidthLimiter {
+	l := &bandwidthLimiter{
+		reserved: map[ChannelID]*tokenBucket{},
+		channels: map[ChannelID]*tokenBucket{},
+	}
+
+	if rate > 0 {
+		sharedRate := float64(rate)
+		for chID, share := range shares {
+			if share <= 0 {
+				continue
+			}
+			l.reserved[chID] = newTokenBucket(float64(rate)*share, float64(rate)*share, now)
+			sharedRate -= float64(rate) * share
+		}
+		// The shared bucket can hold a second worth of the full peer
+		// limit, since it also collects the unused reserved bandwidth.
+		l.shared = newTokenBucket(math.Max(sharedRate, 0), float64(rate), now)
+		l.shared.tokens = math.Max(sharedRate, 0)
+	}
+
+	for chID, channelRate := range channelRates {
+		if channelRate > 0 {
+			l.channels[chID] = newTokenBucket(float64(channelRate), float64(channelRate), now)
+		}
+	}
+
+	if l.shared == nil && len(l.channels) == 0 {
+		return nil
+	}
+	return l
+}
+
+// reserve takes n bytes worth of tokens for a message on the given channel. It
+// returns 0 if the message can be sent or received now, otherwise it takes
+// nothing and returns how long to wait before trying again.
+func (l *bandwidthLimiter) reserve(chID ChannelID, n int, now time.Time) time.Duration {
+	channel := l.channels[chID]
+	if channel != nil {
+		channel.refill(now)
+		if wait := channel.wait(); wait > 0 {
+			return wait
+		}
+	}
+
+	reserved := l.reserved[chID]
+	if l.shared != nil {
+		for _, bucket := range l.reserved {
+			l.shared.tokens += bucket.refill(now)
+		}
+		l.shared.refill(now)
+
+		switch {
+		case reserved != nil && reserved.tokens > 0:
+			reserved.tokens -= float64(n)
+			// The part of the message the reserved bucket can't cover is
+			// taken from the shared bucket, if it has anything left.
+			if reserved.tokens < 0 && l.shared.tokens > 0 {
+				covered := math.Min(-reserved.tokens, l.shared.tokens)
+				reserved.tokens += covered
+				l.shared.tokens -= covered
+			}
+
+		case l.shared.tokens > 0:
+			l.shared.tokens -= float64(n)
+
+		default:
+			// Unused reserved bandwidth may refill the shared bucket sooner
+			// than its own rate does, so don't wait too long to try again.
+			wait := maxBandwidthWait
+			if w := l.shared.wait(); w < wait {
+				wait = w
+			}
+			if reserved != nil && reserved.wait() < wait {
+				wait = reserved.wait()
+			}
+			return wait
+		}
+	}
+
+	if channel != nil {
+		channel.tokens -= float64(n)
+	}
+	return 0
+}
@@ -0,0 +1,82 @@
+package p2p
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestBandwidthLimiter_Unlimited(t *testing.T) {
+	require.Nil(t, newBandwidthLimiter(0, map[ChannelID]float64{1: 0.5}, map[ChannelID]int64{1: 0}, time.Now()))
+	require.NotNil(t, newBandwidthLimiter(0, nil, map[ChannelID]int64{1: 100}, time.Now()))
+	require.NotNil(t, newBandwidthLimiter(100, nil, nil, time.Now()))
+}
+
+func TestBandwidthLimiter_PeerLimit(t *testing.T) {
+	now := time.Now()
+	limiter := newBandwidthLimiter(1000, nil, nil, now)
+
+	// The first second worth of bandwidth is available right away, and a
+	// message may exceed it.
+	require.Zero(t, limiter.reserve(1, 600, now))
+	require.Zero(t, limiter.reserve(2, 600, now))
+
+	// The debt must be paid off before anything else passes, which takes
+	// 200ms but the limiter asks to try again sooner.
+	require.Equal(t, maxBandwidthWait, limiter.reserve(1, 1, now))
+	require.NotZero(t, limiter.reserve(1, 1, now.Add(150*time.Millisecond)))
+	require.Zero(t, limiter.reserve(1, 1, now.Add(250*time.Millisecond)))
+
+	// Tokens don't accumulate beyond a second worth of bandwidth.
+	now = now.Add(time.Hour)
+	require.Zero(t, limiter.reserve(1, 1000, now))
+	require.NotZero(t, limiter.reserve(1, 1, now))
+}
+
+func TestBandwidthLimiter_MinShare(t *testing.T) {
+	now := time.Now()
+	limiter := newBandwidthLimiter(1000, map[ChannelID]float64{1: 0.4}, nil, now)
+
+	// Channel 2 can only use the unreserved 600 bytes.
+	require.Zero(t, limiter.reserve(2, 600, now))
+	require.NotZero(t, limiter.reserve(2, 1, now))
+
+	// Channel 1 still has its 400 bytes, and then falls back to the shared
+	// bandwidth.
+	require.Zero(t, limiter.reserve(1, 400, now))
+	require.NotZero(t, limiter.reserve(1, 1, now))
+
+	// When channel 1 doesn't use its share, it overflows to the other
+	// channels: after a second, channel 2 can use 600 bytes of shared
+	// bandwidth and the 400 bytes channel 1 didn't use.
+	now = now.Add(time.Second)
+	require.Zero(t, limiter.reserve(1, 0, now))
+	now = now.Add(time.Second)
+	require.Zero(t, limiter.reserve(2, 999, now))
+	require.Zero(t, limiter.reserve(2, 1, now))
+	require.NotZero(t, limiter.reserve(2, 1, now))
+
+	// Channel 1 always gets its share, even when channel 2 uses everything
+	// else.
+	now = now.Add(time.Second)
+	require.Zero(t, limiter.reserve(2, 1000, now))
+	require.NotZero(t, limiter.reserve(2, 1, now))
+	require.Zero(t, limiter.reserve(1, 400, now))
+}
+
+func TestBandwidthLimiter_ChannelLimit(t *testing.T) {
+	now := time.Now()
+	limiter := newBandwidthLimiter(1000, nil, map[ChannelID]int64{1: 100}, now)
+
+	require.Zero(t, limiter.reserve(1, 200, now))
+	wait := limiter.reserve(1, 1, now)
+	require.Greater(t, wait, time.Second)
+
+	// Other channels are only bound by the peer limit.
+	require.Zero(t, limiter.reserve(2, 800, now))
+	require.NotZero(t, limiter.reserve(2, 1, now))
+
+	now = now.Add(wait)
+	require.Zero(t, limiter.reserve(1, 1, now))
+}
@@ -619,6 +619,18 @@ type ChannelDescriptor struct {
 	// RecvBufferCapacity defines the max buffer size of inbound messages for a
 	// given p2p Channel queue.
 	RecvBufferCapacity int
+
+	// MinBandwidthShare is the fraction of a peer's bandwidth limit in the
+	// router reserved for the channel, between 0 and 1. Reserved bandwidth
+	// the channel does not use is available to the other channels. It has no
+	// effect when the router does not limit the bandwidth of peers.
+	MinBandwidthShare float64
+
+	// Lossless channels never have messages dropped by the router when they
+	// exceed the bandwidth limits. Instead, the router stops taking messages
+	// for the peer until the channel's backlog is sent. It has no effect when
+	// the router does not limit the bandwidth of peers.
+	Lossless bool
 }
 
 func (chDesc ChannelDescriptor) FillDefaults() (filled ChannelDescriptor) {
@@ -0,0 +1,135 @@
+package conn
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/tendermint/tendermint/crypto"
+	"github.com/tendermint/tendermint/crypto/ed25519"
+	"github.com/tendermint/tendermint/crypto/encoding"
+	"github.com/tendermint/tendermint/crypto/sr25519"
+	tmp2p "github.com/tendermint/tendermint/proto/tendermint/p2p"
+)
+
+// evilNoiseResponder answers a Noise_XX handshake as the responder, misbehaving
+// as configured.
+type evilNoiseResponder struct {
+	privKey crypto.PrivKey
+
+	shareEphKey      bool // respond to the initiator at all
+	shortEphKey      bool // send a truncated ephemeral key
+	lowOrderEphKey   bool // send a low order ephemeral key
+	badStaticKey     bool // corrupt the encrypted static key
+	badAuthSignature bool // sign something else than the static key
+}
+
+func (e *evilNoiseResponder) run(conn kvstoreConn) {
+	defer conn.Close()
+
+	hs, err := newNoiseHandshake(e.privKey, false, nil)
+	if err != nil {
+		panic(err)
+	}
+	msg, err := readNoiseMessage(conn)
+	if err != nil || !e.shareEphKey {
+		return
+	}
+	if err := hs.readMessageE(msg); err != nil {
+		panic(err)
+	}
+
+	// <- e, ee, s, es
+	switch {
+	case e.shortEphKey:
+		_ = writeNoiseMessage(conn, hs.ePub[:16])
+		return
+	case e.lowOrderEphKey:
+		_ = writeNoiseMessage(conn, make([]byte, noiseDHSize+noiseDHSize+2*aeadSizeOverhead))
+		return
+	}
+
+	msg = append([]byte(nil), hs.ePub[:]...)
+	hs.mixHash(hs.ePub[:])
+	if err := hs.dh(&hs.e, hs.re); err != nil {
+		panic(err)
+	}
+	if msg, err = hs.encryptAndHash(msg, hs.sPub[:]); err != nil {
+		panic(err)
+	}
+	if e.badStaticKey {
+		msg[noiseDHSize] ^= 1
+	}
+	if err := hs.dh(&hs.s, hs.re); err != nil {
+		panic(err)
+	}
+
+	signed := hs.sPub[:]
+	if e.badAuthSignature {
+		signed = []byte("drop users;")
+	}
+	sig, err := e.privKey.Sign(append([]byte(noiseStaticKeyLabel), signed...))
+	if err != nil {
+		panic(err)
+	}
+	pbpk, err := encoding.PubKeyToProto(e.privKey.PubKey())
+	if err != nil {
+		panic(err)
+	}
+	payload, err := (&tmp2p.AuthSigMessage{PubKey: pbpk, Sig: sig}).Marshal()
+	if err != nil {
+		panic(err)
+	}
+	if msg, err = hs.encryptAndHash(msg, payload); err != nil {
+		panic(err)
+	}
+	if err := writeNoiseMessage(conn, msg); err != nil {
+		return
+	}
+
+	// -> s, se
+	_, _ = readNoiseMessage(conn)
+}
+
+// TestMakeNoiseConnection runs the handshake against an evil responder and
+// tests that MakeNoiseConnection errors at different stages.
+func TestMakeNoiseConnection(t *testing.T) {
+	testCases := []struct {
+		name   string
+		evil   *evilNoiseResponder
+		errMsg string
+	}{
+		{"refuse to share ephemeral key", &evilNoiseResponder{}, "EOF"},
+		{"share short ephemeral key", &evilNoiseResponder{shareEphKey: true, shortEphKey: true}, "too short"},
+		{"share low order ephemeral key", &evilNoiseResponder{shareEphKey: true, lowOrderEphKey: true},
+			ErrSmallOrderRemotePubKey.Error()},
+		{"share bad static key", &evilNoiseResponder{shareEphKey: true, badStaticKey: true},
+			"failed to decrypt noise handshake"},
+		{"share bad auth signature", &evilNoiseResponder{shareEphKey: true, badAuthSignature: true},
+			"static key signature verification failed"},
+		{"share non-ed25519 key", &evilNoiseResponder{shareEphKey: true, privKey: sr25519.GenPrivKey()},
+			"expected ed25519 pubkey"},
+		{"all good", &evilNoiseResponder{shareEphKey: true}, ""},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			fooConn, barConn := makeKVStoreConnPair()
+			if tc.evil.privKey == nil {
+				tc.evil.privKey = ed25519.GenPrivKey()
+			}
+			go tc.evil.run(barConn)
+
+			_, err := MakeNoiseConnection(fooConn, ed25519.GenPrivKey(), true, nil)
+			_ = fooConn.Close()
+			if tc.errMsg != "" {
+				if assert.Error(t, err) {
+					assert.Contains(t, err.Error(), tc.errMsg)
+				}
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
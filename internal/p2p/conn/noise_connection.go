@@ -0,0 +1,567 @@
+package conn
+
+import (
+	"crypto/cipher"
+	"crypto/hmac"
+	crand "crypto/rand"
+	"crypto/sha256"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"net"
+	"time"
+
+	"golang.org/x/crypto/chacha20poly1305"
+	"golang.org/x/crypto/curve25519"
+
+	"github.com/tendermint/tendermint/crypto"
+	"github.com/tendermint/tendermint/crypto/ed25519"
+	"github.com/tendermint/tendermint/crypto/encoding"
+	tmsync "github.com/tendermint/tendermint/internal/libs/sync"
+	tmp2p "github.com/tendermint/tendermint/proto/tendermint/p2p"
+)
+
+const (
+	// noiseProtocolName is exactly 32 bytes, the length of a SHA-256 hash, so
+	// it is used as the initial handshake hash as is.
+	noiseProtocolName = "Noise_XX_25519_ChaChaPoly_SHA256"
+
+	noiseDHSize         = 32
+	noiseMaxMessageSize = math.MaxUint16
+	noiseMaxPayloadSize = noiseMaxMessageSize - aeadSizeOverhead
+
+	// noiseStaticKeyLabel prefixes the Noise static key signed by the node
+	// key, to bind the node key to the session.
+	noiseStaticKeyLabel = "TENDERMINT_NOISE_STATIC_KEY:"
+)
+
+// NoiseConnection implements net.Conn. It is a secure channel established
+// with the Noise_XX_25519_ChaChaPoly_SHA256 handshake, see
+// https://noiseprotocol.org/noise.html.
+//
+// The Noise static keys are generated for each connection. The handshake
+// payloads carry the ed25519 node key and its signature of the static key,
+// which binds the node key to the session.
+//
+// Consumers of the NoiseConnection are responsible for authenticating the
+// remote peer's pubkey against known information, like a nodeID.
+type NoiseConnection struct {
+	conn      io.ReadWriteCloser
+	remPubKey crypto.PubKey
+
+	// As with SecretConnection, reads and writes have independent states
+	// protected by their own mutexes.
+	recvMtx    tmsync.Mutex
+	recvCipher *noiseCipherState
+	recvBuffer []byte
+
+	sendMtx    tmsync.Mutex
+	sendCipher *noiseCipherState
+}
+
+// MakeNoiseConnection performs a Noise_XX handshake and returns a new
+// authenticated NoiseConnection. Exactly one side of the connection must be
+// the initiator. The prologue must be the same on both sides, otherwise the
+// handshake fails; it binds data exchanged before the handshake to the
+// session. Caller should call conn.Close() on error.
+func MakeNoiseConnection(
+	conn io.ReadWriteCloser,
+	locPrivKey crypto.PrivKey,
+	initiator bool,
+	prologue []byte,
+) (*NoiseConnection, error) {
+	hs, err := newNoiseHandshake(locPrivKey, initiator, prologue)
+	if err != nil {
+		return nil, err
+	}
+
+	var payload []byte
+	if initiator {
+		// -> e
+		if err := writeNoiseMessage(conn, hs.writeMessageE()); err != nil {
+			return nil, err
+		}
+		// <- e, ee, s, es
+		msg, err := readNoiseMessage(conn)
+		if err != nil {
+			return nil, err
+		}
+		if payload, err = hs.readMessageEES(msg); err != nil {
+			return nil, err
+		}
+		// -> s, se
+		msg, err = hs.writeMessageS()
+		if err != nil {
+			return nil, err
+		}
+		if err := writeNoiseMessage(conn, msg); err != nil {
+			return nil, err
+		}
+	} else {
+		// -> e
+		msg, err := readNoiseMessage(conn)
+		if err != nil {
+			return nil, err
+		}
+		if err := hs.readMessageE(msg); err != nil {
+			return nil, err
+		}
+		// <- e, ee, s, es
+		if msg, err = hs.writeMessageEES(); err != nil {
+			return nil, err
+		}
+		if err := writeNoiseMessage(conn, msg); err != nil {
+			return nil, err
+		}
+		// -> s, se
+		if msg, err = readNoiseMessage(conn); err != nil {
+			return nil, err
+		}
+		if payload, err = hs.readMessageS(msg); err != nil {
+			return nil, err
+		}
+	}
+
+	remPubKey, err := verifyNoisePayload(payload, hs.rs)
+	if err != nil {
+		return nil, err
+	}
+
+	c1, c2, err := hs.split()
+	if err != nil {
+		return nil, err
+	}
+	nc := &NoiseConnection{conn: conn, remPubKey: remPubKey}
+	if initiator {
+		nc.sendCipher, nc.recvCipher = c1, c2
+	} else {
+		nc.sendCipher, nc.recvCipher = c2, c1
+	}
+	return nc, nil
+}
+
+// RemotePubKey returns authenticated remote pubkey
+func (nc *NoiseConnection) RemotePubKey() crypto.PubKey {
+	return nc.remPubKey
+}
+
+// Write encrypts data into Noise transport messages.
+// CONTRACT: data smaller than noiseMaxPayloadSize is written atomically.
+func (nc *NoiseConnection) Write(data []byte) (n int, err error) {
+	nc.sendMtx.Lock()
+	defer nc.sendMtx.Unlock()
+
+	for len(data) > 0 {
+		chunk := data
+		if len(chunk) > noiseMaxPayloadSize {
+			chunk = chunk[:noiseMaxPayloadSize]
+		}
+		msg, err := nc.sendCipher.encrypt(nil, chunk)
+		if err != nil {
+			return n, err
+		}
+		if err := writeNoiseMessage(nc.conn, msg); err != nil {
+			return n, err
+		}
+		n += len(chunk)
+		data = data[len(chunk):]
+	}
+	return n, nil
+}
+
+// Read decrypts Noise transport messages into data.
+// CONTRACT: data smaller than noiseMaxPayloadSize is read atomically.
+func (nc *NoiseConnection) Read(data []byte) (n int, err error) {
+	nc.recvMtx.Lock()
+	defer nc.recvMtx.Unlock()
+
+	if len(nc.recvBuffer) > 0 {
+		n = copy(data, nc.recvBuffer)
+		nc.recvBuffer = nc.recvBuffer[n:]
+		return n, nil
+	}
+
+	msg, err := readNoiseMessage(nc.conn)
+	if err != nil {
+		return 0, err
+	}
+	chunk, err := nc.recvCipher.decrypt(msg[:0], msg)
+	if err != nil {
+		return 0, fmt.Errorf("failed to decrypt NoiseConnection: %w", err)
+	}
+	n = copy(data, chunk)
+	if n < len(chunk) {
+		nc.recvBuffer = chunk[n:]
+	}
+	return n, nil
+}
+
+// Implements net.Conn
+func (nc *NoiseConnection) Close() error                  { return nc.conn.Close() }
+func (nc *NoiseConnection) LocalAddr() net.Addr           { return nc.conn.(net.Conn).LocalAddr() }
+func (nc *NoiseConnection) RemoteAddr() net.Addr          { return nc.conn.(net.Conn).RemoteAddr() }
+func (nc *NoiseConnection) SetDeadline(t time.Time) error { return nc.conn.(net.Conn).SetDeadline(t) }
+func (nc *NoiseConnection) SetReadDeadline(t time.Time) error {
+	return nc.conn.(net.Conn).SetReadDeadline(t)
+}
+func (nc *NoiseConnection) SetWriteDeadline(t time.Time) error {
+	return nc.conn.(net.Conn).SetWriteDeadline(t)
+}
+
+// writeNoiseMessage writes a Noise message prefixed by its 2-byte big-endian
+// length.
+func writeNoiseMessage(w io.Writer, msg []byte) error {
+	if len(msg) > noiseMaxMessageSize {
+		return fmt.Errorf("noise message of %d bytes exceeds maximum size", len(msg))
+	}
+	frame := make([]byte, 2+len(msg))
+	binary.BigEndian.PutUint16(frame, uint16(len(msg)))
+	copy(frame[2:], msg)
+	_, err := w.Write(frame)
+	return err
+}
+
+// readNoiseMessage reads a Noise message prefixed by its 2-byte big-endian
+// length.
+func readNoiseMessage(r io.Reader) ([]byte, error) {
+	var length [2]byte
+	if _, err := io.ReadFull(r, length[:]); err != nil {
+		return nil, err
+	}
+	msg := make([]byte, binary.BigEndian.Uint16(length[:]))
+	if _, err := io.ReadFull(r, msg); err != nil {
+		return nil, err
+	}
+	return msg, nil
+}
+
+// signNoiseStaticKey signs the Noise static key with the node key, and returns
+// the handshake payload carrying the node key and the signature.
+func signNoiseStaticKey(privKey crypto.PrivKey, staticKey []byte) ([]byte, error) {
+	sig, err := privKey.Sign(append([]byte(noiseStaticKeyLabel), staticKey...))
+	if err != nil {
+		return nil, err
+	}
+	pbpk, err := encoding.PubKeyToProto(privKey.PubKey())
+	if err != nil {
+		return nil, err
+	}
+	msg := tmp2p.AuthSigMessage{PubKey: pbpk, Sig: sig}
+	return msg.Marshal()
+}
+
+// verifyNoisePayload verifies that the handshake payload of the peer carries
+// an ed25519 node key that signed the peer's Noise static key, and returns
+// the node key.
+func verifyNoisePayload(payload, staticKey []byte) (crypto.PubKey, error) {
+	var msg tmp2p.AuthSigMessage
+	if err := msg.Unmarshal(payload); err != nil {
+		return nil, fmt.Errorf("invalid handshake payload: %w", err)
+	}
+	pubKey, err := encoding.PubKeyFromProto(msg.PubKey)
+	if err != nil {
+		return nil, err
+	}
+	if _, ok := pubKey.(ed25519.PubKey); !ok {
+		return nil, fmt.Errorf("expected ed25519 pubkey, got %T", pubKey)
+	}
+	if !pubKey.VerifySignature(append([]byte(noiseStaticKeyLabel), staticKey...), msg.Sig) {
+		return nil, errors.New("static key signature verification failed")
+	}
+	return pubKey, nil
+}
+
+//--------------------------------------------------------------------------------
+
+// noiseCipherState is the CipherState of the Noise specification.
+type noiseCipherState struct {
+	aead  cipher.AEAD // nil until a key is set
+	nonce uint64
+}
+
+func newNoiseCipherState(key []byte) (*noiseCipherState, error) {
+	aead, err := chacha20poly1305.New(key)
+	if err != nil {
+		return nil, err
+	}
+	return &noiseCipherState{aead: aead}, nil
+}
+
+func (cs *noiseCipherState) nextNonce() ([]byte, error) {
+	// Terminates the session rather than reusing a nonce, the last nonce is
+	// reserved by the specification.
+	if cs.nonce == math.MaxUint64 {
+		return nil, errors.New("can't increase nonce without overflow")
+	}
+	var nonce [aeadNonceSize]byte
+	binary.LittleEndian.PutUint64(nonce[4:], cs.nonce)
+	cs.nonce++
+	return nonce[:], nil
+}
+
+func (cs *noiseCipherState) encryptWithAd(dst, ad, plaintext []byte) ([]byte, error) {
+	if cs.aead == nil {
+		return append(dst, plaintext...), nil
+	}
+	nonce, err := cs.nextNonce()
+	if err != nil {
+		return nil, err
+	}
+	return cs.aead.Seal(dst, nonce, plaintext, ad), nil
+}
+
+func (cs *noiseCipherState) decryptWithAd(dst, ad, ciphertext []byte) ([]byte, error) {
+	if cs.aead == nil {
+		return append(dst, ciphertext...), nil
+	}
+	nonce, err := cs.nextNonce()
+	if err != nil {
+		return nil, err
+	}
+	return cs.aead.Open(dst, nonce, ciphertext, ad)
+}
+
+func (cs *noiseCipherState) encrypt(dst, plaintext []byte) ([]byte, error) {
+	return cs.encryptWithAd(dst, nil, plaintext)
+}
+
+func (cs *noiseCipherState) decrypt(dst, ciphertext []byte) ([]byte, error) {
+	return cs.decryptWithAd(dst, nil, ciphertext)
+}
+
+// noiseHandshake is the HandshakeState of the Noise specification, along with
+// its SymmetricState, for the XX pattern:
+//
+//   -> e
+//   <- e, ee, s, es
+//   -> s, se
+type noiseHandshake struct {
+	privKey   crypto.PrivKey
+	initiator bool
+
+	ck     [sha256.Size]byte
+	h      [sha256.Size]byte
+	cipher noiseCipherState
+
+	s, sPub [noiseDHSize]byte // local static key pair
+	e, ePub [noiseDHSize]byte // local ephemeral key pair
+	rs, re  []byte            // remote static and ephemeral public keys
+}
+
+func newNoiseHandshake(privKey crypto.PrivKey, initiator bool, prologue []byte) (*noiseHandshake, error) {
+	hs := &noiseHandshake{privKey: privKey, initiator: initiator}
+	copy(hs.h[:], noiseProtocolName)
+	hs.ck = hs.h
+	hs.mixHash(prologue)
+
+	if err := generateNoiseKeyPair(&hs.s, &hs.sPub); err != nil {
+		return nil, err
+	}
+	if err := generateNoiseKeyPair(&hs.e, &hs.ePub); err != nil {
+		return nil, err
+	}
+	return hs, nil
+}
+
+func generateNoiseKeyPair(priv, pub *[noiseDHSize]byte) error {
+	if _, err := io.ReadFull(crand.Reader, priv[:]); err != nil {
+		return err
+	}
+	p, err := curve25519.X25519(priv[:], curve25519.Basepoint)
+	if err != nil {
+		return err
+	}
+	copy(pub[:], p)
+	return nil
+}
+
+func (hs *noiseHandshake) mixHash(data []byte) {
+	hash := sha256.New()
+	hash.Write(hs.h[:])
+	hash.Write(data)
+	hash.Sum(hs.h[:0])
+}
+
+func (hs *noiseHandshake) mixKey(ikm []byte) error {
+	ck, k := noiseHKDF(hs.ck[:], ikm)
+	hs.ck = ck
+	aead, err := chacha20poly1305.New(k[:])
+	if err != nil {
+		return err
+	}
+	hs.cipher = noiseCipherState{aead: aead}
+	return nil
+}
+
+// dh mixes the X25519 shared secret of a local private key and a remote
+// public key into the chaining key. It rejects low order points.
+func (hs *noiseHandshake) dh(priv *[noiseDHSize]byte, pub []byte) error {
+	secret, err := curve25519.X25519(priv[:], pub)
+	if err != nil {
+		return ErrSmallOrderRemotePubKey
+	}
+	return hs.mixKey(secret)
+}
+
+func (hs *noiseHandshake) encryptAndHash(dst, plaintext []byte) ([]byte, error) {
+	start := len(dst)
+	out, err := hs.cipher.encryptWithAd(dst, hs.h[:], plaintext)
+	if err != nil {
+		return nil, err
+	}
+	hs.mixHash(out[start:])
+	return out, nil
+}
+
+func (hs *noiseHandshake) decryptAndHash(ciphertext []byte) ([]byte, error) {
+	plaintext, err := hs.cipher.decryptWithAd(nil, hs.h[:], ciphertext)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decrypt noise handshake: %w", err)
+	}
+	hs.mixHash(ciphertext)
+	return plaintext, nil
+}
+
+func (hs *noiseHandshake) readEphemeralKey(msg []byte) ([]byte, error) {
+	if len(msg) < noiseDHSize {
+		return nil, fmt.Errorf("noise handshake message of %d bytes is too short", len(msg))
+	}
+	hs.re = append([]byte(nil), msg[:noiseDHSize]...)
+	hs.mixHash(hs.re)
+	return msg[noiseDHSize:], nil
+}
+
+func (hs *noiseHandshake) readStaticKey(msg []byte) ([]byte, error) {
+	size := noiseDHSize + aeadSizeOverhead
+	if len(msg) < size {
+		return nil, fmt.Errorf("noise handshake message of %d bytes is too short", len(msg))
+	}
+	rs, err := hs.decryptAndHash(msg[:size])
+	if err != nil {
+		return nil, err
+	}
+	hs.rs = rs
+	return msg[size:], nil
+}
+
+// writeMessageE writes the initiator's first message: -> e
+func (hs *noiseHandshake) writeMessageE() []byte {
+	msg := append([]byte(nil), hs.ePub[:]...)
+	hs.mixHash(hs.ePub[:])
+	// The empty payload is not encrypted yet, so it adds nothing.
+	hs.mixHash(nil)
+	return msg
+}
+
+// readMessageE reads the initiator's first message: -> e
+func (hs *noiseHandshake) readMessageE(msg []byte) error {
+	rest, err := hs.readEphemeralKey(msg)
+	if err != nil {
+		return err
+	}
+	if len(rest) != 0 {
+		return errors.New("unexpected payload in first noise handshake message")
+	}
+	hs.mixHash(nil)
+	return nil
+}
+
+// writeMessageEES writes the responder's message: <- e, ee, s, es
+func (hs *noiseHandshake) writeMessageEES() ([]byte, error) {
+	msg := append([]byte(nil), hs.ePub[:]...)
+	hs.mixHash(hs.ePub[:])
+	if err := hs.dh(&hs.e, hs.re); err != nil {
+		return nil, err
+	}
+	msg, err := hs.encryptAndHash(msg, hs.sPub[:])
+	if err != nil {
+		return nil, err
+	}
+	if err := hs.dh(&hs.s, hs.re); err != nil {
+		return nil, err
+	}
+	payload, err := signNoiseStaticKey(hs.privKey, hs.sPub[:])
+	if err != nil {
+		return nil, err
+	}
+	return hs.encryptAndHash(msg, payload)
+}
+
+// readMessageEES reads the responder's message: <- e, ee, s, es
+func (hs *noiseHandshake) readMessageEES(msg []byte) ([]byte, error) {
+	msg, err := hs.readEphemeralKey(msg)
+	if err != nil {
+		return nil, err
+	}
+	if err := hs.dh(&hs.e, hs.re); err != nil {
+		return nil, err
+	}
+	if msg, err = hs.readStaticKey(msg); err != nil {
+		return nil, err
+	}
+	if err := hs.dh(&hs.e, hs.rs); err != nil {
+		return nil, err
+	}
+	return hs.decryptAndHash(msg)
+}
+
+// writeMessageS writes the initiator's last message: -> s, se
+func (hs *noiseHandshake) writeMessageS() ([]byte, error) {
+	msg, err := hs.encryptAndHash(nil, hs.sPub[:])
+	if err != nil {
+		return nil, err
+	}
+	if err := hs.dh(&hs.s, hs.re); err != nil {
+		return nil, err
+	}
+	payload, err := signNoiseStaticKey(hs.privKey, hs.sPub[:])
+	if err != nil {
+		return nil, err
+	}
+	return hs.encryptAndHash(msg, payload)
+}
+
+// readMessageS reads the initiator's last message: -> s, se
+func (hs *noiseHandshake) readMessageS(msg []byte) ([]byte, error) {
+	msg, err := hs.readStaticKey(msg)
+	if err != nil {
+		return nil, err
+	}
+	if err := hs.dh(&hs.e, hs.rs); err != nil {
+		return nil, err
+	}
+	return hs.decryptAndHash(msg)
+}
+
+// split returns the cipher states of the initiator and of the responder.
+func (hs *noiseHandshake) split() (*noiseCipherState, *noiseCipherState, error) {
+	k1, k2 := noiseHKDF(hs.ck[:], nil)
+	c1, err := newNoiseCipherState(k1[:])
+	if err != nil {
+		return nil, nil, err
+	}
+	c2, err := newNoiseCipherState(k2[:])
+	if err != nil {
+		return nil, nil, err
+	}
+	return c1, c2, nil
+}
+
+// noiseHKDF is the HKDF function of the Noise specification with two outputs.
+func noiseHKDF(chainingKey, ikm []byte) (out1, out2 [sha256.Size]byte) {
+	mac := hmac.New(sha256.New, chainingKey)
+	mac.Write(ikm)
+	temp := mac.Sum(nil)
+
+	mac = hmac.New(sha256.New, temp)
+	mac.Write([]byte{0x01})
+	mac.Sum(out1[:0])
+
+	mac = hmac.New(sha256.New, temp)
+	mac.Write(out1[:])
+	mac.Write([]byte{0x02})
+	mac.Sum(out2[:0])
+	return out1, out2
+}
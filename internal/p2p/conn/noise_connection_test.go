@@ -0,0 +1,147 @@
+package conn
+
+import (
+	"io"
+	mrand "math/rand"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/tendermint/tendermint/crypto"
+	"github.com/tendermint/tendermint/crypto/ed25519"
+	"github.com/tendermint/tendermint/crypto/sr25519"
+	tmrand "github.com/tendermint/tendermint/libs/rand"
+)
+
+func TestNoiseConnectionHandshake(t *testing.T) {
+	fooConn, barConn := makeNoiseConnPair(t)
+	require.NoError(t, fooConn.Close())
+	require.NoError(t, barConn.Close())
+}
+
+func TestNoiseConnectionReadWrite(t *testing.T) {
+	fooConn, barConn := makeNoiseConnPair(t)
+	t.Cleanup(closeAll(t, fooConn, barConn))
+
+	// Messages larger than a Noise message are split, and reads smaller than
+	// a Noise message are buffered.
+	writes := make([][]byte, 20)
+	for i := range writes {
+		writes[i] = tmrand.Bytes(mrand.Intn(3*noiseMaxPayloadSize) + 1)
+	}
+
+	go func() {
+		for _, write := range writes {
+			if _, err := fooConn.Write(write); err != nil {
+				t.Error(err)
+				return
+			}
+		}
+	}()
+
+	for _, write := range writes {
+		read := make([]byte, len(write))
+		_, err := io.ReadFull(barConn, read)
+		require.NoError(t, err)
+		require.Equal(t, write, read)
+	}
+}
+
+func TestNoiseConnectionConcurrentWrite(t *testing.T) {
+	fooConn, barConn := makeNoiseConnPair(t)
+	t.Cleanup(closeAll(t, fooConn, barConn))
+	fooWriteText := tmrand.Str(dataMaxSize)
+
+	n := 100
+	wg := new(sync.WaitGroup)
+	wg.Add(3)
+	go writeLots(t, wg, fooConn, fooWriteText, n)
+	go writeLots(t, wg, fooConn, fooWriteText, n)
+
+	readLots(t, wg, barConn, n*2)
+	wg.Wait()
+}
+
+func TestNoiseConnectionPrologueMismatch(t *testing.T) {
+	fooConn, barConn := makeKVStoreConnPair()
+	t.Cleanup(closeAll(t, fooConn, barConn))
+
+	go func() {
+		_, _ = MakeNoiseConnection(barConn, ed25519.GenPrivKey(), false, []byte("bar"))
+		_ = barConn.Close()
+	}()
+
+	_, err := MakeNoiseConnection(fooConn, ed25519.GenPrivKey(), true, []byte("foo"))
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "failed to decrypt noise handshake")
+}
+
+func TestNoiseConnectionNonEd25519Pubkey(t *testing.T) {
+	fooConn, barConn := makeKVStoreConnPair()
+	t.Cleanup(closeAll(t, fooConn, barConn))
+
+	go func() {
+		_, _ = MakeNoiseConnection(barConn, sr25519.GenPrivKey(), false, nil)
+		_ = barConn.Close()
+	}()
+
+	_, err := MakeNoiseConnection(fooConn, ed25519.GenPrivKey(), true, nil)
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "expected ed25519 pubkey")
+}
+
+func TestNoiseConnectionTampered(t *testing.T) {
+	fooConn, barConn := makeNoiseConnPair(t)
+	t.Cleanup(closeAll(t, fooConn, barConn))
+
+	// Flip a bit of an encrypted message on the wire.
+	go func() {
+		msg, err := fooConn.sendCipher.encrypt(nil, []byte("hello"))
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		msg[0] ^= 1
+		_ = writeNoiseMessage(fooConn.conn, msg)
+	}()
+
+	_, err := barConn.Read(make([]byte, 16))
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "failed to decrypt NoiseConnection")
+}
+
+// makeNoiseConnPair makes a pair of connected NoiseConnections, foo being the
+// initiator.
+func makeNoiseConnPair(tb testing.TB) (fooNoiseConn, barNoiseConn *NoiseConnection) {
+	var (
+		fooConn, barConn = makeKVStoreConnPair()
+		fooPrvKey        = ed25519.GenPrivKey()
+		barPrvKey        = ed25519.GenPrivKey()
+		prologue         = []byte("prologue")
+	)
+
+	type result struct {
+		conn *NoiseConnection
+		err  error
+	}
+	barCh := make(chan result, 1)
+	go func() {
+		conn, err := MakeNoiseConnection(barConn, barPrvKey, false, prologue)
+		barCh <- result{conn, err}
+	}()
+
+	fooNoiseConn, err := MakeNoiseConnection(fooConn, fooPrvKey, true, prologue)
+	require.NoError(tb, err)
+	bar := <-barCh
+	require.NoError(tb, bar.err)
+
+	requireEqualPubKeys(tb, barPrvKey.PubKey(), fooNoiseConn.RemotePubKey())
+	requireEqualPubKeys(tb, fooPrvKey.PubKey(), bar.conn.RemotePubKey())
+	return fooNoiseConn, bar.conn
+}
+
+func requireEqualPubKeys(tb testing.TB, expect, actual crypto.PubKey) {
+	tb.Helper()
+	require.True(tb, expect.Equals(actual), "expected remote pubkey %v, got %v", expect, actual)
+}
@@ -0,0 +1,400 @@
+package p2p
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/tendermint/tendermint/types"
+)
+
+// nonRoutableNets are IP ranges that are not reachable from the public
+// Internet. Peers in these ranges are exempt from diversity limits, since
+// they are typically local testnets or sentry setups.
+var nonRoutableNets = func() []*net.IPNet {
+	cidrs := []string{
+		"0.0.0.0/8",      // "this" network
+		"10.0.0.0/8",     // RFC1918
+		"100.64.0.0/10",  // RFC6598 shared address space
+		"127.0.0.0/8",    // loopback
+		"169.254.0.0/16", // link-local
+		"172.16.0.0/12",  // RFC1918
+		"192.168.0.0/16", // RFC1918
+		"::/128",         // unspecified
+		"::1/128",        // loopback
+		"fc00::/7",       // unique local
+		"fe80::/10",      // link-local
+	}
+	nets := make([]*net.IPNet, 0, len(cidrs))
+	for _, cidr := range cidrs {
+		_, ipNet, err := net.ParseCIDR(cidr)
+		if err != nil {
+			panic(err)
+		}
+		nets = append(nets, ipNet)
+	}
+	return nets
+}()
+
+// isRoutable returns true if the IP address is reachable from the public
+// Internet.
+func isRoutable(ip net.IP) bool {
+	if ip == nil {
+		return false
+	}
+	for _, ipNet := range nonRoutableNets {
+		if ipNet.Contains(ip) {
+			return false
+		}
+	}
+	return true
+}
+
+// ASNMap maps IP address ranges to the autonomous system numbers (ASNs) of
+// the networks announcing them, for ASN peer diversity limits.
+type ASNMap struct {
+	v4 asnPrefixes
+	v6 asnPrefixes
+}
+
+// asnPrefixes maps IP prefixes of one address family to ASNs.
+type asnPrefixes struct {
+	bits    int                       // address size in bits
+	lengths []int                     // prefix lengths, longest first
+	asns    map[int]map[string]uint32 // prefix length → masked IP → ASN
+}
+
+func (p *asnPrefixes) add(ipNet *net.IPNet, asn uint32) {
+	ones, _ := ipNet.Mask.Size()
+	if p.asns == nil {
+		p.asns = map[int]map[string]uint32{}
+	}
+	if _, ok := p.asns[ones]; !ok {
+		p.asns[ones] = map[string]uint32{}
+		p.lengths = append(p.lengths, ones)
+		sort.Sort(sort.Reverse(sort.IntSlice(p.lengths)))
+	}
+	p.asns[ones][string(ipNet.IP)] = asn
+}
+
+func (p *asnPrefixes) lookup(ip net.IP) uint32 {
+	for _, ones := range p.lengths {
+		if asn, ok := p.asns[ones][string(ip.Mask(net.CIDRMask(ones, p.bits)))]; ok {
+			return asn
+		}
+	}
+	return 0
+}
+
+// ParseASNMap parses an ASN mapping with one IP range per line, in CIDR
+// notation, followed by the ASN announcing it, with or without an "AS"
+// prefix, e.g. "1.1.1.0/24 AS13335". Blank lines and lines starting with #
+// are ignored. Addresses in overlapping ranges map to the most specific one.
+func ParseASNMap(r io.Reader) (*ASNMap, error) {
+	m := &ASNMap{
+		v4: asnPrefixes{bits: 8 * net.IPv4len},
+		v6: asnPrefixes{bits: 8 * net.IPv6len},
+	}
+
+	scanner := bufio.NewScanner(r)
+	for line := 1; scanner.Scan(); line++ {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) == 0 || strings.HasPrefix(fields[0], "#") {
+			continue
+		}
+		if len(fields) != 2 {
+			return nil, fmt.Errorf("line %d: expected an IP range and an ASN, got %q", line, scanner.Text())
+		}
+
+		_, ipNet, err := net.ParseCIDR(fields[0])
+		if err != nil {
+			return nil, fmt.Errorf("line %d: invalid IP range: %w", line, err)
+		}
+		asn, err := strconv.ParseUint(strings.TrimPrefix(strings.ToUpper(fields[1]), "AS"), 10, 32)
+		if err != nil || asn == 0 {
+			return nil, fmt.Errorf("line %d: invalid ASN %q", line, fields[1])
+		}
+
+		if ip4 := ipNet.IP.To4(); ip4 != nil {
+			ipNet.IP = ip4
+			m.v4.add(ipNet, uint32(asn))
+		} else {
+			m.v6.add(ipNet, uint32(asn))
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// LoadASNMap loads an ASN mapping file, see ParseASNMap for the format.
+func LoadASNMap(path string) (*ASNMap, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	m, err := ParseASNMap(file)
+	if err != nil {
+		return nil, fmt.Errorf("invalid ASN map %v: %w", path, err)
+	}
+	return m, nil
+}
+
+// Lookup returns the ASN of an IP address, or 0 if it is unknown.
+func (m *ASNMap) Lookup(ip net.IP) uint32 {
+	if m == nil {
+		return 0
+	}
+	if ip4 := ip.To4(); ip4 != nil {
+		return m.v4.lookup(ip4)
+	}
+	if ip16 := ip.To16(); ip16 != nil {
+		return m.v6.lookup(ip16)
+	}
+	return 0
+}
+
+// peerGroup identifies the network groups of a peer IP address that
+// diversity limits apply to. The zero value is not in any group.
+type peerGroup struct {
+	subnet string // IPv4 /16 or IPv6 /32 subnet, empty if not routable
+	asn    uint32 // 0 if unknown or not routable
+}
+
+func (g peerGroup) String() string {
+	if g.asn != 0 {
+		return fmt.Sprintf("%v (AS%v)", g.subnet, g.asn)
+	}
+	return g.subnet
+}
+
+// ipGroup returns the peer group of an IP address.
+func (m *PeerManager) ipGroup(ip net.IP) peerGroup {
+	if !isRoutable(ip) {
+		return peerGroup{}
+	}
+	var subnet *net.IPNet
+	if ip4 := ip.To4(); ip4 != nil {
+		subnet = &net.IPNet{IP: ip4.Mask(net.CIDRMask(16, 32)), Mask: net.CIDRMask(16, 32)}
+	} else {
+		subnet = &net.IPNet{IP: ip.Mask(net.CIDRMask(32, 128)), Mask: net.CIDRMask(32, 128)}
+	}
+	return peerGroup{subnet: subnet.String(), asn: m.options.ASNMap.Lookup(ip)}
+}
+
+// addressGroup returns the peer group of an address, if its hostname is an IP
+// address.
+func (m *PeerManager) addressGroup(address NodeAddress) peerGroup {
+	return m.ipGroup(net.ParseIP(address.Hostname))
+}
+
+// groupCounts counts the peers in each subnet and ASN.
+type groupCounts struct {
+	subnets map[string]int
+	asns    map[uint32]int
+}
+
+func newGroupCounts() groupCounts {
+	return groupCounts{subnets: map[string]int{}, asns: map[uint32]int{}}
+}
+
+func (c groupCounts) add(group peerGroup) {
+	if group.subnet != "" {
+		c.subnets[group.subnet]++
+	}
+	if group.asn != 0 {
+		c.asns[group.asn]++
+	}
+}
+
+// addGroups adds n to the counts of each subnet and ASN in the given counts,
+// dropping the groups that end up empty.
+func (c groupCounts) addGroups(groups groupCounts, n int) {
+	for subnet := range groups.subnets {
+		if c.subnets[subnet] += n; c.subnets[subnet] <= 0 {
+			delete(c.subnets, subnet)
+		}
+	}
+	for asn := range groups.asns {
+		if c.asns[asn] += n; c.asns[asn] <= 0 {
+			delete(c.asns, asn)
+		}
+	}
+}
+
+// crowding returns the number of peers sharing a group with the given one.
+func (c groupCounts) crowding(group peerGroup) int {
+	n := 0
+	if group.subnet != "" {
+		n += c.subnets[group.subnet]
+	}
+	if group.asn != 0 {
+		n += c.asns[group.asn]
+	}
+	return n
+}
+
+// connectedGroupCounts counts the connected peers in each group, except the
+// given peer and peers being evicted. The caller must hold the mutex lock.
+func (m *PeerManager) connectedGroupCounts(except types.NodeID) groupCounts {
+	counts := newGroupCounts()
+	for id, group := range m.connectedGroups {
+		if id != except && !m.evicting[id] {
+			counts.add(group)
+		}
+	}
+	return counts
+}
+
+// groupFull checks whether the given peer can't join a group of connected
+// peers without exceeding MaxConnectedPerSubnet or MaxConnectedPerASN,
+// returning whether the subnet and the ASN are full. Persistent peers are
+// exempt. The caller must hold the mutex lock.
+func (m *PeerManager) groupFull(id types.NodeID, group peerGroup) (subnetFull bool, asnFull bool) {
+	if m.options.isPersistent(id) || group == (peerGroup{}) {
+		return false, false
+	}
+	counts := m.connectedGroupCounts(id)
+	subnetFull = m.options.MaxConnectedPerSubnet > 0 && group.subnet != "" &&
+		counts.subnets[group.subnet] >= int(m.options.MaxConnectedPerSubnet)
+	asnFull = m.options.MaxConnectedPerASN > 0 && group.asn != 0 &&
+		counts.asns[group.asn] >= int(m.options.MaxConnectedPerASN)
+	return subnetFull, asnFull
+}
+
+// storedGroupFull checks whether adding an address of the given peer to the
+// peer store would exceed MaxPeersPerSubnet or MaxPeersPerASN. Persistent
+// peers are exempt. The caller must hold the mutex lock.
+func (m *PeerManager) storedGroupFull(id types.NodeID, group peerGroup) bool {
+	if m.options.MaxPeersPerSubnet == 0 && m.options.MaxPeersPerASN == 0 {
+		return false
+	}
+	if m.options.isPersistent(id) || group == (peerGroup{}) {
+		return false
+	}
+
+	// the peer's own addresses don't count against it
+	subnetPeers := m.store.groups.subnets[group.subnet]
+	asnPeers := m.store.groups.asns[group.asn]
+	if peer, ok := m.store.peers[id]; ok {
+		own := m.peerGroups(peer)
+		subnetPeers -= own.subnets[group.subnet]
+		asnPeers -= own.asns[group.asn]
+	}
+
+	return (m.options.MaxPeersPerSubnet > 0 && group.subnet != "" &&
+		subnetPeers >= int(m.options.MaxPeersPerSubnet)) ||
+		(m.options.MaxPeersPerASN > 0 && group.asn != 0 &&
+			asnPeers >= int(m.options.MaxPeersPerASN))
+}
+
+// peerGroups returns the subnets and ASNs of a peer's addresses, each counted
+// once.
+func (m *PeerManager) peerGroups(peer *peerInfo) groupCounts {
+	groups := newGroupCounts()
+	for address := range peer.AddressInfo {
+		group := m.addressGroup(address)
+		if group.subnet != "" {
+			groups.subnets[group.subnet] = 1
+		}
+		if group.asn != 0 {
+			groups.asns[group.asn] = 1
+		}
+	}
+	return groups
+}
+
+// diversityKey returns the group an address is diversified by: its ASN when
+// known, otherwise its subnet. Addresses that aren't in any group have an
+// empty key and are considered diverse.
+func (m *PeerManager) diversityKey(address NodeAddress) string {
+	switch group := m.addressGroup(address); {
+	case group.asn != 0:
+		return fmt.Sprintf("AS%v", group.asn)
+	case group.subnet != "":
+		return group.subnet
+	}
+	return ""
+}
+
+// diversePicker picks up to limit addresses from the ones offered to it,
+// taking the first address of each group as it is offered, and deferring the
+// others until there are no more addresses to offer.
+type diversePicker struct {
+	m        *PeerManager
+	limit    int
+	picked   []NodeAddress
+	deferred []NodeAddress
+	seen     map[string]bool
+}
+
+func (m *PeerManager) newDiversePicker(limit int) *diversePicker {
+	return &diversePicker{m: m, limit: limit, picked: []NodeAddress{}, seen: map[string]bool{}}
+}
+
+// offer offers an address to the picker, returning false once enough
+// addresses have been picked.
+func (p *diversePicker) offer(address NodeAddress) bool {
+	if len(p.picked) >= p.limit {
+		return false
+	}
+	key := p.m.diversityKey(address)
+	if key == "" || !p.seen[key] {
+		p.seen[key] = true
+		p.picked = append(p.picked, address)
+		return len(p.picked) < p.limit
+	}
+	if len(p.deferred) < p.limit-len(p.picked) {
+		p.deferred = append(p.deferred, address)
+	}
+	return true
+}
+
+// addresses returns the picked addresses, filling up with the deferred ones
+// spread across their groups.
+func (p *diversePicker) addresses() []NodeAddress {
+	addresses := append(p.picked, p.m.diversify(p.deferred)...)
+	if len(addresses) > p.limit {
+		addresses = addresses[:p.limit]
+	}
+	return addresses
+}
+
+// diversify reorders addresses so that consecutive addresses are in different
+// groups where possible, keeping the relative order of addresses within each
+// group. Addresses are grouped by ASN when known, otherwise by subnet.
+// Addresses that aren't in any group are considered diverse.
+func (m *PeerManager) diversify(addresses []NodeAddress) []NodeAddress {
+	var (
+		buckets [][]NodeAddress
+		index   = map[string]int{}
+	)
+	for _, address := range addresses {
+		key := m.diversityKey(address)
+		if i, ok := index[key]; ok && key != "" {
+			buckets[i] = append(buckets[i], address)
+			continue
+		}
+		index[key] = len(buckets)
+		buckets = append(buckets, []NodeAddress{address})
+	}
+
+	diverse := make([]NodeAddress, 0, len(addresses))
+	for len(diverse) < len(addresses) {
+		for i, bucket := range buckets {
+			if len(bucket) > 0 {
+				diverse = append(diverse, bucket[0])
+				buckets[i] = bucket[1:]
+			}
+		}
+	}
+	return diverse
+}
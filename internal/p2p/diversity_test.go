@@ -0,0 +1,201 @@
+package p2p_test
+
+import (
+	"net"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	dbm "github.com/tendermint/tm-db"
+
+	"github.com/tendermint/tendermint/internal/p2p"
+	"github.com/tendermint/tendermint/types"
+)
+
+// tcpAddress returns a TCP node address for the given ID character and IP.
+func tcpAddress(id string, ip string) p2p.NodeAddress {
+	return p2p.NodeAddress{
+		Protocol: "tcp",
+		NodeID:   types.NodeID(strings.Repeat(id, 40)),
+		Hostname: ip,
+		Port:     26656,
+	}
+}
+
+func TestParseASNMap(t *testing.T) {
+	asnMap, err := p2p.ParseASNMap(strings.NewReader(`
+# comment
+1.2.0.0/16    AS100
+1.2.3.0/24    200
+2001:db8::/32 as300
+`))
+	require.NoError(t, err)
+
+	testcases := map[string]uint32{
+		"1.2.0.1":        100,
+		"1.2.3.4":        200,
+		"1.3.0.1":        0,
+		"2001:db8::1":    300,
+		"2001:db9::1":    0,
+		"::ffff:1.2.0.1": 100,
+	}
+	for ip, expect := range testcases {
+		require.Equal(t, expect, asnMap.Lookup(net.ParseIP(ip)), ip)
+	}
+
+	var nilMap *p2p.ASNMap
+	require.Zero(t, nilMap.Lookup(net.ParseIP("1.2.0.1")))
+
+	for _, invalid := range []string{"1.2.0.0/16", "1.2.0.0 AS100", "1.2.0.0/16 ASX", "1.2.0.0/16 0"} {
+		_, err := p2p.ParseASNMap(strings.NewReader(invalid))
+		require.Error(t, err, invalid)
+	}
+}
+
+func TestPeerManager_Add_MaxPeersPerSubnet(t *testing.T) {
+	a := tcpAddress("a", "1.2.0.1")
+	b := tcpAddress("b", "1.2.0.2")
+	c := tcpAddress("c", "1.2.0.3")
+	d := tcpAddress("d", "1.3.0.1")
+	e := tcpAddress("e", "10.0.0.1")
+	f := tcpAddress("f", "10.0.0.2")
+
+	db := dbm.NewMemDB()
+	options := p2p.PeerManagerOptions{
+		PersistentPeers:   []types.NodeID{c.NodeID},
+		MaxPeersPerSubnet: 1,
+	}
+	peerManager, err := p2p.NewPeerManager(selfID, db, options)
+	require.NoError(t, err)
+
+	for _, address := range []p2p.NodeAddress{a, c, d, e, f} {
+		added, err := peerManager.Add(address)
+		require.NoError(t, err)
+		require.True(t, added, address)
+	}
+
+	// b is in the same subnet as a, and isn't persistent.
+	added, err := peerManager.Add(b)
+	require.NoError(t, err)
+	require.False(t, added)
+	require.ElementsMatch(t, []types.NodeID{a.NodeID, c.NodeID, d.NodeID, e.NodeID, f.NodeID},
+		peerManager.Peers())
+
+	// Another address of d in its subnet doesn't count against it.
+	d2 := d
+	d2.Port++
+	added, err = peerManager.Add(d2)
+	require.NoError(t, err)
+	require.True(t, added)
+
+	// The subnet is still full when the peers are loaded from the database.
+	peerManager, err = p2p.NewPeerManager(selfID, db, options)
+	require.NoError(t, err)
+	g := tcpAddress("9", "1.3.0.2")
+	added, err = peerManager.Add(g)
+	require.NoError(t, err)
+	require.False(t, added)
+
+	// Removing d makes room for g.
+	require.NoError(t, peerManager.Remove(d.NodeID))
+	added, err = peerManager.Add(g)
+	require.NoError(t, err)
+	require.True(t, added)
+}
+
+func TestPeerManager_Accepted_MaxConnectedPerSubnet(t *testing.T) {
+	a := tcpAddress("a", "1.2.0.1")
+	b := tcpAddress("b", "1.2.0.2")
+	c := tcpAddress("c", "1.3.0.1")
+	d := tcpAddress("d", "1.2.0.3")
+
+	peerManager, err := p2p.NewPeerManager(selfID, dbm.NewMemDB(), p2p.PeerManagerOptions{
+		PeerScores:            map[types.NodeID]p2p.PeerScore{d.NodeID: 1},
+		MaxConnectedPerSubnet: 1,
+	})
+	require.NoError(t, err)
+	for _, address := range []p2p.NodeAddress{a, b, c, d} {
+		added, err := peerManager.Add(address)
+		require.NoError(t, err)
+		require.True(t, added)
+	}
+
+	require.NoError(t, peerManager.Accepted(a.NodeID, net.ParseIP(a.Hostname)))
+	require.NoError(t, peerManager.Accepted(c.NodeID, net.ParseIP(c.Hostname)))
+
+	// b can't replace a, since it's not scored higher.
+	require.Error(t, peerManager.Accepted(b.NodeID, net.ParseIP(b.Hostname)))
+
+	// Peers without a known IP aren't limited.
+	require.NoError(t, peerManager.Accepted(b.NodeID, nil))
+	peerManager.Disconnected(b.NodeID)
+
+	// d replaces a, which is in the same subnet.
+	require.NoError(t, peerManager.Accepted(d.NodeID, net.ParseIP(d.Hostname)))
+	evict, err := peerManager.TryEvictNext()
+	require.NoError(t, err)
+	require.Equal(t, a.NodeID, evict)
+}
+
+func TestPeerManager_Dialed_MaxConnectedPerASN(t *testing.T) {
+	asnMap, err := p2p.ParseASNMap(strings.NewReader("1.2.0.0/15 AS100"))
+	require.NoError(t, err)
+
+	a := tcpAddress("a", "1.2.0.1")
+	b := tcpAddress("b", "1.3.0.1")
+	c := tcpAddress("c", "1.4.0.1")
+
+	peerManager, err := p2p.NewPeerManager(selfID, dbm.NewMemDB(), p2p.PeerManagerOptions{
+		PeerScores:         map[types.NodeID]p2p.PeerScore{a.NodeID: 2, c.NodeID: 1},
+		MaxConnectedPerASN: 1,
+		ASNMap:             asnMap,
+	})
+	require.NoError(t, err)
+	for _, address := range []p2p.NodeAddress{a, b, c} {
+		added, err := peerManager.Add(address)
+		require.NoError(t, err)
+		require.True(t, added)
+	}
+
+	dial, err := peerManager.TryDialNext()
+	require.NoError(t, err)
+	require.Equal(t, a, dial)
+	require.NoError(t, peerManager.Dialed(a, nil))
+
+	// b is in the same ASN as a, so it's skipped.
+	dial, err = peerManager.TryDialNext()
+	require.NoError(t, err)
+	require.Equal(t, c, dial)
+	require.NoError(t, peerManager.Dialed(c, nil))
+
+	dial, err = peerManager.TryDialNext()
+	require.NoError(t, err)
+	require.Zero(t, dial)
+
+	// The IP the peer was reached on takes precedence over the address.
+	peerManager.Disconnected(c.NodeID)
+	require.Error(t, peerManager.Dialed(c, net.ParseIP("1.3.0.2")))
+}
+
+func TestPeerManager_Advertise_Diverse(t *testing.T) {
+	a := tcpAddress("a", "1.2.0.1")
+	b := tcpAddress("b", "1.2.0.2")
+	c := tcpAddress("c", "1.3.0.1")
+	d := tcpAddress("d", "10.0.0.1")
+
+	peerManager, err := p2p.NewPeerManager(selfID, dbm.NewMemDB(), p2p.PeerManagerOptions{
+		PeerScores: map[types.NodeID]p2p.PeerScore{a.NodeID: 4, b.NodeID: 3, c.NodeID: 2, d.NodeID: 1},
+	})
+	require.NoError(t, err)
+	for _, address := range []p2p.NodeAddress{a, b, c, d} {
+		added, err := peerManager.Add(address)
+		require.NoError(t, err)
+		require.True(t, added)
+	}
+
+	require.Equal(t, []p2p.NodeAddress{a, c, d, b}, peerManager.Advertise(selfID, 10))
+	require.Equal(t, []p2p.NodeAddress{a, c}, peerManager.Advertise(selfID, 2))
+	require.Equal(t, []p2p.NodeAddress{a, c, d}, peerManager.Advertise(selfID, 3))
+	require.Equal(t, []p2p.NodeAddress{b, c, d}, peerManager.Advertise(a.NodeID, 10))
+	require.Empty(t, peerManager.Advertise(selfID, 0))
+}
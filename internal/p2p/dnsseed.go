@@ -0,0 +1,141 @@
+package p2p
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"strings"
+	"time"
+
+	"github.com/tendermint/tendermint/types"
+)
+
+// DNSSeedProtocol is the NodeAddress protocol of DNS seeds, i.e. domain names
+// listing peer addresses in DNS records (e.g. dnsseed://seeds.example.org).
+// DNS seeds are not peers themselves, and are resolved into peer addresses by
+// ResolveDNSSeed.
+const DNSSeedProtocol Protocol = "dnsseed"
+
+const (
+	// dnsSeedTimeout is the timeout for resolving all DNS seeds once.
+	dnsSeedTimeout = 30 * time.Second
+
+	// dnsSeedRetryInterval is the longest time to wait before resolving DNS
+	// seeds again after a failure.
+	dnsSeedRetryInterval = time.Minute
+)
+
+// DNSResolver looks up the DNS records of DNS seeds. It is implemented by
+// net.Resolver.
+type DNSResolver interface {
+	LookupTXT(ctx context.Context, name string) ([]string, error)
+	LookupSRV(ctx context.Context, service, proto, name string) (string, []*net.SRV, error)
+}
+
+// ResolveDNSSeed resolves a DNS seed into the peer addresses it lists, in
+// either or both of these DNS records of the seed's domain name:
+//
+//   TXT records, containing comma or space separated peer addresses, e.g.
+//   "ade6fe1c8b5e0a4b50a3e1e2e9dbd1d8d7f0eb76@node1.example.org:26656".
+//
+//   SRV records, whose target's first label is the peer's node ID, e.g.
+//   "ade6fe1c8b5e0a4b50a3e1e2e9dbd1d8d7f0eb76.nodes.example.org." port 26656.
+//
+// Invalid records are skipped. It only fails if the seed has no valid records
+// and one of the lookups failed.
+func ResolveDNSSeed(ctx context.Context, resolver DNSResolver, seed NodeAddress) ([]NodeAddress, error) {
+	if seed.Protocol != DNSSeedProtocol {
+		return nil, fmt.Errorf("%v is not a DNS seed", seed)
+	}
+	if err := seed.Validate(); err != nil {
+		return nil, err
+	}
+
+	var (
+		addresses []NodeAddress
+		errs      []string
+	)
+
+	txts, err := resolver.LookupTXT(ctx, seed.Hostname)
+	if err != nil {
+		errs = append(errs, err.Error())
+	}
+	for _, txt := range txts {
+		for _, s := range strings.FieldsFunc(txt, func(r rune) bool { return r == ',' || r == ' ' }) {
+			address, err := ParseNodeAddress(s)
+			if err != nil || address.Protocol == DNSSeedProtocol {
+				continue
+			}
+			addresses = append(addresses, address)
+		}
+	}
+
+	_, srvs, err := resolver.LookupSRV(ctx, "", "", seed.Hostname)
+	if err != nil {
+		errs = append(errs, err.Error())
+	}
+	for _, srv := range srvs {
+		target := strings.ToLower(strings.TrimSuffix(srv.Target, "."))
+		labels := strings.SplitN(target, ".", 2)
+		if len(labels) != 2 {
+			continue
+		}
+		address := NodeAddress{
+			NodeID:   types.NodeID(labels[0]),
+			Protocol: defaultProtocol,
+			Hostname: target,
+			Port:     srv.Port,
+		}
+		if address.Validate() != nil {
+			continue
+		}
+		addresses = append(addresses, address)
+	}
+
+	if len(addresses) == 0 && len(errs) > 0 {
+		return nil, fmt.Errorf("failed to resolve DNS seed %v: %s", seed, strings.Join(errs, "; "))
+	}
+	return addresses, nil
+}
+
+// RefreshDNSSeeds resolves the configured DNS seeds and adds the peer
+// addresses they list to the peer store. It returns the number of new
+// addresses, and an error listing the seeds that failed to resolve and the
+// addresses that failed to be added, if any.
+func (m *PeerManager) RefreshDNSSeeds(ctx context.Context) (int, error) {
+	resolver := m.options.DNSResolver
+	if resolver == nil {
+		resolver = net.DefaultResolver
+	}
+
+	var (
+		added int
+		errs  []string
+	)
+	for _, seed := range m.options.DNSSeeds {
+		addresses, err := ResolveDNSSeed(ctx, resolver, seed)
+		if err != nil {
+			errs = append(errs, err.Error())
+			continue
+		}
+		for _, address := range addresses {
+			if address.NodeID == m.selfID {
+				continue
+			}
+			ok, err := m.Add(address)
+			if err != nil {
+				errs = append(errs, fmt.Sprintf("failed to add %v from DNS seed %v: %v", address, seed, err))
+				continue
+			}
+			if ok {
+				added++
+			}
+		}
+	}
+
+	if len(errs) > 0 {
+		return added, errors.New(strings.Join(errs, "; "))
+	}
+	return added, nil
+}
@@ -0,0 +1,172 @@
+package p2p_test
+
+import (
+	"context"
+	"errors"
+	"net"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	dbm "github.com/tendermint/tm-db"
+
+	"github.com/tendermint/tendermint/internal/p2p"
+	"github.com/tendermint/tendermint/libs/log"
+	"github.com/tendermint/tendermint/types"
+)
+
+// dnsZone is a local DNS stand-in serving the TXT and SRV records of domains.
+type dnsZone struct {
+	mtx  sync.Mutex
+	txt  map[string][]string
+	srv  map[string][]*net.SRV
+	fail map[string]bool
+}
+
+func (z *dnsZone) LookupTXT(ctx context.Context, name string) ([]string, error) {
+	z.mtx.Lock()
+	defer z.mtx.Unlock()
+	if z.fail[name] {
+		return nil, errors.New("server misbehaving")
+	}
+	if len(z.txt[name]) == 0 {
+		return nil, &net.DNSError{Err: "no such host", Name: name, IsNotFound: true}
+	}
+	return z.txt[name], nil
+}
+
+func (z *dnsZone) LookupSRV(ctx context.Context, service, proto, name string) (string, []*net.SRV, error) {
+	z.mtx.Lock()
+	defer z.mtx.Unlock()
+	if z.fail[name] {
+		return "", nil, errors.New("server misbehaving")
+	}
+	if len(z.srv[name]) == 0 {
+		return "", nil, &net.DNSError{Err: "no such host", Name: name, IsNotFound: true}
+	}
+	return name, z.srv[name], nil
+}
+
+func TestResolveDNSSeed(t *testing.T) {
+	idA := types.NodeID(strings.Repeat("a", 40))
+	idB := types.NodeID(strings.Repeat("b", 40))
+	idC := types.NodeID(strings.Repeat("c", 40))
+
+	zone := &dnsZone{
+		txt: map[string][]string{
+			"seeds.example.org": {
+				string(idA) + "@node-a.example.org:26656, " + string(idB) + "@10.0.0.2:26656",
+				"invalid dnsseed://other.example.org",
+			},
+			"txt.example.org": {string(idA) + "@node-a.example.org:26656"},
+		},
+		srv: map[string][]*net.SRV{
+			"seeds.example.org": {
+				{Target: strings.ToUpper(string(idC)) + ".nodes.example.org.", Port: 26656},
+				{Target: "not-a-node-id.nodes.example.org.", Port: 26656},
+			},
+		},
+		fail: map[string]bool{"broken.example.org": true},
+	}
+
+	seed, err := p2p.ParseNodeAddress("dnsseed://seeds.example.org")
+	require.NoError(t, err)
+	addresses, err := p2p.ResolveDNSSeed(ctx, zone, seed)
+	require.NoError(t, err)
+	require.Equal(t, []p2p.NodeAddress{
+		{Protocol: "mconn", NodeID: idA, Hostname: "node-a.example.org", Port: 26656},
+		{Protocol: "mconn", NodeID: idB, Hostname: "10.0.0.2", Port: 26656},
+		{Protocol: "mconn", NodeID: idC, Hostname: string(idC) + ".nodes.example.org", Port: 26656},
+	}, addresses)
+
+	// A seed with only one kind of records resolves.
+	seed, err = p2p.ParseNodeAddress("dnsseed://txt.example.org")
+	require.NoError(t, err)
+	addresses, err = p2p.ResolveDNSSeed(ctx, zone, seed)
+	require.NoError(t, err)
+	require.Len(t, addresses, 1)
+
+	// A seed without records fails.
+	seed, err = p2p.ParseNodeAddress("dnsseed://broken.example.org")
+	require.NoError(t, err)
+	_, err = p2p.ResolveDNSSeed(ctx, zone, seed)
+	require.Error(t, err)
+
+	// Other addresses are not DNS seeds.
+	_, err = p2p.ResolveDNSSeed(ctx, zone, p2p.NodeAddress{Protocol: "mconn", NodeID: idA, Hostname: "seeds.example.org"})
+	require.Error(t, err)
+
+	// DNS seeds are not dialable.
+	_, err = seed.Resolve(ctx)
+	require.Error(t, err)
+}
+
+func TestPeerManager_DNSSeeds(t *testing.T) {
+	idA := types.NodeID(strings.Repeat("a", 40))
+	idB := types.NodeID(strings.Repeat("b", 40))
+	idC := types.NodeID(strings.Repeat("c", 40))
+
+	seed, err := p2p.ParseNodeAddress("dnsseed://seeds.example.org")
+	require.NoError(t, err)
+	zone := &dnsZone{
+		txt: map[string][]string{
+			"seeds.example.org": {string(selfID) + "@self.example.org:26656 " + string(idA) + "@node-a.example.org:26656"},
+		},
+	}
+
+	// Seeds must be DNS seeds.
+	_, err = p2p.NewPeerManager(selfID, dbm.NewMemDB(), p2p.PeerManagerOptions{
+		DNSSeeds: []p2p.NodeAddress{{Protocol: "mconn", NodeID: idA, Hostname: "seeds.example.org"}},
+	})
+	require.Error(t, err)
+
+	peerManager, err := p2p.NewPeerManager(selfID, dbm.NewMemDB(), p2p.PeerManagerOptions{
+		DNSSeeds:        []p2p.NodeAddress{seed},
+		DNSSeedInterval: 10 * time.Millisecond,
+		DNSResolver:     zone,
+	})
+	require.NoError(t, err)
+	defer peerManager.Close()
+
+	// The seeds are resolved when refreshed, skipping ourself.
+	require.Empty(t, peerManager.Peers())
+	added, err := peerManager.RefreshDNSSeeds(ctx)
+	require.NoError(t, err)
+	require.Equal(t, 1, added)
+	require.Equal(t, []types.NodeID{idA}, peerManager.Peers())
+
+	added, err = peerManager.RefreshDNSSeeds(ctx)
+	require.NoError(t, err)
+	require.Zero(t, added)
+
+	// The router refreshes them periodically, until it is stopped.
+	router, err := p2p.NewRouter(
+		log.TestingLogger(),
+		p2p.NopMetrics(),
+		selfInfo,
+		selfKey,
+		peerManager,
+		nil,
+		nil,
+		p2p.RouterOptions{},
+	)
+	require.NoError(t, err)
+	require.NoError(t, router.Start())
+
+	zone.mtx.Lock()
+	zone.txt["seeds.example.org"] = []string{string(idB) + "@node-b.example.org:26656"}
+	zone.mtx.Unlock()
+	require.Eventually(t, func() bool {
+		return len(peerManager.Peers()) == 2
+	}, time.Second, 10*time.Millisecond)
+
+	require.NoError(t, router.Stop())
+	zone.mtx.Lock()
+	zone.txt["seeds.example.org"] = []string{string(idC) + "@node-c.example.org:26656"}
+	zone.mtx.Unlock()
+	require.Never(t, func() bool {
+		return len(peerManager.Peers()) == 3
+	}, 100*time.Millisecond, 10*time.Millisecond)
+}
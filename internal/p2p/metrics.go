@@ -56,6 +56,19 @@ type Metrics struct {
 	// queue for a specific flow (i.e. Channel).
 	PeerQueueMsgSize metrics.Gauge
 
+	// RouterChannelSendBytes defines the number of bytes sent to peers on a
+	// p2p Channel.
+	RouterChannelSendBytes metrics.Counter
+
+	// RouterChannelRecvBytes defines the number of bytes received from peers
+	// on a p2p Channel.
+	RouterChannelRecvBytes metrics.Counter
+
+	// RouterChannelDroppedMsgs defines the number of messages of a p2p Channel
+	// dropped by the router, because the channel exceeded its bandwidth or
+	// was closed.
+	RouterChannelDroppedMsgs metrics.Counter
+
 	mtx               *sync.RWMutex
 	messageLabelNames map[reflect.Type]string
 }
@@ -134,6 +147,27 @@ func PrometheusMetrics(namespace string, labelsAndValues ...string) *Metrics {
 			Help:      "The size of messages sent over a peer's queue for a specific p2p Channel.",
 		}, append(labels, "ch_id")).With(labelsAndValues...),
 
+		RouterChannelSendBytes: prometheus.NewCounterFrom(stdprometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: MetricsSubsystem,
+			Name:      "router_channel_send_bytes",
+			Help:      "Number of bytes sent to peers on a specific p2p Channel.",
+		}, append(labels, "ch_id")).With(labelsAndValues...),
+
+		RouterChannelRecvBytes: prometheus.NewCounterFrom(stdprometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: MetricsSubsystem,
+			Name:      "router_channel_recv_bytes",
+			Help:      "Number of bytes received from peers on a specific p2p Channel.",
+		}, append(labels, "ch_id")).With(labelsAndValues...),
+
+		RouterChannelDroppedMsgs: prometheus.NewCounterFrom(stdprometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: MetricsSubsystem,
+			Name:      "router_channel_dropped_msgs",
+			Help:      "The number of messages of a specific p2p Channel dropped by the router because the channel exceeded its bandwidth or was closed.",
+		}, append(labels, "ch_id")).With(labelsAndValues...),
+
 		mtx:               &sync.RWMutex{},
 		messageLabelNames: map[reflect.Type]string{},
 	}
@@ -142,17 +176,20 @@ func PrometheusMetrics(namespace string, labelsAndValues ...string) *Metrics {
 // NopMetrics returns no-op Metrics.
 func NopMetrics() *Metrics {
 	return &Metrics{
-		Peers:                  discard.NewGauge(),
-		PeerReceiveBytesTotal:  discard.NewCounter(),
-		PeerSendBytesTotal:     discard.NewCounter(),
-		PeerPendingSendBytes:   discard.NewGauge(),
-		RouterPeerQueueRecv:    discard.NewHistogram(),
-		RouterPeerQueueSend:    discard.NewHistogram(),
-		RouterChannelQueueSend: discard.NewHistogram(),
-		PeerQueueDroppedMsgs:   discard.NewCounter(),
-		PeerQueueMsgSize:       discard.NewGauge(),
-		mtx:                    &sync.RWMutex{},
-		messageLabelNames:      map[reflect.Type]string{},
+		Peers:                    discard.NewGauge(),
+		PeerReceiveBytesTotal:    discard.NewCounter(),
+		PeerSendBytesTotal:       discard.NewCounter(),
+		PeerPendingSendBytes:     discard.NewGauge(),
+		RouterPeerQueueRecv:      discard.NewHistogram(),
+		RouterPeerQueueSend:      discard.NewHistogram(),
+		RouterChannelQueueSend:   discard.NewHistogram(),
+		PeerQueueDroppedMsgs:     discard.NewCounter(),
+		PeerQueueMsgSize:         discard.NewGauge(),
+		RouterChannelSendBytes:   discard.NewCounter(),
+		RouterChannelRecvBytes:   discard.NewCounter(),
+		RouterChannelDroppedMsgs: discard.NewCounter(),
+		mtx:                      &sync.RWMutex{},
+		messageLabelNames:        map[reflect.Type]string{},
 	}
 }
 
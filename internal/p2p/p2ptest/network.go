@@ -14,6 +14,7 @@ import (
 	"github.com/tendermint/tendermint/internal/p2p"
 	"github.com/tendermint/tendermint/libs/log"
 	"github.com/tendermint/tendermint/types"
+	"github.com/tendermint/tendermint/version"
 )
 
 // Network sets up an in-memory network that can be used for high-level P2P
@@ -37,6 +38,8 @@ type NetworkOptions struct {
 type NodeOptions struct {
 	MaxPeers     uint16
 	MaxConnected uint16
+	PeerSendRate int64
+	PeerRecvRate int64
 }
 
 func (opts *NetworkOptions) setDefaults() {
@@ -225,9 +228,10 @@ func (n *Network) MakeNode(t *testing.T, opts NodeOptions) *Node {
 	privKey := ed25519.GenPrivKey()
 	nodeID := types.NodeIDFromPubKey(privKey.PubKey())
 	nodeInfo := types.NodeInfo{
-		NodeID:     nodeID,
-		ListenAddr: "0.0.0.0:0", // FIXME: We have to fake this for now.
-		Moniker:    string(nodeID),
+		ProtocolVersion: types.ProtocolVersion{P2P: version.P2PProtocol},
+		NodeID:          nodeID,
+		ListenAddr:      "0.0.0.0:0", // FIXME: We have to fake this for now.
+		Moniker:         string(nodeID),
 	}
 
 	transport := n.memoryNetwork.CreateTransport(nodeID)
@@ -250,7 +254,11 @@ func (n *Network) MakeNode(t *testing.T, opts NodeOptions) *Node {
 		peerManager,
 		[]p2p.Transport{transport},
 		transport.Endpoints(),
-		p2p.RouterOptions{DialSleep: func(_ context.Context) {}},
+		p2p.RouterOptions{
+			DialSleep:    func(_ context.Context) {},
+			PeerSendRate: opts.PeerSendRate,
+			PeerRecvRate: opts.PeerRecvRate,
+		},
 	)
 	require.NoError(t, err)
 	require.NoError(t, router.Start())
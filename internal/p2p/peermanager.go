@@ -6,11 +6,13 @@ import (
 	"fmt"
 	"math"
 	"math/rand"
+	"net"
 	"sort"
 	"sync"
 	"time"
 
 	"github.com/gogo/protobuf/proto"
+	gogotypes "github.com/gogo/protobuf/types"
 	"github.com/google/orderedcode"
 	dbm "github.com/tendermint/tm-db"
 
@@ -154,6 +156,37 @@ type PeerManagerOptions struct {
 	// consider private and never gossip.
 	PrivatePeers map[types.NodeID]struct{}
 
+	// DNSSeeds are DNS seed addresses (e.g. dnsseed://seeds.example.org)
+	// whose listed peers are added to the peer store by RefreshDNSSeeds,
+	// which the router calls while it is running, see ResolveDNSSeed.
+	DNSSeeds []NodeAddress
+
+	// DNSSeedInterval is how often DNS seeds are resolved again. 0 resolves
+	// them only once, when the router starts.
+	DNSSeedInterval time.Duration
+
+	// DNSResolver resolves DNS seeds. Defaults to net.DefaultResolver.
+	DNSResolver DNSResolver
+
+	// MaxConnectedPerSubnet is the maximum number of connected peers in the
+	// same IPv4 /16 or IPv6 /32 subnet, and MaxPeersPerSubnet the maximum
+	// number of peers in the peer store with an address in it. This keeps a
+	// single network operator from taking all connection slots and
+	// eclipsing us. Peers on loopback, private and link-local addresses and
+	// persistent peers are exempt. 0 means no limit.
+	MaxConnectedPerSubnet uint16
+	MaxPeersPerSubnet     uint16
+
+	// MaxConnectedPerASN and MaxPeersPerASN are like MaxConnectedPerSubnet
+	// and MaxPeersPerSubnet, but for the autonomous systems of peers, as
+	// given by ASNMap. 0 means no limit.
+	MaxConnectedPerASN uint16
+	MaxPeersPerASN     uint16
+
+	// ASNMap maps IP addresses to autonomous system numbers, for the ASN
+	// limits. If nil, ASNs are unknown and the ASN limits don't apply.
+	ASNMap *ASNMap
+
 	// persistentPeers provides fast PersistentPeers lookups. It is built
 	// by optimize().
 	persistentPeers map[types.NodeID]bool
@@ -173,6 +206,19 @@ func (o *PeerManagerOptions) Validate() error {
 		}
 	}
 
+	for _, seed := range o.DNSSeeds {
+		if seed.Protocol != DNSSeedProtocol {
+			return fmt.Errorf("DNS seed %v must have protocol %v", seed, DNSSeedProtocol)
+		}
+		if err := seed.Validate(); err != nil {
+			return fmt.Errorf("invalid DNS seed %v: %w", seed, err)
+		}
+	}
+
+	if o.DNSSeedInterval < 0 {
+		return errors.New("DNSSeedInterval can't be negative")
+	}
+
 	if o.MaxConnected > 0 && len(o.PersistentPeers) > int(o.MaxConnected) {
 		return fmt.Errorf("number of persistent peers %v can't exceed MaxConnected %v",
 			len(o.PersistentPeers), o.MaxConnected)
@@ -279,15 +325,18 @@ type PeerManager struct {
 	closeCh    chan struct{} // signal channel for Close()
 	closeOnce  sync.Once
 
-	mtx           sync.Mutex
-	store         *peerStore
-	subscriptions map[*PeerUpdates]*PeerUpdates // keyed by struct identity (address)
-	dialing       map[types.NodeID]bool         // peers being dialed (DialNext → Dialed/DialFail)
-	upgrading     map[types.NodeID]types.NodeID // peers claimed for upgrade (DialNext → Dialed/DialFail)
-	connected     map[types.NodeID]bool         // connected peers (Dialed/Accepted → Disconnected)
-	ready         map[types.NodeID]bool         // ready peers (Ready → Disconnected)
-	evict         map[types.NodeID]bool         // peers scheduled for eviction (Connected → EvictNext)
-	evicting      map[types.NodeID]bool         // peers being evicted (EvictNext → Disconnected)
+	mtx             sync.Mutex
+	store           *peerStore
+	subscriptions   map[*PeerUpdates]*PeerUpdates          // keyed by struct identity (address)
+	dialing         map[types.NodeID]bool                  // peers being dialed (DialNext → Dialed/DialFail)
+	upgrading       map[types.NodeID]types.NodeID          // peers claimed for upgrade (DialNext → Dialed/DialFail)
+	connected       map[types.NodeID]bool                  // connected peers (Dialed/Accepted → Disconnected)
+	connectedGroups map[types.NodeID]peerGroup             // network groups of connected peers (Dialed/Accepted → Disconnected)
+	connectedIPs    map[types.NodeID]net.IP                // IP addresses of connected peers, if known (Dialed/Accepted → Disconnected)
+	connectedProtos map[types.NodeID]types.ProtocolVersion // protocol versions of connected peers (SetProtocolVersion → Disconnected)
+	ready           map[types.NodeID]bool                  // ready peers (Ready → Disconnected)
+	evict           map[types.NodeID]bool                  // peers scheduled for eviction (Connected → EvictNext)
+	evicting        map[types.NodeID]bool                  // peers being evicted (EvictNext → Disconnected)
 }
 
 // NewPeerManager creates a new peer manager.
@@ -301,11 +350,6 @@ func NewPeerManager(selfID types.NodeID, peerDB dbm.DB, options PeerManagerOptio
 
 	options.optimize()
 
-	store, err := newPeerStore(peerDB)
-	if err != nil {
-		return nil, err
-	}
-
 	peerManager := &PeerManager{
 		selfID:     selfID,
 		options:    options,
@@ -314,14 +358,22 @@ func NewPeerManager(selfID types.NodeID, peerDB dbm.DB, options PeerManagerOptio
 		evictWaker: tmsync.NewWaker(),
 		closeCh:    make(chan struct{}),
 
-		store:         store,
-		dialing:       map[types.NodeID]bool{},
-		upgrading:     map[types.NodeID]types.NodeID{},
-		connected:     map[types.NodeID]bool{},
-		ready:         map[types.NodeID]bool{},
-		evict:         map[types.NodeID]bool{},
-		evicting:      map[types.NodeID]bool{},
-		subscriptions: map[*PeerUpdates]*PeerUpdates{},
+		dialing:         map[types.NodeID]bool{},
+		upgrading:       map[types.NodeID]types.NodeID{},
+		connected:       map[types.NodeID]bool{},
+		connectedGroups: map[types.NodeID]peerGroup{},
+		connectedIPs:    map[types.NodeID]net.IP{},
+		connectedProtos: map[types.NodeID]types.ProtocolVersion{},
+		ready:           map[types.NodeID]bool{},
+		evict:           map[types.NodeID]bool{},
+		evicting:        map[types.NodeID]bool{},
+		subscriptions:   map[*PeerUpdates]*PeerUpdates{},
+	}
+
+	var err error
+	peerManager.store, err = newPeerStore(peerDB, peerManager.peerGroups)
+	if err != nil {
+		return nil, err
 	}
 	if err = peerManager.configurePeers(); err != nil {
 		return nil, err
@@ -421,6 +473,11 @@ func (m *PeerManager) Add(address NodeAddress) (bool, error) {
 		return false, nil
 	}
 
+	// don't add addresses in groups that already have too many peers
+	if m.storedGroupFull(address.NodeID, m.addressGroup(address)) {
+		return false, nil
+	}
+
 	// else add the new address
 	peer.AddressInfo[address] = &peerAddressInfo{Address: address}
 	if err := m.store.Set(peer); err != nil {
@@ -478,7 +535,7 @@ func (m *PeerManager) TryDialNext() (NodeAddress, error) {
 	}
 
 	for _, peer := range m.store.Ranked() {
-		if m.dialing[peer.ID] || m.connected[peer.ID] {
+		if m.dialing[peer.ID] || m.connected[peer.ID] || m.isBanned(string(peer.ID)) {
 			continue
 		}
 
@@ -487,6 +544,13 @@ func (m *PeerManager) TryDialNext() (NodeAddress, error) {
 				continue
 			}
 
+			// Don't dial addresses in groups that already have too many
+			// connected peers.
+			group := m.addressGroup(addressInfo.Address)
+			if subnetFull, asnFull := m.groupFull(peer.ID, group); subnetFull || asnFull {
+				continue
+			}
+
 			// We now have an eligible address to dial. If we're full but have
 			// upgrade capacity (as checked above), we find a lower-scored peer
 			// we can replace and mark it as upgrading so noone else claims it.
@@ -495,7 +559,7 @@ func (m *PeerManager) TryDialNext() (NodeAddress, error) {
 			// peers, since they will all have the same or lower score than this
 			// peer (since they're ordered by score via peerStore.Ranked).
 			if m.options.MaxConnected > 0 && len(m.connected) >= int(m.options.MaxConnected) {
-				upgradeFromPeer := m.findUpgradeCandidate(peer.ID, peer.Score())
+				upgradeFromPeer := m.findUpgradeCandidate(peer.ID, peer.Score(), group)
 				if upgradeFromPeer == "" {
 					return NodeAddress{}, nil
 				}
@@ -564,7 +628,13 @@ func (m *PeerManager) DialFailed(address NodeAddress) error {
 
 // Dialed marks a peer as successfully dialed. Any further connections will be
 // rejected, and once disconnected the peer may be dialed again.
-func (m *PeerManager) Dialed(address NodeAddress) error {
+//
+// ip is the IP address the peer was reached on, if known, which is used for
+// the diversity limits (e.g. MaxConnectedPerSubnet). If nil, the address
+// hostname is used if it is an IP address. If the peer's group is full, the
+// connection is only allowed if it can replace a lower-scored peer in the
+// group, which is then evicted.
+func (m *PeerManager) Dialed(address NodeAddress, ip net.IP) error {
 	m.mtx.Lock()
 	defer m.mtx.Unlock()
 
@@ -585,6 +655,9 @@ func (m *PeerManager) Dialed(address NodeAddress) error {
 	if m.connected[address.NodeID] {
 		return fmt.Errorf("peer %v is already connected", address.NodeID)
 	}
+	if ip != nil && m.isBanned(ip.String()) {
+		return fmt.Errorf("peer IP %v is banned", ip)
+	}
 	if m.options.MaxConnected > 0 && len(m.connected) >= int(m.options.MaxConnected) {
 		if upgradeFromPeer == "" || len(m.connected) >=
 			int(m.options.MaxConnected)+int(m.options.MaxConnectedUpgrade) {
@@ -596,6 +669,19 @@ func (m *PeerManager) Dialed(address NodeAddress) error {
 	if !ok {
 		return fmt.Errorf("peer %q was removed while dialing", address.NodeID)
 	}
+
+	group := m.addressGroup(address)
+	if ip != nil {
+		group = m.ipGroup(ip)
+	}
+	var groupUpgradeFromPeer types.NodeID
+	if subnetFull, asnFull := m.groupFull(peer.ID, group); subnetFull || asnFull {
+		groupUpgradeFromPeer = m.findUpgradeCandidate(peer.ID, peer.Score(), group)
+		if groupUpgradeFromPeer == "" {
+			return fmt.Errorf("already connected to maximum number of peers in %v", group)
+		}
+	}
+
 	now := time.Now().UTC()
 	peer.LastConnected = now
 	if addressInfo, ok := peer.AddressInfo[address]; ok {
@@ -608,18 +694,28 @@ func (m *PeerManager) Dialed(address NodeAddress) error {
 		return err
 	}
 
-	if upgradeFromPeer != "" && m.options.MaxConnected > 0 &&
-		len(m.connected) >= int(m.options.MaxConnected) {
+	switch {
+	case groupUpgradeFromPeer != "":
+		// Replacing a peer in the same group also frees up a connection
+		// slot, if we were upgrading.
+		m.evict[groupUpgradeFromPeer] = true
+
+	case upgradeFromPeer != "" && m.options.MaxConnected > 0 &&
+		len(m.connected) >= int(m.options.MaxConnected):
 		// Look for an even lower-scored peer that may have appeared since we
 		// started the upgrade.
 		if p, ok := m.store.Get(upgradeFromPeer); ok {
-			if u := m.findUpgradeCandidate(p.ID, p.Score()); u != "" {
+			if u := m.findUpgradeCandidate(p.ID, p.Score(), group); u != "" {
 				upgradeFromPeer = u
 			}
 		}
 		m.evict[upgradeFromPeer] = true
 	}
 	m.connected[peer.ID] = true
+	m.connectedGroups[peer.ID] = group
+	if ip != nil {
+		m.connectedIPs[peer.ID] = ip
+	}
 	m.evictWaker.Wake()
 
 	return nil
@@ -637,12 +733,17 @@ func (m *PeerManager) Dialed(address NodeAddress) error {
 // number for outbound traffic than inbound traffic, so the peer's endpoint
 // wouldn't necessarily be an appropriate address to dial.
 //
+// The peer's remote IP address is used for the diversity limits (e.g.
+// MaxConnectedPerSubnet), and may be nil if unknown. If the peer's group is
+// full, the connection is only accepted if it can replace a lower-scored peer
+// in the group, which is then evicted.
+//
 // FIXME: When we accept a connection from a peer, we should register that
 // peer's address in the peer store so that we can dial it later. In order to do
 // that, we'll need to get the remote address after all, but as noted above that
 // can't be the remote endpoint since that will usually have the wrong port
 // number.
-func (m *PeerManager) Accepted(peerID types.NodeID) error {
+func (m *PeerManager) Accepted(peerID types.NodeID, ip net.IP) error {
 	m.mtx.Lock()
 	defer m.mtx.Unlock()
 
@@ -670,9 +771,17 @@ func (m *PeerManager) Accepted(peerID types.NodeID) error {
 	// If all connections slots are full, but we allow upgrades (and we checked
 	// above that we have upgrade capacity), then we can look for a lower-scored
 	// peer to replace and if found accept the connection anyway and evict it.
+	// Likewise if the peer's group is full, but then the replaced peer must be
+	// in the same group.
+	group := m.ipGroup(ip)
 	var upgradeFromPeer types.NodeID
-	if m.options.MaxConnected > 0 && len(m.connected) >= int(m.options.MaxConnected) {
-		upgradeFromPeer = m.findUpgradeCandidate(peer.ID, peer.Score())
+	if subnetFull, asnFull := m.groupFull(peer.ID, group); subnetFull || asnFull {
+		upgradeFromPeer = m.findUpgradeCandidate(peer.ID, peer.Score(), group)
+		if upgradeFromPeer == "" {
+			return fmt.Errorf("already connected to maximum number of peers in %v", group)
+		}
+	} else if m.options.MaxConnected > 0 && len(m.connected) >= int(m.options.MaxConnected) {
+		upgradeFromPeer = m.findUpgradeCandidate(peer.ID, peer.Score(), group)
 		if upgradeFromPeer == "" {
 			return fmt.Errorf("already connected to maximum number of peers")
 		}
@@ -684,6 +793,10 @@ func (m *PeerManager) Accepted(peerID types.NodeID) error {
 	}
 
 	m.connected[peerID] = true
+	m.connectedGroups[peerID] = group
+	if ip != nil {
+		m.connectedIPs[peerID] = ip
+	}
 	if upgradeFromPeer != "" {
 		m.evict[upgradeFromPeer] = true
 	}
@@ -769,6 +882,9 @@ func (m *PeerManager) Disconnected(peerID types.NodeID) {
 	ready := m.ready[peerID]
 
 	delete(m.connected, peerID)
+	delete(m.connectedGroups, peerID)
+	delete(m.connectedIPs, peerID)
+	delete(m.connectedProtos, peerID)
 	delete(m.upgrading, peerID)
 	delete(m.evict, peerID)
 	delete(m.evicting, peerID)
@@ -811,25 +927,25 @@ func (m *PeerManager) Advertise(peerID types.NodeID, limit uint16) []NodeAddress
 	m.mtx.Lock()
 	defer m.mtx.Unlock()
 
-	addresses := make([]NodeAddress, 0, limit)
+	// Spread the addresses across subnets and ASNs, so that peers don't all
+	// learn about the same network groups.
+	picker := m.newDiversePicker(int(limit))
 	for _, peer := range m.store.Ranked() {
 		if peer.ID == peerID {
 			continue
 		}
 
 		for nodeAddr, addressInfo := range peer.AddressInfo {
-			if len(addresses) >= int(limit) {
-				return addresses
-			}
-
 			// only add non-private NodeIDs
-			if _, ok := m.options.PrivatePeers[nodeAddr.NodeID]; !ok {
-				addresses = append(addresses, addressInfo.Address)
+			if _, ok := m.options.PrivatePeers[nodeAddr.NodeID]; ok {
+				continue
+			}
+			if !picker.offer(addressInfo.Address) {
+				return picker.addresses()
 			}
 		}
 	}
-
-	return addresses
+	return picker.addresses()
 }
 
 // Subscribe subscribes to peer updates. The caller must consume the peer
@@ -982,31 +1098,45 @@ func (m *PeerManager) Status(id types.NodeID) PeerStatus {
 }
 
 // findUpgradeCandidate looks for a lower-scored peer that we could evict
-// to make room for the given peer. Returns an empty ID if none is found.
-// If the peer is already being upgraded to, we return that same upgrade.
-// The caller must hold the mutex lock.
-func (m *PeerManager) findUpgradeCandidate(id types.NodeID, score PeerScore) types.NodeID {
+// to make room for the given peer in the given group. Returns an empty ID if
+// none is found. If the peer is already being upgraded to, we return that same
+// upgrade. If the peer's subnet or ASN is full, the candidate must be in the
+// same one, otherwise peers in the most crowded groups are evicted first,
+// and the lowest-scored of them. The caller must hold the mutex lock.
+func (m *PeerManager) findUpgradeCandidate(id types.NodeID, score PeerScore, group peerGroup) types.NodeID {
 	for from, to := range m.upgrading {
 		if to == id {
 			return from
 		}
 	}
 
+	subnetFull, asnFull := m.groupFull(id, group)
+	counts := m.connectedGroupCounts("")
+
+	var (
+		upgradeFrom types.NodeID
+		crowding    = -1
+	)
 	ranked := m.store.Ranked()
 	for i := len(ranked) - 1; i >= 0; i-- {
 		candidate := ranked[i]
+		candidateGroup := m.connectedGroups[candidate.ID]
 		switch {
 		case candidate.Score() >= score:
-			return "" // no further peers can be scored lower, due to sorting
+			return upgradeFrom // no further peers can be scored lower, due to sorting
 		case !m.connected[candidate.ID]:
 		case m.evict[candidate.ID]:
 		case m.evicting[candidate.ID]:
 		case m.upgrading[candidate.ID] != "":
+		case subnetFull && candidateGroup.subnet != group.subnet:
+		case asnFull && candidateGroup.asn != group.asn:
 		default:
-			return candidate.ID
+			if c := counts.crowding(candidateGroup); c > crowding {
+				upgradeFrom, crowding = candidate.ID, c
+			}
 		}
 	}
-	return ""
+	return upgradeFrom
 }
 
 // retryDelay calculates a dial retry delay using exponential backoff, based on
@@ -1049,6 +1179,29 @@ func (m *PeerManager) GetHeight(peerID types.NodeID) int64 {
 	return peer.Height
 }
 
+// GetProtocolVersion returns the protocol versions a connected peer announced
+// in its handshake, as reported via SetProtocolVersion, or zero versions if
+// the peer isn't connected. Reactors use it to only send messages that the
+// peer's P2P protocol version supports.
+func (m *PeerManager) GetProtocolVersion(peerID types.NodeID) types.ProtocolVersion {
+	m.mtx.Lock()
+	defer m.mtx.Unlock()
+
+	return m.connectedProtos[peerID]
+}
+
+// SetProtocolVersion stores the protocol versions a connected peer announced
+// in its handshake, making them available via GetProtocolVersion until the
+// peer disconnects. It is called by the router before marking the peer ready.
+func (m *PeerManager) SetProtocolVersion(peerID types.NodeID, version types.ProtocolVersion) {
+	m.mtx.Lock()
+	defer m.mtx.Unlock()
+
+	if m.connected[peerID] {
+		m.connectedProtos[peerID] = version
+	}
+}
+
 // SetHeight stores a peer's height, making it available via GetHeight.
 //
 // FIXME: This is a temporary workaround to share state between the consensus
@@ -1066,6 +1219,270 @@ func (m *PeerManager) SetHeight(peerID types.NodeID, height int64) error {
 	return m.store.Set(peer)
 }
 
+// SetPersistent makes a peer persistent for the lifetime of the peer manager,
+// as if it was listed in PersistentPeers.
+func (m *PeerManager) SetPersistent(peerID types.NodeID) error {
+	if err := peerID.Validate(); err != nil {
+		return err
+	}
+
+	m.mtx.Lock()
+	defer m.mtx.Unlock()
+
+	m.options.persistentPeers[peerID] = true
+	if peer, ok := m.store.Get(peerID); ok {
+		if err := m.store.Set(m.configurePeer(peer)); err != nil {
+			return err
+		}
+	}
+	m.dialWaker.Wake()
+	return nil
+}
+
+// Remove removes a peer and all of its addresses from the peer store,
+// disconnecting it if it is connected. The peer may be added again later,
+// e.g. when learned through PEX.
+func (m *PeerManager) Remove(peerID types.NodeID) error {
+	m.mtx.Lock()
+	defer m.mtx.Unlock()
+
+	if _, ok := m.store.Get(peerID); !ok {
+		return fmt.Errorf("peer %q not found", peerID)
+	}
+	if err := m.store.Delete(peerID); err != nil {
+		return err
+	}
+	if m.connected[peerID] {
+		m.evict[peerID] = true
+		m.evictWaker.Wake()
+	}
+	return nil
+}
+
+// PeerBan is a ban of a node ID or an IP address.
+type PeerBan struct {
+	// Peer is the banned node ID or IP address.
+	Peer string `json:"peer"`
+
+	// Expires is when the ban expires, or zero if the ban is permanent.
+	Expires time.Time `json:"expires"`
+}
+
+// Ban bans a peer, given as a node ID or an IP address, until the given
+// expiry time, or permanently if it is zero. A banned node ID is neither
+// dialed nor accepted, and is disconnected if it is connected. Connections
+// with a banned IP address are rejected, and the peers connected from it are
+// disconnected. Bans are persisted in the peer store.
+func (m *PeerManager) Ban(peer string, expires time.Time) (PeerBan, error) {
+	key, err := parseBan(peer)
+	if err != nil {
+		return PeerBan{}, err
+	}
+	if key == string(m.selfID) {
+		return PeerBan{}, fmt.Errorf("can't ban self (%v)", m.selfID)
+	}
+	if !expires.IsZero() && !expires.After(time.Now()) {
+		return PeerBan{}, fmt.Errorf("ban expiry %v is in the past", expires)
+	}
+
+	m.mtx.Lock()
+	defer m.mtx.Unlock()
+
+	if err := m.store.SetBan(key, expires); err != nil {
+		return PeerBan{}, err
+	}
+	if id := types.NodeID(key); m.connected[id] {
+		m.evict[id] = true
+	}
+	for id, ip := range m.connectedIPs {
+		if ip.String() == key {
+			m.evict[id] = true
+		}
+	}
+	m.evictWaker.Wake()
+	return PeerBan{Peer: key, Expires: expires}, nil
+}
+
+// Unban lifts the ban of a node ID or an IP address.
+func (m *PeerManager) Unban(peer string) error {
+	key, err := parseBan(peer)
+	if err != nil {
+		return err
+	}
+
+	m.mtx.Lock()
+	defer m.mtx.Unlock()
+
+	if !m.isBanned(key) {
+		return fmt.Errorf("peer %q is not banned", peer)
+	}
+	if err := m.store.DeleteBan(key); err != nil {
+		return err
+	}
+	m.dialWaker.Wake()
+	return nil
+}
+
+// Bans returns the bans that haven't expired, ordered by peer.
+func (m *PeerManager) Bans() []PeerBan {
+	m.mtx.Lock()
+	defer m.mtx.Unlock()
+
+	bans := []PeerBan{}
+	for key, expires := range m.store.bans {
+		if m.isBanned(key) {
+			bans = append(bans, PeerBan{Peer: key, Expires: expires})
+		}
+	}
+	sort.Slice(bans, func(i, j int) bool { return bans[i].Peer < bans[j].Peer })
+	return bans
+}
+
+// IsBanned returns true if the given node ID is banned.
+func (m *PeerManager) IsBanned(peerID types.NodeID) bool {
+	m.mtx.Lock()
+	defer m.mtx.Unlock()
+	return m.isBanned(string(peerID))
+}
+
+// IsBannedIP returns true if the given IP address is banned.
+func (m *PeerManager) IsBannedIP(ip net.IP) bool {
+	m.mtx.Lock()
+	defer m.mtx.Unlock()
+	return m.isBanned(ip.String())
+}
+
+// isBanned returns true if the given node ID or IP address is banned,
+// deleting the ban if it has expired. The caller must hold the mutex lock.
+func (m *PeerManager) isBanned(key string) bool {
+	expires, ok := m.store.bans[key]
+	if !ok {
+		return false
+	}
+	if expires.IsZero() || time.Now().Before(expires) {
+		return true
+	}
+	if err := m.store.DeleteBan(key); err != nil {
+		// the ban is checked again next time, but it has expired regardless
+		return false
+	}
+	m.dialWaker.Wake()
+	return false
+}
+
+// parseBan validates a node ID or IP address to ban, returning it in its
+// canonical form.
+func parseBan(peer string) (string, error) {
+	if id, err := types.NewNodeID(peer); err == nil {
+		return string(id), nil
+	}
+	if ip := net.ParseIP(peer); ip != nil {
+		return ip.String(), nil
+	}
+	return "", fmt.Errorf("%q is neither a node ID nor an IP address", peer)
+}
+
+// PeerRecord describes a peer in the peer store, along with the dial history
+// of its addresses. It is used to list, export and import peers.
+type PeerRecord struct {
+	ID            types.NodeID        `json:"id"`
+	LastConnected time.Time           `json:"last_connected"`
+	Addresses     []PeerAddressRecord `json:"addresses"`
+}
+
+// PeerAddressRecord describes a peer address and its dial history.
+type PeerAddressRecord struct {
+	Address         string    `json:"address"`
+	LastDialSuccess time.Time `json:"last_dial_success"`
+	LastDialFailure time.Time `json:"last_dial_failure"`
+	DialFailures    uint32    `json:"dial_failures"`
+}
+
+// Records returns the records of all peers in the peer store, ordered by
+// node ID.
+func (m *PeerManager) Records() []PeerRecord {
+	m.mtx.Lock()
+	defer m.mtx.Unlock()
+
+	records := make([]PeerRecord, 0, m.store.Size())
+	for _, peer := range m.store.List() {
+		record := PeerRecord{
+			ID:            peer.ID,
+			LastConnected: peer.LastConnected,
+			Addresses:     make([]PeerAddressRecord, 0, len(peer.AddressInfo)),
+		}
+		for _, addressInfo := range peer.AddressInfo {
+			record.Addresses = append(record.Addresses, PeerAddressRecord{
+				Address:         addressInfo.Address.String(),
+				LastDialSuccess: addressInfo.LastDialSuccess,
+				LastDialFailure: addressInfo.LastDialFailure,
+				DialFailures:    addressInfo.DialFailures,
+			})
+		}
+		sort.Slice(record.Addresses, func(i, j int) bool {
+			return record.Addresses[i].Address < record.Addresses[j].Address
+		})
+		records = append(records, record)
+	}
+	sort.Slice(records, func(i, j int) bool { return records[i].ID < records[j].ID })
+	return records
+}
+
+// Import merges peer records, e.g. exported from another node, into the peer
+// store. Addresses that are already known keep their own dial history, while
+// new ones are added along with the imported history. It returns the number of
+// addresses added. Records of ourself are skipped.
+func (m *PeerManager) Import(records []PeerRecord) (int, error) {
+	m.mtx.Lock()
+	defer m.mtx.Unlock()
+
+	added := 0
+	for _, record := range records {
+		if err := record.ID.Validate(); err != nil {
+			return added, fmt.Errorf("invalid peer ID %q: %w", record.ID, err)
+		}
+		if record.ID == m.selfID {
+			continue
+		}
+
+		peer, ok := m.store.Get(record.ID)
+		if !ok {
+			peer = m.newPeerInfo(record.ID)
+		}
+		if record.LastConnected.After(peer.LastConnected) {
+			peer.LastConnected = record.LastConnected
+		}
+		for _, a := range record.Addresses {
+			address, err := ParseNodeAddress(a.Address)
+			if err != nil {
+				return added, fmt.Errorf("invalid address %q of peer %v: %w", a.Address, record.ID, err)
+			}
+			if address.NodeID != record.ID {
+				return added, fmt.Errorf("address %q does not belong to peer %v", a.Address, record.ID)
+			}
+			if _, ok := peer.AddressInfo[address]; ok {
+				continue
+			}
+			peer.AddressInfo[address] = &peerAddressInfo{
+				Address:         address,
+				LastDialSuccess: a.LastDialSuccess,
+				LastDialFailure: a.LastDialFailure,
+				DialFailures:    a.DialFailures,
+			}
+			added++
+		}
+		if err := m.store.Set(peer); err != nil {
+			return added, err
+		}
+	}
+
+	if err := m.prunePeers(); err != nil {
+		return added, err
+	}
+	m.dialWaker.Wake()
+	return added, nil
+}
+
 // peerStore stores information about peers. It is not thread-safe, assuming it
 // is only used by PeerManager which handles concurrency control. This allows
 // the manager to execute multiple operations atomically via its own mutex.
@@ -1076,19 +1493,27 @@ func (m *PeerManager) SetHeight(peerID types.NodeID, height int64) error {
 type peerStore struct {
 	db     dbm.DB
 	peers  map[types.NodeID]*peerInfo
-	ranked []*peerInfo // cache for Ranked(), nil invalidates cache
+	bans   map[string]time.Time // banned node IDs and IPs, zero expiry if permanent
+	ranked []*peerInfo          // cache for Ranked(), nil invalidates cache
+
+	groups     groupCounts                 // number of peers in each subnet and ASN
+	peerGroups func(*peerInfo) groupCounts // subnets and ASNs of a peer
 }
 
 // newPeerStore creates a new peer store, loading all persisted peers from the
-// database into memory.
-func newPeerStore(db dbm.DB) (*peerStore, error) {
+// database into memory. It counts the peers in each network group, as given
+// by peerGroups.
+func newPeerStore(db dbm.DB, peerGroups func(*peerInfo) groupCounts) (*peerStore, error) {
 	if db == nil {
 		return nil, errors.New("no database provided")
 	}
-	store := &peerStore{db: db}
+	store := &peerStore{db: db, peerGroups: peerGroups}
 	if err := store.loadPeers(); err != nil {
 		return nil, err
 	}
+	if err := store.loadBans(); err != nil {
+		return nil, err
+	}
 	return store, nil
 }
 
@@ -1120,6 +1545,10 @@ func (s *peerStore) loadPeers() error {
 	}
 	s.peers = peers
 	s.ranked = nil // invalidate cache if populated
+	s.groups = newGroupCounts()
+	for _, peer := range peers {
+		s.groups.addGroups(s.peerGroups(peer), 1)
+	}
 	return nil
 }
 
@@ -1148,7 +1577,13 @@ func (s *peerStore) Set(peer peerInfo) error {
 		return err
 	}
 
-	if current, ok := s.peers[peer.ID]; !ok || current.Score() != peer.Score() {
+	current, ok := s.peers[peer.ID]
+	if ok {
+		s.groups.addGroups(s.peerGroups(current), -1)
+	}
+	s.groups.addGroups(s.peerGroups(&peer), 1)
+
+	if !ok || current.Score() != peer.Score() {
 		// If the peer is new, or its score changes, we invalidate the Ranked() cache.
 		s.peers[peer.ID] = &peer
 		s.ranked = nil
@@ -1164,12 +1599,14 @@ func (s *peerStore) Set(peer peerInfo) error {
 
 // Delete deletes a peer, or does nothing if it does not exist.
 func (s *peerStore) Delete(id types.NodeID) error {
-	if _, ok := s.peers[id]; !ok {
+	peer, ok := s.peers[id]
+	if !ok {
 		return nil
 	}
 	if err := s.db.Delete(keyPeerInfo(id)); err != nil {
 		return err
 	}
+	s.groups.addGroups(s.peerGroups(peer), -1)
 	delete(s.peers, id)
 	s.ranked = nil
 	return nil
@@ -1219,6 +1656,69 @@ func (s *peerStore) Size() int {
 	return len(s.peers)
 }
 
+// loadBans loads all bans from the database into memory.
+func (s *peerStore) loadBans() error {
+	bans := map[string]time.Time{}
+
+	start, end := keyPeerBanRange()
+	iter, err := s.db.Iterator(start, end)
+	if err != nil {
+		return err
+	}
+	defer iter.Close()
+	for ; iter.Valid(); iter.Next() {
+		var (
+			prefix  int64
+			peer    string
+			expires gogotypes.Int64Value
+		)
+		if _, err := orderedcode.Parse(string(iter.Key()), &prefix, &peer); err != nil {
+			return fmt.Errorf("invalid ban key: %w", err)
+		}
+		if err := proto.Unmarshal(iter.Value(), &expires); err != nil {
+			return fmt.Errorf("invalid ban Protobuf data: %w", err)
+		}
+		bans[peer] = time.Time{}
+		if expires.Value != 0 {
+			bans[peer] = time.Unix(0, expires.Value).UTC()
+		}
+	}
+	if iter.Error() != nil {
+		return iter.Error()
+	}
+	s.bans = bans
+	return nil
+}
+
+// SetBan stores a ban of a node ID or IP address, replacing any existing ban.
+func (s *peerStore) SetBan(peer string, expires time.Time) error {
+	value := gogotypes.Int64Value{}
+	if !expires.IsZero() {
+		value.Value = expires.UnixNano()
+	}
+	bz, err := proto.Marshal(&value)
+	if err != nil {
+		return err
+	}
+	if err := s.db.Set(keyPeerBan(peer), bz); err != nil {
+		return err
+	}
+	s.bans[peer] = expires
+	return nil
+}
+
+// DeleteBan deletes a ban, or does nothing if it does not exist.
+func (s *peerStore) DeleteBan(peer string) error {
+	if _, ok := s.bans[peer]; !ok {
+		return nil
+	}
+	if err := s.db.Delete(keyPeerBan(peer)); err != nil {
+		return err
+	}
+	delete(s.bans, peer)
+	return nil
+}
+
 // peerInfo contains peer information stored in a peerStore.
 type peerInfo struct {
 	ID            types.NodeID
@@ -1379,6 +1879,7 @@ func (a *peerAddressInfo) Validate() error {
 // Database key prefixes.
 const (
 	prefixPeerInfo int64 = 1
+	prefixPeerBan  int64 = 2
 )
 
 // keyPeerInfo generates a peerInfo database key.
@@ -1402,3 +1903,25 @@ func keyPeerInfoRange() ([]byte, []byte) {
 	}
 	return start, end
 }
+
+// keyPeerBan generates a ban database key.
+func keyPeerBan(peer string) []byte {
+	key, err := orderedcode.Append(nil, prefixPeerBan, peer)
+	if err != nil {
+		panic(err)
+	}
+	return key
+}
+
+// keyPeerBanRange generates start/end keys for the entire ban key range.
+func keyPeerBanRange() ([]byte, []byte) {
+	start, err := orderedcode.Append(nil, prefixPeerBan, "")
+	if err != nil {
+		panic(err)
+	}
+	end, err := orderedcode.Append(nil, prefixPeerBan, orderedcode.Infinity)
+	if err != nil {
+		panic(err)
+	}
+	return start, end
+}
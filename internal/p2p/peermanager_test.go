@@ -3,6 +3,7 @@ package p2p_test
 import (
 	"context"
 	"errors"
+	"net"
 	"strings"
 	"testing"
 	"time"
@@ -438,7 +439,7 @@ func TestPeerManager_DialNext_WakeOnDisconnected(t *testing.T) {
 	added, err := peerManager.Add(a)
 	require.NoError(t, err)
 	require.True(t, added)
-	err = peerManager.Accepted(a.NodeID)
+	err = peerManager.Accepted(a.NodeID, nil)
 	require.NoError(t, err)
 
 	dial, err := peerManager.TryDialNext()
@@ -474,7 +475,7 @@ func TestPeerManager_TryDialNext_MaxConnected(t *testing.T) {
 	dial, err := peerManager.TryDialNext()
 	require.NoError(t, err)
 	require.Equal(t, a, dial)
-	require.NoError(t, peerManager.Dialed(a))
+	require.NoError(t, peerManager.Dialed(a, nil))
 
 	// Add b and start dialing it.
 	added, err = peerManager.Add(b)
@@ -521,7 +522,7 @@ func TestPeerManager_TryDialNext_MaxConnectedUpgrade(t *testing.T) {
 	dial, err := peerManager.TryDialNext()
 	require.NoError(t, err)
 	require.Equal(t, a, dial)
-	require.NoError(t, peerManager.Dialed(a))
+	require.NoError(t, peerManager.Dialed(a, nil))
 
 	// Add b and start dialing it.
 	added, err = peerManager.Add(b)
@@ -550,7 +551,7 @@ func TestPeerManager_TryDialNext_MaxConnectedUpgrade(t *testing.T) {
 	require.Zero(t, dial)
 
 	// We go through with c's upgrade.
-	require.NoError(t, peerManager.Dialed(c))
+	require.NoError(t, peerManager.Dialed(c, nil))
 
 	// Still can't dial d.
 	dial, err = peerManager.TryDialNext()
@@ -563,7 +564,7 @@ func TestPeerManager_TryDialNext_MaxConnectedUpgrade(t *testing.T) {
 	dial, err = peerManager.TryDialNext()
 	require.NoError(t, err)
 	require.Equal(t, d, dial)
-	require.NoError(t, peerManager.Dialed(d))
+	require.NoError(t, peerManager.Dialed(d, nil))
 
 	// However, if we disconnect b (such that only c and d are connected), we
 	// should not be allowed to dial e even though there are upgrade slots,
@@ -596,7 +597,7 @@ func TestPeerManager_TryDialNext_UpgradeReservesPeer(t *testing.T) {
 	dial, err := peerManager.TryDialNext()
 	require.NoError(t, err)
 	require.Equal(t, a, dial)
-	require.NoError(t, peerManager.Dialed(a))
+	require.NoError(t, peerManager.Dialed(a, nil))
 
 	// Add b and start dialing it. This will claim a for upgrading.
 	added, err = peerManager.Add(b)
@@ -646,7 +647,7 @@ func TestPeerManager_TryDialNext_DialingConnected(t *testing.T) {
 	require.Zero(t, dial)
 
 	// Marking a as dialed will still not dispense it.
-	require.NoError(t, peerManager.Dialed(a))
+	require.NoError(t, peerManager.Dialed(a, nil))
 	dial, err = peerManager.TryDialNext()
 	require.NoError(t, err)
 	require.Zero(t, dial)
@@ -655,7 +656,7 @@ func TestPeerManager_TryDialNext_DialingConnected(t *testing.T) {
 	added, err = peerManager.Add(b)
 	require.NoError(t, err)
 	require.True(t, added)
-	require.NoError(t, peerManager.Accepted(bID))
+	require.NoError(t, peerManager.Accepted(bID, nil))
 	dial, err = peerManager.TryDialNext()
 	require.NoError(t, err)
 	require.Zero(t, dial)
@@ -753,7 +754,7 @@ func TestPeerManager_DialFailed_UnreservePeer(t *testing.T) {
 	dial, err := peerManager.TryDialNext()
 	require.NoError(t, err)
 	require.Equal(t, a, dial)
-	require.NoError(t, peerManager.Dialed(a))
+	require.NoError(t, peerManager.Dialed(a, nil))
 
 	// Add b and start dialing it. This will claim a for upgrading.
 	added, err = peerManager.Add(b)
@@ -795,8 +796,8 @@ func TestPeerManager_Dialed_Connected(t *testing.T) {
 	require.NoError(t, err)
 	require.Equal(t, a, dial)
 
-	require.NoError(t, peerManager.Dialed(a))
-	require.Error(t, peerManager.Dialed(a))
+	require.NoError(t, peerManager.Dialed(a, nil))
+	require.Error(t, peerManager.Dialed(a, nil))
 
 	// Accepting a connection from b and then trying to mark it as dialed should fail.
 	added, err = peerManager.Add(b)
@@ -806,8 +807,8 @@ func TestPeerManager_Dialed_Connected(t *testing.T) {
 	require.NoError(t, err)
 	require.Equal(t, b, dial)
 
-	require.NoError(t, peerManager.Accepted(b.NodeID))
-	require.Error(t, peerManager.Dialed(b))
+	require.NoError(t, peerManager.Accepted(b.NodeID, nil))
+	require.Error(t, peerManager.Dialed(b, nil))
 }
 
 func TestPeerManager_Dialed_Self(t *testing.T) {
@@ -841,10 +842,10 @@ func TestPeerManager_Dialed_MaxConnected(t *testing.T) {
 	added, err = peerManager.Add(b)
 	require.NoError(t, err)
 	require.True(t, added)
-	require.NoError(t, peerManager.Dialed(b))
+	require.NoError(t, peerManager.Dialed(b, nil))
 
 	// Completing the dial for a should now error.
-	require.Error(t, peerManager.Dialed(a))
+	require.Error(t, peerManager.Dialed(a, nil))
 }
 
 func TestPeerManager_Dialed_MaxConnectedUpgrade(t *testing.T) {
@@ -864,12 +865,12 @@ func TestPeerManager_Dialed_MaxConnectedUpgrade(t *testing.T) {
 	added, err := peerManager.Add(a)
 	require.NoError(t, err)
 	require.True(t, added)
-	require.NoError(t, peerManager.Dialed(a))
+	require.NoError(t, peerManager.Dialed(a, nil))
 
 	added, err = peerManager.Add(b)
 	require.NoError(t, err)
 	require.True(t, added)
-	require.NoError(t, peerManager.Dialed(b))
+	require.NoError(t, peerManager.Dialed(b, nil))
 
 	// Starting an upgrade of c should be fine.
 	added, err = peerManager.Add(c)
@@ -878,14 +879,14 @@ func TestPeerManager_Dialed_MaxConnectedUpgrade(t *testing.T) {
 	dial, err := peerManager.TryDialNext()
 	require.NoError(t, err)
 	require.Equal(t, c, dial)
-	require.NoError(t, peerManager.Dialed(c))
+	require.NoError(t, peerManager.Dialed(c, nil))
 
 	// Trying to mark d dialed should fail, since there are no more upgrade
 	// slots and a/b haven't been evicted yet.
 	added, err = peerManager.Add(d)
 	require.NoError(t, err)
 	require.True(t, added)
-	require.Error(t, peerManager.Dialed(d))
+	require.Error(t, peerManager.Dialed(d, nil))
 }
 
 func TestPeerManager_Dialed_Unknown(t *testing.T) {
@@ -895,7 +896,7 @@ func TestPeerManager_Dialed_Unknown(t *testing.T) {
 	require.NoError(t, err)
 
 	// Marking an unknown node as dialed should error.
-	require.Error(t, peerManager.Dialed(a))
+	require.Error(t, peerManager.Dialed(a, nil))
 }
 
 func TestPeerManager_Dialed_Upgrade(t *testing.T) {
@@ -914,7 +915,7 @@ func TestPeerManager_Dialed_Upgrade(t *testing.T) {
 	added, err := peerManager.Add(a)
 	require.NoError(t, err)
 	require.True(t, added)
-	require.NoError(t, peerManager.Dialed(a))
+	require.NoError(t, peerManager.Dialed(a, nil))
 
 	// Upgrading it with b should work, since b has a higher score.
 	added, err = peerManager.Add(b)
@@ -923,7 +924,7 @@ func TestPeerManager_Dialed_Upgrade(t *testing.T) {
 	dial, err := peerManager.TryDialNext()
 	require.NoError(t, err)
 	require.Equal(t, b, dial)
-	require.NoError(t, peerManager.Dialed(b))
+	require.NoError(t, peerManager.Dialed(b, nil))
 
 	// a hasn't been evicted yet, but c shouldn't be allowed to upgrade anyway
 	// since it's about to be evicted.
@@ -962,12 +963,12 @@ func TestPeerManager_Dialed_UpgradeEvenLower(t *testing.T) {
 	added, err := peerManager.Add(a)
 	require.NoError(t, err)
 	require.True(t, added)
-	require.NoError(t, peerManager.Dialed(a))
+	require.NoError(t, peerManager.Dialed(a, nil))
 
 	added, err = peerManager.Add(b)
 	require.NoError(t, err)
 	require.True(t, added)
-	require.NoError(t, peerManager.Dialed(b))
+	require.NoError(t, peerManager.Dialed(b, nil))
 
 	// Start an upgrade with c, which should pick b to upgrade (since it
 	// has score 2).
@@ -984,11 +985,11 @@ func TestPeerManager_Dialed_UpgradeEvenLower(t *testing.T) {
 	added, err = peerManager.Add(d)
 	require.NoError(t, err)
 	require.True(t, added)
-	require.NoError(t, peerManager.Accepted(d.NodeID))
+	require.NoError(t, peerManager.Accepted(d.NodeID, nil))
 
 	// Once c completes the upgrade of b, it should instead evict d,
 	// since it has en even lower score.
-	require.NoError(t, peerManager.Dialed(c))
+	require.NoError(t, peerManager.Dialed(c, nil))
 	evict, err := peerManager.TryEvictNext()
 	require.NoError(t, err)
 	require.Equal(t, d.NodeID, evict)
@@ -1014,12 +1015,12 @@ func TestPeerManager_Dialed_UpgradeNoEvict(t *testing.T) {
 	added, err := peerManager.Add(a)
 	require.NoError(t, err)
 	require.True(t, added)
-	require.NoError(t, peerManager.Dialed(a))
+	require.NoError(t, peerManager.Dialed(a, nil))
 
 	added, err = peerManager.Add(b)
 	require.NoError(t, err)
 	require.True(t, added)
-	require.NoError(t, peerManager.Dialed(b))
+	require.NoError(t, peerManager.Dialed(b, nil))
 
 	// Start an upgrade with c, which should pick a to upgrade.
 	added, err = peerManager.Add(c)
@@ -1035,7 +1036,7 @@ func TestPeerManager_Dialed_UpgradeNoEvict(t *testing.T) {
 	// Once c completes the upgrade of b, there is no longer a need to
 	// evict anything since we're at capacity.
 	// since it has en even lower score.
-	require.NoError(t, peerManager.Dialed(c))
+	require.NoError(t, peerManager.Dialed(c, nil))
 	evict, err := peerManager.TryEvictNext()
 	require.NoError(t, err)
 	require.Zero(t, evict)
@@ -1051,19 +1052,19 @@ func TestPeerManager_Accepted(t *testing.T) {
 	require.NoError(t, err)
 
 	// Accepting a connection from self should error.
-	require.Error(t, peerManager.Accepted(selfID))
+	require.Error(t, peerManager.Accepted(selfID, nil))
 
 	// Accepting a connection from a known peer should work.
 	added, err := peerManager.Add(a)
 	require.NoError(t, err)
 	require.True(t, added)
-	require.NoError(t, peerManager.Accepted(a.NodeID))
+	require.NoError(t, peerManager.Accepted(a.NodeID, nil))
 
 	// Accepting a connection from an already accepted peer should error.
-	require.Error(t, peerManager.Accepted(a.NodeID))
+	require.Error(t, peerManager.Accepted(a.NodeID, nil))
 
 	// Accepting a connection from an unknown peer should work and register it.
-	require.NoError(t, peerManager.Accepted(b.NodeID))
+	require.NoError(t, peerManager.Accepted(b.NodeID, nil))
 	require.ElementsMatch(t, []types.NodeID{a.NodeID, b.NodeID}, peerManager.Peers())
 
 	// Accepting a connection from a peer that's being dialed should work, and
@@ -1074,8 +1075,8 @@ func TestPeerManager_Accepted(t *testing.T) {
 	dial, err := peerManager.TryDialNext()
 	require.NoError(t, err)
 	require.Equal(t, c, dial)
-	require.NoError(t, peerManager.Accepted(c.NodeID))
-	require.Error(t, peerManager.Dialed(c))
+	require.NoError(t, peerManager.Accepted(c.NodeID, nil))
+	require.Error(t, peerManager.Dialed(c, nil))
 
 	// Accepting a connection from a peer that's been dialed should fail.
 	added, err = peerManager.Add(d)
@@ -1084,8 +1085,8 @@ func TestPeerManager_Accepted(t *testing.T) {
 	dial, err = peerManager.TryDialNext()
 	require.NoError(t, err)
 	require.Equal(t, d, dial)
-	require.NoError(t, peerManager.Dialed(d))
-	require.Error(t, peerManager.Accepted(d.NodeID))
+	require.NoError(t, peerManager.Dialed(d, nil))
+	require.Error(t, peerManager.Accepted(d.NodeID, nil))
 }
 
 func TestPeerManager_Accepted_MaxConnected(t *testing.T) {
@@ -1102,18 +1103,18 @@ func TestPeerManager_Accepted_MaxConnected(t *testing.T) {
 	added, err := peerManager.Add(a)
 	require.NoError(t, err)
 	require.True(t, added)
-	require.NoError(t, peerManager.Dialed(a))
+	require.NoError(t, peerManager.Dialed(a, nil))
 
 	added, err = peerManager.Add(b)
 	require.NoError(t, err)
 	require.True(t, added)
-	require.NoError(t, peerManager.Accepted(b.NodeID))
+	require.NoError(t, peerManager.Accepted(b.NodeID, nil))
 
 	// Accepting c should now fail.
 	added, err = peerManager.Add(c)
 	require.NoError(t, err)
 	require.True(t, added)
-	require.Error(t, peerManager.Accepted(c.NodeID))
+	require.Error(t, peerManager.Accepted(c.NodeID, nil))
 }
 
 func TestPeerManager_Accepted_MaxConnectedUpgrade(t *testing.T) {
@@ -1136,30 +1137,30 @@ func TestPeerManager_Accepted_MaxConnectedUpgrade(t *testing.T) {
 	added, err := peerManager.Add(a)
 	require.NoError(t, err)
 	require.True(t, added)
-	require.NoError(t, peerManager.Dialed(a))
+	require.NoError(t, peerManager.Dialed(a, nil))
 
 	// Accepting b should fail, since it's not an upgrade over a.
 	added, err = peerManager.Add(b)
 	require.NoError(t, err)
 	require.True(t, added)
-	require.Error(t, peerManager.Accepted(b.NodeID))
+	require.Error(t, peerManager.Accepted(b.NodeID, nil))
 
 	// Accepting c should work, since it upgrades a.
 	added, err = peerManager.Add(c)
 	require.NoError(t, err)
 	require.True(t, added)
-	require.NoError(t, peerManager.Accepted(c.NodeID))
+	require.NoError(t, peerManager.Accepted(c.NodeID, nil))
 
 	// a still hasn't been evicted, so accepting b should still fail.
 	_, err = peerManager.Add(b)
 	require.NoError(t, err)
-	require.Error(t, peerManager.Accepted(b.NodeID))
+	require.Error(t, peerManager.Accepted(b.NodeID, nil))
 
 	// Also, accepting d should fail, since all upgrade slots are full.
 	added, err = peerManager.Add(d)
 	require.NoError(t, err)
 	require.True(t, added)
-	require.Error(t, peerManager.Accepted(d.NodeID))
+	require.Error(t, peerManager.Accepted(d.NodeID, nil))
 }
 
 func TestPeerManager_Accepted_Upgrade(t *testing.T) {
@@ -1181,19 +1182,19 @@ func TestPeerManager_Accepted_Upgrade(t *testing.T) {
 	added, err := peerManager.Add(a)
 	require.NoError(t, err)
 	require.True(t, added)
-	require.NoError(t, peerManager.Accepted(a.NodeID))
+	require.NoError(t, peerManager.Accepted(a.NodeID, nil))
 
 	// Accepting b should work, since it upgrades a.
 	added, err = peerManager.Add(b)
 	require.NoError(t, err)
 	require.True(t, added)
-	require.NoError(t, peerManager.Accepted(b.NodeID))
+	require.NoError(t, peerManager.Accepted(b.NodeID, nil))
 
 	// c cannot get accepted, since a has been upgraded by b.
 	added, err = peerManager.Add(c)
 	require.NoError(t, err)
 	require.True(t, added)
-	require.Error(t, peerManager.Accepted(c.NodeID))
+	require.Error(t, peerManager.Accepted(c.NodeID, nil))
 
 	// This should cause a to get evicted.
 	evict, err := peerManager.TryEvictNext()
@@ -1202,7 +1203,7 @@ func TestPeerManager_Accepted_Upgrade(t *testing.T) {
 	peerManager.Disconnected(a.NodeID)
 
 	// c still cannot get accepted, since it's not scored above b.
-	require.Error(t, peerManager.Accepted(c.NodeID))
+	require.Error(t, peerManager.Accepted(c.NodeID, nil))
 }
 
 func TestPeerManager_Accepted_UpgradeDialing(t *testing.T) {
@@ -1224,7 +1225,7 @@ func TestPeerManager_Accepted_UpgradeDialing(t *testing.T) {
 	added, err := peerManager.Add(a)
 	require.NoError(t, err)
 	require.True(t, added)
-	require.NoError(t, peerManager.Accepted(a.NodeID))
+	require.NoError(t, peerManager.Accepted(a.NodeID, nil))
 
 	// Start dial upgrade from a to b.
 	added, err = peerManager.Add(b)
@@ -1239,17 +1240,17 @@ func TestPeerManager_Accepted_UpgradeDialing(t *testing.T) {
 	added, err = peerManager.Add(c)
 	require.NoError(t, err)
 	require.True(t, added)
-	require.Error(t, peerManager.Accepted(c.NodeID))
+	require.Error(t, peerManager.Accepted(c.NodeID, nil))
 
 	// However, if b connects to us while we're also trying to upgrade to it via
 	// dialing, then we accept the incoming connection as an upgrade.
-	require.NoError(t, peerManager.Accepted(b.NodeID))
+	require.NoError(t, peerManager.Accepted(b.NodeID, nil))
 
 	// This should cause a to get evicted, and the dial upgrade to fail.
 	evict, err := peerManager.TryEvictNext()
 	require.NoError(t, err)
 	require.Equal(t, a.NodeID, evict)
-	require.Error(t, peerManager.Dialed(b))
+	require.Error(t, peerManager.Dialed(b, nil))
 }
 
 func TestPeerManager_Ready(t *testing.T) {
@@ -1266,7 +1267,7 @@ func TestPeerManager_Ready(t *testing.T) {
 	added, err := peerManager.Add(a)
 	require.NoError(t, err)
 	require.True(t, added)
-	require.NoError(t, peerManager.Accepted(a.NodeID))
+	require.NoError(t, peerManager.Accepted(a.NodeID, nil))
 	require.Equal(t, p2p.PeerStatusDown, peerManager.Status(a.NodeID))
 
 	// Marking a as ready should transition it to PeerStatusUp and send an update.
@@ -1297,7 +1298,7 @@ func TestPeerManager_EvictNext(t *testing.T) {
 	added, err := peerManager.Add(a)
 	require.NoError(t, err)
 	require.True(t, added)
-	require.NoError(t, peerManager.Accepted(a.NodeID))
+	require.NoError(t, peerManager.Accepted(a.NodeID, nil))
 	peerManager.Ready(a.NodeID)
 
 	// Since there are no peers to evict, EvictNext should block until timeout.
@@ -1330,7 +1331,7 @@ func TestPeerManager_EvictNext_WakeOnError(t *testing.T) {
 	added, err := peerManager.Add(a)
 	require.NoError(t, err)
 	require.True(t, added)
-	require.NoError(t, peerManager.Accepted(a.NodeID))
+	require.NoError(t, peerManager.Accepted(a.NodeID, nil))
 	peerManager.Ready(a.NodeID)
 
 	// Spawn a goroutine to error a peer after a delay.
@@ -1362,7 +1363,7 @@ func TestPeerManager_EvictNext_WakeOnUpgradeDialed(t *testing.T) {
 	added, err := peerManager.Add(a)
 	require.NoError(t, err)
 	require.True(t, added)
-	require.NoError(t, peerManager.Accepted(a.NodeID))
+	require.NoError(t, peerManager.Accepted(a.NodeID, nil))
 	peerManager.Ready(a.NodeID)
 
 	// Spawn a goroutine to upgrade to b with a delay.
@@ -1374,7 +1375,7 @@ func TestPeerManager_EvictNext_WakeOnUpgradeDialed(t *testing.T) {
 		dial, err := peerManager.TryDialNext()
 		require.NoError(t, err)
 		require.Equal(t, b, dial)
-		require.NoError(t, peerManager.Dialed(b))
+		require.NoError(t, peerManager.Dialed(b, nil))
 	}()
 
 	// This will block until peer is upgraded above.
@@ -1400,13 +1401,13 @@ func TestPeerManager_EvictNext_WakeOnUpgradeAccepted(t *testing.T) {
 	added, err := peerManager.Add(a)
 	require.NoError(t, err)
 	require.True(t, added)
-	require.NoError(t, peerManager.Accepted(a.NodeID))
+	require.NoError(t, peerManager.Accepted(a.NodeID, nil))
 	peerManager.Ready(a.NodeID)
 
 	// Spawn a goroutine to upgrade b with a delay.
 	go func() {
 		time.Sleep(200 * time.Millisecond)
-		require.NoError(t, peerManager.Accepted(b.NodeID))
+		require.NoError(t, peerManager.Accepted(b.NodeID, nil))
 	}()
 
 	// This will block until peer is upgraded above.
@@ -1432,7 +1433,7 @@ func TestPeerManager_TryEvictNext(t *testing.T) {
 	require.Zero(t, evict)
 
 	// Connecting to a won't evict anything either.
-	require.NoError(t, peerManager.Accepted(a.NodeID))
+	require.NoError(t, peerManager.Accepted(a.NodeID, nil))
 	peerManager.Ready(a.NodeID)
 
 	// But if a errors it should be evicted.
@@ -1470,14 +1471,14 @@ func TestPeerManager_Disconnected(t *testing.T) {
 	added, err := peerManager.Add(a)
 	require.NoError(t, err)
 	require.True(t, added)
-	require.NoError(t, peerManager.Accepted(a.NodeID))
+	require.NoError(t, peerManager.Accepted(a.NodeID, nil))
 	peerManager.Disconnected(a.NodeID)
 	require.Empty(t, sub.Updates())
 
 	// Disconnecting a ready peer sends a status update.
 	_, err = peerManager.Add(a)
 	require.NoError(t, err)
-	require.NoError(t, peerManager.Accepted(a.NodeID))
+	require.NoError(t, peerManager.Accepted(a.NodeID, nil))
 	peerManager.Ready(a.NodeID)
 	require.Equal(t, p2p.PeerStatusUp, peerManager.Status(a.NodeID))
 	require.NotEmpty(t, sub.Updates())
@@ -1529,7 +1530,7 @@ func TestPeerManager_Errored(t *testing.T) {
 	require.NoError(t, err)
 	require.Zero(t, evict)
 
-	require.NoError(t, peerManager.Accepted(a.NodeID))
+	require.NoError(t, peerManager.Accepted(a.NodeID, nil))
 	peerManager.Ready(a.NodeID)
 	evict, err = peerManager.TryEvictNext()
 	require.NoError(t, err)
@@ -1558,7 +1559,7 @@ func TestPeerManager_Subscribe(t *testing.T) {
 	require.Empty(t, sub.Updates())
 
 	// Inbound connection.
-	require.NoError(t, peerManager.Accepted(a.NodeID))
+	require.NoError(t, peerManager.Accepted(a.NodeID, nil))
 	require.Empty(t, sub.Updates())
 
 	peerManager.Ready(a.NodeID)
@@ -1575,7 +1576,7 @@ func TestPeerManager_Subscribe(t *testing.T) {
 	require.Equal(t, a, dial)
 	require.Empty(t, sub.Updates())
 
-	require.NoError(t, peerManager.Dialed(a))
+	require.NoError(t, peerManager.Dialed(a, nil))
 	require.Empty(t, sub.Updates())
 
 	peerManager.Ready(a.NodeID)
@@ -1615,7 +1616,7 @@ func TestPeerManager_Subscribe_Close(t *testing.T) {
 	added, err := peerManager.Add(a)
 	require.NoError(t, err)
 	require.True(t, added)
-	require.NoError(t, peerManager.Accepted(a.NodeID))
+	require.NoError(t, peerManager.Accepted(a.NodeID, nil))
 	require.Empty(t, sub.Updates())
 
 	peerManager.Ready(a.NodeID)
@@ -1647,7 +1648,7 @@ func TestPeerManager_Subscribe_Broadcast(t *testing.T) {
 	added, err := peerManager.Add(a)
 	require.NoError(t, err)
 	require.True(t, added)
-	require.NoError(t, peerManager.Accepted(a.NodeID))
+	require.NoError(t, peerManager.Accepted(a.NodeID, nil))
 	peerManager.Ready(a.NodeID)
 
 	expectUp := p2p.PeerUpdate{NodeID: a.NodeID, Status: p2p.PeerStatusUp}
@@ -1760,6 +1761,29 @@ func TestPeerManager_Advertise(t *testing.T) {
 	}, peerManager.Advertise(dID, 2))
 }
 
+func TestPeerManager_SetProtocolVersion_GetProtocolVersion(t *testing.T) {
+	a := p2p.NodeAddress{Protocol: "memory", NodeID: types.NodeID(strings.Repeat("a", 40))}
+	version := types.ProtocolVersion{P2P: 9, Block: 11}
+
+	peerManager, err := p2p.NewPeerManager(selfID, dbm.NewMemDB(), p2p.PeerManagerOptions{})
+	require.NoError(t, err)
+	added, err := peerManager.Add(a)
+	require.NoError(t, err)
+	require.True(t, added)
+
+	// Versions are only recorded for connected peers.
+	peerManager.SetProtocolVersion(a.NodeID, version)
+	require.Zero(t, peerManager.GetProtocolVersion(a.NodeID))
+
+	require.NoError(t, peerManager.Accepted(a.NodeID, nil))
+	peerManager.SetProtocolVersion(a.NodeID, version)
+	require.Equal(t, version, peerManager.GetProtocolVersion(a.NodeID))
+
+	// They're forgotten when the peer disconnects.
+	peerManager.Disconnected(a.NodeID)
+	require.Zero(t, peerManager.GetProtocolVersion(a.NodeID))
+}
+
 func TestPeerManager_SetHeight_GetHeight(t *testing.T) {
 	a := p2p.NodeAddress{Protocol: "memory", NodeID: types.NodeID(strings.Repeat("a", 40))}
 	b := p2p.NodeAddress{Protocol: "memory", NodeID: types.NodeID(strings.Repeat("b", 40))}
@@ -1795,3 +1819,197 @@ func TestPeerManager_SetHeight_GetHeight(t *testing.T) {
 	require.Zero(t, peerManager.GetHeight(a.NodeID))
 	require.Zero(t, peerManager.GetHeight(b.NodeID))
 }
+
+func TestPeerManager_Remove(t *testing.T) {
+	a := p2p.NodeAddress{Protocol: "memory", NodeID: types.NodeID(strings.Repeat("a", 40))}
+	b := p2p.NodeAddress{Protocol: "memory", NodeID: types.NodeID(strings.Repeat("b", 40))}
+
+	peerManager, err := p2p.NewPeerManager(selfID, dbm.NewMemDB(), p2p.PeerManagerOptions{})
+	require.NoError(t, err)
+
+	// Removing an unknown peer errors.
+	require.Error(t, peerManager.Remove(a.NodeID))
+
+	added, err := peerManager.Add(a)
+	require.NoError(t, err)
+	require.True(t, added)
+	added, err = peerManager.Add(b)
+	require.NoError(t, err)
+	require.True(t, added)
+
+	// Removing an unconnected peer just deletes it.
+	require.NoError(t, peerManager.Remove(b.NodeID))
+	require.Equal(t, []types.NodeID{a.NodeID}, peerManager.Peers())
+
+	// Removing a connected peer also evicts it.
+	require.NoError(t, peerManager.Accepted(a.NodeID, nil))
+	require.NoError(t, peerManager.Remove(a.NodeID))
+	require.Empty(t, peerManager.Peers())
+	evict, err := peerManager.TryEvictNext()
+	require.NoError(t, err)
+	require.Equal(t, a.NodeID, evict)
+}
+
+func TestPeerManager_Ban(t *testing.T) {
+	a := p2p.NodeAddress{Protocol: "memory", NodeID: types.NodeID(strings.Repeat("a", 40))}
+	b := p2p.NodeAddress{Protocol: "memory", NodeID: types.NodeID(strings.Repeat("b", 40))}
+
+	db := dbm.NewMemDB()
+	peerManager, err := p2p.NewPeerManager(selfID, db, p2p.PeerManagerOptions{})
+	require.NoError(t, err)
+
+	added, err := peerManager.Add(a)
+	require.NoError(t, err)
+	require.True(t, added)
+	added, err = peerManager.Add(b)
+	require.NoError(t, err)
+	require.True(t, added)
+	require.NoError(t, peerManager.Accepted(b.NodeID, nil))
+
+	// Bans must be valid node IDs or IPs, and can't be for ourself or
+	// already expired.
+	_, err = peerManager.Ban("foo", time.Time{})
+	require.Error(t, err)
+	_, err = peerManager.Ban(string(selfID), time.Time{})
+	require.Error(t, err)
+	_, err = peerManager.Ban("1.2.3.4", time.Now().Add(-time.Second))
+	require.Error(t, err)
+
+	// Banning a peer prevents dialing it, and evicts it if connected.
+	_, err = peerManager.Ban(strings.ToUpper(string(a.NodeID)), time.Time{})
+	require.NoError(t, err)
+	_, err = peerManager.Ban(string(b.NodeID), time.Time{})
+	require.NoError(t, err)
+	require.True(t, peerManager.IsBanned(a.NodeID))
+	dial, err := peerManager.TryDialNext()
+	require.NoError(t, err)
+	require.Zero(t, dial)
+	evict, err := peerManager.TryEvictNext()
+	require.NoError(t, err)
+	require.Equal(t, b.NodeID, evict)
+
+	// IP bans can expire.
+	ban, err := peerManager.Ban("::ffff:1.2.3.4", time.Now().Add(100*time.Millisecond))
+	require.NoError(t, err)
+	require.Equal(t, "1.2.3.4", ban.Peer)
+	require.True(t, peerManager.IsBannedIP(net.IPv4(1, 2, 3, 4)))
+	require.False(t, peerManager.IsBannedIP(net.IPv4(1, 2, 3, 5)))
+	require.Eventually(t, func() bool {
+		return !peerManager.IsBannedIP(net.IPv4(1, 2, 3, 4))
+	}, time.Second, 10*time.Millisecond)
+
+	// Bans are persisted.
+	peerManager.Close()
+	peerManager, err = p2p.NewPeerManager(selfID, db, p2p.PeerManagerOptions{})
+	require.NoError(t, err)
+	require.Equal(t, []p2p.PeerBan{
+		{Peer: string(a.NodeID)},
+		{Peer: string(b.NodeID)},
+	}, peerManager.Bans())
+
+	// Unbanning a peer allows dialing it again.
+	require.Error(t, peerManager.Unban("1.2.3.4"))
+	require.NoError(t, peerManager.Unban(string(a.NodeID)))
+	require.False(t, peerManager.IsBanned(a.NodeID))
+	dial, err = peerManager.TryDialNext()
+	require.NoError(t, err)
+	require.Equal(t, a, dial)
+}
+
+func TestPeerManager_BanIP(t *testing.T) {
+	a := p2p.NodeAddress{Protocol: "memory", NodeID: types.NodeID(strings.Repeat("a", 40))}
+	b := p2p.NodeAddress{Protocol: "memory", NodeID: types.NodeID(strings.Repeat("b", 40))}
+	c := p2p.NodeAddress{Protocol: "memory", NodeID: types.NodeID(strings.Repeat("c", 40))}
+
+	peerManager, err := p2p.NewPeerManager(selfID, dbm.NewMemDB(), p2p.PeerManagerOptions{})
+	require.NoError(t, err)
+
+	// a is dialed and b accepted at the IP to ban, c at another one.
+	added, err := peerManager.Add(a)
+	require.NoError(t, err)
+	require.True(t, added)
+	dial, err := peerManager.TryDialNext()
+	require.NoError(t, err)
+	require.Equal(t, a, dial)
+	require.NoError(t, peerManager.Dialed(a, net.IPv4(1, 2, 3, 4)))
+	require.NoError(t, peerManager.Accepted(b.NodeID, net.IPv4(1, 2, 3, 4)))
+	require.NoError(t, peerManager.Accepted(c.NodeID, net.IPv4(1, 2, 3, 5)))
+
+	// Banning the IP evicts the peers connected from it.
+	_, err = peerManager.Ban("1.2.3.4", time.Time{})
+	require.NoError(t, err)
+	evicted := []types.NodeID{}
+	for {
+		evict, err := peerManager.TryEvictNext()
+		require.NoError(t, err)
+		if evict == "" {
+			break
+		}
+		evicted = append(evicted, evict)
+		peerManager.Disconnected(evict)
+	}
+	require.ElementsMatch(t, []types.NodeID{a.NodeID, b.NodeID}, evicted)
+
+	// Peers can't be dialed at the banned IP either.
+	dial, err = peerManager.TryDialNext()
+	require.NoError(t, err)
+	require.Contains(t, []p2p.NodeAddress{a, b}, dial)
+	require.Error(t, peerManager.Dialed(dial, net.IPv4(1, 2, 3, 4)))
+}
+
+func TestPeerManager_Records_Import(t *testing.T) {
+	a := p2p.NodeAddress{Protocol: "memory", NodeID: types.NodeID(strings.Repeat("a", 40))}
+	aTCP := p2p.NodeAddress{Protocol: "tcp", NodeID: a.NodeID, Hostname: "127.0.0.1", Port: 26656}
+	b := p2p.NodeAddress{Protocol: "memory", NodeID: types.NodeID(strings.Repeat("b", 40))}
+
+	source, err := p2p.NewPeerManager(selfID, dbm.NewMemDB(), p2p.PeerManagerOptions{})
+	require.NoError(t, err)
+	for _, addr := range []p2p.NodeAddress{a, aTCP, b} {
+		added, err := source.Add(addr)
+		require.NoError(t, err)
+		require.True(t, added)
+	}
+	dial, err := source.TryDialNext()
+	require.NoError(t, err)
+	require.NoError(t, source.DialFailed(dial))
+
+	records := source.Records()
+	require.Len(t, records, 2)
+	require.Equal(t, a.NodeID, records[0].ID)
+	require.Len(t, records[0].Addresses, 2)
+	require.Equal(t, b.NodeID, records[1].ID)
+
+	var failures uint32
+	for _, record := range records {
+		for _, addr := range record.Addresses {
+			failures += addr.DialFailures
+		}
+	}
+	require.EqualValues(t, 1, failures)
+
+	// Importing into a node that already knows one of the addresses only
+	// adds the others, along with their dial history.
+	target, err := p2p.NewPeerManager(selfID, dbm.NewMemDB(), p2p.PeerManagerOptions{})
+	require.NoError(t, err)
+	added, err := target.Add(a)
+	require.NoError(t, err)
+	require.True(t, added)
+
+	n, err := target.Import(records)
+	require.NoError(t, err)
+	require.Equal(t, 2, n)
+	require.ElementsMatch(t, []p2p.NodeAddress{a, aTCP}, target.Addresses(a.NodeID))
+	require.Equal(t, []p2p.NodeAddress{b}, target.Addresses(b.NodeID))
+
+	// Importing the same records again adds nothing.
+	n, err = target.Import(records)
+	require.NoError(t, err)
+	require.Zero(t, n)
+
+	// Records with addresses of other peers are rejected.
+	_, err = target.Import([]p2p.PeerRecord{{
+		ID:        a.NodeID,
+		Addresses: []p2p.PeerAddressRecord{{Address: b.String()}},
+	}})
+	require.Error(t, err)
+}
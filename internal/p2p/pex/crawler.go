@@ -0,0 +1,567 @@
+package pex
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/orderedcode"
+	dbm "github.com/tendermint/tm-db"
+
+	"github.com/tendermint/tendermint/internal/p2p"
+	tmjson "github.com/tendermint/tendermint/libs/json"
+	"github.com/tendermint/tendermint/libs/log"
+	"github.com/tendermint/tendermint/libs/service"
+	"github.com/tendermint/tendermint/types"
+)
+
+var _ service.Service = (*Crawler)(nil)
+
+const (
+	// crawlCheckInterval is the longest time between checks for peers that
+	// are due to be crawled.
+	crawlCheckInterval = time.Minute
+
+	// prefixCrawledPeer is the database key prefix of crawled peers.
+	prefixCrawledPeer int64 = 1
+)
+
+// Prober dials and handshakes with peers without routing their connections.
+// It is implemented by p2p.Router.
+type Prober interface {
+	Probe(ctx context.Context, address p2p.NodeAddress) (types.NodeInfo, error)
+}
+
+// CrawlerOptions specifies options for a Crawler.
+type CrawlerOptions struct {
+	// Interval is how often each peer in the peer store is dialed. Defaults
+	// to 30 minutes.
+	Interval time.Duration
+
+	// DialTimeout is the timeout for dialing and handshaking with a peer
+	// address. Defaults to 10 seconds.
+	DialTimeout time.Duration
+
+	// MaxConcurrentDials is the maximum number of peers dialed at the same
+	// time. Defaults to 16.
+	MaxConcurrentDials int
+}
+
+// Validate validates the options, filling in defaults.
+func (o *CrawlerOptions) Validate() error {
+	switch {
+	case o.Interval < 0:
+		return errors.New("crawl interval can't be negative")
+	case o.Interval == 0:
+		o.Interval = 30 * time.Minute
+	}
+	switch {
+	case o.DialTimeout < 0:
+		return errors.New("crawl dial timeout can't be negative")
+	case o.DialTimeout == 0:
+		o.DialTimeout = 10 * time.Second
+	}
+	switch {
+	case o.MaxConcurrentDials < 0:
+		return errors.New("crawl concurrent dials can't be negative")
+	case o.MaxConcurrentDials == 0:
+		o.MaxConcurrentDials = 16
+	}
+	return nil
+}
+
+// CrawledPeer is what a Crawler knows about a peer.
+type CrawledPeer struct {
+	ID types.NodeID `json:"id"`
+
+	// Address is the last address the peer was reachable on, or the last
+	// address dialed if it never was.
+	Address string `json:"address"`
+
+	// NodeInfo is the NodeInfo the peer sent in its last handshake, if any.
+	NodeInfo *types.NodeInfo `json:"node_info,omitempty"`
+
+	// Compatible is true if the peer's NodeInfo is compatible with ours,
+	// i.e. it runs the same protocol versions on the same network.
+	Compatible bool `json:"compatible"`
+
+	FirstSeen   time.Time `json:"first_seen"`   // first successful dial
+	LastSeen    time.Time `json:"last_seen"`    // last successful dial
+	LastAttempt time.Time `json:"last_attempt"` // last dial
+	UpSince     time.Time `json:"up_since"`     // first successful dial since the last failure
+	LastError   string    `json:"last_error,omitempty"`
+
+	DialAttempts  uint64 `json:"dial_attempts"`
+	DialSuccesses uint64 `json:"dial_successes"`
+}
+
+// Reachable returns true if the last dial to the peer succeeded.
+func (p CrawledPeer) Reachable() bool {
+	return !p.UpSince.IsZero()
+}
+
+// Uptime returns how long the peer has been reachable without interruption, as
+// of the last dial.
+func (p CrawledPeer) Uptime() time.Duration {
+	if !p.Reachable() {
+		return 0
+	}
+	return p.LastSeen.Sub(p.UpSince)
+}
+
+// SuccessRate returns the fraction of dials to the peer that succeeded.
+func (p CrawledPeer) SuccessRate() float64 {
+	if p.DialAttempts == 0 {
+		return 0
+	}
+	return float64(p.DialSuccesses) / float64(p.DialAttempts)
+}
+
+// rank returns the advertisement rank of the peer, lower is better: reachable
+// and compatible peers, then peers that haven't been dialed yet, then
+// reachable but incompatible peers, and finally unreachable peers.
+func (p *CrawledPeer) rank() int {
+	switch {
+	case p == nil || p.DialAttempts == 0:
+		return 1
+	case p.Reachable() && p.Compatible:
+		return 0
+	case p.Reachable():
+		return 2
+	default:
+		return 3
+	}
+}
+
+// version returns the software version of the peer's last NodeInfo, if any.
+func (p *CrawledPeer) version() string {
+	if p.NodeInfo == nil {
+		return ""
+	}
+	return p.NodeInfo.Version
+}
+
+// compareVersions compares two software versions of the form
+// [v]major.minor.patch[-prerelease], returning -1, 0 or 1 if a is older than,
+// the same as or newer than b. Numeric components are compared as numbers, a
+// prerelease is older than its release, and versions that don't parse are
+// older than those that do.
+func compareVersions(a, b string) int {
+	av, apre, aok := parseVersion(a)
+	bv, bpre, bok := parseVersion(b)
+	if !aok || !bok {
+		return compareBools(aok, bok)
+	}
+	for i := 0; i < len(av) || i < len(bv); i++ {
+		var x, y uint64
+		if i < len(av) {
+			x = av[i]
+		}
+		if i < len(bv) {
+			y = bv[i]
+		}
+		switch {
+		case x < y:
+			return -1
+		case x > y:
+			return 1
+		}
+	}
+	switch {
+	case apre == bpre:
+		return 0
+	case apre == "":
+		return 1
+	case bpre == "":
+		return -1
+	case apre < bpre:
+		return -1
+	default:
+		return 1
+	}
+}
+
+// parseVersion splits a software version into its numeric components and its
+// prerelease suffix.
+func parseVersion(version string) ([]uint64, string, bool) {
+	version = strings.TrimPrefix(strings.TrimSpace(version), "v")
+	version, pre := splitPrerelease(version)
+	if version == "" {
+		return nil, "", false
+	}
+	parts := strings.Split(version, ".")
+	numbers := make([]uint64, len(parts))
+	for i, part := range parts {
+		n, err := strconv.ParseUint(part, 10, 64)
+		if err != nil {
+			return nil, "", false
+		}
+		numbers[i] = n
+	}
+	return numbers, pre, true
+}
+
+// splitPrerelease splits the prerelease and build suffixes off a version.
+func splitPrerelease(version string) (string, string) {
+	if i := strings.IndexByte(version, '+'); i >= 0 {
+		version = version[:i]
+	}
+	if i := strings.IndexByte(version, '-'); i >= 0 {
+		return version[:i], version[i+1:]
+	}
+	return version, ""
+}
+
+// compareBools orders false before true.
+func compareBools(a, b bool) int {
+	switch {
+	case a == b:
+		return 0
+	case a:
+		return 1
+	default:
+		return -1
+	}
+}
+
+// Crawler is used by seed nodes to map the network. It keeps dialing every
+// peer in the peer store, independently of the router's connections, and
+// records the peers' NodeInfo, uptime and dial success rate in a database.
+//
+// The PEX reactor can use the Crawler as its Advertiser, to favour reachable
+// peers running the most recent compatible software in its responses.
+type Crawler struct {
+	service.BaseService
+
+	peerManager *p2p.PeerManager
+	prober      Prober
+	db          dbm.DB
+	options     CrawlerOptions
+
+	mtx   sync.RWMutex
+	peers map[types.NodeID]*CrawledPeer
+
+	cancel context.CancelFunc
+	doneCh chan struct{}
+}
+
+// NewCrawler creates a new crawler, loading previously crawled peers from the
+// database.
+func NewCrawler(
+	logger log.Logger,
+	peerManager *p2p.PeerManager,
+	prober Prober,
+	db dbm.DB,
+	options CrawlerOptions,
+) (*Crawler, error) {
+	if err := options.Validate(); err != nil {
+		return nil, err
+	}
+
+	c := &Crawler{
+		peerManager: peerManager,
+		prober:      prober,
+		db:          db,
+		options:     options,
+		peers:       map[types.NodeID]*CrawledPeer{},
+	}
+	if err := c.loadPeers(); err != nil {
+		return nil, err
+	}
+
+	c.BaseService = *service.NewBaseService(logger, "Crawler", c)
+	return c, nil
+}
+
+// OnStart starts crawling.
+func (c *Crawler) OnStart() error {
+	var ctx context.Context
+	ctx, c.cancel = context.WithCancel(context.Background())
+	c.doneCh = make(chan struct{})
+	go c.crawlRoutine(ctx)
+	return nil
+}
+
+// OnStop stops crawling, waiting for ongoing dials to complete.
+func (c *Crawler) OnStop() {
+	c.cancel()
+	<-c.doneCh
+}
+
+// crawlRoutine crawls the peers that are due until the context is canceled.
+func (c *Crawler) crawlRoutine(ctx context.Context) {
+	defer close(c.doneCh)
+
+	wait := crawlCheckInterval
+	if c.options.Interval < wait {
+		wait = c.options.Interval
+	}
+
+	for {
+		c.Crawl(ctx)
+
+		timer := time.NewTimer(wait)
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		}
+	}
+}
+
+// Crawl dials all peers in the peer store that haven't been dialed within the
+// crawl interval, and records the results. It returns the number of peers
+// dialed. Records of peers that have been removed from the peer store are
+// deleted.
+func (c *Crawler) Crawl(ctx context.Context) int {
+	now := time.Now()
+	peerIDs := c.peerManager.Peers()
+
+	var due []types.NodeID
+	c.mtx.Lock()
+	known := make(map[types.NodeID]bool, len(peerIDs))
+	for _, id := range peerIDs {
+		known[id] = true
+		if peer, ok := c.peers[id]; ok && now.Sub(peer.LastAttempt) < c.options.Interval {
+			continue
+		}
+		due = append(due, id)
+	}
+	for id := range c.peers {
+		if !known[id] {
+			delete(c.peers, id)
+			if err := c.db.Delete(keyCrawledPeer(id)); err != nil {
+				c.Logger.Error("failed to delete crawled peer", "peer", id, "err", err)
+			}
+		}
+	}
+	c.mtx.Unlock()
+
+	var (
+		wg      sync.WaitGroup
+		sem     = make(chan struct{}, c.options.MaxConcurrentDials)
+		crawled int
+	)
+LOOP:
+	for _, id := range due {
+		select {
+		case sem <- struct{}{}:
+		case <-ctx.Done():
+			break LOOP
+		}
+		crawled++
+		wg.Add(1)
+		go func(id types.NodeID) {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+			c.crawlPeer(ctx, id)
+		}(id)
+	}
+	wg.Wait()
+
+	return crawled
+}
+
+// crawlPeer dials the addresses of a peer until one succeeds, and records the
+// result.
+func (c *Crawler) crawlPeer(ctx context.Context, id types.NodeID) {
+	addresses := c.peerManager.Addresses(id)
+	if len(addresses) == 0 {
+		return
+	}
+
+	var (
+		address p2p.NodeAddress
+		err     error
+	)
+	for _, address = range addresses {
+		var nodeInfo types.NodeInfo
+		nodeInfo, err = c.probe(ctx, address)
+		if ctx.Err() != nil {
+			return
+		}
+
+		var rejected p2p.ErrRejected
+		switch {
+		case err == nil:
+			c.recordSuccess(id, address, nodeInfo, nil)
+			return
+		case errors.As(err, &rejected) && rejected.IsIncompatible():
+			c.recordSuccess(id, address, nodeInfo, err)
+			return
+		}
+		c.Logger.Debug("failed to crawl peer", "peer", address, "err", err)
+	}
+	c.recordFailure(id, address, err)
+}
+
+func (c *Crawler) probe(ctx context.Context, address p2p.NodeAddress) (types.NodeInfo, error) {
+	ctx, cancel := context.WithTimeout(ctx, c.options.DialTimeout)
+	defer cancel()
+	return c.prober.Probe(ctx, address)
+}
+
+// recordSuccess records a successful dial of a peer. err is the reason the
+// peer is incompatible, if it is.
+func (c *Crawler) recordSuccess(id types.NodeID, address p2p.NodeAddress, nodeInfo types.NodeInfo, err error) {
+	now := time.Now().UTC()
+
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+
+	peer := c.getOrMakePeer(id)
+	peer.Address = address.String()
+	peer.NodeInfo = &nodeInfo
+	peer.Compatible = err == nil
+	peer.LastError = ""
+	if err != nil {
+		peer.LastError = err.Error()
+	}
+	if peer.FirstSeen.IsZero() {
+		peer.FirstSeen = now
+	}
+	if peer.UpSince.IsZero() {
+		peer.UpSince = now
+	}
+	peer.LastSeen = now
+	peer.LastAttempt = now
+	peer.DialAttempts++
+	peer.DialSuccesses++
+	c.savePeer(peer)
+}
+
+// recordFailure records a failed dial of a peer.
+func (c *Crawler) recordFailure(id types.NodeID, address p2p.NodeAddress, err error) {
+	now := time.Now().UTC()
+
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+
+	peer := c.getOrMakePeer(id)
+	if !peer.Reachable() || peer.Address == "" {
+		peer.Address = address.String()
+	}
+	peer.UpSince = time.Time{}
+	peer.LastAttempt = now
+	peer.LastError = err.Error()
+	peer.DialAttempts++
+	c.savePeer(peer)
+}
+
+// getOrMakePeer returns the record of a peer, creating it if needed. The
+// caller must hold the mutex.
+func (c *Crawler) getOrMakePeer(id types.NodeID) *CrawledPeer {
+	peer, ok := c.peers[id]
+	if !ok {
+		peer = &CrawledPeer{ID: id}
+		c.peers[id] = peer
+	}
+	return peer
+}
+
+// savePeer saves a peer record to the database. The caller must hold the
+// mutex.
+func (c *Crawler) savePeer(peer *CrawledPeer) {
+	bz, err := tmjson.Marshal(peer)
+	if err == nil {
+		err = c.db.Set(keyCrawledPeer(peer.ID), bz)
+	}
+	if err != nil {
+		c.Logger.Error("failed to save crawled peer", "peer", peer.ID, "err", err)
+	}
+}
+
+// loadPeers loads the crawled peers from the database.
+func (c *Crawler) loadPeers() error {
+	start, end := keyCrawledPeerRange()
+	iter, err := c.db.Iterator(start, end)
+	if err != nil {
+		return err
+	}
+	defer iter.Close()
+
+	for ; iter.Valid(); iter.Next() {
+		peer := &CrawledPeer{}
+		if err := tmjson.Unmarshal(iter.Value(), peer); err != nil {
+			return fmt.Errorf("invalid crawled peer data: %w", err)
+		}
+		c.peers[peer.ID] = peer
+	}
+	return iter.Error()
+}
+
+// CrawledPeers returns the records of all crawled peers, ordered by node ID.
+func (c *Crawler) CrawledPeers() []CrawledPeer {
+	c.mtx.RLock()
+	defer c.mtx.RUnlock()
+
+	peers := make([]CrawledPeer, 0, len(c.peers))
+	for _, peer := range c.peers {
+		peers = append(peers, *peer)
+	}
+	sort.Slice(peers, func(i, j int) bool { return peers[i].ID < peers[j].ID })
+	return peers
+}
+
+// Advertise returns a list of peer addresses to advertise to a peer, like
+// PeerManager.Advertise, but ordered by what the crawler knows about them:
+// reachable and compatible peers first, then peers that haven't been crawled
+// yet, then incompatible peers and finally unreachable peers. Peers with the
+// same rank are ordered by software version, newest first, then by dial
+// success rate, then by peer score.
+func (c *Crawler) Advertise(peerID types.NodeID, limit uint16) []p2p.NodeAddress {
+	addresses := c.peerManager.Advertise(peerID, math.MaxUint16)
+
+	c.mtx.RLock()
+	sort.SliceStable(addresses, func(i, j int) bool {
+		a, b := c.peers[addresses[i].NodeID], c.peers[addresses[j].NodeID]
+		if a.rank() != b.rank() {
+			return a.rank() < b.rank()
+		}
+		if a == nil || b == nil {
+			return false
+		}
+		if cmp := compareVersions(a.version(), b.version()); cmp != 0 {
+			return cmp > 0
+		}
+		return a.SuccessRate() > b.SuccessRate()
+	})
+	c.mtx.RUnlock()
+
+	if len(addresses) > int(limit) {
+		addresses = addresses[:limit]
+	}
+	return addresses
+}
+
+// keyCrawledPeer generates a crawled peer database key.
+func keyCrawledPeer(id types.NodeID) []byte {
+	key, err := orderedcode.Append(nil, prefixCrawledPeer, string(id))
+	if err != nil {
+		panic(err)
+	}
+	return key
+}
+
+// keyCrawledPeerRange generates start/end keys for the entire crawled peer key
+// range.
+func keyCrawledPeerRange() ([]byte, []byte) {
+	start, err := orderedcode.Append(nil, prefixCrawledPeer, "")
+	if err != nil {
+		panic(err)
+	}
+	end, err := orderedcode.Append(nil, prefixCrawledPeer, orderedcode.Infinity)
+	if err != nil {
+		panic(err)
+	}
+	return start, end
+}
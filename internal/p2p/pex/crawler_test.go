@@ -0,0 +1,181 @@
+package pex_test
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	dbm "github.com/tendermint/tm-db"
+
+	"github.com/tendermint/tendermint/internal/p2p"
+	"github.com/tendermint/tendermint/internal/p2p/pex"
+	"github.com/tendermint/tendermint/libs/log"
+	"github.com/tendermint/tendermint/types"
+)
+
+// testProber is a pex.Prober with canned responses by node ID. Peers without
+// a response are unreachable.
+type testProber struct {
+	mtx    sync.Mutex
+	infos  map[types.NodeID]types.NodeInfo
+	probed []p2p.NodeAddress
+}
+
+func (p *testProber) Probe(ctx context.Context, address p2p.NodeAddress) (types.NodeInfo, error) {
+	p.mtx.Lock()
+	defer p.mtx.Unlock()
+
+	p.probed = append(p.probed, address)
+	info, ok := p.infos[address.NodeID]
+	if !ok {
+		return types.NodeInfo{}, errors.New("connection refused")
+	}
+	return info, nil
+}
+
+func TestCrawler_Crawl(t *testing.T) {
+	ctx := context.Background()
+	up := p2p.NodeAddress{Protocol: "memory", NodeID: newNodeID(t, "a")}
+	down := p2p.NodeAddress{Protocol: "memory", NodeID: newNodeID(t, "b")}
+
+	peerManager, err := p2p.NewPeerManager(newNodeID(t, "f"), dbm.NewMemDB(), p2p.PeerManagerOptions{})
+	require.NoError(t, err)
+	for _, address := range []p2p.NodeAddress{up, down} {
+		added, err := peerManager.Add(address)
+		require.NoError(t, err)
+		require.True(t, added)
+	}
+
+	prober := &testProber{infos: map[types.NodeID]types.NodeInfo{
+		up.NodeID: {NodeID: up.NodeID, Moniker: "up", Version: "1.0.0", Channels: []byte{pex.PexChannel}},
+	}}
+	db := dbm.NewMemDB()
+	crawler, err := pex.NewCrawler(log.TestingLogger(), peerManager, prober, db,
+		pex.CrawlerOptions{Interval: time.Hour})
+	require.NoError(t, err)
+
+	require.Equal(t, 2, crawler.Crawl(ctx))
+
+	peers := crawler.CrawledPeers()
+	require.Len(t, peers, 2)
+	byID := map[types.NodeID]pex.CrawledPeer{}
+	for _, peer := range peers {
+		byID[peer.ID] = peer
+	}
+
+	require.True(t, byID[up.NodeID].Reachable())
+	require.True(t, byID[up.NodeID].Compatible)
+	require.Equal(t, "up", byID[up.NodeID].NodeInfo.Moniker)
+	require.Equal(t, up.String(), byID[up.NodeID].Address)
+	require.EqualValues(t, 1, byID[up.NodeID].DialSuccesses)
+	require.Equal(t, 1.0, byID[up.NodeID].SuccessRate())
+
+	require.False(t, byID[down.NodeID].Reachable())
+	require.Nil(t, byID[down.NodeID].NodeInfo)
+	require.Equal(t, "connection refused", byID[down.NodeID].LastError)
+	require.EqualValues(t, 1, byID[down.NodeID].DialAttempts)
+	require.Zero(t, byID[down.NodeID].SuccessRate())
+
+	// Peers are not dialed again within the crawl interval.
+	require.Zero(t, crawler.Crawl(ctx))
+	require.Len(t, prober.probed, 2)
+
+	// The records are persisted.
+	crawler, err = pex.NewCrawler(log.TestingLogger(), peerManager, prober, db, pex.CrawlerOptions{})
+	require.NoError(t, err)
+	require.Equal(t, peers, crawler.CrawledPeers())
+
+	// Records of peers removed from the peer store are deleted.
+	require.NoError(t, peerManager.Remove(down.NodeID))
+	crawler.Crawl(ctx)
+	require.Len(t, crawler.CrawledPeers(), 1)
+	crawler, err = pex.NewCrawler(log.TestingLogger(), peerManager, prober, db, pex.CrawlerOptions{})
+	require.NoError(t, err)
+	require.Len(t, crawler.CrawledPeers(), 1)
+}
+
+func TestCrawler_Uptime(t *testing.T) {
+	ctx := context.Background()
+	address := p2p.NodeAddress{Protocol: "memory", NodeID: newNodeID(t, "a")}
+
+	peerManager, err := p2p.NewPeerManager(newNodeID(t, "f"), dbm.NewMemDB(), p2p.PeerManagerOptions{})
+	require.NoError(t, err)
+	_, err = peerManager.Add(address)
+	require.NoError(t, err)
+
+	prober := &testProber{infos: map[types.NodeID]types.NodeInfo{
+		address.NodeID: {NodeID: address.NodeID},
+	}}
+	crawler, err := pex.NewCrawler(log.TestingLogger(), peerManager, prober, dbm.NewMemDB(),
+		pex.CrawlerOptions{Interval: time.Nanosecond})
+	require.NoError(t, err)
+
+	require.Equal(t, 1, crawler.Crawl(ctx))
+	time.Sleep(10 * time.Millisecond)
+	require.Equal(t, 1, crawler.Crawl(ctx))
+
+	peer := crawler.CrawledPeers()[0]
+	require.True(t, peer.Reachable())
+	require.GreaterOrEqual(t, peer.Uptime(), 10*time.Millisecond)
+
+	// A failed dial resets the uptime, but not the dial history.
+	delete(prober.infos, address.NodeID)
+	require.Equal(t, 1, crawler.Crawl(ctx))
+
+	peer = crawler.CrawledPeers()[0]
+	require.False(t, peer.Reachable())
+	require.Zero(t, peer.Uptime())
+	require.EqualValues(t, 3, peer.DialAttempts)
+	require.EqualValues(t, 2, peer.DialSuccesses)
+}
+
+func TestCrawler_Advertise(t *testing.T) {
+	ctx := context.Background()
+	var (
+		up        = p2p.NodeAddress{Protocol: "memory", NodeID: newNodeID(t, "a")}
+		down      = p2p.NodeAddress{Protocol: "memory", NodeID: newNodeID(t, "b")}
+		uncrawled = p2p.NodeAddress{Protocol: "memory", NodeID: newNodeID(t, "c")}
+		upRC      = p2p.NodeAddress{Protocol: "memory", NodeID: newNodeID(t, "d")}
+		upOld     = p2p.NodeAddress{Protocol: "memory", NodeID: newNodeID(t, "9")}
+	)
+
+	peerManager, err := p2p.NewPeerManager(newNodeID(t, "f"), dbm.NewMemDB(), p2p.PeerManagerOptions{})
+	require.NoError(t, err)
+	for _, address := range []p2p.NodeAddress{down, upOld, upRC, up} {
+		_, err = peerManager.Add(address)
+		require.NoError(t, err)
+	}
+
+	prober := &testProber{infos: map[types.NodeID]types.NodeInfo{
+		up.NodeID:    {NodeID: up.NodeID, Version: "v0.35.10"},
+		upRC.NodeID:  {NodeID: upRC.NodeID, Version: "0.35.10-rc1"},
+		upOld.NodeID: {NodeID: upOld.NodeID, Version: "0.35.9"},
+	}}
+	crawler, err := pex.NewCrawler(log.TestingLogger(), peerManager, prober, dbm.NewMemDB(), pex.CrawlerOptions{})
+	require.NoError(t, err)
+	require.Equal(t, 4, crawler.Crawl(ctx))
+
+	// The uncrawled peer is added after the crawl.
+	_, err = peerManager.Add(uncrawled)
+	require.NoError(t, err)
+
+	// Reachable peers are ordered by software version, newest first.
+	require.Equal(t, []p2p.NodeAddress{up, upRC, upOld, uncrawled, down}, crawler.Advertise(newNodeID(t, "e"), 10))
+	require.Equal(t, []p2p.NodeAddress{up, upRC}, crawler.Advertise(newNodeID(t, "e"), 2))
+	require.Equal(t, []p2p.NodeAddress{upRC, upOld, uncrawled, down}, crawler.Advertise(up.NodeID, 10))
+}
+
+func TestCrawlerOptions_Validate(t *testing.T) {
+	options := pex.CrawlerOptions{}
+	require.NoError(t, options.Validate())
+	require.Equal(t, 30*time.Minute, options.Interval)
+	require.Equal(t, 10*time.Second, options.DialTimeout)
+	require.Equal(t, 16, options.MaxConcurrentDials)
+
+	require.Error(t, (&pex.CrawlerOptions{Interval: -1}).Validate())
+	require.Error(t, (&pex.CrawlerOptions{DialTimeout: -1}).Validate())
+	require.Error(t, (&pex.CrawlerOptions{MaxConcurrentDials: -1}).Validate())
+}
@@ -52,6 +52,12 @@ const (
 	fullCapacityInterval = 10 * time.Minute
 )
 
+// Advertiser selects the peer addresses to advertise to a peer. It is
+// implemented by p2p.PeerManager and Crawler.
+type Advertiser interface {
+	Advertise(peerID types.NodeID, limit uint16) []p2p.NodeAddress
+}
+
 // TODO: We should decide whether we want channel descriptors to be housed
 // within each reactor (as they are now) or, considering that the reactor doesn't
 // really need to care about the channel descriptors, if they should be housed
@@ -79,6 +85,7 @@ type Reactor struct {
 	service.BaseService
 
 	peerManager *p2p.PeerManager
+	advertiser  Advertiser
 	pexCh       *p2p.Channel
 	peerUpdates *p2p.PeerUpdates
 	closeCh     chan struct{}
@@ -123,6 +130,7 @@ func NewReactor(
 
 	r := &Reactor{
 		peerManager:          peerManager,
+		advertiser:           peerManager,
 		pexCh:                pexCh,
 		peerUpdates:          peerUpdates,
 		closeCh:              make(chan struct{}),
@@ -135,6 +143,12 @@ func NewReactor(
 	return r
 }
 
+// SetAdvertiser sets the Advertiser used to respond to PEX requests, instead
+// of the peer manager. It must be called before the reactor is started.
+func (r *Reactor) SetAdvertiser(advertiser Advertiser) {
+	r.advertiser = advertiser
+}
+
 // OnStart starts separate go routines for each p2p Channel and listens for
 // envelopes on each. In addition, it also listens for peer updates and handles
 // messages on that p2p channel accordingly. The caller must be sure to execute
@@ -218,9 +232,9 @@ func (r *Reactor) handlePexMessage(envelope p2p.Envelope) error {
 			return err
 		}
 
-		// request peers from the peer manager and parse the NodeAddresses into
+		// request peers from the advertiser and parse the NodeAddresses into
 		// URL strings
-		nodeAddresses := r.peerManager.Advertise(envelope.From, maxAddresses)
+		nodeAddresses := r.advertiser.Advertise(envelope.From, maxAddresses)
 		pexAddresses := make([]protop2p.PexAddress, len(nodeAddresses))
 		for idx, addr := range nodeAddresses {
 			pexAddresses[idx] = protop2p.PexAddress{
@@ -8,11 +8,13 @@ import (
 	"math/rand"
 	"net"
 	"runtime"
+	"sort"
 	"sync"
 	"time"
 
 	"github.com/gogo/protobuf/proto"
 
+	"github.com/tendermint/tendermint/config"
 	"github.com/tendermint/tendermint/crypto"
 	"github.com/tendermint/tendermint/libs/log"
 	"github.com/tendermint/tendermint/libs/service"
@@ -166,6 +168,17 @@ type RouterOptions struct {
 	// are used to dial peers. This defaults to the value of
 	// runtime.NumCPU.
 	NumConcurrentDials func() int
+
+	// PeerSendRate and PeerRecvRate limit the bandwidth used with each
+	// peer by the router, in bytes per second, regardless of the
+	// transport. Channels with a MinBandwidthShare are guaranteed that
+	// share of the limit. 0 means unlimited.
+	PeerSendRate int64
+	PeerRecvRate int64
+
+	// ChannelRateLimits limits the bandwidth used by individual channels
+	// with each peer, in addition to the peer limits.
+	ChannelRateLimits map[ChannelID]config.ChannelRateLimit
 }
 
 const (
@@ -196,6 +209,15 @@ func (o *RouterOptions) Validate() error {
 		o.MaxIncomingConnectionAttempts = 100
 	}
 
+	if o.PeerSendRate < 0 || o.PeerRecvRate < 0 {
+		return errors.New("peer rate limits can't be negative")
+	}
+	for chID, limit := range o.ChannelRateLimits {
+		if limit.SendRate < 0 || limit.RecvRate < 0 {
+			return fmt.Errorf("rate limits of channel %v can't be negative", chID)
+		}
+	}
+
 	return nil
 }
 
@@ -363,6 +385,15 @@ func (r *Router) OpenChannel(chDesc *ChannelDescriptor) (*Channel, error) {
 	if _, ok := r.channelQueues[id]; ok {
 		return nil, fmt.Errorf("channel %v already exists", id)
 	}
+
+	share := chDesc.MinBandwidthShare
+	for _, desc := range r.chDescs {
+		share += desc.MinBandwidthShare
+	}
+	if chDesc.MinBandwidthShare < 0 || share > 1 {
+		return nil, fmt.Errorf("invalid minimum bandwidth share %v of channel %v, the shares of all channels must add up to at most 1",
+			chDesc.MinBandwidthShare, id)
+	}
 	r.chDescs = append(r.chDescs, chDesc)
 
 	messageType := chDesc.MessageType
@@ -520,6 +551,10 @@ func (r *Router) numConccurentDials() int {
 }
 
 func (r *Router) filterPeersIP(ctx context.Context, ip net.IP, port uint16) error {
+	if r.peerManager.IsBannedIP(ip) {
+		return fmt.Errorf("peer IP %v is banned", ip)
+	}
+
 	if r.options.FilterPeerByIP == nil {
 		return nil
 	}
@@ -528,6 +563,10 @@ func (r *Router) filterPeersIP(ctx context.Context, ip net.IP, port uint16) erro
 }
 
 func (r *Router) filterPeersID(ctx context.Context, id types.NodeID) error {
+	if r.peerManager.IsBanned(id) {
+		return fmt.Errorf("peer %v is banned", id)
+	}
+
 	if r.options.FilterPeerByID == nil {
 		return nil
 	}
@@ -634,13 +673,13 @@ func (r *Router) openConnection(ctx context.Context, conn Connection) {
 		return
 	}
 
-	if err := r.runWithPeerMutex(func() error { return r.peerManager.Accepted(peerInfo.NodeID) }); err != nil {
+	if err := r.runWithPeerMutex(func() error { return r.peerManager.Accepted(peerInfo.NodeID, incomingIP) }); err != nil {
 		r.logger.Error("failed to accept connection",
 			"op", "incoming/accepted", "peer", peerInfo.NodeID, "err", err)
 		return
 	}
 
-	r.routePeer(peerInfo.NodeID, conn, toChannelIDs(peerInfo.Channels))
+	r.routePeer(peerInfo.NodeID, peerInfo.ProtocolVersion, conn, toChannelIDs(peerInfo.Channels))
 }
 
 // dialPeers maintains outbound connections to peers by dialing them.
@@ -729,7 +768,7 @@ func (r *Router) connectPeer(ctx context.Context, address NodeAddress) {
 		return
 	}
 
-	if err := r.runWithPeerMutex(func() error { return r.peerManager.Dialed(address) }); err != nil {
+	if err := r.runWithPeerMutex(func() error { return r.peerManager.Dialed(address, conn.RemoteEndpoint().IP) }); err != nil {
 		r.logger.Error("failed to dial peer",
 			"op", "outgoing/dialing", "peer", address.NodeID, "err", err)
 		conn.Close()
@@ -737,7 +776,7 @@ func (r *Router) connectPeer(ctx context.Context, address NodeAddress) {
 	}
 
 	// routePeer (also) calls connection close
-	go r.routePeer(address.NodeID, conn, toChannelIDs(peerInfo.Channels))
+	go r.routePeer(address.NodeID, peerInfo.ProtocolVersion, conn, toChannelIDs(peerInfo.Channels))
 }
 
 func (r *Router) getOrMakeQueue(peerID types.NodeID, channels channelIDs) queue {
@@ -843,6 +882,21 @@ func (r *Router) handshakePeer(
 	return peerInfo, nil
 }
 
+// Probe dials the given address and handshakes with the peer, then closes the
+// connection again without routing it or reporting it to the peer manager. It
+// is used by crawlers to check whether peers are reachable. If the peer is
+// reachable but incompatible with us, its NodeInfo is returned along with an
+// ErrRejected error.
+func (r *Router) Probe(ctx context.Context, address NodeAddress) (types.NodeInfo, error) {
+	conn, err := r.dialPeer(ctx, address)
+	if err != nil {
+		return types.NodeInfo{}, err
+	}
+	defer conn.Close()
+
+	return r.handshakePeer(ctx, conn, address.NodeID)
+}
+
 func (r *Router) runWithPeerMutex(fn func() error) error {
 	r.peerMtx.Lock()
 	defer r.peerMtx.Unlock()
@@ -852,8 +906,14 @@ func (r *Router) runWithPeerMutex(fn func() error) error {
 // routePeer routes inbound and outbound messages between a peer and the reactor
 // channels. It will close the given connection and send queue when done, or if
 // they are closed elsewhere it will cause this method to shut down and return.
-func (r *Router) routePeer(peerID types.NodeID, conn Connection, channels channelIDs) {
+func (r *Router) routePeer(
+	peerID types.NodeID,
+	version types.ProtocolVersion,
+	conn Connection,
+	channels channelIDs,
+) {
 	r.metrics.Peers.Add(1)
+	r.peerManager.SetProtocolVersion(peerID, version)
 	r.peerManager.Ready(peerID)
 
 	sendQueue := r.getOrMakeQueue(peerID, channels)
@@ -871,14 +931,15 @@ func (r *Router) routePeer(peerID types.NodeID, conn Connection, channels channe
 
 	r.logger.Info("peer connected", "peer", peerID, "endpoint", conn)
 
+	sendLimiter, recvLimiter := r.newBandwidthLimiters()
 	errCh := make(chan error, 2)
 
 	go func() {
-		errCh <- r.receivePeer(peerID, conn)
+		errCh <- r.receivePeer(peerID, conn, recvLimiter)
 	}()
 
 	go func() {
-		errCh <- r.sendPeer(peerID, conn, sendQueue)
+		errCh <- r.sendPeer(peerID, conn, sendQueue, sendLimiter)
 	}()
 
 	err := <-errCh
@@ -900,9 +961,32 @@ func (r *Router) routePeer(peerID types.NodeID, conn Connection, channels channe
 	}
 }
 
+// newBandwidthLimiters creates the limiters of the bandwidth sent to and
+// received from a peer, which are nil if it is unlimited.
+func (r *Router) newBandwidthLimiters() (send, recv *bandwidthLimiter) {
+	r.channelMtx.RLock()
+	shares := make(map[ChannelID]float64, len(r.chDescs))
+	for _, chDesc := range r.chDescs {
+		shares[chDesc.ID] = chDesc.MinBandwidthShare
+	}
+	r.channelMtx.RUnlock()
+
+	sendRates := make(map[ChannelID]int64, len(r.options.ChannelRateLimits))
+	recvRates := make(map[ChannelID]int64, len(r.options.ChannelRateLimits))
+	for chID, limit := range r.options.ChannelRateLimits {
+		sendRates[chID] = limit.SendRate
+		recvRates[chID] = limit.RecvRate
+	}
+
+	now := time.Now()
+	return newBandwidthLimiter(r.options.PeerSendRate, shares, sendRates, now),
+		newBandwidthLimiter(r.options.PeerRecvRate, shares, recvRates, now)
+}
+
 // receivePeer receives inbound messages from a peer, deserializes them and
-// passes them on to the appropriate channel.
-func (r *Router) receivePeer(peerID types.NodeID, conn Connection) error {
+// passes them on to the appropriate channel. If limiter is given, messages
+// are not received faster than it allows.
+func (r *Router) receivePeer(peerID types.NodeID, conn Connection, limiter *bandwidthLimiter) error {
 	for {
 		chID, bz, err := conn.ReceiveMessage()
 		if err != nil {
@@ -919,6 +1003,20 @@ func (r *Router) receivePeer(peerID types.NodeID, conn Connection) error {
 			continue
 		}
 
+		r.metrics.RouterChannelRecvBytes.With("ch_id", fmt.Sprint(chID)).Add(float64(len(bz)))
+
+		// Holding off the next read applies backpressure to the peer
+		// through the transport.
+		if limiter != nil {
+			for wait := limiter.reserve(chID, len(bz), time.Now()); wait > 0; wait = limiter.reserve(chID, len(bz), time.Now()) {
+				select {
+				case <-time.After(wait):
+				case <-r.stopCh:
+					return nil
+				}
+			}
+		}
+
 		msg := proto.Clone(messageType)
 		if err := proto.Unmarshal(bz, msg); err != nil {
 			r.logger.Error("message decoding failed, dropping message", "peer", peerID, "err", err)
@@ -945,6 +1043,7 @@ func (r *Router) receivePeer(peerID types.NodeID, conn Connection) error {
 			r.logger.Debug("received message", "peer", peerID, "message", msg)
 
 		case <-queue.closed():
+			r.metrics.RouterChannelDroppedMsgs.With("ch_id", fmt.Sprint(chID)).Add(1)
 			r.logger.Debug("channel closed, dropping message", "peer", peerID, "channel", chID)
 
 		case <-r.stopCh:
@@ -953,30 +1052,149 @@ func (r *Router) receivePeer(peerID types.NodeID, conn Connection) error {
 	}
 }
 
-// sendPeer sends queued messages to a peer.
-func (r *Router) sendPeer(peerID types.NodeID, conn Connection, peerQueue queue) error {
+// sendPeer sends queued messages to a peer. If limiter is given, messages are
+// not sent faster than it allows, see sendPeerLimited.
+func (r *Router) sendPeer(peerID types.NodeID, conn Connection, peerQueue queue, limiter *bandwidthLimiter) error {
+	if limiter != nil {
+		return r.sendPeerLimited(peerID, conn, peerQueue, limiter)
+	}
+
 	for {
 		start := time.Now().UTC()
 
 		select {
 		case envelope := <-peerQueue.dequeue():
 			r.metrics.RouterPeerQueueRecv.Observe(time.Since(start).Seconds())
-			if envelope.Message == nil {
-				r.logger.Error("dropping nil message", "peer", peerID)
+			bz, ok := r.marshalEnvelope(peerID, envelope)
+			if !ok {
 				continue
 			}
 
-			bz, err := proto.Marshal(envelope.Message)
-			if err != nil {
-				r.logger.Error("failed to marshal message", "peer", peerID, "err", err)
-				continue
+			if err := r.sendMessage(conn, envelope, bz); err != nil {
+				return err
 			}
 
-			if err = conn.SendMessage(envelope.channelID, bz); err != nil {
-				return err
+		case <-peerQueue.closed():
+			return nil
+
+		case <-r.stopCh:
+			return nil
+		}
+	}
+}
+
+// minPendingSendCapacity is the minimum number of messages per channel held
+// back by sendPeerLimited before dropping the oldest ones.
+const minPendingSendCapacity = 16
+
+// pendingChannel holds the outbound messages of a channel that are waiting
+// for bandwidth.
+type pendingChannel struct {
+	id       ChannelID
+	priority int
+	capacity int
+	lossless bool
+	messages []pendingMessage
+}
+
+type pendingMessage struct {
+	envelope Envelope
+	bz       []byte
+}
+
+// sendPeerLimited sends queued messages to a peer as fast as the bandwidth
+// limiter allows. Messages waiting for bandwidth are held back per channel,
+// and the channel with the highest priority that the limiter lets through is
+// sent first, so a channel at its limit does not hold up the others. When a
+// channel holds back more messages than its send queue capacity, the oldest
+// ones are dropped, except for lossless channels: while one of them is at
+// capacity, no more messages are taken from the peer queue.
+func (r *Router) sendPeerLimited(
+	peerID types.NodeID,
+	conn Connection,
+	peerQueue queue,
+	limiter *bandwidthLimiter,
+) error {
+	// channels is ordered by descending priority.
+	var channels []*pendingChannel
+
+	for {
+		var wait time.Duration
+		for sent := true; sent; {
+			sent, wait = false, 0
+			for _, ch := range channels {
+				if len(ch.messages) == 0 {
+					continue
+				}
+				msg := ch.messages[0]
+				if w := limiter.reserve(ch.id, len(msg.bz), time.Now()); w > 0 {
+					if wait == 0 || w < wait {
+						wait = w
+					}
+					continue
+				}
+
+				ch.messages[0] = pendingMessage{}
+				ch.messages = ch.messages[1:]
+				if err := r.sendMessage(conn, msg.envelope, msg.bz); err != nil {
+					return err
+				}
+				sent = true
+				break
+			}
+		}
+
+		var (
+			timer  *time.Timer
+			waitCh <-chan time.Time
+		)
+		if wait > 0 {
+			timer = time.NewTimer(wait)
+			waitCh = timer.C
+		}
+
+		dequeueCh := peerQueue.dequeue()
+		for _, ch := range channels {
+			if ch.lossless && len(ch.messages) >= ch.capacity {
+				dequeueCh = nil
+				break
+			}
+		}
+
+		start := time.Now().UTC()
+
+		select {
+		case envelope := <-dequeueCh:
+			r.metrics.RouterPeerQueueRecv.Observe(time.Since(start).Seconds())
+			bz, ok := r.marshalEnvelope(peerID, envelope)
+			if !ok {
+				break
+			}
+
+			var ch *pendingChannel
+			for _, c := range channels {
+				if c.id == envelope.channelID {
+					ch = c
+					break
+				}
+			}
+			if ch == nil {
+				ch = r.newPendingChannel(envelope.channelID)
+				channels = append(channels, ch)
+				sort.SliceStable(channels, func(i, j int) bool {
+					return channels[i].priority > channels[j].priority
+				})
+			}
+
+			ch.messages = append(ch.messages, pendingMessage{envelope: envelope, bz: bz})
+			if len(ch.messages) > ch.capacity && !ch.lossless {
+				ch.messages[0] = pendingMessage{}
+				ch.messages = ch.messages[1:]
+				r.metrics.RouterChannelDroppedMsgs.With("ch_id", fmt.Sprint(ch.id)).Add(1)
+				r.logger.Error("channel bandwidth exceeded, dropping message", "peer", peerID, "channel", ch.id)
 			}
 
-			r.logger.Debug("sent message", "peer", envelope.To, "message", envelope.Message)
+		case <-waitCh:
 
 		case <-peerQueue.closed():
 			return nil
@@ -984,7 +1202,54 @@ func (r *Router) sendPeer(peerID types.NodeID, conn Connection, peerQueue queue)
 		case <-r.stopCh:
 			return nil
 		}
+
+		if timer != nil {
+			timer.Stop()
+		}
+	}
+}
+
+func (r *Router) newPendingChannel(chID ChannelID) *pendingChannel {
+	ch := &pendingChannel{id: chID, capacity: minPendingSendCapacity}
+
+	r.channelMtx.RLock()
+	defer r.channelMtx.RUnlock()
+	for _, chDesc := range r.chDescs {
+		if chDesc.ID == chID {
+			ch.priority = chDesc.Priority
+			ch.lossless = chDesc.Lossless
+			if chDesc.SendQueueCapacity > ch.capacity {
+				ch.capacity = chDesc.SendQueueCapacity
+			}
+		}
 	}
+	return ch
+}
+
+// marshalEnvelope marshals an outbound envelope, logging and returning false
+// if it can't be sent.
+func (r *Router) marshalEnvelope(peerID types.NodeID, envelope Envelope) ([]byte, bool) {
+	if envelope.Message == nil {
+		r.logger.Error("dropping nil message", "peer", peerID)
+		return nil, false
+	}
+
+	bz, err := proto.Marshal(envelope.Message)
+	if err != nil {
+		r.logger.Error("failed to marshal message", "peer", peerID, "err", err)
+		return nil, false
+	}
+	return bz, true
+}
+
+func (r *Router) sendMessage(conn Connection, envelope Envelope, bz []byte) error {
+	if err := conn.SendMessage(envelope.channelID, bz); err != nil {
+		return err
+	}
+
+	r.metrics.RouterChannelSendBytes.With("ch_id", fmt.Sprint(envelope.channelID)).Add(float64(len(bz)))
+	r.logger.Debug("sent message", "peer", envelope.To, "message", envelope.Message)
+	return nil
 }
 
 // evictPeers evicts connected peers as requested by the peer manager.
@@ -1042,6 +1307,7 @@ func (r *Router) OnStart() error {
 
 	go r.dialPeers()
 	go r.evictPeers()
+	go r.refreshDNSSeeds()
 
 	for _, transport := range r.transports {
 		go r.acceptPeers(transport)
@@ -1050,6 +1316,42 @@ func (r *Router) OnStart() error {
 	return nil
 }
 
+// refreshDNSSeeds resolves the DNS seeds of the peer manager every
+// DNSSeedInterval, or only once if 0, until the router is stopped. Failures
+// are retried sooner.
+func (r *Router) refreshDNSSeeds() {
+	if len(r.peerManager.options.DNSSeeds) == 0 {
+		return
+	}
+
+	for {
+		ctx, cancel := context.WithTimeout(context.Background(), dnsSeedTimeout)
+		added, err := r.peerManager.RefreshDNSSeeds(ctx)
+		cancel()
+		if err != nil {
+			r.logger.Error("failed to refresh DNS seeds", "added", added, "err", err)
+		} else {
+			r.logger.Debug("refreshed DNS seeds", "added", added)
+		}
+
+		wait := r.peerManager.options.DNSSeedInterval
+		switch {
+		case err != nil && (wait == 0 || wait > dnsSeedRetryInterval):
+			wait = dnsSeedRetryInterval
+		case err == nil && wait == 0:
+			return
+		}
+
+		timer := time.NewTimer(wait)
+		select {
+		case <-timer.C:
+		case <-r.stopCh:
+			timer.Stop()
+			return
+		}
+	}
+}
+
 // OnStop implements service.Service.
 //
 // All channels must be closed by OpenChannel() callers before stopping the
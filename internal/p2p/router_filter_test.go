@@ -4,12 +4,16 @@ import (
 	"context"
 	"errors"
 	"net"
+	"strings"
 	"testing"
 	"time"
 
 	"github.com/stretchr/testify/require"
+	dbm "github.com/tendermint/tm-db"
+
 	"github.com/tendermint/tendermint/internal/libs/sync"
 	"github.com/tendermint/tendermint/libs/log"
+	"github.com/tendermint/tendermint/types"
 )
 
 func TestConnectionFiltering(t *testing.T) {
@@ -17,9 +21,13 @@ func TestConnectionFiltering(t *testing.T) {
 	defer cancel()
 	logger := log.TestingLogger()
 
+	peerManager, err := NewPeerManager(types.NodeID(strings.Repeat("a", 40)), dbm.NewMemDB(), PeerManagerOptions{})
+	require.NoError(t, err)
+
 	filterByIPCount := 0
 	router := &Router{
 		logger:      logger,
+		peerManager: peerManager,
 		connTracker: newConnTracker(1, time.Second),
 		options: RouterOptions{
 			FilterPeerByIP: func(ctx context.Context, ip net.IP, port uint16) error {
@@ -32,3 +40,25 @@ func TestConnectionFiltering(t *testing.T) {
 	router.openConnection(ctx, &MemoryConnection{logger: logger, closer: sync.NewCloser()})
 	require.Equal(t, 1, filterByIPCount)
 }
+
+func TestConnectionFiltering_Banned(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	peerID := types.NodeID(strings.Repeat("b", 40))
+	peerManager, err := NewPeerManager(types.NodeID(strings.Repeat("a", 40)), dbm.NewMemDB(), PeerManagerOptions{})
+	require.NoError(t, err)
+	router := &Router{logger: log.TestingLogger(), peerManager: peerManager}
+
+	require.NoError(t, router.filterPeersIP(ctx, net.IPv4(1, 2, 3, 4), 26656))
+	require.NoError(t, router.filterPeersID(ctx, peerID))
+
+	_, err = peerManager.Ban("1.2.3.4", time.Time{})
+	require.NoError(t, err)
+	_, err = peerManager.Ban(string(peerID), time.Time{})
+	require.NoError(t, err)
+
+	require.Error(t, router.filterPeersIP(ctx, net.IPv4(1, 2, 3, 4), 26656))
+	require.NoError(t, router.filterPeersIP(ctx, net.IPv4(1, 2, 3, 5), 26656))
+	require.Error(t, router.filterPeersID(ctx, peerID))
+}
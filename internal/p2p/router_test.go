@@ -135,6 +135,14 @@ func TestRouter_Channel_Basic(t *testing.T) {
 	require.NoError(t, err)
 	require.Contains(t, router.NodeInfo().Channels, byte(chDesc2.ID))
 
+	// Opening channels reserving more than the whole bandwidth should fail.
+	_, err = router.OpenChannel(&p2p.ChannelDescriptor{ID: 3, MessageType: &p2ptest.Message{}, MinBandwidthShare: 0.6})
+	require.NoError(t, err)
+	_, err = router.OpenChannel(&p2p.ChannelDescriptor{ID: 4, MessageType: &p2ptest.Message{}, MinBandwidthShare: 0.6})
+	require.Error(t, err)
+	_, err = router.OpenChannel(&p2p.ChannelDescriptor{ID: 4, MessageType: &p2ptest.Message{}, MinBandwidthShare: -0.1})
+	require.Error(t, err)
+
 	// Closing the channel, then opening it again should be fine.
 	channel.Close()
 	time.Sleep(100 * time.Millisecond) // yes yes, but Close() is async...
@@ -216,6 +224,73 @@ func TestRouter_Channel_SendReceive(t *testing.T) {
 	}
 }
 
+func TestRouter_Channel_BandwidthLimit(t *testing.T) {
+	t.Cleanup(leaktest.Check(t))
+
+	// Create a test network where nodes send at most 1000 bytes/s to each peer.
+	network := p2ptest.MakeNetwork(t, p2ptest.NetworkOptions{
+		NumNodes: 2,
+		NodeOpts: p2ptest.NodeOptions{PeerSendRate: 1000},
+	})
+
+	ids := network.NodeIDs()
+	aID, bID := ids[0], ids[1]
+	channels := network.MakeChannels(t, chDesc)
+	a, b := channels[aID], channels[bID]
+
+	network.Start(t)
+
+	// Sending 1500 bytes should take at least half a second once the first
+	// second worth of bandwidth is used, and all messages should arrive in
+	// order.
+	value := strings.Repeat("x", 100)
+	start := time.Now()
+	for i := 0; i < 15; i++ {
+		p2ptest.RequireSend(t, a, p2p.Envelope{To: bID, Message: &p2ptest.Message{Value: fmt.Sprint(i, value)}})
+	}
+	for i := 0; i < 15; i++ {
+		p2ptest.RequireReceive(t, b, p2p.Envelope{From: aID, Message: &p2ptest.Message{Value: fmt.Sprint(i, value)}})
+	}
+	require.GreaterOrEqual(t, time.Since(start), 400*time.Millisecond)
+	p2ptest.RequireEmpty(t, a, b)
+}
+
+func TestRouter_Channel_BandwidthLimit_Lossless(t *testing.T) {
+	t.Cleanup(leaktest.Check(t))
+
+	// Create a test network where nodes send at most 1000 bytes/s to each peer.
+	network := p2ptest.MakeNetwork(t, p2ptest.NetworkOptions{
+		NumNodes: 2,
+		NodeOpts: p2ptest.NodeOptions{PeerSendRate: 1000},
+	})
+
+	ids := network.NodeIDs()
+	aID, bID := ids[0], ids[1]
+	losslessDesc := p2ptest.MakeChannelDesc(chID)
+	losslessDesc.Lossless = true
+	channels := network.MakeChannels(t, losslessDesc)
+	a, b := channels[aID], channels[bID]
+
+	network.Start(t)
+
+	// Sending more messages than the channel can hold back while waiting for
+	// bandwidth should not drop any of them, but hold up the sender instead.
+	value := strings.Repeat("x", 100)
+	go func() {
+		for i := 0; i < 30; i++ {
+			select {
+			case a.Out <- p2p.Envelope{To: bID, Message: &p2ptest.Message{Value: fmt.Sprint(i, value)}}:
+			case <-a.Done():
+				return
+			}
+		}
+	}()
+	for i := 0; i < 30; i++ {
+		p2ptest.RequireReceive(t, b, p2p.Envelope{From: aID, Message: &p2ptest.Message{Value: fmt.Sprint(i, value)}})
+	}
+	p2ptest.RequireEmpty(t, a, b)
+}
+
 func TestRouter_Channel_Broadcast(t *testing.T) {
 	t.Cleanup(leaktest.Check(t))
 
@@ -590,6 +665,7 @@ func TestRouter_DialPeers(t *testing.T) {
 				mockConnection.On("Handshake", mock.Anything, selfInfo, selfKey).
 					Return(tc.peerInfo, tc.peerKey, nil)
 				mockConnection.On("Close").Run(func(_ mock.Arguments) { closer.Close() }).Return(nil)
+				mockConnection.On("RemoteEndpoint").Maybe().Return(p2p.Endpoint{})
 			}
 			if tc.ok {
 				// without the sleep after RequireUpdate this method isn't
@@ -927,3 +1003,26 @@ func TestRouter_DontSendOnInvalidChannel(t *testing.T) {
 	require.NoError(t, router.Stop())
 	mockTransport.AssertExpectations(t)
 }
+
+func TestRouter_Probe(t *testing.T) {
+	t.Cleanup(leaktest.Check(t))
+
+	network := p2ptest.MakeNetwork(t, p2ptest.NetworkOptions{NumNodes: 2})
+	ids := network.NodeIDs()
+	a, b := network.Nodes[ids[0]], network.Nodes[ids[1]]
+
+	// Probing b returns its NodeInfo without connecting it.
+	info, err := a.Router.Probe(ctx, b.NodeAddress)
+	require.NoError(t, err)
+	require.Equal(t, b.Router.NodeInfo(), info)
+	require.Equal(t, p2p.PeerStatusDown, a.PeerManager.Status(b.NodeID))
+	require.Empty(t, a.PeerManager.Peers())
+
+	// Probing an address with the wrong node ID fails.
+	_, err = a.Router.Probe(ctx, p2p.NodeAddress{
+		Protocol: b.NodeAddress.Protocol,
+		NodeID:   types.NodeID(strings.Repeat("a", 40)),
+		Path:     b.NodeAddress.Path,
+	})
+	require.Error(t, err)
+}
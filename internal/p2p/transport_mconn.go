@@ -2,14 +2,17 @@ package p2p
 
 import (
 	"context"
+	"encoding/binary"
 	"errors"
 	"fmt"
 	"io"
 	"math"
 	"net"
 	"strconv"
+	"strings"
 	"sync"
 
+	gogotypes "github.com/gogo/protobuf/types"
 	"golang.org/x/net/netutil"
 
 	"github.com/tendermint/tendermint/crypto"
@@ -25,6 +28,26 @@ const (
 	TCPProtocol   Protocol = "tcp"
 )
 
+// SecureChannel is a protocol securing MConnTransport connections.
+type SecureChannel string
+
+const (
+	// SecureChannelSecretConnection is the STS-based conn.SecretConnection.
+	SecureChannelSecretConnection SecureChannel = "secret-connection"
+
+	// SecureChannelNoise is the Noise_XX-based conn.NoiseConnection.
+	SecureChannelNoise SecureChannel = "noise"
+)
+
+// secureChannelHelloMarker starts the secure channel negotiation. A legacy
+// peer reads it as an empty SecretConnection handshake message, which it
+// rejects, and it can't start a SecretConnection handshake message, so each
+// side can tell when the other does not negotiate.
+const secureChannelHelloMarker = 0x00
+
+// maxSecureChannelHelloSize is the maximum size of a secure channel hello.
+const maxSecureChannelHelloSize = 1024
+
 // MConnTransportOptions sets options for MConnTransport.
 type MConnTransportOptions struct {
 	// MaxAcceptedConnections is the maximum number of simultaneous accepted
@@ -35,6 +58,17 @@ type MConnTransportOptions struct {
 	// Router, since it will need to do e.g. rate limiting and such as well.
 	// But it might also make sense to have per-transport limits.
 	MaxAcceptedConnections uint32
+
+	// SecureChannels lists the secure channel protocols negotiated with
+	// peers, in order of preference. The dialer's preference wins. If
+	// empty, connections use a SecretConnection without negotiation, which
+	// is what legacy peers expect. Peers that negotiate can't connect to
+	// peers that don't.
+	//
+	// Only Noise handshakes authenticate the negotiation, so offering
+	// SecretConnection alongside Noise allows a man in the middle to
+	// downgrade connections to SecretConnection.
+	SecureChannels []SecureChannel
 }
 
 // MConnTransport is a Transport implementation using the current multiplexed
@@ -147,7 +181,7 @@ func (m *MConnTransport) Accept() (Connection, error) {
 		}
 	}
 
-	return newMConnConnection(m.logger, tcpConn, m.mConnConfig, m.channelDescs), nil
+	return newMConnConnection(m.logger, tcpConn, m.mConnConfig, m.channelDescs, m.options.SecureChannels, false), nil
 }
 
 // Dial implements Transport.
@@ -171,7 +205,7 @@ func (m *MConnTransport) Dial(ctx context.Context, endpoint Endpoint) (Connectio
 		}
 	}
 
-	return newMConnConnection(m.logger, tcpConn, m.mConnConfig, m.channelDescs), nil
+	return newMConnConnection(m.logger, tcpConn, m.mConnConfig, m.channelDescs, m.options.SecureChannels, true), nil
 }
 
 // Close implements Transport.
@@ -216,14 +250,16 @@ func (m *MConnTransport) validateEndpoint(endpoint Endpoint) error {
 
 // mConnConnection implements Connection for MConnTransport.
 type mConnConnection struct {
-	logger       log.Logger
-	conn         net.Conn
-	mConnConfig  conn.MConnConfig
-	channelDescs []*ChannelDescriptor
-	receiveCh    chan mConnMessage
-	errorCh      chan error
-	closeCh      chan struct{}
-	closeOnce    sync.Once
+	logger         log.Logger
+	conn           net.Conn
+	mConnConfig    conn.MConnConfig
+	channelDescs   []*ChannelDescriptor
+	secureChannels []SecureChannel
+	dialed         bool // whether we dialed the connection
+	receiveCh      chan mConnMessage
+	errorCh        chan error
+	closeCh        chan struct{}
+	closeOnce      sync.Once
 
 	mconn *conn.MConnection // set during Handshake()
 }
@@ -240,15 +276,19 @@ func newMConnConnection(
 	conn net.Conn,
 	mConnConfig conn.MConnConfig,
 	channelDescs []*ChannelDescriptor,
+	secureChannels []SecureChannel,
+	dialed bool,
 ) *mConnConnection {
 	return &mConnConnection{
-		logger:       logger,
-		conn:         conn,
-		mConnConfig:  mConnConfig,
-		channelDescs: channelDescs,
-		receiveCh:    make(chan mConnMessage),
-		errorCh:      make(chan error, 1), // buffered to avoid onError leak
-		closeCh:      make(chan struct{}),
+		logger:         logger,
+		conn:           conn,
+		mConnConfig:    mConnConfig,
+		channelDescs:   channelDescs,
+		secureChannels: secureChannels,
+		dialed:         dialed,
+		receiveCh:      make(chan mConnMessage),
+		errorCh:        make(chan error, 1), // buffered to avoid onError leak
+		closeCh:        make(chan struct{}),
 	}
 }
 
@@ -310,7 +350,7 @@ func (c *mConnConnection) handshake(
 		return nil, types.NodeInfo{}, nil, errors.New("connection is already handshaked")
 	}
 
-	secretConn, err := conn.MakeSecretConnection(c.conn, privKey)
+	secretConn, err := c.makeSecureChannel(privKey)
 	if err != nil {
 		return nil, types.NodeInfo{}, nil, err
 	}
@@ -347,6 +387,126 @@ func (c *mConnConnection) handshake(
 	return mconn, peerInfo, secretConn.RemotePubKey(), nil
 }
 
+// secureConn is a secure channel established with a peer.
+type secureConn interface {
+	net.Conn
+	RemotePubKey() crypto.PubKey
+}
+
+// makeSecureChannel negotiates a secure channel protocol with the peer, if
+// configured to, and establishes the secure channel.
+func (c *mConnConnection) makeSecureChannel(privKey crypto.PrivKey) (secureConn, error) {
+	if len(c.secureChannels) == 0 {
+		return conn.MakeSecretConnection(c.conn, privKey)
+	}
+
+	secureChannel, prologue, err := c.negotiateSecureChannel()
+	if err != nil {
+		return nil, fmt.Errorf("secure channel negotiation failed: %w", err)
+	}
+
+	switch secureChannel {
+	case SecureChannelNoise:
+		return conn.MakeNoiseConnection(c.conn, privKey, c.dialed, prologue)
+	default:
+		return conn.MakeSecretConnection(c.conn, privKey)
+	}
+}
+
+// negotiateSecureChannel exchanges the supported secure channel protocols
+// with the peer, and returns the dialer's most preferred protocol that both
+// support. It also returns the dialer's and acceptor's hellos, which Noise
+// handshakes use as prologue so that a tampered negotiation fails.
+func (c *mConnConnection) negotiateSecureChannel() (SecureChannel, []byte, error) {
+	protocols := make([]string, 0, len(c.secureChannels))
+	for _, sc := range c.secureChannels {
+		protocols = append(protocols, string(sc))
+	}
+	hello, err := protoio.MarshalDelimited(&gogotypes.StringValue{Value: strings.Join(protocols, ",")})
+	if err != nil {
+		return "", nil, err
+	}
+	hello = append([]byte{secureChannelHelloMarker}, hello...)
+
+	var peerHello []byte
+	errCh := make(chan error, 2)
+	go func() {
+		_, err := c.conn.Write(hello)
+		errCh <- err
+	}()
+	go func() {
+		var err error
+		peerHello, err = readSecureChannelHello(c.conn)
+		errCh <- err
+	}()
+	for i := 0; i < cap(errCh); i++ {
+		if err := <-errCh; err != nil {
+			return "", nil, err
+		}
+	}
+
+	var pbPeerProtocols gogotypes.StringValue
+	if err := protoio.UnmarshalDelimited(peerHello[1:], &pbPeerProtocols); err != nil {
+		return "", nil, err
+	}
+	peerProtocols := strings.Split(pbPeerProtocols.Value, ",")
+
+	dialerProtocols, acceptorProtocols := protocols, peerProtocols
+	prologue := append(hello, peerHello...)
+	if !c.dialed {
+		dialerProtocols, acceptorProtocols = peerProtocols, protocols
+		prologue = append(peerHello, hello...)
+	}
+	for _, dialerProtocol := range dialerProtocols {
+		for _, acceptorProtocol := range acceptorProtocols {
+			if dialerProtocol == acceptorProtocol {
+				return SecureChannel(dialerProtocol), prologue, nil
+			}
+		}
+	}
+	return "", nil, fmt.Errorf("no common secure channel with peer, which supports %q", pbPeerProtocols.Value)
+}
+
+// readSecureChannelHello reads a secure channel hello, and returns it as is.
+func readSecureChannelHello(r io.Reader) ([]byte, error) {
+	var marker [1]byte
+	if _, err := io.ReadFull(r, marker[:]); err != nil {
+		return nil, err
+	}
+	if marker[0] != secureChannelHelloMarker {
+		return nil, errors.New("peer does not negotiate secure channels")
+	}
+
+	var hello = []byte{secureChannelHelloMarker}
+	length, err := binary.ReadUvarint(&byteReader{r: r, read: &hello})
+	if err != nil {
+		return nil, err
+	}
+	if length > maxSecureChannelHelloSize {
+		return nil, fmt.Errorf("secure channel hello of %d bytes is too large", length)
+	}
+	msg := make([]byte, length)
+	if _, err := io.ReadFull(r, msg); err != nil {
+		return nil, err
+	}
+	return append(hello, msg...), nil
+}
+
+// byteReader is an io.ByteReader that records the bytes it reads.
+type byteReader struct {
+	r    io.Reader
+	read *[]byte
+}
+
+func (br *byteReader) ReadByte() (byte, error) {
+	var b [1]byte
+	if _, err := io.ReadFull(br.r, b[:]); err != nil {
+		return 0, err
+	}
+	*br.read = append(*br.read, b[0])
+	return b[0], nil
+}
+
 // onReceive is a callback for MConnection received messages.
 func (c *mConnConnection) onReceive(chID ChannelID, payload []byte) {
 	select {
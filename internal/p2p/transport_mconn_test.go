@@ -1,44 +1,217 @@
 package p2p_test
 
 import (
+	"bufio"
+	"context"
+	"encoding/binary"
+	"fmt"
 	"io"
 	"net"
 	"testing"
 	"time"
 
 	"github.com/fortytw2/leaktest"
+	gogotypes "github.com/gogo/protobuf/types"
 	"github.com/stretchr/testify/require"
 
+	"github.com/tendermint/tendermint/crypto/ed25519"
+	"github.com/tendermint/tendermint/internal/libs/protoio"
 	"github.com/tendermint/tendermint/internal/p2p"
 	"github.com/tendermint/tendermint/internal/p2p/conn"
 	"github.com/tendermint/tendermint/libs/log"
+	"github.com/tendermint/tendermint/types"
 )
 
 // Transports are mainly tested by common tests in transport_test.go, we
 // register a transport factory here to get included in those tests.
 func init() {
 	testTransports["mconn"] = func(t *testing.T) p2p.Transport {
-		transport := p2p.NewMConnTransport(
-			log.TestingLogger(),
-			conn.DefaultMConnConfig(),
-			[]*p2p.ChannelDescriptor{{ID: chID, Priority: 1}},
-			p2p.MConnTransportOptions{},
-		)
-		err := transport.Listen(p2p.Endpoint{
-			Protocol: p2p.MConnProtocol,
-			IP:       net.IPv4(127, 0, 0, 1),
-			Port:     0, // assign a random port
+		return makeMConnTransport(t, p2p.MConnTransportOptions{})
+	}
+	testTransports["mconn-noise"] = func(t *testing.T) p2p.Transport {
+		return makeMConnTransport(t, p2p.MConnTransportOptions{
+			SecureChannels: []p2p.SecureChannel{p2p.SecureChannelNoise},
 		})
-		require.NoError(t, err)
+	}
+}
+
+// makeMConnTransport creates an MConnTransport listening on a random port.
+func makeMConnTransport(t *testing.T, options p2p.MConnTransportOptions) *p2p.MConnTransport {
+	transport := p2p.NewMConnTransport(
+		log.TestingLogger(),
+		conn.DefaultMConnConfig(),
+		[]*p2p.ChannelDescriptor{{ID: chID, Priority: 1}},
+		options,
+	)
+	err := transport.Listen(p2p.Endpoint{
+		Protocol: p2p.MConnProtocol,
+		IP:       net.IPv4(127, 0, 0, 1),
+		Port:     0, // assign a random port
+	})
+	require.NoError(t, err)
 
-		t.Cleanup(func() {
-			require.NoError(t, transport.Close())
+	t.Cleanup(func() {
+		require.NoError(t, transport.Close())
+	})
+
+	return transport
+}
+
+func TestMConnTransport_SecureChannelNegotiation(t *testing.T) {
+	var (
+		legacy     []p2p.SecureChannel
+		noise      = []p2p.SecureChannel{p2p.SecureChannelNoise}
+		secretConn = []p2p.SecureChannel{p2p.SecureChannelSecretConnection}
+		both       = []p2p.SecureChannel{p2p.SecureChannelNoise, p2p.SecureChannelSecretConnection}
+	)
+	testcases := []struct {
+		name     string
+		dialer   []p2p.SecureChannel
+		acceptor []p2p.SecureChannel
+		ok       bool
+	}{
+		{"legacy", legacy, legacy, true},
+		{"noise", noise, noise, true},
+		{"secret connection", secretConn, secretConn, true},
+		{"dialer preference", both, secretConn, true},
+		{"acceptor preference", secretConn, both, true},
+		{"no common secure channel", noise, secretConn, false},
+		{"legacy dialer", legacy, both, false},
+		{"legacy acceptor", both, legacy, false},
+	}
+	for _, tc := range testcases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			a := makeMConnTransport(t, p2p.MConnTransportOptions{SecureChannels: tc.dialer})
+			b := makeMConnTransport(t, p2p.MConnTransportOptions{SecureChannels: tc.acceptor})
+			ab, ba := dialAccept(t, a, b)
+
+			ctx, cancel := context.WithTimeout(ctx, time.Second)
+			defer cancel()
+
+			aKey, bKey := ed25519.GenPrivKey(), ed25519.GenPrivKey()
+			aInfo := types.NodeInfo{NodeID: types.NodeIDFromPubKey(aKey.PubKey())}
+			bInfo := types.NodeInfo{NodeID: types.NodeIDFromPubKey(bKey.PubKey())}
+
+			errCh := make(chan error, 1)
+			go func() {
+				// Unblock the dialer if the acceptor fails.
+				_, _, err := ba.Handshake(ctx, bInfo, bKey)
+				if err != nil {
+					_ = ba.Close()
+				}
+				errCh <- err
+			}()
+
+			peerInfo, peerKey, err := ab.Handshake(ctx, aInfo, aKey)
+			if !tc.ok {
+				require.Error(t, err)
+				require.Error(t, <-errCh)
+				return
+			}
+			require.NoError(t, err)
+			require.NoError(t, <-errCh)
+			require.Equal(t, bInfo, peerInfo)
+			require.Equal(t, bKey.PubKey(), peerKey)
 		})
+	}
+}
+
+func TestMConnTransport_SecureChannelTamperedHello(t *testing.T) {
+	noise := []p2p.SecureChannel{p2p.SecureChannelNoise}
+	testcases := []struct {
+		name      string
+		protocols string // the protocols offered in the tampered dialer hello
+	}{
+		{"downgrade to secret connection", "secret-connection"},
+		{"altered noise offer", "noise,secret-connection"},
+	}
+	for _, tc := range testcases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			a := makeMConnTransport(t, p2p.MConnTransportOptions{SecureChannels: noise})
+			b := makeMConnTransport(t, p2p.MConnTransportOptions{SecureChannels: noise})
+			proxy := startTamperingProxy(t, b.Endpoints()[0], tc.protocols)
+
+			ctx, cancel := context.WithTimeout(ctx, time.Second)
+			defer cancel()
+
+			aKey, bKey := ed25519.GenPrivKey(), ed25519.GenPrivKey()
+			aInfo := types.NodeInfo{NodeID: types.NodeIDFromPubKey(aKey.PubKey())}
+			bInfo := types.NodeInfo{NodeID: types.NodeIDFromPubKey(bKey.PubKey())}
 
-		return transport
+			errCh := make(chan error, 1)
+			go func() {
+				ba, err := b.Accept()
+				if err == nil {
+					defer ba.Close()
+					_, _, err = ba.Handshake(ctx, bInfo, bKey)
+				}
+				errCh <- err
+			}()
+
+			ab, err := a.Dial(ctx, proxy)
+			require.NoError(t, err)
+			_, _, err = ab.Handshake(ctx, aInfo, aKey)
+			require.Error(t, err)
+			require.NoError(t, ab.Close())
+			require.Error(t, <-errCh)
+		})
 	}
 }
 
+// startTamperingProxy starts a proxy that forwards a connection to target,
+// replacing the secure channel hello of the dialer with one offering the given
+// protocols.
+func startTamperingProxy(t *testing.T, target p2p.Endpoint, protocols string) p2p.Endpoint {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = listener.Close() })
+
+	go func() {
+		dialer, err := listener.Accept()
+		if err != nil {
+			return
+		}
+		defer dialer.Close()
+		acceptor, err := net.Dial("tcp", net.JoinHostPort(target.IP.String(), fmt.Sprint(target.Port)))
+		if err != nil {
+			return
+		}
+		defer acceptor.Close()
+
+		// drop the marker and message of the dialer's hello
+		r := bufio.NewReader(dialer)
+		if _, err := r.ReadByte(); err != nil {
+			return
+		}
+		length, err := binary.ReadUvarint(r)
+		if err != nil {
+			return
+		}
+		if _, err := io.CopyN(io.Discard, r, int64(length)); err != nil {
+			return
+		}
+
+		hello, err := protoio.MarshalDelimited(&gogotypes.StringValue{Value: protocols})
+		if err != nil {
+			return
+		}
+		if _, err := acceptor.Write(append([]byte{0x00}, hello...)); err != nil {
+			return
+		}
+
+		go func() {
+			_, _ = io.Copy(dialer, acceptor)
+			_ = dialer.Close()
+		}()
+		_, _ = io.Copy(acceptor, r)
+	}()
+
+	addr := listener.Addr().(*net.TCPAddr)
+	return p2p.Endpoint{Protocol: p2p.MConnProtocol, IP: addr.IP, Port: uint16(addr.Port)}
+}
+
 func TestMConnTransport_AcceptBeforeListen(t *testing.T) {
 	transport := p2p.NewMConnTransport(
 		log.TestingLogger(),
@@ -199,8 +199,9 @@ func (env *Environment) BlockSearch(
 	orderBy string,
 ) (*coretypes.ResultBlockSearch, error) {
 
-	if !indexer.KVSinkEnabled(env.EventSinks) {
-		return nil, fmt.Errorf("block searching is disabled due to no kvEventSink")
+	sink := indexer.SearchSink(env.EventSinks)
+	if sink == nil {
+		return nil, fmt.Errorf("block searching is disabled due to no kv or sqlite event sink")
 	}
 
 	q, err := tmquery.New(query)
@@ -208,14 +209,7 @@ func (env *Environment) BlockSearch(
 		return nil, err
 	}
 
-	var kvsink indexer.EventSink
-	for _, sink := range env.EventSinks {
-		if sink.Type() == indexer.KV {
-			kvsink = sink
-		}
-	}
-
-	results, err := kvsink.SearchBlockEvents(ctx.Context(), q)
+	results, err := sink.SearchBlockEvents(ctx.Context(), q)
 	if err != nil {
 		return nil, err
 	}
@@ -131,5 +131,6 @@ func (mockBlockStore) LoadBlockPart(height int64, index int) *types.Part { retur
 func (mockBlockStore) LoadBlockCommit(height int64) *types.Commit        { return nil }
 func (mockBlockStore) LoadSeenCommit() *types.Commit                     { return nil }
 func (mockBlockStore) PruneBlocks(height int64) (uint64, error)          { return 0, nil }
+func (mockBlockStore) DeleteBlocksAfter(height int64) (uint64, error)    { return 0, nil }
 func (mockBlockStore) SaveBlock(block *types.Block, blockParts *types.PartSet, seenCommit *types.Commit) {
 }
@@ -9,8 +9,10 @@ import (
 	"github.com/tendermint/tendermint/crypto"
 	"github.com/tendermint/tendermint/internal/consensus"
 	"github.com/tendermint/tendermint/internal/eventbus"
+	"github.com/tendermint/tendermint/internal/evidence"
 	"github.com/tendermint/tendermint/internal/mempool"
 	"github.com/tendermint/tendermint/internal/p2p"
+	"github.com/tendermint/tendermint/internal/p2p/pex"
 	"github.com/tendermint/tendermint/internal/proxy"
 	sm "github.com/tendermint/tendermint/internal/state"
 	"github.com/tendermint/tendermint/internal/state/indexer"
@@ -57,9 +59,33 @@ type consensusReactor interface {
 	GetPeerState(peerID types.NodeID) (*consensus.PeerState, bool)
 }
 
+type evidencePool interface {
+	AddEvidence(types.Evidence) error
+	ListPendingEvidence() ([]types.Evidence, error)
+	ListCommittedEvidence(height int64) ([]evidence.CommittedEvidence, error)
+	GetEvidence(hash []byte) (types.Evidence, int64, error)
+}
+
 type peerManager interface {
 	Peers() []types.NodeID
 	Addresses(types.NodeID) []p2p.NodeAddress
+	Status(types.NodeID) p2p.PeerStatus
+	Add(p2p.NodeAddress) (bool, error)
+	SetPersistent(types.NodeID) error
+	Remove(types.NodeID) error
+	Ban(peer string, expires time.Time) (p2p.PeerBan, error)
+	Unban(peer string) error
+	Bans() []p2p.PeerBan
+	Records() []p2p.PeerRecord
+}
+
+type crawler interface {
+	CrawledPeers() []pex.CrawledPeer
+}
+
+type mempoolReactor interface {
+	PeerTxStats(types.NodeID) (mempool.PeerTxStats, bool)
+	PendingTx(types.TxKey) (mempool.PendingTx, bool)
 }
 
 //----------------------------------------------
@@ -73,15 +99,17 @@ type Environment struct {
 	// interfaces defined in types and above
 	StateStore       sm.Store
 	BlockStore       sm.BlockStore
-	EvidencePool     sm.EvidencePool
+	EvidencePool     evidencePool
 	ConsensusState   consensusState
 	ConsensusReactor consensusReactor
+	MempoolReactor   mempoolReactor
 
 	// Legacy p2p stack
 	P2PTransport transport
 
 	// interfaces for new p2p interfaces
 	PeerManager peerManager
+	Crawler     crawler
 
 	// objects
 	PubKey            crypto.PubKey
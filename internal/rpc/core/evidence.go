@@ -1,8 +1,14 @@
 package core
 
 import (
+	"bytes"
+	"errors"
 	"fmt"
+	"sort"
 
+	tmbytes "github.com/tendermint/tendermint/libs/bytes"
+	tmmath "github.com/tendermint/tendermint/libs/math"
+	tmquery "github.com/tendermint/tendermint/libs/pubsub/query"
 	"github.com/tendermint/tendermint/rpc/coretypes"
 	rpctypes "github.com/tendermint/tendermint/rpc/jsonrpc/types"
 	"github.com/tendermint/tendermint/types"
@@ -27,3 +33,182 @@ func (env *Environment) BroadcastEvidence(
 	}
 	return &coretypes.ResultBroadcastEvidence{Hash: ev.Hash()}, nil
 }
+
+// PendingEvidence returns the evidence that has been verified by the node but
+// not committed yet, from oldest to newest.
+// More: https://docs.tendermint.com/master/rpc/#/Evidence/pending_evidence
+func (env *Environment) PendingEvidence(
+	ctx *rpctypes.Context,
+	pagePtr, perPagePtr *int,
+) (*coretypes.ResultEvidenceList, error) {
+
+	pending, err := env.EvidencePool.ListPendingEvidence()
+	if err != nil {
+		return nil, err
+	}
+
+	results := make([]*coretypes.ResultEvidence, 0, len(pending))
+	for _, ev := range pending {
+		results = append(results, &coretypes.ResultEvidence{Evidence: ev, Hash: ev.Hash()})
+	}
+	return env.paginateEvidence(results, pagePtr, perPagePtr)
+}
+
+// CommittedEvidence returns the evidence committed in blocks, ordered by the
+// height of the misbehavior. If height is given, only the evidence committed
+// in the block at that height is returned. If validator is given, only the
+// evidence against the validator with that address is returned.
+// More: https://docs.tendermint.com/master/rpc/#/Evidence/committed_evidence
+func (env *Environment) CommittedEvidence(
+	ctx *rpctypes.Context,
+	heightPtr *int64,
+	validator tmbytes.HexBytes,
+	pagePtr, perPagePtr *int,
+) (*coretypes.ResultEvidenceList, error) {
+
+	var height int64
+	if heightPtr != nil {
+		var err error
+		height, err = env.getHeight(env.BlockStore.Height(), heightPtr)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	committed, err := env.EvidencePool.ListCommittedEvidence(height)
+	if err != nil {
+		return nil, err
+	}
+
+	results := make([]*coretypes.ResultEvidence, 0, len(committed))
+	for _, c := range committed {
+		if len(validator) > 0 && !evidenceAgainst(c.Evidence, validator) {
+			continue
+		}
+		results = append(results, &coretypes.ResultEvidence{
+			Evidence: c.Evidence,
+			Hash:     c.Evidence.Hash(),
+			Height:   c.Height,
+		})
+	}
+	return env.paginateEvidence(results, pagePtr, perPagePtr)
+}
+
+// Evidence returns the pending or committed evidence with the given hash.
+// More: https://docs.tendermint.com/master/rpc/#/Evidence/evidence
+func (env *Environment) Evidence(ctx *rpctypes.Context, hash tmbytes.HexBytes) (*coretypes.ResultEvidence, error) {
+	// N.B. The hash parameter is HexBytes so that the reflective parameter
+	// decoding logic in the HTTP service will correctly translate from JSON.
+
+	ev, height, err := env.EvidencePool.GetEvidence(hash)
+	if err != nil {
+		return nil, err
+	}
+	if ev == nil {
+		return nil, fmt.Errorf("evidence (%X) not found", hash)
+	}
+
+	return &coretypes.ResultEvidence{Evidence: ev, Hash: ev.Hash(), Height: height}, nil
+}
+
+// EvidenceSearch allows you to query for pending and committed evidence by
+// the reserved evidence events, i.e. evidence.hash, evidence.height,
+// evidence.type and evidence.validator. The same events are published with
+// NewEvidence events. Results are ordered by the height of the misbehavior.
+// More: https://docs.tendermint.com/master/rpc/#/Evidence/evidence_search
+func (env *Environment) EvidenceSearch(
+	ctx *rpctypes.Context,
+	query string,
+	pagePtr, perPagePtr *int,
+	orderBy string,
+) (*coretypes.ResultEvidenceList, error) {
+
+	if len(query) > maxQueryLength {
+		return nil, errors.New("maximum query length exceeded")
+	}
+
+	q, err := tmquery.New(query)
+	if err != nil {
+		return nil, err
+	}
+
+	pending, err := env.EvidencePool.ListPendingEvidence()
+	if err != nil {
+		return nil, err
+	}
+	committed, err := env.EvidencePool.ListCommittedEvidence(0)
+	if err != nil {
+		return nil, err
+	}
+
+	var results []*coretypes.ResultEvidence
+	add := func(ev types.Evidence, height int64) error {
+		match, err := q.Matches(types.EvidenceEvents(ev))
+		if err != nil {
+			return err
+		}
+		if match {
+			results = append(results, &coretypes.ResultEvidence{Evidence: ev, Hash: ev.Hash(), Height: height})
+		}
+		return nil
+	}
+	for _, c := range committed {
+		if err := add(c.Evidence, c.Height); err != nil {
+			return nil, err
+		}
+	}
+	for _, ev := range pending {
+		if err := add(ev, 0); err != nil {
+			return nil, err
+		}
+	}
+
+	// sort results (must be done before pagination)
+	switch orderBy {
+	case "desc", "":
+		sort.SliceStable(results, func(i, j int) bool {
+			return results[i].Evidence.Height() > results[j].Evidence.Height()
+		})
+	case "asc":
+		sort.SliceStable(results, func(i, j int) bool {
+			return results[i].Evidence.Height() < results[j].Evidence.Height()
+		})
+	default:
+		return nil, fmt.Errorf("expected order_by to be either `asc` or `desc` or empty: %w", coretypes.ErrInvalidRequest)
+	}
+
+	return env.paginateEvidence(results, pagePtr, perPagePtr)
+}
+
+func (env *Environment) paginateEvidence(
+	results []*coretypes.ResultEvidence,
+	pagePtr, perPagePtr *int,
+) (*coretypes.ResultEvidenceList, error) {
+
+	totalCount := len(results)
+	perPage := env.validatePerPage(perPagePtr)
+
+	page, err := validatePage(pagePtr, perPage, totalCount)
+	if err != nil {
+		return nil, err
+	}
+
+	skipCount := validateSkipCount(page, perPage)
+	pageSize := tmmath.MinInt(perPage, totalCount-skipCount)
+
+	return &coretypes.ResultEvidenceList{
+		Evidence:   results[skipCount : skipCount+pageSize],
+		TotalCount: totalCount,
+	}, nil
+}
+
+// evidenceAgainst returns true if the evidence is against the validator with
+// the given address.
+func evidenceAgainst(ev types.Evidence, address []byte) bool {
+	for _, abciEv := range ev.ABCI() {
+		if bytes.Equal(abciEv.Validator.Address, address) {
+			return true
+		}
+	}
+	return false
+}
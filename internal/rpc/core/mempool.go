@@ -9,6 +9,7 @@ import (
 	abci "github.com/tendermint/tendermint/abci/types"
 	"github.com/tendermint/tendermint/internal/mempool"
 	"github.com/tendermint/tendermint/internal/state/indexer"
+	tmbytes "github.com/tendermint/tendermint/libs/bytes"
 	"github.com/tendermint/tendermint/rpc/coretypes"
 	rpctypes "github.com/tendermint/tendermint/rpc/jsonrpc/types"
 	"github.com/tendermint/tendermint/types"
@@ -73,12 +74,12 @@ func (env *Environment) BroadcastTxCommit(ctx *rpctypes.Context, tx types.Tx) (*
 
 	r := (<-resCh).GetCheckTx()
 
-	if !indexer.KVSinkEnabled(env.EventSinks) {
+	if indexer.SearchSink(env.EventSinks) == nil {
 		return &coretypes.ResultBroadcastTxCommit{
 				CheckTx: *r,
 				Hash:    tx.Hash(),
 			},
-			errors.New("cannot confirm transaction because kvEventSink is not enabled")
+			errors.New("cannot confirm transaction because no kv or sqlite event sink is enabled")
 	}
 
 	startAt := time.Now()
@@ -155,3 +156,37 @@ func (env *Environment) CheckTx(ctx *rpctypes.Context, tx types.Tx) (*coretypes.
 func (env *Environment) RemoveTx(ctx *rpctypes.Context, txkey types.TxKey) error {
 	return env.Mempool.RemoveTxByKey(txkey)
 }
+
+// MempoolTx returns a transaction pending in the mempool by hash, with its
+// priority, sender, lane, the height at which it was first checked and the
+// connected peers that sent it.
+// More: https://docs.tendermint.com/master/rpc/#/Info/mempool_tx
+func (env *Environment) MempoolTx(ctx *rpctypes.Context, hash tmbytes.HexBytes) (*coretypes.ResultMempoolTx, error) {
+	// N.B. The hash parameter is HexBytes so that the reflective parameter
+	// decoding logic in the HTTP service will correctly translate from JSON.
+
+	if env.MempoolReactor == nil {
+		return nil, errors.New("mempool reactor is not available")
+	}
+	if len(hash) != len(types.TxKey{}) {
+		return nil, fmt.Errorf("invalid tx hash length %d, expected %d", len(hash), len(types.TxKey{}))
+	}
+
+	var key types.TxKey
+	copy(key[:], hash)
+	pending, ok := env.MempoolReactor.PendingTx(key)
+	if !ok {
+		return nil, fmt.Errorf("tx (%X) not found in the mempool", hash)
+	}
+
+	return &coretypes.ResultMempoolTx{
+		Hash:      hash,
+		Tx:        pending.Tx,
+		Priority:  pending.Priority,
+		Sender:    pending.Sender,
+		Lane:      pending.Lane,
+		Height:    pending.Height,
+		Timestamp: pending.Timestamp,
+		Peers:     pending.Peers,
+	}, nil
+}
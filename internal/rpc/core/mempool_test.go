@@ -0,0 +1,56 @@
+package core
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/tendermint/tendermint/internal/mempool"
+	rpctypes "github.com/tendermint/tendermint/rpc/jsonrpc/types"
+	"github.com/tendermint/tendermint/types"
+)
+
+type testMempoolReactor map[types.TxKey]mempool.PendingTx
+
+func (r testMempoolReactor) PeerTxStats(types.NodeID) (mempool.PeerTxStats, bool) {
+	return mempool.PeerTxStats{}, false
+}
+
+func (r testMempoolReactor) PendingTx(key types.TxKey) (mempool.PendingTx, bool) {
+	pending, ok := r[key]
+	return pending, ok
+}
+
+func TestMempoolTx(t *testing.T) {
+	tx := types.Tx("sender=key=10")
+	pending := mempool.PendingTx{
+		Tx:        tx,
+		Priority:  10,
+		Sender:    "sender",
+		Lane:      "default",
+		Height:    5,
+		Timestamp: time.Now().UTC(),
+		Peers:     []types.NodeID{types.NodeID(strings.Repeat("a", 40))},
+	}
+	env := &Environment{MempoolReactor: testMempoolReactor{tx.Key(): pending}}
+	ctx := &rpctypes.Context{}
+
+	result, err := env.MempoolTx(ctx, tx.Hash())
+	require.NoError(t, err)
+	require.EqualValues(t, tx.Hash(), result.Hash)
+	require.Equal(t, tx, result.Tx)
+	require.Equal(t, pending.Priority, result.Priority)
+	require.Equal(t, pending.Sender, result.Sender)
+	require.Equal(t, pending.Lane, result.Lane)
+	require.Equal(t, pending.Height, result.Height)
+	require.Equal(t, pending.Timestamp, result.Timestamp)
+	require.Equal(t, pending.Peers, result.Peers)
+
+	_, err = env.MempoolTx(ctx, types.Tx("unknown").Hash())
+	require.Error(t, err)
+
+	_, err = env.MempoolTx(ctx, []byte{1, 2, 3})
+	require.Error(t, err)
+}
@@ -3,9 +3,13 @@ package core
 import (
 	"errors"
 	"fmt"
+	"time"
 
+	"github.com/tendermint/tendermint/internal/p2p"
+	tmmath "github.com/tendermint/tendermint/libs/math"
 	"github.com/tendermint/tendermint/rpc/coretypes"
 	rpctypes "github.com/tendermint/tendermint/rpc/jsonrpc/types"
+	"github.com/tendermint/tendermint/types"
 )
 
 // NetInfo returns network info.
@@ -20,10 +24,21 @@ func (env *Environment) NetInfo(ctx *rpctypes.Context) (*coretypes.ResultNetInfo
 			continue
 		}
 
-		peers = append(peers, coretypes.Peer{
+		p := coretypes.Peer{
 			ID:  peer,
 			URL: addrs[0].String(),
-		})
+		}
+		if env.MempoolReactor != nil {
+			if stats, ok := env.MempoolReactor.PeerTxStats(peer); ok {
+				p.Mempool = &coretypes.PeerMempoolStats{
+					AcceptedTxs:    stats.AcceptedTxs,
+					InvalidTxs:     stats.InvalidTxs,
+					DuplicateTxs:   stats.DuplicateTxs,
+					RateLimitedTxs: stats.RateLimitedTxs,
+				}
+			}
+		}
+		peers = append(peers, p)
 	}
 
 	return &coretypes.ResultNetInfo{
@@ -34,6 +49,162 @@ func (env *Environment) NetInfo(ctx *rpctypes.Context) (*coretypes.ResultNetInfo
 	}, nil
 }
 
+// UnsafeDialPeers adds the given peer addresses to the peer store, so that
+// they are dialed as soon as a connection slot is available. If persistent is
+// true, the peers are also made persistent until the node restarts.
+// More: https://docs.tendermint.com/master/rpc/#/Unsafe/dial_peers
+func (env *Environment) UnsafeDialPeers(
+	ctx *rpctypes.Context,
+	peers []string,
+	persistent bool,
+) (*coretypes.ResultDialPeers, error) {
+	if len(peers) == 0 {
+		return nil, fmt.Errorf("%w: no peers provided", coretypes.ErrInvalidRequest)
+	}
+
+	addresses := make([]p2p.NodeAddress, 0, len(peers))
+	for _, peer := range peers {
+		address, err := p2p.ParseNodeAddress(peer)
+		if err != nil {
+			return nil, fmt.Errorf("%w: invalid peer address %q: %v", coretypes.ErrInvalidRequest, peer, err)
+		}
+		addresses = append(addresses, address)
+	}
+
+	for _, address := range addresses {
+		if _, err := env.PeerManager.Add(address); err != nil {
+			return nil, fmt.Errorf("failed to add peer %q: %w", address, err)
+		}
+		if persistent {
+			if err := env.PeerManager.SetPersistent(address.NodeID); err != nil {
+				return nil, fmt.Errorf("failed to make peer %q persistent: %w", address, err)
+			}
+		}
+	}
+
+	return &coretypes.ResultDialPeers{Log: "Dialing peers in progress. See /net_info for details"}, nil
+}
+
+// UnsafeRemovePeer removes a peer and its addresses from the peer store,
+// disconnecting it if it is connected.
+// More: https://docs.tendermint.com/master/rpc/#/Unsafe/remove_peer
+func (env *Environment) UnsafeRemovePeer(ctx *rpctypes.Context, peerID types.NodeID) (*coretypes.ResultRemovePeer, error) {
+	if err := env.PeerManager.Remove(peerID); err != nil {
+		return nil, err
+	}
+	return &coretypes.ResultRemovePeer{}, nil
+}
+
+// UnsafeBanPeer bans a node ID or an IP address for the given duration, e.g.
+// "24h", or permanently if no duration is given. Banned peers are
+// disconnected and not dialed or accepted again until the ban expires or is
+// lifted with unban_peer. Bans persist across restarts.
+// More: https://docs.tendermint.com/master/rpc/#/Unsafe/ban_peer
+func (env *Environment) UnsafeBanPeer(
+	ctx *rpctypes.Context,
+	peer string,
+	duration string,
+) (*coretypes.ResultBanPeer, error) {
+	var expires time.Time
+	if duration != "" {
+		d, err := time.ParseDuration(duration)
+		if err != nil {
+			return nil, fmt.Errorf("%w: invalid duration %q: %v", coretypes.ErrInvalidRequest, duration, err)
+		}
+		if d <= 0 {
+			return nil, fmt.Errorf("%w: duration must be positive", coretypes.ErrInvalidRequest)
+		}
+		expires = time.Now().Add(d).UTC()
+	}
+
+	ban, err := env.PeerManager.Ban(peer, expires)
+	if err != nil {
+		return nil, err
+	}
+	return &coretypes.ResultBanPeer{Ban: coretypes.PeerBan(ban)}, nil
+}
+
+// UnsafeUnbanPeer lifts the ban of a node ID or an IP address.
+// More: https://docs.tendermint.com/master/rpc/#/Unsafe/unban_peer
+func (env *Environment) UnsafeUnbanPeer(ctx *rpctypes.Context, peer string) (*coretypes.ResultUnbanPeer, error) {
+	if err := env.PeerManager.Unban(peer); err != nil {
+		return nil, err
+	}
+	return &coretypes.ResultUnbanPeer{}, nil
+}
+
+// UnsafeListPeers returns all peers in the peer store, along with the dial
+// history of their addresses, and the active bans.
+// More: https://docs.tendermint.com/master/rpc/#/Unsafe/list_peers
+func (env *Environment) UnsafeListPeers(ctx *rpctypes.Context) (*coretypes.ResultListPeers, error) {
+	records := env.PeerManager.Records()
+	peers := make([]coretypes.PeerInfo, 0, len(records))
+	for _, record := range records {
+		addresses := make([]coretypes.PeerAddressInfo, 0, len(record.Addresses))
+		for _, address := range record.Addresses {
+			addresses = append(addresses, coretypes.PeerAddressInfo(address))
+		}
+		peers = append(peers, coretypes.PeerInfo{
+			ID:            record.ID,
+			Connected:     env.PeerManager.Status(record.ID) == p2p.PeerStatusUp,
+			LastConnected: record.LastConnected,
+			Addresses:     addresses,
+		})
+	}
+
+	bans := env.PeerManager.Bans()
+	result := &coretypes.ResultListPeers{
+		Peers: peers,
+		Bans:  make([]coretypes.PeerBan, 0, len(bans)),
+	}
+	for _, ban := range bans {
+		result.Bans = append(result.Bans, coretypes.PeerBan(ban))
+	}
+	return result, nil
+}
+
+// CrawledPeers returns the peers crawled by a seed node, with their NodeInfo
+// and dial history, ordered by node ID, along with a summary of the network.
+// More: https://docs.tendermint.com/master/rpc/#/Info/crawled_peers
+func (env *Environment) CrawledPeers(
+	ctx *rpctypes.Context,
+	pagePtr, perPagePtr *int,
+) (*coretypes.ResultCrawledPeers, error) {
+	if env.Crawler == nil {
+		return nil, errors.New("the node is not crawling the network")
+	}
+
+	crawled := env.Crawler.CrawledPeers()
+	result := &coretypes.ResultCrawledPeers{
+		TotalCount: len(crawled),
+		Versions:   map[string]int{},
+	}
+	for _, peer := range crawled {
+		if !peer.Reachable() {
+			continue
+		}
+		result.Reachable++
+		if peer.Compatible {
+			result.Compatible++
+		}
+		result.Versions[peer.NodeInfo.Version]++
+	}
+
+	perPage := env.validatePerPage(perPagePtr)
+	page, err := validatePage(pagePtr, perPage, len(crawled))
+	if err != nil {
+		return nil, err
+	}
+	skipCount := validateSkipCount(page, perPage)
+	pageSize := tmmath.MinInt(perPage, len(crawled)-skipCount)
+
+	result.Peers = make([]coretypes.CrawledPeer, 0, pageSize)
+	for _, peer := range crawled[skipCount : skipCount+pageSize] {
+		result.Peers = append(result.Peers, coretypes.CrawledPeer(peer))
+	}
+	return result, nil
+}
+
 // Genesis returns genesis file.
 // More: https://docs.tendermint.com/master/rpc/#/Info/genesis
 func (env *Environment) Genesis(ctx *rpctypes.Context) (*coretypes.ResultGenesis, error) {
@@ -0,0 +1,125 @@
+package core
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	dbm "github.com/tendermint/tm-db"
+
+	"github.com/tendermint/tendermint/internal/p2p"
+	"github.com/tendermint/tendermint/internal/p2p/pex"
+	"github.com/tendermint/tendermint/rpc/coretypes"
+	rpctypes "github.com/tendermint/tendermint/rpc/jsonrpc/types"
+	"github.com/tendermint/tendermint/types"
+)
+
+func TestUnsafePeerManagement(t *testing.T) {
+	selfID := types.NodeID(strings.Repeat("a", 40))
+	peerID := types.NodeID(strings.Repeat("b", 40))
+	peerManager, err := p2p.NewPeerManager(selfID, dbm.NewMemDB(), p2p.PeerManagerOptions{})
+	require.NoError(t, err)
+
+	env := &Environment{PeerManager: peerManager}
+	ctx := &rpctypes.Context{}
+
+	_, err = env.UnsafeDialPeers(ctx, nil, false)
+	require.Error(t, err)
+	_, err = env.UnsafeDialPeers(ctx, []string{"foo"}, false)
+	require.Error(t, err)
+
+	_, err = env.UnsafeDialPeers(ctx, []string{string(peerID) + "@127.0.0.1:26656"}, true)
+	require.NoError(t, err)
+
+	list, err := env.UnsafeListPeers(ctx)
+	require.NoError(t, err)
+	require.Len(t, list.Peers, 1)
+	require.Equal(t, peerID, list.Peers[0].ID)
+	require.False(t, list.Peers[0].Connected)
+	require.Len(t, list.Peers[0].Addresses, 1)
+	require.Empty(t, list.Bans)
+
+	_, err = env.UnsafeBanPeer(ctx, string(peerID), "-1h")
+	require.Error(t, err)
+	ban, err := env.UnsafeBanPeer(ctx, string(peerID), "1h")
+	require.NoError(t, err)
+	require.Equal(t, string(peerID), ban.Ban.Peer)
+	require.False(t, ban.Ban.Expires.IsZero())
+	_, err = env.UnsafeBanPeer(ctx, "10.0.0.1", "")
+	require.NoError(t, err)
+
+	list, err = env.UnsafeListPeers(ctx)
+	require.NoError(t, err)
+	require.Len(t, list.Bans, 2)
+	require.Equal(t, "10.0.0.1", list.Bans[0].Peer)
+	require.True(t, list.Bans[0].Expires.IsZero())
+
+	_, err = env.UnsafeUnbanPeer(ctx, "10.0.0.1")
+	require.NoError(t, err)
+	_, err = env.UnsafeUnbanPeer(ctx, "10.0.0.1")
+	require.Error(t, err)
+
+	_, err = env.UnsafeRemovePeer(ctx, peerID)
+	require.NoError(t, err)
+	_, err = env.UnsafeRemovePeer(ctx, peerID)
+	require.Error(t, err)
+
+	list, err = env.UnsafeListPeers(ctx)
+	require.NoError(t, err)
+	require.Empty(t, list.Peers)
+	require.Len(t, list.Bans, 1)
+}
+
+type testCrawler []pex.CrawledPeer
+
+func (c testCrawler) CrawledPeers() []pex.CrawledPeer { return c }
+
+func TestCrawledPeers(t *testing.T) {
+	now := time.Now().UTC()
+	crawled := testCrawler{
+		{
+			ID:            types.NodeID(strings.Repeat("a", 40)),
+			NodeInfo:      &types.NodeInfo{Version: "0.35.0"},
+			Compatible:    true,
+			UpSince:       now,
+			DialAttempts:  1,
+			DialSuccesses: 1,
+		},
+		{
+			ID:            types.NodeID(strings.Repeat("b", 40)),
+			NodeInfo:      &types.NodeInfo{Version: "0.34.0"},
+			UpSince:       now,
+			DialAttempts:  2,
+			DialSuccesses: 1,
+		},
+		{
+			ID:           types.NodeID(strings.Repeat("c", 40)),
+			DialAttempts: 1,
+		},
+	}
+	ctx := &rpctypes.Context{}
+
+	_, err := (&Environment{}).CrawledPeers(ctx, nil, nil)
+	require.Error(t, err)
+
+	env := &Environment{Crawler: crawled}
+	result, err := env.CrawledPeers(ctx, nil, nil)
+	require.NoError(t, err)
+	require.Equal(t, 3, result.TotalCount)
+	require.Equal(t, 2, result.Reachable)
+	require.Equal(t, 1, result.Compatible)
+	require.Equal(t, map[string]int{"0.35.0": 1, "0.34.0": 1}, result.Versions)
+	require.Len(t, result.Peers, 3)
+	require.Equal(t, coretypes.CrawledPeer(crawled[1]), result.Peers[1])
+
+	page, perPage := 2, 2
+	result, err = env.CrawledPeers(ctx, &page, &perPage)
+	require.NoError(t, err)
+	require.Equal(t, 3, result.TotalCount)
+	require.Equal(t, []coretypes.CrawledPeer{coretypes.CrawledPeer(crawled[2])}, result.Peers)
+
+	page = 3
+	_, err = env.CrawledPeers(ctx, &page, &perPage)
+	require.Error(t, err)
+}
@@ -38,6 +38,7 @@ func (env *Environment) GetRoutes() RoutesMap {
 		"consensus_params":     rpc.NewRPCFunc(env.ConsensusParams, "height", true),
 		"unconfirmed_txs":      rpc.NewRPCFunc(env.UnconfirmedTxs, "limit", false),
 		"num_unconfirmed_txs":  rpc.NewRPCFunc(env.NumUnconfirmedTxs, "", false),
+		"mempool_tx":           rpc.NewRPCFunc(env.MempoolTx, "hash", false),
 
 		// tx broadcast API
 		"broadcast_tx_commit": rpc.NewRPCFunc(env.BroadcastTxCommit, "tx", false),
@@ -50,6 +51,20 @@ func (env *Environment) GetRoutes() RoutesMap {
 
 		// evidence API
 		"broadcast_evidence": rpc.NewRPCFunc(env.BroadcastEvidence, "evidence", false),
+		"pending_evidence":   rpc.NewRPCFunc(env.PendingEvidence, "page,per_page", false),
+		"committed_evidence": rpc.NewRPCFunc(env.CommittedEvidence, "height,validator,page,per_page", false),
+		"evidence":           rpc.NewRPCFunc(env.Evidence, "hash", false),
+		"evidence_search":    rpc.NewRPCFunc(env.EvidenceSearch, "query,page,per_page,order_by", false),
+	}
+}
+
+// GetCrawlerRoutes returns the routes served by seed nodes crawling the
+// network.
+func (env *Environment) GetCrawlerRoutes() RoutesMap {
+	return RoutesMap{
+		"health":        rpc.NewRPCFunc(env.Health, "", false),
+		"net_info":      rpc.NewRPCFunc(env.NetInfo, "", false),
+		"crawled_peers": rpc.NewRPCFunc(env.CrawledPeers, "page,per_page", false),
 	}
 }
 
@@ -57,4 +72,11 @@ func (env *Environment) GetRoutes() RoutesMap {
 func (env *Environment) AddUnsafe(routes RoutesMap) {
 	// control API
 	routes["unsafe_flush_mempool"] = rpc.NewRPCFunc(env.UnsafeFlushMempool, "", false)
+
+	// peer management API
+	routes["dial_peers"] = rpc.NewRPCFunc(env.UnsafeDialPeers, "peers,persistent", false)
+	routes["remove_peer"] = rpc.NewRPCFunc(env.UnsafeRemovePeer, "peer_id", false)
+	routes["ban_peer"] = rpc.NewRPCFunc(env.UnsafeBanPeer, "peer,duration", false)
+	routes["unban_peer"] = rpc.NewRPCFunc(env.UnsafeUnbanPeer, "peer", false)
+	routes["list_peers"] = rpc.NewRPCFunc(env.UnsafeListPeers, "", false)
 }
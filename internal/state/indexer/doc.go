@@ -1,10 +1,13 @@
 /*
 Package indexer defines Tendermint's block and transaction event indexing logic.
 
-Tendermint supports two primary means of block and transaction event indexing:
+Tendermint supports the following means of block and transaction event indexing:
 
 1. A key-value sink via an embedded database with a proprietary query language.
 2. A Postgres-based sink.
+3. A webhook sink, posting the events to HTTP receivers.
+4. An SQLite-based sink, with the relational layout of the Postgres-based sink
+   and support for the query language of the key-value sink.
 
 An ABCI application can emit events during block and transaction execution in the form
 
@@ -12,7 +15,7 @@ An ABCI application can emit events during block and transaction execution in th
 
 for example "transfer.amount=10000".
 
-An operator can enable one or both of the supported indexing sinks via the
+An operator can enable one or more of the supported indexing sinks via the
 'tx-index.indexer' Tendermint configuration.
 
 Example:
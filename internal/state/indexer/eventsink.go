@@ -11,9 +11,11 @@ import (
 type EventSinkType string
 
 const (
-	NULL EventSinkType = "null"
-	KV   EventSinkType = "kv"
-	PSQL EventSinkType = "psql"
+	NULL    EventSinkType = "null"
+	KV      EventSinkType = "kv"
+	PSQL    EventSinkType = "psql"
+	WEBHOOK EventSinkType = "webhook"
+	SQLITE  EventSinkType = "sqlite"
 )
 
 //go:generate ../../../scripts/mockery_generate.sh EventSink
@@ -32,20 +34,20 @@ type EventSink interface {
 	// must guarantee the index of given transactions are in order.
 	IndexTxEvents([]*abci.TxResult) error
 
-	// SearchBlockEvents provides the block search by given query conditions. This function only
-	// supported by the kvEventSink.
+	// SearchBlockEvents provides the block search by given query conditions. This function is only
+	// supported by the kv and sqlite event sinks.
 	SearchBlockEvents(context.Context, *query.Query) ([]int64, error)
 
-	// SearchTxEvents provides the transaction search by given query conditions. This function only
-	// supported by the kvEventSink.
+	// SearchTxEvents provides the transaction search by given query conditions. This function is only
+	// supported by the kv and sqlite event sinks.
 	SearchTxEvents(context.Context, *query.Query) ([]*abci.TxResult, error)
 
-	// GetTxByHash provides the transaction search by given transaction hash. This function only
-	// supported by the kvEventSink.
+	// GetTxByHash provides the transaction search by given transaction hash. This function is only
+	// supported by the kv and sqlite event sinks.
 	GetTxByHash([]byte) (*abci.TxResult, error)
 
-	// HasBlock provides the transaction search by given transaction hash. This function only
-	// supported by the kvEventSink.
+	// HasBlock provides the transaction search by given transaction hash. This function is only
+	// supported by the kv and sqlite event sinks.
 	HasBlock(int64) (bool, error)
 
 	// Type checks the eventsink structure type.
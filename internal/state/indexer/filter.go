@@ -0,0 +1,203 @@
+package indexer
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	abci "github.com/tendermint/tendermint/abci/types"
+	"github.com/tendermint/tendermint/libs/pubsub/query"
+	"github.com/tendermint/tendermint/types"
+)
+
+// EventFilter selects the event attributes an event sink indexes, with allow
+// and deny rules matched against the composite keys ("type.key") of the
+// attributes. The rules are glob patterns, where "*" matches any sequence of
+// characters and "?" a single character.
+type EventFilter struct {
+	allow []string
+	deny  []string
+}
+
+// NewEventFilter returns the filter of the given sink type from the allow and
+// deny rules of the configuration (see config.TxIndexConfig). Rules prefixed
+// with the name of another sink type are ignored. It returns nil if no rule
+// applies to the sink type.
+func NewEventFilter(sinkType EventSinkType, allow, deny []string) *EventFilter {
+	f := &EventFilter{
+		allow: sinkRules(sinkType, allow),
+		deny:  sinkRules(sinkType, deny),
+	}
+	if len(f.allow) == 0 && len(f.deny) == 0 {
+		return nil
+	}
+	return f
+}
+
+// sinkRules returns the patterns of the rules applying to the sink type.
+func sinkRules(sinkType EventSinkType, rules []string) []string {
+	var patterns []string
+	for _, rule := range rules {
+		if i := strings.Index(rule, ":"); i >= 0 {
+			switch EventSinkType(rule[:i]) {
+			case sinkType:
+				rule = rule[i+1:]
+			case KV, PSQL, WEBHOOK, SQLITE:
+				continue
+			}
+		}
+		patterns = append(patterns, rule)
+	}
+	return patterns
+}
+
+// Indexes reports whether the attributes with the given composite key are
+// indexed. The reserved keys of the transaction hash and height and of the
+// block height are always indexed.
+func (f *EventFilter) Indexes(compositeKey string) bool {
+	switch compositeKey {
+	case types.TxHashKey, types.TxHeightKey, types.BlockHeightKey:
+		return true
+	}
+
+	if len(f.allow) > 0 && !matchAny(f.allow, compositeKey) {
+		return false
+	}
+	return !matchAny(f.deny, compositeKey)
+}
+
+// Apply returns the events with the Index flag cleared on the attributes the
+// filter excludes. The given events are not modified.
+func (f *EventFilter) Apply(events []abci.Event) []abci.Event {
+	var filtered []abci.Event
+	for i, event := range events {
+		var attrs []abci.EventAttribute
+		for j, attr := range event.Attributes {
+			if !attr.Index || f.Indexes(event.Type+"."+attr.Key) {
+				continue
+			}
+			if attrs == nil {
+				attrs = make([]abci.EventAttribute, len(event.Attributes))
+				copy(attrs, event.Attributes)
+			}
+			attrs[j].Index = false
+		}
+		if attrs == nil {
+			continue
+		}
+
+		if filtered == nil {
+			filtered = make([]abci.Event, len(events))
+			copy(filtered, events)
+		}
+		filtered[i].Attributes = attrs
+	}
+
+	if filtered == nil {
+		return events
+	}
+	return filtered
+}
+
+// CheckQuery returns an error if a condition of the query is on a composite
+// key which is not indexed.
+func (f *EventFilter) CheckQuery(q *query.Query) error {
+	conditions, err := q.Conditions()
+	if err != nil {
+		return fmt.Errorf("error during parsing conditions from query: %w", err)
+	}
+	for _, c := range conditions {
+		if strings.Contains(c.CompositeKey, ".") && !f.Indexes(c.CompositeKey) {
+			return fmt.Errorf("the query condition on %q can't match anything, since the event "+
+				"attribute is not indexed (see tx-index.index-allow and tx-index.index-deny)", c.CompositeKey)
+		}
+	}
+	return nil
+}
+
+// matchAny reports whether s matches one of the patterns.
+func matchAny(patterns []string, s string) bool {
+	for _, pattern := range patterns {
+		if matchPattern(pattern, s) {
+			return true
+		}
+	}
+	return false
+}
+
+// matchPattern reports whether s matches the glob pattern, where "*" matches
+// any sequence of characters and "?" a single character.
+func matchPattern(pattern, s string) bool {
+	// the positions to resume from after the last "*", if any
+	star, next := -1, 0
+
+	p, i := 0, 0
+	for i < len(s) {
+		switch {
+		case p < len(pattern) && pattern[p] == '*':
+			star, next = p, i
+			p++
+		case p < len(pattern) && (pattern[p] == '?' || pattern[p] == s[i]):
+			p++
+			i++
+		case star >= 0:
+			// let the last "*" match one more character
+			next++
+			p, i = star+1, next
+		default:
+			return false
+		}
+	}
+	for p < len(pattern) && pattern[p] == '*' {
+		p++
+	}
+	return p == len(pattern)
+}
+
+// filteredSink is an EventSink applying an EventFilter to the events it
+// indexes, and rejecting the queries on attributes it doesn't index.
+type filteredSink struct {
+	EventSink
+	filter *EventFilter
+}
+
+// WithEventFilter returns a sink indexing the events with the given sink,
+// after applying the filter to them. Its searches report an error if the
+// query has a condition on attributes the filter excludes. If filter is nil,
+// sink is returned.
+func WithEventFilter(sink EventSink, filter *EventFilter) EventSink {
+	if filter == nil {
+		return sink
+	}
+	return &filteredSink{EventSink: sink, filter: filter}
+}
+
+func (fs *filteredSink) IndexBlockEvents(h types.EventDataNewBlockHeader) error {
+	h.ResultBeginBlock.Events = fs.filter.Apply(h.ResultBeginBlock.Events)
+	h.ResultEndBlock.Events = fs.filter.Apply(h.ResultEndBlock.Events)
+	return fs.EventSink.IndexBlockEvents(h)
+}
+
+func (fs *filteredSink) IndexTxEvents(txrs []*abci.TxResult) error {
+	filtered := make([]*abci.TxResult, len(txrs))
+	for i, txr := range txrs {
+		copied := *txr
+		copied.Result.Events = fs.filter.Apply(txr.Result.Events)
+		filtered[i] = &copied
+	}
+	return fs.EventSink.IndexTxEvents(filtered)
+}
+
+func (fs *filteredSink) SearchBlockEvents(ctx context.Context, q *query.Query) ([]int64, error) {
+	if err := fs.filter.CheckQuery(q); err != nil {
+		return nil, err
+	}
+	return fs.EventSink.SearchBlockEvents(ctx, q)
+}
+
+func (fs *filteredSink) SearchTxEvents(ctx context.Context, q *query.Query) ([]*abci.TxResult, error) {
+	if err := fs.filter.CheckQuery(q); err != nil {
+		return nil, err
+	}
+	return fs.EventSink.SearchTxEvents(ctx, q)
+}
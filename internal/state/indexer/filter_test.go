@@ -0,0 +1,131 @@
+package indexer_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	dbm "github.com/tendermint/tm-db"
+
+	abci "github.com/tendermint/tendermint/abci/types"
+	"github.com/tendermint/tendermint/internal/state/indexer"
+	"github.com/tendermint/tendermint/internal/state/indexer/sink/kv"
+	"github.com/tendermint/tendermint/libs/pubsub/query"
+	"github.com/tendermint/tendermint/types"
+)
+
+func TestEventFilterIndexes(t *testing.T) {
+	testCases := []struct {
+		allow, deny []string
+		key         string
+		indexed     bool
+	}{
+		{nil, []string{"transfer.*"}, "transfer.amount", false},
+		{nil, []string{"transfer.*"}, "transfers.amount", true},
+		{nil, []string{"*.sender"}, "message.sender", false},
+		{nil, []string{"*.sender"}, "message.senders", true},
+		{nil, []string{"message.?ender"}, "message.sender", false},
+		{nil, []string{"message.?ender"}, "message.ender", true},
+		{nil, []string{"*a*b*"}, "xaxxbx", false},
+		{nil, []string{"*a*b"}, "xaxxbx", true},
+		{[]string{"transfer.*", "*.sender"}, nil, "transfer.amount", true},
+		{[]string{"transfer.*", "*.sender"}, nil, "message.sender", true},
+		{[]string{"transfer.*", "*.sender"}, nil, "message.action", false},
+		{[]string{"transfer.*"}, []string{"transfer.memo"}, "transfer.memo", false},
+		{[]string{"transfer.*"}, []string{"transfer.memo"}, "transfer.amount", true},
+		// the reserved keys are always indexed
+		{[]string{"transfer.*"}, []string{"*"}, types.TxHashKey, true},
+		{[]string{"transfer.*"}, []string{"*"}, types.TxHeightKey, true},
+		{[]string{"transfer.*"}, []string{"*"}, types.BlockHeightKey, true},
+		// rules of other sinks are ignored
+		{nil, []string{"kv:transfer.*", "psql:message.*"}, "transfer.amount", false},
+		{nil, []string{"kv:transfer.*", "psql:message.*"}, "message.action", true},
+		// a prefix which is not a sink type is part of the pattern
+		{nil, []string{"ibc:*"}, "ibc:packet.data", false},
+	}
+
+	for _, tc := range testCases {
+		f := indexer.NewEventFilter(indexer.KV, tc.allow, tc.deny)
+		require.NotNil(t, f)
+		assert.Equal(t, tc.indexed, f.Indexes(tc.key), "allow %v, deny %v, key %q", tc.allow, tc.deny, tc.key)
+	}
+
+	assert.Nil(t, indexer.NewEventFilter(indexer.KV, nil, nil))
+	assert.Nil(t, indexer.NewEventFilter(indexer.KV, []string{"psql:transfer.*"}, []string{"sqlite:*"}))
+}
+
+func TestEventFilterApply(t *testing.T) {
+	f := indexer.NewEventFilter(indexer.KV, nil, []string{"transfer.memo"})
+
+	events := []abci.Event{
+		{Type: "message", Attributes: []abci.EventAttribute{{Key: "memo", Value: "a", Index: true}}},
+		{Type: "transfer", Attributes: []abci.EventAttribute{
+			{Key: "amount", Value: "10", Index: true},
+			{Key: "memo", Value: "b", Index: true},
+		}},
+	}
+	filtered := f.Apply(events)
+	require.Len(t, filtered, 2)
+	assert.True(t, filtered[0].Attributes[0].Index)
+	assert.True(t, filtered[1].Attributes[0].Index)
+	assert.False(t, filtered[1].Attributes[1].Index)
+	assert.Equal(t, "b", filtered[1].Attributes[1].Value)
+
+	// the events passed in are not modified
+	assert.True(t, events[1].Attributes[1].Index)
+}
+
+func TestWithEventFilter(t *testing.T) {
+	sink := kv.NewEventSink(dbm.NewMemDB())
+	assert.Equal(t, sink, indexer.WithEventFilter(sink, nil))
+
+	es := indexer.WithEventFilter(sink, indexer.NewEventFilter(indexer.KV, nil, []string{"*.memo", "end_event.*"}))
+	assert.Equal(t, indexer.KV, es.Type())
+
+	require.NoError(t, es.IndexBlockEvents(types.EventDataNewBlockHeader{
+		Header: types.Header{Height: 1},
+		ResultEndBlock: abci.ResponseEndBlock{Events: []abci.Event{
+			{Type: "end_event", Attributes: []abci.EventAttribute{{Key: "foo", Value: "1", Index: true}}},
+		}},
+	}))
+	txr := &abci.TxResult{
+		Height: 1,
+		Tx:     types.Tx("tx"),
+		Result: abci.ResponseDeliverTx{Events: []abci.Event{
+			{Type: "transfer", Attributes: []abci.EventAttribute{
+				{Key: "amount", Value: "10", Index: true},
+				{Key: "memo", Value: "hello", Index: true},
+			}},
+		}},
+	}
+	require.NoError(t, es.IndexTxEvents([]*abci.TxResult{txr}))
+	assert.True(t, txr.Result.Events[0].Attributes[1].Index)
+
+	ctx := context.Background()
+	results, err := es.SearchTxEvents(ctx, query.MustParse("transfer.amount = 10"))
+	require.NoError(t, err)
+	require.Len(t, results, 1)
+	assert.False(t, results[0].Result.Events[0].Attributes[1].Index)
+
+	results, err = es.SearchTxEvents(ctx, query.MustParse("tx.height = 1"))
+	require.NoError(t, err)
+	assert.Len(t, results, 1)
+
+	_, err = es.SearchTxEvents(ctx, query.MustParse("tx.height = 1 AND transfer.memo = 'hello'"))
+	assert.Error(t, err)
+	// the attributes were not indexed by the underlying sink either
+	results, err = sink.SearchTxEvents(ctx, query.MustParse("transfer.memo = 'hello'"))
+	require.NoError(t, err)
+	assert.Empty(t, results)
+
+	heights, err := es.SearchBlockEvents(ctx, query.MustParse("block.height = 1"))
+	require.NoError(t, err)
+	assert.Equal(t, []int64{1}, heights)
+	_, err = es.SearchBlockEvents(ctx, query.MustParse("end_event.foo = 1"))
+	assert.Error(t, err)
+
+	ok, err := es.HasBlock(1)
+	require.NoError(t, err)
+	assert.True(t, ok)
+}
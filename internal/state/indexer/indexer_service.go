@@ -157,10 +157,26 @@ func KVSinkEnabled(sinks []EventSink) bool {
 	return false
 }
 
+// SearchSink returns the sink of the given eventSinks serving the search and
+// lookup queries of the RPC, or nil if none of them supports them. The kv sink
+// is preferred to the sqlite sink.
+func SearchSink(sinks []EventSink) EventSink {
+	for _, typ := range []EventSinkType{KV, SQLITE} {
+		for _, sink := range sinks {
+			if sink.Type() == typ {
+				return sink
+			}
+		}
+	}
+
+	return nil
+}
+
 // IndexingEnabled returns the given eventSinks is supporting the indexing services.
 func IndexingEnabled(sinks []EventSink) bool {
 	for _, sink := range sinks {
-		if sink.Type() == KV || sink.Type() == PSQL {
+		switch sink.Type() {
+		case KV, PSQL, WEBHOOK, SQLITE:
 			return true
 		}
 	}
@@ -2,6 +2,7 @@ package sink
 
 import (
 	"errors"
+	"path/filepath"
 	"strings"
 
 	"github.com/tendermint/tendermint/config"
@@ -9,13 +10,17 @@ import (
 	"github.com/tendermint/tendermint/internal/state/indexer/sink/kv"
 	"github.com/tendermint/tendermint/internal/state/indexer/sink/null"
 	"github.com/tendermint/tendermint/internal/state/indexer/sink/psql"
+	"github.com/tendermint/tendermint/internal/state/indexer/sink/sqlite"
+	"github.com/tendermint/tendermint/internal/state/indexer/sink/webhook"
+	"github.com/tendermint/tendermint/libs/log"
+	tmos "github.com/tendermint/tendermint/libs/os"
 )
 
 // EventSinksFromConfig constructs a slice of indexer.EventSink using the provided
 // configuration.
 //
 //nolint:lll
-func EventSinksFromConfig(cfg *config.Config, dbProvider config.DBProvider, chainID string) ([]indexer.EventSink, error) {
+func EventSinksFromConfig(cfg *config.Config, dbProvider config.DBProvider, chainID string, logger log.Logger) ([]indexer.EventSink, error) {
 	if len(cfg.TxIndex.Indexer) == 0 {
 		return []indexer.EventSink{null.NewEventSink()}, nil
 	}
@@ -56,10 +61,72 @@ func EventSinksFromConfig(cfg *config.Config, dbProvider config.DBProvider, chai
 				return nil, err
 			}
 			eventSinks = append(eventSinks, es)
+
+		case indexer.WEBHOOK:
+			es, err := NewWebhookEventSink(cfg, dbProvider, chainID, logger)
+			if err != nil {
+				return nil, err
+			}
+			eventSinks = append(eventSinks, es)
+
+		case indexer.SQLITE:
+			es, err := NewSQLiteEventSink(cfg, chainID)
+			if err != nil {
+				return nil, err
+			}
+			eventSinks = append(eventSinks, es)
+
 		default:
 			return nil, errors.New("unsupported event sink type")
 		}
 	}
-	return eventSinks, nil
+	return WithEventFilters(cfg, eventSinks), nil
 
 }
+
+// WithEventFilters applies the event filters configured in cfg to the sinks.
+func WithEventFilters(cfg *config.Config, sinks []indexer.EventSink) []indexer.EventSink {
+	filtered := make([]indexer.EventSink, len(sinks))
+	for i, sink := range sinks {
+		filter := indexer.NewEventFilter(sink.Type(), cfg.TxIndex.IndexAllow, cfg.TxIndex.IndexDeny)
+		filtered[i] = indexer.WithEventFilter(sink, filter)
+	}
+	return filtered
+}
+
+// NewWebhookEventSink creates the webhook event sink configured in cfg, with
+// its outbox in the "webhook_outbox" database.
+func NewWebhookEventSink(
+	cfg *config.Config,
+	dbProvider config.DBProvider,
+	chainID string,
+	logger log.Logger,
+) (*webhook.EventSink, error) {
+	if err := cfg.TxIndex.ValidateBasic(); err != nil {
+		return nil, err
+	}
+	store, err := dbProvider(&config.DBContext{ID: "webhook_outbox", Config: cfg})
+	if err != nil {
+		return nil, err
+	}
+	es, err := webhook.NewEventSink(store, chainID, webhook.Config{
+		URLs:      cfg.TxIndex.WebhookURLs,
+		Secret:    []byte(cfg.TxIndex.WebhookSecret),
+		BatchSize: cfg.TxIndex.WebhookBatchSize,
+		Timeout:   cfg.TxIndex.WebhookTimeout,
+	}, logger.With("sink", "webhook"))
+	if err != nil {
+		store.Close()
+		return nil, err
+	}
+	return es, nil
+}
+
+// NewSQLiteEventSink opens the sqlite event sink, which is stored in the
+// "tx_index.sqlite" file of the database directory.
+func NewSQLiteEventSink(cfg *config.Config, chainID string) (*sqlite.EventSink, error) {
+	if err := tmos.EnsureDir(cfg.DBDir(), config.DefaultDirPerm); err != nil {
+		return nil, err
+	}
+	return sqlite.NewEventSink(filepath.Join(cfg.DBDir(), "tx_index.sqlite"), chainID)
+}
@@ -0,0 +1,590 @@
+// Package sqlite implements an event sink backed by an embedded SQLite
+// database.
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	_ "embed" // for the schema
+	"encoding/hex"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gogo/protobuf/proto"
+
+	abci "github.com/tendermint/tendermint/abci/types"
+	"github.com/tendermint/tendermint/internal/state/indexer"
+	"github.com/tendermint/tendermint/libs/pubsub/query"
+	"github.com/tendermint/tendermint/types"
+
+	// Register the SQLite database driver.
+	_ "modernc.org/sqlite"
+)
+
+const (
+	tableBlocks     = "blocks"
+	tableTxResults  = "tx_results"
+	tableEvents     = "events"
+	tableAttributes = "attributes"
+	driverName      = "sqlite"
+
+	// The connection settings: enforce the foreign keys of the schema, let
+	// readers proceed while the indexer writes, wait for the lock instead of
+	// failing when the database is busy, and take the write lock when a
+	// transaction begins, since all transactions write.
+	dsnParams = "?_pragma=foreign_keys(1)&_pragma=journal_mode(WAL)" +
+		"&_pragma=busy_timeout(10000)&_txlock=immediate"
+)
+
+//go:embed schema.sql
+var schema string
+
+var _ indexer.EventSink = (*EventSink)(nil)
+
+// EventSink is an indexer backend providing the tx/block index services. This
+// implementation stores records in an SQLite database using the schema
+// defined in state/indexer/sink/sqlite/schema.sql, which follows the layout of
+// the psql event sink, and supports searching them.
+type EventSink struct {
+	store   *sql.DB
+	chainID string
+}
+
+// NewEventSink opens the SQLite database at path, creating it and installing
+// the schema if needed. Events written to the sink are attributed to the
+// specified chainID.
+func NewEventSink(path, chainID string) (*EventSink, error) {
+	db, err := sql.Open(driverName, path+dsnParams)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := db.Exec(schema); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("installing schema: %w", err)
+	}
+
+	return &EventSink{
+		store:   db,
+		chainID: chainID,
+	}, nil
+}
+
+// DB returns the underlying SQLite connection used by the sink.
+// This is exported to support testing.
+func (es *EventSink) DB() *sql.DB { return es.store }
+
+// Type returns the structure type for this sink, which is SQLite.
+func (es *EventSink) Type() indexer.EventSinkType { return indexer.SQLITE }
+
+// runInTransaction executes query in a fresh database transaction.
+// If query reports an error, the transaction is rolled back and the
+// error from query is reported to the caller.
+// Otherwise, the result of committing the transaction is returned.
+func runInTransaction(db *sql.DB, query func(*sql.Tx) error) error {
+	dbtx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	if err := query(dbtx); err != nil {
+		_ = dbtx.Rollback() // report the initial error, not the rollback
+		return err
+	}
+	return dbtx.Commit()
+}
+
+// queryWithID executes the specified SQL query with the given arguments,
+// expecting a single-row, single-column result containing an ID. If the query
+// succeeds, the ID from the result is returned.
+func queryWithID(tx *sql.Tx, query string, args ...interface{}) (int64, error) {
+	var id int64
+	if err := tx.QueryRow(query, args...).Scan(&id); err != nil {
+		return 0, err
+	}
+	return id, nil
+}
+
+// insertEvents inserts a slice of events and any indexed attributes of those
+// events into the database associated with dbtx.
+//
+// If txID > 0, the event is attributed to the Tendermint transaction with that
+// ID; otherwise it is recorded as a block event.
+func insertEvents(dbtx *sql.Tx, blockID, txID int64, evts []abci.Event) error {
+	// Populate the transaction ID field iff one is defined (> 0).
+	var txIDArg interface{}
+	if txID > 0 {
+		txIDArg = txID
+	}
+
+	// Add each event to the events table, and retrieve its row ID to use when
+	// adding any attributes the event provides.
+	for _, evt := range evts {
+		// Skip events with an empty type.
+		if evt.Type == "" {
+			continue
+		}
+
+		eid, err := queryWithID(dbtx, `
+INSERT INTO `+tableEvents+` (block_id, tx_id, type) VALUES (?, ?, ?)
+  RETURNING rowid;
+`, blockID, txIDArg, evt.Type)
+		if err != nil {
+			return err
+		}
+
+		// Add any attributes flagged for indexing.
+		for _, attr := range evt.Attributes {
+			if !attr.Index {
+				continue
+			}
+			compositeKey := evt.Type + "." + attr.Key
+			if _, err := dbtx.Exec(`
+INSERT INTO `+tableAttributes+` (event_id, key, composite_key, value)
+  VALUES (?, ?, ?, ?);
+`, eid, attr.Key, compositeKey, attr.Value); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// makeIndexedEvent constructs an event from the specified composite key and
+// value. If the key has the form "type.name", the event will have a single
+// attribute with that name and the value; otherwise the event will have only
+// a type and no attributes.
+func makeIndexedEvent(compositeKey, value string) abci.Event {
+	i := strings.Index(compositeKey, ".")
+	if i < 0 {
+		return abci.Event{Type: compositeKey}
+	}
+	return abci.Event{Type: compositeKey[:i], Attributes: []abci.EventAttribute{
+		{Key: compositeKey[i+1:], Value: value, Index: true},
+	}}
+}
+
+// IndexBlockEvents indexes the specified block header, part of the
+// indexer.EventSink interface.
+func (es *EventSink) IndexBlockEvents(h types.EventDataNewBlockHeader) error {
+	ts := time.Now().UTC()
+
+	return runInTransaction(es.store, func(dbtx *sql.Tx) error {
+		// Add the block to the blocks table and report back its row ID for use
+		// in indexing the events for the block.
+		blockID, err := queryWithID(dbtx, `
+INSERT INTO `+tableBlocks+` (height, chain_id, created_at)
+  VALUES (?, ?, ?)
+  ON CONFLICT DO NOTHING
+  RETURNING rowid;
+`, h.Header.Height, es.chainID, ts)
+		if err == sql.ErrNoRows {
+			return nil // we already saw this block; quietly succeed
+		} else if err != nil {
+			return fmt.Errorf("indexing block header: %w", err)
+		}
+
+		// Insert the special block meta-event for height.
+		if err := insertEvents(dbtx, blockID, 0, []abci.Event{
+			makeIndexedEvent(types.BlockHeightKey, fmt.Sprint(h.Header.Height)),
+		}); err != nil {
+			return fmt.Errorf("block meta-events: %w", err)
+		}
+		// Insert all the block events. Order is important here,
+		if err := insertEvents(dbtx, blockID, 0, h.ResultBeginBlock.Events); err != nil {
+			return fmt.Errorf("begin-block events: %w", err)
+		}
+		if err := insertEvents(dbtx, blockID, 0, h.ResultEndBlock.Events); err != nil {
+			return fmt.Errorf("end-block events: %w", err)
+		}
+		return nil
+	})
+}
+
+// IndexTxEvents indexes the specified transaction results, part of the
+// indexer.EventSink interface. The block of the transactions must have been
+// indexed before.
+func (es *EventSink) IndexTxEvents(txrs []*abci.TxResult) error {
+	ts := time.Now().UTC()
+
+	for _, txr := range txrs {
+		// Encode the result message in protobuf wire format for indexing.
+		resultData, err := proto.Marshal(txr)
+		if err != nil {
+			return fmt.Errorf("marshaling tx_result: %w", err)
+		}
+
+		// Index the hash of the underlying transaction as a hex string.
+		txHash := fmt.Sprintf("%X", types.Tx(txr.Tx).Hash())
+
+		if err := runInTransaction(es.store, func(dbtx *sql.Tx) error {
+			// Find the block associated with this transaction. The block header
+			// must have been indexed prior to the transactions belonging to it.
+			blockID, err := queryWithID(dbtx, `
+SELECT rowid FROM `+tableBlocks+` WHERE height = ? AND chain_id = ?;
+`, txr.Height, es.chainID)
+			if err != nil {
+				return fmt.Errorf("finding block ID: %w", err)
+			}
+
+			// Insert a record for this tx_result and capture its ID for indexing events.
+			txID, err := queryWithID(dbtx, `
+INSERT INTO `+tableTxResults+` (block_id, "index", created_at, tx_hash, tx_result)
+  VALUES (?, ?, ?, ?, ?)
+  ON CONFLICT DO NOTHING
+  RETURNING rowid;
+`, blockID, txr.Index, ts, txHash, resultData)
+			if err == sql.ErrNoRows {
+				return nil // we already saw this transaction; quietly succeed
+			} else if err != nil {
+				return fmt.Errorf("indexing tx_result: %w", err)
+			}
+
+			// Insert the special transaction meta-events for hash and height.
+			if err := insertEvents(dbtx, blockID, txID, []abci.Event{
+				makeIndexedEvent(types.TxHashKey, txHash),
+				makeIndexedEvent(types.TxHeightKey, fmt.Sprint(txr.Height)),
+			}); err != nil {
+				return fmt.Errorf("indexing transaction meta-events: %w", err)
+			}
+			// Index any events packaged with the transaction.
+			if err := insertEvents(dbtx, blockID, txID, txr.Result.Events); err != nil {
+				return fmt.Errorf("indexing transaction events: %w", err)
+			}
+			return nil
+
+		}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// SearchBlockEvents returns the heights of the blocks whose events match all
+// the conditions of q, in no particular order.
+func (es *EventSink) SearchBlockEvents(ctx context.Context, q *query.Query) ([]int64, error) {
+	conditions, err := q.Conditions()
+	if err != nil {
+		return nil, fmt.Errorf("error during parsing conditions from query: %w", err)
+	}
+
+	heights, err := es.search(ctx, blockScope, conditions)
+	if err != nil {
+		return nil, err
+	}
+
+	results := make([]int64, 0, len(heights))
+	for height := range heights {
+		results = append(results, height)
+	}
+	return results, nil
+}
+
+// SearchTxEvents returns the results of the transactions whose events match
+// all the conditions of q, in no particular order. If q has a "tx.hash"
+// condition, only the transaction with that hash is returned.
+func (es *EventSink) SearchTxEvents(ctx context.Context, q *query.Query) ([]*abci.TxResult, error) {
+	conditions, err := q.Conditions()
+	if err != nil {
+		return nil, fmt.Errorf("error during parsing conditions from query: %w", err)
+	}
+
+	// if there is a hash condition, return the result immediately
+	for _, c := range conditions {
+		if c.CompositeKey == types.TxHashKey && c.Op == query.OpEqual {
+			hash, err := hex.DecodeString(fmt.Sprint(c.Operand))
+			if err != nil {
+				return nil, fmt.Errorf("error during searching for a hash in the query: %w", err)
+			}
+			res, err := es.GetTxByHash(hash)
+			switch {
+			case err != nil:
+				return nil, fmt.Errorf("error while retrieving the result: %w", err)
+			case res == nil:
+				return []*abci.TxResult{}, nil
+			default:
+				return []*abci.TxResult{res}, nil
+			}
+		}
+	}
+
+	ids, err := es.search(ctx, txScope, conditions)
+	if err != nil {
+		return nil, err
+	}
+
+	results := make([]*abci.TxResult, 0, len(ids))
+	for id := range ids {
+		var data []byte
+		if err := es.store.QueryRowContext(ctx, `
+SELECT tx_result FROM `+tableTxResults+` WHERE rowid = ?;
+`, id).Scan(&data); err != nil {
+			return nil, fmt.Errorf("loading tx_result: %w", err)
+		}
+		txr := new(abci.TxResult)
+		if err := proto.Unmarshal(data, txr); err != nil {
+			return nil, fmt.Errorf("error reading TxResult: %w", err)
+		}
+		results = append(results, txr)
+	}
+	return results, nil
+}
+
+// searchScope holds the SQL fragments to search the events of blocks or of
+// transactions.
+type searchScope struct {
+	// the column of the matching IDs, and the tables they are selected from
+	id, from string
+	// the join of the events of the scope
+	joinEvents string
+	// the reserved height key, which is matched against the block height
+	heightKey string
+}
+
+var (
+	blockScope = searchScope{
+		id:         "blocks.height",
+		from:       tableBlocks,
+		joinEvents: `JOIN ` + tableEvents + ` ON events.block_id = blocks.rowid AND events.tx_id IS NULL`,
+		heightKey:  types.BlockHeightKey,
+	}
+	txScope = searchScope{
+		id:         "tx_results.rowid",
+		from:       tableTxResults + ` JOIN ` + tableBlocks + ` ON blocks.rowid = tx_results.block_id`,
+		joinEvents: `JOIN ` + tableEvents + ` ON events.tx_id = tx_results.rowid`,
+		heightKey:  types.TxHeightKey,
+	}
+)
+
+// search returns the IDs in scope matching all the conditions.
+func (es *EventSink) search(
+	ctx context.Context,
+	scope searchScope,
+	conditions []query.Condition,
+) (map[int64]struct{}, error) {
+	var ids map[int64]struct{}
+	for i, c := range conditions {
+		matches, err := es.match(ctx, scope, c)
+		if err != nil {
+			return nil, err
+		}
+
+		if i == 0 {
+			ids = matches
+		} else {
+			for id := range ids {
+				if _, ok := matches[id]; !ok {
+					delete(ids, id)
+				}
+			}
+		}
+		// Ignore any remaining conditions if no IDs match so far (assuming
+		// implicit AND operand).
+		if len(ids) == 0 {
+			break
+		}
+	}
+	return ids, nil
+}
+
+// match returns the IDs in scope having an event which matches c. Conditions
+// on the block height are matched against the blocks table, and the other
+// conditions against the indexed event attributes. String equality, CONTAINS
+// and EXISTS conditions are evaluated by the database, while the values of
+// the attributes compared to numbers and times are parsed and compared here,
+// skipping those that can't be parsed.
+func (es *EventSink) match(ctx context.Context, scope searchScope, c query.Condition) (map[int64]struct{}, error) {
+	var (
+		where     = []string{"blocks.chain_id = ?"}
+		args      = []interface{}{es.chainID}
+		joins     string
+		valueExpr = "NULL"
+		compare   bool
+	)
+
+	switch {
+	case c.CompositeKey == scope.heightKey && isInt(c.Operand):
+		where = append(where, "blocks.height "+sqlOp(c.Op)+" ?")
+		args = append(args, c.Operand)
+
+	case c.Op == query.OpExists && !strings.Contains(c.CompositeKey, "."):
+		// Searching for an event type.
+		joins = scope.joinEvents
+		where = append(where, "events.type = ?")
+		args = append(args, c.CompositeKey)
+
+	default:
+		joins = scope.joinEvents + ` JOIN ` + tableAttributes + ` ON attributes.event_id = events.rowid`
+		where = append(where, "attributes.composite_key = ?")
+		args = append(args, c.CompositeKey)
+
+		switch operand := c.Operand.(type) {
+		case nil: // EXISTS
+		case string:
+			switch c.Op {
+			case query.OpEqual:
+				where = append(where, "attributes.value = ?")
+			case query.OpContains:
+				where = append(where, "instr(attributes.value, ?) > 0")
+			default:
+				return nil, fmt.Errorf("unsupported operator for a string operand in condition on %q", c.CompositeKey)
+			}
+			args = append(args, operand)
+		default:
+			valueExpr = "attributes.value"
+			compare = true
+		}
+	}
+
+	rows, err := es.store.QueryContext(ctx, `
+SELECT DISTINCT `+scope.id+`, `+valueExpr+` FROM `+scope.from+` `+joins+`
+  WHERE `+strings.Join(where, " AND ")+`;
+`, args...)
+	if err != nil {
+		return nil, fmt.Errorf("searching events: %w", err)
+	}
+	defer rows.Close()
+
+	ids := make(map[int64]struct{})
+	for rows.Next() {
+		var (
+			id    int64
+			value sql.NullString
+		)
+		if err := rows.Scan(&id, &value); err != nil {
+			return nil, err
+		}
+		if compare && !matchValue(value.String, c.Op, c.Operand) {
+			continue
+		}
+		ids[id] = struct{}{}
+	}
+	return ids, rows.Err()
+}
+
+func isInt(operand interface{}) bool {
+	_, ok := operand.(int64)
+	return ok
+}
+
+// sqlOp returns the SQL comparison operator for op.
+func sqlOp(op query.Operator) string {
+	switch op {
+	case query.OpLessEqual:
+		return "<="
+	case query.OpGreaterEqual:
+		return ">="
+	case query.OpLess:
+		return "<"
+	case query.OpGreater:
+		return ">"
+	default:
+		return "="
+	}
+}
+
+// matchValue reports whether an attribute value compares to a numeric or
+// time operand as required by op.
+func matchValue(value string, op query.Operator, operand interface{}) bool {
+	var cmp int
+	switch operand := operand.(type) {
+	case int64:
+		v, err := strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			return false
+		}
+		cmp = compareInt(v, operand)
+
+	case float64:
+		v, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return false
+		}
+		switch {
+		case v < operand:
+			cmp = -1
+		case v > operand:
+			cmp = 1
+		}
+
+	case time.Time:
+		layout := query.DateLayout
+		if strings.Contains(value, "T") {
+			layout = query.TimeLayout
+		}
+		v, err := time.Parse(layout, value)
+		if err != nil {
+			return false
+		}
+		cmp = compareInt(v.UnixNano(), operand.UnixNano())
+
+	default:
+		return false
+	}
+
+	switch op {
+	case query.OpLessEqual:
+		return cmp <= 0
+	case query.OpGreaterEqual:
+		return cmp >= 0
+	case query.OpLess:
+		return cmp < 0
+	case query.OpGreater:
+		return cmp > 0
+	case query.OpEqual:
+		return cmp == 0
+	default:
+		return false
+	}
+}
+
+func compareInt(a, b int64) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	default:
+		return 0
+	}
+}
+
+// GetTxByHash returns the result of the transaction with the given hash, or
+// nil if it has not been indexed.
+func (es *EventSink) GetTxByHash(hash []byte) (*abci.TxResult, error) {
+	if len(hash) == 0 {
+		return nil, indexer.ErrorEmptyHash
+	}
+
+	var data []byte
+	err := es.store.QueryRow(`
+SELECT tx_result FROM `+tableTxResults+` JOIN `+tableBlocks+` ON blocks.rowid = tx_results.block_id
+  WHERE tx_hash = ? AND chain_id = ?
+  ORDER BY tx_results.rowid DESC LIMIT 1;
+`, fmt.Sprintf("%X", hash), es.chainID).Scan(&data)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	} else if err != nil {
+		return nil, fmt.Errorf("loading tx_result: %w", err)
+	}
+
+	txr := new(abci.TxResult)
+	if err := proto.Unmarshal(data, txr); err != nil {
+		return nil, fmt.Errorf("error reading TxResult: %w", err)
+	}
+	return txr, nil
+}
+
+// HasBlock reports whether the block at the given height has been indexed.
+func (es *EventSink) HasBlock(h int64) (bool, error) {
+	var exists bool
+	if err := es.store.QueryRow(`
+SELECT EXISTS (SELECT 1 FROM `+tableBlocks+` WHERE height = ? AND chain_id = ?);
+`, h, es.chainID).Scan(&exists); err != nil {
+		return false, err
+	}
+	return exists, nil
+}
+
+// Stop closes the underlying SQLite database.
+func (es *EventSink) Stop() error { return es.store.Close() }
@@ -0,0 +1,259 @@
+package sqlite
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"sort"
+	"testing"
+
+	"github.com/gogo/protobuf/proto"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	abci "github.com/tendermint/tendermint/abci/types"
+	"github.com/tendermint/tendermint/internal/state/indexer"
+	"github.com/tendermint/tendermint/libs/pubsub/query"
+	"github.com/tendermint/tendermint/types"
+)
+
+const chainID = "test-chainID"
+
+func newTestSink(t *testing.T) *EventSink {
+	es, err := NewEventSink(filepath.Join(t.TempDir(), "tx_index.sqlite"), chainID)
+	require.NoError(t, err)
+	t.Cleanup(func() { require.NoError(t, es.Stop()) })
+	return es
+}
+
+func newTestBlock(height int64) types.EventDataNewBlockHeader {
+	return types.EventDataNewBlockHeader{
+		Header: types.Header{Height: height},
+		ResultBeginBlock: abci.ResponseBeginBlock{
+			Events: []abci.Event{{
+				Type: "begin_event",
+				Attributes: []abci.EventAttribute{
+					{Key: "proposer", Value: "FCAA001", Index: true},
+				},
+			}},
+		},
+		ResultEndBlock: abci.ResponseEndBlock{
+			Events: []abci.Event{{
+				Type: "end_event",
+				Attributes: []abci.EventAttribute{
+					{Key: "foo", Value: fmt.Sprint(height * 10), Index: height%2 == 0},
+				},
+			}},
+		},
+	}
+}
+
+func newTestTxResult(height int64, index uint32, events ...abci.Event) *abci.TxResult {
+	return &abci.TxResult{
+		Height: height,
+		Index:  index,
+		Tx:     types.Tx(fmt.Sprintf("tx-%d-%d", height, index)),
+		Result: abci.ResponseDeliverTx{
+			Data:   []byte{0},
+			Code:   abci.CodeTypeOK,
+			Events: events,
+		},
+	}
+}
+
+func TestType(t *testing.T) {
+	es := newTestSink(t)
+	assert.Equal(t, indexer.SQLITE, es.Type())
+}
+
+func TestIndexing(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "tx_index.sqlite")
+	es, err := NewEventSink(path, chainID)
+	require.NoError(t, err)
+
+	require.NoError(t, es.IndexBlockEvents(newTestBlock(1)))
+	// indexing a block again is a no-op
+	require.NoError(t, es.IndexBlockEvents(newTestBlock(1)))
+
+	txr := newTestTxResult(1, 0, abci.Event{Type: "account", Attributes: []abci.EventAttribute{
+		{Key: "owner", Value: "Ivan", Index: true},
+		{Key: "secret", Value: "hidden", Index: false},
+	}})
+	require.NoError(t, es.IndexTxEvents([]*abci.TxResult{txr}))
+	require.NoError(t, es.IndexTxEvents([]*abci.TxResult{txr}))
+
+	// the transactions of a block which was not indexed are rejected
+	require.Error(t, es.IndexTxEvents([]*abci.TxResult{newTestTxResult(2, 0)}))
+
+	// the relational views of the psql event sink are available
+	var n int
+	require.NoError(t, es.DB().QueryRow(`SELECT COUNT(*) FROM tx_events WHERE composite_key = 'account.owner'`).Scan(&n))
+	assert.Equal(t, 1, n)
+	require.NoError(t, es.DB().QueryRow(`SELECT COUNT(*) FROM tx_events WHERE key = 'secret'`).Scan(&n))
+	assert.Equal(t, 0, n)
+	require.NoError(t, es.DB().QueryRow(`SELECT COUNT(*) FROM block_events WHERE height = 1`).Scan(&n))
+	assert.Equal(t, 3, n)
+
+	// the data survives reopening the database
+	require.NoError(t, es.Stop())
+	es, err = NewEventSink(path, chainID)
+	require.NoError(t, err)
+	defer es.Stop()
+
+	ok, err := es.HasBlock(1)
+	require.NoError(t, err)
+	assert.True(t, ok)
+	ok, err = es.HasBlock(2)
+	require.NoError(t, err)
+	assert.False(t, ok)
+
+	got, err := es.GetTxByHash(types.Tx(txr.Tx).Hash())
+	require.NoError(t, err)
+	assert.True(t, proto.Equal(txr, got))
+
+	got, err = es.GetTxByHash(types.Tx("missing").Hash())
+	require.NoError(t, err)
+	assert.Nil(t, got)
+
+	_, err = es.GetTxByHash(nil)
+	assert.Equal(t, indexer.ErrorEmptyHash, err)
+
+	// blocks of other chains are not visible
+	other, err := NewEventSink(path, "other-chain")
+	require.NoError(t, err)
+	defer other.Stop()
+	ok, err = other.HasBlock(1)
+	require.NoError(t, err)
+	assert.False(t, ok)
+}
+
+func TestSearchBlockEvents(t *testing.T) {
+	es := newTestSink(t)
+	for height := int64(1); height <= 10; height++ {
+		require.NoError(t, es.IndexBlockEvents(newTestBlock(height)))
+	}
+
+	testCases := []struct {
+		q       string
+		results []int64
+	}{
+		{"block.height = 100", nil},
+		{"block.height = 5", []int64{5}},
+		{"block.height > 3 AND block.height <= 5", []int64{4, 5}},
+		{"begin_event.key1 = 'value1'", nil},
+		{"begin_event.proposer = 'FCAA001'", []int64{1, 2, 3, 4, 5, 6, 7, 8, 9, 10}},
+		{"begin_event.proposer CONTAINS 'CAA'", []int64{1, 2, 3, 4, 5, 6, 7, 8, 9, 10}},
+		{"begin_event.proposer CONTAINS 'FFFFFFF'", nil},
+		{"end_event.foo <= 50", []int64{2, 4}},
+		{"end_event.foo >= 100", []int64{10}},
+		{"end_event.foo = 60", []int64{6}},
+		{"end_event.foo > 15.5", []int64{2, 4, 6, 8, 10}},
+		{"block.height > 2 AND end_event.foo <= 80", []int64{4, 6, 8}},
+		{"end_event.foo EXISTS", []int64{2, 4, 6, 8, 10}},
+		{"end_event EXISTS AND block.height < 3", []int64{1, 2}},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.q, func(t *testing.T) {
+			results, err := es.SearchBlockEvents(context.Background(), query.MustParse(tc.q))
+			require.NoError(t, err)
+			sort.Slice(results, func(i, j int) bool { return results[i] < results[j] })
+			if tc.results == nil {
+				assert.Empty(t, results)
+			} else {
+				assert.Equal(t, tc.results, results)
+			}
+		})
+	}
+}
+
+func TestSearchTxEvents(t *testing.T) {
+	es := newTestSink(t)
+	for height := int64(1); height <= 2; height++ {
+		require.NoError(t, es.IndexBlockEvents(newTestBlock(height)))
+	}
+
+	txr1 := newTestTxResult(1, 0,
+		abci.Event{Type: "account", Attributes: []abci.EventAttribute{
+			{Key: "number", Value: "1", Index: true},
+			{Key: "owner", Value: "Ivan", Index: true},
+		}},
+		abci.Event{Type: "transfer", Attributes: []abci.EventAttribute{
+			{Key: "date", Value: "2013-05-03", Index: true},
+		}},
+	)
+	txr2 := newTestTxResult(1, 1,
+		abci.Event{Type: "account", Attributes: []abci.EventAttribute{
+			{Key: "number", Value: "2", Index: true},
+			{Key: "owner", Value: "Vlad", Index: true},
+		}},
+		abci.Event{Type: "account", Attributes: []abci.EventAttribute{
+			{Key: "number", Value: "3", Index: true},
+		}},
+	)
+	txr3 := newTestTxResult(2, 0,
+		abci.Event{Type: "account", Attributes: []abci.EventAttribute{
+			{Key: "number.id", Value: "1", Index: true},
+			{Key: "owner", Value: "Ulan", Index: false},
+		}},
+		abci.Event{Type: "transfer", Attributes: []abci.EventAttribute{
+			{Key: "date", Value: "2020-01-01T10:00:00Z", Index: true},
+		}},
+	)
+	require.NoError(t, es.IndexTxEvents([]*abci.TxResult{txr1, txr2}))
+	require.NoError(t, es.IndexTxEvents([]*abci.TxResult{txr3}))
+
+	testCases := []struct {
+		q       string
+		results []*abci.TxResult
+	}{
+		{fmt.Sprintf("tx.hash = '%X'", types.Tx(txr2.Tx).Hash()), []*abci.TxResult{txr2}},
+		{fmt.Sprintf("tx.hash = '%X'", types.Tx("missing").Hash()), nil},
+		{"tx.height = 1", []*abci.TxResult{txr1, txr2}},
+		{"tx.height > 1", []*abci.TxResult{txr3}},
+		{"account.number = 1", []*abci.TxResult{txr1}},
+		{"account.number >= 2", []*abci.TxResult{txr2}},
+		{"account.number >= 1 AND account.number <= 5", []*abci.TxResult{txr1, txr2}},
+		{"account.number = 3 AND account.owner = 'Vlad'", []*abci.TxResult{txr2}},
+		{"account.number = 1 AND account.owner = 'Vlad'", nil},
+		{"account.owner = 'Ivan'", []*abci.TxResult{txr1}},
+		{"account.owner CONTAINS 'an'", []*abci.TxResult{txr1}},
+		{"account.owner EXISTS", []*abci.TxResult{txr1, txr2}},
+		{"account.number.id EXISTS", []*abci.TxResult{txr3}},
+		{"account EXISTS AND tx.height = 2", []*abci.TxResult{txr3}},
+		{"transfer.date < DATE 2014-01-01", []*abci.TxResult{txr1}},
+		{"transfer.date >= TIME 2013-05-03T14:45:00Z", []*abci.TxResult{txr3}},
+		{"not_allowed = 'boom'", nil},
+		{"account.date >= TIME 2013-05-03T14:45:00Z", nil},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.q, func(t *testing.T) {
+			results, err := es.SearchTxEvents(context.Background(), query.MustParse(tc.q))
+			require.NoError(t, err)
+			require.Len(t, results, len(tc.results))
+			sort.Slice(results, func(i, j int) bool {
+				if results[i].Height == results[j].Height {
+					return results[i].Index < results[j].Index
+				}
+				return results[i].Height < results[j].Height
+			})
+			for i, txr := range results {
+				assert.True(t, proto.Equal(tc.results[i], txr))
+			}
+		})
+	}
+}
+
+func TestSearchTxEventsCanceled(t *testing.T) {
+	es := newTestSink(t)
+	require.NoError(t, es.IndexBlockEvents(newTestBlock(1)))
+	require.NoError(t, es.IndexTxEvents([]*abci.TxResult{newTestTxResult(1, 0)}))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err := es.SearchTxEvents(ctx, query.MustParse("tx.height = 1"))
+	assert.Error(t, err)
+}
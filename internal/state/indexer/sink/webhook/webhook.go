@@ -0,0 +1,513 @@
+// Package webhook implements an event sink which posts block and transaction
+// events to HTTP receivers.
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	dbm "github.com/tendermint/tm-db"
+
+	abci "github.com/tendermint/tendermint/abci/types"
+	"github.com/tendermint/tendermint/internal/state/indexer"
+	tmjson "github.com/tendermint/tendermint/libs/json"
+	"github.com/tendermint/tendermint/libs/log"
+	"github.com/tendermint/tendermint/libs/pubsub/query"
+	"github.com/tendermint/tendermint/types"
+)
+
+const (
+	// SignatureHeader is the header holding the signature of a request body.
+	SignatureHeader = "X-Tendermint-Signature"
+
+	signaturePrefix = "sha256="
+
+	// EventTypeBlock and EventTypeTx are the types of the delivered events.
+	EventTypeBlock = "block"
+	EventTypeTx    = "tx"
+)
+
+// the interval between delivery attempts doubles from minRetryInterval up to
+// maxRetryInterval
+var (
+	minRetryInterval = time.Second
+	maxRetryInterval = time.Minute
+)
+
+// outbox keys
+var (
+	prefixEvent  = []byte{0x01}
+	prefixCursor = []byte{0x02}
+	keySeq       = []byte{0x03}
+)
+
+func eventKey(seq uint64) []byte {
+	key := make([]byte, len(prefixEvent)+8)
+	copy(key, prefixEvent)
+	binary.BigEndian.PutUint64(key[len(prefixEvent):], seq)
+	return key
+}
+
+func cursorKey(url string) []byte {
+	return append(append([]byte{}, prefixCursor...), url...)
+}
+
+func encodeSeq(seq uint64) []byte {
+	bz := make([]byte, 8)
+	binary.BigEndian.PutUint64(bz, seq)
+	return bz
+}
+
+func decodeSeq(bz []byte) (uint64, error) {
+	if len(bz) != 8 {
+		return 0, fmt.Errorf("invalid sequence number length %d", len(bz))
+	}
+	return binary.BigEndian.Uint64(bz), nil
+}
+
+// Event is an event delivered to the receivers. Events are numbered in the
+// order they were indexed. Since a request may be retried after the receiver
+// processed it, events can be delivered more than once, and receivers should
+// use Seq to discard duplicates.
+type Event struct {
+	Seq    uint64 `json:"seq"`
+	Type   string `json:"type"`
+	Height int64  `json:"height"`
+	// Data holds the types.EventDataNewBlockHeader of a block event, or the
+	// abci.TxResult of a tx event, encoded like the corresponding RPC events.
+	Data json.RawMessage `json:"data"`
+}
+
+// Request is the JSON body of the requests posted to the receivers.
+type Request struct {
+	ChainID string  `json:"chain_id"`
+	Events  []Event `json:"events"`
+}
+
+// Config are the settings of the EventSink.
+type Config struct {
+	// URLs are the receivers events are posted to.
+	URLs []string
+	// Secret is the key requests are signed with.
+	Secret []byte
+	// BatchSize is the maximum number of events in a request.
+	BatchSize int
+	// Timeout is the timeout of a request.
+	Timeout time.Duration
+}
+
+// receiver is a URL events are delivered to, along with the sequence number
+// of the last event it accepted.
+type receiver struct {
+	url    string
+	cursor uint64
+	notify chan struct{}
+}
+
+var _ indexer.EventSink = (*EventSink)(nil)
+
+// EventSink is an event sink which posts batches of events, signed with
+// HMAC-SHA256, to a set of HTTP receivers. Indexed events are first written to
+// an outbox database and are delivered in the background, so indexing does not
+// depend on the receivers being up. Each receiver is sent all events in order,
+// retrying until it accepts them, and events are removed from the outbox once
+// all receivers have accepted them.
+type EventSink struct {
+	db      dbm.DB
+	chainID string
+	cfg     Config
+	client  *http.Client
+	logger  log.Logger
+
+	mtx       sync.Mutex
+	seq       uint64
+	receivers []*receiver
+
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+// NewEventSink creates an EventSink keeping its outbox in db, and starts
+// delivering the events left in it. Receivers which were not configured before
+// are only sent the events indexed from now on.
+func NewEventSink(db dbm.DB, chainID string, cfg Config, logger log.Logger) (*EventSink, error) {
+	if len(cfg.URLs) == 0 {
+		return nil, errors.New("no webhook URLs configured")
+	}
+	if cfg.BatchSize <= 0 {
+		return nil, errors.New("webhook batch size must be positive")
+	}
+
+	es := &EventSink{
+		db:      db,
+		chainID: chainID,
+		cfg:     cfg,
+		client:  &http.Client{Timeout: cfg.Timeout},
+		logger:  logger,
+	}
+
+	bz, err := db.Get(keySeq)
+	if err != nil {
+		return nil, err
+	}
+	if len(bz) > 0 {
+		if es.seq, err = decodeSeq(bz); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := es.loadReceivers(); err != nil {
+		return nil, err
+	}
+	if err := es.prune(); err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	es.cancel = cancel
+	for _, r := range es.receivers {
+		es.wg.Add(1)
+		go es.deliver(ctx, r)
+	}
+	return es, nil
+}
+
+// loadReceivers loads the cursors of the configured receivers, and removes
+// those of receivers no longer configured, so they don't hold back pruning.
+func (es *EventSink) loadReceivers() error {
+	configured := make(map[string]bool, len(es.cfg.URLs))
+	batch := es.db.NewBatch()
+	defer batch.Close()
+
+	for _, url := range es.cfg.URLs {
+		if configured[url] {
+			continue
+		}
+		configured[url] = true
+
+		r := &receiver{url: url, cursor: es.seq, notify: make(chan struct{}, 1)}
+		bz, err := es.db.Get(cursorKey(url))
+		if err != nil {
+			return err
+		}
+		if len(bz) > 0 {
+			if r.cursor, err = decodeSeq(bz); err != nil {
+				return err
+			}
+		} else if err := batch.Set(cursorKey(url), encodeSeq(r.cursor)); err != nil {
+			return err
+		}
+		es.receivers = append(es.receivers, r)
+	}
+
+	iter, err := dbm.IteratePrefix(es.db, prefixCursor)
+	if err != nil {
+		return err
+	}
+	defer iter.Close()
+	for ; iter.Valid(); iter.Next() {
+		if url := string(iter.Key()[len(prefixCursor):]); !configured[url] {
+			if err := batch.Delete(iter.Key()); err != nil {
+				return err
+			}
+		}
+	}
+	if err := iter.Error(); err != nil {
+		return err
+	}
+	return batch.WriteSync()
+}
+
+// Type returns the structure type for this sink, which is webhook.
+func (es *EventSink) Type() indexer.EventSinkType { return indexer.WEBHOOK }
+
+// IndexBlockEvents queues the block header for delivery.
+func (es *EventSink) IndexBlockEvents(h types.EventDataNewBlockHeader) error {
+	data, err := tmjson.Marshal(h)
+	if err != nil {
+		return fmt.Errorf("marshaling block header: %w", err)
+	}
+	return es.enqueue(Event{Type: EventTypeBlock, Height: h.Header.Height, Data: data})
+}
+
+// IndexTxEvents queues the transaction results for delivery.
+func (es *EventSink) IndexTxEvents(txrs []*abci.TxResult) error {
+	events := make([]Event, 0, len(txrs))
+	for _, txr := range txrs {
+		data, err := tmjson.Marshal(txr)
+		if err != nil {
+			return fmt.Errorf("marshaling tx result: %w", err)
+		}
+		events = append(events, Event{Type: EventTypeTx, Height: txr.Height, Data: data})
+	}
+	return es.enqueue(events...)
+}
+
+// enqueue numbers the events and writes them to the outbox. They are durable
+// once it returns.
+func (es *EventSink) enqueue(events ...Event) error {
+	if len(events) == 0 {
+		return nil
+	}
+
+	es.mtx.Lock()
+	defer es.mtx.Unlock()
+
+	batch := es.db.NewBatch()
+	defer batch.Close()
+
+	seq := es.seq
+	for _, event := range events {
+		seq++
+		event.Seq = seq
+		bz, err := json.Marshal(event)
+		if err != nil {
+			return err
+		}
+		if err := batch.Set(eventKey(seq), bz); err != nil {
+			return err
+		}
+	}
+	if err := batch.Set(keySeq, encodeSeq(seq)); err != nil {
+		return err
+	}
+	if err := batch.WriteSync(); err != nil {
+		return err
+	}
+	es.seq = seq
+
+	for _, r := range es.receivers {
+		select {
+		case r.notify <- struct{}{}:
+		default:
+		}
+	}
+	return nil
+}
+
+// deliver posts the events to a receiver until the sink is stopped.
+func (es *EventSink) deliver(ctx context.Context, r *receiver) {
+	defer es.wg.Done()
+
+	retryInterval := minRetryInterval
+	for {
+		events, err := es.pending(r)
+		if err == nil && len(events) == 0 {
+			select {
+			case <-r.notify:
+				continue
+			case <-ctx.Done():
+				return
+			}
+		}
+		if err == nil {
+			err = es.post(ctx, r.url, events)
+		}
+		if err == nil {
+			err = es.advance(r, events[len(events)-1].Seq)
+		}
+		if ctx.Err() != nil {
+			return
+		}
+
+		if err == nil {
+			retryInterval = minRetryInterval
+			continue
+		}
+		es.logger.Error("failed to deliver events", "url", r.url, "retry_in", retryInterval, "err", err)
+		select {
+		case <-time.After(retryInterval):
+		case <-ctx.Done():
+			return
+		}
+		retryInterval *= 2
+		if retryInterval > maxRetryInterval {
+			retryInterval = maxRetryInterval
+		}
+	}
+}
+
+// pending returns the next events to deliver to a receiver.
+func (es *EventSink) pending(r *receiver) ([]Event, error) {
+	es.mtx.Lock()
+	start := r.cursor + 1
+	es.mtx.Unlock()
+
+	// the events sort before the cursors
+	iter, err := es.db.Iterator(eventKey(start), prefixCursor)
+	if err != nil {
+		return nil, err
+	}
+	defer iter.Close()
+
+	var events []Event
+	for ; iter.Valid() && len(events) < es.cfg.BatchSize; iter.Next() {
+		var event Event
+		if err := json.Unmarshal(iter.Value(), &event); err != nil {
+			return nil, fmt.Errorf("decoding event at key %X: %w", iter.Key(), err)
+		}
+		events = append(events, event)
+	}
+	return events, iter.Error()
+}
+
+// post sends a batch of events to a receiver.
+func (es *EventSink) post(ctx context.Context, url string, events []Event) error {
+	body, err := json.Marshal(Request{ChainID: es.chainID, Events: events})
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(SignatureHeader, Sign(es.cfg.Secret, body))
+
+	resp, err := es.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 1<<16))
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("receiver responded with status %s", resp.Status)
+	}
+	return nil
+}
+
+// advance records that a receiver accepted the events up to seq, and removes
+// the events all receivers accepted from the outbox.
+func (es *EventSink) advance(r *receiver, seq uint64) error {
+	if err := es.db.SetSync(cursorKey(r.url), encodeSeq(seq)); err != nil {
+		return err
+	}
+	es.mtx.Lock()
+	r.cursor = seq
+	es.mtx.Unlock()
+	return es.prune()
+}
+
+// prune removes the events all receivers accepted.
+func (es *EventSink) prune() error {
+	es.mtx.Lock()
+	delivered := es.seq
+	for _, r := range es.receivers {
+		if r.cursor < delivered {
+			delivered = r.cursor
+		}
+	}
+	es.mtx.Unlock()
+
+	iter, err := es.db.Iterator(eventKey(0), eventKey(delivered+1))
+	if err != nil {
+		return err
+	}
+	defer iter.Close()
+
+	batch := es.db.NewBatch()
+	defer batch.Close()
+	for ; iter.Valid(); iter.Next() {
+		if err := batch.Delete(iter.Key()); err != nil {
+			return err
+		}
+	}
+	if err := iter.Error(); err != nil {
+		return err
+	}
+	return batch.Write()
+}
+
+// SearchBlockEvents is not implemented by this sink, and reports an error for all queries.
+func (es *EventSink) SearchBlockEvents(ctx context.Context, q *query.Query) ([]int64, error) {
+	return nil, errors.New("block search is not supported via the webhook event sink")
+}
+
+// SearchTxEvents is not implemented by this sink, and reports an error for all queries.
+func (es *EventSink) SearchTxEvents(ctx context.Context, q *query.Query) ([]*abci.TxResult, error) {
+	return nil, errors.New("tx search is not supported via the webhook event sink")
+}
+
+// GetTxByHash is not implemented by this sink, and reports an error for all queries.
+func (es *EventSink) GetTxByHash(hash []byte) (*abci.TxResult, error) {
+	return nil, errors.New("getTxByHash is not supported via the webhook event sink")
+}
+
+// HasBlock is not implemented by this sink, and reports an error for all queries.
+func (es *EventSink) HasBlock(h int64) (bool, error) {
+	return false, errors.New("hasBlock is not supported via the webhook event sink")
+}
+
+// Stop stops delivering events and closes the outbox. Undelivered events are
+// delivered once a sink is created with the same outbox again.
+func (es *EventSink) Stop() error {
+	es.cancel()
+	es.wg.Wait()
+	return es.db.Close()
+}
+
+// Sign returns the signature of a request body, as sent in the
+// SignatureHeader.
+func Sign(secret, body []byte) string {
+	mac := hmac.New(sha256.New, secret)
+	mac.Write(body)
+	return signaturePrefix + hex.EncodeToString(mac.Sum(nil))
+}
+
+// Verify checks the signature of a request body.
+func Verify(secret, body []byte, signature string) bool {
+	if !strings.HasPrefix(signature, signaturePrefix) {
+		return false
+	}
+	sig, err := hex.DecodeString(strings.TrimPrefix(signature, signaturePrefix))
+	if err != nil {
+		return false
+	}
+	mac := hmac.New(sha256.New, secret)
+	mac.Write(body)
+	return hmac.Equal(sig, mac.Sum(nil))
+}
+
+// Handler returns an http.Handler receiving the requests of an EventSink. It
+// checks their signature and passes them to handle. A request is accepted, and
+// won't be sent again, if handle returns nil.
+func Handler(secret []byte, handle func(*Request) error) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if req.Method != http.MethodPost {
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		body, err := io.ReadAll(req.Body)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		if !Verify(secret, body, req.Header.Get(SignatureHeader)) {
+			http.Error(w, "invalid signature", http.StatusUnauthorized)
+			return
+		}
+		var request Request
+		if err := json.Unmarshal(body, &request); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		if err := handle(&request); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	})
+}
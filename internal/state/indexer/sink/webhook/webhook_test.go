@@ -0,0 +1,262 @@
+package webhook
+
+import (
+	"bytes"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	dbm "github.com/tendermint/tm-db"
+
+	abci "github.com/tendermint/tendermint/abci/types"
+	"github.com/tendermint/tendermint/internal/state/indexer"
+	tmjson "github.com/tendermint/tendermint/libs/json"
+	"github.com/tendermint/tendermint/libs/log"
+	"github.com/tendermint/tendermint/types"
+)
+
+var testSecret = []byte("secret")
+
+func init() {
+	minRetryInterval = 10 * time.Millisecond
+	maxRetryInterval = 50 * time.Millisecond
+}
+
+// testReceiver records the events posted to it, and rejects them while down.
+type testReceiver struct {
+	*httptest.Server
+
+	mtx    sync.Mutex
+	down   bool
+	events []Event
+}
+
+func newTestReceiver(t *testing.T, chainID string) *testReceiver {
+	r := &testReceiver{}
+	r.Server = httptest.NewServer(Handler(testSecret, func(req *Request) error {
+		r.mtx.Lock()
+		defer r.mtx.Unlock()
+		if r.down {
+			return errors.New("down")
+		}
+		if req.ChainID != chainID {
+			return errors.New("unexpected chain ID")
+		}
+		r.events = append(r.events, req.Events...)
+		return nil
+	}))
+	t.Cleanup(r.Close)
+	return r
+}
+
+func (r *testReceiver) setDown(down bool) {
+	r.mtx.Lock()
+	defer r.mtx.Unlock()
+	r.down = down
+}
+
+func (r *testReceiver) received() []Event {
+	r.mtx.Lock()
+	defer r.mtx.Unlock()
+	return append([]Event{}, r.events...)
+}
+
+func (r *testReceiver) waitFor(t *testing.T, n int) []Event {
+	t.Helper()
+	require.Eventually(t, func() bool { return len(r.received()) >= n }, 5*time.Second, 10*time.Millisecond)
+	return r.received()
+}
+
+func newTestSink(t *testing.T, db dbm.DB, urls ...string) *EventSink {
+	es, err := NewEventSink(db, "test-chain", Config{
+		URLs:      urls,
+		Secret:    testSecret,
+		BatchSize: 2,
+		Timeout:   time.Second,
+	}, log.TestingLogger())
+	require.NoError(t, err)
+	return es
+}
+
+func indexTestBlock(t *testing.T, es *EventSink, height int64) {
+	require.NoError(t, es.IndexBlockEvents(types.EventDataNewBlockHeader{
+		Header: types.Header{Height: height},
+		NumTxs: 1,
+	}))
+	require.NoError(t, es.IndexTxEvents([]*abci.TxResult{{
+		Height: height,
+		Tx:     types.Tx("tx"),
+		Result: abci.ResponseDeliverTx{Code: abci.CodeTypeOK},
+	}}))
+}
+
+func outboxSize(t *testing.T, db dbm.DB) int {
+	iter, err := dbm.IteratePrefix(db, prefixEvent)
+	require.NoError(t, err)
+	defer iter.Close()
+	n := 0
+	for ; iter.Valid(); iter.Next() {
+		n++
+	}
+	require.NoError(t, iter.Error())
+	return n
+}
+
+func TestType(t *testing.T) {
+	r := newTestReceiver(t, "test-chain")
+	es := newTestSink(t, dbm.NewMemDB(), r.URL)
+	assert.Equal(t, indexer.WEBHOOK, es.Type())
+	assert.NoError(t, es.Stop())
+}
+
+func TestNewEventSinkInvalidConfig(t *testing.T) {
+	_, err := NewEventSink(dbm.NewMemDB(), "test-chain", Config{BatchSize: 1}, log.TestingLogger())
+	assert.Error(t, err)
+	_, err = NewEventSink(dbm.NewMemDB(), "test-chain", Config{URLs: []string{"http://localhost"}}, log.TestingLogger())
+	assert.Error(t, err)
+}
+
+func TestDeliver(t *testing.T) {
+	r := newTestReceiver(t, "test-chain")
+	db := dbm.NewMemDB()
+	es := newTestSink(t, db, r.URL)
+	defer es.Stop()
+
+	indexTestBlock(t, es, 1)
+	indexTestBlock(t, es, 2)
+
+	events := r.waitFor(t, 4)
+	require.Len(t, events, 4)
+	for i, event := range events {
+		assert.EqualValues(t, i+1, event.Seq)
+		assert.EqualValues(t, i/2+1, event.Height)
+	}
+
+	assert.Equal(t, EventTypeBlock, events[0].Type)
+	var header types.EventDataNewBlockHeader
+	require.NoError(t, tmjson.Unmarshal(events[0].Data, &header))
+	assert.EqualValues(t, 1, header.Header.Height)
+	assert.EqualValues(t, 1, header.NumTxs)
+
+	assert.Equal(t, EventTypeTx, events[1].Type)
+	var txr abci.TxResult
+	require.NoError(t, tmjson.Unmarshal(events[1].Data, &txr))
+	assert.EqualValues(t, 1, txr.Height)
+	assert.Equal(t, []byte("tx"), txr.Tx)
+
+	require.Eventually(t, func() bool { return outboxSize(t, db) == 0 }, 5*time.Second, 10*time.Millisecond)
+}
+
+func TestDeliverRetry(t *testing.T) {
+	r := newTestReceiver(t, "test-chain")
+	r.setDown(true)
+	db := dbm.NewMemDB()
+	es := newTestSink(t, db, r.URL)
+	defer es.Stop()
+
+	indexTestBlock(t, es, 1)
+	time.Sleep(100 * time.Millisecond)
+	assert.Empty(t, r.received())
+	assert.Equal(t, 2, outboxSize(t, db))
+
+	r.setDown(false)
+	events := r.waitFor(t, 2)
+	assert.Len(t, events, 2)
+}
+
+func TestDeliverAfterRestart(t *testing.T) {
+	r := newTestReceiver(t, "test-chain")
+	r.setDown(true)
+	db := dbm.NewMemDB()
+
+	es := newTestSink(t, db, r.URL)
+	indexTestBlock(t, es, 1)
+	// closing a MemDB does not discard its contents
+	require.NoError(t, es.Stop())
+
+	r.setDown(false)
+	es = newTestSink(t, db, r.URL)
+	defer es.Stop()
+	indexTestBlock(t, es, 2)
+
+	events := r.waitFor(t, 4)
+	require.Len(t, events, 4)
+	for i, event := range events {
+		assert.EqualValues(t, i+1, event.Seq)
+	}
+}
+
+func TestDeliverMultipleReceivers(t *testing.T) {
+	up := newTestReceiver(t, "test-chain")
+	down := newTestReceiver(t, "test-chain")
+	down.setDown(true)
+	db := dbm.NewMemDB()
+
+	es := newTestSink(t, db, up.URL, down.URL)
+	indexTestBlock(t, es, 1)
+	up.waitFor(t, 2)
+	// the events are kept until all receivers accepted them
+	time.Sleep(100 * time.Millisecond)
+	assert.Equal(t, 2, outboxSize(t, db))
+	require.NoError(t, es.Stop())
+
+	// a receiver which is no longer configured does not hold events back
+	es = newTestSink(t, db, up.URL)
+	assert.Equal(t, 0, outboxSize(t, db))
+	require.NoError(t, es.Stop())
+
+	// a new receiver is only sent the events indexed from now on
+	down.setDown(false)
+	es = newTestSink(t, db, up.URL, down.URL)
+	defer es.Stop()
+	indexTestBlock(t, es, 2)
+
+	events := down.waitFor(t, 2)
+	require.Len(t, events, 2)
+	assert.EqualValues(t, 3, events[0].Seq)
+	assert.Len(t, up.waitFor(t, 4), 4)
+}
+
+func TestSignVerify(t *testing.T) {
+	body := []byte(`{"chain_id":"test-chain"}`)
+	sig := Sign(testSecret, body)
+	assert.True(t, Verify(testSecret, body, sig))
+	assert.False(t, Verify([]byte("other"), body, sig))
+	assert.False(t, Verify(testSecret, []byte(`{}`), sig))
+	assert.False(t, Verify(testSecret, body, sig[len(signaturePrefix):]))
+	assert.False(t, Verify(testSecret, body, ""))
+}
+
+func TestHandler(t *testing.T) {
+	called := false
+	h := Handler(testSecret, func(*Request) error {
+		called = true
+		return nil
+	})
+	body := []byte(`{"chain_id":"test-chain","events":[]}`)
+
+	testCases := []struct {
+		method string
+		sig    string
+		status int
+	}{
+		{http.MethodGet, Sign(testSecret, body), http.StatusMethodNotAllowed},
+		{http.MethodPost, "", http.StatusUnauthorized},
+		{http.MethodPost, Sign([]byte("other"), body), http.StatusUnauthorized},
+		{http.MethodPost, Sign(testSecret, body), http.StatusNoContent},
+	}
+	for _, tc := range testCases {
+		called = false
+		req := httptest.NewRequest(tc.method, "/", bytes.NewReader(body))
+		req.Header.Set(SignatureHeader, tc.sig)
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, req)
+		assert.Equal(t, tc.status, rec.Code)
+		assert.Equal(t, tc.status == http.StatusNoContent, called)
+	}
+}
@@ -17,6 +17,16 @@ const (
 type Metrics struct {
 	// Time between BeginBlock and EndBlock.
 	BlockProcessingTime metrics.Histogram
+
+	// Uncompressed size of the ABCI responses written, in bytes.
+	ABCIResponsesRawBytes metrics.Counter
+
+	// Size of the ABCI responses written after compression, in bytes.
+	ABCIResponsesStoredBytes metrics.Counter
+
+	// Ratio of stored to uncompressed size of each ABCI responses record
+	// written.
+	ABCIResponsesCompressionRatio metrics.Histogram
 }
 
 // PrometheusMetrics returns Metrics build using Prometheus client library.
@@ -35,12 +45,34 @@ func PrometheusMetrics(namespace string, labelsAndValues ...string) *Metrics {
 			Help:      "Time between BeginBlock and EndBlock in ms.",
 			Buckets:   stdprometheus.LinearBuckets(1, 10, 10),
 		}, labels).With(labelsAndValues...),
+		ABCIResponsesRawBytes: prometheus.NewCounterFrom(stdprometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: MetricsSubsystem,
+			Name:      "abci_responses_raw_bytes",
+			Help:      "Uncompressed size of the ABCI responses written, in bytes.",
+		}, labels).With(labelsAndValues...),
+		ABCIResponsesStoredBytes: prometheus.NewCounterFrom(stdprometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: MetricsSubsystem,
+			Name:      "abci_responses_stored_bytes",
+			Help:      "Size of the ABCI responses written after compression, in bytes.",
+		}, labels).With(labelsAndValues...),
+		ABCIResponsesCompressionRatio: prometheus.NewHistogramFrom(stdprometheus.HistogramOpts{
+			Namespace: namespace,
+			Subsystem: MetricsSubsystem,
+			Name:      "abci_responses_compression_ratio",
+			Help:      "Ratio of stored to uncompressed size of each ABCI responses record written.",
+			Buckets:   stdprometheus.LinearBuckets(0.1, 0.1, 10),
+		}, labels).With(labelsAndValues...),
 	}
 }
 
 // NopMetrics returns no-op Metrics.
 func NopMetrics() *Metrics {
 	return &Metrics{
-		BlockProcessingTime: discard.NewHistogram(),
+		BlockProcessingTime:           discard.NewHistogram(),
+		ABCIResponsesRawBytes:         discard.NewCounter(),
+		ABCIResponsesStoredBytes:      discard.NewCounter(),
+		ABCIResponsesCompressionRatio: discard.NewHistogram(),
 	}
 }
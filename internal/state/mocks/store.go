@@ -94,6 +94,29 @@ func (_m *Store) LoadConsensusParams(_a0 int64) (types.ConsensusParams, error) {
 	return r0, r1
 }
 
+// LoadRollback provides a mock function with given fields:
+func (_m *Store) LoadRollback() (*tendermintstate.RollbackInfo, error) {
+	ret := _m.Called()
+
+	var r0 *tendermintstate.RollbackInfo
+	if rf, ok := ret.Get(0).(func() *tendermintstate.RollbackInfo); ok {
+		r0 = rf()
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*tendermintstate.RollbackInfo)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func() error); ok {
+		r1 = rf()
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // LoadValidators provides a mock function with given fields: _a0
 func (_m *Store) LoadValidators(_a0 int64) (*types.ValidatorSet, error) {
 	ret := _m.Called(_a0)
@@ -159,6 +182,20 @@ func (_m *Store) SaveABCIResponses(_a0 int64, _a1 *tendermintstate.ABCIResponses
 	return r0
 }
 
+// SaveRollback provides a mock function with given fields: _a0
+func (_m *Store) SaveRollback(_a0 *tendermintstate.RollbackInfo) error {
+	ret := _m.Called(_a0)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(*tendermintstate.RollbackInfo) error); ok {
+		r0 = rf(_a0)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // SaveValidatorSets provides a mock function with given fields: _a0, _a1, _a2
 func (_m *Store) SaveValidatorSets(_a0 int64, _a1 int64, _a2 *types.ValidatorSet) error {
 	ret := _m.Called(_a0, _a1, _a2)
@@ -1,9 +1,14 @@
 package state
 
 import (
+	"bytes"
 	"errors"
 	"fmt"
 
+	tmmath "github.com/tendermint/tendermint/libs/math"
+	tmtime "github.com/tendermint/tendermint/libs/time"
+	tmstate "github.com/tendermint/tendermint/proto/tendermint/state"
+	"github.com/tendermint/tendermint/types"
 	"github.com/tendermint/tendermint/version"
 )
 
@@ -102,3 +107,240 @@ func Rollback(bs BlockStore, ss Store) (int64, []byte, error) {
 
 	return rolledBackState.LastBlockHeight, rolledBackState.AppHash, nil
 }
+
+// RollbackOptions are the options of RollbackTo.
+type RollbackOptions struct {
+	// DryRun checks that the rollback is possible and computes its result
+	// without modifying the stores.
+	DryRun bool
+
+	// Force allows rolling back further than the evidence max age.
+	Force bool
+}
+
+// RollbackResult describes a rollback performed, or planned in a dry run, by
+// RollbackTo.
+type RollbackResult struct {
+	// FromHeight is the height of the block store before the rollback.
+	FromHeight int64
+	// Height and AppHash are those of the rolled back state.
+	Height  int64
+	AppHash []byte
+	// BlocksRemoved is the number of blocks removed from the block store.
+	BlocksRemoved int64
+}
+
+// RollbackTo overwrites the current Tendermint state with the state after the
+// block at the given height, and removes all blocks above that height from
+// the block store. The state is rebuilt from the validators, consensus params
+// and ABCI responses stored for the target height, and the rollback is
+// recorded (see Store.LoadRollback) so that the private validator's last sign
+// state can be adjusted.
+//
+// The target height must not be below the base of the block store. Unless
+// opts.Force is set, the removed blocks must also not span more than the
+// evidence max age, since validators signing the removed heights again could
+// otherwise produce conflicting votes for which evidence is no longer
+// accepted.
+//
+// Persisting the state and removing the blocks doesn't happen atomically. If
+// interrupted, calling RollbackTo again with the same height completes the
+// rollback.
+// Note that this function does not affect application state.
+func RollbackTo(bs BlockStore, ss Store, height int64, opts RollbackOptions) (RollbackResult, error) {
+	currentState, err := ss.Load()
+	if err != nil {
+		return RollbackResult{}, err
+	}
+	if currentState.IsEmpty() {
+		return RollbackResult{}, errors.New("no state found")
+	}
+
+	blockHeight := bs.Height()
+
+	// the state was already rolled back, but not all the blocks were removed
+	if currentState.LastBlockHeight == height && blockHeight > height+1 {
+		return resumeRollback(bs, ss, currentState, opts)
+	}
+
+	if blockHeight != currentState.LastBlockHeight && blockHeight != currentState.LastBlockHeight+1 {
+		return RollbackResult{}, fmt.Errorf(
+			"statestore height (%d) is not one below or equal to blockstore height (%d)",
+			currentState.LastBlockHeight, blockHeight)
+	}
+	if height > currentState.LastBlockHeight || height >= blockHeight {
+		return RollbackResult{}, fmt.Errorf("target height %d must be below the latest height %d",
+			height, blockHeight)
+	}
+	if height < currentState.InitialHeight {
+		return RollbackResult{}, fmt.Errorf("target height %d is below the initial height %d",
+			height, currentState.InitialHeight)
+	}
+	if base := bs.Base(); height < base {
+		return RollbackResult{}, fmt.Errorf("target height %d is below the block store base %d", height, base)
+	}
+
+	targetBlock := bs.LoadBlockMeta(height)
+	if targetBlock == nil {
+		return RollbackResult{}, fmt.Errorf("block at height %d not found", height)
+	}
+	// the app hash and results hash resulting from the target block are in
+	// the header of the next one
+	nextBlock := bs.LoadBlockMeta(height + 1)
+	if nextBlock == nil {
+		return RollbackResult{}, fmt.Errorf("block at height %d not found", height+1)
+	}
+	latestBlock := bs.LoadBlockMeta(blockHeight)
+	if latestBlock == nil {
+		return RollbackResult{}, fmt.Errorf("block at height %d not found", blockHeight)
+	}
+
+	var (
+		evidenceParams = currentState.ConsensusParams.Evidence
+		ageNumBlocks   = blockHeight - height
+		ageDuration    = latestBlock.Header.Time.Sub(targetBlock.Header.Time)
+	)
+	if !opts.Force && ageNumBlocks > evidenceParams.MaxAgeNumBlocks && ageDuration > evidenceParams.MaxAgeDuration {
+		return RollbackResult{}, fmt.Errorf(
+			"rolling back %d blocks (%v) exceeds the evidence max age of %d blocks and %v",
+			ageNumBlocks, ageDuration, evidenceParams.MaxAgeNumBlocks, evidenceParams.MaxAgeDuration)
+	}
+
+	rolledBackState, err := rebuildState(ss, currentState, targetBlock, nextBlock)
+	if err != nil {
+		return RollbackResult{}, err
+	}
+
+	result := RollbackResult{
+		FromHeight:    blockHeight,
+		Height:        rolledBackState.LastBlockHeight,
+		AppHash:       rolledBackState.AppHash,
+		BlocksRemoved: blockHeight - height,
+	}
+	if opts.DryRun {
+		return result, nil
+	}
+
+	// the rollback is recorded first, so that an interrupted rollback can be
+	// told apart from an inconsistent block store
+	if err := ss.SaveRollback(&tmstate.RollbackInfo{
+		FromHeight: blockHeight,
+		Height:     height,
+		AppHash:    rolledBackState.AppHash,
+		Time:       tmtime.Now(),
+	}); err != nil {
+		return RollbackResult{}, fmt.Errorf("failed to record rollback: %w", err)
+	}
+	if err := ss.Save(rolledBackState); err != nil {
+		return RollbackResult{}, fmt.Errorf("failed to save rolled back state: %w", err)
+	}
+	if _, err := bs.DeleteBlocksAfter(height); err != nil {
+		return RollbackResult{}, fmt.Errorf("failed to remove blocks above height %d: %w", height, err)
+	}
+
+	return result, nil
+}
+
+// resumeRollback removes the remaining blocks of a rollback to the height of
+// the given state.
+func resumeRollback(bs BlockStore, ss Store, currentState State, opts RollbackOptions) (RollbackResult, error) {
+	height, blockHeight := currentState.LastBlockHeight, bs.Height()
+
+	info, err := ss.LoadRollback()
+	if err != nil {
+		return RollbackResult{}, err
+	}
+	if info == nil || info.Height != height {
+		return RollbackResult{}, fmt.Errorf(
+			"statestore height (%d) is not one below or equal to blockstore height (%d)", height, blockHeight)
+	}
+
+	result := RollbackResult{
+		FromHeight:    info.FromHeight,
+		Height:        height,
+		AppHash:       currentState.AppHash,
+		BlocksRemoved: blockHeight - height,
+	}
+	if opts.DryRun {
+		return result, nil
+	}
+	if _, err := bs.DeleteBlocksAfter(height); err != nil {
+		return RollbackResult{}, fmt.Errorf("failed to remove blocks above height %d: %w", height, err)
+	}
+	return result, nil
+}
+
+// rebuildState returns the state after the target block, using the stored
+// validators, consensus params and ABCI responses for its height.
+func rebuildState(ss Store, currentState State, targetBlock, nextBlock *types.BlockMeta) (State, error) {
+	height := targetBlock.Header.Height
+
+	lastValidators, err := ss.LoadValidators(height)
+	if err != nil {
+		return State{}, err
+	}
+	validators, err := ss.LoadValidators(height + 1)
+	if err != nil {
+		return State{}, err
+	}
+	nextValidators, err := ss.LoadValidators(height + 2)
+	if err != nil {
+		return State{}, err
+	}
+	params, err := ss.LoadConsensusParams(height + 1)
+	if err != nil {
+		return State{}, err
+	}
+
+	var valChangeHeight, paramsChangeHeight int64
+	if store, ok := ss.(dbStore); ok {
+		valChangeHeight, paramsChangeHeight, err = store.lastHeightsChanged(height)
+		if err != nil {
+			return State{}, err
+		}
+	} else {
+		// without the store's records of when they changed, clamp the heights
+		// the same way Rollback does
+		valChangeHeight = tmmath.MinInt64(currentState.LastHeightValidatorsChanged, height+2)
+		paramsChangeHeight = tmmath.MinInt64(currentState.LastHeightConsensusParamsChanged, height+1)
+	}
+
+	resultsHash := nextBlock.Header.LastResultsHash
+	abciResponses, err := ss.LoadABCIResponses(height)
+	switch {
+	case errors.As(err, &ErrNoABCIResponsesForHeight{}):
+	case err != nil:
+		return State{}, err
+	case !bytes.Equal(ABCIResponsesResultsHash(abciResponses), resultsHash):
+		return State{}, fmt.Errorf("ABCI responses for height %d do not match the results hash of block %d",
+			height, height+1)
+	}
+
+	return State{
+		Version: Version{
+			Consensus: version.Consensus{
+				Block: version.BlockProtocol,
+				App:   params.Version.AppVersion,
+			},
+			Software: version.TMVersion,
+		},
+		// immutable fields
+		ChainID:       currentState.ChainID,
+		InitialHeight: currentState.InitialHeight,
+
+		LastBlockHeight: height,
+		LastBlockID:     targetBlock.BlockID,
+		LastBlockTime:   targetBlock.Header.Time,
+
+		NextValidators:              nextValidators,
+		Validators:                  validators,
+		LastValidators:              lastValidators,
+		LastHeightValidatorsChanged: valChangeHeight,
+
+		ConsensusParams:                  params,
+		LastHeightConsensusParamsChanged: paramsChangeHeight,
+
+		LastResultsHash: resultsHash,
+		AppHash:         nextBlock.Header.AppHash,
+	}, nil
+}
@@ -2,13 +2,22 @@ package state_test
 
 import (
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/require"
 	dbm "github.com/tendermint/tm-db"
 
+	abciclient "github.com/tendermint/tendermint/abci/client"
+	abci "github.com/tendermint/tendermint/abci/types"
+	"github.com/tendermint/tendermint/crypto/encoding"
+	mmock "github.com/tendermint/tendermint/internal/mempool/mock"
+	"github.com/tendermint/tendermint/internal/proxy"
 	"github.com/tendermint/tendermint/internal/state"
 	"github.com/tendermint/tendermint/internal/state/mocks"
+	"github.com/tendermint/tendermint/internal/store"
 	"github.com/tendermint/tendermint/internal/test/factory"
+	"github.com/tendermint/tendermint/libs/log"
+	tmstate "github.com/tendermint/tendermint/proto/tendermint/state"
 	"github.com/tendermint/tendermint/types"
 	"github.com/tendermint/tendermint/version"
 )
@@ -100,6 +109,132 @@ func TestRollbackDifferentStateHeight(t *testing.T) {
 	require.Equal(t, err.Error(), "statestore height (100) is not one below or equal to blockstore height (102)")
 }
 
+// makeRollbackChain applies and saves numBlocks blocks, changing the power of
+// a validator at height 3. It returns the states after each height, as loaded
+// from the store.
+func makeRollbackChain(t *testing.T, numBlocks int64) (*store.BlockStore, state.Store, map[int64]state.State) {
+	t.Helper()
+
+	app := &testApp{}
+	proxyApp := proxy.NewAppConns(abciclient.NewLocalCreator(app), log.TestingLogger(), proxy.NopMetrics())
+	require.NoError(t, proxyApp.Start())
+	t.Cleanup(func() { require.NoError(t, proxyApp.Stop()) })
+
+	st, stateDB, privVals := makeState(2, 1)
+	stateStore := state.NewStore(stateDB)
+	blockStore := store.NewBlockStore(dbm.NewMemDB())
+	blockExec := state.NewBlockExecutor(stateStore, log.TestingLogger(), proxyApp.Consensus(),
+		mmock.Mempool{}, state.EmptyEvidencePool{}, blockStore)
+
+	st.ConsensusParams.Evidence.MaxAgeNumBlocks = 5
+	st.ConsensusParams.Evidence.MaxAgeDuration = time.Nanosecond
+	require.NoError(t, stateStore.Save(st))
+
+	states := make(map[int64]state.State)
+	lastCommit := new(types.Commit)
+	for height := int64(1); height <= numBlocks; height++ {
+		app.ValidatorUpdates = nil
+		if height == 3 {
+			pk, err := encoding.PubKeyToProto(st.Validators.Validators[0].PubKey)
+			require.NoError(t, err)
+			app.ValidatorUpdates = []abci.ValidatorUpdate{{PubKey: pk, Power: 2000}}
+		}
+
+		block, parts := st.MakeBlock(height, factory.MakeTenTxs(height), lastCommit, nil,
+			st.Validators.GetProposer().Address)
+		blockID := types.BlockID{Hash: block.Hash(), PartSetHeader: parts.Header()}
+		commit, err := makeValidCommit(height, blockID, st.Validators, privVals)
+		require.NoError(t, err)
+		blockStore.SaveBlock(block, parts, commit)
+
+		_, err = blockExec.ApplyBlock(st, blockID, block)
+		require.NoError(t, err)
+		st, err = stateStore.Load()
+		require.NoError(t, err)
+		states[height] = st
+		lastCommit = commit
+	}
+	return blockStore, stateStore, states
+}
+
+func TestRollbackTo(t *testing.T) {
+	blockStore, stateStore, states := makeRollbackChain(t, 10)
+
+	// a dry run does not modify the stores
+	result, err := state.RollbackTo(blockStore, stateStore, 5, state.RollbackOptions{DryRun: true})
+	require.NoError(t, err)
+	require.Equal(t, state.RollbackResult{
+		FromHeight:    10,
+		Height:        5,
+		AppHash:       states[5].AppHash,
+		BlocksRemoved: 5,
+	}, result)
+	require.EqualValues(t, 10, blockStore.Height())
+	loadedState, err := stateStore.Load()
+	require.NoError(t, err)
+	require.Equal(t, states[10], loadedState)
+	info, err := stateStore.LoadRollback()
+	require.NoError(t, err)
+	require.Nil(t, info)
+
+	// roll back across the validator change
+	for _, height := range []int64{5, 2} {
+		fromHeight := blockStore.Height()
+		result, err = state.RollbackTo(blockStore, stateStore, height, state.RollbackOptions{})
+		require.NoError(t, err)
+		require.EqualValues(t, fromHeight-height, result.BlocksRemoved)
+
+		loadedState, err = stateStore.Load()
+		require.NoError(t, err)
+		require.Equal(t, states[height], loadedState)
+
+		require.Equal(t, height, blockStore.Height())
+		require.Nil(t, blockStore.LoadBlockMeta(height+1))
+		require.Equal(t, states[height].LastBlockID, blockStore.LoadSeenCommit().BlockID)
+
+		info, err = stateStore.LoadRollback()
+		require.NoError(t, err)
+		require.Equal(t, fromHeight, info.FromHeight)
+		require.Equal(t, height, info.Height)
+		require.Equal(t, states[height].AppHash, info.AppHash)
+	}
+}
+
+func TestRollbackToGuards(t *testing.T) {
+	blockStore, stateStore, _ := makeRollbackChain(t, 10)
+
+	_, err := state.RollbackTo(blockStore, stateStore, 10, state.RollbackOptions{})
+	require.EqualError(t, err, "target height 10 must be below the latest height 10")
+
+	// the evidence max age is 5 blocks
+	_, err = state.RollbackTo(blockStore, stateStore, 4, state.RollbackOptions{DryRun: true})
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "exceeds the evidence max age of 5 blocks")
+	_, err = state.RollbackTo(blockStore, stateStore, 4, state.RollbackOptions{DryRun: true, Force: true})
+	require.NoError(t, err)
+
+	_, err = blockStore.PruneBlocks(4)
+	require.NoError(t, err)
+	_, err = state.RollbackTo(blockStore, stateStore, 3, state.RollbackOptions{Force: true})
+	require.EqualError(t, err, "target height 3 is below the block store base 4")
+}
+
+func TestRollbackToResume(t *testing.T) {
+	blockStore, stateStore, states := makeRollbackChain(t, 10)
+
+	// the state was rolled back, but the blocks were not removed
+	require.NoError(t, stateStore.SaveRollback(&tmstate.RollbackInfo{FromHeight: 10, Height: 7}))
+	require.NoError(t, stateStore.Save(states[7]))
+
+	_, err := state.RollbackTo(blockStore, stateStore, 6, state.RollbackOptions{})
+	require.EqualError(t, err, "statestore height (7) is not one below or equal to blockstore height (10)")
+
+	result, err := state.RollbackTo(blockStore, stateStore, 7, state.RollbackOptions{})
+	require.NoError(t, err)
+	require.EqualValues(t, 10, result.FromHeight)
+	require.EqualValues(t, 7, blockStore.Height())
+}
+
 func setupStateStore(t *testing.T, height int64) state.Store {
 	stateStore := state.NewStore(dbm.NewMemDB())
 	valSet, _ := factory.RandValidatorSet(5, 10)
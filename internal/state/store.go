@@ -2,6 +2,7 @@ package state
 
 import (
 	"bytes"
+	"context"
 	"errors"
 	"fmt"
 
@@ -10,6 +11,7 @@ import (
 	dbm "github.com/tendermint/tm-db"
 
 	abci "github.com/tendermint/tendermint/abci/types"
+	"github.com/tendermint/tendermint/internal/libs/compress"
 	tmmath "github.com/tendermint/tendermint/libs/math"
 	tmstate "github.com/tendermint/tendermint/proto/tendermint/state"
 	tmproto "github.com/tendermint/tendermint/proto/tendermint/types"
@@ -32,6 +34,7 @@ const (
 	prefixConsensusParams = int64(6)
 	prefixABCIResponses   = int64(7)
 	prefixState           = int64(8)
+	prefixRollback        = int64(15)
 )
 
 func encodeKey(prefix int64, height int64) []byte {
@@ -54,8 +57,8 @@ func abciResponsesKey(height int64) []byte {
 	return encodeKey(prefixABCIResponses, height)
 }
 
-// stateKey should never change after being set in init()
-var stateKey []byte
+// stateKey and rollbackKey should never change after being set in init()
+var stateKey, rollbackKey []byte
 
 func init() {
 	var err error
@@ -63,6 +66,10 @@ func init() {
 	if err != nil {
 		panic(err)
 	}
+	rollbackKey, err = orderedcode.Append(nil, prefixRollback)
+	if err != nil {
+		panic(err)
+	}
 }
 
 //----------------------
@@ -92,18 +99,47 @@ type Store interface {
 	Bootstrap(State) error
 	// PruneStates takes the height from which to prune up to (exclusive)
 	PruneStates(int64) error
+	// SaveRollback records the last rollback of the state
+	SaveRollback(*tmstate.RollbackInfo) error
+	// LoadRollback loads the last recorded rollback, or nil if there was none
+	LoadRollback() (*tmstate.RollbackInfo, error)
 }
 
 // dbStore wraps a db (github.com/tendermint/tm-db)
 type dbStore struct {
-	db dbm.DB
+	db      dbm.DB
+	codec   compress.Codec
+	metrics *Metrics
 }
 
 var _ Store = (*dbStore)(nil)
 
+// StoreOption sets an optional parameter on the dbStore.
+type StoreOption func(*dbStore)
+
+// StoreWithCompression sets the codec used to compress the ABCI responses
+// written by the store. Responses are always decompressed according to the
+// codec they were written with.
+func StoreWithCompression(codec compress.Codec) StoreOption {
+	return func(store *dbStore) { store.codec = codec }
+}
+
+// StoreWithMetrics sets the metrics.
+func StoreWithMetrics(metrics *Metrics) StoreOption {
+	return func(store *dbStore) { store.metrics = metrics }
+}
+
 // NewStore creates the dbStore of the state pkg.
-func NewStore(db dbm.DB) Store {
-	return dbStore{db}
+func NewStore(db dbm.DB, options ...StoreOption) Store {
+	store := dbStore{
+		db:      db,
+		codec:   compress.None,
+		metrics: NopMetrics(),
+	}
+	for _, option := range options {
+		option(&store)
+	}
+	return store
 }
 
 // LoadState loads the State from the database.
@@ -211,6 +247,31 @@ func (store dbStore) Bootstrap(state State) error {
 	return batch.WriteSync()
 }
 
+// SaveRollback records the last rollback of the state, overwriting any
+// previous record.
+func (store dbStore) SaveRollback(info *tmstate.RollbackInfo) error {
+	bz, err := info.Marshal()
+	if err != nil {
+		return err
+	}
+	return store.db.SetSync(rollbackKey, bz)
+}
+
+// LoadRollback loads the last recorded rollback of the state. It returns nil
+// if the state was never rolled back.
+func (store dbStore) LoadRollback() (*tmstate.RollbackInfo, error) {
+	bz, err := store.db.Get(rollbackKey)
+	if err != nil || len(bz) == 0 {
+		return nil, err
+	}
+
+	info := new(tmstate.RollbackInfo)
+	if err := info.Unmarshal(bz); err != nil {
+		return nil, fmt.Errorf("unmarshaling rollback info: %w", err)
+	}
+	return info, nil
+}
+
 // PruneStates deletes states up to the height specified (exclusive). It is not
 // guaranteed to delete all states, since the last checkpointed state and states being pointed to by
 // e.g. `LastHeightChanged` must remain. The state at retain height must also exist.
@@ -417,6 +478,11 @@ func (store dbStore) LoadABCIResponses(height int64) (*tmstate.ABCIResponses, er
 
 		return nil, ErrNoABCIResponsesForHeight{height}
 	}
+	buf, err = compress.Decode(buf)
+	if err != nil {
+		// DATA HAS BEEN CORRUPTED OR THE SPEC HAS CHANGED
+		panic(fmt.Sprintf("data has been corrupted or its spec has changed: %+v", err))
+	}
 
 	abciResponses := new(tmstate.ABCIResponses)
 	err = abciResponses.Unmarshal(buf)
@@ -455,7 +521,34 @@ func (store dbStore) saveABCIResponses(height int64, abciResponses *tmstate.ABCI
 		return err
 	}
 
-	return store.db.SetSync(abciResponsesKey(height), bz)
+	stored := compress.Encode(store.codec, bz)
+	store.metrics.ABCIResponsesRawBytes.Add(float64(len(bz)))
+	store.metrics.ABCIResponsesStoredBytes.Add(float64(len(stored)))
+	if len(bz) > 0 {
+		store.metrics.ABCIResponsesCompressionRatio.Observe(float64(len(stored)) / float64(len(bz)))
+	}
+
+	return store.db.SetSync(abciResponsesKey(height), stored)
+}
+
+// RecompressABCIResponses rewrites the ABCI responses stored in db with the
+// given codec, batchSize records at a time. It is safe to interrupt and
+// restart, and must not be run while a node is using the database.
+func RecompressABCIResponses(
+	ctx context.Context,
+	db dbm.DB,
+	codec compress.Codec,
+	batchSize int,
+) (compress.Stats, error) {
+	start, err := orderedcode.Append(nil, prefixABCIResponses)
+	if err != nil {
+		return compress.Stats{}, err
+	}
+	end, err := orderedcode.Append(nil, prefixABCIResponses+1)
+	if err != nil {
+		return compress.Stats{}, err
+	}
+	return compress.Recompress(ctx, db, start, end, codec, batchSize)
 }
 
 // SaveValidatorSets is used to save the validator set over multiple heights.
@@ -582,6 +675,22 @@ func (store dbStore) saveValidatorsInfo(
 	return batch.Set(validatorsKey(height), bz)
 }
 
+// lastHeightsChanged returns the heights at which the validators and the
+// consensus params last changed, as of the state after the block at height.
+// These are recorded alongside the next validators and consensus params saved
+// with that state.
+func (store dbStore) lastHeightsChanged(height int64) (vals int64, params int64, err error) {
+	valInfo, err := loadValidatorsInfo(store.db, height+2)
+	if err != nil {
+		return 0, 0, ErrNoValSetForHeight{height + 2}
+	}
+	paramsInfo, err := store.loadConsensusParamsInfo(height + 1)
+	if err != nil {
+		return 0, 0, fmt.Errorf("could not find consensus params for height #%d: %w", height+1, err)
+	}
+	return valInfo.LastHeightChanged, paramsInfo.LastHeightChanged, nil
+}
+
 //-----------------------------------------------------------------------------
 
 // ConsensusParamsInfo represents the latest consensus params, or the last height it changed
@@ -1,6 +1,7 @@
 package state_test
 
 import (
+	"context"
 	"fmt"
 	"os"
 	"testing"
@@ -13,6 +14,7 @@ import (
 	"github.com/tendermint/tendermint/config"
 	"github.com/tendermint/tendermint/crypto"
 	"github.com/tendermint/tendermint/crypto/ed25519"
+	"github.com/tendermint/tendermint/internal/libs/compress"
 	sm "github.com/tendermint/tendermint/internal/state"
 	"github.com/tendermint/tendermint/internal/test/factory"
 	tmrand "github.com/tendermint/tendermint/libs/rand"
@@ -307,3 +309,45 @@ func TestABCIResponsesResultsHash(t *testing.T) {
 	require.NoError(t, err)
 	assert.NoError(t, proof.Verify(root, bz))
 }
+
+func TestStoreABCIResponsesCompression(t *testing.T) {
+	stateDB := dbm.NewMemDB()
+
+	makeResponses := func(n int) *tmstate.ABCIResponses {
+		responses := &tmstate.ABCIResponses{
+			BeginBlock: &abci.ResponseBeginBlock{},
+			EndBlock:   &abci.ResponseEndBlock{},
+		}
+		for i := 0; i < n; i++ {
+			responses.DeliverTxs = append(responses.DeliverTxs,
+				&abci.ResponseDeliverTx{Code: 0, Data: []byte("result"), Log: "ok"})
+		}
+		return responses
+	}
+
+	// save height 1 uncompressed and height 2 with snappy
+	require.NoError(t, sm.NewStore(stateDB).SaveABCIResponses(1, makeResponses(100)))
+	stateStore := sm.NewStore(stateDB, sm.StoreWithCompression(compress.Snappy))
+	require.NoError(t, stateStore.SaveABCIResponses(2, makeResponses(200)))
+
+	checkResponses := func() {
+		for height, n := range map[int64]int{1: 100, 2: 200} {
+			responses, err := stateStore.LoadABCIResponses(height)
+			require.NoError(t, err)
+			require.Len(t, responses.DeliverTxs, n)
+		}
+	}
+	checkResponses()
+
+	stats, err := sm.RecompressABCIResponses(context.Background(), stateDB, compress.Snappy, 10)
+	require.NoError(t, err)
+	assert.EqualValues(t, 2, stats.Records)
+	assert.EqualValues(t, 1, stats.Rewritten)
+	assert.Less(t, stats.BytesAfter, stats.BytesBefore)
+	checkResponses()
+
+	stats, err = sm.RecompressABCIResponses(context.Background(), stateDB, compress.None, 10)
+	require.NoError(t, err)
+	assert.EqualValues(t, 2, stats.Rewritten)
+	checkResponses()
+}
@@ -0,0 +1,437 @@
+package statesync
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+
+	abci "github.com/tendermint/tendermint/abci/types"
+	"github.com/tendermint/tendermint/internal/libs/protoio"
+	"github.com/tendermint/tendermint/internal/proxy"
+	sm "github.com/tendermint/tendermint/internal/state"
+	"github.com/tendermint/tendermint/internal/store"
+	"github.com/tendermint/tendermint/libs/log"
+	tmstate "github.com/tendermint/tendermint/proto/tendermint/state"
+	ssproto "github.com/tendermint/tendermint/proto/tendermint/statesync"
+	tmproto "github.com/tendermint/tendermint/proto/tendermint/types"
+	"github.com/tendermint/tendermint/types"
+	"github.com/tendermint/tendermint/version"
+)
+
+// A snapshot archive holds an application snapshot together with everything
+// needed to bootstrap a node from it without peers. It is a stream of
+// varint-delimited protobuf messages:
+//
+//  1. the snapshot as a SnapshotsResponse
+//  2. the light blocks at the snapshot height h, h+1 and h+2
+//  3. the state after committing block h
+//  4. every chunk of the snapshot, in order, as a ChunkResponse
+//
+// This mirrors what state sync fetches from peers and a light client.
+
+// maxArchiveMsgSize is the maximum size of a message in a snapshot archive.
+var maxArchiveMsgSize = chunkMsgSize
+
+// ErrNoExportableSnapshot is returned by ExportSnapshot when the app has no
+// snapshot for which the block store has the necessary light blocks.
+var ErrNoExportableSnapshot = errors.New("no exportable snapshot found")
+
+// ExportSnapshot writes a snapshot archive of the app's snapshot at the given
+// height to w. If height is 0, the most recent snapshot that can be exported is
+// used. The block and state stores must hold the blocks at the snapshot height
+// and the two following heights. It returns the height of the exported
+// snapshot.
+func ExportSnapshot(
+	ctx context.Context,
+	conn proxy.AppConnSnapshot,
+	stateStore sm.Store,
+	blockStore *store.BlockStore,
+	height uint64,
+	w io.Writer,
+) (uint64, error) {
+	resp, err := conn.ListSnapshotsSync(ctx, abci.RequestListSnapshots{})
+	if err != nil {
+		return 0, fmt.Errorf("failed to list snapshots: %w", err)
+	}
+
+	snapshots := resp.Snapshots
+	sort.Slice(snapshots, func(i, j int) bool {
+		a, b := snapshots[i], snapshots[j]
+		return a.Height > b.Height || (a.Height == b.Height && a.Format > b.Format)
+	})
+
+	var snapshot *abci.Snapshot
+	for _, s := range snapshots {
+		if (height == 0 || s.Height == height) && s.Height+2 <= uint64(blockStore.Height()) {
+			snapshot = s
+			break
+		}
+	}
+	if snapshot == nil {
+		if height != 0 {
+			return 0, fmt.Errorf("%w at height %d", ErrNoExportableSnapshot, height)
+		}
+		return 0, ErrNoExportableSnapshot
+	}
+
+	lightBlocks := make([]*types.LightBlock, 3)
+	for i := range lightBlocks {
+		lightBlocks[i], err = loadLightBlock(stateStore, blockStore, int64(snapshot.Height)+int64(i))
+		if err != nil {
+			return 0, err
+		}
+	}
+
+	state, err := stateFromLightBlocks(stateStore, lightBlocks)
+	if err != nil {
+		return 0, err
+	}
+
+	pw := protoio.NewDelimitedWriter(w)
+	if _, err := pw.WriteMsg(&ssproto.SnapshotsResponse{
+		Height:   snapshot.Height,
+		Format:   snapshot.Format,
+		Chunks:   snapshot.Chunks,
+		Hash:     snapshot.Hash,
+		Metadata: snapshot.Metadata,
+	}); err != nil {
+		return 0, err
+	}
+
+	for _, lb := range lightBlocks {
+		lbproto, err := lb.ToProto()
+		if err != nil {
+			return 0, err
+		}
+		if _, err := pw.WriteMsg(lbproto); err != nil {
+			return 0, err
+		}
+	}
+
+	stateproto, err := state.ToProto()
+	if err != nil {
+		return 0, err
+	}
+	if _, err := pw.WriteMsg(stateproto); err != nil {
+		return 0, err
+	}
+
+	for index := uint32(0); index < snapshot.Chunks; index++ {
+		resp, err := conn.LoadSnapshotChunkSync(ctx, abci.RequestLoadSnapshotChunk{
+			Height: snapshot.Height,
+			Format: snapshot.Format,
+			Chunk:  index,
+		})
+		if err != nil {
+			return 0, fmt.Errorf("failed to load chunk %d: %w", index, err)
+		}
+		if resp.Chunk == nil {
+			return 0, fmt.Errorf("app is missing chunk %d", index)
+		}
+		if _, err := pw.WriteMsg(&ssproto.ChunkResponse{
+			Height: snapshot.Height,
+			Format: snapshot.Format,
+			Index:  index,
+			Chunk:  resp.Chunk,
+		}); err != nil {
+			return 0, err
+		}
+	}
+
+	return snapshot.Height, nil
+}
+
+// RestoreSnapshot restores the app from the snapshot archive in r and
+// bootstraps the state and block stores, which must be empty, from it. The
+// archive must belong to the chain of the genesis doc, and its light blocks
+// must form a valid chain starting at the light block with trustedHash, which
+// is the root of trust of the restore. The app hash of the restored app is
+// verified against the light blocks. It returns the restored state.
+func RestoreSnapshot(
+	ctx context.Context,
+	logger log.Logger,
+	conn proxy.AppConnSnapshot,
+	connQuery proxy.AppConnQuery,
+	stateStore sm.Store,
+	blockStore *store.BlockStore,
+	genDoc *types.GenesisDoc,
+	r io.Reader,
+	trustedHash []byte,
+) (sm.State, error) {
+	if len(trustedHash) == 0 {
+		return sm.State{}, errors.New("the trusted hash of the block at the snapshot height is required")
+	}
+
+	currentState, err := stateStore.Load()
+	if err != nil {
+		return sm.State{}, err
+	}
+	if !currentState.IsEmpty() || blockStore.Height() > 0 {
+		return sm.State{}, errors.New("can only restore a snapshot into empty state and block stores")
+	}
+
+	pr := protoio.NewDelimitedReader(r, maxArchiveMsgSize)
+
+	var snapshotproto ssproto.SnapshotsResponse
+	if _, err := pr.ReadMsg(&snapshotproto); err != nil {
+		return sm.State{}, fmt.Errorf("failed to read snapshot: %w", err)
+	}
+	snapshot := &snapshot{
+		Height:   snapshotproto.Height,
+		Format:   snapshotproto.Format,
+		Chunks:   snapshotproto.Chunks,
+		Hash:     snapshotproto.Hash,
+		Metadata: snapshotproto.Metadata,
+	}
+
+	lightBlocks := make([]*types.LightBlock, 3)
+	for i := range lightBlocks {
+		var lbproto tmproto.LightBlock
+		if _, err := pr.ReadMsg(&lbproto); err != nil {
+			return sm.State{}, fmt.Errorf("failed to read light block: %w", err)
+		}
+		lightBlocks[i], err = types.LightBlockFromProto(&lbproto)
+		if err != nil {
+			return sm.State{}, err
+		}
+	}
+
+	var stateproto tmstate.State
+	if _, err := pr.ReadMsg(&stateproto); err != nil {
+		return sm.State{}, fmt.Errorf("failed to read state: %w", err)
+	}
+	state, err := sm.FromProto(&stateproto)
+	if err != nil {
+		return sm.State{}, err
+	}
+
+	if err := verifyArchive(snapshot, lightBlocks, *state, genDoc, trustedHash); err != nil {
+		return sm.State{}, fmt.Errorf("invalid snapshot archive: %w", err)
+	}
+	snapshot.trustedAppHash = state.AppHash
+
+	// the syncer's helpers only need the app connections
+	s := &syncer{logger: logger, conn: conn, connQuery: connQuery}
+	if err := s.offerSnapshot(ctx, snapshot); err != nil {
+		return sm.State{}, err
+	}
+
+	for index := uint32(0); index < snapshot.Chunks; index++ {
+		var chunk ssproto.ChunkResponse
+		if _, err := pr.ReadMsg(&chunk); err != nil {
+			return sm.State{}, fmt.Errorf("failed to read chunk %d: %w", index, err)
+		}
+		if chunk.Height != snapshot.Height || chunk.Format != snapshot.Format || chunk.Index != index {
+			return sm.State{}, fmt.Errorf("unexpected chunk %d of snapshot %d/%d, expected chunk %d",
+				chunk.Index, chunk.Height, chunk.Format, index)
+		}
+		if err := applyArchiveChunk(ctx, s, &chunk); err != nil {
+			return sm.State{}, err
+		}
+	}
+
+	appVersion, err := s.verifyApp(snapshot)
+	if err != nil {
+		return sm.State{}, err
+	}
+	state.Version.Consensus.App = appVersion
+
+	if err := stateStore.Bootstrap(*state); err != nil {
+		return sm.State{}, fmt.Errorf("failed to bootstrap node with new state: %w", err)
+	}
+
+	last := lightBlocks[0]
+	if err := blockStore.SaveSignedHeader(last.SignedHeader, last.Commit.BlockID); err != nil {
+		return sm.State{}, fmt.Errorf("failed to store signed header: %w", err)
+	}
+	if err := blockStore.SaveSeenCommit(last.Height, last.Commit); err != nil {
+		return sm.State{}, fmt.Errorf("failed to store last seen commit: %w", err)
+	}
+
+	logger.Info("Snapshot restored", "height", snapshot.Height, "format", snapshot.Format,
+		"hash", snapshot.Hash)
+
+	return *state, nil
+}
+
+// applyArchiveChunk applies a chunk read from a snapshot archive to the app.
+// Since the archive can't be rewound, the app can only ask for the same chunk
+// to be retried.
+func applyArchiveChunk(ctx context.Context, s *syncer, chunk *ssproto.ChunkResponse) error {
+	for {
+		resp, err := s.conn.ApplySnapshotChunkSync(ctx, abci.RequestApplySnapshotChunk{
+			Index: chunk.Index,
+			Chunk: chunk.Chunk,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to apply chunk %v: %w", chunk.Index, err)
+		}
+		s.logger.Info("Applied snapshot chunk to ABCI app", "height", chunk.Height,
+			"format", chunk.Format, "chunk", chunk.Index)
+
+		for _, index := range resp.RefetchChunks {
+			if index != chunk.Index {
+				return fmt.Errorf("app requested chunk %d to be refetched, which is not possible from an archive",
+					index)
+			}
+		}
+
+		switch resp.Result {
+		case abci.ResponseApplySnapshotChunk_ACCEPT:
+			return nil
+		case abci.ResponseApplySnapshotChunk_RETRY:
+			continue
+		case abci.ResponseApplySnapshotChunk_ABORT:
+			return errAbort
+		case abci.ResponseApplySnapshotChunk_RETRY_SNAPSHOT:
+			return errRetrySnapshot
+		case abci.ResponseApplySnapshotChunk_REJECT_SNAPSHOT:
+			return errRejectSnapshot
+		default:
+			return fmt.Errorf("unknown ResponseApplySnapshotChunk result %v", resp.Result)
+		}
+	}
+}
+
+// verifyArchive checks that the light blocks of an archive are a valid chain
+// of the genesis doc's chain, starting at the snapshot height with the light
+// block with trustedHash, and that the state matches them.
+func verifyArchive(
+	snapshot *snapshot,
+	lightBlocks []*types.LightBlock,
+	state sm.State,
+	genDoc *types.GenesisDoc,
+	trustedHash []byte,
+) error {
+	if state.ChainID != genDoc.ChainID {
+		return fmt.Errorf("archive belongs to chain %q, expected %q", state.ChainID, genDoc.ChainID)
+	}
+	if state.InitialHeight != genDoc.InitialHeight {
+		return fmt.Errorf("archive state has initial height %d, expected %d", state.InitialHeight, genDoc.InitialHeight)
+	}
+
+	for i, lb := range lightBlocks {
+		if lb.Height != int64(snapshot.Height)+int64(i) {
+			return fmt.Errorf("expected light block at height %d, got %d", int64(snapshot.Height)+int64(i), lb.Height)
+		}
+		if err := lb.ValidateBasic(genDoc.ChainID); err != nil {
+			return fmt.Errorf("light block %d: %w", lb.Height, err)
+		}
+		if err := lb.ValidatorSet.VerifyCommitLight(genDoc.ChainID, lb.Commit.BlockID, lb.Height, lb.Commit); err != nil {
+			return fmt.Errorf("light block %d: %w", lb.Height, err)
+		}
+		if i == 0 {
+			continue
+		}
+		prev := lightBlocks[i-1]
+		if !bytes.Equal(lb.LastBlockID.Hash, prev.Hash()) {
+			return fmt.Errorf("light block %d does not link to light block %d", lb.Height, prev.Height)
+		}
+		if !bytes.Equal(lb.ValidatorsHash, prev.NextValidatorsHash) {
+			return fmt.Errorf("validators of light block %d do not match the next validators of light block %d",
+				lb.Height, prev.Height)
+		}
+	}
+
+	if !bytes.Equal(lightBlocks[0].Hash(), trustedHash) {
+		return fmt.Errorf("expected light block %d to have the trusted hash %X, got %X",
+			lightBlocks[0].Height, trustedHash, lightBlocks[0].Hash())
+	}
+
+	expected, err := stateFromLightBlocks(nil, lightBlocks)
+	if err != nil {
+		return err
+	}
+	switch {
+	case state.LastBlockHeight != expected.LastBlockHeight:
+		return fmt.Errorf("state is at height %d, expected %d", state.LastBlockHeight, expected.LastBlockHeight)
+	case !state.LastBlockID.Equals(expected.LastBlockID):
+		return errors.New("state last block ID does not match the light blocks")
+	case !bytes.Equal(state.AppHash, expected.AppHash):
+		return fmt.Errorf("state app hash %X does not match the light blocks app hash %X", state.AppHash, expected.AppHash)
+	case !bytes.Equal(state.LastResultsHash, expected.LastResultsHash):
+		return errors.New("state last results hash does not match the light blocks")
+	case !bytes.Equal(state.LastValidators.Hash(), expected.LastValidators.Hash()),
+		!bytes.Equal(state.Validators.Hash(), expected.Validators.Hash()),
+		!bytes.Equal(state.NextValidators.Hash(), expected.NextValidators.Hash()):
+		return errors.New("state validators do not match the light blocks")
+	case !bytes.Equal(state.ConsensusParams.HashConsensusParams(), lightBlocks[1].ConsensusHash):
+		return errors.New("state consensus params do not match the light blocks")
+	}
+	return nil
+}
+
+// stateFromLightBlocks builds the state after committing the first of three
+// consecutive light blocks, as the p2p and RPC state providers do. The
+// consensus params are loaded from the state store, if given.
+func stateFromLightBlocks(stateStore sm.Store, lightBlocks []*types.LightBlock) (sm.State, error) {
+	last, current, next := lightBlocks[0], lightBlocks[1], lightBlocks[2]
+	state := sm.State{
+		ChainID: last.ChainID,
+		Version: sm.Version{
+			Consensus: current.Version,
+			Software:  version.TMVersion,
+		},
+		LastBlockHeight:             last.Height,
+		LastBlockTime:               last.Time,
+		LastBlockID:                 last.Commit.BlockID,
+		AppHash:                     current.AppHash,
+		LastResultsHash:             current.LastResultsHash,
+		LastValidators:              last.ValidatorSet,
+		Validators:                  current.ValidatorSet,
+		NextValidators:              next.ValidatorSet,
+		LastHeightValidatorsChanged: next.Height,
+	}
+
+	if stateStore != nil {
+		latest, err := stateStore.Load()
+		if err != nil {
+			return sm.State{}, err
+		}
+		state.InitialHeight = latest.InitialHeight
+
+		params, err := stateStore.LoadConsensusParams(lightBlocks[1].Height)
+		if err != nil {
+			return sm.State{}, fmt.Errorf("failed to load consensus params at height %d: %w",
+				lightBlocks[1].Height, err)
+		}
+		state.ConsensusParams = params
+		state.LastHeightConsensusParamsChanged = lightBlocks[1].Height
+	}
+
+	return state, nil
+}
+
+// loadLightBlock loads the light block at the given height from the stores.
+// The commit of the latest block is only available as the seen commit.
+func loadLightBlock(stateStore sm.Store, blockStore *store.BlockStore, height int64) (*types.LightBlock, error) {
+	blockMeta := blockStore.LoadBlockMeta(height)
+	if blockMeta == nil {
+		return nil, fmt.Errorf("block %d not found", height)
+	}
+
+	commit := blockStore.LoadBlockCommit(height)
+	if commit == nil {
+		if seen := blockStore.LoadSeenCommit(); seen != nil && seen.Height == height {
+			commit = seen
+		} else {
+			return nil, fmt.Errorf("commit for block %d not found", height)
+		}
+	}
+
+	vals, err := stateStore.LoadValidators(height)
+	if err != nil {
+		return nil, err
+	}
+
+	return &types.LightBlock{
+		SignedHeader: &types.SignedHeader{
+			Header: &blockMeta.Header,
+			Commit: commit,
+		},
+		ValidatorSet: vals,
+	}, nil
+}
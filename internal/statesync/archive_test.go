@@ -0,0 +1,120 @@
+package statesync
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	dbm "github.com/tendermint/tm-db"
+
+	abci "github.com/tendermint/tendermint/abci/types"
+	proxymocks "github.com/tendermint/tendermint/internal/proxy/mocks"
+	sm "github.com/tendermint/tendermint/internal/state"
+	smmocks "github.com/tendermint/tendermint/internal/state/mocks"
+	"github.com/tendermint/tendermint/internal/store"
+	"github.com/tendermint/tendermint/internal/test/factory"
+	"github.com/tendermint/tendermint/libs/log"
+	"github.com/tendermint/tendermint/types"
+)
+
+func TestSnapshotArchive(t *testing.T) {
+	chain := buildLightBlockChain(t, 1, 6, time.Now())
+	chunks := [][]byte{{1, 2, 0}, {1, 2, 1}, {1, 2, 2}}
+	snapshot := &abci.Snapshot{Height: 2, Format: 1, Chunks: uint32(len(chunks)), Hash: []byte{1, 2}}
+
+	// the exporting node has blocks up to height 5
+	blockStore := store.NewBlockStore(dbm.NewMemDB())
+	stateStore := &smmocks.Store{}
+	stateStore.On("Load").Return(sm.State{InitialHeight: 1}, nil)
+	for height := int64(1); height <= 5; height++ {
+		require.NoError(t, blockStore.SaveSignedHeader(chain[height].SignedHeader, chain[height].Commit.BlockID))
+		stateStore.On("LoadValidators", height).Return(chain[height].ValidatorSet, nil)
+		stateStore.On("LoadConsensusParams", height).Return(*types.DefaultConsensusParams(), nil)
+	}
+
+	exportConn := &proxymocks.AppConnSnapshot{}
+	exportConn.On("ListSnapshotsSync", mock.Anything, abci.RequestListSnapshots{}).Return(
+		&abci.ResponseListSnapshots{Snapshots: []*abci.Snapshot{
+			snapshot,
+			// blocks 5 and 6 are needed to export this one
+			{Height: 4, Format: 1, Chunks: 1, Hash: []byte{1, 4}},
+		}}, nil)
+	for i, chunk := range chunks {
+		exportConn.On("LoadSnapshotChunkSync", mock.Anything, abci.RequestLoadSnapshotChunk{
+			Height: 2, Format: 1, Chunk: uint32(i),
+		}).Return(&abci.ResponseLoadSnapshotChunk{Chunk: chunk}, nil)
+	}
+
+	_, err := ExportSnapshot(ctx, exportConn, stateStore, blockStore, 4, &bytes.Buffer{})
+	require.ErrorIs(t, err, ErrNoExportableSnapshot)
+
+	var archive bytes.Buffer
+	height, err := ExportSnapshot(ctx, exportConn, stateStore, blockStore, 0, &archive)
+	require.NoError(t, err)
+	require.EqualValues(t, 2, height)
+
+	appHash := chain[3].AppHash
+	conn := &proxymocks.AppConnSnapshot{}
+	conn.On("OfferSnapshotSync", mock.Anything, abci.RequestOfferSnapshot{
+		Snapshot: snapshot,
+		AppHash:  appHash,
+	}).Return(&abci.ResponseOfferSnapshot{Result: abci.ResponseOfferSnapshot_ACCEPT}, nil)
+	for i, chunk := range chunks {
+		conn.On("ApplySnapshotChunkSync", mock.Anything, abci.RequestApplySnapshotChunk{
+			Index: uint32(i), Chunk: chunk,
+		}).Return(&abci.ResponseApplySnapshotChunk{Result: abci.ResponseApplySnapshotChunk_ACCEPT}, nil)
+	}
+	connQuery := &proxymocks.AppConnQuery{}
+	connQuery.On("InfoSync", mock.Anything, mock.Anything).Return(&abci.ResponseInfo{
+		AppVersion:       9,
+		LastBlockHeight:  2,
+		LastBlockAppHash: appHash,
+	}, nil)
+
+	genDoc := &types.GenesisDoc{ChainID: factory.DefaultTestChainID, InitialHeight: 1}
+	testCases := []struct {
+		name        string
+		genDoc      *types.GenesisDoc
+		trustedHash []byte
+	}{
+		{"no trusted hash", genDoc, nil},
+		{"different trusted hash", genDoc, chain[3].Hash()},
+		{"different chain", &types.GenesisDoc{ChainID: "other-chain", InitialHeight: 1}, chain[2].Hash()},
+		{"different initial height", &types.GenesisDoc{ChainID: factory.DefaultTestChainID, InitialHeight: 2},
+			chain[2].Hash()},
+	}
+	for _, tc := range testCases {
+		// invalid archives are rejected before the app is touched
+		_, err = RestoreSnapshot(ctx, log.TestingLogger(), conn, connQuery, sm.NewStore(dbm.NewMemDB()),
+			store.NewBlockStore(dbm.NewMemDB()), tc.genDoc, bytes.NewReader(archive.Bytes()), tc.trustedHash)
+		require.Error(t, err, tc.name)
+		conn.AssertNotCalled(t, "OfferSnapshotSync", mock.Anything, mock.Anything)
+	}
+
+	// the exporting node's stores are not empty
+	_, err = RestoreSnapshot(ctx, log.TestingLogger(), conn, connQuery, sm.NewStore(dbm.NewMemDB()),
+		blockStore, genDoc, bytes.NewReader(archive.Bytes()), chain[2].Hash())
+	require.Error(t, err)
+
+	newStateStore := sm.NewStore(dbm.NewMemDB())
+	newBlockStore := store.NewBlockStore(dbm.NewMemDB())
+	state, err := RestoreSnapshot(ctx, log.TestingLogger(), conn, connQuery, newStateStore, newBlockStore,
+		genDoc, bytes.NewReader(archive.Bytes()), chain[2].Hash())
+	require.NoError(t, err)
+	conn.AssertExpectations(t)
+	connQuery.AssertExpectations(t)
+
+	require.EqualValues(t, 2, state.LastBlockHeight)
+	require.EqualValues(t, 9, state.Version.Consensus.App)
+	require.Equal(t, []byte(appHash), state.AppHash)
+	require.Equal(t, chain[4].ValidatorSet.Hash(), state.NextValidators.Hash())
+
+	loaded, err := newStateStore.Load()
+	require.NoError(t, err)
+	require.Equal(t, state.LastBlockID, loaded.LastBlockID)
+	require.EqualValues(t, 2, newBlockStore.Height())
+	require.Equal(t, chain[2].Hash(), newBlockStore.LoadBlockMeta(2).Header.Hash())
+	require.Equal(t, chain[2].Commit.BlockID, newBlockStore.LoadSeenCommit().BlockID)
+}
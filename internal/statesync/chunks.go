@@ -1,6 +1,7 @@
 package statesync
 
 import (
+	"encoding/json"
 	"errors"
 	"fmt"
 	"os"
@@ -9,9 +10,14 @@ import (
 	"time"
 
 	tmsync "github.com/tendermint/tendermint/internal/libs/sync"
+	"github.com/tendermint/tendermint/internal/libs/tempfile"
 	"github.com/tendermint/tendermint/types"
 )
 
+// chunkProgressFile is the name of the file that records the progress of a
+// persistent chunk queue, next to its chunk files.
+const chunkProgressFile = "progress.json"
+
 // errDone is returned by chunkQueue.Next() when all chunks have been returned.
 var errDone = errors.New("chunk queue has completed")
 
@@ -24,14 +30,43 @@ type chunk struct {
 	Sender types.NodeID
 }
 
+// chunkProgress is the progress of a persistent chunk queue, which allows a
+// restore of the same snapshot to be resumed after a restart with the chunks
+// that were already fetched. Since offering a snapshot to the app starts its
+// restoration from scratch, the chunks are applied again.
+type chunkProgress struct {
+	Snapshot *snapshot `json:"snapshot"`
+}
+
+// loadChunkProgress loads the progress of the chunk queue persisted in dir, or
+// nil if there is none.
+func loadChunkProgress(dir string) (*chunkProgress, error) {
+	bz, err := os.ReadFile(filepath.Join(dir, chunkProgressFile))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	} else if err != nil {
+		return nil, fmt.Errorf("failed to read state sync progress: %w", err)
+	}
+
+	var progress chunkProgress
+	if err := json.Unmarshal(bz, &progress); err != nil {
+		return nil, fmt.Errorf("failed to decode state sync progress: %w", err)
+	}
+	if progress.Snapshot == nil {
+		return nil, errors.New("state sync progress has no snapshot")
+	}
+	return &progress, nil
+}
+
 // chunkQueue manages chunks for a state sync process, ordering them if requested. It acts as an
 // iterator over all chunks, but callers can request chunks to be retried, optionally after
 // refetching.
 type chunkQueue struct {
 	tmsync.Mutex
 	snapshot       *snapshot                  // if this is nil, the queue has been closed
-	dir            string                     // temp dir for on-disk chunk storage
-	chunkFiles     map[uint32]string          // path to temporary chunk file
+	dir            string                     // dir for on-disk chunk storage
+	persistent     bool                       // whether dir is kept on Close() to resume later
+	chunkFiles     map[uint32]string          // path to chunk file
 	chunkSenders   map[uint32]types.NodeID    // the peer who sent the given chunk
 	chunkAllocated map[uint32]bool            // chunks that have been allocated via Allocate()
 	chunkReturned  map[uint32]bool            // chunks returned via Next()
@@ -60,6 +95,66 @@ func newChunkQueue(snapshot *snapshot, tempDir string) (*chunkQueue, error) {
 	}, nil
 }
 
+// newPersistentChunkQueue creates a new chunk queue for a snapshot that stores
+// its chunks and progress in dir, and keeps them when closed. If dir holds the
+// progress of the same snapshot, the chunks that were already fetched are
+// loaded instead of being fetched again. Any other content of dir is removed.
+// Callers must call Close() when done, and Delete() once the snapshot has been
+// restored or rejected.
+func newPersistentChunkQueue(snapshot *snapshot, dir string) (*chunkQueue, error) {
+	if snapshot.Chunks == 0 {
+		return nil, errors.New("snapshot has no chunks")
+	}
+
+	progress, err := loadChunkProgress(dir)
+	if err != nil || progress == nil || progress.Snapshot.Key() != snapshot.Key() {
+		// start from scratch, whatever the reason
+		if err := os.RemoveAll(dir); err != nil {
+			return nil, fmt.Errorf("failed to clean up state sync dir %v: %w", dir, err)
+		}
+		progress = nil
+	}
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return nil, fmt.Errorf("unable to create dir for state sync chunks: %w", err)
+	}
+
+	q := &chunkQueue{
+		snapshot:       snapshot,
+		dir:            dir,
+		persistent:     true,
+		chunkFiles:     make(map[uint32]string, snapshot.Chunks),
+		chunkSenders:   make(map[uint32]types.NodeID, snapshot.Chunks),
+		chunkAllocated: make(map[uint32]bool, snapshot.Chunks),
+		chunkReturned:  make(map[uint32]bool, snapshot.Chunks),
+		waiters:        make(map[uint32][]chan<- uint32),
+	}
+
+	if progress != nil {
+		entries, err := os.ReadDir(dir)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list state sync chunks: %w", err)
+		}
+		for _, entry := range entries {
+			index, err := strconv.ParseUint(entry.Name(), 10, 32)
+			if err != nil || uint32(index) >= snapshot.Chunks {
+				continue
+			}
+			q.chunkFiles[uint32(index)] = filepath.Join(dir, entry.Name())
+			q.chunkAllocated[uint32(index)] = true
+		}
+	}
+
+	bz, err := json.Marshal(chunkProgress{Snapshot: snapshot})
+	if err != nil {
+		return nil, err
+	}
+	if err := tempfile.WriteFileAtomic(filepath.Join(dir, chunkProgressFile), bz, 0600); err != nil {
+		return nil, fmt.Errorf("failed to save state sync progress: %w", err)
+	}
+
+	return q, nil
+}
+
 // Add adds a chunk to the queue. It ignores chunks that already exist, returning false.
 func (q *chunkQueue) Add(chunk *chunk) (bool, error) {
 	if chunk == nil || chunk.Chunk == nil {
@@ -86,7 +181,7 @@ func (q *chunkQueue) Add(chunk *chunk) (bool, error) {
 	}
 
 	path := filepath.Join(q.dir, strconv.FormatUint(uint64(chunk.Index), 10))
-	err := os.WriteFile(path, chunk.Chunk, 0600)
+	err := tempfile.WriteFileAtomic(path, chunk.Chunk, 0600)
 	if err != nil {
 		return false, fmt.Errorf("failed to save chunk %v to file %v: %w", chunk.Index, path, err)
 	}
@@ -129,7 +224,8 @@ func (q *chunkQueue) Allocate() (uint32, error) {
 	return 0, errDone
 }
 
-// Close closes the chunk queue, cleaning up all temporary files.
+// Close closes the chunk queue, cleaning up all temporary files. The files of
+// a persistent queue are kept, see Delete().
 func (q *chunkQueue) Close() error {
 	q.Lock()
 	defer q.Unlock()
@@ -147,6 +243,10 @@ func (q *chunkQueue) Close() error {
 	q.waiters = nil
 	q.snapshot = nil
 
+	if q.persistent {
+		return nil
+	}
+
 	if err := os.RemoveAll(q.dir); err != nil {
 		return fmt.Errorf("failed to clean up state sync tempdir %v: %w", q.dir, err)
 	}
@@ -154,6 +254,20 @@ func (q *chunkQueue) Close() error {
 	return nil
 }
 
+// Delete closes the chunk queue and removes all of its files, including those
+// of a persistent queue.
+func (q *chunkQueue) Delete() error {
+	if err := q.Close(); err != nil {
+		return err
+	}
+
+	if err := os.RemoveAll(q.dir); err != nil {
+		return fmt.Errorf("failed to clean up state sync dir %v: %w", q.dir, err)
+	}
+
+	return nil
+}
+
 // Discard discards a chunk. It will be removed from the queue, available for allocation, and can
 // be added and returned via Next() again. If the chunk is not already in the queue this does
 // nothing, to avoid it being allocated to multiple fetchers.
@@ -355,6 +469,14 @@ func (q *chunkQueue) WaitFor(index uint32) <-chan uint32 {
 	return ch
 }
 
+// numChunksAdded returns the number of chunks in the queue, including those
+// loaded from a previous run of a persistent queue.
+func (q *chunkQueue) numChunksAdded() int {
+	q.Lock()
+	defer q.Unlock()
+	return len(q.chunkFiles)
+}
+
 func (q *chunkQueue) numChunksReturned() int {
 	q.Lock()
 	defer q.Unlock()
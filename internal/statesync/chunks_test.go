@@ -2,6 +2,7 @@ package statesync
 
 import (
 	"os"
+	"path/filepath"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -53,6 +54,64 @@ func TestNewChunkQueue_TempDir(t *testing.T) {
 	assert.Len(t, files, 0)
 }
 
+func TestPersistentChunkQueue_Resume(t *testing.T) {
+	s := &snapshot{Height: 3, Format: 1, Chunks: 3, Hash: []byte{7}}
+	dir := filepath.Join(t.TempDir(), "statesync")
+
+	queue, err := newPersistentChunkQueue(s, dir)
+	require.NoError(t, err)
+	for i := uint32(0); i < 2; i++ {
+		index, err := queue.Allocate()
+		require.NoError(t, err)
+		_, err = queue.Add(&chunk{Height: 3, Format: 1, Index: index, Chunk: []byte{3, 1, byte(index)}})
+		require.NoError(t, err)
+	}
+	_, err = queue.Next()
+	require.NoError(t, err)
+
+	progress, err := loadChunkProgress(dir)
+	require.NoError(t, err)
+	require.Equal(t, s.Key(), progress.Snapshot.Key())
+
+	// closing the queue keeps its chunks and progress around
+	require.NoError(t, queue.Close())
+
+	queue, err = newPersistentChunkQueue(s, dir)
+	require.NoError(t, err)
+	require.Equal(t, 2, queue.numChunksAdded())
+	require.True(t, queue.Has(0))
+	require.True(t, queue.Has(1))
+	require.False(t, queue.Has(2))
+
+	// only the missing chunk is fetched, and all chunks are applied again
+	index, err := queue.Allocate()
+	require.NoError(t, err)
+	require.EqualValues(t, 2, index)
+	_, err = queue.Allocate()
+	require.Equal(t, errDone, err)
+	_, err = queue.Add(&chunk{Height: 3, Format: 1, Index: 2, Chunk: []byte{3, 1, 2}})
+	require.NoError(t, err)
+	for i := uint32(0); i < 3; i++ {
+		c, err := queue.Next()
+		require.NoError(t, err)
+		require.Equal(t, &chunk{Height: 3, Format: 1, Index: i, Chunk: []byte{3, 1, byte(i)}}, c)
+	}
+
+	// a different snapshot starts from scratch
+	require.NoError(t, queue.Close())
+	other := &snapshot{Height: 4, Format: 1, Chunks: 3, Hash: []byte{8}}
+	queue, err = newPersistentChunkQueue(other, dir)
+	require.NoError(t, err)
+	require.Equal(t, 0, queue.numChunksAdded())
+	progress, err = loadChunkProgress(dir)
+	require.NoError(t, err)
+	require.Equal(t, other.Key(), progress.Snapshot.Key())
+
+	require.NoError(t, queue.Delete())
+	_, err = os.Stat(dir)
+	require.True(t, os.IsNotExist(err))
+}
+
 func TestChunkQueue(t *testing.T) {
 	queue, teardown := setupChunkQueue(t)
 	defer teardown()
@@ -167,6 +167,10 @@ type BlockProvider struct {
 	peer       types.NodeID
 	chainID    string
 	dispatcher *Dispatcher
+
+	// chain IDs set by the light client, see SetChainIDs
+	mtx      sync.RWMutex
+	chainIDs []string
 }
 
 // Creates a block provider which implements the light client Provider interface.
@@ -203,7 +207,7 @@ func (p *BlockProvider) LightBlock(ctx context.Context, height int64) (*types.Li
 	}
 
 	// perform basic validation
-	if err := lb.ValidateBasic(p.chainID); err != nil {
+	if err := lb.ValidateBasic(p.expectedChainID(lb.ChainID)); err != nil {
 		return nil, provider.ErrBadLightBlock{Reason: err}
 	}
 
@@ -218,6 +222,28 @@ func (p *BlockProvider) ReportEvidence(ctx context.Context, ev types.Evidence) e
 	return nil
 }
 
+// SetChainIDs implements provider.ChainIDSetter. Light blocks of any of the
+// given chains, which the light client follows across upgrades, are accepted
+// besides those of the provider's chain.
+func (p *BlockProvider) SetChainIDs(chainIDs []string) {
+	p.mtx.Lock()
+	defer p.mtx.Unlock()
+	p.chainIDs = chainIDs
+}
+
+// expectedChainID returns the chain ID a light block with the given chain ID
+// is validated against.
+func (p *BlockProvider) expectedChainID(chainID string) string {
+	p.mtx.RLock()
+	defer p.mtx.RUnlock()
+	for _, id := range p.chainIDs {
+		if id == chainID {
+			return id
+		}
+	}
+	return p.chainID
+}
+
 // String implements stringer interface
 func (p *BlockProvider) String() string { return string(p.peer) }
 
@@ -112,6 +112,28 @@ func TestDispatcherProviders(t *testing.T) {
 	}
 }
 
+func TestBlockProviderChainIDs(t *testing.T) {
+	t.Cleanup(leaktest.Check(t))
+
+	ch := make(chan p2p.Envelope, 100)
+	closeCh := make(chan struct{})
+	defer close(closeCh)
+
+	d := NewDispatcher(ch)
+	go handleRequests(t, d, ch, closeCh)
+
+	// light blocks of other chains are rejected, unless the light client
+	// follows them after an upgrade
+	p := NewBlockProvider(factory.NodeID("a"), "old-chain", d)
+	_, err := p.LightBlock(context.Background(), 10)
+	assert.Error(t, err)
+
+	p.SetChainIDs([]string{"old-chain", "test-chain"})
+	lb, err := p.LightBlock(context.Background(), 10)
+	require.NoError(t, err)
+	assert.Equal(t, "test-chain", lb.ChainID)
+}
+
 func TestPeerListBasic(t *testing.T) {
 	t.Cleanup(leaktest.Check(t))
 	peerList := newPeerList()
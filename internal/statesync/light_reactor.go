@@ -0,0 +1,276 @@
+package statesync
+
+import (
+	"context"
+	"fmt"
+	"runtime/debug"
+	"time"
+
+	tmsync "github.com/tendermint/tendermint/internal/libs/sync"
+	"github.com/tendermint/tendermint/internal/p2p"
+	"github.com/tendermint/tendermint/libs/log"
+	"github.com/tendermint/tendermint/libs/service"
+	"github.com/tendermint/tendermint/light"
+	"github.com/tendermint/tendermint/light/provider"
+	ssproto "github.com/tendermint/tendermint/proto/tendermint/statesync"
+	"github.com/tendermint/tendermint/types"
+)
+
+// maxLightClientWitnesses is the amount of peers that are added as witnesses
+// to the light client as they connect. Too many overburdens the network and
+// too little compromises the second layer of security.
+const maxLightClientWitnesses = 6
+
+// LightClientReactor serves the light block channel for nodes that only run a
+// light client, without the rest of state sync. It hands out a p2p
+// BlockProvider for every connected peer and answers the light block requests
+// of other peers from the light client's trusted store.
+type LightClientReactor struct {
+	service.BaseService
+
+	chainID     string
+	blockCh     *p2p.Channel
+	peerUpdates *p2p.PeerUpdates
+	closeCh     chan struct{}
+
+	dispatcher *Dispatcher
+	peers      *peerList
+
+	// lc is set once the light client has been initialized from the
+	// providers of this reactor. providers holds the providers handed out
+	// for the connected peers, which are removed from lc when the peers
+	// disconnect.
+	mtx       tmsync.RWMutex
+	lc        *light.Client
+	providers map[types.NodeID]*BlockProvider
+}
+
+// NewLightClientReactor returns a reference to a new light client reactor. It
+// accepts the light block Channel and a channel to listen for peer updates on.
+// Note, the reactor will close the p2p Channel when stopping.
+func NewLightClientReactor(
+	chainID string,
+	logger log.Logger,
+	blockCh *p2p.Channel,
+	peerUpdates *p2p.PeerUpdates,
+) *LightClientReactor {
+	r := &LightClientReactor{
+		chainID:     chainID,
+		blockCh:     blockCh,
+		peerUpdates: peerUpdates,
+		closeCh:     make(chan struct{}),
+		dispatcher:  NewDispatcher(blockCh.Out),
+		peers:       newPeerList(),
+		providers:   make(map[types.NodeID]*BlockProvider),
+	}
+
+	r.BaseService = *service.NewBaseService(logger, "LightClient", r)
+	return r
+}
+
+// OnStart starts separate go routines to listen for envelopes on the light
+// block channel and for peer updates.
+func (r *LightClientReactor) OnStart() error {
+	go r.processBlockCh()
+
+	go r.processPeerUpdates()
+
+	return nil
+}
+
+// OnStop stops the reactor by signaling to all spawned goroutines to exit and
+// blocking until they all exit.
+func (r *LightClientReactor) OnStop() {
+	r.dispatcher.Close()
+	<-r.dispatcher.Done()
+
+	close(r.closeCh)
+
+	<-r.peerUpdates.Done()
+	<-r.blockCh.Done()
+}
+
+// Providers blocks until at least minPeers peers are connected and returns a
+// light block provider for every connected peer.
+func (r *LightClientReactor) Providers(ctx context.Context, minPeers int) ([]provider.Provider, error) {
+	t := time.NewTicker(100 * time.Millisecond)
+	defer t.Stop()
+
+	for r.peers.Len() < minPeers {
+		select {
+		case <-ctx.Done():
+			return nil, fmt.Errorf("operation canceled while waiting for peers [%d/%d]",
+				r.peers.Len(), minPeers)
+		case <-r.closeCh:
+			return nil, fmt.Errorf("shutdown while waiting for peers [%d/%d]", r.peers.Len(), minPeers)
+		case <-t.C:
+		}
+	}
+
+	r.mtx.Lock()
+	defer r.mtx.Unlock()
+
+	peers := r.peers.All()
+	providers := make([]provider.Provider, len(peers))
+	for idx, peer := range peers {
+		p := NewBlockProvider(peer, r.chainID, r.dispatcher)
+		r.providers[peer] = p
+		providers[idx] = p
+	}
+	return providers, nil
+}
+
+// SetLightClient sets the light client that was created from the reactor's
+// providers. Peers that connect afterwards are added to it as witnesses, and
+// light block requests from peers are served from its trusted store.
+func (r *LightClientReactor) SetLightClient(lc *light.Client) {
+	r.mtx.Lock()
+	defer r.mtx.Unlock()
+	r.lc = lc
+}
+
+func (r *LightClientReactor) lightClient() *light.Client {
+	r.mtx.RLock()
+	defer r.mtx.RUnlock()
+	return r.lc
+}
+
+func (r *LightClientReactor) handleLightBlockMessage(envelope p2p.Envelope) error {
+	switch msg := envelope.Message.(type) {
+	case *ssproto.LightBlockRequest:
+		r.Logger.Debug("received light block request", "height", msg.Height)
+
+		// NOTE: If we don't have the light block we will send a nil light block
+		// back to the requested node, indicating that we don't have it.
+		resp := &ssproto.LightBlockResponse{}
+		if lc := r.lightClient(); lc != nil && msg.Height > 0 {
+			if lb, err := lc.TrustedLightBlock(int64(msg.Height)); err == nil {
+				lbproto, err := lb.ToProto()
+				if err != nil {
+					r.Logger.Error("marshaling light block to proto", "err", err)
+					return nil
+				}
+				resp.LightBlock = lbproto
+			}
+		}
+		r.blockCh.Out <- p2p.Envelope{
+			To:      envelope.From,
+			Message: resp,
+		}
+
+	case *ssproto.LightBlockResponse:
+		var height int64
+		if msg.LightBlock != nil {
+			height = msg.LightBlock.SignedHeader.Header.Height
+		}
+		r.Logger.Debug("received light block response", "peer", envelope.From, "height", height)
+		if err := r.dispatcher.Respond(msg.LightBlock, envelope.From); err != nil {
+			r.Logger.Error("error processing light block response", "err", err, "height", height)
+		}
+
+	default:
+		return fmt.Errorf("received unknown message: %T", msg)
+	}
+
+	return nil
+}
+
+// handleMessage handles an Envelope sent from a peer on the light block
+// channel. It will handle errors and any possible panics gracefully.
+func (r *LightClientReactor) handleMessage(envelope p2p.Envelope) (err error) {
+	defer func() {
+		if e := recover(); e != nil {
+			err = fmt.Errorf("panic in processing message: %v", e)
+			r.Logger.Error(
+				"recovering from processing message panic",
+				"err", err,
+				"stack", string(debug.Stack()),
+			)
+		}
+	}()
+
+	return r.handleLightBlockMessage(envelope)
+}
+
+// processBlockCh routes light block messages to their handler. Any error
+// encountered during message execution will result in a PeerError being sent
+// on the channel. When the reactor is stopped, we will catch the signal and
+// close the p2p Channel gracefully.
+func (r *LightClientReactor) processBlockCh() {
+	defer r.blockCh.Close()
+
+	for {
+		select {
+		case envelope := <-r.blockCh.In:
+			if err := r.handleMessage(envelope); err != nil {
+				r.Logger.Error("failed to process light block message",
+					"ch_id", r.blockCh.ID, "envelope", envelope, "err", err)
+				r.blockCh.Error <- p2p.PeerError{
+					NodeID: envelope.From,
+					Err:    err,
+				}
+			}
+
+		case <-r.closeCh:
+			r.Logger.Debug("stopped listening on light block channel; closing...")
+			return
+		}
+	}
+}
+
+// processPeerUpdate keeps track of the connected peers, adds new peers as
+// witnesses to the light client, if there is one, and removes the providers
+// of the peers that disconnect from it.
+func (r *LightClientReactor) processPeerUpdate(peerUpdate p2p.PeerUpdate) {
+	r.Logger.Debug("received peer update", "peer", peerUpdate.NodeID, "status", peerUpdate.Status)
+
+	switch peerUpdate.Status {
+	case p2p.PeerStatusUp:
+		r.peers.Append(peerUpdate.NodeID)
+	case p2p.PeerStatusDown:
+		r.peers.Remove(peerUpdate.NodeID)
+	}
+
+	r.mtx.Lock()
+	defer r.mtx.Unlock()
+
+	switch peerUpdate.Status {
+	case p2p.PeerStatusUp:
+		if _, ok := r.providers[peerUpdate.NodeID]; ok {
+			return
+		}
+		if r.lc != nil && len(r.lc.Witnesses()) < maxLightClientWitnesses {
+			p := NewBlockProvider(peerUpdate.NodeID, r.chainID, r.dispatcher)
+			r.providers[peerUpdate.NodeID] = p
+			r.lc.AddProvider(p)
+		}
+
+	case p2p.PeerStatusDown:
+		p, ok := r.providers[peerUpdate.NodeID]
+		if !ok {
+			return
+		}
+		delete(r.providers, peerUpdate.NodeID)
+		if r.lc != nil {
+			r.lc.RemoveProvider(p)
+		}
+	}
+}
+
+// processPeerUpdates initiates a blocking process where we listen for and
+// handle PeerUpdate messages. When the reactor is stopped, we will catch the
+// signal and close the p2p PeerUpdatesCh gracefully.
+func (r *LightClientReactor) processPeerUpdates() {
+	defer r.peerUpdates.Close()
+
+	for {
+		select {
+		case peerUpdate := <-r.peerUpdates.Updates():
+			r.processPeerUpdate(peerUpdate)
+
+		case <-r.closeCh:
+			r.Logger.Debug("stopped listening on peer updates channel; closing...")
+			return
+		}
+	}
+}
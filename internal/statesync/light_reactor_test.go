@@ -0,0 +1,136 @@
+package statesync
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	dbm "github.com/tendermint/tm-db"
+
+	"github.com/tendermint/tendermint/internal/p2p"
+	"github.com/tendermint/tendermint/internal/test/factory"
+	"github.com/tendermint/tendermint/libs/log"
+	"github.com/tendermint/tendermint/light"
+	dbs "github.com/tendermint/tendermint/light/store/db"
+	ssproto "github.com/tendermint/tendermint/proto/tendermint/statesync"
+	"github.com/tendermint/tendermint/types"
+)
+
+func TestLightClientReactor(t *testing.T) {
+	var chBuf uint = 2
+	blockInCh := make(chan p2p.Envelope, chBuf)
+	blockOutCh := make(chan p2p.Envelope, chBuf)
+	blockPeerErrCh := make(chan p2p.PeerError, chBuf)
+	peerUpdateCh := make(chan p2p.PeerUpdate, chBuf)
+
+	blockCh := p2p.NewChannel(LightBlockChannel, new(ssproto.Message), blockInCh, blockOutCh, blockPeerErrCh)
+	reactor := NewLightClientReactor(
+		factory.DefaultTestChainID,
+		log.TestingLogger(),
+		blockCh,
+		p2p.NewPeerUpdates(peerUpdateCh, int(chBuf)),
+	)
+	require.NoError(t, reactor.Start())
+	t.Cleanup(func() {
+		if reactor.IsRunning() {
+			require.NoError(t, reactor.Stop())
+		}
+	})
+
+	// peers answer the light block requests of the reactor's providers
+	respondCh := make(chan p2p.Envelope, chBuf)
+	closeCh := make(chan struct{})
+	defer close(closeCh)
+	chain := buildLightBlockChain(t, 1, 10, time.Now())
+	go handleLightBlockRequests(t, chain, respondCh, blockInCh, closeCh, 0)
+	forwardCh := make(chan p2p.Envelope, chBuf)
+	go func() {
+		for {
+			select {
+			case envelope := <-blockOutCh:
+				if _, ok := envelope.Message.(*ssproto.LightBlockRequest); ok {
+					respondCh <- envelope
+				} else {
+					forwardCh <- envelope
+				}
+			case <-closeCh:
+				return
+			}
+		}
+	}()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	_, err := reactor.Providers(ctx, 2)
+	require.Error(t, err)
+
+	peerUpdateCh <- p2p.PeerUpdate{NodeID: types.NodeID("aa"), Status: p2p.PeerStatusUp}
+	peerUpdateCh <- p2p.PeerUpdate{NodeID: types.NodeID("bb"), Status: p2p.PeerStatusUp}
+
+	ctx, cancel = context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	providers, err := reactor.Providers(ctx, 2)
+	require.NoError(t, err)
+	require.Len(t, providers, 2)
+
+	lc, err := light.NewClient(
+		ctx,
+		factory.DefaultTestChainID,
+		light.TrustOptions{
+			Period: 24 * time.Hour,
+			Height: 1,
+			Hash:   chain[1].Hash(),
+		},
+		providers[0],
+		providers[1:],
+		dbs.New(dbm.NewMemDB()),
+		light.Logger(log.TestingLogger()),
+	)
+	require.NoError(t, err)
+	lb, err := lc.VerifyLightBlockAtHeight(ctx, 9, time.Now())
+	require.NoError(t, err)
+	require.Equal(t, chain[9].Hash(), lb.Hash())
+
+	// peers that connect after the light client was set become witnesses
+	reactor.SetLightClient(lc)
+	peerUpdateCh <- p2p.PeerUpdate{NodeID: types.NodeID("cc"), Status: p2p.PeerStatusUp}
+	require.Eventually(t, func() bool { return len(lc.Witnesses()) == 2 }, time.Second, 10*time.Millisecond)
+
+	// and witnesses are removed when their peers disconnect
+	peerUpdateCh <- p2p.PeerUpdate{NodeID: types.NodeID("bb"), Status: p2p.PeerStatusDown}
+	require.Eventually(t, func() bool { return len(lc.Witnesses()) == 1 }, time.Second, 10*time.Millisecond)
+	require.Equal(t, "cc", lc.Witnesses()[0].(*BlockProvider).String())
+
+	// trusted light blocks are served to other peers
+	blockInCh <- p2p.Envelope{
+		From:    types.NodeID("dd"),
+		Message: &ssproto.LightBlockRequest{Height: 9},
+	}
+	select {
+	case response := <-forwardCh:
+		require.Equal(t, types.NodeID("dd"), response.To)
+		res, ok := response.Message.(*ssproto.LightBlockResponse)
+		require.True(t, ok)
+		receivedLB, err := types.LightBlockFromProto(res.LightBlock)
+		require.NoError(t, err)
+		require.Equal(t, lb.Hash(), receivedLB.Hash())
+	case <-time.After(time.Second):
+		t.Fatal("expected light block response")
+	}
+
+	// unknown light blocks are answered with an empty response
+	blockInCh <- p2p.Envelope{
+		From:    types.NodeID("dd"),
+		Message: &ssproto.LightBlockRequest{Height: 100},
+	}
+	select {
+	case response := <-forwardCh:
+		res, ok := response.Message.(*ssproto.LightBlockResponse)
+		require.True(t, ok)
+		require.Nil(t, res.LightBlock)
+	case <-time.After(time.Second):
+		t.Fatal("expected light block response")
+	}
+	require.Empty(t, blockPeerErrCh)
+}
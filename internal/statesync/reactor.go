@@ -5,6 +5,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"math/rand"
 	"reflect"
 	"runtime/debug"
 	"sort"
@@ -136,6 +137,7 @@ type Reactor struct {
 	conn        proxy.AppConnSnapshot
 	connQuery   proxy.AppConnQuery
 	tempDir     string
+	progressDir string
 	snapshotCh  *p2p.Channel
 	chunkCh     *p2p.Channel
 	blockCh     *p2p.Channel
@@ -164,7 +166,9 @@ type Reactor struct {
 // NewReactor returns a reference to a new state sync reactor, which implements
 // the service.Service interface. It accepts a logger, connections for snapshots
 // and querying, references to p2p Channels and a channel to listen for peer
-// updates on. Note, the reactor will close all p2p Channels when stopping.
+// updates on. Chunks are stored in tempDir, or in progressDir if set, where the
+// progress of a state sync is kept so that it can be resumed after a restart.
+// Note, the reactor will close all p2p Channels when stopping.
 func NewReactor(
 	chainID string,
 	initialHeight int64,
@@ -177,6 +181,7 @@ func NewReactor(
 	stateStore sm.Store,
 	blockStore *store.BlockStore,
 	tempDir string,
+	progressDir string,
 	ssMetrics *Metrics,
 ) *Reactor {
 	r := &Reactor{
@@ -192,6 +197,7 @@ func NewReactor(
 		peerUpdates:   peerUpdates,
 		closeCh:       make(chan struct{}),
 		tempDir:       tempDir,
+		progressDir:   progressDir,
 		stateStore:    stateStore,
 		blockStore:    blockStore,
 		peers:         newPeerList(),
@@ -279,6 +285,7 @@ func (r *Reactor) Sync(ctx context.Context) (sm.State, error) {
 		r.chunkCh.Out,
 		r.snapshotCh.Done(),
 		r.tempDir,
+		r.progressDir,
 		r.metrics,
 	)
 	r.mtx.Unlock()
@@ -525,6 +532,34 @@ func (r *Reactor) backfill(
 	}
 }
 
+// LightBlock fetches the light block at the given height from one of the
+// connected peers, trying them in random order until one returns it. Block
+// sync uses this to fetch the headers of the blocks to sync in header-first
+// mode. The light block is checked for basic validity only.
+func (r *Reactor) LightBlock(ctx context.Context, height int64) (*types.LightBlock, error) {
+	peers := r.peers.All()
+	if len(peers) == 0 {
+		return nil, errNoConnectedPeers
+	}
+	rand.Shuffle(len(peers), func(i, j int) { peers[i], peers[j] = peers[j], peers[i] })
+
+	var err error
+	for _, peer := range peers {
+		subCtx, cancel := context.WithTimeout(ctx, lightBlockResponseTimeout)
+		var lb *types.LightBlock
+		lb, err = NewBlockProvider(peer, r.chainID, r.dispatcher).LightBlock(subCtx, height)
+		cancel()
+		if err == nil {
+			return lb, nil
+		}
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+		r.Logger.Debug("failed to fetch light block", "height", height, "peer", peer, "err", err)
+	}
+	return nil, err
+}
+
 // handleSnapshotMessage handles envelopes sent from peers on the
 // SnapshotChannel. It returns an error only if the Envelope.Message is unknown
 // for this channel. This should never be called outside of handleMessage.
@@ -160,6 +160,7 @@ func setup(
 		rts.stateStore,
 		rts.blockStore,
 		"",
+		"",
 		m,
 	)
 
@@ -173,6 +174,7 @@ func setup(
 		rts.chunkOutCh,
 		rts.snapshotChannel.Done(),
 		"",
+		"",
 		rts.reactor.metrics,
 	)
 
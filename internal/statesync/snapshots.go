@@ -128,6 +128,13 @@ func (p *snapshotPool) Best() *snapshot {
 	return ranked[0]
 }
 
+// Get returns the snapshot with the given key, if it is in the pool.
+func (p *snapshotPool) Get(key snapshotKey) *snapshot {
+	p.Lock()
+	defer p.Unlock()
+	return p.snapshots[key]
+}
+
 // GetPeer returns a random peer for a snapshot, if any.
 func (p *snapshotPool) GetPeer(snapshot *snapshot) types.NodeID {
 	peers := p.GetPeers(snapshot)
@@ -5,6 +5,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"os"
 	"time"
 
 	abci "github.com/tendermint/tendermint/abci/types"
@@ -60,6 +61,8 @@ type syncer struct {
 	snapshotCh    chan<- p2p.Envelope
 	chunkCh       chan<- p2p.Envelope
 	tempDir       string
+	progressDir   string
+	throughput    *peerThroughput
 	fetchers      int32
 	retryTimeout  time.Duration
 
@@ -84,6 +87,7 @@ func newSyncer(
 	chunkCh chan<- p2p.Envelope,
 	closeCh <-chan struct{},
 	tempDir string,
+	progressDir string,
 	metrics *Metrics,
 ) *syncer {
 	return &syncer{
@@ -95,6 +99,8 @@ func newSyncer(
 		snapshotCh:    snapshotCh,
 		chunkCh:       chunkCh,
 		tempDir:       tempDir,
+		progressDir:   progressDir,
+		throughput:    newPeerThroughput(),
 		fetchers:      cfg.Fetchers,
 		retryTimeout:  cfg.ChunkRequestTimeout,
 		metrics:       metrics,
@@ -115,8 +121,9 @@ func (s *syncer) AddChunk(chunk *chunk) (bool, error) {
 		return false, err
 	}
 	if added {
+		peer := s.throughput.Received(chunk.Index, len(chunk.Chunk))
 		s.logger.Debug("Added chunk to queue", "height", chunk.Height, "format", chunk.Format,
-			"chunk", chunk.Index)
+			"chunk", chunk.Index, "peer", peer)
 	} else {
 		s.logger.Debug("Ignoring duplicate chunk in queue", "height", chunk.Height, "format", chunk.Format,
 			"chunk", chunk.Index)
@@ -170,6 +177,7 @@ func (s *syncer) AddPeer(peerID types.NodeID) (err error) {
 func (s *syncer) RemovePeer(peerID types.NodeID) {
 	s.logger.Debug("Removing peer from sync", "peer", peerID)
 	s.snapshots.RemovePeer(peerID)
+	s.throughput.RemovePeer(peerID)
 }
 
 // SyncAny tries to sync any of the snapshots in the snapshot pool, waiting to discover further
@@ -200,7 +208,10 @@ func (s *syncer) SyncAny(
 	for {
 		// If not nil, we're going to retry restoration of the same snapshot.
 		if snapshot == nil {
-			snapshot = s.snapshots.Best()
+			snapshot = s.resumableSnapshot()
+			if snapshot == nil {
+				snapshot = s.snapshots.Best()
+			}
 			chunks = nil
 		}
 		if snapshot == nil {
@@ -212,7 +223,11 @@ func (s *syncer) SyncAny(
 			continue
 		}
 		if chunks == nil {
-			chunks, err = newChunkQueue(snapshot, s.tempDir)
+			if s.progressDir != "" {
+				chunks, err = newPersistentChunkQueue(snapshot, s.progressDir)
+			} else {
+				chunks, err = newChunkQueue(snapshot, s.tempDir)
+			}
 			if err != nil {
 				return sm.State{}, nil, fmt.Errorf("failed to create chunk queue: %w", err)
 			}
@@ -227,9 +242,15 @@ func (s *syncer) SyncAny(
 		case err == nil:
 			s.metrics.SnapshotHeight.Set(float64(snapshot.Height))
 			s.lastSyncedSnapshotHeight = int64(snapshot.Height)
+			if err := chunks.Delete(); err != nil {
+				s.logger.Error("Failed to clean up chunk queue", "err", err)
+			}
 			return newState, commit, nil
 
 		case errors.Is(err, errAbort):
+			if err := chunks.Delete(); err != nil {
+				s.logger.Error("Failed to clean up chunk queue", "err", err)
+			}
 			return sm.State{}, nil, err
 
 		case errors.Is(err, errRetrySnapshot):
@@ -261,11 +282,18 @@ func (s *syncer) SyncAny(
 			}
 
 		default:
+			// the chunks of a persistent queue are kept to resume from after a
+			// restart, if the node is shutting down
+			if !s.interrupted(ctx) {
+				if err := chunks.Delete(); err != nil {
+					s.logger.Error("Failed to clean up chunk queue", "err", err)
+				}
+			}
 			return sm.State{}, nil, fmt.Errorf("snapshot restoration failed: %w", err)
 		}
 
 		// Discard snapshot and chunks for next iteration
-		err = chunks.Close()
+		err = chunks.Delete()
 		if err != nil {
 			s.logger.Error("Failed to clean up chunk queue", "err", err)
 		}
@@ -275,6 +303,56 @@ func (s *syncer) SyncAny(
 	}
 }
 
+// resumableSnapshot returns the snapshot whose restoration was in progress
+// before the node was restarted, if peers still offer it.
+func (s *syncer) resumableSnapshot() *snapshot {
+	if s.progressDir == "" {
+		return nil
+	}
+
+	progress, err := loadChunkProgress(s.progressDir)
+	if err != nil {
+		s.logger.Error("Failed to load state sync progress, starting over", "err", err)
+		s.removeProgress()
+		return nil
+	}
+	if progress == nil {
+		return nil
+	}
+
+	snapshot := s.snapshots.Get(progress.Snapshot.Key())
+	if snapshot == nil {
+		s.logger.Info("Snapshot of previous state sync is no longer offered by peers, starting over",
+			"height", progress.Snapshot.Height, "format", progress.Snapshot.Format,
+			"hash", progress.Snapshot.Hash)
+		s.removeProgress()
+		return nil
+	}
+
+	s.logger.Info("Resuming state sync of previous snapshot", "height", snapshot.Height,
+		"format", snapshot.Format, "hash", snapshot.Hash, "chunks", snapshot.Chunks)
+	return snapshot
+}
+
+// removeProgress removes the chunks and the progress of a previous state sync
+// which can't be resumed.
+func (s *syncer) removeProgress() {
+	if err := os.RemoveAll(s.progressDir); err != nil {
+		s.logger.Error("Failed to remove state sync progress", "dir", s.progressDir, "err", err)
+	}
+}
+
+// interrupted returns true if the sync was canceled or the syncer is closed,
+// as when the node shuts down.
+func (s *syncer) interrupted(ctx context.Context) bool {
+	select {
+	case <-s.closeCh:
+		return true
+	default:
+		return ctx.Err() != nil
+	}
+}
+
 // Sync executes a sync for a specific snapshot, returning the latest state and block commit which
 // the caller must use to bootstrap the node.
 func (s *syncer) Sync(ctx context.Context, snapshot *snapshot, chunks *chunkQueue) (sm.State, *types.Commit, error) {
@@ -518,11 +596,14 @@ func (s *syncer) fetchChunks(ctx context.Context, snapshot *snapshot, chunks *ch
 			next = true
 
 		case <-ticker.C:
+			s.throughput.Failed(index)
 			next = false
 
 		case <-ctx.Done():
+			s.throughput.Cancel(index)
 			return
 		case <-s.closeCh:
+			s.throughput.Cancel(index)
 			return
 		}
 
@@ -530,9 +611,9 @@ func (s *syncer) fetchChunks(ctx context.Context, snapshot *snapshot, chunks *ch
 	}
 }
 
-// requestChunk requests a chunk from a peer.
+// requestChunk requests a chunk from the peer with the best expected throughput.
 func (s *syncer) requestChunk(snapshot *snapshot, chunk uint32) {
-	peer := s.snapshots.GetPeer(snapshot)
+	peer := s.throughput.Pick(s.snapshots.GetPeers(snapshot))
 	if peer == "" {
 		s.logger.Error("No valid peers found for snapshot", "height", snapshot.Height,
 			"format", snapshot.Format, "hash", snapshot.Hash)
@@ -546,6 +627,7 @@ func (s *syncer) requestChunk(snapshot *snapshot, chunk uint32) {
 		"chunk", chunk,
 		"peer", peer,
 	)
+	s.throughput.Requested(chunk, peer)
 
 	msg := p2p.Envelope{
 		To: peer,
@@ -3,6 +3,8 @@ package statesync
 import (
 	"context"
 	"errors"
+	"os"
+	"path/filepath"
 	"sync"
 	"testing"
 	"time"
@@ -219,8 +221,17 @@ func TestSyncer_SyncAny_noSnapshots(t *testing.T) {
 
 	rts := setup(t, nil, nil, stateProvider, 2)
 
-	_, _, err := rts.syncer.SyncAny(ctx, 0, func() {})
+	// the progress of a snapshot which is no longer offered is removed
+	rts.syncer.progressDir = filepath.Join(t.TempDir(), "statesync")
+	queue, err := newPersistentChunkQueue(&snapshot{Height: 1, Format: 1, Chunks: 3, Hash: []byte{1}},
+		rts.syncer.progressDir)
+	require.NoError(t, err)
+	require.NoError(t, queue.Close())
+
+	_, _, err = rts.syncer.SyncAny(ctx, 0, func() {})
 	require.Equal(t, errNoSnapshots, err)
+	_, err = os.Stat(rts.syncer.progressDir)
+	require.True(t, os.IsNotExist(err))
 }
 
 func TestSyncer_SyncAny_abort(t *testing.T) {
@@ -382,9 +393,15 @@ func TestSyncer_SyncAny_abciError(t *testing.T) {
 		Snapshot: toABCI(s), AppHash: []byte("app_hash"),
 	}).Once().Return(nil, errBoom)
 
+	rts.syncer.progressDir = filepath.Join(t.TempDir(), "statesync")
+
 	_, _, err = rts.syncer.SyncAny(ctx, 0, func() {})
 	require.True(t, errors.Is(err, errBoom))
 	rts.conn.AssertExpectations(t)
+
+	// the chunks of a failed restore are removed
+	_, err = os.Stat(rts.syncer.progressDir)
+	require.True(t, os.IsNotExist(err))
 }
 
 func TestSyncer_offerSnapshot(t *testing.T) {
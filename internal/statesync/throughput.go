@@ -0,0 +1,161 @@
+package statesync
+
+import (
+	"time"
+
+	tmsync "github.com/tendermint/tendermint/internal/libs/sync"
+	"github.com/tendermint/tendermint/types"
+)
+
+// throughputWeight is the weight of the most recent sample in a peer's
+// exponentially weighted moving average throughput.
+const throughputWeight = 0.3
+
+// chunkRequest is an outstanding chunk request to a peer.
+type chunkRequest struct {
+	peer types.NodeID
+	sent time.Time
+}
+
+// peerThroughput tracks how fast peers deliver snapshot chunks, so that chunks
+// can be requested from the peers that are likely to deliver them the fastest
+// while still spreading requests across peers.
+type peerThroughput struct {
+	tmsync.Mutex
+	rates    map[types.NodeID]float64 // moving average throughput in bytes/second
+	inflight map[types.NodeID]int     // number of outstanding requests per peer
+	requests map[uint32]chunkRequest  // outstanding requests by chunk index
+}
+
+// newPeerThroughput creates a new, empty peer throughput tracker.
+func newPeerThroughput() *peerThroughput {
+	return &peerThroughput{
+		rates:    make(map[types.NodeID]float64),
+		inflight: make(map[types.NodeID]int),
+		requests: make(map[uint32]chunkRequest),
+	}
+}
+
+// Pick returns the peer with the highest expected throughput per outstanding
+// request, or an empty ID if there are no peers. Peers without any samples yet
+// are assumed to be as fast as the fastest known peer, so that they are tried
+// out. Ties are broken in the order of the given peers.
+func (t *peerThroughput) Pick(peers []types.NodeID) types.NodeID {
+	t.Lock()
+	defer t.Unlock()
+
+	var (
+		best      types.NodeID
+		bestScore float64
+	)
+	for _, peer := range peers {
+		score := t.rate(peer) / float64(t.inflight[peer]+1)
+		if best == "" || score > bestScore {
+			best = peer
+			bestScore = score
+		}
+	}
+	return best
+}
+
+// Requested records that a chunk was requested from a peer. A previous
+// request of the same chunk is considered canceled.
+func (t *peerThroughput) Requested(index uint32, peer types.NodeID) {
+	t.Lock()
+	defer t.Unlock()
+
+	t.release(index)
+	t.requests[index] = chunkRequest{peer: peer, sent: time.Now()}
+	t.inflight[peer]++
+}
+
+// Received records that a chunk of the given size was received, updating the
+// throughput of the peer it was requested from. It returns that peer, if any.
+func (t *peerThroughput) Received(index uint32, size int) types.NodeID {
+	t.Lock()
+	defer t.Unlock()
+
+	req, ok := t.requests[index]
+	if !ok {
+		return ""
+	}
+	t.release(index)
+
+	elapsed := time.Since(req.sent).Seconds()
+	if elapsed <= 0 {
+		elapsed = time.Millisecond.Seconds()
+	}
+	sample := float64(size) / elapsed
+	if rate, ok := t.rates[req.peer]; ok {
+		t.rates[req.peer] = throughputWeight*sample + (1-throughputWeight)*rate
+	} else {
+		t.rates[req.peer] = sample
+	}
+	return req.peer
+}
+
+// Failed records that a chunk request timed out, halving the throughput of the
+// peer it was requested from.
+func (t *peerThroughput) Failed(index uint32) {
+	t.Lock()
+	defer t.Unlock()
+
+	req, ok := t.requests[index]
+	if !ok {
+		return
+	}
+	t.release(index)
+	t.rates[req.peer] = t.rate(req.peer) / 2
+}
+
+// Cancel forgets about an outstanding chunk request without affecting the
+// throughput of the peer.
+func (t *peerThroughput) Cancel(index uint32) {
+	t.Lock()
+	defer t.Unlock()
+	t.release(index)
+}
+
+// RemovePeer forgets about a peer.
+func (t *peerThroughput) RemovePeer(peer types.NodeID) {
+	t.Lock()
+	defer t.Unlock()
+
+	delete(t.rates, peer)
+	delete(t.inflight, peer)
+	for index, req := range t.requests {
+		if req.peer == peer {
+			delete(t.requests, index)
+		}
+	}
+}
+
+// rate returns the expected throughput of a peer. The caller must hold the
+// mutex lock.
+func (t *peerThroughput) rate(peer types.NodeID) float64 {
+	if rate, ok := t.rates[peer]; ok {
+		return rate
+	}
+
+	max := 1.0
+	for _, rate := range t.rates {
+		if rate > max {
+			max = rate
+		}
+	}
+	return max
+}
+
+// release removes an outstanding request. The caller must hold the mutex lock.
+func (t *peerThroughput) release(index uint32) {
+	req, ok := t.requests[index]
+	if !ok {
+		return
+	}
+	delete(t.requests, index)
+	if t.inflight[req.peer] > 1 {
+		t.inflight[req.peer]--
+	} else {
+		delete(t.inflight, req.peer)
+	}
+}
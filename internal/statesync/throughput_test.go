@@ -0,0 +1,49 @@
+package statesync
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/tendermint/tendermint/types"
+)
+
+func TestPeerThroughput(t *testing.T) {
+	peerA := types.NodeID("aa")
+	peerB := types.NodeID("bb")
+	peerC := types.NodeID("cc")
+	peers := []types.NodeID{peerA, peerB}
+
+	tracker := newPeerThroughput()
+	require.Equal(t, types.NodeID(""), tracker.Pick(nil))
+
+	// without samples, requests are spread across peers
+	require.Equal(t, peerA, tracker.Pick(peers))
+	tracker.Requested(0, peerA)
+	require.Equal(t, peerB, tracker.Pick(peers))
+	tracker.Requested(1, peerB)
+
+	// peer A delivers far faster than peer B
+	require.Equal(t, peerA, tracker.Received(0, 1<<20))
+	time.Sleep(10 * time.Millisecond)
+	require.Equal(t, peerB, tracker.Received(1, 1))
+	require.Equal(t, types.NodeID(""), tracker.Received(1, 1))
+
+	require.Equal(t, peerA, tracker.Pick(peers))
+	tracker.Requested(2, peerA)
+	require.Equal(t, peerA, tracker.Pick(peers))
+
+	// unknown peers are assumed to be as fast as the fastest peer
+	require.Equal(t, peerC, tracker.Pick(append(peers, peerC)))
+
+	// timeouts penalize the peer
+	tracker.Cancel(2)
+	tracker.Requested(3, peerC)
+	tracker.Failed(3)
+	require.Equal(t, peerA, tracker.Pick([]types.NodeID{peerC, peerA}))
+
+	tracker.RemovePeer(peerA)
+	require.Empty(t, tracker.requests)
+	require.Equal(t, peerA, tracker.Pick(peers))
+}
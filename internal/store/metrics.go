@@ -0,0 +1,69 @@
+package store
+
+import (
+	"github.com/go-kit/kit/metrics"
+	"github.com/go-kit/kit/metrics/discard"
+	"github.com/go-kit/kit/metrics/prometheus"
+	stdprometheus "github.com/prometheus/client_golang/prometheus"
+)
+
+const (
+	// MetricsSubsystem is a subsystem shared by all metrics exposed by this
+	// package.
+	MetricsSubsystem = "blockstore"
+)
+
+// Metrics contains metrics exposed by this package.
+type Metrics struct {
+	// Uncompressed size of the records written, in bytes, labeled by record
+	// type.
+	RawBytes metrics.Counter
+
+	// Size of the records written after compression, in bytes, labeled by
+	// record type.
+	StoredBytes metrics.Counter
+
+	// Ratio of stored to uncompressed size of each record written, labeled by
+	// record type.
+	CompressionRatio metrics.Histogram
+}
+
+// PrometheusMetrics returns Metrics build using Prometheus client library.
+// Optionally, labels can be provided along with their values ("foo",
+// "fooValue").
+func PrometheusMetrics(namespace string, labelsAndValues ...string) *Metrics {
+	labels := []string{}
+	for i := 0; i < len(labelsAndValues); i += 2 {
+		labels = append(labels, labelsAndValues[i])
+	}
+	return &Metrics{
+		RawBytes: prometheus.NewCounterFrom(stdprometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: MetricsSubsystem,
+			Name:      "raw_bytes",
+			Help:      "Uncompressed size of the records written, in bytes.",
+		}, append(labels, "record")).With(labelsAndValues...),
+		StoredBytes: prometheus.NewCounterFrom(stdprometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: MetricsSubsystem,
+			Name:      "stored_bytes",
+			Help:      "Size of the records written after compression, in bytes.",
+		}, append(labels, "record")).With(labelsAndValues...),
+		CompressionRatio: prometheus.NewHistogramFrom(stdprometheus.HistogramOpts{
+			Namespace: namespace,
+			Subsystem: MetricsSubsystem,
+			Name:      "compression_ratio",
+			Help:      "Ratio of stored to uncompressed size of each record written.",
+			Buckets:   stdprometheus.LinearBuckets(0.1, 0.1, 10),
+		}, append(labels, "record")).With(labelsAndValues...),
+	}
+}
+
+// NopMetrics returns no-op Metrics.
+func NopMetrics() *Metrics {
+	return &Metrics{
+		RawBytes:         discard.NewCounter(),
+		StoredBytes:      discard.NewCounter(),
+		CompressionRatio: discard.NewHistogram(),
+	}
+}
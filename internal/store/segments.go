@@ -0,0 +1,270 @@
+package store
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+const (
+	// defaultSegmentSize is the size above which a new segment is started.
+	defaultSegmentSize = int64(512 << 20)
+
+	segmentExt = ".seg"
+
+	// every record is prefixed with its length and a CRC32-C checksum
+	recordHeaderSize = 8
+	locationSize     = 20
+)
+
+var crcTable = crc32.MakeTable(crc32.Castagnoli)
+
+// partLocation is the position of a block part record in a segment.
+type partLocation struct {
+	segment uint64
+	offset  uint64
+	length  uint32
+}
+
+func (l partLocation) bytes() []byte {
+	bz := make([]byte, locationSize)
+	binary.BigEndian.PutUint64(bz[0:8], l.segment)
+	binary.BigEndian.PutUint64(bz[8:16], l.offset)
+	binary.BigEndian.PutUint32(bz[16:20], l.length)
+	return bz
+}
+
+func partLocationFromBytes(bz []byte) (partLocation, error) {
+	if len(bz) != locationSize {
+		return partLocation{}, fmt.Errorf("invalid part location length %d", len(bz))
+	}
+	return partLocation{
+		segment: binary.BigEndian.Uint64(bz[0:8]),
+		offset:  binary.BigEndian.Uint64(bz[8:16]),
+		length:  binary.BigEndian.Uint32(bz[16:20]),
+	}, nil
+}
+
+// segmentStore stores block parts in numbered, append-only segment files.
+// Only the last segment is written to; once it grows beyond maxSize a new one
+// is started at the next block. The locations of the records and the highest
+// height written to each segment are kept in the BlockStore's database, so
+// that whole segments can be removed when pruning.
+type segmentStore struct {
+	dir     string
+	maxSize int64
+	noSync  bool // only for tests
+
+	mtx        sync.RWMutex
+	files      map[uint64]*os.File // open segments, by number
+	active     uint64              // number of the segment being appended to
+	activeSize int64
+}
+
+func newSegmentStore(dir string, maxSize int64) (*segmentStore, error) {
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return nil, fmt.Errorf("creating segment directory: %w", err)
+	}
+	ss := &segmentStore{
+		dir:     dir,
+		maxSize: maxSize,
+		files:   make(map[uint64]*os.File),
+	}
+
+	segments, err := ss.list()
+	if err != nil {
+		return nil, err
+	}
+	active := uint64(1)
+	if len(segments) > 0 {
+		active = segments[len(segments)-1]
+	}
+	if err := ss.openActive(active); err != nil {
+		return nil, err
+	}
+	return ss, nil
+}
+
+// list returns the numbers of the segments in the directory, in ascending
+// order.
+func (ss *segmentStore) list() ([]uint64, error) {
+	entries, err := os.ReadDir(ss.dir)
+	if err != nil {
+		return nil, err
+	}
+	segments := make([]uint64, 0, len(entries))
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || !strings.HasSuffix(name, segmentExt) {
+			continue
+		}
+		num, err := strconv.ParseUint(strings.TrimSuffix(name, segmentExt), 10, 64)
+		if err != nil {
+			continue
+		}
+		segments = append(segments, num)
+	}
+	sort.Slice(segments, func(i, j int) bool { return segments[i] < segments[j] })
+	return segments, nil
+}
+
+func (ss *segmentStore) path(segment uint64) string {
+	return filepath.Join(ss.dir, fmt.Sprintf("%020d%s", segment, segmentExt))
+}
+
+// openActive opens (or creates) the given segment for appending. The caller
+// must hold the write lock, or have exclusive access to ss.
+func (ss *segmentStore) openActive(segment uint64) error {
+	file, err := os.OpenFile(ss.path(segment), os.O_RDWR|os.O_CREATE, 0600)
+	if err != nil {
+		return fmt.Errorf("opening segment %d: %w", segment, err)
+	}
+	size, err := file.Seek(0, io.SeekEnd)
+	if err != nil {
+		file.Close()
+		return fmt.Errorf("opening segment %d: %w", segment, err)
+	}
+	ss.files[segment] = file
+	ss.active = segment
+	ss.activeSize = size
+	return nil
+}
+
+// beginBlock must be called before the parts of a block are appended. It
+// starts a new segment if the active one is full, and returns the number of
+// the segment the block is written to.
+func (ss *segmentStore) beginBlock() (uint64, error) {
+	ss.mtx.Lock()
+	defer ss.mtx.Unlock()
+
+	if ss.activeSize < ss.maxSize {
+		return ss.active, nil
+	}
+	if err := ss.openActive(ss.active + 1); err != nil {
+		return 0, err
+	}
+	return ss.active, nil
+}
+
+// append writes a record to the active segment and returns its location.
+// The record is not durable until sync is called.
+func (ss *segmentStore) append(bz []byte) (partLocation, error) {
+	ss.mtx.Lock()
+	defer ss.mtx.Unlock()
+
+	record := make([]byte, recordHeaderSize+len(bz))
+	binary.BigEndian.PutUint32(record[0:4], uint32(len(bz)))
+	binary.BigEndian.PutUint32(record[4:8], crc32.Checksum(bz, crcTable))
+	copy(record[recordHeaderSize:], bz)
+
+	loc := partLocation{
+		segment: ss.active,
+		offset:  uint64(ss.activeSize),
+		length:  uint32(len(bz)),
+	}
+	if _, err := ss.files[ss.active].WriteAt(record, ss.activeSize); err != nil {
+		return partLocation{}, fmt.Errorf("writing to segment %d: %w", ss.active, err)
+	}
+	ss.activeSize += int64(len(record))
+	return loc, nil
+}
+
+// sync flushes the active segment to disk.
+func (ss *segmentStore) sync() error {
+	if ss.noSync {
+		return nil
+	}
+	ss.mtx.RLock()
+	defer ss.mtx.RUnlock()
+	return ss.files[ss.active].Sync()
+}
+
+// read returns the record at the given location. It returns nil if the
+// segment has been removed.
+func (ss *segmentStore) read(loc partLocation) ([]byte, error) {
+	file, err := ss.file(loc.segment)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	record := make([]byte, recordHeaderSize+int(loc.length))
+	if _, err := file.ReadAt(record, int64(loc.offset)); err != nil {
+		return nil, fmt.Errorf("reading segment %d at offset %d: %w", loc.segment, loc.offset, err)
+	}
+	if length := binary.BigEndian.Uint32(record[0:4]); length != loc.length {
+		return nil, fmt.Errorf("segment %d at offset %d: expected record length %d, got %d",
+			loc.segment, loc.offset, loc.length, length)
+	}
+	bz := record[recordHeaderSize:]
+	if crc := binary.BigEndian.Uint32(record[4:8]); crc != crc32.Checksum(bz, crcTable) {
+		return nil, fmt.Errorf("segment %d at offset %d: checksum mismatch", loc.segment, loc.offset)
+	}
+	return bz, nil
+}
+
+// file returns the open file for a segment, opening it for reading if needed.
+func (ss *segmentStore) file(segment uint64) (*os.File, error) {
+	ss.mtx.RLock()
+	file, ok := ss.files[segment]
+	ss.mtx.RUnlock()
+	if ok {
+		return file, nil
+	}
+
+	ss.mtx.Lock()
+	defer ss.mtx.Unlock()
+	if file, ok := ss.files[segment]; ok {
+		return file, nil
+	}
+	file, err := os.Open(ss.path(segment))
+	if err != nil {
+		return nil, err
+	}
+	ss.files[segment] = file
+	return file, nil
+}
+
+// remove deletes a segment. The active segment is never removed.
+func (ss *segmentStore) remove(segment uint64) (bool, error) {
+	ss.mtx.Lock()
+	defer ss.mtx.Unlock()
+
+	if segment == ss.active {
+		return false, nil
+	}
+	if file, ok := ss.files[segment]; ok {
+		if err := file.Close(); err != nil {
+			return false, err
+		}
+		delete(ss.files, segment)
+	}
+	if err := os.Remove(ss.path(segment)); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return false, err
+	}
+	return true, nil
+}
+
+// close closes all open segments.
+func (ss *segmentStore) close() error {
+	ss.mtx.Lock()
+	defer ss.mtx.Unlock()
+
+	var firstErr error
+	for segment, file := range ss.files {
+		if err := file.Close(); err != nil && firstErr == nil {
+			firstErr = err
+		}
+		delete(ss.files, segment)
+	}
+	return firstErr
+}
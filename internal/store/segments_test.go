@@ -0,0 +1,113 @@
+package store
+
+import (
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	dbm "github.com/tendermint/tm-db"
+
+	"github.com/tendermint/tendermint/internal/state/test/factory"
+	tmtime "github.com/tendermint/tendermint/libs/time"
+	"github.com/tendermint/tendermint/types"
+)
+
+// TestFileBlockStore runs the block store tests against a store keeping the
+// block parts in segment files.
+func TestFileBlockStore(t *testing.T) {
+	defer func(f func(dbm.DB) *BlockStore) { newBlockStore = f }(newBlockStore)
+	newBlockStore = func(db dbm.DB) *BlockStore {
+		bs, err := NewFileBlockStore(db, t.TempDir(), WithSegmentSize(4096))
+		require.NoError(t, err)
+		bs.segments.noSync = true
+		t.Cleanup(func() { require.NoError(t, bs.Close()) })
+		return bs
+	}
+
+	tests := map[string]func(*testing.T){
+		"SaveLoadBlock":          TestBlockStoreSaveLoadBlock,
+		"LoadBaseMeta":           TestLoadBaseMeta,
+		"LoadBlockPart":          TestLoadBlockPart,
+		"PruneBlocks":            TestPruneBlocks,
+		"DeleteBlocksAfter":      TestDeleteBlocksAfter,
+		"LoadBlockMeta":          TestLoadBlockMeta,
+		"BlockFetchAtHeight":     TestBlockFetchAtHeight,
+		"SeenAndCanonicalCommit": TestSeenAndCanonicalCommit,
+	}
+	for name, test := range tests {
+		t.Run(name, test)
+	}
+}
+
+func TestFileBlockStoreSegments(t *testing.T) {
+	db := dbm.NewMemDB()
+	dir := t.TempDir()
+
+	// write the first blocks to the database, as a node would have before
+	// switching to segment files
+	kvStore := NewBlockStore(db)
+	saveBlock := func(bs *BlockStore, height int64) {
+		block := factory.MakeBlock(state, height, makeTestCommit(height-1, tmtime.Now()))
+		bs.SaveBlock(block, block.MakePartSet(types.BlockPartSizeBytes), makeTestCommit(height, tmtime.Now()))
+	}
+	for h := int64(1); h <= 5; h++ {
+		saveBlock(kvStore, h)
+	}
+
+	bs, err := NewFileBlockStore(db, dir, WithSegmentSize(2048))
+	require.NoError(t, err)
+	for h := int64(6); h <= 50; h++ {
+		saveBlock(bs, h)
+	}
+	segments, err := bs.segments.list()
+	require.NoError(t, err)
+	require.Greater(t, len(segments), 2, "expected the segments to be rotated")
+
+	// parts of the blocks saved to the database are still readable, and
+	// the new ones are only in the segments
+	for h := int64(1); h <= 50; h++ {
+		require.NotNil(t, bs.LoadBlock(h), "height %d", h)
+	}
+	bz, err := db.Get(blockPartKey(10, 0))
+	require.NoError(t, err)
+	require.Empty(t, bz)
+
+	// the store can be reopened and written to
+	require.NoError(t, bs.Close())
+	bs, err = NewFileBlockStore(db, dir, WithSegmentSize(2048))
+	require.NoError(t, err)
+	defer bs.Close()
+	saveBlock(bs, 51)
+	require.NotNil(t, bs.LoadBlock(25))
+	require.NotNil(t, bs.LoadBlock(51))
+
+	// pruning removes the whole segments below the retained height
+	_, err = bs.PruneBlocks(30)
+	require.NoError(t, err)
+	for h := int64(30); h <= 51; h++ {
+		require.NotNil(t, bs.LoadBlock(h), "height %d", h)
+	}
+	remaining, err := bs.segments.list()
+	require.NoError(t, err)
+	assert.Less(t, len(remaining), len(segments))
+	entries, err := os.ReadDir(dir)
+	require.NoError(t, err)
+	assert.Len(t, entries, len(remaining))
+
+	// and a corrupted record is detected
+	loc, err := db.Get(blockPartLocationKey(51, 0))
+	require.NoError(t, err)
+	l, err := partLocationFromBytes(loc)
+	require.NoError(t, err)
+	f, err := os.OpenFile(bs.segments.path(l.segment), os.O_RDWR, 0600)
+	require.NoError(t, err)
+	_, err = f.WriteAt([]byte{0xff}, int64(l.offset)+recordHeaderSize)
+	require.NoError(t, err)
+	require.NoError(t, f.Close())
+	_, _, panicErr := doFn(func() (interface{}, error) {
+		return bs.LoadBlockPart(51, 0), nil
+	})
+	require.Error(t, panicErr)
+	require.Contains(t, panicErr.Error(), "checksum mismatch")
+}
@@ -2,6 +2,7 @@ package store
 
 import (
 	"bytes"
+	"context"
 	"fmt"
 	"strconv"
 
@@ -9,6 +10,8 @@ import (
 	"github.com/google/orderedcode"
 	dbm "github.com/tendermint/tm-db"
 
+	"github.com/tendermint/tendermint/config"
+	"github.com/tendermint/tendermint/internal/libs/compress"
 	tmproto "github.com/tendermint/tendermint/proto/tendermint/types"
 	"github.com/tendermint/tendermint/types"
 )
@@ -27,17 +30,104 @@ the Commit data outside the Block. (TODO)
 
 The store can be assumed to contain all contiguous blocks between base and height (inclusive).
 
+Block parts and commits can optionally be compressed (see WithCompression).
+The codec is recorded per record, so stores written with different or no
+compression remain readable.
+
+By default all records are kept in the database. A store created with
+NewFileBlockStore instead appends block parts to rotating segment files and
+only keeps their locations in the database, which avoids compacting the bulk
+of the block data and lets pruning delete whole segments.
+
 // NOTE: BlockStore methods will panic if they encounter errors
 // deserializing loaded data, indicating probable corruption on disk.
 */
 type BlockStore struct {
-	db dbm.DB
+	db      dbm.DB
+	codec   compress.Codec
+	metrics *Metrics
+
+	// segments is nil unless the block parts are stored in segment files.
+	segments    *segmentStore
+	segmentSize int64
+}
+
+// BlockStoreOption sets an optional parameter on the BlockStore.
+type BlockStoreOption func(*BlockStore)
+
+// WithCompression sets the codec used to compress block parts and commits
+// written by the BlockStore. Records are always decompressed according to the
+// codec they were written with.
+func WithCompression(codec compress.Codec) BlockStoreOption {
+	return func(bs *BlockStore) { bs.codec = codec }
+}
+
+// WithMetrics sets the metrics.
+func WithMetrics(metrics *Metrics) BlockStoreOption {
+	return func(bs *BlockStore) { bs.metrics = metrics }
+}
+
+// WithSegmentSize sets the size above which a store created with
+// NewFileBlockStore starts a new segment file.
+func WithSegmentSize(size int64) BlockStoreOption {
+	return func(bs *BlockStore) { bs.segmentSize = size }
 }
 
 // NewBlockStore returns a new BlockStore with the given DB,
 // initialized to the last height that was committed to the DB.
-func NewBlockStore(db dbm.DB) *BlockStore {
-	return &BlockStore{db}
+func NewBlockStore(db dbm.DB, options ...BlockStoreOption) *BlockStore {
+	bs := &BlockStore{
+		db:          db,
+		codec:       compress.None,
+		metrics:     NopMetrics(),
+		segmentSize: defaultSegmentSize,
+	}
+	for _, option := range options {
+		option(bs)
+	}
+	return bs
+}
+
+// NewFileBlockStore returns a new BlockStore which appends block parts to
+// segment files in dir, and keeps the block metadata, commits and the
+// locations of the parts in the given DB. Block parts previously saved to the
+// DB by a store created with NewBlockStore remain readable. The store must be
+// closed with Close.
+func NewFileBlockStore(db dbm.DB, dir string, options ...BlockStoreOption) (*BlockStore, error) {
+	bs := NewBlockStore(db, options...)
+	segments, err := newSegmentStore(dir, bs.segmentSize)
+	if err != nil {
+		return nil, err
+	}
+	bs.segments = segments
+	return bs, nil
+}
+
+// OpenBlockStore returns a BlockStore keeping its records in db, using the
+// backend and compression selected in cfg.
+func OpenBlockStore(cfg config.BaseConfig, db dbm.DB, options ...BlockStoreOption) (*BlockStore, error) {
+	codec, err := compress.ParseCodec(cfg.DBCompression)
+	if err != nil {
+		return nil, err
+	}
+	options = append([]BlockStoreOption{WithCompression(codec)}, options...)
+
+	switch cfg.BlockStoreBackend {
+	case "", config.BlockStoreBackendKV:
+		return NewBlockStore(db, options...), nil
+	case config.BlockStoreBackendFlatFile:
+		return NewFileBlockStore(db, cfg.BlockSegmentsDir(), options...)
+	default:
+		return nil, fmt.Errorf("unknown block store backend %q", cfg.BlockStoreBackend)
+	}
+}
+
+// Close releases the segment files of the store. It does not close the DB.
+func (bs *BlockStore) Close() error {
+	if bs.segments == nil {
+		return nil
+	}
+	return bs.segments.close()
 }
 
 // Base returns the first known contiguous block height, or 0 for empty block stores.
@@ -187,13 +277,17 @@ func (bs *BlockStore) LoadBlockByHash(hash []byte) *types.Block {
 func (bs *BlockStore) LoadBlockPart(height int64, index int) *types.Part {
 	var pbpart = new(tmproto.Part)
 
-	bz, err := bs.db.Get(blockPartKey(height, index))
+	bz, err := bs.loadBlockPartBytes(height, index)
 	if err != nil {
 		panic(err)
 	}
 	if len(bz) == 0 {
 		return nil
 	}
+	bz, err = compress.Decode(bz)
+	if err != nil {
+		panic(fmt.Errorf("error reading block part: %w", err))
+	}
 
 	err = proto.Unmarshal(bz, pbpart)
 	if err != nil {
@@ -207,6 +301,25 @@ func (bs *BlockStore) LoadBlockPart(height int64, index int) *types.Part {
 	return part
 }
 
+// loadBlockPartBytes returns the stored record of a block part, looking it up
+// in the segment files first if the store has any.
+func (bs *BlockStore) loadBlockPartBytes(height int64, index int) ([]byte, error) {
+	if bs.segments != nil {
+		bz, err := bs.db.Get(blockPartLocationKey(height, index))
+		if err != nil {
+			return nil, err
+		}
+		if len(bz) > 0 {
+			loc, err := partLocationFromBytes(bz)
+			if err != nil {
+				return nil, err
+			}
+			return bs.segments.read(loc)
+		}
+	}
+	return bs.db.Get(blockPartKey(height, index))
+}
+
 // LoadBlockMeta returns the BlockMeta for the given height.
 // If no block is found for the given height, it returns nil.
 func (bs *BlockStore) LoadBlockMeta(height int64) *types.BlockMeta {
@@ -247,6 +360,10 @@ func (bs *BlockStore) LoadBlockCommit(height int64) *types.Commit {
 	if len(bz) == 0 {
 		return nil
 	}
+	bz, err = compress.Decode(bz)
+	if err != nil {
+		panic(fmt.Errorf("error reading block commit: %w", err))
+	}
 	err = proto.Unmarshal(bz, pbc)
 	if err != nil {
 		panic(fmt.Errorf("error reading block commit: %w", err))
@@ -271,6 +388,10 @@ func (bs *BlockStore) LoadSeenCommit() *types.Commit {
 	if len(bz) == 0 {
 		return nil
 	}
+	bz, err = compress.Decode(bz)
+	if err != nil {
+		panic(fmt.Sprintf("error reading block seen commit: %v", err))
+	}
 	err = proto.Unmarshal(bz, pbc)
 	if err != nil {
 		panic(fmt.Sprintf("error reading block seen commit: %v", err))
@@ -293,28 +414,6 @@ func (bs *BlockStore) PruneBlocks(height int64) (uint64, error) {
 		return 0, fmt.Errorf("height must be equal to or less than the latest height %d", bs.Height())
 	}
 
-	// when removing the block meta, use the hash to remove the hash key at the same time
-	removeBlockHash := func(key, value []byte, batch dbm.Batch) error {
-		// unmarshal block meta
-		var pbbm = new(tmproto.BlockMeta)
-		err := proto.Unmarshal(value, pbbm)
-		if err != nil {
-			return fmt.Errorf("unmarshal to tmproto.BlockMeta: %w", err)
-		}
-
-		blockMeta, err := types.BlockMetaFromProto(pbbm)
-		if err != nil {
-			return fmt.Errorf("error from proto blockMeta: %w", err)
-		}
-
-		// delete the hash key corresponding to the block meta's hash
-		if err := batch.Delete(blockHashKey(blockMeta.BlockID.Hash)); err != nil {
-			return fmt.Errorf("failed to delete hash key: %X: %w", blockHashKey(blockMeta.BlockID.Hash), err)
-		}
-
-		return nil
-	}
-
 	// remove block meta first as this is used to indicate whether the block exists.
 	// For this reason, we also use ony block meta as a measure of the amount of blocks pruned
 	pruned, err := bs.pruneRange(blockMetaKey(0), blockMetaKey(height), removeBlockHash)
@@ -330,9 +429,138 @@ func (bs *BlockStore) PruneBlocks(height int64) (uint64, error) {
 		return pruned, err
 	}
 
+	if bs.segments != nil {
+		if _, err := bs.pruneRange(blockPartLocationKey(0, 0), blockPartLocationKey(height, 0), nil); err != nil {
+			return pruned, err
+		}
+		if err := bs.pruneSegments(height); err != nil {
+			return pruned, err
+		}
+	}
+
 	return pruned, nil
 }
 
+// DeleteBlocksAfter removes all blocks above height, which must be within the
+// store's range. The canonical commit for height becomes the seen commit. It
+// returns the number of blocks removed.
+//
+// With segment files, the removed block parts are left in their segments and
+// are only reclaimed once the segments are pruned.
+func (bs *BlockStore) DeleteBlocksAfter(height int64) (uint64, error) {
+	if base := bs.Base(); height < base || base == 0 {
+		return 0, fmt.Errorf("height %d is not within the store's range", height)
+	}
+	if height >= bs.Height() {
+		return 0, nil
+	}
+
+	// the commit for height was saved with the next block, so it must be kept
+	// as the seen commit before that block is removed
+	commit := bs.LoadBlockCommit(height)
+	if commit == nil {
+		return 0, fmt.Errorf("commit for height %d not found", height)
+	}
+	if err := bs.SaveSeenCommit(height, commit); err != nil {
+		return 0, err
+	}
+
+	// remove block meta first as this is used to indicate whether the block exists.
+	end := height + 1
+	deleted, err := bs.pruneRange(blockMetaKey(end), blockMetaKey(1<<63-1), removeBlockHash)
+	if err != nil {
+		return deleted, err
+	}
+
+	if _, err := bs.pruneRange(blockPartKey(end, 0), blockPartKey(1<<63-1, 0), nil); err != nil {
+		return deleted, err
+	}
+
+	if _, err := bs.pruneRange(blockCommitKey(height), blockCommitKey(1<<63-1), nil); err != nil {
+		return deleted, err
+	}
+
+	if bs.segments != nil {
+		if _, err := bs.pruneRange(
+			blockPartLocationKey(end, 0), blockPartLocationKey(1<<63-1, 0), nil,
+		); err != nil {
+			return deleted, err
+		}
+	}
+
+	return deleted, nil
+}
+
+// removeBlockHash is a pruneRange hook which, when removing a block meta,
+// removes the hash key at the same time.
+func removeBlockHash(key, value []byte, batch dbm.Batch) error {
+	// unmarshal block meta
+	var pbbm = new(tmproto.BlockMeta)
+	err := proto.Unmarshal(value, pbbm)
+	if err != nil {
+		return fmt.Errorf("unmarshal to tmproto.BlockMeta: %w", err)
+	}
+
+	blockMeta, err := types.BlockMetaFromProto(pbbm)
+	if err != nil {
+		return fmt.Errorf("error from proto blockMeta: %w", err)
+	}
+
+	// delete the hash key corresponding to the block meta's hash
+	if err := batch.Delete(blockHashKey(blockMeta.BlockID.Hash)); err != nil {
+		return fmt.Errorf("failed to delete hash key: %X: %w", blockHashKey(blockMeta.BlockID.Hash), err)
+	}
+
+	return nil
+}
+
+// pruneSegments removes the segment files which only contain blocks below
+// height. It must be called after the locations of the pruned block parts
+// have been deleted.
+func (bs *BlockStore) pruneSegments(height int64) error {
+	iter, err := bs.db.Iterator(prefixKey(prefixBlockSegment), prefixKey(prefixBlockSegment+1))
+	if err != nil {
+		return err
+	}
+	var segments []uint64
+	for ; iter.Valid(); iter.Next() {
+		lastHeight, err := strconv.ParseInt(string(iter.Value()), 10, 64)
+		if err != nil {
+			iter.Close()
+			return fmt.Errorf("invalid height of segment at key %X: %w", iter.Key(), err)
+		}
+		if lastHeight >= height {
+			continue
+		}
+		segment, err := decodeBlockSegmentKey(iter.Key())
+		if err != nil {
+			iter.Close()
+			return err
+		}
+		segments = append(segments, segment)
+	}
+	err = iter.Error()
+	if cerr := iter.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		return err
+	}
+
+	for _, segment := range segments {
+		removed, err := bs.segments.remove(segment)
+		if err != nil {
+			return fmt.Errorf("removing segment %d: %w", segment, err)
+		}
+		if removed {
+			if err := bs.db.Delete(blockSegmentKey(segment)); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
 // pruneRange is a generic function for deleting a range of values based on the lowest
 // height up to but excluding retainHeight. For each key/value pair, an optional hook can be
 // executed before the deletion itself is made. pruneRange will use batch delete to delete
@@ -447,10 +675,25 @@ func (bs *BlockStore) SaveBlock(block *types.Block, blockParts *types.PartSet, s
 	// typically load the block meta first as an indication that the block exists
 	// and then go on to load block parts - we must make sure the block is
 	// complete as soon as the block meta is written.
+	if bs.segments != nil {
+		segment, err := bs.segments.beginBlock()
+		if err != nil {
+			panic(err)
+		}
+		if err := batch.Set(blockSegmentKey(segment), []byte(fmt.Sprintf("%d", height))); err != nil {
+			panic(err)
+		}
+	}
 	for i := 0; i < int(blockParts.Total()); i++ {
 		part := blockParts.GetPart(i)
 		bs.saveBlockPart(height, i, part, batch)
 	}
+	if bs.segments != nil {
+		// the parts must be durable before their locations are written
+		if err := bs.segments.sync(); err != nil {
+			panic(err)
+		}
+	}
 
 	blockMeta := types.NewBlockMeta(block, blockParts)
 	pbm := blockMeta.ToProto()
@@ -468,14 +711,14 @@ func (bs *BlockStore) SaveBlock(block *types.Block, blockParts *types.PartSet, s
 	}
 
 	pbc := block.LastCommit.ToProto()
-	blockCommitBytes := mustEncode(pbc)
+	blockCommitBytes := bs.encode(recordCommit, mustEncode(pbc))
 	if err := batch.Set(blockCommitKey(height-1), blockCommitBytes); err != nil {
 		panic(err)
 	}
 
 	// Save seen commit (seen +2/3 precommits for block)
 	pbsc := seenCommit.ToProto()
-	seenCommitBytes := mustEncode(pbsc)
+	seenCommitBytes := bs.encode(recordSeenCommit, mustEncode(pbsc))
 	if err := batch.Set(seenCommitKey(), seenCommitBytes); err != nil {
 		panic(err)
 	}
@@ -494,7 +737,17 @@ func (bs *BlockStore) saveBlockPart(height int64, index int, part *types.Part, b
 	if err != nil {
 		panic(fmt.Errorf("unable to make part into proto: %w", err))
 	}
-	partBytes := mustEncode(pbp)
+	partBytes := bs.encode(recordBlockPart, mustEncode(pbp))
+	if bs.segments != nil {
+		loc, err := bs.segments.append(partBytes)
+		if err != nil {
+			panic(err)
+		}
+		if err := batch.Set(blockPartLocationKey(height, index), loc.bytes()); err != nil {
+			panic(err)
+		}
+		return
+	}
 	if err := batch.Set(blockPartKey(height, index), partBytes); err != nil {
 		panic(err)
 	}
@@ -507,7 +760,7 @@ func (bs *BlockStore) SaveSeenCommit(height int64, seenCommit *types.Commit) err
 	if err != nil {
 		return fmt.Errorf("unable to marshal commit: %w", err)
 	}
-	return bs.db.Set(seenCommitKey(), seenCommitBytes)
+	return bs.db.Set(seenCommitKey(), bs.encode(recordSeenCommit, seenCommitBytes))
 }
 
 func (bs *BlockStore) SaveSignedHeader(sh *types.SignedHeader, blockID types.BlockID) error {
@@ -540,7 +793,7 @@ func (bs *BlockStore) SaveSignedHeader(sh *types.SignedHeader, blockID types.Blo
 	}
 
 	pbc := sh.Commit.ToProto()
-	blockCommitBytes := mustEncode(pbc)
+	blockCommitBytes := bs.encode(recordCommit, mustEncode(pbc))
 	if err := batch.Set(blockCommitKey(sh.Height), blockCommitBytes); err != nil {
 		return fmt.Errorf("unable to save commit: %w", err)
 	}
@@ -552,6 +805,41 @@ func (bs *BlockStore) SaveSignedHeader(sh *types.SignedHeader, blockID types.Blo
 	return batch.Close()
 }
 
+// record types used to label the compression metrics
+const (
+	recordBlockPart  = "block_part"
+	recordCommit     = "commit"
+	recordSeenCommit = "seen_commit"
+)
+
+// encode compresses a record with the store's codec and records the
+// compression metrics.
+func (bs *BlockStore) encode(record string, bz []byte) []byte {
+	out := compress.Encode(bs.codec, bz)
+	bs.metrics.RawBytes.With("record", record).Add(float64(len(bz)))
+	bs.metrics.StoredBytes.With("record", record).Add(float64(len(out)))
+	if len(bz) > 0 {
+		bs.metrics.CompressionRatio.With("record", record).Observe(float64(len(out)) / float64(len(bz)))
+	}
+	return out
+}
+
+// Recompress rewrites the block parts and commits stored in db with the given
+// codec, batchSize records at a time. It is safe to interrupt and restart, and
+// must not be run while a node is using the database. Block parts stored in
+// segment files are not rewritten.
+func Recompress(ctx context.Context, db dbm.DB, codec compress.Codec, batchSize int) (compress.Stats, error) {
+	var total compress.Stats
+	for _, prefix := range []int64{prefixBlockPart, prefixBlockCommit, prefixSeenCommit} {
+		stats, err := compress.Recompress(ctx, db, prefixKey(prefix), prefixKey(prefix+1), codec, batchSize)
+		total.Add(stats)
+		if err != nil {
+			return total, err
+		}
+	}
+	return total, nil
+}
+
 //---------------------------------- KEY ENCODING -----------------------------------------
 
 // key prefixes
@@ -562,8 +850,19 @@ const (
 	prefixBlockCommit = int64(2)
 	prefixSeenCommit  = int64(3)
 	prefixBlockHash   = int64(4)
+
+	prefixBlockPartLocation = int64(13)
+	prefixBlockSegment      = int64(14)
 )
 
+func prefixKey(prefix int64) []byte {
+	key, err := orderedcode.Append(nil, prefix)
+	if err != nil {
+		panic(err)
+	}
+	return key
+}
+
 func blockMetaKey(height int64) []byte {
 	key, err := orderedcode.Append(nil, prefixBlockMeta, height)
 	if err != nil {
@@ -595,6 +894,34 @@ func blockPartKey(height int64, partIndex int) []byte {
 	return key
 }
 
+func blockPartLocationKey(height int64, partIndex int) []byte {
+	key, err := orderedcode.Append(nil, prefixBlockPartLocation, height, int64(partIndex))
+	if err != nil {
+		panic(err)
+	}
+	return key
+}
+
+func blockSegmentKey(segment uint64) []byte {
+	key, err := orderedcode.Append(nil, prefixBlockSegment, segment)
+	if err != nil {
+		panic(err)
+	}
+	return key
+}
+
+func decodeBlockSegmentKey(key []byte) (segment uint64, err error) {
+	var prefix int64
+	remaining, err := orderedcode.Parse(string(key), &prefix, &segment)
+	if err != nil {
+		return
+	}
+	if len(remaining) != 0 || prefix != prefixBlockSegment {
+		return 0, fmt.Errorf("invalid segment key %X", key)
+	}
+	return
+}
+
 func blockCommitKey(height int64) []byte {
 	key, err := orderedcode.Append(nil, prefixBlockCommit, height)
 	if err != nil {
@@ -1,6 +1,7 @@
 package store
 
 import (
+	"context"
 	"fmt"
 	"os"
 	"runtime/debug"
@@ -14,6 +15,7 @@ import (
 
 	"github.com/tendermint/tendermint/config"
 	"github.com/tendermint/tendermint/crypto"
+	"github.com/tendermint/tendermint/internal/libs/compress"
 	sm "github.com/tendermint/tendermint/internal/state"
 	"github.com/tendermint/tendermint/internal/state/test/factory"
 	"github.com/tendermint/tendermint/libs/log"
@@ -56,12 +58,18 @@ func makeStateAndBlockStore(logger log.Logger) (sm.State, *BlockStore, cleanupFu
 	if err != nil {
 		panic(fmt.Errorf("error constructing state from genesis file: %w", err))
 	}
-	return state, NewBlockStore(blockDB), func() { os.RemoveAll(cfg.RootDir) }
+	return state, newBlockStore(blockDB), func() { os.RemoveAll(cfg.RootDir) }
 }
 
 func freshBlockStore() (*BlockStore, dbm.DB) {
 	db := dbm.NewMemDB()
-	return NewBlockStore(db), db
+	return newBlockStore(db), db
+}
+
+// newBlockStore creates the block stores used by the tests. It is replaced to
+// run the tests against other backends.
+var newBlockStore = func(db dbm.DB) *BlockStore {
+	return NewBlockStore(db)
 }
 
 var (
@@ -302,7 +310,7 @@ func TestLoadBaseMeta(t *testing.T) {
 	defer os.RemoveAll(cfg.RootDir)
 	state, err := sm.MakeGenesisStateFromFile(cfg.GenesisFile())
 	require.NoError(t, err)
-	bs := NewBlockStore(dbm.NewMemDB())
+	bs := newBlockStore(dbm.NewMemDB())
 
 	for h := int64(1); h <= 10; h++ {
 		block := factory.MakeBlock(state, h, new(types.Commit))
@@ -361,7 +369,7 @@ func TestPruneBlocks(t *testing.T) {
 	state, err := sm.MakeGenesisStateFromFile(cfg.GenesisFile())
 	require.NoError(t, err)
 	db := dbm.NewMemDB()
-	bs := NewBlockStore(db)
+	bs := newBlockStore(db)
 	assert.EqualValues(t, 0, bs.Base())
 	assert.EqualValues(t, 0, bs.Height())
 	assert.EqualValues(t, 0, bs.Size())
@@ -433,6 +441,54 @@ func TestPruneBlocks(t *testing.T) {
 	assert.Nil(t, bs.LoadBlock(1501))
 }
 
+func TestDeleteBlocksAfter(t *testing.T) {
+	state, bs, cleanup := makeStateAndBlockStore(log.TestingLogger())
+	defer cleanup()
+
+	_, err := bs.DeleteBlocksAfter(1)
+	require.Error(t, err)
+
+	blocks := make([]*types.Block, 0, 1100)
+	for h := int64(1); h <= 1100; h++ {
+		block := factory.MakeBlock(state, h, makeTestCommit(h-1, tmtime.Now()))
+		bs.SaveBlock(block, block.MakePartSet(2), makeTestCommit(h, tmtime.Now()))
+		blocks = append(blocks, block)
+	}
+	_, err = bs.PruneBlocks(10)
+	require.NoError(t, err)
+
+	_, err = bs.DeleteBlocksAfter(9)
+	require.Error(t, err)
+
+	deleted, err := bs.DeleteBlocksAfter(1100)
+	require.NoError(t, err)
+	assert.EqualValues(t, 0, deleted)
+
+	// more than 1000 blocks, to test batch deletions
+	deleted, err = bs.DeleteBlocksAfter(50)
+	require.NoError(t, err)
+	assert.EqualValues(t, 1050, deleted)
+	assert.EqualValues(t, 10, bs.Base())
+	assert.EqualValues(t, 50, bs.Height())
+
+	for _, block := range blocks[50:] {
+		require.Nil(t, bs.LoadBlockMeta(block.Height))
+		require.Nil(t, bs.LoadBlockByHash(block.Hash()))
+		require.Nil(t, bs.LoadBlockPart(block.Height, 0))
+		require.Nil(t, bs.LoadBlockCommit(block.Height-1))
+	}
+	require.Equal(t, blocks[49].Hash(), bs.LoadBlock(50).Hash())
+
+	// the canonical commit of the new last block became the seen commit
+	require.Equal(t, blocks[50].LastCommit.Hash(), bs.LoadSeenCommit().Hash())
+
+	// blocks can be added again
+	block := factory.MakeBlock(state, 51, bs.LoadSeenCommit())
+	bs.SaveBlock(block, block.MakePartSet(2), makeTestCommit(51, tmtime.Now()))
+	assert.EqualValues(t, 51, bs.Height())
+	require.Equal(t, block.Hash(), bs.LoadBlock(51).Hash())
+}
+
 func TestLoadBlockMeta(t *testing.T) {
 	bs, db := freshBlockStore()
 	height := int64(10)
@@ -561,3 +617,56 @@ func newBlock(hdr types.Header, lastCommit *types.Commit) *types.Block {
 		LastCommit: lastCommit,
 	}
 }
+
+func TestBlockStoreCompression(t *testing.T) {
+	db := dbm.NewMemDB()
+
+	txs := make([]types.Tx, 100)
+	for i := range txs {
+		txs[i] = types.Tx(strings.Repeat(fmt.Sprintf("tx%d", i), 50))
+	}
+	saveBlock := func(bs *BlockStore, height int64) *types.Block {
+		block, _ := state.MakeBlock(height, txs, makeTestCommit(height-1, tmtime.Now()),
+			nil, state.Validators.GetProposer().Address)
+		bs.SaveBlock(block, block.MakePartSet(types.BlockPartSizeBytes), makeTestCommit(height, tmtime.Now()))
+		return block
+	}
+	recordCodec := func(key []byte) compress.Codec {
+		bz, err := db.Get(key)
+		require.NoError(t, err)
+		require.NotEmpty(t, bz)
+		return compress.RecordCodec(bz)
+	}
+
+	// write the first block uncompressed and the second one with snappy
+	blocks := []*types.Block{saveBlock(NewBlockStore(db), 1)}
+	bs := NewBlockStore(db, WithCompression(compress.Snappy))
+	blocks = append(blocks, saveBlock(bs, 2))
+
+	require.Equal(t, compress.None, recordCodec(blockPartKey(1, 0)))
+	require.Equal(t, compress.Snappy, recordCodec(blockPartKey(2, 0)))
+
+	checkBlocks := func() {
+		for _, block := range blocks {
+			loaded := bs.LoadBlock(block.Height)
+			require.NotNil(t, loaded)
+			require.Equal(t, block.Hash(), loaded.Hash())
+		}
+		require.NotNil(t, bs.LoadBlockCommit(1))
+		require.EqualValues(t, 2, bs.LoadSeenCommit().Height)
+	}
+	checkBlocks()
+
+	// recompressing converts the remaining records and keeps them readable
+	stats, err := Recompress(context.Background(), db, compress.Snappy, 1)
+	require.NoError(t, err)
+	require.EqualValues(t, 1, stats.Rewritten)
+	require.Less(t, stats.BytesAfter, stats.BytesBefore)
+	require.Equal(t, compress.Snappy, recordCodec(blockPartKey(1, 0)))
+	checkBlocks()
+
+	_, err = Recompress(context.Background(), db, compress.None, 10)
+	require.NoError(t, err)
+	require.Equal(t, compress.None, recordCodec(blockPartKey(2, 0)))
+	checkBlocks()
+}
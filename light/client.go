@@ -12,6 +12,7 @@ import (
 	tmsync "github.com/tendermint/tendermint/internal/libs/sync"
 	"github.com/tendermint/tendermint/libs/log"
 	tmmath "github.com/tendermint/tendermint/libs/math"
+	tmstrings "github.com/tendermint/tendermint/libs/strings"
 	"github.com/tendermint/tendermint/light/provider"
 	"github.com/tendermint/tendermint/light/store"
 	"github.com/tendermint/tendermint/types"
@@ -54,6 +55,9 @@ const (
 	defaultMaxBlockLag = 10 * time.Second
 
 	defaultProviderTimeout = 10 * time.Second
+
+	// By default pivot light blocks are fetched one after another.
+	defaultFetchConcurrency = 1
 )
 
 // Option sets a parameter for the light client.
@@ -119,6 +123,23 @@ func ProviderTimeout(d time.Duration) Option {
 	return func(c *Client) { c.providerTimeout = d }
 }
 
+// FetchConcurrency option sets how many light blocks skipping verification
+// may request at once. With n > 1, the client speculatively fetches the next
+// n-1 pivot heights it would need should verification of the current pivot
+// fail, from the primary and the witnesses in turn, and cross-checks the
+// target header with the witnesses while verification is still in progress.
+// Pivots fetched from witnesses are confirmed with the primary. Default: 1.
+func FetchConcurrency(n int) Option {
+	return func(c *Client) { c.fetchConcurrency = n }
+}
+
+// Upgrades option configures trusted hard-fork upgrades, which let the light
+// client continue verification when the chain ID changes or the validator set
+// is replaced by a genesis restart. See Upgrade.
+func Upgrades(upgrades ...Upgrade) Option {
+	return func(c *Client) { c.upgrades = append(c.upgrades, upgrades...) }
+}
+
 // Client represents a light client, connected to a single chain, which gets
 // light blocks from a primary provider, verifies them either sequentially or by
 // skipping some and stores them in a trusted store (usually, a local FS).
@@ -132,6 +153,13 @@ type Client struct {
 	maxClockDrift    time.Duration
 	maxBlockLag      time.Duration
 	providerTimeout  time.Duration
+	fetchConcurrency int
+
+	// Trusted hard-fork upgrades. See Upgrades option and AddUpgrade.
+	upgrades []Upgrade
+	// Mutex guarding chainID and upgrades, which change when upgrades are
+	// added or crossed
+	chainMutex tmsync.RWMutex
 
 	// Mutex for locking during changes of the light clients providers
 	providerMutex tmsync.Mutex
@@ -139,6 +167,9 @@ type Client struct {
 	primary provider.Provider
 	// Providers used to "witness" new headers.
 	witnesses []provider.Provider
+	// Incremented whenever the set of witnesses changes, so that in-flight
+	// witness comparisons can tell whether their indexes are still valid.
+	witnessesGen uint64
 
 	// Where trusted light blocks are stored.
 	trustedStore store.Store
@@ -203,6 +234,7 @@ func NewClient(
 		maxClockDrift:    defaultMaxClockDrift,
 		maxBlockLag:      defaultMaxBlockLag,
 		providerTimeout:  defaultProviderTimeout,
+		fetchConcurrency: defaultFetchConcurrency,
 		pruningSize:      defaultPruningSize,
 		logger:           log.NewNopLogger(),
 	}
@@ -216,6 +248,17 @@ func NewClient(
 		return nil, err
 	}
 
+	// Validate fetch concurrency.
+	if c.fetchConcurrency < 1 {
+		return nil, fmt.Errorf("fetch concurrency must be positive, given %d", c.fetchConcurrency)
+	}
+
+	// Validate upgrades.
+	if err := c.validateUpgrades(); err != nil {
+		return nil, err
+	}
+	c.setProviderChainIDs(append([]provider.Provider{c.primary}, c.witnesses...)...)
+
 	// Use the trusted hash and height to fetch the first weakly-trusted block
 	// from the primary provider. Assert that all the witnesses have the same block
 	if err := c.initializeWithTrustOptions(ctx, trustOptions); err != nil {
@@ -242,6 +285,7 @@ func NewClientFromTrustedStore(
 		trustLevel:       DefaultTrustLevel,
 		maxClockDrift:    defaultMaxClockDrift,
 		maxBlockLag:      defaultMaxBlockLag,
+		fetchConcurrency: defaultFetchConcurrency,
 		primary:          primary,
 		witnesses:        witnesses,
 		trustedStore:     trustedStore,
@@ -263,6 +307,17 @@ func NewClientFromTrustedStore(
 		return nil, err
 	}
 
+	// Validate fetch concurrency.
+	if c.fetchConcurrency < 1 {
+		return nil, fmt.Errorf("fetch concurrency must be positive, given %d", c.fetchConcurrency)
+	}
+
+	// Validate upgrades.
+	if err := c.validateUpgrades(); err != nil {
+		return nil, err
+	}
+	c.setProviderChainIDs(append([]provider.Provider{c.primary}, c.witnesses...)...)
+
 	// Check that the trusted store has at least one block and
 	if err := c.restoreTrustedLightBlock(); err != nil {
 		return nil, err
@@ -286,7 +341,12 @@ func (c *Client) restoreTrustedLightBlock() error {
 		return fmt.Errorf("can't get last trusted light block: %w", err)
 	}
 	c.latestTrustedBlock = trustedBlock
-	c.logger.Info("restored trusted light block", "height", lastHeight)
+	if c.chainUpgradesTo(trustedBlock.ChainID) {
+		c.chainMutex.Lock()
+		c.chainID = trustedBlock.ChainID
+		c.chainMutex.Unlock()
+	}
+	c.logger.Info("restored trusted light block", "height", lastHeight, "chainID", trustedBlock.ChainID)
 
 	return nil
 }
@@ -308,7 +368,7 @@ func (c *Client) initializeWithTrustOptions(ctx context.Context, options TrustOp
 
 	// 3) Ensure that +2/3 of validators signed correctly. This also sanity checks that the
 	// chain ID is the same.
-	err = l.ValidatorSet.VerifyCommitLight(c.chainID, l.Commit.BlockID, l.Height, l.Commit)
+	err = l.ValidatorSet.VerifyCommitLight(c.ChainID(), l.Commit.BlockID, l.Height, l.Commit)
 	if err != nil {
 		return fmt.Errorf("invalid commit: %w", err)
 	}
@@ -511,7 +571,7 @@ func (c *Client) verifyLightBlock(ctx context.Context, newLightBlock *types.Ligh
 	switch {
 	// Verifying forwards
 	case newLightBlock.Height >= c.latestTrustedBlock.Height:
-		err = verifyFunc(ctx, c.latestTrustedBlock, newLightBlock, now)
+		err = c.verifyForwards(ctx, verifyFunc, c.latestTrustedBlock, newLightBlock, now)
 
 	// Verifying backwards
 	case newLightBlock.Height < firstBlockHeight:
@@ -520,6 +580,10 @@ func (c *Client) verifyLightBlock(ctx context.Context, newLightBlock *types.Ligh
 		if err != nil {
 			return fmt.Errorf("can't get first light block: %w", err)
 		}
+		if firstBlock.ChainID != newLightBlock.ChainID {
+			return fmt.Errorf("can't verify backwards across an upgrade (from %s to %s)",
+				firstBlock.ChainID, newLightBlock.ChainID)
+		}
 		err = c.backwards(ctx, firstBlock.Header, newLightBlock.Header)
 
 	// Verifying between first and last trusted light block. In this situation
@@ -531,7 +595,7 @@ func (c *Client) verifyLightBlock(ctx context.Context, newLightBlock *types.Ligh
 		if err != nil {
 			return fmt.Errorf("can't get signed header before height %d: %w", newLightBlock.Height, err)
 		}
-		err = verifyFunc(ctx, closestBlock, newLightBlock, now)
+		err = c.verifyForwards(ctx, verifyFunc, closestBlock, newLightBlock, now)
 	}
 	if err != nil {
 		c.logger.Error("failed to verify", "err", err)
@@ -542,6 +606,205 @@ func (c *Client) verifyLightBlock(ctx context.Context, newLightBlock *types.Ligh
 	return c.updateTrustedLightBlock(newLightBlock)
 }
 
+// verifyForwards verifies newLightBlock against trustedBlock using verifyFunc.
+// If newLightBlock belongs to a different chain, the upgrades from the chain of
+// trustedBlock are crossed first and verification continues from the first
+// block of the upgraded chain.
+func (c *Client) verifyForwards(
+	ctx context.Context,
+	verifyFunc func(ctx context.Context, trusted *types.LightBlock, new *types.LightBlock, now time.Time) error,
+	trustedBlock *types.LightBlock,
+	newLightBlock *types.LightBlock,
+	now time.Time) error {
+
+	if trustedBlock.ChainID == newLightBlock.ChainID {
+		return verifyFunc(ctx, trustedBlock, newLightBlock, now)
+	}
+
+	initialBlock, err := c.crossUpgrade(ctx, trustedBlock, now)
+	if err != nil {
+		return ErrVerificationFailed{From: trustedBlock.Height, To: newLightBlock.Height, Reason: err}
+	}
+
+	if initialBlock.Height == newLightBlock.Height {
+		if !bytes.Equal(initialBlock.Hash(), newLightBlock.Hash()) {
+			return fmt.Errorf("first block of the upgraded chain %X does not match new light block %X",
+				initialBlock.Hash(), newLightBlock.Hash())
+		}
+		return nil
+	}
+
+	return c.verifyForwards(ctx, verifyFunc, initialBlock, newLightBlock, now)
+}
+
+// crossUpgrade fetches the first light block of the chain that the chain of
+// trustedBlock was upgraded to, verifies it against the upgrade and the
+// witnesses and saves it to the trusted store.
+func (c *Client) crossUpgrade(
+	ctx context.Context,
+	trustedBlock *types.LightBlock,
+	now time.Time) (*types.LightBlock, error) {
+
+	u, ok := c.upgradeFrom(trustedBlock.ChainID)
+	if !ok {
+		return nil, ErrInvalidHeader{fmt.Errorf("no upgrade is known from chain %s", trustedBlock.ChainID)}
+	}
+	if trustedBlock.Height > u.LastHeight {
+		return nil, fmt.Errorf("trusted block %d is above the last height %d of chain %s",
+			trustedBlock.Height, u.LastHeight, u.OldChainID)
+	}
+
+	// The upgrade may have already been crossed when verifying a later block.
+	if l, err := c.trustedStore.LightBlock(u.InitialHeight); err == nil && l.ChainID == u.NewChainID {
+		return l, nil
+	}
+
+	c.logger.Info("crossing chain upgrade", "oldChainID", u.OldChainID, "lastHeight", u.LastHeight,
+		"newChainID", u.NewChainID, "initialHeight", u.InitialHeight)
+
+	l, err := c.lightBlockFromPrimary(ctx, u.InitialHeight)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := VerifyUpgrade(u, trustedBlock.SignedHeader, l, c.trustingPeriod, now, c.maxClockDrift); err != nil {
+		return nil, err
+	}
+
+	// Cross-verify with witnesses to ensure everybody is on the same upgraded chain.
+	if err := c.compareFirstHeaderWithWitnesses(ctx, l.SignedHeader); err != nil {
+		return nil, err
+	}
+
+	return l, c.updateTrustedLightBlock(l)
+}
+
+// AddUpgrade verifies that the upgrade was signed by +2/3 of the validator set
+// of the old chain at u.LastHeight and adds it to the client's upgrades. The
+// light block at u.LastHeight is verified first if it is not trusted yet.
+//
+// Upgrades supplied by the operator can be added with the Upgrades option
+// instead, in which case no signatures are required.
+func (c *Client) AddUpgrade(ctx context.Context, u Upgrade, now time.Time) error {
+	if err := u.ValidateBasic(); err != nil {
+		return fmt.Errorf("invalid upgrade: %w", err)
+	}
+	if len(u.Signatures) == 0 {
+		return errors.New("upgrade is not signed")
+	}
+	if existing, ok := c.upgradeFrom(u.OldChainID); ok {
+		if bytes.Equal(existing.SignBytes(), u.SignBytes()) {
+			return nil
+		}
+		return fmt.Errorf("a different upgrade from chain %s is already known", u.OldChainID)
+	}
+
+	l, err := c.VerifyLightBlockAtHeight(ctx, u.LastHeight, now)
+	if err != nil {
+		return fmt.Errorf("can't verify last block of chain %s: %w", u.OldChainID, err)
+	}
+	if l.ChainID != u.OldChainID {
+		return fmt.Errorf("light block at height %d belongs to chain %s, not %s",
+			u.LastHeight, l.ChainID, u.OldChainID)
+	}
+
+	if err := u.VerifySignatures(l.ValidatorSet); err != nil {
+		return fmt.Errorf("invalid upgrade signatures: %w", err)
+	}
+
+	c.chainMutex.Lock()
+	if existing, ok := c.findUpgrade(u.OldChainID); ok {
+		c.chainMutex.Unlock()
+		if bytes.Equal(existing.SignBytes(), u.SignBytes()) {
+			return nil
+		}
+		return fmt.Errorf("a different upgrade from chain %s is already known", u.OldChainID)
+	}
+	c.upgrades = append(c.upgrades, u)
+	c.chainMutex.Unlock()
+
+	c.providerMutex.Lock()
+	c.setProviderChainIDs(append([]provider.Provider{c.primary}, c.witnesses...)...)
+	c.providerMutex.Unlock()
+
+	c.logger.Info("added signed upgrade", "oldChainID", u.OldChainID, "newChainID", u.NewChainID,
+		"initialHeight", u.InitialHeight)
+	return nil
+}
+
+// validateUpgrades checks the upgrades given through the Upgrades option.
+func (c *Client) validateUpgrades() error {
+	seen := make(map[string]struct{}, len(c.upgrades))
+	for i, u := range c.upgrades {
+		if err := u.ValidateBasic(); err != nil {
+			return fmt.Errorf("invalid upgrade #%d: %w", i, err)
+		}
+		if _, ok := seen[u.OldChainID]; ok {
+			return fmt.Errorf("more than one upgrade from chain %s", u.OldChainID)
+		}
+		seen[u.OldChainID] = struct{}{}
+	}
+	return nil
+}
+
+// upgradeFrom returns the upgrade from the given chain, if any.
+func (c *Client) upgradeFrom(chainID string) (Upgrade, bool) {
+	c.chainMutex.RLock()
+	defer c.chainMutex.RUnlock()
+	return c.findUpgrade(chainID)
+}
+
+// findUpgrade is upgradeFrom without locking. The caller must hold chainMutex.
+func (c *Client) findUpgrade(chainID string) (Upgrade, bool) {
+	for _, u := range c.upgrades {
+		if u.OldChainID == chainID {
+			return u, true
+		}
+	}
+	return Upgrade{}, false
+}
+
+// setProviderChainIDs sets the chain IDs of the configured chain and of the
+// upgrades on the providers which check them (see provider.ChainIDSetter).
+func (c *Client) setProviderChainIDs(providers ...provider.Provider) {
+	c.chainMutex.RLock()
+	chainIDs := []string{c.chainID}
+	for _, u := range c.upgrades {
+		for _, id := range []string{u.OldChainID, u.NewChainID} {
+			if !tmstrings.StringInSlice(id, chainIDs) {
+				chainIDs = append(chainIDs, id)
+			}
+		}
+	}
+	c.chainMutex.RUnlock()
+
+	for _, p := range providers {
+		if s, ok := p.(provider.ChainIDSetter); ok {
+			s.SetChainIDs(chainIDs)
+		}
+	}
+}
+
+// chainUpgradesTo returns true if the configured chain is upgraded, possibly
+// over several upgrades, to the given chain.
+func (c *Client) chainUpgradesTo(chainID string) bool {
+	c.chainMutex.RLock()
+	defer c.chainMutex.RUnlock()
+
+	current := c.chainID
+	for i := 0; i < len(c.upgrades); i++ {
+		u, ok := c.findUpgrade(current)
+		if !ok {
+			return false
+		}
+		if u.NewChainID == chainID {
+			return true
+		}
+		current = u.NewChainID
+	}
+	return false
+}
+
 // see VerifyHeader
 func (c *Client) verifySequential(
 	ctx context.Context,
@@ -643,6 +906,49 @@ func (c *Client) verifySkipping(
 	newLightBlock *types.LightBlock,
 	now time.Time) ([]*types.LightBlock, error) {
 
+	fetcher := newPivotFetcher(ctx, c, source)
+	defer fetcher.stop()
+
+	return c.verifySkippingWith(fetcher, trustedBlock, newLightBlock, now)
+}
+
+// verifySkippingFromPrimary is verifySkipping with the primary as the source.
+// With concurrent fetching, the speculative pivots are spread across the
+// primary and the witnesses. Pivots taken from a witness must match the
+// primary's light blocks at their heights. If they don't, or verification
+// fails with such a pivot in the trace, verification is repeated with pivots
+// from the primary only, so that a faulty witness can't get the primary
+// replaced.
+func (c *Client) verifySkippingFromPrimary(
+	ctx context.Context,
+	trustedBlock *types.LightBlock,
+	newLightBlock *types.LightBlock,
+	now time.Time) ([]*types.LightBlock, error) {
+
+	if c.fetchConcurrency > 1 {
+		c.providerMutex.Lock()
+		witnesses := append([]provider.Provider(nil), c.witnesses...)
+		c.providerMutex.Unlock()
+
+		fetcher := newPivotFetcher(ctx, c, c.primary, witnesses...)
+		trace, err := c.verifySkippingWith(fetcher, trustedBlock, newLightBlock, now)
+		fetcher.stop()
+		if !errors.Is(err, errUnconfirmedPivot) {
+			return trace, err
+		}
+		c.logger.Info("verifying with pivots from the primary only", "err", err)
+	}
+
+	return c.verifySkipping(ctx, c.primary, trustedBlock, newLightBlock, now)
+}
+
+// verifySkippingWith is verifySkipping with the pivots fetched by fetcher.
+func (c *Client) verifySkippingWith(
+	fetcher *pivotFetcher,
+	trustedBlock *types.LightBlock,
+	newLightBlock *types.LightBlock,
+	now time.Time) ([]*types.LightBlock, error) {
+
 	var (
 		// The block cache is ordered in height from highest to lowest. We start
 		// with the newLightBlock and for any height requested in between we add
@@ -671,6 +977,9 @@ func (c *Client) verifySkipping(
 			// can return a success along with the trace of intermediate headers
 			if depth == 0 {
 				trace = append(trace, newLightBlock)
+				if err := fetcher.confirm(trace); err != nil {
+					return nil, err
+				}
 				return trace, nil
 			}
 			// If not, update the lower bound to the previous upper bound
@@ -693,11 +1002,11 @@ func (c *Client) verifySkipping(
 			// previously verified one in the hope that it has a better chance
 			// of having a similar validator set
 			if depth == len(blockCache)-1 {
-				// schedule what the next height we need to fetch is
+				// schedule what the next height we need to fetch is and,
+				// if allowed, the ones after it should this one fail too
 				pivotHeight := c.schedule(verifiedBlock.Height, blockCache[depth].Height)
-				subCtx, cancel := context.WithTimeout(ctx, c.providerTimeout)
-				defer cancel()
-				interimBlock, providerErr := c.getLightBlock(subCtx, source, pivotHeight)
+				fetcher.prefetch(c.speculativePivots(verifiedBlock.Height, pivotHeight)...)
+				interimBlock, providerErr := fetcher.get(pivotHeight)
 				if providerErr != nil {
 					return nil, ErrVerificationFailed{From: verifiedBlock.Height, To: pivotHeight, Reason: providerErr}
 				}
@@ -707,6 +1016,9 @@ func (c *Client) verifySkipping(
 
 		// for any verification error we abort the operation and return the error
 		default:
+			if fetcher.fromWitness(append(trace, blockCache[depth])...) {
+				return nil, fmt.Errorf("%w: verification failed: %v", errUnconfirmedPivot, err)
+			}
 			return nil, ErrVerificationFailed{From: verifiedBlock.Height, To: blockCache[depth].Height, Reason: err}
 		}
 	}
@@ -726,14 +1038,22 @@ func (c *Client) verifySkippingAgainstPrimary(
 	newLightBlock *types.LightBlock,
 	now time.Time) error {
 
-	trace, err := c.verifySkipping(ctx, c.primary, trustedBlock, newLightBlock, now)
+	// With concurrent fetching, the witnesses are asked for the new header
+	// while the primary's trace is still being verified.
+	var cmp *witnessComparison
+	if c.fetchConcurrency > 1 {
+		cmp = c.startWitnessComparison(ctx, newLightBlock.SignedHeader)
+		defer cmp.stop()
+	}
+
+	trace, err := c.verifySkippingFromPrimary(ctx, trustedBlock, newLightBlock, now)
 	if err == nil {
 		// Success! Now compare the header with the witnesses to ensure it's not a fork.
 		// More witnesses we have, more chance to notice one.
 		//
 		// CORRECTNESS ASSUMPTION: there's at least 1 correct full node
 		// (primary or one of the witnesses).
-		if cmpErr := c.detectDivergence(ctx, trace, now); cmpErr != nil {
+		if cmpErr := c.detectDivergenceWithComparison(ctx, trace, cmp, now); cmpErr != nil {
 			return cmpErr
 		}
 	}
@@ -810,10 +1130,14 @@ func (c *Client) FirstTrustedHeight() (int64, error) {
 	return c.trustedStore.FirstLightBlockHeight()
 }
 
-// ChainID returns the chain ID the light client was configured with.
+// ChainID returns the chain ID of the latest trusted light block. It is the
+// chain ID the light client was configured with unless an upgrade has been
+// crossed since.
 //
 // Safe for concurrent use by multiple goroutines.
 func (c *Client) ChainID() string {
+	c.chainMutex.RLock()
+	defer c.chainMutex.RUnlock()
 	return c.chainID
 }
 
@@ -839,9 +1163,29 @@ func (c *Client) Witnesses() []provider.Provider {
 //
 // NOTE: The light client does not check for uniqueness
 func (c *Client) AddProvider(p provider.Provider) {
+	c.setProviderChainIDs(p)
+
 	c.providerMutex.Lock()
 	defer c.providerMutex.Unlock()
 	c.witnesses = append(c.witnesses, p)
+	c.witnessesGen++
+}
+
+// RemoveProvider removes a provider from the light client's witnesses, e.g.
+// when it is known to be gone. The primary is replaced by a witness once it
+// stops responding.
+//
+// Safe for concurrent use by multiple goroutines.
+func (c *Client) RemoveProvider(p provider.Provider) {
+	c.providerMutex.Lock()
+	defer c.providerMutex.Unlock()
+	for i, w := range c.witnesses {
+		if w == p {
+			c.witnesses = append(c.witnesses[:i:i], c.witnesses[i+1:]...)
+			c.witnessesGen++
+			return
+		}
+	}
 }
 
 // Cleanup removes all the data (headers and validator sets) stored. Note: the
@@ -867,6 +1211,9 @@ func (c *Client) updateTrustedLightBlock(l *types.LightBlock) error {
 
 	if c.latestTrustedBlock == nil || l.Height > c.latestTrustedBlock.Height {
 		c.latestTrustedBlock = l
+		c.chainMutex.Lock()
+		c.chainID = l.ChainID
+		c.chainMutex.Unlock()
 	}
 
 	return nil
@@ -985,6 +1332,9 @@ func (c *Client) removeWitnesses(indexes []int) error {
 		c.witnesses[indexes[i]] = c.witnesses[len(c.witnesses)-1]
 		c.witnesses = c.witnesses[:len(c.witnesses)-1]
 	}
+	if len(indexes) > 0 {
+		c.witnessesGen++
+	}
 
 	return nil
 }
@@ -1040,6 +1390,7 @@ func (c *Client) findNewPrimary(ctx context.Context, height int64, remove bool)
 			// if we are not intending on removing the primary then append the old primary to the end of the witness slice
 			if !remove {
 				c.witnesses = append(c.witnesses, c.primary)
+				c.witnessesGen++
 			}
 
 			// promote respondent as the new primary
@@ -20,18 +20,30 @@ import (
 // or the amount of iterations use the flag -benchtime t -> i.e. -benchtime 5m
 // or -benchtime 100x.
 //
-// Remember that none of these benchmarks account for network latency.
+// Remember that, unless stated otherwise, none of these benchmarks account for
+// network latency.
 var ()
 
+// benchmarkLatency is the round trip time simulated by the benchmarks that
+// account for network latency.
+const benchmarkLatency = 5 * time.Millisecond
+
 type providerBenchmarkImpl struct {
 	currentHeight int64
 	blocks        map[int64]*types.LightBlock
+	latency       time.Duration
 }
 
 func newProviderBenchmarkImpl(headers map[int64]*types.SignedHeader,
 	vals map[int64]*types.ValidatorSet) provider.Provider {
+	return newProviderBenchmarkImplWithLatency(headers, vals, 0)
+}
+
+func newProviderBenchmarkImplWithLatency(headers map[int64]*types.SignedHeader,
+	vals map[int64]*types.ValidatorSet, latency time.Duration) provider.Provider {
 	impl := providerBenchmarkImpl{
-		blocks: make(map[int64]*types.LightBlock, len(headers)),
+		blocks:  make(map[int64]*types.LightBlock, len(headers)),
+		latency: latency,
 	}
 	for height, header := range headers {
 		if height > impl.currentHeight {
@@ -46,6 +58,13 @@ func newProviderBenchmarkImpl(headers map[int64]*types.SignedHeader,
 }
 
 func (impl *providerBenchmarkImpl) LightBlock(ctx context.Context, height int64) (*types.LightBlock, error) {
+	if impl.latency > 0 {
+		select {
+		case <-time.After(impl.latency):
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
 	if height == 0 {
 		return impl.blocks[impl.currentHeight], nil
 	}
@@ -123,6 +142,51 @@ func BenchmarkBisection(b *testing.B) {
 	}
 }
 
+func BenchmarkBisectionWithLatency(b *testing.B) {
+	benchmarkBisectionWithLatency(b, 1)
+}
+
+func BenchmarkBisectionWithLatencyConcurrentFetching(b *testing.B) {
+	benchmarkBisectionWithLatency(b, 4)
+}
+
+func benchmarkBisectionWithLatency(b *testing.B, fetchConcurrency int) {
+	headers, vals, _ := genLightBlocksWithKeys(chainID, 1000, 100, 1, bTime)
+	primary := newProviderBenchmarkImplWithLatency(headers, vals, benchmarkLatency)
+	witness := newProviderBenchmarkImplWithLatency(headers, vals, benchmarkLatency)
+	genesisBlock, _ := primary.LightBlock(context.Background(), 1)
+
+	b.ResetTimer()
+
+	for n := 0; n < b.N; n++ {
+		// start from scratch each time so that the bisection is repeated
+		b.StopTimer()
+		c, err := light.NewClient(
+			context.Background(),
+			chainID,
+			light.TrustOptions{
+				Period: 24 * time.Hour,
+				Height: 1,
+				Hash:   genesisBlock.Hash(),
+			},
+			primary,
+			[]provider.Provider{witness},
+			dbs.New(dbm.NewMemDB()),
+			light.Logger(log.TestingLogger()),
+			light.FetchConcurrency(fetchConcurrency),
+		)
+		if err != nil {
+			b.Fatal(err)
+		}
+		b.StartTimer()
+
+		_, err = c.VerifyLightBlockAtHeight(context.Background(), 1000, bTime.Add(1000*time.Minute))
+		if err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
 func BenchmarkBackwards(b *testing.B) {
 	headers, vals, _ := genLightBlocksWithKeys(chainID, 1000, 100, 1, bTime)
 	benchmarkFullNode := newProviderBenchmarkImpl(headers, vals)
@@ -405,6 +405,77 @@ func TestClientLargeBisectionVerification(t *testing.T) {
 	mockNode.AssertExpectations(t)
 }
 
+func TestClient_ConcurrentFetching(t *testing.T) {
+	numBlocks := int64(300)
+	mockHeaders, mockVals, _ := genLightBlocksWithKeys(chainID, numBlocks, 101, 2, bTime)
+	mockNode := mockNodeFromHeadersAndVals(mockHeaders, mockVals)
+	mockWitness := mockNodeFromHeadersAndVals(mockHeaders, mockVals)
+
+	for _, fetchConcurrency := range []int{1, 2, 8} {
+		fetchConcurrency := fetchConcurrency
+		t.Run(fmt.Sprintf("concurrency=%d", fetchConcurrency), func(t *testing.T) {
+			c, err := light.NewClient(
+				ctx,
+				chainID,
+				light.TrustOptions{
+					Period: 24 * time.Hour,
+					Height: 1,
+					Hash:   mockHeaders[1].Hash(),
+				},
+				mockNode,
+				[]provider.Provider{mockWitness},
+				dbs.New(dbm.NewMemDB()),
+				light.FetchConcurrency(fetchConcurrency),
+			)
+			require.NoError(t, err)
+
+			l, err := c.VerifyLightBlockAtHeight(ctx, numBlocks, bTime.Add(300*time.Minute))
+			require.NoError(t, err)
+			assert.Equal(t, mockHeaders[numBlocks].Hash(), l.Hash())
+
+			height, err := c.LastTrustedHeight()
+			require.NoError(t, err)
+			assert.Equal(t, numBlocks, height)
+		})
+	}
+
+	_, err := light.NewClient(ctx, chainID, trustOptions, mockNode, []provider.Provider{mockWitness},
+		dbs.New(dbm.NewMemDB()), light.FetchConcurrency(0))
+	assert.Error(t, err)
+}
+
+func TestClient_ConcurrentFetching_FaultyWitness(t *testing.T) {
+	numBlocks := int64(300)
+	mockHeaders, mockVals, _ := genLightBlocksWithKeys(chainID, numBlocks, 101, 2, bTime)
+	mockNode := mockNodeFromHeadersAndVals(mockHeaders, mockVals)
+
+	// the witness serves the same headers, but the validator sets of another
+	// chain for the pivots, which fail verification
+	_, forkVals, _ := genLightBlocksWithKeys(chainID, numBlocks, 101, 2, bTime)
+	forkVals[1], forkVals[numBlocks] = mockVals[1], mockVals[numBlocks]
+	faultyWitness := mockNodeFromHeadersAndVals(mockHeaders, forkVals)
+
+	c, err := light.NewClient(
+		ctx,
+		chainID,
+		light.TrustOptions{
+			Period: 24 * time.Hour,
+			Height: 1,
+			Hash:   mockHeaders[1].Hash(),
+		},
+		mockNode,
+		[]provider.Provider{faultyWitness},
+		dbs.New(dbm.NewMemDB()),
+		light.FetchConcurrency(8),
+	)
+	require.NoError(t, err)
+
+	l, err := c.VerifyLightBlockAtHeight(ctx, numBlocks, bTime.Add(300*time.Minute))
+	require.NoError(t, err)
+	assert.Equal(t, mockHeaders[numBlocks].Hash(), l.Hash())
+	assert.Equal(t, mockNode, c.Primary())
+}
+
 func TestClientBisectionBetweenTrustedHeaders(t *testing.T) {
 	mockFullNode := mockNodeFromHeadersAndVals(headerSet, valSet)
 	c, err := light.NewClient(
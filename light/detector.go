@@ -26,6 +26,57 @@ import (
 // If there are no conflictinge headers, the light client deems the verified target header
 // trusted and saves it to the trusted store.
 func (c *Client) detectDivergence(ctx context.Context, primaryTrace []*types.LightBlock, now time.Time) error {
+	return c.detectDivergenceWithComparison(ctx, primaryTrace, nil, now)
+}
+
+// witnessComparison is a comparison of a header with all witnesses that was
+// started ahead of time, i.e. before the header was verified.
+type witnessComparison struct {
+	header       *types.SignedHeader
+	witnessesGen uint64
+	errc         chan error
+	cancel       context.CancelFunc
+}
+
+// startWitnessComparison launches one goroutine per witness to retrieve the
+// light block at the height of h and compare it with h. The results are
+// consumed by detectDivergenceWithComparison.
+func (c *Client) startWitnessComparison(ctx context.Context, h *types.SignedHeader) *witnessComparison {
+	c.providerMutex.Lock()
+	defer c.providerMutex.Unlock()
+
+	return c.compareWithWitnesses(ctx, h)
+}
+
+// stop cancels the requests to the witnesses that are still outstanding.
+func (cmp *witnessComparison) stop() {
+	cmp.cancel()
+}
+
+// NOTE: requires a providerMutex lock
+func (c *Client) compareWithWitnesses(ctx context.Context, h *types.SignedHeader) *witnessComparison {
+	ctx, cancel := context.WithCancel(ctx)
+	cmp := &witnessComparison{
+		header:       h,
+		witnessesGen: c.witnessesGen,
+		errc:         make(chan error, len(c.witnesses)),
+		cancel:       cancel,
+	}
+	for i, witness := range c.witnesses {
+		go c.compareNewHeaderWithWitness(ctx, cmp.errc, h, witness, i)
+	}
+	return cmp
+}
+
+// detectDivergenceWithComparison is detectDivergence that uses the results of
+// cmp, if it was started for the end of the trace and the witnesses have not
+// changed since. Otherwise, the witnesses are queried anew.
+func (c *Client) detectDivergenceWithComparison(
+	ctx context.Context,
+	primaryTrace []*types.LightBlock,
+	cmp *witnessComparison,
+	now time.Time,
+) error {
 	if primaryTrace == nil || len(primaryTrace) < 2 {
 		return errors.New("nil or single block primary trace")
 	}
@@ -44,12 +95,15 @@ func (c *Client) detectDivergence(ctx context.Context, primaryTrace []*types.Lig
 		return ErrNoWitnesses
 	}
 
-	// launch one goroutine per witness to retrieve the light block of the target height
-	// and compare it with the header from the primary
-	errc := make(chan error, len(c.witnesses))
-	for i, witness := range c.witnesses {
-		go c.compareNewHeaderWithWitness(ctx, errc, lastVerifiedHeader, witness, i)
+	// unless they were already launched, launch one goroutine per witness to
+	// retrieve the light block of the target height and compare it with the
+	// header from the primary
+	if cmp == nil || cmp.witnessesGen != c.witnessesGen ||
+		!bytes.Equal(cmp.header.Hash(), lastVerifiedHeader.Hash()) {
+		cmp = c.compareWithWitnesses(ctx, lastVerifiedHeader)
+		defer cmp.stop()
 	}
+	errc := cmp.errc
 
 	// handle errors from the header comparisons as they come in
 	for i := 0; i < cap(errc); i++ {
@@ -0,0 +1,217 @@
+package light
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/tendermint/tendermint/light/provider"
+	"github.com/tendermint/tendermint/types"
+)
+
+// errUnconfirmedPivot is returned by verifySkippingWith when a pivot fetched
+// from a witness was not confirmed by the source, or verification failed with
+// such a pivot in the trace.
+var errUnconfirmedPivot = errors.New("pivot fetched from a witness is not confirmed by the source")
+
+// pivotFetcher fetches light blocks from a source provider during skipping
+// verification. Besides the pivot that is needed right away, it can fetch the
+// pivots that will be needed if verification keeps failing, so that several
+// round trips overlap. These speculative fetches are spread across the source
+// and the given witnesses. Once a pivot fetched from a witness is used, the
+// source's light block at its height is fetched in the background, so that
+// confirm can check that the trace matches the source's. Light blocks that
+// were fetched, but not yet verified, are cached until verification asks for
+// them.
+type pivotFetcher struct {
+	client    *Client
+	source    provider.Provider
+	providers []provider.Provider // the source, followed by the witnesses
+
+	ctx    context.Context
+	cancel context.CancelFunc
+	sem    chan struct{}
+
+	mtx     sync.Mutex
+	fetches map[int64]*pivotFetch
+	next    int // index of the provider of the next speculative fetch
+}
+
+// pivotFetch is a single, possibly still running, light block request.
+type pivotFetch struct {
+	done    chan struct{}
+	lb      *types.LightBlock
+	err     error
+	witness bool        // fetched from a witness rather than the source
+	confirm *pivotFetch // the source's light block, once a witness's is used
+}
+
+func newPivotFetcher(
+	ctx context.Context,
+	c *Client,
+	source provider.Provider,
+	witnesses ...provider.Provider,
+) *pivotFetcher {
+	ctx, cancel := context.WithCancel(ctx)
+	return &pivotFetcher{
+		client:    c,
+		source:    source,
+		providers: append([]provider.Provider{source}, witnesses...),
+		ctx:       ctx,
+		cancel:    cancel,
+		sem:       make(chan struct{}, c.fetchConcurrency),
+		fetches:   make(map[int64]*pivotFetch),
+	}
+}
+
+// prefetch starts fetching the light blocks at the given heights in the
+// background, taking turns between the source and the witnesses. Heights that
+// are already cached or being fetched are skipped.
+func (f *pivotFetcher) prefetch(heights ...int64) {
+	f.mtx.Lock()
+	defer f.mtx.Unlock()
+	for _, height := range heights {
+		if _, ok := f.fetches[height]; ok {
+			continue
+		}
+		f.fetches[height] = f.start(f.providers[f.next%len(f.providers)], height)
+		f.next++
+	}
+}
+
+// get returns the light block at the given height, waiting for it to be
+// fetched if needed. Failed requests are not cached, so that a later call
+// retries them, and failed requests to a witness are retried with the source
+// right away. If the light block was fetched from a witness, the source's is
+// fetched to confirm it.
+func (f *pivotFetcher) get(height int64) (*types.LightBlock, error) {
+	f.mtx.Lock()
+	defer f.mtx.Unlock()
+
+	for {
+		fetch, ok := f.fetches[height]
+		if !ok {
+			fetch = f.start(f.source, height)
+			f.fetches[height] = fetch
+		}
+
+		f.mtx.Unlock()
+		<-fetch.done
+		f.mtx.Lock()
+
+		if fetch.err == nil {
+			if fetch.witness && fetch.confirm == nil {
+				fetch.confirm = f.start(f.source, height)
+			}
+			return fetch.lb, nil
+		}
+
+		if f.fetches[height] == fetch {
+			delete(f.fetches, height)
+		}
+		if !fetch.witness {
+			return nil, fetch.err
+		}
+	}
+}
+
+// fromWitness returns true if any of the given light blocks was fetched from a
+// witness.
+func (f *pivotFetcher) fromWitness(lbs ...*types.LightBlock) bool {
+	f.mtx.Lock()
+	defer f.mtx.Unlock()
+
+	for _, lb := range lbs {
+		if fetch := f.lookupLocked(lb); fetch != nil && fetch.witness {
+			return true
+		}
+	}
+	return false
+}
+
+// confirm waits for the source's light blocks at the heights of the light
+// blocks in trace that were fetched from witnesses, and returns an
+// errUnconfirmedPivot if any of them can't be fetched or differs.
+func (f *pivotFetcher) confirm(trace []*types.LightBlock) error {
+	for _, lb := range trace {
+		f.mtx.Lock()
+		fetch := f.lookupLocked(lb)
+		f.mtx.Unlock()
+		if fetch == nil || !fetch.witness {
+			continue
+		}
+
+		<-fetch.confirm.done
+		if err := fetch.confirm.err; err != nil {
+			return fmt.Errorf("%w: height %d: %v", errUnconfirmedPivot, lb.Height, err)
+		}
+		if !bytes.Equal(fetch.confirm.lb.Hash(), lb.Hash()) {
+			return fmt.Errorf("%w: height %d: witness has %X, source has %X",
+				errUnconfirmedPivot, lb.Height, lb.Hash(), fetch.confirm.lb.Hash())
+		}
+	}
+	return nil
+}
+
+// stop cancels all outstanding requests.
+func (f *pivotFetcher) stop() {
+	f.cancel()
+}
+
+// NOTE: requires the fetcher's mtx lock
+func (f *pivotFetcher) lookupLocked(lb *types.LightBlock) *pivotFetch {
+	fetch, ok := f.fetches[lb.Height]
+	if !ok {
+		return nil
+	}
+	select {
+	case <-fetch.done:
+	default:
+		return nil
+	}
+	if fetch.lb != lb {
+		return nil
+	}
+	return fetch
+}
+
+// start fetches the light block at the given height from p in the background.
+func (f *pivotFetcher) start(p provider.Provider, height int64) *pivotFetch {
+	fetch := &pivotFetch{
+		done:    make(chan struct{}),
+		witness: p != f.source,
+	}
+
+	go func() {
+		defer close(fetch.done)
+
+		select {
+		case f.sem <- struct{}{}:
+			defer func() { <-f.sem }()
+		case <-f.ctx.Done():
+			fetch.err = provider.ErrNoResponse
+			return
+		}
+
+		fetch.lb, fetch.err = f.client.getLightBlock(f.ctx, p, height)
+	}()
+
+	return fetch
+}
+
+// speculativePivots returns the pivots that verifySkipping will need next if
+// verification against verifiedHeight keeps failing, starting after pivotHeight.
+// At most fetchConcurrency-1 heights are returned.
+func (c *Client) speculativePivots(verifiedHeight, pivotHeight int64) []int64 {
+	var pivots []int64
+	for i := 1; i < c.fetchConcurrency; i++ {
+		pivotHeight = c.schedule(verifiedHeight, pivotHeight)
+		if pivotHeight <= verifiedHeight {
+			break
+		}
+		pivots = append(pivots, pivotHeight)
+	}
+	return pivots
+}
@@ -9,6 +9,7 @@ import (
 	"strings"
 	"time"
 
+	tmsync "github.com/tendermint/tendermint/internal/libs/sync"
 	"github.com/tendermint/tendermint/light/provider"
 	rpcclient "github.com/tendermint/tendermint/rpc/client"
 	rpchttp "github.com/tendermint/tendermint/rpc/client/http"
@@ -29,6 +30,11 @@ type http struct {
 	chainID string
 	client  rpcclient.RemoteClient
 
+	// Chain IDs, besides chainID, that the remote may serve after hard-fork
+	// upgrades. See SetChainIDs.
+	mtx      tmsync.RWMutex
+	chainIDs []string
+
 	// httt provider heuristics
 
 	// The provider tracks the amount of times that the
@@ -139,7 +145,7 @@ func (p *http) LightBlock(ctx context.Context, height int64) (*types.LightBlock,
 		ValidatorSet: vs,
 	}
 
-	err = lb.ValidateBasic(p.chainID)
+	err = lb.ValidateBasic(p.expectedChainID(lb.ChainID))
 	if err != nil {
 		return nil, provider.ErrBadLightBlock{Reason: err}
 	}
@@ -147,6 +153,28 @@ func (p *http) LightBlock(ctx context.Context, height int64) (*types.LightBlock,
 	return lb, nil
 }
 
+// SetChainIDs implements provider.ChainIDSetter. Light blocks of any of the
+// given chains are accepted, besides those of the provider's chain.
+func (p *http) SetChainIDs(chainIDs []string) {
+	p.mtx.Lock()
+	defer p.mtx.Unlock()
+	p.chainIDs = chainIDs
+}
+
+// expectedChainID returns the chain ID a light block with the given chain ID
+// is validated against: either one of the chain IDs set by the light client or
+// the provider's own chain ID.
+func (p *http) expectedChainID(chainID string) string {
+	p.mtx.RLock()
+	defer p.mtx.RUnlock()
+	for _, id := range p.chainIDs {
+		if id == chainID {
+			return id
+		}
+	}
+	return p.chainID
+}
+
 // ReportEvidence calls `/broadcast_evidence` endpoint.
 func (p *http) ReportEvidence(ctx context.Context, ev types.Evidence) error {
 	_, err := p.client.BroadcastEvidence(ctx, ev)
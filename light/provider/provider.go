@@ -26,3 +26,11 @@ type Provider interface {
 	// ReportEvidence reports an evidence of misbehavior.
 	ReportEvidence(context.Context, types.Evidence) error
 }
+
+// ChainIDSetter is implemented by providers which check the chain ID of the
+// light blocks they return. The light client sets the chain IDs it follows:
+// the one it was configured with and those of its trusted upgrades, so that
+// the provider accepts light blocks from the upgraded chains.
+type ChainIDSetter interface {
+	SetChainIDs(chainIDs []string)
+}
@@ -37,6 +37,7 @@ func RPCRoutes(c *lrpc.Client) map[string]*rpcserver.RPCFunc {
 		"consensus_params":     rpcserver.NewRPCFunc(makeConsensusParamsFunc(c), "height", true),
 		"unconfirmed_txs":      rpcserver.NewRPCFunc(makeUnconfirmedTxsFunc(c), "limit", false),
 		"num_unconfirmed_txs":  rpcserver.NewRPCFunc(makeNumUnconfirmedTxsFunc(c), "", false),
+		"mempool_tx":           rpcserver.NewRPCFunc(makeMempoolTxFunc(c), "hash", false),
 
 		// tx broadcast API
 		"broadcast_tx_commit": rpcserver.NewRPCFunc(makeBroadcastTxCommitFunc(c), "tx", false),
@@ -49,6 +50,10 @@ func RPCRoutes(c *lrpc.Client) map[string]*rpcserver.RPCFunc {
 
 		// evidence API
 		"broadcast_evidence": rpcserver.NewRPCFunc(makeBroadcastEvidenceFunc(c), "evidence", false),
+		"pending_evidence":   rpcserver.NewRPCFunc(makePendingEvidenceFunc(c), "page,per_page", false),
+		"committed_evidence": rpcserver.NewRPCFunc(makeCommittedEvidenceFunc(c), "height,validator,page,per_page", false),
+		"evidence":           rpcserver.NewRPCFunc(makeEvidenceFunc(c), "hash", false),
+		"evidence_search":    rpcserver.NewRPCFunc(makeEvidenceSearchFunc(c), "query,page,per_page,order_by", false),
 	}
 }
 
@@ -230,6 +235,14 @@ func makeNumUnconfirmedTxsFunc(c *lrpc.Client) rpcNumUnconfirmedTxsFunc {
 	}
 }
 
+type rpcMempoolTxFunc func(ctx *rpctypes.Context, hash bytes.HexBytes) (*coretypes.ResultMempoolTx, error)
+
+func makeMempoolTxFunc(c *lrpc.Client) rpcMempoolTxFunc {
+	return func(ctx *rpctypes.Context, hash bytes.HexBytes) (*coretypes.ResultMempoolTx, error) {
+		return c.MempoolTx(ctx.Context(), hash)
+	}
+}
+
 type rpcBroadcastTxCommitFunc func(ctx *rpctypes.Context, tx types.Tx) (*coretypes.ResultBroadcastTxCommit, error)
 
 func makeBroadcastTxCommitFunc(c *lrpc.Client) rpcBroadcastTxCommitFunc {
@@ -284,3 +297,39 @@ func makeBroadcastEvidenceFunc(c *lrpc.Client) rpcBroadcastEvidenceFunc {
 		return c.BroadcastEvidence(ctx.Context(), ev)
 	}
 }
+
+type rpcPendingEvidenceFunc func(ctx *rpctypes.Context, page, perPage *int) (*coretypes.ResultEvidenceList, error)
+
+func makePendingEvidenceFunc(c *lrpc.Client) rpcPendingEvidenceFunc {
+	return func(ctx *rpctypes.Context, page, perPage *int) (*coretypes.ResultEvidenceList, error) {
+		return c.PendingEvidence(ctx.Context(), page, perPage)
+	}
+}
+
+type rpcCommittedEvidenceFunc func(ctx *rpctypes.Context, height *int64, validator bytes.HexBytes,
+	page, perPage *int) (*coretypes.ResultEvidenceList, error)
+
+func makeCommittedEvidenceFunc(c *lrpc.Client) rpcCommittedEvidenceFunc {
+	return func(ctx *rpctypes.Context, height *int64, validator bytes.HexBytes,
+		page, perPage *int) (*coretypes.ResultEvidenceList, error) {
+		return c.CommittedEvidence(ctx.Context(), height, validator, page, perPage)
+	}
+}
+
+type rpcEvidenceFunc func(ctx *rpctypes.Context, hash bytes.HexBytes) (*coretypes.ResultEvidence, error)
+
+func makeEvidenceFunc(c *lrpc.Client) rpcEvidenceFunc {
+	return func(ctx *rpctypes.Context, hash bytes.HexBytes) (*coretypes.ResultEvidence, error) {
+		return c.Evidence(ctx.Context(), hash)
+	}
+}
+
+type rpcEvidenceSearchFunc func(ctx *rpctypes.Context, query string, page, perPage *int,
+	orderBy string) (*coretypes.ResultEvidenceList, error)
+
+func makeEvidenceSearchFunc(c *lrpc.Client) rpcEvidenceSearchFunc {
+	return func(ctx *rpctypes.Context, query string, page, perPage *int,
+		orderBy string) (*coretypes.ResultEvidenceList, error) {
+		return c.EvidenceSearch(ctx.Context(), query, page, perPage, orderBy)
+	}
+}
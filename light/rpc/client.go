@@ -216,6 +216,10 @@ func (c *Client) RemoveTx(ctx context.Context, txKey types.TxKey) error {
 	return c.next.RemoveTx(ctx, txKey)
 }
 
+func (c *Client) MempoolTx(ctx context.Context, hash tmbytes.HexBytes) (*coretypes.ResultMempoolTx, error) {
+	return c.next.MempoolTx(ctx, hash)
+}
+
 func (c *Client) NetInfo(ctx context.Context) (*coretypes.ResultNetInfo, error) {
 	return c.next.NetInfo(ctx)
 }
@@ -533,6 +537,32 @@ func (c *Client) BroadcastEvidence(ctx context.Context, ev types.Evidence) (*cor
 	return c.next.BroadcastEvidence(ctx, ev)
 }
 
+func (c *Client) PendingEvidence(ctx context.Context, page, perPage *int) (*coretypes.ResultEvidenceList, error) {
+	return c.next.PendingEvidence(ctx, page, perPage)
+}
+
+func (c *Client) CommittedEvidence(
+	ctx context.Context,
+	height *int64,
+	validator tmbytes.HexBytes,
+	page, perPage *int,
+) (*coretypes.ResultEvidenceList, error) {
+	return c.next.CommittedEvidence(ctx, height, validator, page, perPage)
+}
+
+func (c *Client) Evidence(ctx context.Context, hash tmbytes.HexBytes) (*coretypes.ResultEvidence, error) {
+	return c.next.Evidence(ctx, hash)
+}
+
+func (c *Client) EvidenceSearch(
+	ctx context.Context,
+	query string,
+	page, perPage *int,
+	orderBy string,
+) (*coretypes.ResultEvidenceList, error) {
+	return c.next.EvidenceSearch(ctx, query, page, perPage, orderBy)
+}
+
 func (c *Client) Subscribe(ctx context.Context, subscriber, query string,
 	outCapacity ...int) (out <-chan coretypes.ResultEvent, err error) {
 	return c.next.Subscribe(ctx, subscriber, query, outCapacity...)
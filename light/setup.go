@@ -4,11 +4,26 @@ import (
 	"context"
 	"time"
 
+	"github.com/tendermint/tendermint/libs/service"
 	"github.com/tendermint/tendermint/light/provider"
 	"github.com/tendermint/tendermint/light/provider/http"
 	"github.com/tendermint/tendermint/light/store"
 )
 
+// Network is a p2p network, such as a light node, that the light client
+// sources its primary and witnesses from.
+type Network interface {
+	service.Service
+
+	// Providers blocks until at least minPeers peers are connected and returns
+	// a light block provider for every connected peer.
+	Providers(ctx context.Context, minPeers int) ([]provider.Provider, error)
+
+	// SetLightClient sets the light client that is backed by the network's
+	// providers. Peers that connect afterwards are added to it as witnesses.
+	SetLightClient(c *Client)
+}
+
 // NewHTTPClient initiates an instance of a light client using HTTP addresses
 // for both the primary provider and witnesses of the light client. A trusted
 // header and hash must be passed to initialize the client.
@@ -0,0 +1,257 @@
+package light
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/tendermint/tendermint/crypto"
+	"github.com/tendermint/tendermint/crypto/tmhash"
+	tmbytes "github.com/tendermint/tendermint/libs/bytes"
+	tmjson "github.com/tendermint/tendermint/libs/json"
+	"github.com/tendermint/tendermint/types"
+)
+
+// Upgrade describes a hard-fork boundary at which a chain stops at
+// LastHeight and continues as NewChainID from InitialHeight with a fresh
+// genesis (e.g. a genesis restart). The light client cannot verify the first
+// block of the new chain from the old one, because the chain ID and usually
+// the validator set change, so an upgrade acts as an additional root of trust.
+//
+// An upgrade is trusted either because it was supplied by the operator (see
+// the Upgrades option) or because it was signed by +2/3 of the old chain's
+// validator set at LastHeight (see Client.AddUpgrade).
+type Upgrade struct {
+	// OldChainID is the chain ID before the upgrade.
+	OldChainID string `json:"old_chain_id"`
+	// LastHeight is the last height committed with OldChainID.
+	LastHeight int64 `json:"last_height,string"`
+
+	// NewChainID is the chain ID after the upgrade.
+	NewChainID string `json:"new_chain_id"`
+	// InitialHeight is the first height of the new chain. It must be greater
+	// than LastHeight so that both segments fit in the same trusted store.
+	InitialHeight int64 `json:"initial_height,string"`
+	// GenesisHash is the hash of the new chain's genesis document. It is not
+	// committed to by headers, but is covered by the upgrade's signatures so
+	// that operators can check it against the genesis of the nodes they use.
+	GenesisHash tmbytes.HexBytes `json:"genesis_hash"`
+	// Validators is the validator set of the new chain at InitialHeight.
+	Validators []*types.Validator `json:"validators"`
+
+	// Signatures by the old chain's validators over SignBytes.
+	Signatures []UpgradeSignature `json:"signatures,omitempty"`
+}
+
+// UpgradeSignature is a signature of a single validator over Upgrade.SignBytes.
+type UpgradeSignature struct {
+	ValidatorAddress types.Address `json:"validator_address"`
+	Signature        []byte        `json:"signature"`
+}
+
+// ValidateBasic performs basic validation.
+func (u Upgrade) ValidateBasic() error {
+	if u.OldChainID == "" {
+		return errors.New("empty old chain ID")
+	}
+	if u.NewChainID == "" {
+		return errors.New("empty new chain ID")
+	}
+	if u.OldChainID == u.NewChainID {
+		return fmt.Errorf("old and new chain IDs are the same (%s)", u.NewChainID)
+	}
+	if u.LastHeight <= 0 {
+		return errors.New("negative or zero last height")
+	}
+	if u.InitialHeight <= u.LastHeight {
+		return fmt.Errorf("initial height %d must be greater than last height %d",
+			u.InitialHeight, u.LastHeight)
+	}
+	if len(u.GenesisHash) != tmhash.Size {
+		return fmt.Errorf("expected genesis hash size to be %d bytes, got %d bytes",
+			tmhash.Size,
+			len(u.GenesisHash),
+		)
+	}
+	if len(u.Validators) == 0 {
+		return errors.New("empty validator set")
+	}
+	addrs := make(map[string]struct{}, len(u.Validators))
+	for i, val := range u.Validators {
+		if err := val.ValidateBasic(); err != nil {
+			return fmt.Errorf("invalid validator #%d: %w", i, err)
+		}
+		if val.VotingPower <= 0 {
+			return fmt.Errorf("validator #%d has non-positive voting power", i)
+		}
+		if !bytes.Equal(val.Address, val.PubKey.Address()) {
+			return fmt.Errorf("validator #%d address does not match its public key", i)
+		}
+		if _, ok := addrs[string(val.Address)]; ok {
+			return fmt.Errorf("duplicate validator %X", val.Address)
+		}
+		addrs[string(val.Address)] = struct{}{}
+	}
+	for i, sig := range u.Signatures {
+		if len(sig.ValidatorAddress) != crypto.AddressSize {
+			return fmt.Errorf("signature #%d: expected validator address size to be %d bytes, got %d bytes",
+				i, crypto.AddressSize, len(sig.ValidatorAddress))
+		}
+		if len(sig.Signature) == 0 {
+			return fmt.Errorf("signature #%d is empty", i)
+		}
+		if len(sig.Signature) > types.MaxSignatureSize {
+			return fmt.Errorf("signature #%d is too big (max: %d)", i, types.MaxSignatureSize)
+		}
+	}
+	return nil
+}
+
+// ValidatorSet returns the validator set of the new chain at InitialHeight.
+func (u Upgrade) ValidatorSet() *types.ValidatorSet {
+	vals := make([]*types.Validator, len(u.Validators))
+	for i, val := range u.Validators {
+		vals[i] = val.Copy()
+	}
+	return types.NewValidatorSet(vals)
+}
+
+// SignBytes returns the bytes the old chain's validators sign to endorse the
+// upgrade.
+func (u Upgrade) SignBytes() []byte {
+	bz, err := tmjson.Marshal(struct {
+		OldChainID     string           `json:"old_chain_id"`
+		LastHeight     int64            `json:"last_height,string"`
+		NewChainID     string           `json:"new_chain_id"`
+		InitialHeight  int64            `json:"initial_height,string"`
+		GenesisHash    tmbytes.HexBytes `json:"genesis_hash"`
+		ValidatorsHash tmbytes.HexBytes `json:"validators_hash"`
+	}{
+		OldChainID:     u.OldChainID,
+		LastHeight:     u.LastHeight,
+		NewChainID:     u.NewChainID,
+		InitialHeight:  u.InitialHeight,
+		GenesisHash:    u.GenesisHash,
+		ValidatorsHash: u.ValidatorSet().Hash(),
+	})
+	if err != nil {
+		panic(err)
+	}
+	return bz
+}
+
+// VerifySignatures checks that validators holding more than 2/3 of the voting
+// power of vals, the old chain's validator set at LastHeight, signed the
+// upgrade.
+func (u Upgrade) VerifySignatures(vals *types.ValidatorSet) error {
+	var (
+		signBytes    = u.SignBytes()
+		talliedPower int64
+		seen         = make(map[string]struct{}, len(u.Signatures))
+	)
+
+	for _, sig := range u.Signatures {
+		_, val := vals.GetByAddress(sig.ValidatorAddress)
+		if val == nil {
+			return fmt.Errorf("signature from unknown validator %X", sig.ValidatorAddress)
+		}
+		if _, ok := seen[string(sig.ValidatorAddress)]; ok {
+			return fmt.Errorf("double signature from validator %X", sig.ValidatorAddress)
+		}
+		seen[string(sig.ValidatorAddress)] = struct{}{}
+
+		if !val.PubKey.VerifySignature(signBytes, sig.Signature) {
+			return fmt.Errorf("wrong signature from validator %X", sig.ValidatorAddress)
+		}
+		talliedPower += val.VotingPower
+	}
+
+	if needed := vals.TotalVotingPower() * 2 / 3; talliedPower <= needed {
+		return types.ErrNotEnoughVotingPowerSigned{Got: talliedPower, Needed: needed}
+	}
+	return nil
+}
+
+// VerifyUpgrade verifies the first light block of the new chain against the
+// upgrade and the last trusted header of the old chain. It ensures that:
+//
+//	a) untrusted is the block at u.InitialHeight of u.NewChainID
+//	b) untrusted is valid and its time is after lastTrusted's time
+//	c) untrusted is within the trusting period and not from the future
+//	d) untrusted's validators are those of the upgrade
+//	e) more than 2/3 of the upgrade's validators have signed untrusted
+//
+// The old header is not required to be within the trusting period, since the
+// upgrade is the root of trust for the new chain.
+func VerifyUpgrade(
+	u Upgrade,
+	lastTrusted *types.SignedHeader, // chainID=u.OldChainID, height<=u.LastHeight
+	untrusted *types.LightBlock, // chainID=u.NewChainID, height=u.InitialHeight
+	trustingPeriod time.Duration,
+	now time.Time,
+	maxClockDrift time.Duration) error {
+
+	if lastTrusted.ChainID != u.OldChainID || lastTrusted.Height > u.LastHeight {
+		return fmt.Errorf("trusted header %s/%d is not part of the chain being upgraded (%s up to %d)",
+			lastTrusted.ChainID, lastTrusted.Height, u.OldChainID, u.LastHeight)
+	}
+
+	if untrusted.Height != u.InitialHeight {
+		return ErrInvalidHeader{fmt.Errorf("expected first header of the new chain at height %d, got %d",
+			u.InitialHeight, untrusted.Height)}
+	}
+
+	if err := untrusted.ValidateBasic(u.NewChainID); err != nil {
+		return ErrInvalidHeader{err}
+	}
+
+	if !untrusted.Time.After(lastTrusted.Time) {
+		return ErrInvalidHeader{fmt.Errorf("expected new chain's header time %v to be after old header time %v",
+			untrusted.Time, lastTrusted.Time)}
+	}
+
+	if HeaderExpired(untrusted.SignedHeader, trustingPeriod, now) {
+		return ErrOldHeaderExpired{untrusted.Time.Add(trustingPeriod), now}
+	}
+
+	if !untrusted.Time.Before(now.Add(maxClockDrift)) {
+		return ErrInvalidHeader{fmt.Errorf("new header has a time from the future %v (now: %v; max clock drift: %v)",
+			untrusted.Time, now, maxClockDrift)}
+	}
+
+	vals := u.ValidatorSet()
+	if !bytes.Equal(untrusted.ValidatorsHash, vals.Hash()) {
+		return ErrInvalidHeader{fmt.Errorf("expected new chain's validators (%X) to match those of the upgrade (%X)",
+			untrusted.ValidatorsHash, vals.Hash())}
+	}
+
+	if err := vals.VerifyCommitLight(u.NewChainID, untrusted.Commit.BlockID,
+		untrusted.Height, untrusted.Commit); err != nil {
+		return ErrInvalidHeader{err}
+	}
+
+	return nil
+}
+
+// LoadUpgradesFile reads a JSON array of upgrades from the given file and
+// validates them.
+func LoadUpgradesFile(path string) ([]Upgrade, error) {
+	bz, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var upgrades []Upgrade
+	if err := tmjson.Unmarshal(bz, &upgrades); err != nil {
+		return nil, fmt.Errorf("can't parse upgrades file %s: %w", path, err)
+	}
+
+	for i, u := range upgrades {
+		if err := u.ValidateBasic(); err != nil {
+			return nil, fmt.Errorf("invalid upgrade #%d: %w", i, err)
+		}
+	}
+	return upgrades, nil
+}
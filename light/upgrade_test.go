@@ -0,0 +1,211 @@
+package light_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	dbm "github.com/tendermint/tm-db"
+
+	"github.com/tendermint/tendermint/libs/log"
+	"github.com/tendermint/tendermint/light"
+	"github.com/tendermint/tendermint/light/provider"
+	dbs "github.com/tendermint/tendermint/light/store/db"
+	"github.com/tendermint/tendermint/types"
+)
+
+const upgradedChainID = "test-2"
+
+var (
+	upgradedKeys = genPrivKeys(4)
+	upgradedVals = upgradedKeys.ToValidators(20, 10)
+	// first block of the upgraded chain
+	u4 = upgradedKeys.GenSignedHeader(upgradedChainID, 4, bTime.Add(90*time.Minute), nil,
+		upgradedVals, upgradedVals, hash("app_hash"), hash("cons_hash"), hash("results_hash"), 0, len(upgradedKeys))
+	u5 = upgradedKeys.GenSignedHeaderLastBlockID(upgradedChainID, 5, bTime.Add(2*time.Hour), nil,
+		upgradedVals, upgradedVals, hash("app_hash"), hash("cons_hash"), hash("results_hash"), 0,
+		len(upgradedKeys), types.BlockID{Hash: u4.Hash()})
+)
+
+func newUpgrade() light.Upgrade {
+	return light.Upgrade{
+		OldChainID:    chainID,
+		LastHeight:    3,
+		NewChainID:    upgradedChainID,
+		InitialHeight: 4,
+		GenesisHash:   hash("genesis"),
+		Validators:    upgradedVals.Validators,
+	}
+}
+
+func TestUpgrade_ValidateBasic(t *testing.T) {
+	testCases := []struct {
+		name     string
+		malleate func(u *light.Upgrade)
+		expErr   bool
+	}{
+		{"valid", func(u *light.Upgrade) {}, false},
+		{"empty old chain ID", func(u *light.Upgrade) { u.OldChainID = "" }, true},
+		{"same chain IDs", func(u *light.Upgrade) { u.NewChainID = u.OldChainID }, true},
+		{"zero last height", func(u *light.Upgrade) { u.LastHeight = 0 }, true},
+		{"initial height not above last height", func(u *light.Upgrade) { u.InitialHeight = 3 }, true},
+		{"bad genesis hash", func(u *light.Upgrade) { u.GenesisHash = []byte("hash") }, true},
+		{"no validators", func(u *light.Upgrade) { u.Validators = nil }, true},
+		{"duplicate validators", func(u *light.Upgrade) {
+			u.Validators = append(u.Validators, u.Validators[0])
+		}, true},
+		{"empty signature", func(u *light.Upgrade) {
+			u.Signatures = []light.UpgradeSignature{{ValidatorAddress: keys[0].PubKey().Address()}}
+		}, true},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			u := newUpgrade()
+			tc.malleate(&u)
+			if tc.expErr {
+				assert.Error(t, u.ValidateBasic())
+			} else {
+				assert.NoError(t, u.ValidateBasic())
+			}
+		})
+	}
+}
+
+func TestUpgrade_VerifySignatures(t *testing.T) {
+	u := newUpgrade()
+	signBytes := u.SignBytes()
+
+	// 2 out of 4 equally weighted validators is not enough
+	for _, key := range keys[:2] {
+		sig, err := key.Sign(signBytes)
+		require.NoError(t, err)
+		u.Signatures = append(u.Signatures, light.UpgradeSignature{
+			ValidatorAddress: key.PubKey().Address(),
+			Signature:        sig,
+		})
+	}
+	equalVals := keys.ToValidators(10, 0)
+	err := u.VerifySignatures(equalVals)
+	assert.IsType(t, types.ErrNotEnoughVotingPowerSigned{}, err)
+
+	sig, err := keys[2].Sign(signBytes)
+	require.NoError(t, err)
+	u.Signatures = append(u.Signatures, light.UpgradeSignature{
+		ValidatorAddress: keys[2].PubKey().Address(),
+		Signature:        sig,
+	})
+	assert.NoError(t, u.VerifySignatures(equalVals))
+
+	// signatures from a different validator set are rejected
+	assert.Error(t, u.VerifySignatures(upgradedVals))
+
+	// signatures are bound to the content of the upgrade
+	u.InitialHeight++
+	assert.Error(t, u.VerifySignatures(equalVals))
+}
+
+func TestClient_CrossesUpgrade(t *testing.T) {
+	headers := map[int64]*types.SignedHeader{1: h1, 2: h2, 3: h3, 4: u4, 5: u5}
+	validators := map[int64]*types.ValidatorSet{1: vals, 2: vals, 3: vals, 4: upgradedVals, 5: upgradedVals}
+
+	testCases := []struct {
+		name    string
+		options []light.Option
+		expErr  bool
+	}{
+		{"no upgrade", nil, true},
+		{"upgrade", []light.Option{light.Upgrades(newUpgrade())}, false},
+		{"upgrade sequential", []light.Option{light.Upgrades(newUpgrade()), light.SequentialVerification()}, false},
+		{"upgrade to other validators", []light.Option{light.Upgrades(func() light.Upgrade {
+			u := newUpgrade()
+			u.Validators = vals.Validators
+			return u
+		}())}, true},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			mockNode := mockNodeFromHeadersAndVals(headers, validators)
+			trustedStore := dbs.New(dbm.NewMemDB())
+			c, err := light.NewClient(
+				ctx,
+				chainID,
+				trustOptions,
+				mockNode,
+				[]provider.Provider{mockNode},
+				trustedStore,
+				append(tc.options, light.Logger(log.TestingLogger()))...,
+			)
+			require.NoError(t, err)
+
+			// the chain ID can be read while verification crosses the upgrade
+			done := make(chan struct{})
+			go func() {
+				defer close(done)
+				for i := 0; i < 100; i++ {
+					_ = c.ChainID()
+				}
+			}()
+			_, err = c.VerifyLightBlockAtHeight(ctx, 5, bTime.Add(3*time.Hour))
+			<-done
+			if tc.expErr {
+				require.Error(t, err)
+				assert.Equal(t, chainID, c.ChainID())
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, upgradedChainID, c.ChainID())
+
+			// both segments are in the trusted store
+			l, err := c.TrustedLightBlock(1)
+			require.NoError(t, err)
+			assert.Equal(t, chainID, l.ChainID)
+			l, err = c.TrustedLightBlock(4)
+			require.NoError(t, err)
+			assert.Equal(t, u4.Hash(), l.Hash())
+
+			// a restarted client continues on the upgraded chain
+			c, err = light.NewClientFromTrustedStore(chainID, trustPeriod, mockNode,
+				[]provider.Provider{mockNode}, trustedStore, tc.options...)
+			require.NoError(t, err)
+			assert.Equal(t, upgradedChainID, c.ChainID())
+		})
+	}
+}
+
+// chainIDsProvider records the chain IDs set by the light client.
+type chainIDsProvider struct {
+	provider.Provider
+	chainIDs []string
+}
+
+func (p *chainIDsProvider) SetChainIDs(chainIDs []string) { p.chainIDs = chainIDs }
+
+func TestClient_SetsProviderChainIDs(t *testing.T) {
+	headers := map[int64]*types.SignedHeader{1: h1, 2: h2, 3: h3, 4: u4, 5: u5}
+	validators := map[int64]*types.ValidatorSet{1: vals, 2: vals, 3: vals, 4: upgradedVals, 5: upgradedVals}
+	primary := &chainIDsProvider{Provider: mockNodeFromHeadersAndVals(headers, validators)}
+	witness := &chainIDsProvider{Provider: mockNodeFromHeadersAndVals(headers, validators)}
+
+	c, err := light.NewClient(
+		ctx,
+		chainID,
+		trustOptions,
+		primary,
+		[]provider.Provider{witness},
+		dbs.New(dbm.NewMemDB()),
+		light.Upgrades(newUpgrade()),
+		light.Logger(log.TestingLogger()),
+	)
+	require.NoError(t, err)
+	assert.Equal(t, []string{chainID, upgradedChainID}, primary.chainIDs)
+	assert.Equal(t, []string{chainID, upgradedChainID}, witness.chainIDs)
+
+	added := &chainIDsProvider{Provider: mockNodeFromHeadersAndVals(headers, validators)}
+	c.AddProvider(added)
+	assert.Equal(t, []string{chainID, upgradedChainID}, added.chainIDs)
+}
@@ -0,0 +1,201 @@
+package node
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/tendermint/tendermint/config"
+	"github.com/tendermint/tendermint/internal/p2p"
+	"github.com/tendermint/tendermint/internal/p2p/pex"
+	"github.com/tendermint/tendermint/internal/statesync"
+	"github.com/tendermint/tendermint/libs/log"
+	"github.com/tendermint/tendermint/libs/service"
+	"github.com/tendermint/tendermint/light"
+	"github.com/tendermint/tendermint/light/provider"
+	"github.com/tendermint/tendermint/types"
+	"github.com/tendermint/tendermint/version"
+)
+
+// lightNodeImpl is a node that joins the p2p network of a chain only to
+// source light blocks for a light client. It discovers peers through PEX and
+// serves the light blocks it has verified to other peers.
+type lightNodeImpl struct {
+	service.BaseService
+
+	config *config.Config
+
+	peerManager  *p2p.PeerManager
+	router       *p2p.Router
+	nodeInfo     types.NodeInfo
+	nodeKey      types.NodeKey
+	pexReactor   service.Service
+	lightReactor *statesync.LightClientReactor
+
+	shutdownOps closer
+}
+
+// NewLightNode constructs a light node for the given chain, using the P2P
+// section of the config and the node key and databases in its home directory.
+// PEX must be enabled for the node to discover peers.
+func NewLightNode(cfg *config.Config, logger log.Logger, chainID string) (light.Network, error) {
+	nodeKey, err := types.LoadOrGenNodeKey(cfg.NodeKeyFile())
+	if err != nil {
+		return nil, fmt.Errorf("failed to load or gen node key %s: %w", cfg.NodeKeyFile(), err)
+	}
+
+	return makeLightNode(cfg, config.DefaultDBProvider, nodeKey, chainID, logger)
+}
+
+func makeLightNode(cfg *config.Config,
+	dbProvider config.DBProvider,
+	nodeKey types.NodeKey,
+	chainID string,
+	logger log.Logger,
+) (*lightNodeImpl, error) {
+	if !cfg.P2P.PexReactor {
+		return nil, errors.New("cannot run light nodes with PEX disabled")
+	}
+
+	nodeInfo, err := makeLightNodeInfo(cfg, nodeKey, chainID)
+	if err != nil {
+		return nil, err
+	}
+
+	p2pMetrics := defaultMetricsProvider(cfg.Instrumentation)(chainID).p2p
+
+	peerManager, closer, err := createPeerManager(cfg, dbProvider, nodeKey.ID)
+	if err != nil {
+		return nil, combineCloseError(
+			fmt.Errorf("failed to create peer manager: %w", err),
+			closer)
+	}
+
+	router, err := createRouter(logger, p2pMetrics, nodeInfo, nodeKey,
+		peerManager, cfg, nil)
+	if err != nil {
+		return nil, combineCloseError(
+			fmt.Errorf("failed to create router: %w", err),
+			closer)
+	}
+
+	pexReactor, err := createPEXReactor(logger, peerManager, router)
+	if err != nil {
+		return nil, combineCloseError(err, closer)
+	}
+
+	var lightBlockChDesc *p2p.ChannelDescriptor
+	for _, chDesc := range statesync.GetChannelDescriptors() {
+		if chDesc.ID == statesync.LightBlockChannel {
+			lightBlockChDesc = chDesc
+		}
+	}
+	blockCh, err := router.OpenChannel(lightBlockChDesc)
+	if err != nil {
+		return nil, combineCloseError(err, closer)
+	}
+
+	lightReactor := statesync.NewLightClientReactor(
+		chainID,
+		logger.With("module", "light"),
+		blockCh,
+		peerManager.Subscribe(),
+	)
+
+	node := &lightNodeImpl{
+		config: cfg,
+
+		nodeInfo:    nodeInfo,
+		nodeKey:     nodeKey,
+		peerManager: peerManager,
+		router:      router,
+
+		shutdownOps: closer,
+
+		pexReactor:   pexReactor,
+		lightReactor: lightReactor,
+	}
+	node.BaseService = *service.NewBaseService(logger, "LightNode", node)
+
+	return node, nil
+}
+
+// OnStart starts the light node. It implements service.Service.
+func (n *lightNodeImpl) OnStart() error {
+	if err := n.router.Start(); err != nil {
+		return err
+	}
+
+	if err := n.lightReactor.Start(); err != nil {
+		return err
+	}
+
+	return n.pexReactor.Start()
+}
+
+// OnStop stops the light node. It implements service.Service.
+func (n *lightNodeImpl) OnStop() {
+	n.Logger.Info("Stopping Node")
+
+	if err := n.lightReactor.Stop(); err != nil {
+		n.Logger.Error("failed to stop the light client reactor", "err", err)
+	}
+
+	if err := n.pexReactor.Stop(); err != nil {
+		n.Logger.Error("failed to stop the PEX v2 reactor", "err", err)
+	}
+
+	if err := n.router.Stop(); err != nil {
+		n.Logger.Error("failed to stop router", "err", err)
+	}
+
+	if err := n.shutdownOps(); err != nil {
+		n.Logger.Error("problem shutting down additional services", "err", err)
+	}
+}
+
+func (n *lightNodeImpl) Providers(ctx context.Context, minPeers int) ([]provider.Provider, error) {
+	return n.lightReactor.Providers(ctx, minPeers)
+}
+
+func (n *lightNodeImpl) SetLightClient(c *light.Client) {
+	n.lightReactor.SetLightClient(c)
+}
+
+// makeLightNodeInfo returns the node info of a light node. Without a genesis
+// document or state, the chain ID and the block protocol of this binary are
+// advertised.
+func makeLightNodeInfo(
+	cfg *config.Config,
+	nodeKey types.NodeKey,
+	chainID string,
+) (types.NodeInfo, error) {
+	nodeInfo := types.NodeInfo{
+		ProtocolVersion: types.ProtocolVersion{
+			P2P:   version.P2PProtocol, // global
+			Block: version.BlockProtocol,
+		},
+		NodeID:  nodeKey.ID,
+		Network: chainID,
+		Version: version.TMVersion,
+		Channels: []byte{
+			byte(statesync.LightBlockChannel),
+			pex.PexChannel,
+		},
+		Moniker: cfg.Moniker,
+		Other: types.NodeInfoOther{
+			TxIndex: "off",
+		},
+	}
+
+	lAddr := cfg.P2P.ExternalAddress
+
+	if lAddr == "" {
+		lAddr = cfg.P2P.ListenAddress
+	}
+
+	nodeInfo.ListenAddr = lAddr
+
+	err := nodeInfo.Validate()
+	return nodeInfo, err
+}
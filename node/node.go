@@ -6,6 +6,8 @@ import (
 	"fmt"
 	"net"
 	"net/http"
+	"os"
+	"path/filepath"
 	"strconv"
 	"time"
 
@@ -16,10 +18,13 @@ import (
 	abci "github.com/tendermint/tendermint/abci/types"
 	"github.com/tendermint/tendermint/config"
 	"github.com/tendermint/tendermint/crypto"
+	"github.com/tendermint/tendermint/internal/blocksync"
 	"github.com/tendermint/tendermint/internal/consensus"
 	"github.com/tendermint/tendermint/internal/eventbus"
+	"github.com/tendermint/tendermint/internal/libs/compress"
 	"github.com/tendermint/tendermint/internal/mempool"
 	"github.com/tendermint/tendermint/internal/p2p"
+	"github.com/tendermint/tendermint/internal/p2p/pex"
 	"github.com/tendermint/tendermint/internal/proxy"
 	rpccore "github.com/tendermint/tendermint/internal/rpc/core"
 	sm "github.com/tendermint/tendermint/internal/state"
@@ -71,6 +76,7 @@ type nodeImpl struct {
 	stateSyncReactor *statesync.Reactor // for hosting and restoring state sync snapshots
 	consensusReactor *consensus.Reactor // for participating in the consensus
 	pexReactor       service.Service    // for exchanging peer addresses
+	crawler          *pex.Crawler       // for crawling the network as a seed node
 	evidenceReactor  service.Service
 	rpcListeners     []net.Listener // rpc servers
 	shutdownOps      closer
@@ -129,33 +135,39 @@ func makeNode(cfg *config.Config,
 ) (service.Service, error) {
 	closers := []closer{}
 
-	blockStore, stateDB, dbCloser, err := initDBs(cfg, dbProvider)
+	genDoc, err := genesisDocProvider()
 	if err != nil {
-		return nil, combineCloseError(err, dbCloser)
+		return nil, err
 	}
-	closers = append(closers, dbCloser)
 
-	stateStore := sm.NewStore(stateDB)
+	err = genDoc.ValidateAndComplete()
+	if err != nil {
+		return nil, fmt.Errorf("error in genesis doc: %w", err)
+	}
 
-	genDoc, err := genesisDocProvider()
+	nodeMetrics := defaultMetricsProvider(cfg.Instrumentation)(genDoc.ChainID)
+
+	dbCodec, err := compress.ParseCodec(cfg.DBCompression)
 	if err != nil {
-		return nil, combineCloseError(err, makeCloser(closers))
+		return nil, err
 	}
 
-	err = genDoc.ValidateAndComplete()
+	blockStore, stateDB, dbCloser, err := initDBs(cfg, dbProvider, nodeMetrics.blockstore)
 	if err != nil {
-		return nil, combineCloseError(
-			fmt.Errorf("error in genesis doc: %w", err),
-			makeCloser(closers))
+		return nil, combineCloseError(err, dbCloser)
 	}
+	closers = append(closers, dbCloser)
+
+	stateStore := sm.NewStore(stateDB,
+		sm.StoreWithCompression(dbCodec),
+		sm.StoreWithMetrics(nodeMetrics.state),
+	)
 
 	state, err := loadStateFromDBOrGenesisDocProvider(stateStore, genDoc)
 	if err != nil {
 		return nil, combineCloseError(err, makeCloser(closers))
 	}
 
-	nodeMetrics := defaultMetricsProvider(cfg.Instrumentation)(genDoc.ChainID)
-
 	// Create the proxyApp and establish connections to the ABCI app (consensus, mempool, query).
 	proxyApp, err := createAndStartProxyAppConns(clientCreator, logger, nodeMetrics.proxy)
 	if err != nil {
@@ -273,7 +285,7 @@ func makeNode(cfg *config.Config,
 	}
 
 	mpReactor, mp, err := createMempoolReactor(
-		cfg, proxyApp, state, nodeMetrics.mempool, peerManager, router, logger,
+		cfg, proxyApp, state, nodeMetrics.mempool, peerManager, router, eventBus, logger,
 	)
 	if err != nil {
 		return nil, combineCloseError(err, makeCloser(closers))
@@ -306,26 +318,6 @@ func makeNode(cfg *config.Config,
 		return nil, combineCloseError(err, makeCloser(closers))
 	}
 
-	// Create the blockchain reactor. Note, we do not start block sync if we're
-	// doing a state sync first.
-	bcReactor, err := createBlockchainReactor(
-		logger, state, blockExec, blockStore, csReactor,
-		peerManager, router, blockSync && !stateSync, nodeMetrics.consensus,
-	)
-	if err != nil {
-		return nil, combineCloseError(
-			fmt.Errorf("could not create blockchain reactor: %w", err),
-			makeCloser(closers))
-	}
-
-	// Make ConsensusReactor. Don't enable fully if doing a state sync and/or block sync first.
-	// FIXME We need to update metrics here, since other reactors don't have access to them.
-	if stateSync {
-		nodeMetrics.consensus.StateSyncing.Set(1)
-	} else if blockSync {
-		nodeMetrics.consensus.BlockSyncing.Set(1)
-	}
-
 	// Set up state sync reactor, and schedule a sync if requested.
 	// FIXME The way we do phased startups (e.g. replay -> block sync -> consensus) is very messy,
 	// we should clean this whole thing up. See:
@@ -342,6 +334,18 @@ func makeNode(cfg *config.Config,
 		channels[ch.ID] = ch
 	}
 
+	if cfg.StateSync.TempDir != "" {
+		logger.Error("statesync.temp-dir is deprecated and ignored: snapshot chunks are kept in the "+
+			"statesync directory of the data directory", "temp-dir", cfg.StateSync.TempDir)
+	}
+	stateSyncDir := filepath.Join(cfg.DBDir(), "statesync")
+	if !stateSync {
+		// the chunks of an interrupted state sync can't be resumed anymore
+		if err := os.RemoveAll(stateSyncDir); err != nil {
+			logger.Error("failed to remove state sync chunks", "dir", stateSyncDir, "err", err)
+		}
+	}
+
 	stateSyncReactor := statesync.NewReactor(
 		genDoc.ChainID,
 		genDoc.InitialHeight,
@@ -357,9 +361,37 @@ func makeNode(cfg *config.Config,
 		stateStore,
 		blockStore,
 		cfg.StateSync.TempDir,
+		stateSyncDir,
 		nodeMetrics.statesync,
 	)
 
+	// In header-first block sync, the headers of the blocks to sync are
+	// fetched over the light block channel of the state sync reactor.
+	var lightBlocks blocksync.LightBlockProvider
+	if cfg.BlockSync.HeaderFirst {
+		lightBlocks = stateSyncReactor
+	}
+
+	// Create the blockchain reactor. Note, we do not start block sync if we're
+	// doing a state sync first.
+	bcReactor, err := createBlockchainReactor(
+		logger, state, blockExec, blockStore, csReactor,
+		peerManager, router, blockSync && !stateSync, cfg.BlockSync, lightBlocks, nodeMetrics.consensus,
+	)
+	if err != nil {
+		return nil, combineCloseError(
+			fmt.Errorf("could not create blockchain reactor: %w", err),
+			makeCloser(closers))
+	}
+
+	// Make ConsensusReactor. Don't enable fully if doing a state sync and/or block sync first.
+	// FIXME We need to update metrics here, since other reactors don't have access to them.
+	if stateSync {
+		nodeMetrics.consensus.StateSyncing.Set(1)
+	} else if blockSync {
+		nodeMetrics.consensus.BlockSyncing.Set(1)
+	}
+
 	var pexReactor service.Service
 	if cfg.P2P.PexReactor {
 		pexReactor, err = createPEXReactor(logger, peerManager, router)
@@ -404,6 +436,7 @@ func makeNode(cfg *config.Config,
 			ConsensusState: csState,
 
 			ConsensusReactor: csReactor,
+			MempoolReactor:   mpReactor,
 			BlockSyncReactor: bcReactor.(consensus.BlockSyncReactor),
 
 			PeerManager: peerManager,
@@ -451,7 +484,12 @@ func makeSeedNode(cfg *config.Config,
 	}
 
 	// Setup Transport and Switch.
-	p2pMetrics := p2p.PrometheusMetrics(cfg.Instrumentation.Namespace, "chain_id", genDoc.ChainID)
+	// Prometheus metrics are only registered when enabled, as with full nodes,
+	// so that several seed nodes can run in the same process.
+	p2pMetrics := p2p.NopMetrics()
+	if cfg.Instrumentation.Prometheus {
+		p2pMetrics = p2p.PrometheusMetrics(cfg.Instrumentation.Namespace, "chain_id", genDoc.ChainID)
+	}
 
 	peerManager, closer, err := createPeerManager(cfg, dbProvider, nodeKey.ID)
 	if err != nil {
@@ -473,6 +511,18 @@ func makeSeedNode(cfg *config.Config,
 		return nil, combineCloseError(err, closer)
 	}
 
+	var crawler *pex.Crawler
+	if cfg.P2P.Crawl {
+		var crawlerCloser func() error
+		crawler, crawlerCloser, err = createCrawler(logger, cfg, dbProvider, peerManager, router)
+		peerCloser := closer
+		closer = func() error { return combineCloseError(crawlerCloser(), peerCloser) }
+		if err != nil {
+			return nil, combineCloseError(err, closer)
+		}
+		pexReactor.SetAdvertiser(crawler)
+	}
+
 	node := &nodeImpl{
 		config:     cfg,
 		genesisDoc: genDoc,
@@ -485,7 +535,21 @@ func makeSeedNode(cfg *config.Config,
 		shutdownOps: closer,
 
 		pexReactor: pexReactor,
+		crawler:    crawler,
+	}
+
+	// Seed nodes only serve the crawler's results over RPC.
+	if crawler != nil {
+		node.rpcEnv = &rpccore.Environment{
+			PeerManager:  peerManager,
+			Crawler:      crawler,
+			P2PTransport: node,
+			GenDoc:       genDoc,
+			Logger:       logger.With("module", "rpc"),
+			Config:       *cfg.RPC,
+		}
 	}
+
 	node.BaseService = *service.NewBaseService(logger, "SeedNode", node)
 
 	return node, nil
@@ -512,7 +576,7 @@ func (n *nodeImpl) OnStart() error {
 
 	// Start the RPC server before the P2P server
 	// so we can eg. receive txs for the first block
-	if n.config.RPC.ListenAddress != "" && n.config.Mode != config.ModeSeed {
+	if n.config.RPC.ListenAddress != "" && n.rpcEnv != nil {
 		listeners, err := n.startRPC()
 		if err != nil {
 			return err
@@ -563,6 +627,12 @@ func (n *nodeImpl) OnStart() error {
 		}
 	}
 
+	if n.crawler != nil {
+		if err := n.crawler.Start(); err != nil {
+			return err
+		}
+	}
+
 	// Run state sync
 	// TODO: We shouldn't run state sync if we already have state that has a
 	// LastBlockHeight that is not InitialHeight
@@ -688,6 +758,12 @@ func (n *nodeImpl) OnStop() {
 		}
 	}
 
+	if n.crawler != nil {
+		if err := n.crawler.Stop(); err != nil {
+			n.Logger.Error("failed to stop the crawler", "err", err)
+		}
+	}
+
 	if err := n.pexReactor.Stop(); err != nil {
 		n.Logger.Error("failed to stop the PEX v2 reactor", "err", err)
 	}
@@ -737,8 +813,9 @@ func (n *nodeImpl) startRPC() ([]net.Listener, error) {
 
 	listenAddrs := strings.SplitAndTrimEmpty(n.config.RPC.ListenAddress, ",", " ")
 	routes := n.rpcEnv.GetRoutes()
-
-	if n.config.RPC.Unsafe {
+	if n.config.Mode == config.ModeSeed {
+		routes = n.rpcEnv.GetCrawlerRoutes()
+	} else if n.config.RPC.Unsafe {
 		n.rpcEnv.AddUnsafe(routes)
 	}
 
@@ -761,6 +838,9 @@ func (n *nodeImpl) startRPC() ([]net.Listener, error) {
 		wmLogger := rpcLogger.With("protocol", "websocket")
 		wm := rpcserver.NewWebsocketManager(routes,
 			rpcserver.OnDisconnect(func(remoteAddr string) {
+				if n.eventBus == nil {
+					return
+				}
 				err := n.eventBus.UnsubscribeAll(context.Background(), remoteAddr)
 				if err != nil && err != tmpubsub.ErrSubscriptionNotFound {
 					wmLogger.Error("Failed to unsubscribe addr from events", "addr", remoteAddr, "err", err)
@@ -903,13 +983,14 @@ func defaultGenesisDocProviderFunc(cfg *config.Config) genesisDocProvider {
 }
 
 type nodeMetrics struct {
-	consensus *consensus.Metrics
-	indexer   *indexer.Metrics
-	mempool   *mempool.Metrics
-	p2p       *p2p.Metrics
-	proxy     *proxy.Metrics
-	state     *sm.Metrics
-	statesync *statesync.Metrics
+	blockstore *store.Metrics
+	consensus  *consensus.Metrics
+	indexer    *indexer.Metrics
+	mempool    *mempool.Metrics
+	p2p        *p2p.Metrics
+	proxy      *proxy.Metrics
+	state      *sm.Metrics
+	statesync  *statesync.Metrics
 }
 
 // metricsProvider returns consensus, p2p, mempool, state, statesync Metrics.
@@ -921,23 +1002,25 @@ func defaultMetricsProvider(cfg *config.InstrumentationConfig) metricsProvider {
 	return func(chainID string) *nodeMetrics {
 		if cfg.Prometheus {
 			return &nodeMetrics{
-				consensus: consensus.PrometheusMetrics(cfg.Namespace, "chain_id", chainID),
-				indexer:   indexer.PrometheusMetrics(cfg.Namespace, "chain_id", chainID),
-				mempool:   mempool.PrometheusMetrics(cfg.Namespace, "chain_id", chainID),
-				p2p:       p2p.PrometheusMetrics(cfg.Namespace, "chain_id", chainID),
-				proxy:     proxy.PrometheusMetrics(cfg.Namespace, "chain_id", chainID),
-				state:     sm.PrometheusMetrics(cfg.Namespace, "chain_id", chainID),
-				statesync: statesync.PrometheusMetrics(cfg.Namespace, "chain_id", chainID),
+				blockstore: store.PrometheusMetrics(cfg.Namespace, "chain_id", chainID),
+				consensus:  consensus.PrometheusMetrics(cfg.Namespace, "chain_id", chainID),
+				indexer:    indexer.PrometheusMetrics(cfg.Namespace, "chain_id", chainID),
+				mempool:    mempool.PrometheusMetrics(cfg.Namespace, "chain_id", chainID),
+				p2p:        p2p.PrometheusMetrics(cfg.Namespace, "chain_id", chainID),
+				proxy:      proxy.PrometheusMetrics(cfg.Namespace, "chain_id", chainID),
+				state:      sm.PrometheusMetrics(cfg.Namespace, "chain_id", chainID),
+				statesync:  statesync.PrometheusMetrics(cfg.Namespace, "chain_id", chainID),
 			}
 		}
 		return &nodeMetrics{
-			consensus: consensus.NopMetrics(),
-			indexer:   indexer.NopMetrics(),
-			mempool:   mempool.NopMetrics(),
-			p2p:       p2p.NopMetrics(),
-			proxy:     proxy.NopMetrics(),
-			state:     sm.NopMetrics(),
-			statesync: statesync.NopMetrics(),
+			blockstore: store.NopMetrics(),
+			consensus:  consensus.NopMetrics(),
+			indexer:    indexer.NopMetrics(),
+			mempool:    mempool.NopMetrics(),
+			p2p:        p2p.NopMetrics(),
+			proxy:      proxy.NopMetrics(),
+			state:      sm.NopMetrics(),
+			statesync:  statesync.NopMetrics(),
 		}
 	}
 }
@@ -1026,7 +1109,17 @@ func createAndStartPrivValidatorGRPCClient(
 
 func getRouterConfig(conf *config.Config, proxyApp proxy.AppConns) p2p.RouterOptions {
 	opts := p2p.RouterOptions{
-		QueueType: conf.P2P.QueueType,
+		QueueType:    conf.P2P.QueueType,
+		PeerSendRate: conf.P2P.PeerSendRate,
+		PeerRecvRate: conf.P2P.PeerRecvRate,
+	}
+
+	// The limits have been validated with the rest of the config.
+	if limits, err := conf.P2P.ParseChannelRateLimits(); err == nil && len(limits) > 0 {
+		opts.ChannelRateLimits = make(map[p2p.ChannelID]config.ChannelRateLimit, len(limits))
+		for chID, limit := range limits {
+			opts.ChannelRateLimits[p2p.ChannelID(chID)] = limit
+		}
 	}
 
 	if conf.FilterPeers && proxyApp != nil {
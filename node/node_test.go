@@ -26,6 +26,7 @@ import (
 	"github.com/tendermint/tendermint/internal/proxy"
 	sm "github.com/tendermint/tendermint/internal/state"
 	"github.com/tendermint/tendermint/internal/state/indexer"
+	"github.com/tendermint/tendermint/internal/statesync"
 	"github.com/tendermint/tendermint/internal/store"
 	"github.com/tendermint/tendermint/internal/test/factory"
 	"github.com/tendermint/tendermint/libs/log"
@@ -266,12 +267,13 @@ func TestCreateProposalBlock(t *testing.T) {
 	state.ConsensusParams.Evidence.MaxBytes = maxEvidenceBytes
 	proposerAddr, _ := state.Validators.GetByIndex(0)
 
-	mp := mempool.NewTxMempool(
+	mp, err := mempool.NewTxMempool(
 		logger.With("module", "mempool"),
 		cfg.Mempool,
 		proxyApp.Mempool(),
 		state.LastBlockHeight,
 	)
+	require.NoError(t, err)
 
 	// Make EvidencePool
 	evidenceDB := dbm.NewMemDB()
@@ -361,12 +363,13 @@ func TestMaxTxsProposalBlockSize(t *testing.T) {
 
 	// Make Mempool
 
-	mp := mempool.NewTxMempool(
+	mp, err := mempool.NewTxMempool(
 		logger.With("module", "mempool"),
 		cfg.Mempool,
 		proxyApp.Mempool(),
 		state.LastBlockHeight,
 	)
+	require.NoError(t, err)
 
 	// fill the mempool with one txs just below the maximum size
 	txLength := int(types.MaxDataBytesNoEvidence(maxBytes, 1))
@@ -422,12 +425,13 @@ func TestMaxProposalBlockSize(t *testing.T) {
 	proposerAddr, _ := state.Validators.GetByIndex(0)
 
 	// Make Mempool
-	mp := mempool.NewTxMempool(
+	mp, err := mempool.NewTxMempool(
 		logger.With("module", "mempool"),
 		cfg.Mempool,
 		proxyApp.Mempool(),
 		state.LastBlockHeight,
 	)
+	require.NoError(t, err)
 
 	// fill the mempool with one txs just below the maximum size
 	txLength := int(types.MaxDataBytesNoEvidence(maxBytes, types.MaxVotesCount))
@@ -543,6 +547,55 @@ func TestNodeNewSeedNode(t *testing.T) {
 
 }
 
+func TestNodeNewSeedNodeCrawler(t *testing.T) {
+	cfg, err := config.ResetTestRoot("node_new_seed_node_crawler_test")
+	require.NoError(t, err)
+	cfg.Mode = config.ModeSeed
+	cfg.P2P.Crawl = true
+	defer os.RemoveAll(cfg.RootDir)
+
+	nodeKey, err := types.LoadOrGenNodeKey(cfg.NodeKeyFile())
+	require.NoError(t, err)
+
+	ns, err := makeSeedNode(cfg,
+		config.DefaultDBProvider,
+		nodeKey,
+		defaultGenesisDocProviderFunc(cfg),
+		log.TestingLogger(),
+	)
+	require.NoError(t, err)
+	n, ok := ns.(*nodeImpl)
+	require.True(t, ok)
+
+	require.NoError(t, n.Start())
+	assert.True(t, n.crawler.IsRunning())
+	assert.Len(t, n.rpcListeners, 1)
+
+	require.NoError(t, n.Stop())
+	assert.False(t, n.crawler.IsRunning())
+}
+
+func TestNodeNewLightNode(t *testing.T) {
+	cfg, err := config.ResetTestRoot("node_new_light_node_test")
+	require.NoError(t, err)
+	defer os.RemoveAll(cfg.RootDir)
+
+	ln, err := NewLightNode(cfg, log.TestingLogger(), "test-chain")
+	require.NoError(t, err)
+	n, ok := ln.(*lightNodeImpl)
+	require.True(t, ok)
+	assert.Contains(t, n.nodeInfo.Channels, byte(statesync.LightBlockChannel))
+
+	require.NoError(t, n.Start())
+	assert.True(t, n.pexReactor.IsRunning())
+	assert.True(t, n.lightReactor.IsRunning())
+	require.NoError(t, n.Stop())
+
+	cfg.P2P.PexReactor = false
+	_, err = NewLightNode(cfg, log.TestingLogger(), "test-chain")
+	require.Error(t, err)
+}
+
 func TestNodeSetEventSink(t *testing.T) {
 	cfg, err := config.ResetTestRoot("node_app_version_test")
 	require.NoError(t, err)
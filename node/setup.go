@@ -68,15 +68,19 @@ func combineCloseError(err error, cl closer) error {
 func initDBs(
 	cfg *config.Config,
 	dbProvider config.DBProvider,
+	metrics *store.Metrics,
 ) (*store.BlockStore, dbm.DB, closer, error) {
 
 	blockStoreDB, err := dbProvider(&config.DBContext{ID: "blockstore", Config: cfg})
 	if err != nil {
 		return nil, nil, func() error { return nil }, err
 	}
-	closers := []closer{}
-	blockStore := store.NewBlockStore(blockStoreDB)
-	closers = append(closers, blockStoreDB.Close)
+	closers := []closer{blockStoreDB.Close}
+	blockStore, err := store.OpenBlockStore(cfg.BaseConfig, blockStoreDB, store.WithMetrics(metrics))
+	if err != nil {
+		return nil, nil, makeCloser(closers), err
+	}
+	closers = append(closers, blockStore.Close)
 
 	stateDB, err := dbProvider(&config.DBContext{ID: "state", Config: cfg})
 	if err != nil {
@@ -115,7 +119,7 @@ func createAndStartIndexerService(
 	chainID string,
 	metrics *indexer.Metrics,
 ) (*indexer.Service, []indexer.EventSink, error) {
-	eventSinks, err := sink.EventSinksFromConfig(cfg, dbProvider, chainID)
+	eventSinks, err := sink.EventSinksFromConfig(cfg, dbProvider, chainID, logger.With("module", "txindex"))
 	if err != nil {
 		return nil, nil, err
 	}
@@ -186,8 +190,9 @@ func createMempoolReactor(
 	memplMetrics *mempool.Metrics,
 	peerManager *p2p.PeerManager,
 	router *p2p.Router,
+	eventBus types.MempoolEventPublisher,
 	logger log.Logger,
-) (service.Service, mempool.Mempool, error) {
+) (*mempool.Reactor, mempool.Mempool, error) {
 
 	logger = logger.With("module", "mempool")
 
@@ -196,15 +201,19 @@ func createMempoolReactor(
 		return nil, nil, err
 	}
 
-	mp := mempool.NewTxMempool(
+	mp, err := mempool.NewTxMempool(
 		logger,
 		cfg.Mempool,
 		proxyApp.Mempool(),
 		state.LastBlockHeight,
 		mempool.WithMetrics(memplMetrics),
+		mempool.WithEventBus(eventBus),
 		mempool.WithPreCheck(sm.TxPreCheck(state)),
 		mempool.WithPostCheck(sm.TxPostCheck(state)),
 	)
+	if err != nil {
+		return nil, nil, err
+	}
 
 	reactor := mempool.NewReactor(
 		logger,
@@ -267,6 +276,8 @@ func createBlockchainReactor(
 	peerManager *p2p.PeerManager,
 	router *p2p.Router,
 	blockSync bool,
+	cfg *config.BlockSyncConfig,
+	lightBlocks blocksync.LightBlockProvider,
 	metrics *consensus.Metrics,
 ) (service.Service, error) {
 
@@ -281,7 +292,7 @@ func createBlockchainReactor(
 
 	reactor, err := blocksync.NewReactor(
 		logger, state.Copy(), blockExec, blockStore, csReactor,
-		ch, peerUpdates, blockSync,
+		ch, peerUpdates, blockSync, *cfg, lightBlocks,
 		metrics,
 	)
 	if err != nil {
@@ -361,12 +372,17 @@ func createTransport(logger log.Logger, cfg *config.Config) *p2p.MConnTransport
 	conf.RecvRate = cfg.P2P.RecvRate
 	conf.MaxPacketMsgPayloadSize = cfg.P2P.MaxPacketMsgPayloadSize
 
-	return p2p.NewMConnTransport(
-		logger, conf, []*p2p.ChannelDescriptor{},
-		p2p.MConnTransportOptions{
-			MaxAcceptedConnections: uint32(cfg.P2P.MaxConnections),
-		},
-	)
+	options := p2p.MConnTransportOptions{
+		MaxAcceptedConnections: uint32(cfg.P2P.MaxConnections),
+	}
+	// Only Noise is offered, since a SecretConnection fallback would let a
+	// man in the middle downgrade the connection by tampering with the
+	// negotiation, which only the Noise handshake authenticates.
+	if cfg.P2P.SecureChannel == string(p2p.SecureChannelNoise) {
+		options.SecureChannels = []p2p.SecureChannel{p2p.SecureChannelNoise}
+	}
+
+	return p2p.NewMConnTransport(logger, conf, []*p2p.ChannelDescriptor{}, options)
 }
 
 func createPeerManager(
@@ -398,6 +414,18 @@ func createPeerManager(
 		MaxRetryTimePersistent: 5 * time.Minute,
 		RetryTimeJitter:        3 * time.Second,
 		PrivatePeers:           privatePeerIDs,
+		MaxConnectedPerSubnet:  cfg.P2P.MaxConnectionsPerSubnet,
+		MaxPeersPerSubnet:      cfg.P2P.MaxPeersPerSubnet,
+		MaxConnectedPerASN:     cfg.P2P.MaxConnectionsPerASN,
+		MaxPeersPerASN:         cfg.P2P.MaxPeersPerASN,
+	}
+
+	if cfg.P2P.ASNMap != "" {
+		asnMap, err := p2p.LoadASNMap(cfg.P2P.ASNMapFile())
+		if err != nil {
+			return nil, func() error { return nil }, err
+		}
+		options.ASNMap = asnMap
 	}
 
 	peers := []p2p.NodeAddress{}
@@ -416,8 +444,13 @@ func createPeerManager(
 		if err != nil {
 			return nil, func() error { return nil }, fmt.Errorf("invalid peer address %q: %w", p, err)
 		}
+		if address.Protocol == p2p.DNSSeedProtocol {
+			options.DNSSeeds = append(options.DNSSeeds, address)
+			continue
+		}
 		peers = append(peers, address)
 	}
+	options.DNSSeedInterval = cfg.P2P.DNSSeedInterval
 
 	peerDB, err := dbProvider(&config.DBContext{ID: "peerstore", Config: cfg})
 	if err != nil {
@@ -472,7 +505,7 @@ func createPEXReactor(
 	logger log.Logger,
 	peerManager *p2p.PeerManager,
 	router *p2p.Router,
-) (service.Service, error) {
+) (*pex.Reactor, error) {
 
 	channel, err := router.OpenChannel(pex.ChannelDescriptor())
 	if err != nil {
@@ -482,6 +515,36 @@ func createPEXReactor(
 	return pex.NewReactor(logger, peerManager, channel, peerManager.Subscribe()), nil
 }
 
+func createCrawler(
+	logger log.Logger,
+	cfg *config.Config,
+	dbProvider config.DBProvider,
+	peerManager *p2p.PeerManager,
+	router *p2p.Router,
+) (*pex.Crawler, closer, error) {
+
+	crawlerDB, err := dbProvider(&config.DBContext{ID: "crawler", Config: cfg})
+	if err != nil {
+		return nil, func() error { return nil }, err
+	}
+
+	crawler, err := pex.NewCrawler(
+		logger.With("module", "crawler"),
+		peerManager,
+		router,
+		crawlerDB,
+		pex.CrawlerOptions{
+			Interval:    cfg.P2P.CrawlInterval,
+			DialTimeout: cfg.P2P.DialTimeout + cfg.P2P.HandshakeTimeout,
+		},
+	)
+	if err != nil {
+		return nil, crawlerDB.Close, fmt.Errorf("failed to create crawler: %w", err)
+	}
+
+	return crawler, crawlerDB.Close, nil
+}
+
 func makeNodeInfo(
 	cfg *config.Config,
 	nodeKey types.NodeKey,
@@ -300,6 +300,24 @@ func (pv *FilePV) Reset() {
 	pv.Save()
 }
 
+// Rollback adjusts the last sign state after the node's state was rolled back
+// to height, so that the heights above it can be signed again. It returns
+// false if nothing above height was signed.
+// NOTE: Unsafe! Signing a height again may produce conflicting votes unless
+// the rest of the network rolled back as well.
+func (pv *FilePV) Rollback(height int64) bool {
+	if pv.LastSignState.Height <= height {
+		return false
+	}
+	pv.LastSignState.Height = height
+	pv.LastSignState.Round = 0
+	pv.LastSignState.Step = 0
+	pv.LastSignState.Signature = nil
+	pv.LastSignState.SignBytes = nil
+	pv.LastSignState.Save()
+	return true
+}
+
 // String returns a string representation of the FilePV.
 func (pv *FilePV) String() string {
 	return fmt.Sprintf(
@@ -72,6 +72,34 @@ func TestResetValidator(t *testing.T) {
 	assert.Equal(t, privVal.LastSignState, emptyState)
 }
 
+func TestRollbackValidator(t *testing.T) {
+	tempKeyFile, err := os.CreateTemp("", "priv_validator_key_")
+	require.NoError(t, err)
+	tempStateFile, err := os.CreateTemp("", "priv_validator_state_")
+	require.NoError(t, err)
+
+	privVal, err := GenFilePV(tempKeyFile.Name(), tempStateFile.Name(), "")
+	require.NoError(t, err)
+	privVal.Save()
+
+	blockID := types.BlockID{Hash: tmrand.Bytes(tmhash.Size), PartSetHeader: types.PartSetHeader{}}
+	vote := newVote(privVal.Key.Address, 0, 10, 1, tmproto.PrecommitType, blockID)
+	require.NoError(t, privVal.SignVote(context.Background(), "mychainid", vote.ToProto()))
+
+	// nothing was signed above height 10
+	assert.False(t, privVal.Rollback(10))
+	assert.EqualValues(t, 10, privVal.LastSignState.Height)
+
+	assert.True(t, privVal.Rollback(7))
+	loaded, err := LoadFilePV(tempKeyFile.Name(), tempStateFile.Name())
+	require.NoError(t, err)
+	assert.Equal(t, FilePVLastSignState{Height: 7, filePath: tempStateFile.Name()}, loaded.LastSignState)
+
+	// heights above the rollback height can be signed again
+	vote = newVote(privVal.Key.Address, 0, 8, 0, tmproto.PrevoteType, blockID)
+	assert.NoError(t, loaded.SignVote(context.Background(), "mychainid", vote.ToProto()))
+}
+
 func TestLoadOrGenValidator(t *testing.T) {
 	assert := assert.New(t)
 
@@ -18,6 +18,9 @@ func (m *Message) Wrap(pb proto.Message) error {
 	case *BlockRequest:
 		m.Sum = &Message_BlockRequest{BlockRequest: msg}
 
+	case *BlockRangeRequest:
+		m.Sum = &Message_BlockRangeRequest{BlockRangeRequest: msg}
+
 	case *BlockResponse:
 		m.Sum = &Message_BlockResponse{BlockResponse: msg}
 
@@ -44,6 +47,9 @@ func (m *Message) Unwrap() (proto.Message, error) {
 	case *Message_BlockRequest:
 		return m.GetBlockRequest(), nil
 
+	case *Message_BlockRangeRequest:
+		return m.GetBlockRangeRequest(), nil
+
 	case *Message_BlockResponse:
 		return m.GetBlockResponse(), nil
 
@@ -73,6 +79,17 @@ func (m *Message) Validate() error {
 			return errors.New("negative Height")
 		}
 
+	case *Message_BlockRangeRequest:
+		if m.GetBlockRangeRequest().StartHeight < 0 {
+			return errors.New("negative StartHeight")
+		}
+		if m.GetBlockRangeRequest().StartHeight > m.GetBlockRangeRequest().EndHeight {
+			return fmt.Errorf(
+				"start height %v cannot be greater than end height %v",
+				m.GetBlockRangeRequest().StartHeight, m.GetBlockRangeRequest().EndHeight,
+			)
+		}
+
 	case *Message_BlockResponse:
 		// validate basic is called later when converting from proto
 		return nil
@@ -89,6 +106,9 @@ func (m *Message) Validate() error {
 		if m.GetStatusResponse().Height < 0 {
 			return errors.New("negative Height")
 		}
+		if m.GetStatusResponse().MaxBlockRange < 0 {
+			return errors.New("negative MaxBlockRange")
+		}
 		if m.GetStatusResponse().Base > m.GetStatusResponse().Height {
 			return fmt.Errorf(
 				"base %v cannot be greater than height %v",
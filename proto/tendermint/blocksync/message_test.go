@@ -34,6 +34,33 @@ func TestBlockRequest_Validate(t *testing.T) {
 	}
 }
 
+func TestBlockRangeRequest_Validate(t *testing.T) {
+	testCases := []struct {
+		testName    string
+		startHeight int64
+		endHeight   int64
+		expectErr   bool
+	}{
+		{"Valid Request Message", 1, 1, false},
+		{"Valid Request Message", 1, 10, false},
+		{"Invalid Request Message", -1, 10, true},
+		{"Invalid Request Message", 10, 1, true},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.testName, func(t *testing.T) {
+			msg := &bcproto.Message{}
+			require.NoError(t, msg.Wrap(&bcproto.BlockRangeRequest{
+				StartHeight: tc.startHeight,
+				EndHeight:   tc.endHeight,
+			}))
+
+			require.Equal(t, tc.expectErr, msg.Validate() != nil)
+		})
+	}
+}
+
 func TestNoBlockResponse_Validate(t *testing.T) {
 	testCases := []struct {
 		testName          string
@@ -118,6 +145,12 @@ func TestBlockchainMessageVectors(t *testing.T) {
 		{"StatusResponseMessage", &bcproto.Message{Sum: &bcproto.Message_StatusResponse{
 			StatusResponse: &bcproto.StatusResponse{Height: math.MaxInt64, Base: math.MaxInt64}}},
 			"2a1408ffffffffffffffff7f10ffffffffffffffff7f"},
+		{"StatusResponseMessage", &bcproto.Message{Sum: &bcproto.Message_StatusResponse{
+			StatusResponse: &bcproto.StatusResponse{Height: 1, Base: 2, MaxBlockRange: 3}}},
+			"2a06080110021803"},
+		{"BlockRangeRequestMessage", &bcproto.Message{Sum: &bcproto.Message_BlockRangeRequest{
+			BlockRangeRequest: &bcproto.BlockRangeRequest{StartHeight: 1, EndHeight: 100}}},
+			"320408011064"},
 	}
 
 	for _, tc := range testCases {
@@ -68,6 +68,61 @@ func (m *BlockRequest) GetHeight() int64 {
 	return 0
 }
 
+// BlockRangeRequest requests the blocks from start_height to end_height
+// (inclusive), which are sent back as a stream of BlockResponses in order.
+// Only sent to peers that advertised a max_block_range in their status.
+type BlockRangeRequest struct {
+	StartHeight int64 `protobuf:"varint,1,opt,name=start_height,json=startHeight,proto3" json:"start_height,omitempty"`
+	EndHeight   int64 `protobuf:"varint,2,opt,name=end_height,json=endHeight,proto3" json:"end_height,omitempty"`
+}
+
+func (m *BlockRangeRequest) Reset()         { *m = BlockRangeRequest{} }
+func (m *BlockRangeRequest) String() string { return proto.CompactTextString(m) }
+func (*BlockRangeRequest) ProtoMessage()    {}
+func (*BlockRangeRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_19b397c236e0fa07, []int{1}
+}
+func (m *BlockRangeRequest) XXX_Unmarshal(b []byte) error {
+	return m.Unmarshal(b)
+}
+func (m *BlockRangeRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	if deterministic {
+		return xxx_messageInfo_BlockRangeRequest.Marshal(b, m, deterministic)
+	} else {
+		b = b[:cap(b)]
+		n, err := m.MarshalToSizedBuffer(b)
+		if err != nil {
+			return nil, err
+		}
+		return b[:n], nil
+	}
+}
+func (m *BlockRangeRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_BlockRangeRequest.Merge(m, src)
+}
+func (m *BlockRangeRequest) XXX_Size() int {
+	return m.Size()
+}
+func (m *BlockRangeRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_BlockRangeRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_BlockRangeRequest proto.InternalMessageInfo
+
+func (m *BlockRangeRequest) GetStartHeight() int64 {
+	if m != nil {
+		return m.StartHeight
+	}
+	return 0
+}
+
+func (m *BlockRangeRequest) GetEndHeight() int64 {
+	if m != nil {
+		return m.EndHeight
+	}
+	return 0
+}
+
 // NoBlockResponse informs the node that the peer does not have block at the
 // requested height
 type NoBlockResponse struct {
@@ -78,7 +133,7 @@ func (m *NoBlockResponse) Reset()         { *m = NoBlockResponse{} }
 func (m *NoBlockResponse) String() string { return proto.CompactTextString(m) }
 func (*NoBlockResponse) ProtoMessage()    {}
 func (*NoBlockResponse) Descriptor() ([]byte, []int) {
-	return fileDescriptor_19b397c236e0fa07, []int{1}
+	return fileDescriptor_19b397c236e0fa07, []int{2}
 }
 func (m *NoBlockResponse) XXX_Unmarshal(b []byte) error {
 	return m.Unmarshal(b)
@@ -123,7 +178,7 @@ func (m *BlockResponse) Reset()         { *m = BlockResponse{} }
 func (m *BlockResponse) String() string { return proto.CompactTextString(m) }
 func (*BlockResponse) ProtoMessage()    {}
 func (*BlockResponse) Descriptor() ([]byte, []int) {
-	return fileDescriptor_19b397c236e0fa07, []int{2}
+	return fileDescriptor_19b397c236e0fa07, []int{3}
 }
 func (m *BlockResponse) XXX_Unmarshal(b []byte) error {
 	return m.Unmarshal(b)
@@ -167,7 +222,7 @@ func (m *StatusRequest) Reset()         { *m = StatusRequest{} }
 func (m *StatusRequest) String() string { return proto.CompactTextString(m) }
 func (*StatusRequest) ProtoMessage()    {}
 func (*StatusRequest) Descriptor() ([]byte, []int) {
-	return fileDescriptor_19b397c236e0fa07, []int{3}
+	return fileDescriptor_19b397c236e0fa07, []int{4}
 }
 func (m *StatusRequest) XXX_Unmarshal(b []byte) error {
 	return m.Unmarshal(b)
@@ -200,13 +255,16 @@ var xxx_messageInfo_StatusRequest proto.InternalMessageInfo
 type StatusResponse struct {
 	Height int64 `protobuf:"varint,1,opt,name=height,proto3" json:"height,omitempty"`
 	Base   int64 `protobuf:"varint,2,opt,name=base,proto3" json:"base,omitempty"`
+	// Maximum number of blocks served per BlockRangeRequest, 0 if the peer
+	// only supports BlockRequests.
+	MaxBlockRange int64 `protobuf:"varint,3,opt,name=max_block_range,json=maxBlockRange,proto3" json:"max_block_range,omitempty"`
 }
 
 func (m *StatusResponse) Reset()         { *m = StatusResponse{} }
 func (m *StatusResponse) String() string { return proto.CompactTextString(m) }
 func (*StatusResponse) ProtoMessage()    {}
 func (*StatusResponse) Descriptor() ([]byte, []int) {
-	return fileDescriptor_19b397c236e0fa07, []int{4}
+	return fileDescriptor_19b397c236e0fa07, []int{5}
 }
 func (m *StatusResponse) XXX_Unmarshal(b []byte) error {
 	return m.Unmarshal(b)
@@ -249,6 +307,13 @@ func (m *StatusResponse) GetBase() int64 {
 	return 0
 }
 
+func (m *StatusResponse) GetMaxBlockRange() int64 {
+	if m != nil {
+		return m.MaxBlockRange
+	}
+	return 0
+}
+
 type Message struct {
 	// Types that are valid to be assigned to Sum:
 	//	*Message_BlockRequest
@@ -256,6 +321,7 @@ type Message struct {
 	//	*Message_BlockResponse
 	//	*Message_StatusRequest
 	//	*Message_StatusResponse
+	//	*Message_BlockRangeRequest
 	Sum isMessage_Sum `protobuf_oneof:"sum"`
 }
 
@@ -263,7 +329,7 @@ func (m *Message) Reset()         { *m = Message{} }
 func (m *Message) String() string { return proto.CompactTextString(m) }
 func (*Message) ProtoMessage()    {}
 func (*Message) Descriptor() ([]byte, []int) {
-	return fileDescriptor_19b397c236e0fa07, []int{5}
+	return fileDescriptor_19b397c236e0fa07, []int{6}
 }
 func (m *Message) XXX_Unmarshal(b []byte) error {
 	return m.Unmarshal(b)
@@ -313,12 +379,16 @@ type Message_StatusRequest struct {
 type Message_StatusResponse struct {
 	StatusResponse *StatusResponse `protobuf:"bytes,5,opt,name=status_response,json=statusResponse,proto3,oneof" json:"status_response,omitempty"`
 }
+type Message_BlockRangeRequest struct {
+	BlockRangeRequest *BlockRangeRequest `protobuf:"bytes,6,opt,name=block_range_request,json=blockRangeRequest,proto3,oneof" json:"block_range_request,omitempty"`
+}
 
-func (*Message_BlockRequest) isMessage_Sum()    {}
-func (*Message_NoBlockResponse) isMessage_Sum() {}
-func (*Message_BlockResponse) isMessage_Sum()   {}
-func (*Message_StatusRequest) isMessage_Sum()   {}
-func (*Message_StatusResponse) isMessage_Sum()  {}
+func (*Message_BlockRequest) isMessage_Sum()      {}
+func (*Message_NoBlockResponse) isMessage_Sum()   {}
+func (*Message_BlockResponse) isMessage_Sum()     {}
+func (*Message_StatusRequest) isMessage_Sum()     {}
+func (*Message_StatusResponse) isMessage_Sum()    {}
+func (*Message_BlockRangeRequest) isMessage_Sum() {}
 
 func (m *Message) GetSum() isMessage_Sum {
 	if m != nil {
@@ -362,6 +432,13 @@ func (m *Message) GetStatusResponse() *StatusResponse {
 	return nil
 }
 
+func (m *Message) GetBlockRangeRequest() *BlockRangeRequest {
+	if x, ok := m.GetSum().(*Message_BlockRangeRequest); ok {
+		return x.BlockRangeRequest
+	}
+	return nil
+}
+
 // XXX_OneofWrappers is for the internal use of the proto package.
 func (*Message) XXX_OneofWrappers() []interface{} {
 	return []interface{}{
@@ -370,11 +447,13 @@ func (*Message) XXX_OneofWrappers() []interface{} {
 		(*Message_BlockResponse)(nil),
 		(*Message_StatusRequest)(nil),
 		(*Message_StatusResponse)(nil),
+		(*Message_BlockRangeRequest)(nil),
 	}
 }
 
 func init() {
 	proto.RegisterType((*BlockRequest)(nil), "tendermint.blocksync.BlockRequest")
+	proto.RegisterType((*BlockRangeRequest)(nil), "tendermint.blocksync.BlockRangeRequest")
 	proto.RegisterType((*NoBlockResponse)(nil), "tendermint.blocksync.NoBlockResponse")
 	proto.RegisterType((*BlockResponse)(nil), "tendermint.blocksync.BlockResponse")
 	proto.RegisterType((*StatusRequest)(nil), "tendermint.blocksync.StatusRequest")
@@ -385,30 +464,35 @@ func init() {
 func init() { proto.RegisterFile("tendermint/blocksync/types.proto", fileDescriptor_19b397c236e0fa07) }
 
 var fileDescriptor_19b397c236e0fa07 = []byte{
-	// 368 bytes of a gzipped FileDescriptorProto
-	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x7c, 0x93, 0x4d, 0x4f, 0xfa, 0x40,
-	0x10, 0xc6, 0xdb, 0x7f, 0x81, 0x7f, 0x32, 0x50, 0x1a, 0x1b, 0xa3, 0xc4, 0x98, 0x86, 0xd4, 0x97,
-	0xe8, 0xc1, 0x36, 0xc1, 0xa3, 0xc6, 0x03, 0x27, 0x4c, 0x7c, 0x49, 0x4a, 0xbc, 0x78, 0x21, 0x14,
-	0x37, 0x40, 0x94, 0x2e, 0x32, 0xdb, 0x03, 0xdf, 0xc2, 0x2f, 0xe0, 0xf7, 0xf1, 0xc8, 0xd1, 0xa3,
-	0x81, 0x2f, 0x62, 0x98, 0x2d, 0x65, 0x69, 0xb0, 0xb7, 0xdd, 0xe9, 0x33, 0xbf, 0x79, 0xfa, 0x64,
-	0x16, 0xea, 0x82, 0x45, 0x2f, 0x6c, 0x32, 0x1a, 0x46, 0xc2, 0x0f, 0xdf, 0x78, 0xef, 0x15, 0xa7,
-	0x51, 0xcf, 0x17, 0xd3, 0x31, 0x43, 0x6f, 0x3c, 0xe1, 0x82, 0xdb, 0xbb, 0x6b, 0x85, 0x97, 0x2a,
-	0x0e, 0x0e, 0x95, 0x3e, 0x52, 0xcb, 0x6e, 0xd9, 0xe3, 0x9e, 0x42, 0xa5, 0xb9, 0xbc, 0x06, 0xec,
-	0x3d, 0x66, 0x28, 0xec, 0x3d, 0x28, 0x0d, 0xd8, 0xb0, 0x3f, 0x10, 0x35, 0xbd, 0xae, 0x9f, 0x19,
-	0x41, 0x72, 0x73, 0xcf, 0xc1, 0x7a, 0xe0, 0x89, 0x12, 0xc7, 0x3c, 0x42, 0xf6, 0xa7, 0xf4, 0x06,
-	0xcc, 0x4d, 0xe1, 0x05, 0x14, 0x69, 0x24, 0xe9, 0xca, 0x8d, 0x7d, 0x4f, 0xf1, 0x29, 0xfd, 0x4b,
-	0xbd, 0x54, 0xb9, 0x16, 0x98, 0x6d, 0xd1, 0x15, 0x31, 0x26, 0x9e, 0xdc, 0x6b, 0xa8, 0xae, 0x0a,
-	0xf9, 0xa3, 0x6d, 0x1b, 0x0a, 0x61, 0x17, 0x59, 0xed, 0x1f, 0x55, 0xe9, 0xec, 0x7e, 0x1a, 0xf0,
-	0xff, 0x9e, 0x21, 0x76, 0xfb, 0xcc, 0xbe, 0x05, 0x93, 0x66, 0x74, 0x26, 0x12, 0x9d, 0x38, 0x72,
-	0xbd, 0x6d, 0xc9, 0x79, 0x6a, 0x30, 0x2d, 0x2d, 0xa8, 0x84, 0x6a, 0x50, 0x6d, 0xd8, 0x89, 0x78,
-	0x67, 0x45, 0x93, 0xbe, 0x68, 0x6e, 0xb9, 0x71, 0xb2, 0x1d, 0x97, 0xc9, 0xaf, 0xa5, 0x05, 0x56,
-	0x94, 0x89, 0xf4, 0x0e, 0xaa, 0x19, 0xa2, 0x41, 0xc4, 0xa3, 0x5c, 0x83, 0x29, 0xcf, 0x0c, 0xb3,
-	0x34, 0xa4, 0xdc, 0xd2, 0xdf, 0x2d, 0xe4, 0xd1, 0x36, 0x42, 0x5f, 0xd2, 0x50, 0x2d, 0xd8, 0x8f,
-	0x60, 0xa5, 0xb4, 0xc4, 0x5c, 0x91, 0x70, 0xc7, 0xf9, 0xb8, 0xd4, 0x5d, 0x15, 0x37, 0x2a, 0xcd,
-	0x22, 0x18, 0x18, 0x8f, 0x9a, 0x4f, 0x5f, 0x73, 0x47, 0x9f, 0xcd, 0x1d, 0xfd, 0x67, 0xee, 0xe8,
-	0x1f, 0x0b, 0x47, 0x9b, 0x2d, 0x1c, 0xed, 0x7b, 0xe1, 0x68, 0xcf, 0x57, 0xfd, 0xa1, 0x18, 0xc4,
-	0xa1, 0xd7, 0xe3, 0x23, 0x5f, 0x5d, 0xe2, 0xf5, 0x91, 0x76, 0xd8, 0xdf, 0xf6, 0x30, 0xc2, 0x12,
-	0x7d, 0xbb, 0xfc, 0x0d, 0x00, 0x00, 0xff, 0xff, 0xf5, 0x1c, 0xa3, 0x45, 0x37, 0x03, 0x00, 0x00,
+	// 444 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x7c, 0x93, 0xcf, 0x8e, 0xd3, 0x30,
+	0x10, 0xc6, 0x13, 0xb2, 0x2d, 0x62, 0xda, 0x34, 0xaa, 0x41, 0xb0, 0x42, 0x10, 0x2d, 0x01, 0x16,
+	0x38, 0x90, 0x48, 0xcb, 0x11, 0x89, 0x43, 0x4f, 0x41, 0xe2, 0x8f, 0x94, 0xd5, 0x1e, 0xe0, 0x52,
+	0x25, 0x8d, 0x95, 0x56, 0x10, 0xa7, 0xc4, 0x8e, 0xb4, 0xfb, 0x16, 0x3c, 0x16, 0xc7, 0x1e, 0x39,
+	0xa2, 0xf6, 0x25, 0x38, 0xa2, 0x8e, 0x9d, 0xd4, 0x89, 0x4a, 0x6e, 0xf6, 0xf8, 0x9b, 0xcf, 0xbf,
+	0x99, 0xd1, 0xc0, 0x99, 0xa0, 0x2c, 0xa5, 0x65, 0xbe, 0x62, 0x22, 0x48, 0xbe, 0x17, 0x8b, 0x6f,
+	0xfc, 0x86, 0x2d, 0x02, 0x71, 0xb3, 0xa6, 0xdc, 0x5f, 0x97, 0x85, 0x28, 0xc8, 0xbd, 0x83, 0xc2,
+	0x6f, 0x14, 0x0f, 0x1f, 0x69, 0x79, 0xa8, 0x96, 0xd9, 0x32, 0xc7, 0x3b, 0x87, 0xf1, 0x6c, 0x7f,
+	0x8d, 0xe8, 0x8f, 0x8a, 0x72, 0x41, 0xee, 0xc3, 0x70, 0x49, 0x57, 0xd9, 0x52, 0x9c, 0x9a, 0x67,
+	0xe6, 0x4b, 0x2b, 0x52, 0x37, 0xef, 0x0a, 0xa6, 0x52, 0x17, 0xb3, 0x8c, 0xd6, 0xe2, 0x27, 0x30,
+	0xe6, 0x22, 0x2e, 0xc5, 0xbc, 0x95, 0x32, 0xc2, 0x58, 0x88, 0x21, 0xf2, 0x18, 0x80, 0xb2, 0xb4,
+	0x16, 0xdc, 0x42, 0xc1, 0x1d, 0xca, 0x52, 0xf9, 0xec, 0xbd, 0x02, 0xe7, 0x53, 0xa1, 0x00, 0xf8,
+	0xba, 0x60, 0x9c, 0xfe, 0x97, 0xe0, 0x1d, 0xd8, 0x6d, 0xe1, 0x6b, 0x18, 0x60, 0x25, 0xa8, 0x1b,
+	0x5d, 0x3c, 0xf0, 0xb5, 0xf2, 0x65, 0x5b, 0xa4, 0x5e, 0xaa, 0x3c, 0x07, 0xec, 0x4b, 0x11, 0x8b,
+	0x8a, 0x2b, 0x7a, 0x2f, 0x85, 0x49, 0x1d, 0xe8, 0xff, 0x9a, 0x10, 0x38, 0x49, 0x62, 0x4e, 0x15,
+	0x3e, 0x9e, 0xc9, 0x39, 0x38, 0x79, 0x7c, 0x3d, 0x47, 0xef, 0x79, 0xb9, 0xef, 0xca, 0xa9, 0x85,
+	0xcf, 0x76, 0x1e, 0x5f, 0x1f, 0x5a, 0xe5, 0xfd, 0xb5, 0xe0, 0xf6, 0x47, 0xca, 0x79, 0x9c, 0x51,
+	0xf2, 0x1e, 0x6c, 0xa5, 0x97, 0x08, 0x8a, 0xdc, 0xf3, 0x8f, 0x0d, 0xce, 0xd7, 0xe7, 0x12, 0x1a,
+	0xd1, 0x38, 0xd1, 0xe7, 0x74, 0x09, 0x53, 0x56, 0xd4, 0xbf, 0x2b, 0x7e, 0xe4, 0x1b, 0x5d, 0x3c,
+	0x3f, 0x6e, 0xd7, 0xe9, 0x73, 0x68, 0x44, 0x0e, 0xeb, 0xb4, 0xfe, 0x03, 0x4c, 0x3a, 0x8e, 0x16,
+	0x3a, 0x3e, 0xed, 0x05, 0x6c, 0xfc, 0xec, 0xa4, 0xeb, 0xc6, 0xb1, 0xbf, 0x4d, 0xb9, 0x27, 0x7d,
+	0x6e, 0xad, 0xe1, 0xec, 0xdd, 0xb8, 0x1e, 0x20, 0x9f, 0xc1, 0x69, 0xdc, 0x14, 0xdc, 0x00, 0xed,
+	0x9e, 0xf5, 0xdb, 0x35, 0x74, 0x13, 0xde, 0x1e, 0xf6, 0x17, 0xb8, 0xab, 0x0d, 0xaf, 0x61, 0x1c,
+	0xa2, 0xe9, 0x8b, 0xbe, 0x8a, 0xb5, 0x15, 0x08, 0x8d, 0x68, 0x9a, 0x74, 0x83, 0xb3, 0x01, 0x58,
+	0xbc, 0xca, 0x67, 0x57, 0xbf, 0xb6, 0xae, 0xb9, 0xd9, 0xba, 0xe6, 0x9f, 0xad, 0x6b, 0xfe, 0xdc,
+	0xb9, 0xc6, 0x66, 0xe7, 0x1a, 0xbf, 0x77, 0xae, 0xf1, 0xf5, 0x6d, 0xb6, 0x12, 0xcb, 0x2a, 0xf1,
+	0x17, 0x45, 0x1e, 0xe8, 0xeb, 0x79, 0x38, 0xe2, 0x76, 0x06, 0xc7, 0x56, 0x3e, 0x19, 0xe2, 0xdb,
+	0x9b, 0x7f, 0x03, 0x00, 0xd6, 0x6e, 0x8d, 0xec, 0x11, 0x04, 0x00, 0x00,
 }
 
 func (m *BlockRequest) Marshal() (dAtA []byte, err error) {
@@ -439,6 +523,39 @@ func (m *BlockRequest) MarshalToSizedBuffer(dAtA []byte) (int, error) {
 	return len(dAtA) - i, nil
 }
 
+func (m *BlockRangeRequest) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalToSizedBuffer(dAtA[:size])
+	if err != nil {
+		return nil, err
+	}
+	return dAtA[:n], nil
+}
+
+func (m *BlockRangeRequest) MarshalTo(dAtA []byte) (int, error) {
+	size := m.Size()
+	return m.MarshalToSizedBuffer(dAtA[:size])
+}
+
+func (m *BlockRangeRequest) MarshalToSizedBuffer(dAtA []byte) (int, error) {
+	i := len(dAtA)
+	_ = i
+	var l int
+	_ = l
+	if m.EndHeight != 0 {
+		i = encodeVarintTypes(dAtA, i, uint64(m.EndHeight))
+		i--
+		dAtA[i] = 0x10
+	}
+	if m.StartHeight != 0 {
+		i = encodeVarintTypes(dAtA, i, uint64(m.StartHeight))
+		i--
+		dAtA[i] = 0x8
+	}
+	return len(dAtA) - i, nil
+}
+
 func (m *NoBlockResponse) Marshal() (dAtA []byte, err error) {
 	size := m.Size()
 	dAtA = make([]byte, size)
@@ -545,6 +662,11 @@ func (m *StatusResponse) MarshalToSizedBuffer(dAtA []byte) (int, error) {
 	_ = i
 	var l int
 	_ = l
+	if m.MaxBlockRange != 0 {
+		i = encodeVarintTypes(dAtA, i, uint64(m.MaxBlockRange))
+		i--
+		dAtA[i] = 0x18
+	}
 	if m.Base != 0 {
 		i = encodeVarintTypes(dAtA, i, uint64(m.Base))
 		i--
@@ -695,6 +817,27 @@ func (m *Message_StatusResponse) MarshalToSizedBuffer(dAtA []byte) (int, error)
 	}
 	return len(dAtA) - i, nil
 }
+func (m *Message_BlockRangeRequest) MarshalTo(dAtA []byte) (int, error) {
+	size := m.Size()
+	return m.MarshalToSizedBuffer(dAtA[:size])
+}
+
+func (m *Message_BlockRangeRequest) MarshalToSizedBuffer(dAtA []byte) (int, error) {
+	i := len(dAtA)
+	if m.BlockRangeRequest != nil {
+		{
+			size, err := m.BlockRangeRequest.MarshalToSizedBuffer(dAtA[:i])
+			if err != nil {
+				return 0, err
+			}
+			i -= size
+			i = encodeVarintTypes(dAtA, i, uint64(size))
+		}
+		i--
+		dAtA[i] = 0x32
+	}
+	return len(dAtA) - i, nil
+}
 func encodeVarintTypes(dAtA []byte, offset int, v uint64) int {
 	offset -= sovTypes(v)
 	base := offset
@@ -718,6 +861,21 @@ func (m *BlockRequest) Size() (n int) {
 	return n
 }
 
+func (m *BlockRangeRequest) Size() (n int) {
+	if m == nil {
+		return 0
+	}
+	var l int
+	_ = l
+	if m.StartHeight != 0 {
+		n += 1 + sovTypes(uint64(m.StartHeight))
+	}
+	if m.EndHeight != 0 {
+		n += 1 + sovTypes(uint64(m.EndHeight))
+	}
+	return n
+}
+
 func (m *NoBlockResponse) Size() (n int) {
 	if m == nil {
 		return 0
@@ -764,6 +922,9 @@ func (m *StatusResponse) Size() (n int) {
 	if m.Base != 0 {
 		n += 1 + sovTypes(uint64(m.Base))
 	}
+	if m.MaxBlockRange != 0 {
+		n += 1 + sovTypes(uint64(m.MaxBlockRange))
+	}
 	return n
 }
 
@@ -839,6 +1000,18 @@ func (m *Message_StatusResponse) Size() (n int) {
 	}
 	return n
 }
+func (m *Message_BlockRangeRequest) Size() (n int) {
+	if m == nil {
+		return 0
+	}
+	var l int
+	_ = l
+	if m.BlockRangeRequest != nil {
+		l = m.BlockRangeRequest.Size()
+		n += 1 + l + sovTypes(uint64(l))
+	}
+	return n
+}
 
 func sovTypes(x uint64) (n int) {
 	return (math_bits.Len64(x|1) + 6) / 7
@@ -915,6 +1088,94 @@ func (m *BlockRequest) Unmarshal(dAtA []byte) error {
 	}
 	return nil
 }
+func (m *BlockRangeRequest) Unmarshal(dAtA []byte) error {
+	l := len(dAtA)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowTypes
+			}
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= uint64(b&0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: BlockRangeRequest: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: BlockRangeRequest: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		case 1:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field StartHeight", wireType)
+			}
+			m.StartHeight = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowTypes
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.StartHeight |= int64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		case 2:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field EndHeight", wireType)
+			}
+			m.EndHeight = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowTypes
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.EndHeight |= int64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		default:
+			iNdEx = preIndex
+			skippy, err := skipTypes(dAtA[iNdEx:])
+			if err != nil {
+				return err
+			}
+			if (skippy < 0) || (iNdEx+skippy) < 0 {
+				return ErrInvalidLengthTypes
+			}
+			if (iNdEx + skippy) > l {
+				return io.ErrUnexpectedEOF
+			}
+			iNdEx += skippy
+		}
+	}
+
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
 func (m *NoBlockResponse) Unmarshal(dAtA []byte) error {
 	l := len(dAtA)
 	iNdEx := 0
@@ -1187,6 +1448,25 @@ func (m *StatusResponse) Unmarshal(dAtA []byte) error {
 					break
 				}
 			}
+		case 3:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field MaxBlockRange", wireType)
+			}
+			m.MaxBlockRange = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowTypes
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.MaxBlockRange |= int64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
 		default:
 			iNdEx = preIndex
 			skippy, err := skipTypes(dAtA[iNdEx:])
@@ -1412,6 +1692,41 @@ func (m *Message) Unmarshal(dAtA []byte) error {
 			}
 			m.Sum = &Message_StatusResponse{v}
 			iNdEx = postIndex
+		case 6:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field BlockRangeRequest", wireType)
+			}
+			var msglen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowTypes
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				msglen |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if msglen < 0 {
+				return ErrInvalidLengthTypes
+			}
+			postIndex := iNdEx + msglen
+			if postIndex < 0 {
+				return ErrInvalidLengthTypes
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			v := &BlockRangeRequest{}
+			if err := v.Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
+				return err
+			}
+			m.Sum = &Message_BlockRangeRequest{v}
+			iNdEx = postIndex
 		default:
 			iNdEx = preIndex
 			skippy, err := skipTypes(dAtA[iNdEx:])
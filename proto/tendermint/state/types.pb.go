@@ -411,66 +411,142 @@ func (m *State) GetAppHash() []byte {
 	return nil
 }
 
+// RollbackInfo records a rollback of the state from one height to an
+// earlier one, so that the private validator's last sign state can be
+// adjusted accordingly.
+type RollbackInfo struct {
+	FromHeight int64     `protobuf:"varint,1,opt,name=from_height,json=fromHeight,proto3" json:"from_height,omitempty"`
+	Height     int64     `protobuf:"varint,2,opt,name=height,proto3" json:"height,omitempty"`
+	AppHash    []byte    `protobuf:"bytes,3,opt,name=app_hash,json=appHash,proto3" json:"app_hash,omitempty"`
+	Time       time.Time `protobuf:"bytes,4,opt,name=time,proto3,stdtime" json:"time"`
+}
+
+func (m *RollbackInfo) Reset()         { *m = RollbackInfo{} }
+func (m *RollbackInfo) String() string { return proto.CompactTextString(m) }
+func (*RollbackInfo) ProtoMessage()    {}
+func (*RollbackInfo) Descriptor() ([]byte, []int) {
+	return fileDescriptor_ccfacf933f22bf93, []int{5}
+}
+func (m *RollbackInfo) XXX_Unmarshal(b []byte) error {
+	return m.Unmarshal(b)
+}
+func (m *RollbackInfo) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	if deterministic {
+		return xxx_messageInfo_RollbackInfo.Marshal(b, m, deterministic)
+	} else {
+		b = b[:cap(b)]
+		n, err := m.MarshalToSizedBuffer(b)
+		if err != nil {
+			return nil, err
+		}
+		return b[:n], nil
+	}
+}
+func (m *RollbackInfo) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_RollbackInfo.Merge(m, src)
+}
+func (m *RollbackInfo) XXX_Size() int {
+	return m.Size()
+}
+func (m *RollbackInfo) XXX_DiscardUnknown() {
+	xxx_messageInfo_RollbackInfo.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_RollbackInfo proto.InternalMessageInfo
+
+func (m *RollbackInfo) GetFromHeight() int64 {
+	if m != nil {
+		return m.FromHeight
+	}
+	return 0
+}
+
+func (m *RollbackInfo) GetHeight() int64 {
+	if m != nil {
+		return m.Height
+	}
+	return 0
+}
+
+func (m *RollbackInfo) GetAppHash() []byte {
+	if m != nil {
+		return m.AppHash
+	}
+	return nil
+}
+
+func (m *RollbackInfo) GetTime() time.Time {
+	if m != nil {
+		return m.Time
+	}
+	return time.Time{}
+}
+
 func init() {
 	proto.RegisterType((*ABCIResponses)(nil), "tendermint.state.ABCIResponses")
 	proto.RegisterType((*ValidatorsInfo)(nil), "tendermint.state.ValidatorsInfo")
 	proto.RegisterType((*ConsensusParamsInfo)(nil), "tendermint.state.ConsensusParamsInfo")
 	proto.RegisterType((*Version)(nil), "tendermint.state.Version")
 	proto.RegisterType((*State)(nil), "tendermint.state.State")
+	proto.RegisterType((*RollbackInfo)(nil), "tendermint.state.RollbackInfo")
 }
 
 func init() { proto.RegisterFile("tendermint/state/types.proto", fileDescriptor_ccfacf933f22bf93) }
 
 var fileDescriptor_ccfacf933f22bf93 = []byte{
-	// 763 bytes of a gzipped FileDescriptorProto
-	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0xa4, 0x55, 0xcf, 0x6f, 0xd3, 0x30,
-	0x14, 0x6e, 0xe8, 0xb6, 0xb6, 0xce, 0xda, 0x0e, 0x8f, 0x43, 0xd6, 0xb1, 0xb4, 0x2b, 0x3f, 0x34,
-	0x71, 0x48, 0xa5, 0x71, 0x40, 0x5c, 0x26, 0x2d, 0x2d, 0x62, 0x95, 0x26, 0x04, 0xd9, 0xb4, 0x03,
-	0x97, 0xc8, 0x6d, 0xbc, 0x24, 0xa2, 0x4d, 0xa2, 0xd8, 0x2d, 0xe3, 0x0f, 0xe0, 0xbe, 0x2b, 0xff,
-	0xd1, 0x8e, 0x3b, 0x22, 0x0e, 0x03, 0xba, 0x7f, 0x04, 0xd9, 0xce, 0x0f, 0xb7, 0x65, 0xd2, 0x10,
-	0x37, 0xfb, 0x7d, 0xdf, 0xfb, 0xfc, 0xf9, 0xf9, 0x3d, 0x19, 0x3c, 0xa6, 0x38, 0x70, 0x70, 0x3c,
-	0xf6, 0x03, 0xda, 0x21, 0x14, 0x51, 0xdc, 0xa1, 0x5f, 0x22, 0x4c, 0x8c, 0x28, 0x0e, 0x69, 0x08,
-	0x37, 0x72, 0xd4, 0xe0, 0x68, 0xe3, 0x91, 0x1b, 0xba, 0x21, 0x07, 0x3b, 0x6c, 0x25, 0x78, 0x8d,
-	0x6d, 0x49, 0x05, 0x0d, 0x86, 0xbe, 0x2c, 0xd2, 0x90, 0x8f, 0xe0, 0xf1, 0x39, 0xb4, 0xb5, 0x84,
-	0x4e, 0xd1, 0xc8, 0x77, 0x10, 0x0d, 0xe3, 0x84, 0xb1, 0xb3, 0xc4, 0x88, 0x50, 0x8c, 0xc6, 0xa9,
-	0x80, 0x2e, 0xc1, 0x53, 0x1c, 0x13, 0x3f, 0x0c, 0xe6, 0x0e, 0x68, 0xba, 0x61, 0xe8, 0x8e, 0x70,
-	0x87, 0xef, 0x06, 0x93, 0xf3, 0x0e, 0xf5, 0xc7, 0x98, 0x50, 0x34, 0x8e, 0x04, 0xa1, 0xfd, 0x43,
-	0x01, 0xd5, 0x43, 0xb3, 0xdb, 0xb7, 0x30, 0x89, 0xc2, 0x80, 0x60, 0x02, 0xbb, 0x40, 0x75, 0xf0,
-	0xc8, 0x9f, 0xe2, 0xd8, 0xa6, 0x17, 0x44, 0x53, 0x5a, 0xc5, 0x3d, 0x75, 0xbf, 0x6d, 0x48, 0xc5,
-	0x60, 0x97, 0x34, 0xd2, 0x84, 0x9e, 0xe0, 0x9e, 0x5e, 0x58, 0xc0, 0x49, 0x97, 0x04, 0x1e, 0x80,
-	0x0a, 0x0e, 0x1c, 0x7b, 0x30, 0x0a, 0x87, 0x9f, 0xb4, 0x07, 0x2d, 0x65, 0x4f, 0xdd, 0xdf, 0xbd,
-	0x53, 0xe2, 0x4d, 0xe0, 0x98, 0x8c, 0x68, 0x95, 0x71, 0xb2, 0x82, 0x3d, 0xa0, 0x0e, 0xb0, 0xeb,
-	0x07, 0x89, 0x42, 0x91, 0x2b, 0x3c, 0xb9, 0x53, 0xc1, 0x64, 0x5c, 0xa1, 0x01, 0x06, 0xd9, 0xba,
-	0xfd, 0x55, 0x01, 0xb5, 0xb3, 0xb4, 0xa0, 0xa4, 0x1f, 0x9c, 0x87, 0xb0, 0x0b, 0xaa, 0x59, 0x89,
-	0x6d, 0x82, 0xa9, 0xa6, 0x70, 0x69, 0x5d, 0x96, 0x16, 0x05, 0xcc, 0x12, 0x4f, 0x30, 0xb5, 0xd6,
-	0xa7, 0xd2, 0x0e, 0x1a, 0x60, 0x73, 0x84, 0x08, 0xb5, 0x3d, 0xec, 0xbb, 0x1e, 0xb5, 0x87, 0x1e,
-	0x0a, 0x5c, 0xec, 0xf0, 0x7b, 0x16, 0xad, 0x87, 0x0c, 0x3a, 0xe2, 0x48, 0x57, 0x00, 0xed, 0x6f,
-	0x0a, 0xd8, 0xec, 0x32, 0x9f, 0x01, 0x99, 0x90, 0xf7, 0xfc, 0xfd, 0xb8, 0x19, 0x0b, 0x6c, 0x0c,
-	0xd3, 0xb0, 0x2d, 0xde, 0x35, 0xf1, 0xb3, 0xbb, 0xec, 0x67, 0x41, 0xc0, 0x5c, 0xb9, 0xba, 0x69,
-	0x16, 0xac, 0xfa, 0x70, 0x3e, 0xfc, 0xcf, 0xde, 0x3c, 0x50, 0x3a, 0x13, 0x8d, 0x03, 0x0f, 0x41,
-	0x25, 0x53, 0x4b, 0x7c, 0xec, 0xc8, 0x3e, 0x92, 0x06, 0xcb, 0x9d, 0x24, 0x1e, 0xf2, 0x2c, 0xd8,
-	0x00, 0x65, 0x12, 0x9e, 0xd3, 0xcf, 0x28, 0xc6, 0xfc, 0xc8, 0x8a, 0x95, 0xed, 0xdb, 0xbf, 0xd7,
-	0xc0, 0xea, 0x09, 0x9b, 0x23, 0xf8, 0x1a, 0x94, 0x12, 0xad, 0xe4, 0x98, 0x2d, 0x63, 0x71, 0xd6,
-	0x8c, 0xc4, 0x54, 0x72, 0x44, 0xca, 0x87, 0xcf, 0x41, 0x79, 0xe8, 0x21, 0x3f, 0xb0, 0x7d, 0x71,
-	0xa7, 0x8a, 0xa9, 0xce, 0x6e, 0x9a, 0xa5, 0x2e, 0x8b, 0xf5, 0x7b, 0x56, 0x89, 0x83, 0x7d, 0x07,
-	0x3e, 0x03, 0x35, 0x3f, 0xf0, 0xa9, 0x8f, 0x46, 0x49, 0x25, 0xb4, 0x1a, 0xaf, 0x40, 0x35, 0x89,
-	0x8a, 0x22, 0xc0, 0x17, 0x80, 0x97, 0x44, 0xb4, 0x59, 0xca, 0x2c, 0x72, 0x66, 0x9d, 0x01, 0xbc,
-	0x8f, 0x12, 0xae, 0x05, 0xaa, 0x12, 0xd7, 0x77, 0xb4, 0x95, 0x65, 0xef, 0xe2, 0xa9, 0x78, 0x56,
-	0xbf, 0x67, 0x6e, 0x32, 0xef, 0xb3, 0x9b, 0xa6, 0x7a, 0x9c, 0x4a, 0xf5, 0x7b, 0x96, 0x9a, 0xe9,
-	0xf6, 0x1d, 0x78, 0x0c, 0xea, 0x92, 0x26, 0x1b, 0x4e, 0x6d, 0x95, 0xab, 0x36, 0x0c, 0x31, 0xb9,
-	0x46, 0x3a, 0xb9, 0xc6, 0x69, 0x3a, 0xb9, 0x66, 0x99, 0xc9, 0x5e, 0xfe, 0x6c, 0x2a, 0x56, 0x35,
-	0xd3, 0x62, 0x28, 0x7c, 0x0b, 0xea, 0x01, 0xbe, 0xa0, 0x76, 0xd6, 0xac, 0x44, 0x5b, 0xbb, 0x57,
-	0x7b, 0xd7, 0x58, 0x5a, 0x3e, 0x29, 0xf0, 0x00, 0x00, 0x49, 0xa3, 0x74, 0x2f, 0x0d, 0x29, 0x83,
-	0x19, 0xe1, 0xd7, 0x92, 0x44, 0xca, 0xf7, 0x33, 0xc2, 0xd2, 0x24, 0x23, 0x5d, 0xa0, 0xcb, 0xdd,
-	0x9c, 0xeb, 0x65, 0x8d, 0x5d, 0xe1, 0x8f, 0xb5, 0x9d, 0x37, 0x76, 0x9e, 0x9d, 0xb4, 0xf8, 0x5f,
-	0xc7, 0x0c, 0xfc, 0xe7, 0x98, 0xbd, 0x03, 0x4f, 0xe7, 0xc6, 0x6c, 0x41, 0x3f, 0xb3, 0xa7, 0x72,
-	0x7b, 0x2d, 0x69, 0xee, 0xe6, 0x85, 0x52, 0x8f, 0x69, 0x23, 0xc6, 0x98, 0x4c, 0x46, 0x94, 0xd8,
-	0x1e, 0x22, 0x9e, 0xb6, 0xde, 0x52, 0xf6, 0xd6, 0x45, 0x23, 0x5a, 0x22, 0x7e, 0x84, 0x88, 0x07,
-	0xb7, 0x40, 0x19, 0x45, 0x91, 0xa0, 0x54, 0x39, 0xa5, 0x84, 0xa2, 0x88, 0x41, 0xe6, 0x87, 0xab,
-	0x99, 0xae, 0x5c, 0xcf, 0x74, 0xe5, 0xd7, 0x4c, 0x57, 0x2e, 0x6f, 0xf5, 0xc2, 0xf5, 0xad, 0x5e,
-	0xf8, 0x7e, 0xab, 0x17, 0x3e, 0xbe, 0x72, 0x7d, 0xea, 0x4d, 0x06, 0xc6, 0x30, 0x1c, 0x77, 0xe4,
-	0x3f, 0x25, 0x5f, 0x8a, 0x8f, 0x6d, 0xf1, 0x4b, 0x1c, 0xac, 0xf1, 0xf8, 0xcb, 0x3f, 0x01, 0x00,
-	0x00, 0xff, 0xff, 0xa5, 0x17, 0xac, 0x23, 0x2d, 0x07, 0x00, 0x00,
+	// 817 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0xa4, 0x55, 0xcf, 0x8f, 0xdb, 0x44,
+	0x14, 0x5e, 0x93, 0xed, 0x26, 0x79, 0xde, 0x24, 0x65, 0x16, 0x21, 0x37, 0xa5, 0x4e, 0x08, 0x3f,
+	0xb4, 0xe2, 0xe0, 0x48, 0xe5, 0x00, 0x5c, 0x90, 0xea, 0x04, 0xd1, 0x48, 0x15, 0x02, 0xb7, 0xea,
+	0x81, 0x8b, 0x35, 0xb6, 0x27, 0xb6, 0x55, 0xc7, 0xb6, 0x3c, 0x93, 0xb0, 0xfc, 0x01, 0xdc, 0x7b,
+	0x45, 0xe2, 0x0f, 0xea, 0xb1, 0x47, 0xc4, 0x61, 0x81, 0xec, 0x3f, 0x82, 0xe6, 0x87, 0x9d, 0x49,
+	0x42, 0xa5, 0xad, 0x7a, 0x9b, 0x79, 0xef, 0x7b, 0xdf, 0x7c, 0xf3, 0xe6, 0x7b, 0x36, 0x7c, 0xc4,
+	0x48, 0x1e, 0x91, 0x6a, 0x95, 0xe6, 0x6c, 0x4a, 0x19, 0x66, 0x64, 0xca, 0x7e, 0x2d, 0x09, 0x75,
+	0xca, 0xaa, 0x60, 0x05, 0xba, 0xbb, 0xcb, 0x3a, 0x22, 0x3b, 0xfc, 0x20, 0x2e, 0xe2, 0x42, 0x24,
+	0xa7, 0x7c, 0x25, 0x71, 0xc3, 0xfb, 0x1a, 0x0b, 0x0e, 0xc2, 0x54, 0x27, 0x19, 0xea, 0x47, 0x88,
+	0xf8, 0x5e, 0x76, 0x7c, 0x94, 0xdd, 0xe0, 0x2c, 0x8d, 0x30, 0x2b, 0x2a, 0x85, 0x78, 0x70, 0x84,
+	0x28, 0x71, 0x85, 0x57, 0x35, 0x81, 0xad, 0xa5, 0x37, 0xa4, 0xa2, 0x69, 0x91, 0xef, 0x1d, 0x30,
+	0x8a, 0x8b, 0x22, 0xce, 0xc8, 0x54, 0xec, 0x82, 0xf5, 0x72, 0xca, 0xd2, 0x15, 0xa1, 0x0c, 0xaf,
+	0x4a, 0x09, 0x98, 0xfc, 0x65, 0x40, 0xef, 0x91, 0x3b, 0x5b, 0x78, 0x84, 0x96, 0x45, 0x4e, 0x09,
+	0x45, 0x33, 0x30, 0x23, 0x92, 0xa5, 0x1b, 0x52, 0xf9, 0xec, 0x8a, 0x5a, 0xc6, 0xb8, 0x75, 0x69,
+	0x3e, 0x9c, 0x38, 0x5a, 0x33, 0xf8, 0x25, 0x9d, 0xba, 0x60, 0x2e, 0xb1, 0xcf, 0xae, 0x3c, 0x88,
+	0xea, 0x25, 0x45, 0xdf, 0x42, 0x97, 0xe4, 0x91, 0x1f, 0x64, 0x45, 0xf8, 0xc2, 0x7a, 0x6f, 0x6c,
+	0x5c, 0x9a, 0x0f, 0x3f, 0x7e, 0x23, 0xc5, 0x77, 0x79, 0xe4, 0x72, 0xa0, 0xd7, 0x21, 0x6a, 0x85,
+	0xe6, 0x60, 0x06, 0x24, 0x4e, 0x73, 0xc5, 0xd0, 0x12, 0x0c, 0x9f, 0xbc, 0x91, 0xc1, 0xe5, 0x58,
+	0xc9, 0x01, 0x41, 0xb3, 0x9e, 0xfc, 0x66, 0x40, 0xff, 0x79, 0xdd, 0x50, 0xba, 0xc8, 0x97, 0x05,
+	0x9a, 0x41, 0xaf, 0x69, 0xb1, 0x4f, 0x09, 0xb3, 0x0c, 0x41, 0x6d, 0xeb, 0xd4, 0xb2, 0x81, 0x4d,
+	0xe1, 0x53, 0xc2, 0xbc, 0xf3, 0x8d, 0xb6, 0x43, 0x0e, 0x5c, 0x64, 0x98, 0x32, 0x3f, 0x21, 0x69,
+	0x9c, 0x30, 0x3f, 0x4c, 0x70, 0x1e, 0x93, 0x48, 0xdc, 0xb3, 0xe5, 0xbd, 0xcf, 0x53, 0x8f, 0x45,
+	0x66, 0x26, 0x13, 0x93, 0xdf, 0x0d, 0xb8, 0x98, 0x71, 0x9d, 0x39, 0x5d, 0xd3, 0x1f, 0xc5, 0xfb,
+	0x09, 0x31, 0x1e, 0xdc, 0x0d, 0xeb, 0xb0, 0x2f, 0xdf, 0xd5, 0x32, 0x8e, 0x9b, 0x25, 0xf5, 0x1c,
+	0x10, 0xb8, 0xa7, 0xaf, 0xae, 0x47, 0x27, 0xde, 0x20, 0xdc, 0x0f, 0xbf, 0xb5, 0xb6, 0x04, 0xda,
+	0xcf, 0xa5, 0x71, 0xd0, 0x23, 0xe8, 0x36, 0x6c, 0x4a, 0xc7, 0x03, 0x5d, 0x87, 0x32, 0xd8, 0x4e,
+	0x89, 0xd2, 0xb0, 0xab, 0x42, 0x43, 0xe8, 0xd0, 0x62, 0xc9, 0x7e, 0xc1, 0x15, 0x11, 0x47, 0x76,
+	0xbd, 0x66, 0x3f, 0xf9, 0xf7, 0x0c, 0xee, 0x3c, 0xe5, 0x73, 0x84, 0xbe, 0x81, 0xb6, 0xe2, 0x52,
+	0xc7, 0xdc, 0x73, 0x0e, 0x67, 0xcd, 0x51, 0xa2, 0xd4, 0x11, 0x35, 0x1e, 0x7d, 0x0e, 0x9d, 0x30,
+	0xc1, 0x69, 0xee, 0xa7, 0xf2, 0x4e, 0x5d, 0xd7, 0xdc, 0x5e, 0x8f, 0xda, 0x33, 0x1e, 0x5b, 0xcc,
+	0xbd, 0xb6, 0x48, 0x2e, 0x22, 0xf4, 0x19, 0xf4, 0xd3, 0x3c, 0x65, 0x29, 0xce, 0x54, 0x27, 0xac,
+	0xbe, 0xe8, 0x40, 0x4f, 0x45, 0x65, 0x13, 0xd0, 0x17, 0x20, 0x5a, 0x22, 0x6d, 0x56, 0x23, 0x5b,
+	0x02, 0x39, 0xe0, 0x09, 0xe1, 0x23, 0x85, 0xf5, 0xa0, 0xa7, 0x61, 0xd3, 0xc8, 0x3a, 0x3d, 0xd6,
+	0x2e, 0x9f, 0x4a, 0x54, 0x2d, 0xe6, 0xee, 0x05, 0xd7, 0xbe, 0xbd, 0x1e, 0x99, 0x4f, 0x6a, 0xaa,
+	0xc5, 0xdc, 0x33, 0x1b, 0xde, 0x45, 0x84, 0x9e, 0xc0, 0x40, 0xe3, 0xe4, 0xc3, 0x69, 0xdd, 0x11,
+	0xac, 0x43, 0x47, 0x4e, 0xae, 0x53, 0x4f, 0xae, 0xf3, 0xac, 0x9e, 0x5c, 0xb7, 0xc3, 0x69, 0x5f,
+	0xfe, 0x3d, 0x32, 0xbc, 0x5e, 0xc3, 0xc5, 0xb3, 0xe8, 0x7b, 0x18, 0xe4, 0xe4, 0x8a, 0xf9, 0x8d,
+	0x59, 0xa9, 0x75, 0x76, 0x2b, 0x7b, 0xf7, 0x79, 0x59, 0x13, 0xe1, 0xe3, 0x0b, 0x1a, 0x47, 0xfb,
+	0x56, 0x1c, 0x5a, 0x05, 0x17, 0x22, 0xae, 0xa5, 0x91, 0x74, 0x6e, 0x27, 0x84, 0x97, 0x69, 0x42,
+	0x66, 0x60, 0xeb, 0x6e, 0xde, 0xf1, 0x35, 0xc6, 0xee, 0x8a, 0xc7, 0xba, 0xbf, 0x33, 0xf6, 0xae,
+	0x5a, 0x59, 0xfc, 0x7f, 0xc7, 0x0c, 0xde, 0x71, 0xcc, 0x7e, 0x80, 0x4f, 0xf7, 0xc6, 0xec, 0x80,
+	0xbf, 0x91, 0x67, 0x0a, 0x79, 0x63, 0x6d, 0xee, 0xf6, 0x89, 0x6a, 0x8d, 0xb5, 0x11, 0x2b, 0x42,
+	0xd7, 0x19, 0xa3, 0x7e, 0x82, 0x69, 0x62, 0x9d, 0x8f, 0x8d, 0xcb, 0x73, 0x69, 0x44, 0x4f, 0xc6,
+	0x1f, 0x63, 0x9a, 0xa0, 0x7b, 0xd0, 0xc1, 0x65, 0x29, 0x21, 0x3d, 0x01, 0x69, 0xe3, 0xb2, 0xe4,
+	0xa9, 0xc9, 0x1f, 0x06, 0x9c, 0x7b, 0x45, 0x96, 0x05, 0x38, 0x7c, 0x21, 0x3e, 0x31, 0x23, 0x30,
+	0x97, 0x55, 0xb1, 0xaa, 0xad, 0x6d, 0x08, 0x39, 0xc0, 0x43, 0xca, 0xd5, 0x1f, 0xc2, 0x99, 0xca,
+	0xc9, 0x4f, 0x84, 0xda, 0xed, 0x1d, 0xd2, 0xda, 0x3b, 0x04, 0x7d, 0x0d, 0xa7, 0xc2, 0xa9, 0xa7,
+	0x6f, 0xe1, 0x54, 0x51, 0xe1, 0xfe, 0xf4, 0x6a, 0x6b, 0x1b, 0xaf, 0xb7, 0xb6, 0xf1, 0xcf, 0xd6,
+	0x36, 0x5e, 0xde, 0xd8, 0x27, 0xaf, 0x6f, 0xec, 0x93, 0x3f, 0x6f, 0xec, 0x93, 0x9f, 0xbf, 0x8a,
+	0x53, 0x96, 0xac, 0x03, 0x27, 0x2c, 0x56, 0x53, 0xfd, 0x97, 0xb7, 0x5b, 0xca, 0xff, 0xee, 0xe1,
+	0x1f, 0x3b, 0x38, 0x13, 0xf1, 0x2f, 0xff, 0x1b, 0x00, 0xf1, 0x0a, 0x34, 0xe3, 0xcc, 0x07, 0x00,
+	0x00,
 }
 
 func (m *ABCIResponses) Marshal() (dAtA []byte, err error) {
@@ -790,6 +866,54 @@ func (m *State) MarshalToSizedBuffer(dAtA []byte) (int, error) {
 	return len(dAtA) - i, nil
 }
 
+func (m *RollbackInfo) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalToSizedBuffer(dAtA[:size])
+	if err != nil {
+		return nil, err
+	}
+	return dAtA[:n], nil
+}
+
+func (m *RollbackInfo) MarshalTo(dAtA []byte) (int, error) {
+	size := m.Size()
+	return m.MarshalToSizedBuffer(dAtA[:size])
+}
+
+func (m *RollbackInfo) MarshalToSizedBuffer(dAtA []byte) (int, error) {
+	i := len(dAtA)
+	_ = i
+	var l int
+	_ = l
+	n13, err13 := github_com_gogo_protobuf_types.StdTimeMarshalTo(m.Time, dAtA[i-github_com_gogo_protobuf_types.SizeOfStdTime(m.Time):])
+	if err13 != nil {
+		return 0, err13
+	}
+	i -= n13
+	i = encodeVarintTypes(dAtA, i, uint64(n13))
+	i--
+	dAtA[i] = 0x22
+	if len(m.AppHash) > 0 {
+		i -= len(m.AppHash)
+		copy(dAtA[i:], m.AppHash)
+		i = encodeVarintTypes(dAtA, i, uint64(len(m.AppHash)))
+		i--
+		dAtA[i] = 0x1a
+	}
+	if m.Height != 0 {
+		i = encodeVarintTypes(dAtA, i, uint64(m.Height))
+		i--
+		dAtA[i] = 0x10
+	}
+	if m.FromHeight != 0 {
+		i = encodeVarintTypes(dAtA, i, uint64(m.FromHeight))
+		i--
+		dAtA[i] = 0x8
+	}
+	return len(dAtA) - i, nil
+}
+
 func encodeVarintTypes(dAtA []byte, offset int, v uint64) int {
 	offset -= sovTypes(v)
 	base := offset
@@ -922,6 +1046,27 @@ func (m *State) Size() (n int) {
 	return n
 }
 
+func (m *RollbackInfo) Size() (n int) {
+	if m == nil {
+		return 0
+	}
+	var l int
+	_ = l
+	if m.FromHeight != 0 {
+		n += 1 + sovTypes(uint64(m.FromHeight))
+	}
+	if m.Height != 0 {
+		n += 1 + sovTypes(uint64(m.Height))
+	}
+	l = len(m.AppHash)
+	if l > 0 {
+		n += 1 + l + sovTypes(uint64(l))
+	}
+	l = github_com_gogo_protobuf_types.SizeOfStdTime(m.Time)
+	n += 1 + l + sovTypes(uint64(l))
+	return n
+}
+
 func sovTypes(x uint64) (n int) {
 	return (math_bits.Len64(x|1) + 6) / 7
 }
@@ -1872,6 +2017,161 @@ func (m *State) Unmarshal(dAtA []byte) error {
 	}
 	return nil
 }
+func (m *RollbackInfo) Unmarshal(dAtA []byte) error {
+	l := len(dAtA)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowTypes
+			}
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= uint64(b&0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: RollbackInfo: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: RollbackInfo: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		case 1:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field FromHeight", wireType)
+			}
+			m.FromHeight = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowTypes
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.FromHeight |= int64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		case 2:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Height", wireType)
+			}
+			m.Height = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowTypes
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.Height |= int64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		case 3:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field AppHash", wireType)
+			}
+			var byteLen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowTypes
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				byteLen |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if byteLen < 0 {
+				return ErrInvalidLengthTypes
+			}
+			postIndex := iNdEx + byteLen
+			if postIndex < 0 {
+				return ErrInvalidLengthTypes
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.AppHash = append(m.AppHash[:0], dAtA[iNdEx:postIndex]...)
+			if m.AppHash == nil {
+				m.AppHash = []byte{}
+			}
+			iNdEx = postIndex
+		case 4:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Time", wireType)
+			}
+			var msglen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowTypes
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				msglen |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if msglen < 0 {
+				return ErrInvalidLengthTypes
+			}
+			postIndex := iNdEx + msglen
+			if postIndex < 0 {
+				return ErrInvalidLengthTypes
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			if err := github_com_gogo_protobuf_types.StdTimeUnmarshal(&m.Time, dAtA[iNdEx:postIndex]); err != nil {
+				return err
+			}
+			iNdEx = postIndex
+		default:
+			iNdEx = preIndex
+			skippy, err := skipTypes(dAtA[iNdEx:])
+			if err != nil {
+				return err
+			}
+			if (skippy < 0) || (iNdEx+skippy) < 0 {
+				return ErrInvalidLengthTypes
+			}
+			if (iNdEx + skippy) > l {
+				return io.ErrUnexpectedEOF
+			}
+			iNdEx += skippy
+		}
+	}
+
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
 func skipTypes(dAtA []byte) (n int, err error) {
 	l := len(dAtA)
 	iNdEx := 0
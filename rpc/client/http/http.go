@@ -324,6 +324,15 @@ func (c *baseRPCClient) RemoveTx(ctx context.Context, txKey types.TxKey) error {
 	return nil
 }
 
+func (c *baseRPCClient) MempoolTx(ctx context.Context, hash bytes.HexBytes) (*coretypes.ResultMempoolTx, error) {
+	result := new(coretypes.ResultMempoolTx)
+	_, err := c.caller.Call(ctx, "mempool_tx", map[string]interface{}{"hash": hash}, result)
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
 func (c *baseRPCClient) NetInfo(ctx context.Context) (*coretypes.ResultNetInfo, error) {
 	result := new(coretypes.ResultNetInfo)
 	_, err := c.caller.Call(ctx, "net_info", map[string]interface{}{}, result)
@@ -570,3 +579,82 @@ func (c *baseRPCClient) BroadcastEvidence(
 	}
 	return result, nil
 }
+
+func (c *baseRPCClient) PendingEvidence(
+	ctx context.Context,
+	page, perPage *int,
+) (*coretypes.ResultEvidenceList, error) {
+	result := new(coretypes.ResultEvidenceList)
+	params := make(map[string]interface{})
+	if page != nil {
+		params["page"] = page
+	}
+	if perPage != nil {
+		params["per_page"] = perPage
+	}
+	_, err := c.caller.Call(ctx, "pending_evidence", params, result)
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+func (c *baseRPCClient) CommittedEvidence(
+	ctx context.Context,
+	height *int64,
+	validator bytes.HexBytes,
+	page, perPage *int,
+) (*coretypes.ResultEvidenceList, error) {
+	result := new(coretypes.ResultEvidenceList)
+	params := make(map[string]interface{})
+	if height != nil {
+		params["height"] = height
+	}
+	if len(validator) > 0 {
+		params["validator"] = validator
+	}
+	if page != nil {
+		params["page"] = page
+	}
+	if perPage != nil {
+		params["per_page"] = perPage
+	}
+	_, err := c.caller.Call(ctx, "committed_evidence", params, result)
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+func (c *baseRPCClient) Evidence(ctx context.Context, hash bytes.HexBytes) (*coretypes.ResultEvidence, error) {
+	result := new(coretypes.ResultEvidence)
+	_, err := c.caller.Call(ctx, "evidence", map[string]interface{}{"hash": hash}, result)
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+func (c *baseRPCClient) EvidenceSearch(
+	ctx context.Context,
+	query string,
+	page, perPage *int,
+	orderBy string,
+) (*coretypes.ResultEvidenceList, error) {
+	result := new(coretypes.ResultEvidenceList)
+	params := map[string]interface{}{
+		"query":    query,
+		"order_by": orderBy,
+	}
+	if page != nil {
+		params["page"] = page
+	}
+	if perPage != nil {
+		params["per_page"] = perPage
+	}
+	_, err := c.caller.Call(ctx, "evidence_search", params, result)
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
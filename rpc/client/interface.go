@@ -147,12 +147,27 @@ type MempoolClient interface {
 	NumUnconfirmedTxs(context.Context) (*coretypes.ResultUnconfirmedTxs, error)
 	CheckTx(context.Context, types.Tx) (*coretypes.ResultCheckTx, error)
 	RemoveTx(context.Context, types.TxKey) error
+	MempoolTx(ctx context.Context, hash bytes.HexBytes) (*coretypes.ResultMempoolTx, error)
 }
 
 // EvidenceClient is used for submitting an evidence of the malicious
-// behavior.
+// behavior and querying the pending and committed evidence.
 type EvidenceClient interface {
 	BroadcastEvidence(context.Context, types.Evidence) (*coretypes.ResultBroadcastEvidence, error)
+	PendingEvidence(ctx context.Context, page, perPage *int) (*coretypes.ResultEvidenceList, error)
+	CommittedEvidence(
+		ctx context.Context,
+		height *int64,
+		validator bytes.HexBytes,
+		page, perPage *int,
+	) (*coretypes.ResultEvidenceList, error)
+	Evidence(ctx context.Context, hash bytes.HexBytes) (*coretypes.ResultEvidence, error)
+	EvidenceSearch(
+		ctx context.Context,
+		query string,
+		page, perPage *int,
+		orderBy string,
+	) (*coretypes.ResultEvidenceList, error)
 }
 
 // RemoteClient is a Client, which can also return the remote network address.
@@ -121,6 +121,10 @@ func (c *Local) RemoveTx(ctx context.Context, txKey types.TxKey) error {
 	return c.env.Mempool.RemoveTxByKey(txKey)
 }
 
+func (c *Local) MempoolTx(ctx context.Context, hash bytes.HexBytes) (*coretypes.ResultMempoolTx, error) {
+	return c.env.MempoolTx(c.ctx, hash)
+}
+
 func (c *Local) NetInfo(ctx context.Context) (*coretypes.ResultNetInfo, error) {
 	return c.env.NetInfo(c.ctx)
 }
@@ -201,6 +205,32 @@ func (c *Local) BroadcastEvidence(ctx context.Context, ev types.Evidence) (*core
 	return c.env.BroadcastEvidence(c.ctx, ev)
 }
 
+func (c *Local) PendingEvidence(ctx context.Context, page, perPage *int) (*coretypes.ResultEvidenceList, error) {
+	return c.env.PendingEvidence(c.ctx, page, perPage)
+}
+
+func (c *Local) CommittedEvidence(
+	ctx context.Context,
+	height *int64,
+	validator bytes.HexBytes,
+	page, perPage *int,
+) (*coretypes.ResultEvidenceList, error) {
+	return c.env.CommittedEvidence(c.ctx, height, validator, page, perPage)
+}
+
+func (c *Local) Evidence(ctx context.Context, hash bytes.HexBytes) (*coretypes.ResultEvidence, error) {
+	return c.env.Evidence(c.ctx, hash)
+}
+
+func (c *Local) EvidenceSearch(
+	ctx context.Context,
+	query string,
+	page, perPage *int,
+	orderBy string,
+) (*coretypes.ResultEvidenceList, error) {
+	return c.env.EvidenceSearch(c.ctx, query, page, perPage, orderBy)
+}
+
 func (c *Local) Subscribe(
 	ctx context.Context,
 	subscriber,
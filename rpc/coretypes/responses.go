@@ -152,8 +152,75 @@ type ResultDialPeers struct {
 
 // A peer
 type Peer struct {
-	ID  types.NodeID `json:"node_id"`
-	URL string       `json:"url"`
+	ID      types.NodeID      `json:"node_id"`
+	URL     string            `json:"url"`
+	Mempool *PeerMempoolStats `json:"mempool,omitempty"`
+}
+
+// Counts of the transactions received from a peer
+type PeerMempoolStats struct {
+	AcceptedTxs    uint64 `json:"accepted_txs"`
+	InvalidTxs     uint64 `json:"invalid_txs"`
+	DuplicateTxs   uint64 `json:"duplicate_txs"`
+	RateLimitedTxs uint64 `json:"rate_limited_txs"`
+}
+
+// Peers in the peer store and active bans
+type ResultListPeers struct {
+	Peers []PeerInfo `json:"peers"`
+	Bans  []PeerBan  `json:"bans"`
+}
+
+// A peer in the peer store, with the dial history of its addresses
+type PeerInfo struct {
+	ID            types.NodeID      `json:"node_id"`
+	Connected     bool              `json:"connected"`
+	LastConnected time.Time         `json:"last_connected"`
+	Addresses     []PeerAddressInfo `json:"addresses"`
+}
+
+// A peer address and its dial history
+type PeerAddressInfo struct {
+	Address         string    `json:"address"`
+	LastDialSuccess time.Time `json:"last_dial_success"`
+	LastDialFailure time.Time `json:"last_dial_failure"`
+	DialFailures    uint32    `json:"dial_failures"`
+}
+
+// A ban of a node ID or IP address. Expires is zero for permanent bans.
+type PeerBan struct {
+	Peer    string    `json:"peer"`
+	Expires time.Time `json:"expires"`
+}
+
+// Ban created by ban_peer
+type ResultBanPeer struct {
+	Ban PeerBan `json:"ban"`
+}
+
+// Peers crawled by a seed node, and a summary of the crawled network
+type ResultCrawledPeers struct {
+	Peers      []CrawledPeer `json:"peers"`
+	TotalCount int           `json:"total_count"`
+	Reachable  int           `json:"reachable"`
+	Compatible int           `json:"compatible"`
+	// Number of reachable peers by software version
+	Versions map[string]int `json:"versions"`
+}
+
+// A peer crawled by a seed node, with its NodeInfo and dial history
+type CrawledPeer struct {
+	ID            types.NodeID    `json:"id"`
+	Address       string          `json:"address"`
+	NodeInfo      *types.NodeInfo `json:"node_info,omitempty"`
+	Compatible    bool            `json:"compatible"`
+	FirstSeen     time.Time       `json:"first_seen"`
+	LastSeen      time.Time       `json:"last_seen"`
+	LastAttempt   time.Time       `json:"last_attempt"`
+	UpSince       time.Time       `json:"up_since"`
+	LastError     string          `json:"last_error,omitempty"`
+	DialAttempts  uint64          `json:"dial_attempts"`
+	DialSuccesses uint64          `json:"dial_successes"`
 }
 
 // Validators for a height.
@@ -244,6 +311,18 @@ type ResultUnconfirmedTxs struct {
 	Txs        []types.Tx `json:"txs"`
 }
 
+// A pending mempool tx
+type ResultMempoolTx struct {
+	Hash      bytes.HexBytes `json:"hash"`
+	Tx        types.Tx       `json:"tx"`
+	Priority  int64          `json:"priority"`
+	Sender    string         `json:"sender"`
+	Lane      string         `json:"lane"`
+	Height    int64          `json:"height"`
+	Timestamp time.Time      `json:"timestamp"`
+	Peers     []types.NodeID `json:"peers"`
+}
+
 // Info abci msg
 type ResultABCIInfo struct {
 	Response abci.ResponseInfo `json:"response"`
@@ -259,9 +338,26 @@ type ResultBroadcastEvidence struct {
 	Hash []byte `json:"hash"`
 }
 
+// ResultEvidence is evidence of misbehavior that the node has seen.
+type ResultEvidence struct {
+	Evidence types.Evidence `json:"evidence"`
+	Hash     bytes.HexBytes `json:"hash"`
+	// Height of the block the evidence was committed in, or 0 if the evidence
+	// is pending.
+	Height int64 `json:"height"`
+}
+
+// ResultEvidenceList is a page of evidence.
+type ResultEvidenceList struct {
+	Evidence   []*ResultEvidence `json:"evidence"`
+	TotalCount int               `json:"total_count"`
+}
+
 // empty results
 type (
 	ResultUnsafeFlushMempool struct{}
+	ResultRemovePeer         struct{}
+	ResultUnbanPeer          struct{}
 	ResultUnsafeProfile      struct{}
 	ResultSubscribe          struct{}
 	ResultUnsubscribe        struct{}
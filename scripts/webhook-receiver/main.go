@@ -0,0 +1,59 @@
+/*
+	webhook-receiver is a receiver for the webhook event sink, for local
+	testing. It checks the signature of the requests, and prints the events,
+	one JSON object per line, skipping those it has already printed.
+
+	Usage:
+			webhook-receiver -secret <secret> [-listen <address>]
+*/
+
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"net/http"
+	"os"
+	"sync"
+
+	"github.com/tendermint/tendermint/internal/state/indexer/sink/webhook"
+)
+
+func main() {
+	listen := flag.String("listen", "127.0.0.1:8080", "address to listen on")
+	secret := flag.String("secret", "", "secret the requests are signed with (tx-index.webhook-secret)")
+	flag.Parse()
+
+	if *secret == "" {
+		fmt.Fprintln(os.Stderr, "missing -secret")
+		os.Exit(1)
+	}
+
+	var (
+		mtx     sync.Mutex
+		lastSeq uint64
+		enc     = json.NewEncoder(os.Stdout)
+	)
+	handler := webhook.Handler([]byte(*secret), func(req *webhook.Request) error {
+		mtx.Lock()
+		defer mtx.Unlock()
+		for _, event := range req.Events {
+			// events are retried after failed requests
+			if event.Seq <= lastSeq {
+				continue
+			}
+			if err := enc.Encode(event); err != nil {
+				return err
+			}
+			lastSeq = event.Seq
+		}
+		return nil
+	})
+
+	fmt.Fprintf(os.Stderr, "listening on http://%s\n", *listen)
+	if err := http.ListenAndServe(*listen, handler); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
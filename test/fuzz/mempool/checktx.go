@@ -27,13 +27,16 @@ func init() {
 
 	getMp = func() mempool.Mempool {
 		if mp == nil {
-			mp = mempool.NewTxMempool(
+			var err error
+			mp, err = mempool.NewTxMempool(
 				log.NewNopLogger(),
 				cfg,
 				appConnMem,
 				0,
 			)
-
+			if err != nil {
+				panic(err)
+			}
 		}
 		return mp
 	}
@@ -23,6 +23,10 @@ const (
 	EventTxValue                  = "Tx"
 	EventValidatorSetUpdatesValue = "ValidatorSetUpdates"
 
+	// Mempool events, triggered when transactions enter or leave the
+	// mempool.
+	EventMempoolTxValue = "MempoolTx"
+
 	// Internal consensus events.
 	// These are used for testing the consensus state machine.
 	// They can also be used to build real-time consensus visualizers.
@@ -84,6 +88,16 @@ var (
 			},
 		},
 	}
+
+	EventMempoolTx = abci.Event{
+		Type: strings.Split(EventTypeKey, ".")[0],
+		Attributes: []abci.EventAttribute{
+			{
+				Key:   strings.Split(EventTypeKey, ".")[1],
+				Value: EventMempoolTxValue,
+			},
+		},
+	}
 )
 
 // ENCODING / DECODING
@@ -98,6 +112,7 @@ func init() {
 	tmjson.RegisterType(EventDataNewBlockHeader{}, "tendermint/event/NewBlockHeader")
 	tmjson.RegisterType(EventDataNewEvidence{}, "tendermint/event/NewEvidence")
 	tmjson.RegisterType(EventDataTx{}, "tendermint/event/Tx")
+	tmjson.RegisterType(EventDataMempoolTx{}, "tendermint/event/MempoolTx")
 	tmjson.RegisterType(EventDataRoundState{}, "tendermint/event/RoundState")
 	tmjson.RegisterType(EventDataNewRound{}, "tendermint/event/NewRound")
 	tmjson.RegisterType(EventDataCompleteProposal{}, "tendermint/event/CompleteProposal")
@@ -138,6 +153,38 @@ type EventDataTx struct {
 	abci.TxResult
 }
 
+// Statuses of the transactions in EventDataMempoolTx.
+const (
+	// MempoolTxAdded is the status of transactions added to the mempool.
+	MempoolTxAdded = "added"
+	// MempoolTxRecheckedInvalid is the status of transactions removed from the
+	// mempool because they failed re-CheckTx after a block was committed.
+	MempoolTxRecheckedInvalid = "rechecked_invalid"
+	// MempoolTxRemoved is the status of transactions removed from the mempool
+	// because they were committed, removed with remove_tx or flushed.
+	MempoolTxRemoved = "removed"
+	// MempoolTxEvicted is the status of transactions evicted from a full
+	// mempool or lane by higher priority transactions.
+	MempoolTxEvicted = "evicted"
+	// MempoolTxExpired is the status of transactions removed from the mempool
+	// because they exceeded ttl-num-blocks or ttl-duration.
+	MempoolTxExpired = "expired"
+)
+
+// EventDataMempoolTx is fired when a transaction enters or leaves the
+// mempool, with the reason for it.
+type EventDataMempoolTx struct {
+	Tx     Tx     `json:"tx"`
+	Status string `json:"status"`
+	Reason string `json:"reason"`
+
+	Priority int64  `json:"priority"`
+	Sender   string `json:"sender"`
+	Lane     string `json:"lane"`
+	// Height is the last block height processed by the mempool.
+	Height int64 `json:"height"`
+}
+
 // NOTE: This goes into the replay WAL
 type EventDataRoundState struct {
 	Height int64  `json:"height"`
@@ -201,11 +248,30 @@ const (
 	// TxHeightKey is a reserved key, used to specify transaction block's height.
 	// see EventBus#PublishEventTx
 	TxHeightKey = "tx.height"
+	// MempoolTxStatusKey is a reserved key, used to specify the status of a
+	// transaction in the mempool.
+	// see EventBus#PublishEventMempoolTx
+	MempoolTxStatusKey = "mempool_tx.status"
 
 	// BlockHeightKey is a reserved key used for indexing BeginBlock and Endblock
 	// events.
 	BlockHeightKey = "block.height"
 
+	// EvidenceHashKey is a reserved key, used to specify the hash of evidence.
+	// see EvidenceEvents
+	EvidenceHashKey = "evidence.hash"
+	// EvidenceHeightKey is a reserved key, used to specify the height of the
+	// misbehavior that evidence is for.
+	// see EvidenceEvents
+	EvidenceHeightKey = "evidence.height"
+	// EvidenceTypeKey is a reserved key, used to specify the type of evidence.
+	// see EvidenceEvents
+	EvidenceTypeKey = "evidence.type"
+	// EvidenceValidatorKey is a reserved key, used to specify the address of a
+	// validator that misbehaved.
+	// see EvidenceEvents
+	EvidenceValidatorKey = "evidence.validator"
+
 	EventTypeBeginBlock = "begin_block"
 	EventTypeEndBlock   = "end_block"
 )
@@ -213,6 +279,7 @@ const (
 var (
 	EventQueryCompleteProposal    = QueryForEvent(EventCompleteProposalValue)
 	EventQueryLock                = QueryForEvent(EventLockValue)
+	EventQueryMempoolTx           = QueryForEvent(EventMempoolTxValue)
 	EventQueryNewBlock            = QueryForEvent(EventNewBlockValue)
 	EventQueryNewBlockHeader      = QueryForEvent(EventNewBlockHeaderValue)
 	EventQueryNewEvidence         = QueryForEvent(EventNewEvidenceValue)
@@ -235,6 +302,32 @@ func EventQueryTxFor(tx Tx) tmpubsub.Query {
 	return tmquery.MustParse(fmt.Sprintf("%s='%s' AND %s='%X'", EventTypeKey, EventTxValue, TxHashKey, tx.Hash()))
 }
 
+// EvidenceEvents returns the Tendermint-reserved events that describe the
+// evidence, which are used to query evidence and to subscribe to new evidence.
+func EvidenceEvents(ev Evidence) []abci.Event {
+	event := abci.Event{Type: strings.Split(EvidenceHashKey, ".")[0]}
+	attr := func(key, value string) {
+		event.Attributes = append(event.Attributes, abci.EventAttribute{
+			Key:   strings.Split(key, ".")[1],
+			Value: value,
+		})
+	}
+
+	attr(EvidenceHashKey, fmt.Sprintf("%X", ev.Hash()))
+	attr(EvidenceHeightKey, fmt.Sprintf("%d", ev.Height()))
+	switch ev.(type) {
+	case *DuplicateVoteEvidence:
+		attr(EvidenceTypeKey, abci.EvidenceType_DUPLICATE_VOTE.String())
+	case *LightClientAttackEvidence:
+		attr(EvidenceTypeKey, abci.EvidenceType_LIGHT_CLIENT_ATTACK.String())
+	}
+	for _, abciEv := range ev.ABCI() {
+		attr(EvidenceValidatorKey, fmt.Sprintf("%X", abciEv.Validator.Address))
+	}
+
+	return []abci.Event{event}
+}
+
 func QueryForEvent(eventValue string) tmpubsub.Query {
 	return tmquery.MustParse(fmt.Sprintf("%s='%s'", EventTypeKey, eventValue))
 }
@@ -251,3 +344,9 @@ type BlockEventPublisher interface {
 type TxEventPublisher interface {
 	PublishEventTx(EventDataTx) error
 }
+
+// MempoolEventPublisher publishes the events of transactions entering and
+// leaving the mempool.
+type MempoolEventPublisher interface {
+	PublishEventMempoolTx(EventDataMempoolTx) error
+}
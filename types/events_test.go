@@ -3,8 +3,12 @@ package types
 import (
 	"fmt"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	tmquery "github.com/tendermint/tendermint/libs/pubsub/query"
 )
 
 func TestQueryTxFor(t *testing.T) {
@@ -25,3 +29,23 @@ func TestQueryForEvent(t *testing.T) {
 		QueryForEvent(EventNewEvidenceValue).String(),
 	)
 }
+
+func TestEvidenceEvents(t *testing.T) {
+	ev := NewMockDuplicateVoteEvidence(3, time.Now(), "test-chain")
+	events := EvidenceEvents(ev)
+
+	for _, q := range []string{
+		fmt.Sprintf("evidence.hash='%X'", ev.Hash()),
+		"evidence.height=3",
+		"evidence.type='DUPLICATE_VOTE'",
+		fmt.Sprintf("evidence.validator='%X'", ev.VoteA.ValidatorAddress),
+	} {
+		match, err := tmquery.MustParse(q).Matches(events)
+		require.NoError(t, err)
+		assert.True(t, match, q)
+	}
+
+	match, err := tmquery.MustParse("evidence.type='LIGHT_CLIENT_ATTACK'").Matches(events)
+	require.NoError(t, err)
+	assert.False(t, match)
+}
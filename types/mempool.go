@@ -42,6 +42,27 @@ func (e ErrMempoolIsFull) Error() string {
 	)
 }
 
+// ErrMempoolLaneIsFull defines an error where a mempool lane has reached its
+// configured limits.
+type ErrMempoolLaneIsFull struct {
+	Lane        string
+	NumTxs      int
+	MaxTxs      int
+	TxsBytes    int64
+	MaxTxsBytes int64
+}
+
+func (e ErrMempoolLaneIsFull) Error() string {
+	return fmt.Sprintf(
+		"mempool lane %q is full: number of txs %d (max: %d), total txs bytes %d (max: %d)",
+		e.Lane,
+		e.NumTxs,
+		e.MaxTxs,
+		e.TxsBytes,
+		e.MaxTxsBytes,
+	)
+}
+
 // ErrPreCheck defines an error where a transaction fails a pre-check.
 type ErrPreCheck struct {
 	Reason error